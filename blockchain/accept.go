@@ -16,8 +16,9 @@ import (
 // ProcessBlock before calling this function with it.
 //
 // The flags modify the behavior of this function as follows:
-//  - BFDryRun: The memory chain index will not be pruned and no accept
-//    notification will be sent since the block is not being accepted.
+//  - BFDryRun: The block will not be stored in the database, the memory chain
+//    index will not be pruned and no accept notification will be sent since
+//    the block is not being accepted.
 //
 // The flags are also passed to checkBlockContext and connectBestChain.  See
 // their documentation for how the flags modify their behavior.
@@ -51,11 +52,16 @@ func (b *BlockChain) maybeAcceptBlock(block *vtcutil.Block, flags BehaviorFlags)
 	// expensive connection logic.  It also has some other nice properties
 	// such as making blocks that never become part of the main chain or
 	// blocks that fail to connect available for further analysis.
-	err = b.db.Update(func(dbTx database.Tx) error {
-		return dbMaybeStoreBlock(dbTx, block)
-	})
-	if err != nil {
-		return false, err
+	//
+	// Blocks processed as a dry run are not stored since they are not
+	// being accepted and might not have passed the proof-of-work checks.
+	if !dryRun {
+		err = b.db.Update(func(dbTx database.Tx) error {
+			return dbMaybeStoreBlock(dbTx, block)
+		})
+		if err != nil {
+			return false, err
+		}
 	}
 
 	// Create a new block node for the block and add it to the in-memory
@@ -78,8 +84,10 @@ func (b *BlockChain) maybeAcceptBlock(block *vtcutil.Block, flags BehaviorFlags)
 
 	// Notify the caller that the new block was accepted into the block
 	// chain.  The caller would typically want to react by relaying the
-	// inventory to other peers.
+	// inventory to other peers.  The header of the block is no longer
+	// needed if it was processed without the block data before.
 	if !dryRun {
+		delete(b.headerNodes, *block.Hash())
 		b.chainLock.Unlock()
 		b.sendNotification(NTBlockAccepted, block)
 		b.chainLock.Lock()
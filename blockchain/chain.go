@@ -13,6 +13,7 @@ import (
 	"github.com/vertcoin/vtcd/chaincfg"
 	"github.com/vertcoin/vtcd/chaincfg/chainhash"
 	"github.com/vertcoin/vtcd/database"
+	"github.com/vertcoin/vtcd/metrics"
 	"github.com/vertcoin/vtcd/txscript"
 	"github.com/vertcoin/vtcd/wire"
 	"github.com/vertcoin/vtcutil"
@@ -22,6 +23,39 @@ const (
 	// maxOrphanBlocks is the maximum number of orphan blocks that can be
 	// queued.
 	maxOrphanBlocks = 100
+
+	// maxHeaderNodes is the maximum number of headers submitted without
+	// their block data that are kept.
+	maxHeaderNodes = 2000
+
+	// headerNodeExpiration is how long a header submitted without its
+	// block data is kept before it is discarded.
+	headerNodeExpiration = time.Hour
+)
+
+// validationBuckets are the upper bounds of the block validation latency
+// histograms in seconds, ranging from 1ms to about 33s.
+var validationBuckets = metrics.ExponentialBuckets(0.001, 2, 16)
+
+var (
+	// bestHeightGauge tracks the height of the main chain tip.
+	bestHeightGauge = metrics.NewGauge("vtcd_chain_best_height",
+		"Height of the best block of the main chain.")
+
+	// blockSanitySeconds tracks the time taken by the context-free sanity
+	// checks of blocks.
+	blockSanitySeconds = metrics.NewHistogram(
+		"vtcd_block_sanity_check_seconds",
+		"Time taken by the context-free sanity checks of a block in "+
+			"seconds.", validationBuckets)
+
+	// blockConnectSeconds tracks the time taken to validate that blocks
+	// can be connected to the main chain, which includes script
+	// validation.
+	blockConnectSeconds = metrics.NewHistogram(
+		"vtcd_block_connect_check_seconds",
+		"Time taken to validate a block against the main chain, "+
+			"including scripts, in seconds.", validationBuckets)
 )
 
 // BlockLocator is used to help locate a specific block.  The algorithm for
@@ -48,6 +82,13 @@ type orphanBlock struct {
 	expiration time.Time
 }
 
+// headerNode is a block node for a header which was submitted without its
+// block data along with the time it expires.
+type headerNode struct {
+	node       *blockNode
+	expiration time.Time
+}
+
 // BestState houses information about the current best block and other info
 // related to the state of the main chain as it exists from the point of view of
 // the current best block.
@@ -140,6 +181,13 @@ type BlockChain struct {
 	prevOrphans  map[chainhash.Hash][]*orphanBlock
 	oldestOrphan *orphanBlock
 
+	// headerNodes houses the nodes of headers which were submitted without
+	// their block data via ProcessBlockHeader.  They are not part of the
+	// block index, so they never become part of the best chain, and are
+	// removed once their block is accepted, once they expire, or to make
+	// room for newer headers.  It is protected by the chain lock.
+	headerNodes map[chainhash.Hash]*headerNode
+
 	// These fields are related to checkpoint handling.  They are protected
 	// by the chain lock.
 	nextCheckpoint *chaincfg.Checkpoint
@@ -335,6 +383,51 @@ func (b *BlockChain) addOrphanBlock(block *vtcutil.Block) {
 	b.prevOrphans[*prevHash] = append(b.prevOrphans[*prevHash], oBlock)
 }
 
+// addHeaderNode adds the passed node of a header which was submitted without
+// its block data to the header nodes.  Expired header nodes are removed first
+// and, when the limit is reached, the oldest header node is evicted to make
+// room for the new one.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) addHeaderNode(node *blockNode) {
+	now := time.Now()
+	var oldest *headerNode
+	for hash, hNode := range b.headerNodes {
+		if now.After(hNode.expiration) {
+			delete(b.headerNodes, hash)
+			continue
+		}
+		if oldest == nil || hNode.expiration.Before(oldest.expiration) {
+			oldest = hNode
+		}
+	}
+	if len(b.headerNodes)+1 > maxHeaderNodes && oldest != nil {
+		delete(b.headerNodes, oldest.node.hash)
+	}
+
+	b.headerNodes[node.hash] = &headerNode{
+		node:       node,
+		expiration: now.Add(headerNodeExpiration),
+	}
+}
+
+// lookupHeaderNode returns the node of the header with the passed hash which
+// was submitted without its block data.  It returns nil when there is no such
+// header or it has expired, in which case it is removed.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) lookupHeaderNode(hash *chainhash.Hash) *blockNode {
+	hNode, ok := b.headerNodes[*hash]
+	if !ok {
+		return nil
+	}
+	if time.Now().After(hNode.expiration) {
+		delete(b.headerNodes, *hash)
+		return nil
+	}
+	return hNode.node
+}
+
 // SequenceLock represents the converted relative lock-time in seconds, and
 // absolute block-height for a transaction input's relative lock-times.
 // According to SequenceLock, after the referenced input has been confirmed
@@ -659,6 +752,7 @@ func (b *BlockChain) connectBlock(node *blockNode, block *vtcutil.Block, view *U
 	b.stateLock.Lock()
 	b.stateSnapshot = state
 	b.stateLock.Unlock()
+	bestHeightGauge.Set(int64(state.Height))
 
 	// Notify the caller that the block was connected to the main chain.
 	// The caller would typically want to react with actions such as
@@ -765,6 +859,7 @@ func (b *BlockChain) disconnectBlock(node *blockNode, block *vtcutil.Block, view
 	b.stateLock.Lock()
 	b.stateSnapshot = state
 	b.stateLock.Unlock()
+	bestHeightGauge.Set(int64(state.Height))
 
 	// Notify the caller that the block was disconnected from the main
 	// chain.  The caller would typically want to react with actions such as
@@ -798,8 +893,12 @@ func countSpentOutputs(block *vtcutil.Block) int {
 //  - BFDryRun: Only the checks which ensure the reorganize can be completed
 //    successfully are performed.  The chain is not reorganized.
 //
+// The passed block is the block of the last node being attached.  It is used
+// instead of loading that block from the database since it is not stored when
+// running with the dry run flag set.
+//
 // This function MUST be called with the chain state lock held (for writes).
-func (b *BlockChain) reorganizeChain(detachNodes, attachNodes *list.List, flags BehaviorFlags) error {
+func (b *BlockChain) reorganizeChain(detachNodes, attachNodes *list.List, tipBlock *vtcutil.Block, flags BehaviorFlags) error {
 	// All of the blocks to detach and related spend journal entries needed
 	// to unspend transaction outputs in the blocks being disconnected must
 	// be loaded from the database during the reorg check phase below and
@@ -869,14 +968,16 @@ func (b *BlockChain) reorganizeChain(detachNodes, attachNodes *list.List, flags
 	// issues before ever modifying the chain.
 	for e := attachNodes.Front(); e != nil; e = e.Next() {
 		n := e.Value.(*blockNode)
-		var block *vtcutil.Block
-		err := b.db.View(func(dbTx database.Tx) error {
-			var err error
-			block, err = dbFetchBlockByNode(dbTx, n)
-			return err
-		})
-		if err != nil {
-			return err
+		block := tipBlock
+		if n.hash != *tipBlock.Hash() {
+			err := b.db.View(func(dbTx database.Tx) error {
+				var err error
+				block, err = dbFetchBlockByNode(dbTx, n)
+				return err
+			})
+			if err != nil {
+				return err
+			}
 		}
 
 		// Store the loaded block for later.
@@ -886,7 +987,9 @@ func (b *BlockChain) reorganizeChain(detachNodes, attachNodes *list.List, flags
 		// thus will not be generated.  This is done because the state
 		// is not being immediately written to the database, so it is
 		// not needed.
-		err = b.checkConnectBlock(n, block, view, nil)
+		start := time.Now()
+		err := b.checkConnectBlock(n, block, view, nil)
+		blockConnectSeconds.Observe(time.Since(start).Seconds())
 		if err != nil {
 			return err
 		}
@@ -1005,7 +1108,9 @@ func (b *BlockChain) connectBestChain(node *blockNode, block *vtcutil.Block, fla
 		view.SetBestHash(parentHash)
 		stxos := make([]spentTxOut, 0, countSpentOutputs(block))
 		if !fastAdd {
+			start := time.Now()
 			err := b.checkConnectBlock(node, block, view, &stxos)
+			blockConnectSeconds.Observe(time.Since(start).Seconds())
 			if err != nil {
 				return false, err
 			}
@@ -1098,7 +1203,7 @@ func (b *BlockChain) connectBestChain(node *blockNode, block *vtcutil.Block, fla
 		log.Infof("REORGANIZE: Block %v is causing a reorganize.",
 			node.hash)
 	}
-	err := b.reorganizeChain(detachNodes, attachNodes, flags)
+	err := b.reorganizeChain(detachNodes, attachNodes, block, flags)
 	if err != nil {
 		return false, err
 	}
@@ -1227,6 +1332,72 @@ func (b *BlockChain) BlockHeightByHash(hash *chainhash.Hash) (int32, error) {
 	return node.height, nil
 }
 
+// BlockPastMedianTime returns the median time of the 11 blocks ending with the
+// block with the given hash in the main chain.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) BlockPastMedianTime(hash *chainhash.Hash) (time.Time, error) {
+	node := b.index.LookupNode(hash)
+	if node == nil || !b.bestChain.Contains(node) {
+		str := fmt.Sprintf("block %s is not in the main chain", hash)
+		return time.Time{}, errNotInMainChain(str)
+	}
+
+	return node.CalcPastMedianTime(), nil
+}
+
+// SpentTxOut is a transaction output spent by a transaction of a block in the
+// main chain.
+type SpentTxOut struct {
+	// Amount is the amount of the output.
+	Amount int64
+
+	// PkScript is the public key script of the output.
+	PkScript []byte
+}
+
+// FetchSpendJournal returns the transaction outputs spent by the transactions
+// of the passed block, which must be in the main chain, in the order they are
+// spent.  Since the coinbase transaction doesn't spend any outputs, the first
+// entry is spent by the first input of the second transaction.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) FetchSpendJournal(block *vtcutil.Block) ([]SpentTxOut, error) {
+	// Hold the chain lock so the block can't be disconnected, and its
+	// spend journal entry removed, while it is fetched.
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	node := b.index.LookupNode(block.Hash())
+	if node == nil || !b.bestChain.Contains(node) {
+		str := fmt.Sprintf("block %s is not in the main chain",
+			block.Hash())
+		return nil, errNotInMainChain(str)
+	}
+
+	var stxos []spentTxOut
+	err := b.db.View(func(dbTx database.Tx) error {
+		var err error
+		stxos, err = dbFetchSpendJournalEntry(dbTx, block, nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	spent := make([]SpentTxOut, len(stxos))
+	for i := range stxos {
+		stxo := &stxos[i]
+		amount, pkScript := stxo.amount, stxo.pkScript
+		if stxo.compressed {
+			amount = int64(decompressTxOutAmount(uint64(amount)))
+			pkScript = decompressScript(pkScript, stxo.version)
+		}
+		spent[i] = SpentTxOut{Amount: amount, PkScript: pkScript}
+	}
+	return spent, nil
+}
+
 // BlockHashByHeight returns the hash of the block at the given height in the
 // main chain.
 //
@@ -1568,6 +1739,7 @@ func New(config *Config) (*BlockChain, error) {
 		bestChain:           newChainView(nil),
 		orphans:             make(map[chainhash.Hash]*orphanBlock),
 		prevOrphans:         make(map[chainhash.Hash][]*orphanBlock),
+		headerNodes:         make(map[chainhash.Hash]*headerNode),
 		warningCaches:       newThresholdCaches(vbNumBits),
 		deploymentCaches:    newThresholdCaches(chaincfg.DefinedDeployments),
 	}
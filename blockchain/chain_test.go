@@ -800,3 +800,68 @@ func TestLocateInventory(t *testing.T) {
 		}
 	}
 }
+
+// TestAddHeaderNode ensures the nodes of headers submitted without their block
+// data are limited and expire.
+func TestAddHeaderNode(t *testing.T) {
+	chain := newFakeChain(&chaincfg.MainNetParams)
+	chain.headerNodes = make(map[chainhash.Hash]*headerNode)
+
+	// Fill the header nodes up to the limit and ensure adding another one
+	// evicts the oldest.
+	tip := chain.bestChain.Tip()
+	nodes := make([]*blockNode, 0, maxHeaderNodes+1)
+	for i := 0; i < maxHeaderNodes+1; i++ {
+		node := newFakeNode(tip, 1, 0x207fffff, time.Unix(int64(i), 0))
+		nodes = append(nodes, node)
+		chain.addHeaderNode(node)
+		chain.headerNodes[node.hash].expiration = time.Now().Add(
+			time.Hour + time.Duration(i)*time.Second)
+	}
+	if len(chain.headerNodes) != maxHeaderNodes {
+		t.Fatalf("unexpected number of header nodes -- got %d, want %d",
+			len(chain.headerNodes), maxHeaderNodes)
+	}
+	if _, ok := chain.headerNodes[nodes[0].hash]; ok {
+		t.Fatalf("oldest header node %v was not evicted", nodes[0].hash)
+	}
+	if _, ok := chain.headerNodes[nodes[maxHeaderNodes].hash]; !ok {
+		t.Fatalf("newest header node %v is missing",
+			nodes[maxHeaderNodes].hash)
+	}
+
+	// An expired header node is no longer found and is removed when it is
+	// looked up.
+	expired := nodes[1]
+	chain.headerNodes[expired.hash].expiration = time.Now().Add(-time.Second)
+	if node := chain.lookupHeaderNode(&expired.hash); node != nil {
+		t.Fatalf("expired header node %v was found", expired.hash)
+	}
+	if _, ok := chain.headerNodes[expired.hash]; ok {
+		t.Fatalf("expired header node %v was not removed", expired.hash)
+	}
+	newest := nodes[maxHeaderNodes]
+	if node := chain.lookupHeaderNode(&newest.hash); node != newest {
+		t.Fatalf("header node %v was not found", newest.hash)
+	}
+
+	// Expire all of the header nodes and ensure they are removed when
+	// another one is added, which expires after headerNodeExpiration.
+	for _, hNode := range chain.headerNodes {
+		hNode.expiration = time.Now().Add(-time.Second)
+	}
+	node := newFakeNode(tip, 1, 0x207fffff, time.Unix(-1, 0))
+	before := time.Now()
+	chain.addHeaderNode(node)
+	if len(chain.headerNodes) != 1 {
+		t.Fatalf("unexpected number of header nodes -- got %d, want 1",
+			len(chain.headerNodes))
+	}
+	expiration := chain.headerNodes[node.hash].expiration
+	if expiration.Before(before.Add(headerNodeExpiration)) ||
+		expiration.After(time.Now().Add(headerNodeExpiration)) {
+
+		t.Fatalf("unexpected expiration %v of new header node",
+			expiration)
+	}
+}
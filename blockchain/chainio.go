@@ -306,6 +306,12 @@ func decodeSpentTxOut(serialized []byte, stxo *spentTxOut, txVersion int32) (int
 // format comments, this function also requires the transactions that spend the
 // txouts and a utxo view that contains any remaining existing utxos in the
 // transactions referenced by the inputs to the passed transasctions.
+//
+// The view is only needed to determine the versions of the transactions
+// containing the spent txouts, which are not needed to decode the txouts
+// themselves.  Callers which only need the amounts and public key scripts may
+// pass a nil view, in which case the versions are only set when they are
+// serialized as a part of the stxos.
 func deserializeSpendJournalEntry(serialized []byte, txns []*wire.MsgTx, view *UtxoViewpoint) ([]spentTxOut, error) {
 	// Calculate the total number of stxos.
 	var numStxos int
@@ -362,8 +368,9 @@ func deserializeSpendJournalEntry(serialized []byte, txns []*wire.MsgTx, view *U
 			// described.
 			txVersion := int32(-1)
 			originHash := &txIn.PreviousOutPoint.Hash
-			entry := view.LookupEntry(originHash)
-			if entry != nil {
+			if view == nil {
+				txVersion = 0
+			} else if entry := view.LookupEntry(originHash); entry != nil {
 				txVersion = entry.Version()
 			} else if idx, ok := stxoInFlight[*originHash]; ok {
 				txVersion = stxos[idx].version
@@ -413,7 +420,7 @@ func serializeSpendJournalEntry(stxos []spentTxOut) []byte {
 // block and deserializes it into a slice of spent txout entries.  The provided
 // view MUST have the utxos referenced by all of the transactions available for
 // the passed block since that information is required to reconstruct the spent
-// txouts.
+// txouts, unless it is nil as described by deserializeSpendJournalEntry.
 func dbFetchSpendJournalEntry(dbTx database.Tx, block *vtcutil.Block, view *UtxoViewpoint) ([]spentTxOut, error) {
 	// Exclude the coinbase transaction since it can't spend anything.
 	spendBucket := dbTx.Metadata().Bucket(spendJournalBucketName)
@@ -1072,6 +1079,7 @@ func (b *BlockChain) createChainState() error {
 	blockWeight := uint64(GetBlockWeight(genesisBlock))
 	b.stateSnapshot = newBestState(node, blockSize, blockWeight, numTxns,
 		numTxns, time.Unix(node.timestamp, 0))
+	bestHeightGauge.Set(int64(node.height))
 
 	// Create the initial the database chain state including creating the
 	// necessary index buckets and inserting the genesis block.
@@ -1205,6 +1213,7 @@ func (b *BlockChain) initChainState() error {
 		numTxns := uint64(len(block.Transactions))
 		b.stateSnapshot = newBestState(tip, blockSize, blockWeight,
 			numTxns, state.totalTxns, tip.CalcPastMedianTime())
+		bestHeightGauge.Set(int64(tip.height))
 		isStateInitialized = true
 
 		return nil
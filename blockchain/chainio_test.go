@@ -436,6 +436,30 @@ func TestSpendJournalSerialization(t *testing.T) {
 				i, test.name, gotEntry, test.entry)
 			continue
 		}
+
+		// Ensure the amounts and scripts are also deserialized without
+		// a utxo view.
+		gotEntry, err = deserializeSpendJournalEntry(test.serialized,
+			test.blockTxns, nil)
+		if err != nil {
+			t.Errorf("deserializeSpendJournalEntry #%d (%s) "+
+				"unexpected error without view: %v", i,
+				test.name, err)
+			continue
+		}
+		for stxoIdx := range gotEntry {
+			stxo := &gotEntry[stxoIdx]
+			stxo.maybeDecompress(stxo.version)
+			want := &test.entry[stxoIdx]
+			if stxo.amount != want.amount ||
+				!bytes.Equal(stxo.pkScript, want.pkScript) {
+
+				t.Errorf("deserializeSpendJournalEntry #%d "+
+					"(%s) mismatched stxo %d without view "+
+					"- got %v, want %v", i, test.name,
+					stxoIdx, stxo, want)
+			}
+		}
 	}
 }
 
@@ -212,6 +212,10 @@ const (
 	// included in the block's coinbase transaction doesn't match the
 	// manually computed witness commitment.
 	ErrWitnessCommitmentMismatch
+
+	// ErrPreviousBlockUnknown indicates that the previous block of a block
+	// header which was submitted without its block data is not known.
+	ErrPreviousBlockUnknown
 )
 
 // Map of ErrorCode values back to their constant names for pretty printing.
@@ -257,6 +261,7 @@ var errorCodeStrings = map[ErrorCode]string{
 	ErrUnexpectedWitness:         "ErrUnexpectedWitness",
 	ErrInvalidWitnessCommitment:  "ErrInvalidWitnessCommitment",
 	ErrWitnessCommitmentMismatch: "ErrWitnessCommitmentMismatch",
+	ErrPreviousBlockUnknown:      "ErrPreviousBlockUnknown",
 }
 
 // String returns the ErrorCode as a human-readable name.
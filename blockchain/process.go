@@ -10,6 +10,7 @@ import (
 
 	"github.com/vertcoin/vtcd/chaincfg/chainhash"
 	"github.com/vertcoin/vtcd/database"
+	"github.com/vertcoin/vtcd/wire"
 	"github.com/vertcoin/vtcutil"
 )
 
@@ -171,7 +172,9 @@ func (b *BlockChain) ProcessBlock(block *vtcutil.Block, flags BehaviorFlags) (bo
 	}
 
 	// Perform preliminary sanity checks on the block and its transactions.
+	start := time.Now()
 	err = checkBlockSanity(block, b.chainParams.PowLimit, b.timeSource, flags)
+	blockSanitySeconds.Observe(time.Since(start).Seconds())
 	if err != nil {
 		return false, false, err
 	}
@@ -254,3 +257,67 @@ func (b *BlockChain) ProcessBlock(block *vtcutil.Block, flags BehaviorFlags) (bo
 
 	return isMainChain, false, nil
 }
+
+// ProcessBlockHeader handles a block header which was submitted without its
+// block data.  The header must pass the same proof of work, difficulty,
+// timestamp and checkpoint checks as the header of a block building on its
+// previous block, which must be a known block or a previously processed header.
+// Headers which pass the checks are kept so headers building on them can be
+// processed as well.  Processing the header of a known block or a known header
+// again is not an error.
+//
+// Since processed headers are not part of the block index, they have no effect
+// on the best chain until their blocks are processed.  At most maxHeaderNodes
+// headers are kept, each for at most headerNodeExpiration.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) ProcessBlockHeader(header *wire.BlockHeader) error {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	blockHash := header.BlockHash()
+	log.Tracef("Processing block header %v", blockHash)
+
+	exists, err := b.blockExists(&blockHash)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+	if b.lookupHeaderNode(&blockHash) != nil {
+		return nil
+	}
+
+	err = checkBlockHeaderSanity(header, b.chainParams.PowLimit,
+		b.timeSource, BFNone)
+	if err != nil {
+		return err
+	}
+
+	// The previous block must either be in the block index or be a header
+	// which was processed before.
+	prevNode := b.index.LookupNode(&header.PrevBlock)
+	if prevNode == nil {
+		prevNode = b.lookupHeaderNode(&header.PrevBlock)
+	}
+	if prevNode == nil {
+		str := fmt.Sprintf("previous block %v of header %v is unknown",
+			header.PrevBlock, blockHash)
+		return ruleError(ErrPreviousBlockUnknown, str)
+	}
+	err = b.checkBlockHeaderContext(header, prevNode, BFNone)
+	if err != nil {
+		return err
+	}
+
+	node := newBlockNode(header, prevNode.height+1)
+	node.parent = prevNode
+	node.workSum.Add(prevNode.workSum, node.workSum)
+	b.addHeaderNode(node)
+
+	log.Debugf("Accepted block header %v (height %d)", blockHash,
+		node.height)
+
+	return nil
+}
@@ -6,10 +6,12 @@ package blockchain
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/vertcoin/vtcd/chaincfg/chainhash"
 	"github.com/vertcoin/vtcd/database"
 	"github.com/vertcoin/vtcd/txscript"
+	"github.com/vertcoin/vtcd/wire"
 	"github.com/vertcoin/vtcutil"
 )
 
@@ -630,3 +632,71 @@ func (b *BlockChain) FetchUtxoEntry(txHash *chainhash.Hash) (*UtxoEntry, error)
 
 	return entry, nil
 }
+
+// ForEachUtxo invokes the passed function with the outpoint of each unspent
+// transaction output in the utxo set along with the utxo entry of its
+// transaction.  Transactions are visited in the order of the bytes of their
+// hashes, and their outputs in the order of their indices.  Iteration stops as
+// soon as the passed function returns an error, which is then returned.
+//
+// The utxo set is read from a snapshot of the database, so blocks may be
+// connected and disconnected while it is iterated.  The hash and height of the
+// best block the snapshot corresponds to are returned.
+//
+// NOTE: The passed function must not retain the utxo entries or their scripts
+// after it returns since they may reference memory owned by the database.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) ForEachUtxo(fn func(outpoint wire.OutPoint, entry *UtxoEntry) error) (*chainhash.Hash, int32, error) {
+	var state bestChainState
+	err := b.db.View(func(dbTx database.Tx) error {
+		var err error
+		state, err = deserializeBestChainState(
+			dbTx.Metadata().Get(chainStateKeyName))
+		if err != nil {
+			return err
+		}
+
+		utxoBucket := dbTx.Metadata().Bucket(utxoSetBucketName)
+		var outputIndices []int
+		return utxoBucket.ForEach(func(k, v []byte) error {
+			outpoint := wire.OutPoint{}
+			copy(outpoint.Hash[:], k)
+			entry, err := deserializeUtxoEntry(v)
+			if err != nil {
+				// Ensure any deserialization errors are
+				// returned as database corruption errors.
+				if isDeserializeErr(err) {
+					return database.Error{
+						ErrorCode: database.ErrCorruption,
+						Description: fmt.Sprintf("corrupt "+
+							"utxo entry for %v: %v",
+							outpoint.Hash, err),
+					}
+				}
+				return err
+			}
+
+			outputIndices = outputIndices[:0]
+			for outputIndex, output := range entry.sparseOutputs {
+				if !output.spent {
+					outputIndices = append(outputIndices,
+						int(outputIndex))
+				}
+			}
+			sort.Ints(outputIndices)
+			for _, outputIndex := range outputIndices {
+				outpoint.Index = uint32(outputIndex)
+				if err := fn(outpoint, entry); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return &state.hash, int32(state.height), nil
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/vertcoin/vtcd/chaincfg"
+	"github.com/vertcoin/vtcd/wire"
+)
+
+// TestForEachUtxo ensures ForEachUtxo visits every unspent output of the utxo
+// set in order along with the best block and stops as soon as the passed
+// function returns an error.
+func TestForEachUtxo(t *testing.T) {
+	blocks, err := loadBlocks("blk_0_to_4.dat.bz2")
+	if err != nil {
+		t.Fatalf("Error loading file: %v\n", err)
+	}
+
+	// Create a new database and chain instance to run tests against.
+	chain, teardownFunc, err := chainSetup("foreachutxo",
+		&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	// The outputs of the genesis coinbase are not spendable, so only the
+	// outputs of the transactions of the connected blocks are expected.
+	want := make(map[wire.OutPoint]int64)
+	for i := 1; i < len(blocks); i++ {
+		_, isOrphan, err := chain.ProcessBlock(blocks[i], BFNone)
+		if err != nil {
+			t.Fatalf("ProcessBlock fail on block %v: %v\n", i, err)
+		}
+		if isOrphan {
+			t.Fatalf("ProcessBlock incorrectly returned block %v "+
+				"is an orphan\n", i)
+		}
+
+		for _, tx := range blocks[i].Transactions() {
+			for txOutIdx, txOut := range tx.MsgTx().TxOut {
+				outpoint := wire.OutPoint{
+					Hash:  *tx.Hash(),
+					Index: uint32(txOutIdx),
+				}
+				want[outpoint] = txOut.Value
+			}
+		}
+	}
+
+	var visited []wire.OutPoint
+	hash, height, err := chain.ForEachUtxo(func(outpoint wire.OutPoint, entry *UtxoEntry) error {
+		amount, ok := want[outpoint]
+		if !ok {
+			t.Errorf("unexpected output %v visited", outpoint)
+		} else if got := entry.AmountByIndex(outpoint.Index); got != amount {
+			t.Errorf("unexpected amount of output %v - got %d, "+
+				"want %d", outpoint, got, amount)
+		}
+		visited = append(visited, outpoint)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEachUtxo: unexpected error: %v", err)
+	}
+	tip := blocks[len(blocks)-1]
+	if !hash.IsEqual(tip.Hash()) || height != tip.Height() {
+		t.Fatalf("ForEachUtxo: unexpected best block - got %v (%d), "+
+			"want %v (%d)", hash, height, tip.Hash(), tip.Height())
+	}
+	if len(visited) != len(want) {
+		t.Fatalf("ForEachUtxo: unexpected number of outputs visited - "+
+			"got %d, want %d", len(visited), len(want))
+	}
+	for i := 1; i < len(visited); i++ {
+		prev, cur := visited[i-1], visited[i]
+		cmp := bytes.Compare(prev.Hash[:], cur.Hash[:])
+		if cmp > 0 || (cmp == 0 && prev.Index >= cur.Index) {
+			t.Fatalf("ForEachUtxo: output %v visited after %v", cur,
+				prev)
+		}
+	}
+
+	// Ensure the iteration stops at the first error, which is returned.
+	errStop := errors.New("stop")
+	var calls int
+	_, _, err = chain.ForEachUtxo(func(wire.OutPoint, *UtxoEntry) error {
+		calls++
+		return errStop
+	})
+	if err != errStop {
+		t.Fatalf("ForEachUtxo: unexpected error - got %v, want %v", err,
+			errStop)
+	}
+	if calls != 1 {
+		t.Fatalf("ForEachUtxo: function called %d times after "+
+			"returning an error", calls)
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/vertcoin/vtcd/chaincfg/chainhash"
 	"github.com/vertcoin/vtcd/database"
 	"github.com/vertcoin/vtcd/mempool"
+	"github.com/vertcoin/vtcd/metrics"
 	peerpkg "github.com/vertcoin/vtcd/peer"
 	"github.com/vertcoin/vtcd/wire"
 	"github.com/vertcoin/vtcutil"
@@ -48,6 +49,25 @@ const (
 // zeroHash is the zero value hash (all zeros).  It is defined as a convenience.
 var zeroHash chainhash.Hash
 
+var (
+	// headerHeightGauge tracks the height of the best known header, which
+	// is ahead of the best block while downloading headers first.
+	headerHeightGauge = metrics.NewGauge("vtcd_chain_header_height",
+		"Height of the best known block header.")
+
+	// syncCurrentGauge, syncHeadersFirstGauge and syncPeerHeightGauge
+	// describe the sync state of the block manager.
+	syncCurrentGauge = metrics.NewGauge("vtcd_sync_current",
+		"Whether the chain is believed to be synced with the network "+
+			"(1) or not (0).")
+	syncHeadersFirstGauge = metrics.NewGauge("vtcd_sync_headers_first",
+		"Whether blocks are being downloaded in headers-first mode (1) "+
+			"or not (0).")
+	syncPeerHeightGauge = metrics.NewGauge("vtcd_sync_peer_height",
+		"Latest block height announced by the sync peer, or 0 without "+
+			"a sync peer.")
+)
+
 // newPeerMsg signifies a newly connected peer to the block handler.
 type newPeerMsg struct {
 	peer *peerpkg.Peer
@@ -144,6 +164,8 @@ type PeerNotifier interface {
 	RelayInventory(invVect *wire.InvVect, data interface{})
 
 	TransactionConfirmed(tx *vtcutil.Tx)
+
+	Misbehaving(peer *peerpkg.Peer, m misbehavior, reason string)
 }
 
 // blockManangerConfig is a configuration struct used to initialize a new
@@ -552,6 +574,8 @@ func (b *blockManager) handleBlockMsg(bmsg *blockMsg) {
 		if b.chainParams != &chaincfg.RegressionNetParams {
 			bmgrLog.Warnf("Got unrequested block %v from %s -- "+
 				"disconnecting", blockHash, peer.Addr())
+			b.peerNotifier.Misbehaving(peer,
+				misbehaviorUnrequestedData, "unrequested block")
 			peer.Disconnect()
 			return
 		}
@@ -595,9 +619,16 @@ func (b *blockManager) handleBlockMsg(bmsg *blockMsg) {
 		// rejected as opposed to something actually going wrong, so log
 		// it as such.  Otherwise, something really did go wrong, so log
 		// it as an actual error.
-		if _, ok := err.(blockchain.RuleError); ok {
+		if ruleErr, ok := err.(blockchain.RuleError); ok {
 			bmgrLog.Infof("Rejected block %v from %s: %v", blockHash,
 				peer, err)
+
+			// Penalize the peer for sending an invalid block unless
+			// the block was only rejected as a duplicate.
+			if ruleErr.ErrorCode != blockchain.ErrDuplicateBlock {
+				b.peerNotifier.Misbehaving(peer,
+					misbehaviorInvalidBlock, ruleErr.Description)
+			}
 		} else {
 			bmgrLog.Errorf("Failed to process block %v: %v",
 				blockHash, err)
@@ -805,6 +836,8 @@ func (b *blockManager) handleHeadersMsg(hmsg *headersMsg) {
 	if !b.headersFirstMode {
 		bmgrLog.Warnf("Got %d unrequested headers from %s -- "+
 			"disconnecting", numHeaders, peer.Addr())
+		b.peerNotifier.Misbehaving(peer, misbehaviorUnrequestedData,
+			"unrequested headers")
 		peer.Disconnect()
 		return
 	}
@@ -845,6 +878,9 @@ func (b *blockManager) handleHeadersMsg(hmsg *headersMsg) {
 			bmgrLog.Warnf("Received block header that does not "+
 				"properly connect to the chain from peer %s "+
 				"-- disconnecting", peer.Addr())
+			b.peerNotifier.Misbehaving(peer,
+				misbehaviorUnconnectedHeaders,
+				"header does not connect")
 			peer.Disconnect()
 			return
 		}
@@ -863,6 +899,9 @@ func (b *blockManager) handleHeadersMsg(hmsg *headersMsg) {
 					"disconnecting", node.height,
 					node.hash, peer.Addr(),
 					b.nextCheckpoint.Hash)
+				b.peerNotifier.Misbehaving(peer,
+					misbehaviorInvalidHeaders,
+					"header does not match checkpoint")
 				peer.Disconnect()
 				return
 			}
@@ -1217,6 +1256,7 @@ out:
 				bmgrLog.Warnf("Invalid message type in block "+
 					"handler: %T", msg)
 			}
+			b.updateSyncMetrics()
 
 		case <-b.quit:
 			break out
@@ -1227,6 +1267,38 @@ out:
 	bmgrLog.Trace("Block handler done")
 }
 
+// updateSyncMetrics updates the metrics describing the sync state.
+//
+// This function MUST only be called from the blockHandler goroutine.
+func (b *blockManager) updateSyncMetrics() {
+	headerHeight := b.chain.BestSnapshot().Height
+	if b.headersFirstMode {
+		if e := b.headerList.Back(); e != nil {
+			node := e.Value.(*headerNode)
+			if node.height > headerHeight {
+				headerHeight = node.height
+			}
+		}
+	}
+	headerHeightGauge.Set(int64(headerHeight))
+
+	var syncPeerHeight int32
+	if b.syncPeer != nil {
+		syncPeerHeight = b.syncPeer.LastBlock()
+	}
+	syncPeerHeightGauge.Set(int64(syncPeerHeight))
+
+	var current, headersFirst int64
+	if b.current() {
+		current = 1
+	}
+	if b.headersFirstMode {
+		headersFirst = 1
+	}
+	syncCurrentGauge.Set(current)
+	syncHeadersFirstGauge.Set(headersFirst)
+}
+
 // handleBlockchainNotification handles notifications from blockchain.  It does
 // things such as request orphan block parents and relay accepted blocks to
 // connected peers.
@@ -1260,14 +1332,14 @@ func (b *blockManager) handleBlockchainNotification(notification *blockchain.Not
 		}
 
 		// Remove all of the transactions (except the coinbase) in the
-		// connected block from the transaction pool.  Secondly, remove any
-		// transactions which are now double spends as a result of these
-		// new transactions.  Finally, remove any transaction that is
-		// no longer an orphan. Transactions which depend on a confirmed
-		// transaction are NOT removed recursively because they are still
-		// valid.
+		// connected block along with their fee deltas from the
+		// transaction pool.  Secondly, remove any transactions which are
+		// now double spends as a result of these new transactions.
+		// Finally, remove any transaction that is no longer an orphan.
+		// Transactions which depend on a confirmed transaction are NOT
+		// removed recursively because they are still valid.
 		for _, tx := range block.Transactions()[1:] {
-			b.txMemPool.RemoveTransaction(tx, false)
+			b.txMemPool.RemoveMinedTransaction(tx)
 			b.txMemPool.RemoveDoubleSpends(tx)
 			b.txMemPool.RemoveOrphan(tx)
 			b.peerNotifier.TransactionConfirmed(tx)
@@ -10,6 +10,7 @@ package btcjson
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 )
 
 // AddNodeSubCmd defines the type used in the addnode JSON-RPC command for the
@@ -44,6 +45,15 @@ func NewAddNodeCmd(addr string, subCmd AddNodeSubCmd) *AddNodeCmd {
 	}
 }
 
+// ClearBannedCmd defines the clearbanned JSON-RPC command.
+type ClearBannedCmd struct{}
+
+// NewClearBannedCmd returns a new instance which can be used to issue a
+// clearbanned JSON-RPC command.
+func NewClearBannedCmd() *ClearBannedCmd {
+	return &ClearBannedCmd{}
+}
+
 // TransactionInput represents the inputs to a transaction.  Specifically a
 // transaction hash and output number pair.
 type TransactionInput struct {
@@ -98,6 +108,37 @@ func NewDecodeScriptCmd(hexScript string) *DecodeScriptCmd {
 	}
 }
 
+// GenerateBlockCmd defines the generateblock JSON-RPC command.
+type GenerateBlockCmd struct {
+	Address      string
+	Transactions []string
+}
+
+// NewGenerateBlockCmd returns a new instance which can be used to issue a
+// generateblock JSON-RPC command.  Each transaction is either the id of a
+// transaction in the memory pool or a hex-encoded raw transaction.
+func NewGenerateBlockCmd(address string, transactions []string) *GenerateBlockCmd {
+	return &GenerateBlockCmd{
+		Address:      address,
+		Transactions: transactions,
+	}
+}
+
+// GenerateToAddressCmd defines the generatetoaddress JSON-RPC command.
+type GenerateToAddressCmd struct {
+	NumBlocks uint32
+	Address   string
+}
+
+// NewGenerateToAddressCmd returns a new instance which can be used to issue a
+// generatetoaddress JSON-RPC command.
+func NewGenerateToAddressCmd(numBlocks uint32, address string) *GenerateToAddressCmd {
+	return &GenerateToAddressCmd{
+		NumBlocks: numBlocks,
+		Address:   address,
+	}
+}
+
 // GetAddedNodeInfoCmd defines the getaddednodeinfo JSON-RPC command.
 type GetAddedNodeInfoCmd struct {
 	DNS  bool
@@ -125,11 +166,83 @@ func NewGetBestBlockHashCmd() *GetBestBlockHashCmd {
 	return &GetBestBlockHashCmd{}
 }
 
+// BlockVerbosity defines the type used in the getblock JSON-RPC command for the
+// verbosity field.
+type BlockVerbosity int
+
+const (
+	// BlockVerbosityHex requests the hex-encoded serialized block.
+	BlockVerbosityHex BlockVerbosity = 0
+
+	// BlockVerbosityJSON requests a JSON object describing the block.
+	BlockVerbosityJSON BlockVerbosity = 1
+
+	// BlockVerbosityTxPrevOut requests a JSON object describing the block
+	// along with its decoded transactions, including the previous outputs
+	// spent by their inputs and their fees.
+	BlockVerbosityTxPrevOut BlockVerbosity = 2
+)
+
+// MarshalJSON provides a custom Marshal method for BlockVerbosity.  The
+// verbosity levels which correspond to the former boolean verbose parameter
+// are marshalled as booleans so the command remains compatible with servers
+// which only support them.
+func (v BlockVerbosity) MarshalJSON() ([]byte, error) {
+	switch v {
+	case BlockVerbosityHex:
+		return json.Marshal(false)
+	case BlockVerbosityJSON:
+		return json.Marshal(true)
+	}
+	return json.Marshal(int(v))
+}
+
+// UnmarshalJSON provides a custom Unmarshal method for BlockVerbosity.  This
+// is necessary because the verbosity may also be specified as a boolean, where
+// false and true respectively mean BlockVerbosityHex and BlockVerbosityJSON.
+func (v *BlockVerbosity) UnmarshalJSON(data []byte) error {
+	var verbose bool
+	if err := json.Unmarshal(data, &verbose); err == nil {
+		*v = BlockVerbosityHex
+		if verbose {
+			*v = BlockVerbosityJSON
+		}
+		return nil
+	}
+
+	var verbosity int
+	if err := json.Unmarshal(data, &verbosity); err != nil {
+		return err
+	}
+	*v = BlockVerbosity(verbosity)
+	return nil
+}
+
+// UnmarshalText provides a custom text Unmarshal method for BlockVerbosity.
+// This allows NewCmd to accept the verbosity as either an integer or the
+// former boolean verbose parameter, such as when passed on the command line.
+func (v *BlockVerbosity) UnmarshalText(text []byte) error {
+	if verbosity, err := strconv.Atoi(string(text)); err == nil {
+		*v = BlockVerbosity(verbosity)
+		return nil
+	}
+
+	verbose, err := strconv.ParseBool(string(text))
+	if err != nil {
+		return err
+	}
+	*v = BlockVerbosityHex
+	if verbose {
+		*v = BlockVerbosityJSON
+	}
+	return nil
+}
+
 // GetBlockCmd defines the getblock JSON-RPC command.
 type GetBlockCmd struct {
 	Hash      string
-	Verbose   *bool `jsonrpcdefault:"true"`
-	VerboseTx *bool `jsonrpcdefault:"false"`
+	Verbosity *BlockVerbosity `jsonrpcdefault:"1"`
+	VerboseTx *bool           `jsonrpcdefault:"false"`
 }
 
 // NewGetBlockCmd returns a new instance which can be used to issue a getblock
@@ -137,10 +250,10 @@ type GetBlockCmd struct {
 //
 // The parameters which are pointers indicate they are optional.  Passing nil
 // for optional parameters will use the default value.
-func NewGetBlockCmd(hash string, verbose, verboseTx *bool) *GetBlockCmd {
+func NewGetBlockCmd(hash string, verbosity *BlockVerbosity, verboseTx *bool) *GetBlockCmd {
 	return &GetBlockCmd{
 		Hash:      hash,
-		Verbose:   verbose,
+		Verbosity: verbosity,
 		VerboseTx: verboseTx,
 	}
 }
@@ -191,6 +304,69 @@ func NewGetBlockHeaderCmd(hash string, verbose *bool) *GetBlockHeaderCmd {
 	}
 }
 
+// HashOrHeight defines the type used in the getblockstats JSON-RPC command to
+// identify a block either by its hash or by its height in the main chain.  It
+// is unmarshalled from a JSON string or number, and heights are marshalled
+// back to JSON numbers.
+type HashOrHeight string
+
+// Height returns the block height identified by the HashOrHeight, and whether
+// it identifies a block by height rather than by hash.
+func (h HashOrHeight) Height() (int32, bool) {
+	if len(h) == 64 {
+		return 0, false
+	}
+	height, err := strconv.ParseInt(string(h), 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return int32(height), true
+}
+
+// MarshalJSON provides a custom Marshal method for HashOrHeight.
+func (h HashOrHeight) MarshalJSON() ([]byte, error) {
+	if height, ok := h.Height(); ok {
+		return json.Marshal(height)
+	}
+	return json.Marshal(string(h))
+}
+
+// UnmarshalJSON provides a custom Unmarshal method for HashOrHeight.  This is
+// necessary because the block may be identified by either a string or a
+// number.
+func (h *HashOrHeight) UnmarshalJSON(data []byte) error {
+	var hash string
+	if err := json.Unmarshal(data, &hash); err == nil {
+		*h = HashOrHeight(hash)
+		return nil
+	}
+
+	var height int32
+	if err := json.Unmarshal(data, &height); err != nil {
+		return err
+	}
+	*h = HashOrHeight(strconv.FormatInt(int64(height), 10))
+	return nil
+}
+
+// GetBlockStatsCmd defines the getblockstats JSON-RPC command.
+type GetBlockStatsCmd struct {
+	HashOrHeight HashOrHeight
+	Stats        *[]string
+}
+
+// NewGetBlockStatsCmd returns a new instance which can be used to issue a
+// getblockstats JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetBlockStatsCmd(hashOrHeight HashOrHeight, stats *[]string) *GetBlockStatsCmd {
+	return &GetBlockStatsCmd{
+		HashOrHeight: hashOrHeight,
+		Stats:        stats,
+	}
+}
+
 // TemplateRequest is a request object as defined in BIP22
 // (https://en.bitcoin.it/wiki/BIP_0022), it is optionally provided as an
 // pointer argument to GetBlockTemplateCmd.
@@ -198,6 +374,9 @@ type TemplateRequest struct {
 	Mode         string   `json:"mode,omitempty"`
 	Capabilities []string `json:"capabilities,omitempty"`
 
+	// Rules the client supports as defined by BIP 0009.
+	Rules []string `json:"rules,omitempty"`
+
 	// Optional long polling.
 	LongPollID string `json:"longpollid,omitempty"`
 
@@ -214,6 +393,32 @@ type TemplateRequest struct {
 	// "proposal".
 	Data   string `json:"data,omitempty"`
 	WorkID string `json:"workid,omitempty"`
+
+	// Optional coinbase specification.  When provided, the returned
+	// template includes a coinbase transaction created from it.
+	Coinbase *TemplateRequestCoinbase `json:"coinbase,omitempty"`
+}
+
+// TemplateRequestCoinbaseOutput describes an output of the coinbase
+// transaction requested via TemplateRequestCoinbase.  Either Address or Script
+// identifies what the output pays to.  The output pays the fixed Amount in
+// satoshi unless Weight is set, in which case it is paid a share of the
+// coinbase value remaining after paying all fixed amounts.
+type TemplateRequestCoinbaseOutput struct {
+	Address string `json:"address,omitempty"`
+	Script  string `json:"script,omitempty"`
+	Amount  int64  `json:"amount,omitempty"`
+	Weight  uint32 `json:"weight,omitempty"`
+}
+
+// TemplateRequestCoinbase is an extension of the BIP22 template request which
+// describes the coinbase transaction of the requested block template.  Tag is
+// added to the coinbase script and each of the hex-encoded Commitments is
+// committed to in an OP_RETURN output.
+type TemplateRequestCoinbase struct {
+	Outputs     []TemplateRequestCoinbaseOutput `json:"outputs"`
+	Tag         string                          `json:"tag,omitempty"`
+	Commitments []string                        `json:"commitments,omitempty"`
 }
 
 // convertTemplateRequestField potentially converts the provided value as
@@ -345,7 +550,9 @@ func NewGetGenerateCmd() *GetGenerateCmd {
 }
 
 // GetHashesPerSecCmd defines the gethashespersec JSON-RPC command.
-type GetHashesPerSecCmd struct{}
+type GetHashesPerSecCmd struct {
+	Verbose *bool `jsonrpcdefault:"false"`
+}
 
 // NewGetHashesPerSecCmd returns a new instance which can be used to issue a
 // gethashespersec JSON-RPC command.
@@ -353,6 +560,15 @@ func NewGetHashesPerSecCmd() *GetHashesPerSecCmd {
 	return &GetHashesPerSecCmd{}
 }
 
+// NewGetHashesPerSecVerboseCmd returns a new instance which can be used to
+// issue a gethashespersec JSON-RPC command which also requests the hash rates
+// of the individual CPU mining workers.
+func NewGetHashesPerSecVerboseCmd() *GetHashesPerSecCmd {
+	return &GetHashesPerSecCmd{
+		Verbose: Bool(true),
+	}
+}
+
 // GetInfoCmd defines the getinfo JSON-RPC command.
 type GetInfoCmd struct{}
 
@@ -429,6 +645,22 @@ func NewGetNetworkHashPSCmd(numBlocks, height *int) *GetNetworkHashPSCmd {
 	}
 }
 
+// GetPeerBanScoreCmd defines the getpeerbanscore JSON-RPC command.
+type GetPeerBanScoreCmd struct {
+	ID *int32
+}
+
+// NewGetPeerBanScoreCmd returns a new instance which can be used to issue a
+// getpeerbanscore JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetPeerBanScoreCmd(id *int32) *GetPeerBanScoreCmd {
+	return &GetPeerBanScoreCmd{
+		ID: id,
+	}
+}
+
 // GetPeerInfoCmd defines the getpeerinfo JSON-RPC command.
 type GetPeerInfoCmd struct{}
 
@@ -475,6 +707,15 @@ func NewGetRawTransactionCmd(txHash string, verbose *int) *GetRawTransactionCmd
 	}
 }
 
+// GetRPCInfoCmd defines the getrpcinfo JSON-RPC command.
+type GetRPCInfoCmd struct{}
+
+// NewGetRPCInfoCmd returns a new instance which can be used to issue a
+// getrpcinfo JSON-RPC command.
+func NewGetRPCInfoCmd() *GetRPCInfoCmd {
+	return &GetRPCInfoCmd{}
+}
+
 // GetTxOutCmd defines the gettxout JSON-RPC command.
 type GetTxOutCmd struct {
 	Txid           string
@@ -567,6 +808,15 @@ func NewInvalidateBlockCmd(blockHash string) *InvalidateBlockCmd {
 	}
 }
 
+// ListBannedCmd defines the listbanned JSON-RPC command.
+type ListBannedCmd struct{}
+
+// NewListBannedCmd returns a new instance which can be used to issue a
+// listbanned JSON-RPC command.
+func NewListBannedCmd() *ListBannedCmd {
+	return &ListBannedCmd{}
+}
+
 // PingCmd defines the ping JSON-RPC command.
 type PingCmd struct{}
 
@@ -589,6 +839,21 @@ func NewPreciousBlockCmd(blockHash string) *PreciousBlockCmd {
 	}
 }
 
+// PrioritiseTransactionCmd defines the prioritisetransaction JSON-RPC command.
+type PrioritiseTransactionCmd struct {
+	Txid     string
+	FeeDelta int64
+}
+
+// NewPrioritiseTransactionCmd returns a new instance which can be used to
+// issue a prioritisetransaction JSON-RPC command.
+func NewPrioritiseTransactionCmd(txHash string, feeDelta int64) *PrioritiseTransactionCmd {
+	return &PrioritiseTransactionCmd{
+		Txid:     txHash,
+		FeeDelta: feeDelta,
+	}
+}
+
 // ReconsiderBlockCmd defines the reconsiderblock JSON-RPC command.
 type ReconsiderBlockCmd struct {
 	BlockHash string
@@ -602,6 +867,98 @@ func NewReconsiderBlockCmd(blockHash string) *ReconsiderBlockCmd {
 	}
 }
 
+// ScanTxOutSetAction defines the type used in the scantxoutset JSON-RPC command
+// for the action field.
+type ScanTxOutSetAction string
+
+const (
+	// ScanStart indicates a scan of the utxo set for the passed scan
+	// objects should be started.
+	ScanStart ScanTxOutSetAction = "start"
+
+	// ScanAbort indicates the scan in progress should be aborted.
+	ScanAbort ScanTxOutSetAction = "abort"
+
+	// ScanStatus indicates the progress of the scan in progress should be
+	// returned.
+	ScanStatus ScanTxOutSetAction = "status"
+)
+
+// ScanObject describes scripts searched by the scantxoutset JSON-RPC command
+// with an output descriptor.  Range optionally specifies the first and last
+// child indices derived by ranged descriptors.
+//
+// Scan objects without a range are marshalled to their descriptor as a JSON
+// string, and the range may be unmarshalled from either a JSON array of the
+// first and last indices or a JSON number for the last index.
+type ScanObject struct {
+	Desc  string     `json:"desc"`
+	Range *[2]uint32 `json:"range,omitempty"`
+}
+
+// MarshalJSON provides a custom Marshal method for ScanObject.
+func (o ScanObject) MarshalJSON() ([]byte, error) {
+	if o.Range == nil {
+		return json.Marshal(o.Desc)
+	}
+
+	type scanObject ScanObject
+	return json.Marshal(scanObject(o))
+}
+
+// UnmarshalJSON provides a custom Unmarshal method for ScanObject.  This is
+// necessary because a scan object may be a JSON string or a JSON object, and
+// its range a JSON array or a JSON number.
+func (o *ScanObject) UnmarshalJSON(data []byte) error {
+	var desc string
+	if err := json.Unmarshal(data, &desc); err == nil {
+		*o = ScanObject{Desc: desc}
+		return nil
+	}
+
+	var object struct {
+		Desc  string          `json:"desc"`
+		Range json.RawMessage `json:"range"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return err
+	}
+	*o = ScanObject{Desc: object.Desc}
+	if len(object.Range) == 0 {
+		return nil
+	}
+
+	var end uint32
+	if err := json.Unmarshal(object.Range, &end); err == nil {
+		o.Range = &[2]uint32{0, end}
+		return nil
+	}
+	var scanRange [2]uint32
+	if err := json.Unmarshal(object.Range, &scanRange); err != nil {
+		return err
+	}
+	o.Range = &scanRange
+	return nil
+}
+
+// ScanTxOutSetCmd defines the scantxoutset JSON-RPC command.
+type ScanTxOutSetCmd struct {
+	Action      ScanTxOutSetAction `jsonrpcusage:"\"start|abort|status\""`
+	ScanObjects *[]ScanObject      `jsonrpcusage:"[\"descriptor\",{\"desc\":\"descriptor\",\"range\":n},...]"`
+}
+
+// NewScanTxOutSetCmd returns a new instance which can be used to issue a
+// scantxoutset JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewScanTxOutSetCmd(action ScanTxOutSetAction, scanObjects *[]ScanObject) *ScanTxOutSetCmd {
+	return &ScanTxOutSetCmd{
+		Action:      action,
+		ScanObjects: scanObjects,
+	}
+}
+
 // SearchRawTransactionsCmd defines the searchrawtransactions JSON-RPC command.
 type SearchRawTransactionsCmd struct {
 	Address     string
@@ -648,6 +1005,42 @@ func NewSendRawTransactionCmd(hexTx string, allowHighFees *bool) *SendRawTransac
 	}
 }
 
+// SetBanSubCmd defines the type used in the setban JSON-RPC command for the
+// sub command field.
+type SetBanSubCmd string
+
+const (
+	// SBAdd indicates the specified subnet should be added to the ban
+	// list.
+	SBAdd SetBanSubCmd = "add"
+
+	// SBRemove indicates the specified subnet should be removed from the
+	// ban list.
+	SBRemove SetBanSubCmd = "remove"
+)
+
+// SetBanCmd defines the setban JSON-RPC command.
+type SetBanCmd struct {
+	SubNet   string
+	SubCmd   SetBanSubCmd `jsonrpcusage:"\"add|remove\""`
+	BanTime  *int64       `jsonrpcdefault:"0"`
+	Absolute *bool        `jsonrpcdefault:"false"`
+}
+
+// NewSetBanCmd returns a new instance which can be used to issue a setban
+// JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewSetBanCmd(subNet string, subCmd SetBanSubCmd, banTime *int64, absolute *bool) *SetBanCmd {
+	return &SetBanCmd{
+		SubNet:   subNet,
+		SubCmd:   subCmd,
+		BanTime:  banTime,
+		Absolute: absolute,
+	}
+}
+
 // SetGenerateCmd defines the setgenerate JSON-RPC command.
 type SetGenerateCmd struct {
 	Generate     bool
@@ -700,6 +1093,37 @@ func NewSubmitBlockCmd(hexBlock string, options *SubmitBlockOptions) *SubmitBloc
 	}
 }
 
+// SubmitHeaderCmd defines the submitheader JSON-RPC command.
+type SubmitHeaderCmd struct {
+	HexHeader string
+}
+
+// NewSubmitHeaderCmd returns a new instance which can be used to issue a
+// submitheader JSON-RPC command.
+func NewSubmitHeaderCmd(hexHeader string) *SubmitHeaderCmd {
+	return &SubmitHeaderCmd{
+		HexHeader: hexHeader,
+	}
+}
+
+// TestBlockValidityCmd defines the testblockvalidity JSON-RPC command.
+type TestBlockValidityCmd struct {
+	HexBlock string
+	CheckPoW *bool `jsonrpcdefault:"true"`
+}
+
+// NewTestBlockValidityCmd returns a new instance which can be used to issue a
+// testblockvalidity JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewTestBlockValidityCmd(hexBlock string, checkPoW *bool) *TestBlockValidityCmd {
+	return &TestBlockValidityCmd{
+		HexBlock: hexBlock,
+		CheckPoW: checkPoW,
+	}
+}
+
 // UptimeCmd defines the uptime JSON-RPC command.
 type UptimeCmd struct{}
 
@@ -774,9 +1198,12 @@ func init() {
 	flags := UsageFlag(0)
 
 	MustRegisterCmd("addnode", (*AddNodeCmd)(nil), flags)
+	MustRegisterCmd("clearbanned", (*ClearBannedCmd)(nil), flags)
 	MustRegisterCmd("createrawtransaction", (*CreateRawTransactionCmd)(nil), flags)
 	MustRegisterCmd("decoderawtransaction", (*DecodeRawTransactionCmd)(nil), flags)
 	MustRegisterCmd("decodescript", (*DecodeScriptCmd)(nil), flags)
+	MustRegisterCmd("generateblock", (*GenerateBlockCmd)(nil), flags)
+	MustRegisterCmd("generatetoaddress", (*GenerateToAddressCmd)(nil), flags)
 	MustRegisterCmd("getaddednodeinfo", (*GetAddedNodeInfoCmd)(nil), flags)
 	MustRegisterCmd("getbestblockhash", (*GetBestBlockHashCmd)(nil), flags)
 	MustRegisterCmd("getblock", (*GetBlockCmd)(nil), flags)
@@ -784,6 +1211,7 @@ func init() {
 	MustRegisterCmd("getblockcount", (*GetBlockCountCmd)(nil), flags)
 	MustRegisterCmd("getblockhash", (*GetBlockHashCmd)(nil), flags)
 	MustRegisterCmd("getblockheader", (*GetBlockHeaderCmd)(nil), flags)
+	MustRegisterCmd("getblockstats", (*GetBlockStatsCmd)(nil), flags)
 	MustRegisterCmd("getblocktemplate", (*GetBlockTemplateCmd)(nil), flags)
 	MustRegisterCmd("getcfilter", (*GetCFilterCmd)(nil), flags)
 	MustRegisterCmd("getcfilterheader", (*GetCFilterHeaderCmd)(nil), flags)
@@ -799,23 +1227,31 @@ func init() {
 	MustRegisterCmd("getnetworkinfo", (*GetNetworkInfoCmd)(nil), flags)
 	MustRegisterCmd("getnettotals", (*GetNetTotalsCmd)(nil), flags)
 	MustRegisterCmd("getnetworkhashps", (*GetNetworkHashPSCmd)(nil), flags)
+	MustRegisterCmd("getpeerbanscore", (*GetPeerBanScoreCmd)(nil), flags)
 	MustRegisterCmd("getpeerinfo", (*GetPeerInfoCmd)(nil), flags)
 	MustRegisterCmd("getrawmempool", (*GetRawMempoolCmd)(nil), flags)
 	MustRegisterCmd("getrawtransaction", (*GetRawTransactionCmd)(nil), flags)
+	MustRegisterCmd("getrpcinfo", (*GetRPCInfoCmd)(nil), flags)
 	MustRegisterCmd("gettxout", (*GetTxOutCmd)(nil), flags)
 	MustRegisterCmd("gettxoutproof", (*GetTxOutProofCmd)(nil), flags)
 	MustRegisterCmd("gettxoutsetinfo", (*GetTxOutSetInfoCmd)(nil), flags)
 	MustRegisterCmd("getwork", (*GetWorkCmd)(nil), flags)
 	MustRegisterCmd("help", (*HelpCmd)(nil), flags)
 	MustRegisterCmd("invalidateblock", (*InvalidateBlockCmd)(nil), flags)
+	MustRegisterCmd("listbanned", (*ListBannedCmd)(nil), flags)
 	MustRegisterCmd("ping", (*PingCmd)(nil), flags)
 	MustRegisterCmd("preciousblock", (*PreciousBlockCmd)(nil), flags)
+	MustRegisterCmd("prioritisetransaction", (*PrioritiseTransactionCmd)(nil), flags)
 	MustRegisterCmd("reconsiderblock", (*ReconsiderBlockCmd)(nil), flags)
+	MustRegisterCmd("scantxoutset", (*ScanTxOutSetCmd)(nil), flags)
 	MustRegisterCmd("searchrawtransactions", (*SearchRawTransactionsCmd)(nil), flags)
 	MustRegisterCmd("sendrawtransaction", (*SendRawTransactionCmd)(nil), flags)
+	MustRegisterCmd("setban", (*SetBanCmd)(nil), flags)
 	MustRegisterCmd("setgenerate", (*SetGenerateCmd)(nil), flags)
 	MustRegisterCmd("stop", (*StopCmd)(nil), flags)
 	MustRegisterCmd("submitblock", (*SubmitBlockCmd)(nil), flags)
+	MustRegisterCmd("submitheader", (*SubmitHeaderCmd)(nil), flags)
+	MustRegisterCmd("testblockvalidity", (*TestBlockValidityCmd)(nil), flags)
 	MustRegisterCmd("uptime", (*UptimeCmd)(nil), flags)
 	MustRegisterCmd("validateaddress", (*ValidateAddressCmd)(nil), flags)
 	MustRegisterCmd("verifychain", (*VerifyChainCmd)(nil), flags)
@@ -40,6 +40,17 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"addnode","params":["127.0.0.1","remove"],"id":1}`,
 			unmarshalled: &btcjson.AddNodeCmd{Addr: "127.0.0.1", SubCmd: btcjson.ANRemove},
 		},
+		{
+			name: "clearbanned",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("clearbanned")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewClearBannedCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"clearbanned","params":[],"id":1}`,
+			unmarshalled: &btcjson.ClearBannedCmd{},
+		},
 		{
 			name: "createrawtransaction",
 			newCmd: func() (interface{}, error) {
@@ -102,6 +113,36 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"decodescript","params":["00"],"id":1}`,
 			unmarshalled: &btcjson.DecodeScriptCmd{HexScript: "00"},
 		},
+		{
+			name: "generateblock",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("generateblock", "1Address",
+					[]string{"123", "0100"})
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGenerateBlockCmd("1Address",
+					[]string{"123", "0100"})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"generateblock","params":["1Address",["123","0100"]],"id":1}`,
+			unmarshalled: &btcjson.GenerateBlockCmd{
+				Address:      "1Address",
+				Transactions: []string{"123", "0100"},
+			},
+		},
+		{
+			name: "generatetoaddress",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("generatetoaddress", 1, "1Address")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGenerateToAddressCmd(1, "1Address")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"generatetoaddress","params":[1,"1Address"],"id":1}`,
+			unmarshalled: &btcjson.GenerateToAddressCmd{
+				NumBlocks: 1,
+				Address:   "1Address",
+			},
+		},
 		{
 			name: "getaddednodeinfo",
 			newCmd: func() (interface{}, error) {
@@ -149,7 +190,7 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled: `{"jsonrpc":"1.0","method":"getblock","params":["123"],"id":1}`,
 			unmarshalled: &btcjson.GetBlockCmd{
 				Hash:      "123",
-				Verbose:   btcjson.Bool(true),
+				Verbosity: btcjson.Verbosity(btcjson.BlockVerbosityJSON),
 				VerboseTx: btcjson.Bool(false),
 			},
 		},
@@ -163,12 +204,12 @@ func TestChainSvrCmds(t *testing.T) {
 				return btcjson.NewCmd("getblock", "123", &verbosePtr)
 			},
 			staticCmd: func() interface{} {
-				return btcjson.NewGetBlockCmd("123", btcjson.Bool(true), nil)
+				return btcjson.NewGetBlockCmd("123", btcjson.Verbosity(btcjson.BlockVerbosityJSON), nil)
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"getblock","params":["123",true],"id":1}`,
 			unmarshalled: &btcjson.GetBlockCmd{
 				Hash:      "123",
-				Verbose:   btcjson.Bool(true),
+				Verbosity: btcjson.Verbosity(btcjson.BlockVerbosityJSON),
 				VerboseTx: btcjson.Bool(false),
 			},
 		},
@@ -178,15 +219,60 @@ func TestChainSvrCmds(t *testing.T) {
 				return btcjson.NewCmd("getblock", "123", true, true)
 			},
 			staticCmd: func() interface{} {
-				return btcjson.NewGetBlockCmd("123", btcjson.Bool(true), btcjson.Bool(true))
+				return btcjson.NewGetBlockCmd("123", btcjson.Verbosity(btcjson.BlockVerbosityJSON), btcjson.Bool(true))
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"getblock","params":["123",true,true],"id":1}`,
 			unmarshalled: &btcjson.GetBlockCmd{
 				Hash:      "123",
-				Verbose:   btcjson.Bool(true),
+				Verbosity: btcjson.Verbosity(btcjson.BlockVerbosityJSON),
 				VerboseTx: btcjson.Bool(true),
 			},
 		},
+		{
+			name: "getblock verbosity hex",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getblock", "123", 0)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetBlockCmd("123", btcjson.Verbosity(btcjson.BlockVerbosityHex), nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getblock","params":["123",false],"id":1}`,
+			unmarshalled: &btcjson.GetBlockCmd{
+				Hash:      "123",
+				Verbosity: btcjson.Verbosity(btcjson.BlockVerbosityHex),
+				VerboseTx: btcjson.Bool(false),
+			},
+		},
+		{
+			name: "getblock verbose string",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getblock", "123", "false")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetBlockCmd("123", btcjson.Verbosity(btcjson.BlockVerbosityHex), nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getblock","params":["123",false],"id":1}`,
+			unmarshalled: &btcjson.GetBlockCmd{
+				Hash:      "123",
+				Verbosity: btcjson.Verbosity(btcjson.BlockVerbosityHex),
+				VerboseTx: btcjson.Bool(false),
+			},
+		},
+		{
+			name: "getblock verbosity prevout",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getblock", "123", 2)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetBlockCmd("123", btcjson.Verbosity(btcjson.BlockVerbosityTxPrevOut), nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getblock","params":["123",2],"id":1}`,
+			unmarshalled: &btcjson.GetBlockCmd{
+				Hash:      "123",
+				Verbosity: btcjson.Verbosity(btcjson.BlockVerbosityTxPrevOut),
+				VerboseTx: btcjson.Bool(false),
+			},
+		},
 		{
 			name: "getblockchaininfo",
 			newCmd: func() (interface{}, error) {
@@ -234,6 +320,33 @@ func TestChainSvrCmds(t *testing.T) {
 				Verbose: btcjson.Bool(true),
 			},
 		},
+		{
+			name: "getblockstats",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getblockstats", "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetBlockStatsCmd("000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getblockstats","params":["000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"],"id":1}`,
+			unmarshalled: &btcjson.GetBlockStatsCmd{
+				HashOrHeight: "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f",
+			},
+		},
+		{
+			name: "getblockstats height optional stats",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getblockstats", "123", `["totalfee","txs"]`)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetBlockStatsCmd("123", &[]string{"totalfee", "txs"})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getblockstats","params":[123,["totalfee","txs"]],"id":1}`,
+			unmarshalled: &btcjson.GetBlockStatsCmd{
+				HashOrHeight: "123",
+				Stats:        &[]string{"totalfee", "txs"},
+			},
+		},
 		{
 			name: "getblocktemplate",
 			newCmd: func() (interface{}, error) {
@@ -317,6 +430,62 @@ func TestChainSvrCmds(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "getblocktemplate optional - template request with rules",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getblocktemplate", `{"mode":"template","capabilities":["coinbasetxn"],"rules":["segwit"]}`)
+			},
+			staticCmd: func() interface{} {
+				template := btcjson.TemplateRequest{
+					Mode:         "template",
+					Capabilities: []string{"coinbasetxn"},
+					Rules:        []string{"segwit"},
+				}
+				return btcjson.NewGetBlockTemplateCmd(&template)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getblocktemplate","params":[{"mode":"template","capabilities":["coinbasetxn"],"rules":["segwit"]}],"id":1}`,
+			unmarshalled: &btcjson.GetBlockTemplateCmd{
+				Request: &btcjson.TemplateRequest{
+					Mode:         "template",
+					Capabilities: []string{"coinbasetxn"},
+					Rules:        []string{"segwit"},
+				},
+			},
+		},
+		{
+			name: "getblocktemplate optional - template request with coinbase",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getblocktemplate", `{"mode":"template","coinbase":{"outputs":[{"address":"1Address","weight":3},{"script":"51","amount":1000}],"tag":"/pool/","commitments":["deadbeef"]}}`)
+			},
+			staticCmd: func() interface{} {
+				template := btcjson.TemplateRequest{
+					Mode: "template",
+					Coinbase: &btcjson.TemplateRequestCoinbase{
+						Outputs: []btcjson.TemplateRequestCoinbaseOutput{
+							{Address: "1Address", Weight: 3},
+							{Script: "51", Amount: 1000},
+						},
+						Tag:         "/pool/",
+						Commitments: []string{"deadbeef"},
+					},
+				}
+				return btcjson.NewGetBlockTemplateCmd(&template)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getblocktemplate","params":[{"mode":"template","coinbase":{"outputs":[{"address":"1Address","weight":3},{"script":"51","amount":1000}],"tag":"/pool/","commitments":["deadbeef"]}}],"id":1}`,
+			unmarshalled: &btcjson.GetBlockTemplateCmd{
+				Request: &btcjson.TemplateRequest{
+					Mode: "template",
+					Coinbase: &btcjson.TemplateRequestCoinbase{
+						Outputs: []btcjson.TemplateRequestCoinbaseOutput{
+							{Address: "1Address", Weight: 3},
+							{Script: "51", Amount: 1000},
+						},
+						Tag:         "/pool/",
+						Commitments: []string{"deadbeef"},
+					},
+				},
+			},
+		},
 		{
 			name: "getcfilter",
 			newCmd: func() (interface{}, error) {
@@ -395,8 +564,23 @@ func TestChainSvrCmds(t *testing.T) {
 			staticCmd: func() interface{} {
 				return btcjson.NewGetHashesPerSecCmd()
 			},
-			marshalled:   `{"jsonrpc":"1.0","method":"gethashespersec","params":[],"id":1}`,
-			unmarshalled: &btcjson.GetHashesPerSecCmd{},
+			marshalled: `{"jsonrpc":"1.0","method":"gethashespersec","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetHashesPerSecCmd{
+				Verbose: btcjson.Bool(false),
+			},
+		},
+		{
+			name: "gethashespersec verbose",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("gethashespersec", true)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetHashesPerSecVerboseCmd()
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"gethashespersec","params":[true],"id":1}`,
+			unmarshalled: &btcjson.GetHashesPerSecCmd{
+				Verbose: btcjson.Bool(true),
+			},
 		},
 		{
 			name: "getinfo",
@@ -508,6 +692,32 @@ func TestChainSvrCmds(t *testing.T) {
 				Height: btcjson.Int(123),
 			},
 		},
+		{
+			name: "getpeerbanscore",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getpeerbanscore")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetPeerBanScoreCmd(nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getpeerbanscore","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetPeerBanScoreCmd{
+				ID: nil,
+			},
+		},
+		{
+			name: "getpeerbanscore optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getpeerbanscore", 7)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetPeerBanScoreCmd(btcjson.Int32(7))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getpeerbanscore","params":[7],"id":1}`,
+			unmarshalled: &btcjson.GetPeerBanScoreCmd{
+				ID: btcjson.Int32(7),
+			},
+		},
 		{
 			name: "getpeerinfo",
 			newCmd: func() (interface{}, error) {
@@ -573,6 +783,17 @@ func TestChainSvrCmds(t *testing.T) {
 				Verbose: btcjson.Int(1),
 			},
 		},
+		{
+			name: "getrpcinfo",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getrpcinfo")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetRPCInfoCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getrpcinfo","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetRPCInfoCmd{},
+		},
 		{
 			name: "gettxout",
 			newCmd: func() (interface{}, error) {
@@ -709,6 +930,17 @@ func TestChainSvrCmds(t *testing.T) {
 				BlockHash: "123",
 			},
 		},
+		{
+			name: "listbanned",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("listbanned")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewListBannedCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"listbanned","params":[],"id":1}`,
+			unmarshalled: &btcjson.ListBannedCmd{},
+		},
 		{
 			name: "ping",
 			newCmd: func() (interface{}, error) {
@@ -733,6 +965,20 @@ func TestChainSvrCmds(t *testing.T) {
 				BlockHash: "0123",
 			},
 		},
+		{
+			name: "prioritisetransaction",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("prioritisetransaction", "123", -1000)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewPrioritiseTransactionCmd("123", -1000)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"prioritisetransaction","params":["123",-1000],"id":1}`,
+			unmarshalled: &btcjson.PrioritiseTransactionCmd{
+				Txid:     "123",
+				FeeDelta: -1000,
+			},
+		},
 		{
 			name: "reconsiderblock",
 			newCmd: func() (interface{}, error) {
@@ -746,6 +992,61 @@ func TestChainSvrCmds(t *testing.T) {
 				BlockHash: "123",
 			},
 		},
+		{
+			name: "scantxoutset status",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("scantxoutset", "status")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewScanTxOutSetCmd(btcjson.ScanStatus, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"scantxoutset","params":["status"],"id":1}`,
+			unmarshalled: &btcjson.ScanTxOutSetCmd{
+				Action: btcjson.ScanStatus,
+			},
+		},
+		{
+			name: "scantxoutset start",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("scantxoutset", "start",
+					`["addr(1Address)",{"desc":"pkh(xpub/*)","range":[5,10]}]`)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewScanTxOutSetCmd(btcjson.ScanStart,
+					&[]btcjson.ScanObject{
+						{Desc: "addr(1Address)"},
+						{Desc: "pkh(xpub/*)", Range: &[2]uint32{5, 10}},
+					})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"scantxoutset","params":["start",["addr(1Address)",{"desc":"pkh(xpub/*)","range":[5,10]}]],"id":1}`,
+			unmarshalled: &btcjson.ScanTxOutSetCmd{
+				Action: btcjson.ScanStart,
+				ScanObjects: &[]btcjson.ScanObject{
+					{Desc: "addr(1Address)"},
+					{Desc: "pkh(xpub/*)", Range: &[2]uint32{5, 10}},
+				},
+			},
+		},
+		{
+			name: "scantxoutset start range end",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("scantxoutset", "start",
+					`[{"desc":"wpkh(xpub/*)","range":20}]`)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewScanTxOutSetCmd(btcjson.ScanStart,
+					&[]btcjson.ScanObject{
+						{Desc: "wpkh(xpub/*)", Range: &[2]uint32{0, 20}},
+					})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"scantxoutset","params":["start",[{"desc":"wpkh(xpub/*)","range":[0,20]}]],"id":1}`,
+			unmarshalled: &btcjson.ScanTxOutSetCmd{
+				Action: btcjson.ScanStart,
+				ScanObjects: &[]btcjson.ScanObject{
+					{Desc: "wpkh(xpub/*)", Range: &[2]uint32{0, 20}},
+				},
+			},
+		},
 		{
 			name: "searchrawtransactions",
 			newCmd: func() (interface{}, error) {
@@ -913,6 +1214,39 @@ func TestChainSvrCmds(t *testing.T) {
 				AllowHighFees: btcjson.Bool(false),
 			},
 		},
+		{
+			name: "setban",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("setban", "1.2.3.0/24", btcjson.SBAdd)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewSetBanCmd("1.2.3.0/24", btcjson.SBAdd, nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"setban","params":["1.2.3.0/24","add"],"id":1}`,
+			unmarshalled: &btcjson.SetBanCmd{
+				SubNet:   "1.2.3.0/24",
+				SubCmd:   btcjson.SBAdd,
+				BanTime:  btcjson.Int64(0),
+				Absolute: btcjson.Bool(false),
+			},
+		},
+		{
+			name: "setban optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("setban", "1.2.3.4", btcjson.SBAdd, 1700000000, true)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewSetBanCmd("1.2.3.4", btcjson.SBAdd,
+					btcjson.Int64(1700000000), btcjson.Bool(true))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"setban","params":["1.2.3.4","add",1700000000,true],"id":1}`,
+			unmarshalled: &btcjson.SetBanCmd{
+				SubNet:   "1.2.3.4",
+				SubCmd:   btcjson.SBAdd,
+				BanTime:  btcjson.Int64(1700000000),
+				Absolute: btcjson.Bool(true),
+			},
+		},
 		{
 			name: "setgenerate",
 			newCmd: func() (interface{}, error) {
@@ -985,6 +1319,48 @@ func TestChainSvrCmds(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "submitheader",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("submitheader", "112233")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewSubmitHeaderCmd("112233")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"submitheader","params":["112233"],"id":1}`,
+			unmarshalled: &btcjson.SubmitHeaderCmd{
+				HexHeader: "112233",
+			},
+		},
+		{
+			name: "testblockvalidity",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("testblockvalidity", "112233")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewTestBlockValidityCmd("112233", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"testblockvalidity","params":["112233"],"id":1}`,
+			unmarshalled: &btcjson.TestBlockValidityCmd{
+				HexBlock: "112233",
+				CheckPoW: btcjson.Bool(true),
+			},
+		},
+		{
+			name: "testblockvalidity optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("testblockvalidity", "112233", false)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewTestBlockValidityCmd("112233",
+					btcjson.Bool(false))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"testblockvalidity","params":["112233",false],"id":1}`,
+			unmarshalled: &btcjson.TestBlockValidityCmd{
+				HexBlock: "112233",
+				CheckPoW: btcjson.Bool(false),
+			},
+		},
 		{
 			name: "uptime",
 			newCmd: func() (interface{}, error) {
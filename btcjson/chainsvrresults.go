@@ -47,6 +47,28 @@ type GetBlockVerboseResult struct {
 	NextHash      string        `json:"nextblockhash,omitempty"`
 }
 
+// GetBlockVerboseTxResult models the data from the getblock command when the
+// verbosity is BlockVerbosityTxPrevOut.  The transactions are fully decoded
+// along with the previous outputs spent by their inputs and their fees.
+type GetBlockVerboseTxResult struct {
+	Hash          string               `json:"hash"`
+	Confirmations uint64               `json:"confirmations"`
+	StrippedSize  int32                `json:"strippedsize"`
+	Size          int32                `json:"size"`
+	Weight        int32                `json:"weight"`
+	Height        int64                `json:"height"`
+	Version       int32                `json:"version"`
+	VersionHex    string               `json:"versionHex"`
+	MerkleRoot    string               `json:"merkleroot"`
+	Tx            []TxRawPrevOutResult `json:"tx"`
+	Time          int64                `json:"time"`
+	Nonce         uint32               `json:"nonce"`
+	Bits          string               `json:"bits"`
+	Difficulty    float64              `json:"difficulty"`
+	PreviousHash  string               `json:"previousblockhash"`
+	NextHash      string               `json:"nextblockhash,omitempty"`
+}
+
 // CreateMultiSigResult models the data returned from the createmultisig
 // command.
 type CreateMultiSigResult struct {
@@ -63,6 +85,11 @@ type DecodeScriptResult struct {
 	P2sh      string   `json:"p2sh,omitempty"`
 }
 
+// GenerateBlockResult models the data returned from the generateblock command.
+type GenerateBlockResult struct {
+	Hash string `json:"hash"`
+}
+
 // GetAddedNodeInfoResultAddr models the data of the addresses portion of the
 // getaddednodeinfo command.
 type GetAddedNodeInfoResultAddr struct {
@@ -114,6 +141,40 @@ type GetBlockChainInfoResult struct {
 	Bip9SoftForks        map[string]*Bip9SoftForkDescription `json:"bip9_softforks"`
 }
 
+// GetBlockStatsResult models the data from the getblockstats command.  All
+// amounts are in satoshi and all fee rates are in satoshi per virtual byte.
+type GetBlockStatsResult struct {
+	AvgFee             int64    `json:"avgfee"`
+	AvgFeeRate         int64    `json:"avgfeerate"`
+	AvgTxSize          int64    `json:"avgtxsize"`
+	BlockHash          string   `json:"blockhash"`
+	FeeRatePercentiles [5]int64 `json:"feerate_percentiles"`
+	Height             int64    `json:"height"`
+	Ins                int64    `json:"ins"`
+	MaxFee             int64    `json:"maxfee"`
+	MaxFeeRate         int64    `json:"maxfeerate"`
+	MaxTxSize          int64    `json:"maxtxsize"`
+	MedianFee          int64    `json:"medianfee"`
+	MedianTime         int64    `json:"mediantime"`
+	MedianTxSize       int64    `json:"mediantxsize"`
+	MinFee             int64    `json:"minfee"`
+	MinFeeRate         int64    `json:"minfeerate"`
+	MinTxSize          int64    `json:"mintxsize"`
+	Outs               int64    `json:"outs"`
+	Subsidy            int64    `json:"subsidy"`
+	SegWitTotalSize    int64    `json:"swtotal_size"`
+	SegWitTotalWeight  int64    `json:"swtotal_weight"`
+	SegWitTxs          int64    `json:"swtxs"`
+	Time               int64    `json:"time"`
+	TotalOut           int64    `json:"total_out"`
+	TotalSize          int64    `json:"total_size"`
+	TotalWeight        int64    `json:"total_weight"`
+	TotalFee           int64    `json:"totalfee"`
+	Txs                int64    `json:"txs"`
+	UTXOIncrease       int64    `json:"utxo_increase"`
+	UTXOSizeIncrease   int64    `json:"utxo_size_inc"`
+}
+
 // GetBlockTemplateResultTx models the transactions field of the
 // getblocktemplate command.
 type GetBlockTemplateResultTx struct {
@@ -153,6 +214,11 @@ type GetBlockTemplateResult struct {
 	// Witness commitment defined in BIP 0141.
 	DefaultWitnessCommitment string `json:"default_witness_commitment,omitempty"`
 
+	// Version bits from BIP 0009.
+	Rules       []string         `json:"rules"`
+	VbAvailable map[string]int32 `json:"vbavailable"`
+	VbRequired  int32            `json:"vbrequired"`
+
 	// Optional long polling from BIP 0022.
 	LongPollID  string `json:"longpollid,omitempty"`
 	LongPollURI string `json:"longpolluri,omitempty"`
@@ -236,27 +302,51 @@ type GetNetworkInfoResult struct {
 
 // GetPeerInfoResult models the data returned from the getpeerinfo command.
 type GetPeerInfoResult struct {
-	ID             int32   `json:"id"`
-	Addr           string  `json:"addr"`
-	AddrLocal      string  `json:"addrlocal,omitempty"`
-	Services       string  `json:"services"`
-	RelayTxes      bool    `json:"relaytxes"`
-	LastSend       int64   `json:"lastsend"`
-	LastRecv       int64   `json:"lastrecv"`
-	BytesSent      uint64  `json:"bytessent"`
-	BytesRecv      uint64  `json:"bytesrecv"`
-	ConnTime       int64   `json:"conntime"`
-	TimeOffset     int64   `json:"timeoffset"`
-	PingTime       float64 `json:"pingtime"`
-	PingWait       float64 `json:"pingwait,omitempty"`
-	Version        uint32  `json:"version"`
-	SubVer         string  `json:"subver"`
-	Inbound        bool    `json:"inbound"`
-	StartingHeight int32   `json:"startingheight"`
-	CurrentHeight  int32   `json:"currentheight,omitempty"`
-	BanScore       int32   `json:"banscore"`
-	FeeFilter      int64   `json:"feefilter"`
-	SyncNode       bool    `json:"syncnode"`
+	ID                 int32   `json:"id"`
+	Addr               string  `json:"addr"`
+	AddrLocal          string  `json:"addrlocal,omitempty"`
+	Services           string  `json:"services"`
+	RelayTxes          bool    `json:"relaytxes"`
+	LastSend           int64   `json:"lastsend"`
+	LastRecv           int64   `json:"lastrecv"`
+	BytesSent          uint64  `json:"bytessent"`
+	BytesRecv          uint64  `json:"bytesrecv"`
+	ConnTime           int64   `json:"conntime"`
+	TimeOffset         int64   `json:"timeoffset"`
+	PingTime           float64 `json:"pingtime"`
+	PingWait           float64 `json:"pingwait,omitempty"`
+	Version            uint32  `json:"version"`
+	SubVer             string  `json:"subver"`
+	Inbound            bool    `json:"inbound"`
+	StartingHeight     int32   `json:"startingheight"`
+	CurrentHeight      int32   `json:"currentheight,omitempty"`
+	BanScore           int32   `json:"banscore"`
+	BanScorePersistent int32   `json:"banscorepersistent"`
+	BanScoreTransient  int32   `json:"banscoretransient"`
+	FeeFilter          int64   `json:"feefilter"`
+	SyncNode           bool    `json:"syncnode"`
+}
+
+// BanScoreEventResult models a single ban score increase returned as part of
+// the getpeerbanscore command.
+type BanScoreEventResult struct {
+	Time        int64  `json:"time"`
+	Misbehavior string `json:"misbehavior"`
+	Reason      string `json:"reason"`
+	Persistent  uint32 `json:"persistent"`
+	Transient   uint32 `json:"transient"`
+	BanScore    uint32 `json:"banscore"`
+}
+
+// GetPeerBanScoreResult models the data returned from the getpeerbanscore
+// command.
+type GetPeerBanScoreResult struct {
+	ID         int32                 `json:"id"`
+	Addr       string                `json:"addr"`
+	BanScore   uint32                `json:"banscore"`
+	Persistent uint32                `json:"persistent"`
+	Transient  uint32                `json:"transient"`
+	History    []BanScoreEventResult `json:"history"`
 }
 
 // GetRawMempoolVerboseResult models the data returned from the getrawmempool
@@ -266,6 +356,7 @@ type GetRawMempoolVerboseResult struct {
 	Size             int32    `json:"size"`
 	Vsize            int32    `json:"vsize"`
 	Fee              float64  `json:"fee"`
+	ModifiedFee      float64  `json:"modifiedfee"`
 	Time             int64    `json:"time"`
 	Height           int64    `json:"height"`
 	StartingPriority float64  `json:"startingpriority"`
@@ -273,6 +364,20 @@ type GetRawMempoolVerboseResult struct {
 	Depends          []string `json:"depends"`
 }
 
+// RPCActiveCommandResult models a command which is being serviced by the RPC
+// server returned as part of the getrpcinfo command.
+type RPCActiveCommandResult struct {
+	Method   string `json:"method"`
+	User     string `json:"user"`
+	Duration int64  `json:"duration"`
+}
+
+// GetRPCInfoResult models the data returned from the getrpcinfo command.
+type GetRPCInfoResult struct {
+	ActiveCommands []RPCActiveCommandResult `json:"active_commands"`
+	LogPath        string                   `json:"logpath"`
+}
+
 // ScriptPubKeyResult models the scriptPubKey data of a tx script.  It is
 // defined separately since it is used by multiple commands.
 type ScriptPubKeyResult struct {
@@ -293,11 +398,31 @@ type GetTxOutResult struct {
 	Coinbase      bool               `json:"coinbase"`
 }
 
+// GetNetTotalsUploadTargetResult models the upload target data returned as
+// part of the getnettotals command.
+type GetNetTotalsUploadTargetResult struct {
+	TimeFrame             int64  `json:"timeframe"`
+	Target                uint64 `json:"target"`
+	TargetReached         bool   `json:"target_reached"`
+	ServeHistoricalBlocks bool   `json:"serve_historical_blocks"`
+	BytesLeftInCycle      uint64 `json:"bytes_left_in_cycle"`
+	TimeLeftInCycle       int64  `json:"time_left_in_cycle"`
+}
+
 // GetNetTotalsResult models the data returned from the getnettotals command.
 type GetNetTotalsResult struct {
-	TotalBytesRecv uint64 `json:"totalbytesrecv"`
-	TotalBytesSent uint64 `json:"totalbytessent"`
-	TimeMillis     int64  `json:"timemillis"`
+	TotalBytesRecv uint64                         `json:"totalbytesrecv"`
+	TotalBytesSent uint64                         `json:"totalbytessent"`
+	TimeMillis     int64                          `json:"timemillis"`
+	UploadTarget   GetNetTotalsUploadTargetResult `json:"uploadtarget"`
+}
+
+// ListBannedResult models the data returned from the listbanned command.
+type ListBannedResult struct {
+	Address     string `json:"address"`
+	BannedUntil int64  `json:"banned_until"`
+	BanCreated  int64  `json:"ban_created"`
+	BanReason   string `json:"ban_reason"`
 }
 
 // ScriptSig models a signature script.  It is defined separately since it only
@@ -475,6 +600,22 @@ type GetMiningInfoResult struct {
 	NetworkHashPS      int64   `json:"networkhashps"`
 	PooledTx           uint64  `json:"pooledtx"`
 	TestNet            bool    `json:"testnet"`
+
+	WorkerHashesPerSec []WorkerHashesPerSecResult `json:"workerhashespersec,omitempty"`
+}
+
+// WorkerHashesPerSecResult models the hashing performance of a single CPU
+// mining worker returned by the gethashespersec and getmininginfo commands.
+type WorkerHashesPerSecResult struct {
+	ID           uint32 `json:"id"`
+	HashesPerSec int64  `json:"hashespersec"`
+}
+
+// GetHashesPerSecVerboseResult models the data from the gethashespersec command
+// when the verbose flag is set.
+type GetHashesPerSecVerboseResult struct {
+	HashesPerSec int64                      `json:"hashespersec"`
+	Workers      []WorkerHashesPerSecResult `json:"workers"`
 }
 
 // GetWorkResult models the data from the getwork command.
@@ -485,6 +626,18 @@ type GetWorkResult struct {
 	Target   string `json:"target"`
 }
 
+// BlockValidityResult models the data returned from the submitheader and
+// testblockvalidity commands.  When the header or block violates a consensus
+// rule, Reason holds the BIP0022 reject reason, RuleError the name of the
+// rule error code and Description the details of the violation.
+type BlockValidityResult struct {
+	Hash        string `json:"hash"`
+	Valid       bool   `json:"valid"`
+	Reason      string `json:"reason,omitempty"`
+	RuleError   string `json:"ruleerror,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
 // InfoChainResult models the data returned by the chain server getinfo command.
 type InfoChainResult struct {
 	Version         int32   `json:"version"`
@@ -516,6 +669,50 @@ type TxRawResult struct {
 	Blocktime     int64  `json:"blocktime,omitempty"`
 }
 
+// TxRawPrevOutResult models the data of a transaction returned by the getblock
+// command when the verbosity is BlockVerbosityTxPrevOut.  Fee is nil for the
+// coinbase transaction.
+type TxRawPrevOutResult struct {
+	Hex      string       `json:"hex"`
+	Txid     string       `json:"txid"`
+	Hash     string       `json:"hash,omitempty"`
+	Size     int32        `json:"size,omitempty"`
+	Vsize    int32        `json:"vsize,omitempty"`
+	Version  int32        `json:"version"`
+	LockTime uint32       `json:"locktime"`
+	Vin      []VinPrevOut `json:"vin"`
+	Vout     []Vout       `json:"vout"`
+	Fee      *float64     `json:"fee,omitempty"`
+}
+
+// ScanTxOutSetUnspent models an unspent transaction output found by the
+// scantxoutset command.
+type ScanTxOutSetUnspent struct {
+	TxID         string  `json:"txid"`
+	Vout         uint32  `json:"vout"`
+	ScriptPubKey string  `json:"scriptPubKey"`
+	Desc         string  `json:"desc"`
+	Amount       float64 `json:"amount"`
+	Height       int32   `json:"height"`
+}
+
+// ScanTxOutSetResult models the data from the scantxoutset command when the
+// action is start.
+type ScanTxOutSetResult struct {
+	Success     bool                  `json:"success"`
+	TxOuts      int64                 `json:"txouts"`
+	Height      int32                 `json:"height"`
+	BestBlock   string                `json:"bestblock"`
+	Unspents    []ScanTxOutSetUnspent `json:"unspents"`
+	TotalAmount float64               `json:"total_amount"`
+}
+
+// ScanTxOutSetStatusResult models the data from the scantxoutset command when
+// the action is status and a scan is in progress.
+type ScanTxOutSetStatusResult struct {
+	Progress float64 `json:"progress"`
+}
+
 // SearchRawTransactionsResult models the data from the searchrawtransaction
 // command.
 type SearchRawTransactionsResult struct {
@@ -58,7 +58,9 @@ func NewNotifyNewTransactionsCmd(verbose *bool) *NotifyNewTransactionsCmd {
 }
 
 // SessionCmd defines the session JSON-RPC command.
-type SessionCmd struct{}
+type SessionCmd struct {
+	SessionID *uint64
+}
 
 // NewSessionCmd returns a new instance which can be used to issue a session
 // JSON-RPC command.
@@ -66,6 +68,14 @@ func NewSessionCmd() *SessionCmd {
 	return &SessionCmd{}
 }
 
+// NewResumeSessionCmd returns a new instance which can be used to issue a
+// session JSON-RPC command that resumes the session with the passed ID.
+func NewResumeSessionCmd(sessionID uint64) *SessionCmd {
+	return &SessionCmd{
+		SessionID: &sessionID,
+	}
+}
+
 // StopNotifyNewTransactionsCmd defines the stopnotifynewtransactions JSON-RPC command.
 type StopNotifyNewTransactionsCmd struct{}
 
@@ -89,6 +89,33 @@ func TestChainSvrWsCmds(t *testing.T) {
 				Verbose: btcjson.Bool(true),
 			},
 		},
+		{
+			name: "session",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("session")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewSessionCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"session","params":[],"id":1}`,
+			unmarshalled: &btcjson.SessionCmd{},
+		},
+		{
+			name: "session optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("session", 67089679842)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewResumeSessionCmd(67089679842)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"session","params":[67089679842],"id":1}`,
+			unmarshalled: &btcjson.SessionCmd{
+				SessionID: func() *uint64 {
+					sessionID := uint64(67089679842)
+					return &sessionID
+				}(),
+			},
+		},
 		{
 			name: "stopnotifynewtransactions",
 			newCmd: func() (interface{}, error) {
@@ -5,6 +5,7 @@
 package btcjson
 
 import (
+	"encoding"
 	"encoding/json"
 	"fmt"
 	"reflect"
@@ -12,6 +13,10 @@ import (
 	"strings"
 )
 
+// textUnmarshalerType is the reflect type of the encoding.TextUnmarshaler
+// interface.
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
 // makeParams creates a slice of interface values for the given struct.
 func makeParams(rt reflect.Type, rv reflect.Value) []interface{} {
 	numFields := rt.NumField()
@@ -234,9 +239,19 @@ func baseType(arg reflect.Type) (reflect.Type, int) {
 // unmarshaling of strings into arrays, slices, structs, and maps via
 // json.Unmarshal.
 func assignField(paramNum int, fieldName string, dest reflect.Value, src reflect.Value) error {
-	// Just error now when the types have no chance of being compatible.
 	destBaseType, destIndirects := baseType(dest.Type())
 	srcBaseType, srcIndirects := baseType(src.Type())
+
+	// Let destination types which implement encoding.TextUnmarshaler parse
+	// string and bool sources themselves.
+	srcKind := srcBaseType.Kind()
+	if (srcKind == reflect.String || srcKind == reflect.Bool) &&
+		reflect.PtrTo(destBaseType).Implements(textUnmarshalerType) {
+
+		return assignTextField(paramNum, fieldName, dest, src)
+	}
+
+	// Just error now when the types have no chance of being compatible.
 	if !typesMaybeCompatible(destBaseType, srcBaseType) {
 		str := fmt.Sprintf("parameter #%d '%s' must be type %v (got "+
 			"%v)", paramNum, fieldName, destBaseType, srcBaseType)
@@ -482,6 +497,34 @@ func assignField(paramNum int, fieldName string, dest reflect.Value, src reflect
 	return nil
 }
 
+// assignTextField sets the destination field, which must be of a type that
+// implements encoding.TextUnmarshaler, to the passed string or bool source
+// value by calling its UnmarshalText method.
+func assignTextField(paramNum int, fieldName string, dest reflect.Value, src reflect.Value) error {
+	// Indirect through to the base source value.
+	for src.Kind() == reflect.Ptr {
+		src = src.Elem()
+	}
+	text := src.String()
+	if src.Kind() == reflect.Bool {
+		text = strconv.FormatBool(src.Bool())
+	}
+
+	// Make any pointers needed to get to the base dest type.
+	for dest.Kind() == reflect.Ptr {
+		dest.Set(reflect.New(dest.Type().Elem()))
+		dest = dest.Elem()
+	}
+
+	unmarshaler := dest.Addr().Interface().(encoding.TextUnmarshaler)
+	if err := unmarshaler.UnmarshalText([]byte(text)); err != nil {
+		str := fmt.Sprintf("parameter #%d '%s' must parse to a %v",
+			paramNum, fieldName, dest.Type())
+		return makeError(ErrInvalidType, str)
+	}
+	return nil
+}
+
 // NewCmd provides a generic mechanism to create a new command that can marshal
 // to a JSON-RPC request while respecting the requirements of the provided
 // method.  The method must have been registered with the package already along
@@ -508,6 +551,8 @@ func assignField(paramNum int, fieldName string, dest reflect.Value, src reflect
 //   - Conversion from string to arrays, slices, structs, and maps by treating
 //     the string as marshalled JSON and calling json.Unmarshal into the
 //     destination field
+//   - Conversion from string or boolean to any type which implements
+//     encoding.TextUnmarshaler by calling its UnmarshalText method
 func NewCmd(method string, args ...interface{}) (interface{}, error) {
 	// Look up details about the provided method.  Any methods that aren't
 	// registered are an error.
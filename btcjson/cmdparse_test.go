@@ -381,6 +381,12 @@ func TestNewCmdErrors(t *testing.T) {
 			args:   []interface{}{1},
 			err:    btcjson.Error{ErrorCode: btcjson.ErrInvalidType},
 		},
+		{
+			name:   "invalid text unmarshaler parameter",
+			method: "getblock",
+			args:   []interface{}{"123", "bogus"},
+			err:    btcjson.Error{ErrorCode: btcjson.ErrInvalidType},
+		},
 	}
 
 	t.Logf("Running %d tests", len(tests))
@@ -31,6 +31,12 @@ the wire:
     {"result":SOMETHING,"error":null,"id":"SOMEID"}
     {"result":null,"error":{"code":SOMEINT,"message":SOMESTRING},"id":"SOMEID"}
 
+JSON-RPC 2.0 requests set the jsonrpc field to "2.0".  The responses to them
+carry the same jsonrpc field and contain exactly one of the result and error
+fields.  Several requests may also be sent together as a JSON array, known as a
+batch, in which case the responses are returned as an array as well.  The
+MarshalResponseVersion and IsBatchRequest functions support these forms.
+
 For requests, the params field can vary in what it contains depending on the
 method (a.k.a. command) being sent.  Each parameter can be as simple as an int
 or a complex structure containing many nested fields.  The id field is used to
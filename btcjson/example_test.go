@@ -17,11 +17,12 @@ func ExampleMarshalCmd() {
 	// Create a new getblock command.  Notice the nil parameter indicates
 	// to use the default parameter for that fields.  This is a common
 	// pattern used in all of the New<Foo>Cmd functions in this package for
-	// optional fields.  Also, notice the call to btcjson.Bool which is a
-	// convenience function for creating a pointer out of a primitive for
-	// optional parameters.
+	// optional fields.  Also, notice the call to btcjson.Verbosity which
+	// is a convenience function for creating a pointer out of a primitive
+	// for optional parameters.
 	blockHash := "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"
-	gbCmd := btcjson.NewGetBlockCmd(blockHash, btcjson.Bool(false), nil)
+	gbCmd := btcjson.NewGetBlockCmd(blockHash,
+		btcjson.Verbosity(btcjson.BlockVerbosityHex), nil)
 
 	// Marshal the command to the format suitable for sending to the RPC
 	// server.  Typically the client would increment the id here which is
@@ -84,12 +85,12 @@ func ExampleUnmarshalCmd() {
 
 	// Display the fields in the concrete command.
 	fmt.Println("Hash:", gbCmd.Hash)
-	fmt.Println("Verbose:", *gbCmd.Verbose)
+	fmt.Println("Verbosity:", *gbCmd.Verbosity)
 	fmt.Println("VerboseTx:", *gbCmd.VerboseTx)
 
 	// Output:
 	// Hash: 000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f
-	// Verbose: false
+	// Verbosity: 0
 	// VerboseTx: false
 }
 
@@ -75,3 +75,12 @@ func String(v string) *string {
 	*p = v
 	return p
 }
+
+// Verbosity is a helper routine that allocates a new BlockVerbosity value to
+// store v and returns a pointer to it.  This is useful when assigning optional
+// parameters.
+func Verbosity(v BlockVerbosity) *BlockVerbosity {
+	p := new(BlockVerbosity)
+	*p = v
+	return p
+}
@@ -101,6 +101,16 @@ func TestHelpers(t *testing.T) {
 				return &val
 			}(),
 		},
+		{
+			name: "verbosity",
+			f: func() interface{} {
+				return btcjson.Verbosity(btcjson.BlockVerbosityTxPrevOut)
+			},
+			expected: func() interface{} {
+				val := btcjson.BlockVerbosityTxPrevOut
+				return &val
+			}(),
+		},
 	}
 
 	t.Logf("Running %d tests", len(tests))
@@ -5,10 +5,19 @@
 package btcjson
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 )
 
+// JSON-RPC protocol versions which may be indicated by the jsonrpc member of
+// requests and responses.  Requests which do not specify a version are treated
+// as JSON-RPC 1.0 requests.
+const (
+	RPCVersion1 = "1.0"
+	RPCVersion2 = "2.0"
+)
+
 // RPCErrorCode represents an error code to be used as a part of an RPCError
 // which is in turn used in a JSON-RPC Response object.
 //
@@ -98,21 +107,38 @@ func NewRequest(id interface{}, method string, params []interface{}) (*Request,
 	}
 
 	return &Request{
-		Jsonrpc: "1.0",
+		Jsonrpc: RPCVersion1,
 		ID:      id,
 		Method:  method,
 		Params:  rawParams,
 	}, nil
 }
 
+// IsBatchRequest returns whether the passed raw JSON-RPC message is a batch of
+// requests, that is, a JSON array rather than a single request object.
+func IsBatchRequest(msg []byte) bool {
+	msg = bytes.TrimLeft(msg, " \t\r\n")
+	return len(msg) > 0 && msg[0] == '['
+}
+
 // Response is the general form of a JSON-RPC response.  The type of the Result
 // field varies from one command to the next, so it is implemented as an
 // interface.  The ID field has to be a pointer for Go to put a null in it when
-// empty.
+// empty.  The Jsonrpc field is only set for JSON-RPC 2.0 responses.
 type Response struct {
-	Result json.RawMessage `json:"result"`
-	Error  *RPCError       `json:"error"`
-	ID     *interface{}    `json:"id"`
+	Jsonrpc string          `json:"jsonrpc,omitempty"`
+	Result  json.RawMessage `json:"result"`
+	Error   *RPCError       `json:"error"`
+	ID      *interface{}    `json:"id"`
+}
+
+// jsonrpc2Response is the wire form of a JSON-RPC 2.0 response.  Unlike
+// JSON-RPC 1.0, exactly one of the result and error members must be present.
+type jsonrpc2Response struct {
+	Jsonrpc string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+	ID      *interface{}    `json:"id"`
 }
 
 // NewResponse returns a new JSON-RPC response object given the provided id,
@@ -148,3 +174,32 @@ func MarshalResponse(id interface{}, result interface{}, rpcErr *RPCError) ([]by
 	}
 	return json.Marshal(&response)
 }
+
+// MarshalResponseVersion marshals the passed id, result, and RPCError to a
+// JSON-RPC response byte slice using the envelope of the passed protocol
+// version.  JSON-RPC 2.0 responses carry the jsonrpc member and omit the result
+// member on error and the error member on success.  Any other version produces
+// the same JSON-RPC 1.0 response as MarshalResponse.
+func MarshalResponseVersion(rpcVersion string, id interface{}, result interface{}, rpcErr *RPCError) ([]byte, error) {
+	if rpcVersion != RPCVersion2 {
+		return MarshalResponse(id, result, rpcErr)
+	}
+
+	if !IsValidIDType(id) {
+		str := fmt.Sprintf("the id of type '%T' is invalid", id)
+		return nil, makeError(ErrInvalidType, str)
+	}
+	response := jsonrpc2Response{
+		Jsonrpc: RPCVersion2,
+		Error:   rpcErr,
+		ID:      &id,
+	}
+	if rpcErr == nil {
+		marshalledResult, err := json.Marshal(result)
+		if err != nil {
+			return nil, err
+		}
+		response.Result = marshalledResult
+	}
+	return json.Marshal(&response)
+}
@@ -98,6 +98,109 @@ func TestMarshalResponse(t *testing.T) {
 	}
 }
 
+// TestMarshalResponseVersion ensures the MarshalResponseVersion function
+// produces the envelope of the requested JSON-RPC version.
+func TestMarshalResponseVersion(t *testing.T) {
+	t.Parallel()
+
+	testID := 1
+	tests := []struct {
+		name     string
+		version  string
+		result   interface{}
+		jsonErr  *btcjson.RPCError
+		expected []byte
+	}{
+		{
+			name:     "unspecified version result",
+			version:  "",
+			result:   true,
+			jsonErr:  nil,
+			expected: []byte(`{"result":true,"error":null,"id":1}`),
+		},
+		{
+			name:     "1.0 result",
+			version:  btcjson.RPCVersion1,
+			result:   true,
+			jsonErr:  nil,
+			expected: []byte(`{"result":true,"error":null,"id":1}`),
+		},
+		{
+			name:     "2.0 result",
+			version:  btcjson.RPCVersion2,
+			result:   true,
+			jsonErr:  nil,
+			expected: []byte(`{"jsonrpc":"2.0","result":true,"id":1}`),
+		},
+		{
+			name:     "2.0 null result",
+			version:  btcjson.RPCVersion2,
+			result:   nil,
+			jsonErr:  nil,
+			expected: []byte(`{"jsonrpc":"2.0","result":null,"id":1}`),
+		},
+		{
+			name:    "2.0 error",
+			version: btcjson.RPCVersion2,
+			result:  nil,
+			jsonErr: func() *btcjson.RPCError {
+				return btcjson.NewRPCError(btcjson.ErrRPCBlockNotFound, "123 not found")
+			}(),
+			expected: []byte(`{"jsonrpc":"2.0","error":{"code":-5,"message":"123 not found"},"id":1}`),
+		},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		marshalled, err := btcjson.MarshalResponseVersion(test.version,
+			testID, test.result, test.jsonErr)
+		if err != nil {
+			t.Errorf("Test #%d (%s) unexpected error: %v", i,
+				test.name, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(marshalled, test.expected) {
+			t.Errorf("Test #%d (%s) mismatched result - got %s, "+
+				"want %s", i, test.name, marshalled,
+				test.expected)
+		}
+	}
+
+	// Force an error by giving it an id type that is not supported.
+	_, err := btcjson.MarshalResponseVersion(btcjson.RPCVersion2,
+		make(chan int), nil, nil)
+	if jerr, ok := err.(btcjson.Error); !ok || jerr.ErrorCode != btcjson.ErrInvalidType {
+		t.Errorf("MarshalResponseVersion: did not receive expected "+
+			"error - got %v (%[1]T)", err)
+	}
+}
+
+// TestIsBatchRequest ensures the IsBatchRequest function detects batches.
+func TestIsBatchRequest(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		msg   string
+		batch bool
+	}{
+		{`{"jsonrpc":"1.0","method":"getblockcount","params":[],"id":1}`, false},
+		{`[{"jsonrpc":"2.0","method":"getblockcount","id":1}]`, true},
+		{" \r\n\t[]", true},
+		{"", false},
+		{"   ", false},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		batch := btcjson.IsBatchRequest([]byte(test.msg))
+		if batch != test.batch {
+			t.Errorf("Test #%d (%q) batch mismatch - got %v, "+
+				"want %v", i, test.msg, batch, test.batch)
+		}
+	}
+}
+
 // TestMiscErrors tests a few error conditions not covered elsewhere.
 func TestMiscErrors(t *testing.T) {
 	t.Parallel()
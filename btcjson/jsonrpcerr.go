@@ -39,6 +39,7 @@ const (
 	ErrRPCDatabase            RPCErrorCode = -20
 	ErrRPCDeserialization     RPCErrorCode = -22
 	ErrRPCVerify              RPCErrorCode = -25
+	ErrRPCRateLimited         RPCErrorCode = -38
 )
 
 // Peer-to-peer client errors.
@@ -46,6 +47,8 @@ const (
 	ErrRPCClientNotConnected      RPCErrorCode = -9
 	ErrRPCClientInInitialDownload RPCErrorCode = -10
 	ErrRPCClientNodeNotAdded      RPCErrorCode = -24
+	ErrRPCClientNodeNotConnected  RPCErrorCode = -29
+	ErrRPCClientInvalidIPOrSubnet RPCErrorCode = -30
 )
 
 // Wallet JSON errors
@@ -107,6 +107,29 @@ const (
 	DefinedDeployments
 )
 
+// DeploymentInfo describes how a rule change deployment is referred to by the
+// RPC server.
+type DeploymentInfo struct {
+	// Name is the name of the deployment as used by the getblocktemplate
+	// rules and the getblockchaininfo soft-fork descriptions.
+	Name string
+
+	// GBTForce indicates the rules of the deployment do not affect the
+	// block template in a way which requires explicit support by
+	// getblocktemplate clients.  Clients which don't support a deployment
+	// without this flag are rejected once it is active as defined by
+	// BIP0009.
+	GBTForce bool
+}
+
+// DeploymentInfos provides the description of each defined deployment indexed
+// by its deployment ID.
+var DeploymentInfos = [DefinedDeployments]DeploymentInfo{
+	DeploymentTestDummy: {Name: "dummy", GBTForce: true},
+	DeploymentCSV:       {Name: "csv", GBTForce: true},
+	DeploymentSegwit:    {Name: "segwit", GBTForce: false},
+}
+
 // Params defines a Litecoin network by its parameters.  These parameters may be
 // used by Litecoin applications to differentiate networks as well as addresses
 // and keys for one network from those intended for use on another network.
@@ -33,6 +33,10 @@ var (
 	defaultRPCServer      = "localhost"
 	defaultRPCCertFile    = filepath.Join(ltcdHomeDir, "rpc.cert")
 	defaultWalletCertFile = filepath.Join(ltcwalletHomeDir, "rpc.cert")
+
+	// vtcdDataDir is the default data directory of the RPC server, which
+	// holds the RPC authentication cookie of each network.
+	vtcdDataDir = filepath.Join(vtcutil.AppDataDir("vtcd", false), "data")
 )
 
 // listCommands categorizes and lists all of the usable commands along with
@@ -92,26 +96,28 @@ func listCommands() {
 //
 // See loadConfig for details on the configuration load process.
 type config struct {
-	ShowVersion   bool   `short:"V" long:"version" description:"Display version information and exit"`
-	ListCommands  bool   `short:"l" long:"listcommands" description:"List all of the supported commands and exit"`
-	ConfigFile    string `short:"C" long:"configfile" description:"Path to configuration file"`
-	RPCUser       string `short:"u" long:"rpcuser" description:"RPC username"`
-	RPCPassword   string `short:"P" long:"rpcpass" default-mask:"-" description:"RPC password"`
-	RPCServer     string `short:"s" long:"rpcserver" description:"RPC server to connect to"`
-	RPCCert       string `short:"c" long:"rpccert" description:"RPC server certificate chain for validation"`
-	NoTLS         bool   `long:"notls" description:"Disable TLS"`
-	Proxy         string `long:"proxy" description:"Connect via SOCKS5 proxy (eg. 127.0.0.1:9050)"`
-	ProxyUser     string `long:"proxyuser" description:"Username for proxy server"`
-	ProxyPass     string `long:"proxypass" default-mask:"-" description:"Password for proxy server"`
-	TestNet4      bool   `long:"testnet" description:"Connect to testnet"`
-	SimNet        bool   `long:"simnet" description:"Connect to the simulation test network"`
-	TLSSkipVerify bool   `long:"skipverify" description:"Do not verify tls certificates (not recommended!)"`
-	Wallet        bool   `long:"wallet" description:"Connect to wallet"`
+	ShowVersion    bool   `short:"V" long:"version" description:"Display version information and exit"`
+	ListCommands   bool   `short:"l" long:"listcommands" description:"List all of the supported commands and exit"`
+	ConfigFile     string `short:"C" long:"configfile" description:"Path to configuration file"`
+	RPCUser        string `short:"u" long:"rpcuser" description:"RPC username"`
+	RPCPassword    string `short:"P" long:"rpcpass" default-mask:"-" description:"RPC password"`
+	RPCCookie      string `long:"rpccookie" description:"RPC authentication cookie file to read the credentials from when no rpcpass is specified"`
+	RPCServer      string `short:"s" long:"rpcserver" description:"RPC server to connect to"`
+	RPCCert        string `short:"c" long:"rpccert" description:"RPC server certificate chain for validation"`
+	NoTLS          bool   `long:"notls" description:"Disable TLS"`
+	Proxy          string `long:"proxy" description:"Connect via SOCKS5 proxy (eg. 127.0.0.1:9050)"`
+	ProxyUser      string `long:"proxyuser" description:"Username for proxy server"`
+	ProxyPass      string `long:"proxypass" default-mask:"-" description:"Password for proxy server"`
+	TestNet4       bool   `long:"testnet" description:"Connect to testnet"`
+	RegressionTest bool   `long:"regtest" description:"Connect to the regression test network"`
+	SimNet         bool   `long:"simnet" description:"Connect to the simulation test network"`
+	TLSSkipVerify  bool   `long:"skipverify" description:"Do not verify tls certificates (not recommended!)"`
+	Wallet         bool   `long:"wallet" description:"Connect to wallet"`
 }
 
 // normalizeAddress returns addr with the passed default port appended if
 // there is not already a port specified.
-func normalizeAddress(addr string, useTestNet4, useSimNet, useWallet bool) string {
+func normalizeAddress(addr string, useTestNet4, useRegTest, useSimNet, useWallet bool) string {
 	_, _, err := net.SplitHostPort(addr)
 	if err != nil {
 		var defaultPort string
@@ -122,6 +128,12 @@ func normalizeAddress(addr string, useTestNet4, useSimNet, useWallet bool) strin
 			} else {
 				defaultPort = "19334"
 			}
+		case useRegTest:
+			if useWallet {
+				defaultPort = "19332"
+			} else {
+				defaultPort = "19334"
+			}
 		case useSimNet:
 			if useWallet {
 				defaultPort = "18554"
@@ -141,6 +153,36 @@ func normalizeAddress(addr string, useTestNet4, useSimNet, useWallet bool) strin
 	return addr
 }
 
+// defaultCookieFile returns the path of the RPC authentication cookie file the
+// RPC server writes to its default data directory for the selected network.
+// The network directory names match the ones the RPC server uses.
+func defaultCookieFile(useTestNet4, useRegTest, useSimNet bool) string {
+	netName := "vtc"
+	switch {
+	case useTestNet4:
+		netName = "testnet"
+	case useRegTest:
+		netName = "regtest"
+	case useSimNet:
+		netName = "simnet"
+	}
+	return filepath.Join(vtcdDataDir, netName, ".cookie")
+}
+
+// readCookieFile returns the username and password stored in the RPC
+// authentication cookie file at the passed path.
+func readCookieFile(path string) (string, string, error) {
+	cookie, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", "", err
+	}
+	parts := strings.SplitN(strings.TrimSpace(string(cookie)), ":", 2)
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("malformed cookie file %s", path)
+	}
+	return parts[0], parts[1], nil
+}
+
 // cleanAndExpandPath expands environement variables and leading ~ in the
 // passed path, cleans the result, and returns it.
 func cleanAndExpandPath(path string) string {
@@ -251,12 +293,15 @@ func loadConfig() (*config, []string, error) {
 	if cfg.TestNet4 {
 		numNets++
 	}
+	if cfg.RegressionTest {
+		numNets++
+	}
 	if cfg.SimNet {
 		numNets++
 	}
 	if numNets > 1 {
-		str := "%s: The testnet and simnet params can't be used " +
-			"together -- choose one of the two"
+		str := "%s: The testnet, regtest, and simnet params can't be " +
+			"used together -- choose one of the three"
 		err := fmt.Errorf(str, "loadConfig")
 		fmt.Fprintln(os.Stderr, err)
 		return nil, nil, err
@@ -271,10 +316,31 @@ func loadConfig() (*config, []string, error) {
 	// Handle environment variable expansion in the RPC certificate path.
 	cfg.RPCCert = cleanAndExpandPath(cfg.RPCCert)
 
+	// Read the credentials from the authentication cookie of the RPC
+	// server when no password was specified.  A missing default cookie is
+	// not an error since the server might not use cookie authentication.
+	if cfg.RPCPassword == "" && !cfg.Wallet {
+		cookiePath := cfg.RPCCookie
+		if cookiePath == "" {
+			cookiePath = defaultCookieFile(cfg.TestNet4,
+				cfg.RegressionTest, cfg.SimNet)
+		}
+		user, pass, err := readCookieFile(cleanAndExpandPath(cookiePath))
+		switch {
+		case err == nil:
+			cfg.RPCUser, cfg.RPCPassword = user, pass
+		case cfg.RPCCookie != "":
+			err := fmt.Errorf("%s: unable to read RPC cookie: %v",
+				"loadConfig", err)
+			fmt.Fprintln(os.Stderr, err)
+			return nil, nil, err
+		}
+	}
+
 	// Add default port to RPC server based on --testnet and --wallet flags
 	// if needed.
 	cfg.RPCServer = normalizeAddress(cfg.RPCServer, cfg.TestNet4,
-		cfg.SimNet, cfg.Wallet)
+		cfg.RegressionTest, cfg.SimNet, cfg.Wallet)
 
 	return &cfg, remainingArgs, nil
 }
@@ -29,6 +29,9 @@ import (
 	"github.com/vertcoin/vtcd/database"
 	_ "github.com/vertcoin/vtcd/database/ffldb"
 	"github.com/vertcoin/vtcd/mempool"
+	"github.com/vertcoin/vtcd/mining"
+	"github.com/vertcoin/vtcd/txscript"
+	"github.com/vertcoin/vtcd/zmq"
 	"github.com/vertcoin/vtcutil"
 )
 
@@ -45,6 +48,11 @@ const (
 	defaultMaxRPCClients         = 10
 	defaultMaxRPCWebsockets      = 25
 	defaultMaxRPCConcurrentReqs  = 20
+	defaultMaxRPCBatchSize       = 1000
+	defaultRPCWSQueueSize        = 1000
+	defaultRPCWSQueuePolicy      = wsQueuePolicyDisconnect
+	defaultRPCWSSessionGrace     = time.Minute
+	defaultZMQPubHWM             = zmq.DefaultHighWaterMark
 	defaultDbType                = "ffldb"
 	defaultFreeTxRelayLimit      = 15.0
 	defaultBlockMinSize          = 0
@@ -56,12 +64,17 @@ const (
 	blockMaxWeightMin            = 4000
 	blockMaxWeightMax            = blockchain.MaxBlockWeight - 4000
 	defaultGenerate              = false
+	defaultStratumPort           = "3333"
+	defaultStratumDifficulty     = 1.0
+	defaultMetricsPort           = "9466"
 	defaultMaxOrphanTransactions = 100
 	defaultMaxOrphanTxSize       = 100000
+	defaultMaxMemPool            = mempool.DefaultMaxPoolSize / 1000000
 	defaultSigCacheMaxSize       = 100000
 	sampleConfigFilename         = "sample-vtcd.conf"
 	defaultTxIndex               = false
 	defaultAddrIndex             = false
+	defaultTorControl            = "127.0.0.1:9051"
 )
 
 var (
@@ -87,6 +100,23 @@ func minUint32(a, b uint32) uint32 {
 	return b
 }
 
+// optionalBool is a boolean option which, unlike a plain bool option, accepts
+// a value.  This allows options which are enabled by default to be disabled
+// with a value such as --datacarrier=0.  Passing the option without a value
+// enables it.
+type optionalBool bool
+
+// UnmarshalFlag parses the passed option value as a boolean.  It implements the
+// flags.Unmarshaler interface.
+func (b *optionalBool) UnmarshalFlag(value string) error {
+	v, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+	*b = optionalBool(v)
+	return nil
+}
+
 // config defines the configuration options for vtcd.
 //
 // See loadConfig for details on the configuration load process.
@@ -103,19 +133,41 @@ type config struct {
 	DisableBanning       bool          `long:"nobanning" description:"Disable banning of misbehaving peers"`
 	BanDuration          time.Duration `long:"banduration" description:"How long to ban misbehaving peers.  Valid time units are {s, m, h}.  Minimum 1 second"`
 	BanThreshold         uint32        `long:"banthreshold" description:"Maximum allowed ban score before disconnecting and banning misbehaving peers."`
+	BanWeights           []string      `long:"banweight" description:"Override the ban score weight of a misbehavior in the form <misbehavior>:<persistent>:<transient> (eg. invalidaddr:50:0)"`
+	Whitelists           []string      `long:"whitelist" description:"Add an IP network or IP that will not be banned or limited by the upload target. (eg. 192.168.1.0/24 or ::1)"`
+	MaxUploadTarget      uint64        `long:"maxuploadtarget" description:"Try to keep outbound traffic under the given target in MiB per 24h -- Historical blocks are no longer served to non-whitelisted peers once it is reached (0 = no limit)"`
+	MaxPeerUploadRate    uint64        `long:"maxpeeruploadrate" description:"Maximum rate in KiB/s at which data is sent to each non-whitelisted peer (0 = no limit)"`
 	RPCUser              string        `short:"u" long:"rpcuser" description:"Username for RPC connections"`
 	RPCPass              string        `short:"P" long:"rpcpass" default-mask:"-" description:"Password for RPC connections"`
 	RPCLimitUser         string        `long:"rpclimituser" description:"Username for limited RPC connections"`
 	RPCLimitPass         string        `long:"rpclimitpass" default-mask:"-" description:"Password for limited RPC connections"`
+	RPCAuth              []string      `long:"rpcauth" description:"Hashed credentials of an additional RPC user in the form USER:SALT$HMAC, where HMAC is the hex encoded HMAC-SHA256 of the password keyed by SALT -- may be specified multiple times"`
+	RPCAllowList         []string      `long:"rpcallowlist" description:"Restrict an RPC user to a comma separated list of methods in the form USER:METHOD,METHOD,... -- may be specified multiple times"`
+	RPCRateLimits        []string      `long:"rpcratelimit" description:"Limit the rate at which RPC users may call methods in the form USER:METHOD:RATE[:BURST], where USER and METHOD may be * to match all users and methods and RATE is in calls per second -- may be specified multiple times"`
+	RPCCookieFile        string        `long:"rpccookiefile" description:"File the RPC authentication cookie is written to when no rpcpass is specified (default: .cookie in the data directory)"`
 	RPCListeners         []string      `long:"rpclisten" description:"Add an interface/port to listen for RPC connections (default port: 9334, testnet: 19334)"`
+	GRPCListeners        []string      `long:"grpclisten" description:"Add an interface/port to listen for gRPC connections (default port: 5890, testnet: 15890) -- The gRPC server uses the credentials and TLS settings of the RPC server"`
 	RPCCert              string        `long:"rpccert" description:"File containing the certificate file"`
 	RPCKey               string        `long:"rpckey" description:"File containing the certificate key"`
 	RPCMaxClients        int           `long:"rpcmaxclients" description:"Max number of RPC clients for standard connections"`
 	RPCMaxWebsockets     int           `long:"rpcmaxwebsockets" description:"Max number of RPC websocket connections"`
 	RPCMaxConcurrentReqs int           `long:"rpcmaxconcurrentreqs" description:"Max number of concurrent RPC requests that may be processed concurrently"`
+	RPCMaxBatchSize      int           `long:"rpcmaxbatchsize" description:"Max number of requests in a single JSON-RPC batch"`
+	RPCWSQueueSize       int           `long:"rpcwsqueuesize" description:"Max number of notifications queued for an RPC websocket client, and kept for replay when its session is resumed"`
+	RPCWSQueuePolicy     string        `long:"rpcwsqueuepolicy" description:"Action taken when the notification queue of an RPC websocket client is full: disconnect the client, or coalesce consecutive blockconnected notifications before disconnecting it {disconnect, coalesce}"`
+	RPCWSSessionGrace    time.Duration `long:"rpcwssessiongrace" description:"Time a disconnected RPC websocket client may resume its session to keep its notification requests and receive the notifications it missed -- 0 to disable"`
+	RPCAuditLog          bool          `long:"rpcauditlog" description:"Record every RPC call in the file rpcaudit.log in the log directory"`
 	RPCQuirks            bool          `long:"rpcquirks" description:"Mirror some JSON-RPC quirks of Bitcoin Core -- NOTE: Discouraged unless interoperability issues need to be worked around"`
-	DisableRPC           bool          `long:"norpc" description:"Disable built-in RPC server -- NOTE: The RPC server is disabled by default if no rpcuser/rpcpass or rpclimituser/rpclimitpass is specified"`
+	EnableREST           bool          `long:"rest" description:"Serve read-only chain and mempool data over an unauthenticated REST interface on the RPC listeners"`
+	DisableRPC           bool          `long:"norpc" description:"Disable built-in RPC server -- NOTE: The RPC server is enabled by default and uses cookie authentication for admin access if no rpcpass is specified"`
 	DisableTLS           bool          `long:"notls" description:"Disable TLS for the RPC server -- NOTE: This is only allowed if the RPC server is bound to localhost"`
+	ZMQPubHashBlock      string        `long:"zmqpubhashblock" description:"Publish the hashes of connected blocks on the given ZeroMQ endpoint (eg. tcp://127.0.0.1:28332)"`
+	ZMQPubRawBlock       string        `long:"zmqpubrawblock" description:"Publish connected blocks on the given ZeroMQ endpoint (eg. tcp://127.0.0.1:28332)"`
+	ZMQPubHashTx         string        `long:"zmqpubhashtx" description:"Publish the hashes of mempool and block transactions on the given ZeroMQ endpoint (eg. tcp://127.0.0.1:28332)"`
+	ZMQPubRawTx          string        `long:"zmqpubrawtx" description:"Publish mempool and block transactions on the given ZeroMQ endpoint (eg. tcp://127.0.0.1:28332)"`
+	ZMQPubSequence       string        `long:"zmqpubsequence" description:"Publish block connection and mempool events on the given ZeroMQ endpoint (eg. tcp://127.0.0.1:28332)"`
+	ZMQPubHWM            int           `long:"zmqpubhwm" description:"Max number of ZeroMQ messages queued per subscriber before further messages are dropped"`
+	MetricsListeners     []string      `long:"metricslisten" description:"Add an interface/port to serve Prometheus metrics on below /metrics (default port: 9466) -- NOTE: Metrics are served without authentication"`
 	DisableDNSSeed       bool          `long:"nodnsseed" description:"Disable DNS seeding for peers"`
 	ExternalIPs          []string      `long:"externalip" description:"Add an ip to the list of local addresses we claim to listen on to peers"`
 	Proxy                string        `long:"proxy" description:"Connect via SOCKS5 proxy (eg. 127.0.0.1:9050)"`
@@ -126,6 +178,9 @@ type config struct {
 	OnionProxyPass       string        `long:"onionpass" default-mask:"-" description:"Password for onion proxy server"`
 	NoOnion              bool          `long:"noonion" description:"Disable connecting to tor hidden services"`
 	TorIsolation         bool          `long:"torisolation" description:"Enable Tor stream isolation by randomizing user credentials for each connection."`
+	TorControl           string        `long:"torcontrol" description:"Tor control port to use for creating an onion service when --listenonion is set (default: 127.0.0.1:9051)"`
+	TorPassword          string        `long:"torpassword" default-mask:"-" description:"Password for the Tor control port -- Cookie authentication is used when not set"`
+	ListenOnion          bool          `long:"listenonion" description:"Automatically create a Tor onion service for the peer listener using the Tor control port"`
 	TestNet4             bool          `long:"testnet" description:"Use the test network"`
 	RegressionTest       bool          `long:"regtest" description:"Use the regression test network"`
 	SimNet               bool          `long:"simnet" description:"Use the simulation test network"`
@@ -135,13 +190,18 @@ type config struct {
 	Profile              string        `long:"profile" description:"Enable HTTP profiling on given port -- NOTE port must be between 1024 and 65536"`
 	CPUProfile           string        `long:"cpuprofile" description:"Write CPU profile to the specified file"`
 	DebugLevel           string        `short:"d" long:"debuglevel" description:"Logging level for all subsystems {trace, debug, info, warn, error, critical} -- You may also specify <subsystem>=<level>,<subsystem2>=<level>,... to set the log level for individual subsystems -- Use show to list available subsystems"`
-	Upnp                 bool          `long:"upnp" description:"Use UPnP to map our listening port outside of NAT"`
+	Upnp                 bool          `long:"upnp" description:"Use UPnP, NAT-PMP or PCP to map our listening port outside of NAT"`
 	MinRelayTxFee        float64       `long:"minrelaytxfee" description:"The minimum transaction fee in BTC/kB to be considered a non-zero fee."`
 	FreeTxRelayLimit     float64       `long:"limitfreerelay" description:"Limit relay of transactions with no transaction fee to the given amount in thousands of bytes per minute"`
 	NoRelayPriority      bool          `long:"norelaypriority" description:"Do not require free or low-fee transactions to have high priority for relaying"`
 	MaxOrphanTxs         int           `long:"maxorphantx" description:"Max number of orphan transactions to keep in memory"`
+	MaxMemPool           int           `long:"maxmempool" description:"Max total size in megabytes of the transactions in the memory pool -- transactions with the lowest fee rates are evicted once it is exceeded (0 for no limit)"`
 	Generate             bool          `long:"generate" description:"Generate (mine) litecoins using the CPU"`
 	MiningAddrs          []string      `long:"miningaddr" description:"Add the specified payment address to the list of addresses to use for generated blocks -- At least one address is required if the generate option is set"`
+	StratumListeners     []string      `long:"stratumlisten" description:"Add an interface/port to listen for Stratum mining connections (default port: 3333) -- At least one mining address is required"`
+	StratumPass          string        `long:"stratumpass" default-mask:"-" description:"Password Stratum miners must use to authorize workers (default: any password is accepted)"`
+	StratumDifficulty    float64       `long:"stratumdifficulty" description:"Initial and minimum share difficulty of Stratum connections relative to the proof of work limit"`
+	CoinbaseTag          string        `long:"coinbasetag" description:"Tag to add to the coinbase script of generated blocks instead of the default flags"`
 	BlockMinSize         uint32        `long:"blockminsize" description:"Mininum block size in bytes to be used when creating a block"`
 	BlockMaxSize         uint32        `long:"blockmaxsize" description:"Maximum block size in bytes to be used when creating a block"`
 	BlockMinWeight       uint32        `long:"blockminweight" description:"Mininum block weight to be used when creating a block"`
@@ -159,12 +219,22 @@ type config struct {
 	DropAddrIndex        bool          `long:"dropaddrindex" description:"Deletes the address-based transaction index from the database on start up and then exits."`
 	RelayNonStd          bool          `long:"relaynonstd" description:"Relay non-standard transactions regardless of the default settings for the active network."`
 	RejectNonStd         bool          `long:"rejectnonstd" description:"Reject non-standard transactions regardless of the default settings for the active network."`
+	DataCarrierSize      uint32        `long:"datacarriersize" description:"Maximum size in bytes of standard output scripts which only carry data"`
+	DataCarrier          optionalBool  `long:"datacarrier" optional:"yes" optional-value:"1" description:"Relay and mine transactions with outputs which only carry data (--datacarrier=0 to disable)"`
+	DustRelayFee         float64       `long:"dustrelayfee" description:"The fee rate in BTC/kB used to decide whether a transaction output is dust"`
+	NoBareMultiSig       bool          `long:"nobaremultisig" description:"Do not relay or mine transactions with bare multi-signature outputs"`
+	MaxStdTxWeight       uint32        `long:"maxstdtxweight" description:"Maximum weight of standard transactions"`
+	MaxStdTxSigOpCost    uint32        `long:"maxstdtxsigopcost" description:"Maximum signature operation cost of transactions which are relayed or mined"`
 	lookup               func(string) ([]net.IP, error)
 	oniondial            func(string, string, time.Duration) (net.Conn, error)
 	dial                 func(string, string, time.Duration) (net.Conn, error)
 	addCheckpoints       []chaincfg.Checkpoint
 	miningAddrs          []vtcutil.Address
 	minRelayTxFee        vtcutil.Amount
+	dustRelayFee         vtcutil.Amount
+	zmqEndpoints         map[string]string
+	whitelists           []*net.IPNet
+	banWeights           banWeights
 }
 
 // serviceOptions defines the configuration options for the daemon as a service on
@@ -406,12 +476,22 @@ func loadConfig() (*config, []string, error) {
 		RPCMaxClients:        defaultMaxRPCClients,
 		RPCMaxWebsockets:     defaultMaxRPCWebsockets,
 		RPCMaxConcurrentReqs: defaultMaxRPCConcurrentReqs,
+		RPCMaxBatchSize:      defaultMaxRPCBatchSize,
+		RPCWSQueueSize:       defaultRPCWSQueueSize,
+		RPCWSQueuePolicy:     defaultRPCWSQueuePolicy,
+		RPCWSSessionGrace:    defaultRPCWSSessionGrace,
+		ZMQPubHWM:            defaultZMQPubHWM,
 		DataDir:              defaultDataDir,
 		LogDir:               defaultLogDir,
 		DbType:               defaultDbType,
 		RPCKey:               defaultRPCKeyFile,
 		RPCCert:              defaultRPCCertFile,
 		MinRelayTxFee:        mempool.DefaultMinRelayTxFee.ToBTC(),
+		DustRelayFee:         mempool.DefaultMinRelayTxFee.ToBTC(),
+		DataCarrierSize:      mempool.DefaultMaxDataCarrierSize,
+		DataCarrier:          true,
+		MaxStdTxWeight:       mempool.DefaultMaxStandardTxWeight,
+		MaxStdTxSigOpCost:    mempool.DefaultMaxStandardTxSigOpCost,
 		FreeTxRelayLimit:     defaultFreeTxRelayLimit,
 		BlockMinSize:         defaultBlockMinSize,
 		BlockMaxSize:         defaultBlockMaxSize,
@@ -419,8 +499,10 @@ func loadConfig() (*config, []string, error) {
 		BlockMaxWeight:       defaultBlockMaxWeight,
 		BlockPrioritySize:    mempool.DefaultBlockPrioritySize,
 		MaxOrphanTxs:         defaultMaxOrphanTransactions,
+		MaxMemPool:           defaultMaxMemPool,
 		SigCacheMaxSize:      defaultSigCacheMaxSize,
 		Generate:             defaultGenerate,
+		StratumDifficulty:    defaultStratumDifficulty,
 		TxIndex:              defaultTxIndex,
 		AddrIndex:            defaultAddrIndex,
 	}
@@ -632,6 +714,32 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// Validate the ban score weights of the misbehavior catalogue.
+	cfg.banWeights, err = parseBanWeights(cfg.BanWeights)
+	if err != nil {
+		str := "%s: %v"
+		err := fmt.Errorf(str, funcName, err)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Validate any given whitelisted IP addresses and networks.
+	if len(cfg.Whitelists) > 0 {
+		cfg.whitelists = make([]*net.IPNet, 0, len(cfg.Whitelists))
+		for _, addr := range cfg.Whitelists {
+			ipnet, err := connmgr.ParseSubnet(addr)
+			if err != nil {
+				str := "%s: The whitelist value of '%s' is invalid"
+				err = fmt.Errorf(str, funcName, addr)
+				fmt.Fprintln(os.Stderr, err)
+				fmt.Fprintln(os.Stderr, usageMessage)
+				return nil, nil, err
+			}
+			cfg.whitelists = append(cfg.whitelists, ipnet)
+		}
+	}
+
 	// --addPeer and --connect do not mix.
 	if len(cfg.AddPeers) > 0 && len(cfg.ConnectPeers) > 0 {
 		str := "%s: the --addpeer and --connect options can not be " +
@@ -682,10 +790,16 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
-	// The RPC server is disabled if no username or password is provided.
-	if (cfg.RPCUser == "" || cfg.RPCPass == "") &&
-		(cfg.RPCLimitUser == "" || cfg.RPCLimitPass == "") {
-		cfg.DisableRPC = true
+	// The admin RPC user authenticates with a generated cookie stored in
+	// the data directory when no password is provided.  Unlike previous
+	// versions, the RPC server is not disabled when no credentials are
+	// provided.  It only listens on localhost by default and can still be
+	// disabled with --norpc.
+	if cfg.RPCCookieFile == "" {
+		cfg.RPCCookieFile = filepath.Join(cfg.DataDir,
+			defaultRPCCookieFilename)
+	} else {
+		cfg.RPCCookieFile = cleanAndExpandPath(cfg.RPCCookieFile)
 	}
 
 	if cfg.DisableRPC {
@@ -714,6 +828,45 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	if cfg.RPCMaxBatchSize < 1 {
+		str := "%s: The rpcmaxbatchsize option may not be less than 1 " +
+			"-- parsed [%d]"
+		err := fmt.Errorf(str, funcName, cfg.RPCMaxBatchSize)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	if cfg.RPCWSQueueSize < 1 {
+		str := "%s: The rpcwsqueuesize option may not be less than 1 " +
+			"-- parsed [%d]"
+		err := fmt.Errorf(str, funcName, cfg.RPCWSQueueSize)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	switch cfg.RPCWSQueuePolicy {
+	case wsQueuePolicyDisconnect, wsQueuePolicyCoalesce:
+	default:
+		str := "%s: The rpcwsqueuepolicy option must be one of %s or " +
+			"%s -- parsed [%s]"
+		err := fmt.Errorf(str, funcName, wsQueuePolicyDisconnect,
+			wsQueuePolicyCoalesce, cfg.RPCWSQueuePolicy)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	if cfg.RPCWSSessionGrace < 0 {
+		str := "%s: The rpcwssessiongrace option may not be negative " +
+			"-- parsed [%v]"
+		err := fmt.Errorf(str, funcName, cfg.RPCWSSessionGrace)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// Validate the the minrelaytxfee.
 	cfg.minRelayTxFee, err = vtcutil.NewAmount(cfg.MinRelayTxFee)
 	if err != nil {
@@ -724,6 +877,45 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// Validate the the dustrelayfee.
+	cfg.dustRelayFee, err = vtcutil.NewAmount(cfg.DustRelayFee)
+	if err != nil {
+		str := "%s: invalid dustrelayfee: %v"
+		err := fmt.Errorf(str, funcName, err)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Limit the standard policy options to sane values.
+	if cfg.DataCarrierSize > txscript.MaxScriptSize {
+		str := "%s: The datacarriersize option may not be more than " +
+			"%d -- parsed [%d]"
+		err := fmt.Errorf(str, funcName, txscript.MaxScriptSize,
+			cfg.DataCarrierSize)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+	if cfg.MaxStdTxWeight == 0 || cfg.MaxStdTxWeight > blockchain.MaxBlockWeight {
+		str := "%s: The maxstdtxweight option must be in between 1 " +
+			"and %d -- parsed [%d]"
+		err := fmt.Errorf(str, funcName, blockchain.MaxBlockWeight,
+			cfg.MaxStdTxWeight)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+	if cfg.MaxStdTxSigOpCost == 0 || cfg.MaxStdTxSigOpCost > blockchain.MaxBlockSigOpsCost {
+		str := "%s: The maxstdtxsigopcost option must be in between " +
+			"1 and %d -- parsed [%d]"
+		err := fmt.Errorf(str, funcName, blockchain.MaxBlockSigOpsCost,
+			cfg.MaxStdTxSigOpCost)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// Limit the max block size to a sane value.
 	if cfg.BlockMaxSize < blockMaxSizeMin || cfg.BlockMaxSize >
 		blockMaxSizeMax {
@@ -760,6 +952,16 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// Limit the max memory pool size to a sane value.
+	if cfg.MaxMemPool < 0 {
+		str := "%s: The maxmempool option may not be less than 0 " +
+			"-- parsed [%d]"
+		err := fmt.Errorf(str, funcName, cfg.MaxMemPool)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// Limit the block priority and minimum block sizes to max block size.
 	cfg.BlockPrioritySize = minUint32(cfg.BlockPrioritySize, cfg.BlockMaxSize)
 	cfg.BlockMinSize = minUint32(cfg.BlockMinSize, cfg.BlockMaxSize)
@@ -858,6 +1060,88 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// The gRPC server is part of the RPC server.
+	if cfg.DisableRPC && len(cfg.GRPCListeners) > 0 {
+		str := "%s: the grpclisten option may not be used when the " +
+			"RPC server is disabled"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Ensure there is at least one mining address when the Stratum server
+	// is enabled.
+	if len(cfg.StratumListeners) > 0 && len(cfg.MiningAddrs) == 0 {
+		str := "%s: the stratumlisten option is set, but there are no " +
+			"mining addresses specified"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Validate the Stratum share difficulty.
+	if cfg.StratumDifficulty <= 0 {
+		str := "%s: the stratumdifficulty option must be positive -- " +
+			"parsed [%v]"
+		err := fmt.Errorf(str, funcName, cfg.StratumDifficulty)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Limit the coinbase tag so it fits the coinbase script along with the
+	// block height and the extra nonce.
+	if len(cfg.CoinbaseTag) > mining.MaxCoinbaseFlagsLen {
+		str := "%s: the coinbasetag option may not be longer than %d " +
+			"bytes -- parsed [%v]"
+		err := fmt.Errorf(str, funcName, mining.MaxCoinbaseFlagsLen,
+			cfg.CoinbaseTag)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Validate the ZeroMQ endpoints and collect them by topic.
+	cfg.zmqEndpoints = make(map[string]string)
+	for topic, endpoint := range map[string]string{
+		zmq.TopicHashBlock: cfg.ZMQPubHashBlock,
+		zmq.TopicRawBlock:  cfg.ZMQPubRawBlock,
+		zmq.TopicHashTx:    cfg.ZMQPubHashTx,
+		zmq.TopicRawTx:     cfg.ZMQPubRawTx,
+		zmq.TopicSequence:  cfg.ZMQPubSequence,
+	} {
+		if endpoint == "" {
+			continue
+		}
+		if _, err := zmq.ParseEndpoint(endpoint); err != nil {
+			str := "%s: the zmqpub%s option is invalid: %v"
+			err := fmt.Errorf(str, funcName, topic, err)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+		cfg.zmqEndpoints[topic] = endpoint
+	}
+	if cfg.ZMQPubHWM < 1 {
+		str := "%s: the zmqpubhwm option must be positive -- parsed [%d]"
+		err := fmt.Errorf(str, funcName, cfg.ZMQPubHWM)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Add default port to all metrics listener addresses if needed and
+	// remove duplicate addresses.
+	cfg.MetricsListeners = normalizeAddresses(cfg.MetricsListeners,
+		defaultMetricsPort)
+
+	// Add default port to all Stratum listener addresses if needed and
+	// remove duplicate addresses.
+	cfg.StratumListeners = normalizeAddresses(cfg.StratumListeners,
+		defaultStratumPort)
+
 	// Add default port to all listener addresses if needed and remove
 	// duplicate addresses.
 	cfg.Listeners = normalizeAddresses(cfg.Listeners,
@@ -868,15 +1152,24 @@ func loadConfig() (*config, []string, error) {
 	cfg.RPCListeners = normalizeAddresses(cfg.RPCListeners,
 		activeNetParams.rpcPort)
 
-	// Only allow TLS to be disabled if the RPC is bound to localhost
-	// addresses.
+	// Add default port to all gRPC listener addresses if needed and remove
+	// duplicate addresses.
+	cfg.GRPCListeners = normalizeAddresses(cfg.GRPCListeners,
+		activeNetParams.grpcPort)
+
+	// Only allow TLS to be disabled if the RPC and gRPC servers are bound
+	// to localhost addresses.
 	if !cfg.DisableRPC && cfg.DisableTLS {
 		allowedTLSListeners := map[string]struct{}{
 			"localhost": {},
 			"127.0.0.1": {},
 			"::1":       {},
 		}
-		for _, addr := range cfg.RPCListeners {
+		rpcListeners := make([]string, 0, len(cfg.RPCListeners)+
+			len(cfg.GRPCListeners))
+		rpcListeners = append(rpcListeners, cfg.RPCListeners...)
+		rpcListeners = append(rpcListeners, cfg.GRPCListeners...)
+		for _, addr := range rpcListeners {
 			host, _, err := net.SplitHostPort(addr)
 			if err != nil {
 				str := "%s: RPC listen interface '%s' is " +
@@ -914,6 +1207,19 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// An onion service can only be created for the peer listener.
+	if cfg.ListenOnion && cfg.DisableListen {
+		str := "%s: the --listenonion option requires listening for " +
+			"incoming connections to be enabled"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+	if cfg.ListenOnion && cfg.TorControl == "" {
+		cfg.TorControl = defaultTorControl
+	}
+
 	// Check the checkpoints for syntax errors.
 	cfg.addCheckpoints, err = parseCheckpoints(cfg.AddCheckpoints)
 	if err != nil {
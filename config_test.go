@@ -7,6 +7,8 @@ import (
 	"regexp"
 	"runtime"
 	"testing"
+
+	flags "github.com/jessevdk/go-flags"
 )
 
 var (
@@ -70,3 +72,38 @@ func TestCreateDefaultConfigFile(t *testing.T) {
 		t.Error("Could not find rpcpass in generated default config file.")
 	}
 }
+
+// TestOptionalBool ensures boolean options which accept a value are enabled
+// when passed without a value and can be disabled with one.
+func TestOptionalBool(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{args: nil, want: true},
+		{args: []string{"--datacarrier"}, want: true},
+		{args: []string{"--datacarrier=1"}, want: true},
+		{args: []string{"--datacarrier=0"}, want: false},
+		{args: []string{"--datacarrier=false"}, want: false},
+	}
+	for _, test := range tests {
+		var cfg struct {
+			DataCarrier optionalBool `long:"datacarrier" optional:"yes" optional-value:"1"`
+		}
+		cfg.DataCarrier = true
+		if _, err := flags.ParseArgs(&cfg, test.args); err != nil {
+			t.Fatalf("%v: unexpected error: %v", test.args, err)
+		}
+		if bool(cfg.DataCarrier) != test.want {
+			t.Errorf("%v: got %v, want %v", test.args,
+				cfg.DataCarrier, test.want)
+		}
+	}
+
+	var cfg struct {
+		DataCarrier optionalBool `long:"datacarrier" optional:"yes" optional-value:"1"`
+	}
+	if _, err := flags.ParseArgs(&cfg, []string{"--datacarrier=x"}); err == nil {
+		t.Fatal("expected error for invalid value")
+	}
+}
@@ -0,0 +1,370 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package connmgr
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// banListVersion is the current version of the serialized ban list.
+	banListVersion = 1
+
+	// BanReasonNodeMisbehaving is the reason recorded for bans that are
+	// the result of a peer exceeding the ban score threshold.
+	BanReasonNodeMisbehaving = "node misbehaving"
+
+	// BanReasonManuallyAdded is the reason recorded for bans that were
+	// added by an operator, for example via the setban RPC.
+	BanReasonManuallyAdded = "manually added"
+)
+
+// BanEntry describes a single banned subnet along with the time the ban was
+// created, when it expires, and why it was put in place.
+type BanEntry struct {
+	Subnet  *net.IPNet
+	Created time.Time
+	Until   time.Time
+	Reason  string
+}
+
+// serializedBanEntry is the on-disk representation of a BanEntry.
+type serializedBanEntry struct {
+	Subnet  string `json:"subnet"`
+	Created int64  `json:"created"`
+	Until   int64  `json:"until"`
+	Reason  string `json:"reason"`
+}
+
+// serializedBanList is the on-disk representation of a BanList.
+type serializedBanList struct {
+	Version int                   `json:"version"`
+	Banned  []*serializedBanEntry `json:"banned"`
+}
+
+// BanList provides a concurrency safe list of banned subnets which can be
+// persisted to and restored from disk.  Entries expire automatically once
+// their ban time has passed.
+type BanList struct {
+	mtx      sync.Mutex
+	filePath string
+	entries  map[string]*BanEntry
+	dirty    bool
+}
+
+// NewBanList returns a new empty ban list which is persisted to the provided
+// file path.  An empty file path disables persistence.  Load must be called to
+// restore any previously saved entries.
+func NewBanList(filePath string) *BanList {
+	return &BanList{
+		filePath: filePath,
+		entries:  make(map[string]*BanEntry),
+	}
+}
+
+// ParseSubnet parses the passed string as either a single IP address or a CIDR
+// subnet.  Single addresses are converted to a subnet consisting of only that
+// address.
+func ParseSubnet(s string) (*net.IPNet, error) {
+	if strings.Contains(s, "/") {
+		_, ipNet, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, err
+		}
+		return normalizeSubnet(ipNet), nil
+	}
+
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address or subnet %q", s)
+	}
+	return SingleHostSubnet(ip), nil
+}
+
+// SingleHostSubnet returns the subnet which contains only the passed IP
+// address.
+func SingleHostSubnet(ip net.IP) *net.IPNet {
+	if ip4 := ip.To4(); ip4 != nil {
+		return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}
+	}
+	return &net.IPNet{IP: ip.To16(), Mask: net.CIDRMask(128, 128)}
+}
+
+// normalizeSubnet converts IPv4 subnets into their 4-byte form so that the
+// same subnet always has the same string key.
+func normalizeSubnet(ipNet *net.IPNet) *net.IPNet {
+	ones, bits := ipNet.Mask.Size()
+	if ip4 := ipNet.IP.To4(); ip4 != nil && bits == 32 {
+		return &net.IPNet{IP: ip4, Mask: net.CIDRMask(ones, 32)}
+	}
+	return ipNet
+}
+
+// Ban adds the passed subnet to the ban list until the provided time.  Banning
+// a subnet which is already banned replaces the existing entry.
+//
+// This function is safe for concurrent access.
+func (b *BanList) Ban(subnet *net.IPNet, until time.Time, reason string) {
+	b.ban(subnet, until, reason, time.Now())
+}
+
+// ban adds the passed subnet to the ban list using the provided time as the
+// creation time of the entry.
+func (b *BanList) ban(subnet *net.IPNet, until time.Time, reason string, now time.Time) {
+	subnet = normalizeSubnet(subnet)
+
+	b.mtx.Lock()
+	b.entries[subnet.String()] = &BanEntry{
+		Subnet:  subnet,
+		Created: now,
+		Until:   until,
+		Reason:  reason,
+	}
+	b.dirty = true
+	b.mtx.Unlock()
+}
+
+// Unban removes the passed subnet from the ban list.  It returns false when the
+// subnet was not banned.
+//
+// This function is safe for concurrent access.
+func (b *BanList) Unban(subnet *net.IPNet) bool {
+	key := normalizeSubnet(subnet).String()
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	if _, ok := b.entries[key]; !ok {
+		return false
+	}
+	delete(b.entries, key)
+	b.dirty = true
+	return true
+}
+
+// Clear removes all entries from the ban list.
+//
+// This function is safe for concurrent access.
+func (b *BanList) Clear() {
+	b.mtx.Lock()
+	b.entries = make(map[string]*BanEntry)
+	b.dirty = true
+	b.mtx.Unlock()
+}
+
+// IsBanned returns whether or not the passed IP address is part of a banned
+// subnet along with the matching entry.  The entry with the latest expiration
+// is returned when several banned subnets contain the address.
+//
+// This function is safe for concurrent access.
+func (b *BanList) IsBanned(ip net.IP) (bool, *BanEntry) {
+	return b.isBanned(ip, time.Now())
+}
+
+// isBanned returns whether or not the passed IP address is banned at the
+// provided time.
+func (b *BanList) isBanned(ip net.IP, now time.Time) (bool, *BanEntry) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	b.sweepExpired(now)
+
+	var match *BanEntry
+	for _, entry := range b.entries {
+		if !entry.Subnet.Contains(ip) {
+			continue
+		}
+		if match == nil || entry.Until.After(match.Until) {
+			match = entry
+		}
+	}
+	if match == nil {
+		return false, nil
+	}
+	entry := *match
+	return true, &entry
+}
+
+// List returns a copy of all of the unexpired entries in the ban list sorted
+// by subnet.
+//
+// This function is safe for concurrent access.
+func (b *BanList) List() []BanEntry {
+	return b.list(time.Now())
+}
+
+// list returns all entries which are still active at the provided time.
+func (b *BanList) list(now time.Time) []BanEntry {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	b.sweepExpired(now)
+
+	entries := make([]BanEntry, 0, len(b.entries))
+	for _, entry := range b.entries {
+		entries = append(entries, *entry)
+	}
+	sort.Sort(banEntriesBySubnet(entries))
+	return entries
+}
+
+// banEntriesBySubnet implements sort.Interface to allow a slice of ban entries
+// to be sorted by their subnet.
+type banEntriesBySubnet []BanEntry
+
+// Len returns the number of entries in the slice.  It is part of the
+// sort.Interface implementation.
+func (s banEntriesBySubnet) Len() int {
+	return len(s)
+}
+
+// Swap swaps the entries at the passed indices.  It is part of the
+// sort.Interface implementation.
+func (s banEntriesBySubnet) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+
+// Less returns whether the entry with index i should sort before the entry with
+// index j.  It is part of the sort.Interface implementation.
+func (s banEntriesBySubnet) Less(i, j int) bool {
+	return s[i].Subnet.String() < s[j].Subnet.String()
+}
+
+// sweepExpired removes all entries which expired before the provided time.
+//
+// This function MUST be called with the ban list lock held.
+func (b *BanList) sweepExpired(now time.Time) {
+	for key, entry := range b.entries {
+		if !now.Before(entry.Until) {
+			log.Debugf("Ban on %s has expired", key)
+			delete(b.entries, key)
+			b.dirty = true
+		}
+	}
+}
+
+// Save writes the ban list to its file when it has changed since it was last
+// loaded or saved.
+//
+// This function is safe for concurrent access.
+func (b *BanList) Save() error {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	if b.filePath == "" || !b.dirty {
+		return nil
+	}
+
+	b.sweepExpired(time.Now())
+
+	sbl := serializedBanList{
+		Version: banListVersion,
+		Banned:  make([]*serializedBanEntry, 0, len(b.entries)),
+	}
+	for key, entry := range b.entries {
+		sbl.Banned = append(sbl.Banned, &serializedBanEntry{
+			Subnet:  key,
+			Created: entry.Created.Unix(),
+			Until:   entry.Until.Unix(),
+			Reason:  entry.Reason,
+		})
+	}
+
+	w, err := os.Create(b.filePath)
+	if err != nil {
+		return fmt.Errorf("error opening file %s: %v", b.filePath, err)
+	}
+	defer w.Close()
+	if err := json.NewEncoder(w).Encode(&sbl); err != nil {
+		return fmt.Errorf("failed to encode file %s: %v", b.filePath,
+			err)
+	}
+
+	b.dirty = false
+	return nil
+}
+
+// Load restores the ban list from its file, replacing any entries currently
+// in the list.  A missing file is not an error and results in an empty list.
+// Entries which have already expired are discarded.  When the file is corrupt,
+// the list is left unchanged and marked as modified, so the next save replaces
+// the file.
+//
+// This function is safe for concurrent access.
+func (b *BanList) Load() error {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	if b.filePath == "" {
+		return nil
+	}
+
+	r, err := os.Open(b.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("%s error opening file: %v", b.filePath, err)
+	}
+	defer r.Close()
+
+	entries, err := b.decode(r)
+	if err != nil {
+		b.dirty = true
+		return err
+	}
+	b.entries = entries
+	b.dirty = false
+	b.sweepExpired(time.Now())
+
+	log.Infof("Loaded %d banned %s from file '%s'", len(b.entries),
+		pickNoun(len(b.entries), "subnet", "subnets"), b.filePath)
+	return nil
+}
+
+// decode reads the ban entries serialized by Save from the passed reader.
+func (b *BanList) decode(r io.Reader) (map[string]*BanEntry, error) {
+	var sbl serializedBanList
+	if err := json.NewDecoder(r).Decode(&sbl); err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", b.filePath, err)
+	}
+	if sbl.Version != banListVersion {
+		return nil, fmt.Errorf("unknown version %v in serialized ban "+
+			"list", sbl.Version)
+	}
+
+	entries := make(map[string]*BanEntry, len(sbl.Banned))
+	for _, sbe := range sbl.Banned {
+		subnet, err := ParseSubnet(sbe.Subnet)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse banned subnet "+
+				"%s: %v", sbe.Subnet, err)
+		}
+		entries[subnet.String()] = &BanEntry{
+			Subnet:  subnet,
+			Created: time.Unix(sbe.Created, 0),
+			Until:   time.Unix(sbe.Until, 0),
+			Reason:  sbe.Reason,
+		}
+	}
+	return entries, nil
+}
+
+// pickNoun returns the singular or plural form of a noun depending on the
+// count n.
+func pickNoun(n int, singular, plural string) string {
+	if n == 1 {
+		return singular
+	}
+	return plural
+}
@@ -0,0 +1,169 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package connmgr
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestParseSubnet ensures single addresses and CIDR subnets are parsed into
+// the expected normalized subnets.
+func TestParseSubnet(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "1.2.3.4", want: "1.2.3.4/32"},
+		{in: "1.2.3.4/24", want: "1.2.3.0/24"},
+		{in: "::ffff:1.2.3.4", want: "1.2.3.4/32"},
+		{in: "2001:db8::1", want: "2001:db8::1/128"},
+		{in: "2001:db8::/32", want: "2001:db8::/32"},
+		{in: "bogus", wantErr: true},
+		{in: "1.2.3.4/33", wantErr: true},
+	}
+
+	for i, test := range tests {
+		subnet, err := ParseSubnet(test.in)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("test #%d (%s): expected error", i, test.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("test #%d (%s): unexpected error: %v", i,
+				test.in, err)
+			continue
+		}
+		if subnet.String() != test.want {
+			t.Errorf("test #%d (%s): got %s, want %s", i, test.in,
+				subnet, test.want)
+		}
+	}
+}
+
+// TestBanListExpiry ensures banned subnets match contained addresses and are
+// removed once their ban time has passed.
+func TestBanListExpiry(t *testing.T) {
+	bl := NewBanList("")
+	base := time.Now()
+
+	subnet, _ := ParseSubnet("10.0.0.0/8")
+	bl.ban(subnet, base.Add(time.Hour), BanReasonManuallyAdded, base)
+
+	banned, entry := bl.isBanned(net.ParseIP("10.1.2.3"), base)
+	if !banned {
+		t.Fatalf("address in banned subnet is not banned")
+	}
+	if entry.Reason != BanReasonManuallyAdded {
+		t.Errorf("unexpected ban reason %q", entry.Reason)
+	}
+	if banned, _ := bl.isBanned(net.ParseIP("11.1.2.3"), base); banned {
+		t.Errorf("address outside banned subnet is banned")
+	}
+
+	if n := len(bl.list(base.Add(time.Hour - time.Second))); n != 1 {
+		t.Errorf("expected 1 entry before expiry, got %d", n)
+	}
+	if n := len(bl.list(base.Add(time.Hour))); n != 0 {
+		t.Errorf("expected 0 entries after expiry, got %d", n)
+	}
+	if banned, _ := bl.isBanned(net.ParseIP("10.1.2.3"), base); banned {
+		t.Errorf("expired entry is still banned")
+	}
+}
+
+// TestBanListUnbanClear ensures entries can be removed individually and all
+// at once.
+func TestBanListUnbanClear(t *testing.T) {
+	bl := NewBanList("")
+	until := time.Now().Add(time.Hour)
+
+	a, _ := ParseSubnet("1.2.3.4")
+	b, _ := ParseSubnet("2001:db8::/32")
+	bl.Ban(a, until, BanReasonNodeMisbehaving)
+	bl.Ban(b, until, BanReasonManuallyAdded)
+
+	if !bl.Unban(a) {
+		t.Errorf("failed to unban %s", a)
+	}
+	if bl.Unban(a) {
+		t.Errorf("unban of %s succeeded twice", a)
+	}
+	if n := len(bl.List()); n != 1 {
+		t.Fatalf("expected 1 entry after unban, got %d", n)
+	}
+
+	bl.Clear()
+	if n := len(bl.List()); n != 0 {
+		t.Errorf("expected 0 entries after clear, got %d", n)
+	}
+}
+
+// TestBanListPersistence ensures the ban list survives a save and load round
+// trip and that corrupt files are reported.
+func TestBanListPersistence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "banlist")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "banlist.json")
+
+	// Loading a missing file must produce an empty list.
+	bl := NewBanList(path)
+	if err := bl.Load(); err != nil {
+		t.Fatalf("unexpected error loading missing file: %v", err)
+	}
+
+	until := time.Now().Add(time.Hour).Truncate(time.Second)
+	subnet, _ := ParseSubnet("192.168.0.0/16")
+	bl.Ban(subnet, until, BanReasonManuallyAdded)
+	if err := bl.Save(); err != nil {
+		t.Fatalf("unable to save ban list: %v", err)
+	}
+
+	bl2 := NewBanList(path)
+	if err := bl2.Load(); err != nil {
+		t.Fatalf("unable to load ban list: %v", err)
+	}
+	entries := bl2.List()
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry after load, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.Subnet.String() != "192.168.0.0/16" {
+		t.Errorf("unexpected subnet %s", entry.Subnet)
+	}
+	if !entry.Until.Equal(until) {
+		t.Errorf("unexpected ban expiry %v, want %v", entry.Until, until)
+	}
+	if entry.Reason != BanReasonManuallyAdded {
+		t.Errorf("unexpected ban reason %q", entry.Reason)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("{garbage"), 0644); err != nil {
+		t.Fatalf("unable to write corrupt file: %v", err)
+	}
+	corrupt := NewBanList(path)
+	if err := corrupt.Load(); err == nil {
+		t.Fatalf("expected error loading corrupt file")
+	}
+
+	// The corrupt file is replaced on the next save.
+	if err := corrupt.Save(); err != nil {
+		t.Fatalf("Save: unexpected error: %v", err)
+	}
+	if err := NewBanList(path).Load(); err != nil {
+		t.Fatalf("Load: unexpected error after replacing corrupt "+
+			"file: %v", err)
+	}
+}
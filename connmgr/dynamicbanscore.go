@@ -87,6 +87,17 @@ func (s *DynamicBanScore) Int() uint32 {
 	return r
 }
 
+// Components returns the persistent score and the current value of the
+// decaying score, which sum up to the ban score.
+//
+// This function is safe for concurrent access.
+func (s *DynamicBanScore) Components() (persistent, transient uint32) {
+	s.mtx.Lock()
+	persistent, transient = s.components(time.Now())
+	s.mtx.Unlock()
+	return persistent, transient
+}
+
 // Increase increases both the persistent and decaying scores by the values
 // passed as parameters. The resulting score is returned.
 //
@@ -115,11 +126,21 @@ func (s *DynamicBanScore) Reset() {
 // This function is not safe for concurrent access. It is intended to be used
 // internally and during testing.
 func (s *DynamicBanScore) int(t time.Time) uint32 {
+	persistent, transient := s.components(t)
+	return persistent + transient
+}
+
+// components returns the persistent score and the value of the decaying score
+// at a given point in time.
+//
+// This function is not safe for concurrent access. It is intended to be used
+// internally and during testing.
+func (s *DynamicBanScore) components(t time.Time) (uint32, uint32) {
 	dt := t.Unix() - s.lastUnix
 	if s.transient < 1 || dt < 0 || Lifetime < dt {
-		return s.persistent
+		return s.persistent, 0
 	}
-	return s.persistent + uint32(s.transient*decayFactor(dt))
+	return s.persistent, uint32(s.transient * decayFactor(dt))
 }
 
 // increase increases the persistent, the decaying or both scores by the values
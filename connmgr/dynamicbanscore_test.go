@@ -32,6 +32,26 @@ func TestDynamicBanScoreDecay(t *testing.T) {
 	}
 }
 
+// TestDynamicBanScoreComponents tests the persistent and decaying scores are
+// reported separately.
+func TestDynamicBanScoreComponents(t *testing.T) {
+	var bs DynamicBanScore
+	base := time.Now()
+
+	bs.increase(100, 50, base)
+	persistent, transient := bs.components(base.Add(time.Minute))
+	if persistent != 100 || transient != 25 {
+		t.Errorf("Unexpected components %d and %d after halflife",
+			persistent, transient)
+	}
+
+	persistent, transient = bs.components(base.Add((Lifetime + 1) * time.Second))
+	if persistent != 100 || transient != 0 {
+		t.Errorf("Unexpected components %d and %d after max age",
+			persistent, transient)
+	}
+}
+
 // TestDynamicBanScoreLifetime tests that DynamicBanScore properly yields zero
 // once the maximum age is reached.
 func TestDynamicBanScoreLifetime(t *testing.T) {
@@ -0,0 +1,380 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package connmgr
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// torControlDialTimeout is the maximum amount of time to wait when
+	// connecting to the Tor control port.
+	torControlDialTimeout = time.Second * 10
+
+	// torCookieLen is the length of the Tor authentication cookie.
+	torCookieLen = 32
+
+	// torNonceLen is the length of the nonces used during SAFECOOKIE
+	// authentication.
+	torNonceLen = 32
+
+	// torServerHashKey and torClientHashKey are the HMAC keys used to
+	// compute the server and controller hashes during SAFECOOKIE
+	// authentication.
+	torServerHashKey = "Tor safe cookie authentication server-to-controller hash"
+	torClientHashKey = "Tor safe cookie authentication controller-to-server hash"
+
+	// OnionKeyTypeED25519V3 is the ADD_ONION key type for version 3 onion
+	// services.
+	OnionKeyTypeED25519V3 = "ED25519-V3"
+)
+
+var (
+	// ErrTorNoAuthMethod indicates the Tor control port does not offer an
+	// authentication method that can be used with the provided
+	// credentials.
+	ErrTorNoAuthMethod = errors.New("no usable tor control authentication method")
+
+	// ErrTorInvalidServerHash indicates the Tor control port replied to a
+	// SAFECOOKIE challenge with a hash that does not prove knowledge of
+	// the authentication cookie.
+	ErrTorInvalidServerHash = errors.New("invalid tor control server hash")
+
+	// ErrTorInvalidControlReply indicates the Tor control port replied in
+	// an unexpected format.
+	ErrTorInvalidControlReply = errors.New("invalid tor control reply")
+)
+
+// TorControlError describes a non-success reply received from the Tor control
+// port.
+type TorControlError struct {
+	Code    int
+	Message string
+}
+
+// Error returns the error as a human-readable string and satisfies the error
+// interface.
+func (e *TorControlError) Error() string {
+	return fmt.Sprintf("tor control error %d: %s", e.Code, e.Message)
+}
+
+// OnionService describes an onion service created through the Tor control
+// port.
+type OnionService struct {
+	// ServiceID is the onion address without the .onion suffix.
+	ServiceID string
+
+	// PrivateKey is the key of the service in the form KeyType:KeyBlob.
+	// It is only set when the key was generated by Tor.
+	PrivateKey string
+}
+
+// TorController is a client for the Tor control protocol.  It supports the
+// subset of the protocol needed to authenticate and create onion services.
+//
+// A TorController is not safe for concurrent access.
+type TorController struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// DialTorControl connects to the Tor control port at the provided address.
+func DialTorControl(addr string) (*TorController, error) {
+	conn, err := net.DialTimeout("tcp", addr, torControlDialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return NewTorController(conn), nil
+}
+
+// NewTorController returns a Tor control protocol client which communicates
+// over the passed connection.
+func NewTorController(conn net.Conn) *TorController {
+	return &TorController{
+		conn: conn,
+		r:    bufio.NewReader(conn),
+	}
+}
+
+// Close closes the connection to the Tor control port.  Any onion services
+// created over the connection are removed by Tor.
+func (c *TorController) Close() error {
+	return c.conn.Close()
+}
+
+// Wait blocks until the connection to the Tor control port is closed or fails
+// and returns the error which ended it.  Asynchronous event replies received in
+// the meantime are discarded.  It allows callers to notice when Tor restarts or
+// the connection drops, which removes any onion services created over it.
+//
+// Wait must not be called concurrently with other methods except Close.
+func (c *TorController) Wait() error {
+	for {
+		if _, _, err := c.readReply(); err != nil {
+			return err
+		}
+	}
+}
+
+// readReply reads a complete reply from the control port and returns its
+// status code along with the text of each reply line.  Data lines which
+// follow a "+" separator are appended to the line that introduced them.
+func (c *TorController) readReply() (int, []string, error) {
+	var lines []string
+	for {
+		line, err := c.r.ReadString('\n')
+		if err != nil {
+			return 0, nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if len(line) < 4 {
+			return 0, nil, ErrTorInvalidControlReply
+		}
+		code, err := strconv.Atoi(line[:3])
+		if err != nil {
+			return 0, nil, ErrTorInvalidControlReply
+		}
+
+		text := line[4:]
+		switch line[3] {
+		case ' ':
+			lines = append(lines, text)
+			return code, lines, nil
+
+		case '-':
+			lines = append(lines, text)
+
+		case '+':
+			// Read the data until the terminating "." line.
+			for {
+				data, err := c.r.ReadString('\n')
+				if err != nil {
+					return 0, nil, err
+				}
+				data = strings.TrimRight(data, "\r\n")
+				if data == "." {
+					break
+				}
+				text += "\n" + strings.TrimPrefix(data, ".")
+			}
+			lines = append(lines, text)
+
+		default:
+			return 0, nil, ErrTorInvalidControlReply
+		}
+	}
+}
+
+// command sends the passed command to the control port and returns the reply
+// lines.  Any reply other than 250 is returned as a TorControlError.
+func (c *TorController) command(cmd string) ([]string, error) {
+	if _, err := c.conn.Write([]byte(cmd + "\r\n")); err != nil {
+		return nil, err
+	}
+	code, lines, err := c.readReply()
+	if err != nil {
+		return nil, err
+	}
+	if code != 250 {
+		return nil, &TorControlError{
+			Code:    code,
+			Message: strings.Join(lines, " "),
+		}
+	}
+	return lines, nil
+}
+
+// parseTorReplyArgs parses the space separated KEY=VALUE pairs of a reply line.
+// Values may be quoted strings containing backslash escapes.  Arguments which
+// are not of the KEY=VALUE form are ignored.
+func parseTorReplyArgs(line string) map[string]string {
+	args := make(map[string]string)
+	for len(line) > 0 {
+		line = strings.TrimLeft(line, " ")
+		eq := strings.IndexAny(line, "= ")
+		if eq == -1 {
+			break
+		}
+		if line[eq] == ' ' {
+			line = line[eq:]
+			continue
+		}
+		key := line[:eq]
+		line = line[eq+1:]
+
+		var value string
+		if strings.HasPrefix(line, "\"") {
+			var buf []byte
+			i := 1
+			for ; i < len(line) && line[i] != '"'; i++ {
+				if line[i] == '\\' && i+1 < len(line) {
+					i++
+				}
+				buf = append(buf, line[i])
+			}
+			value = string(buf)
+			if i < len(line) {
+				i++
+			}
+			line = line[i:]
+		} else {
+			end := strings.IndexByte(line, ' ')
+			if end == -1 {
+				end = len(line)
+			}
+			value = line[:end]
+			line = line[end:]
+		}
+		args[key] = value
+	}
+	return args
+}
+
+// quoteTorString returns the passed string as a quoted string suitable for use
+// as a control protocol argument.
+func quoteTorString(s string) string {
+	s = strings.Replace(s, "\\", "\\\\", -1)
+	s = strings.Replace(s, "\"", "\\\"", -1)
+	return "\"" + s + "\""
+}
+
+// Authenticate authenticates with the control port using the strongest method
+// it offers that can be used.  HASHEDPASSWORD authentication is used when a
+// password is provided, SAFECOOKIE authentication is used when the cookie file
+// advertised by Tor is readable, and NULL authentication is used when Tor does
+// not require any.
+func (c *TorController) Authenticate(password string) error {
+	lines, err := c.command("PROTOCOLINFO 1")
+	if err != nil {
+		return err
+	}
+
+	methods := make(map[string]struct{})
+	var cookieFile string
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "AUTH ") {
+			continue
+		}
+		args := parseTorReplyArgs(line[len("AUTH "):])
+		for _, method := range strings.Split(args["METHODS"], ",") {
+			methods[method] = struct{}{}
+		}
+		cookieFile = args["COOKIEFILE"]
+	}
+
+	if _, ok := methods["HASHEDPASSWORD"]; ok && password != "" {
+		_, err := c.command("AUTHENTICATE " + quoteTorString(password))
+		return err
+	}
+	if _, ok := methods["SAFECOOKIE"]; ok && cookieFile != "" {
+		return c.authenticateSafeCookie(cookieFile)
+	}
+	if _, ok := methods["NULL"]; ok {
+		_, err := c.command("AUTHENTICATE")
+		return err
+	}
+	return ErrTorNoAuthMethod
+}
+
+// authenticateSafeCookie performs SAFECOOKIE authentication using the cookie
+// stored in the passed file.
+func (c *TorController) authenticateSafeCookie(cookieFile string) error {
+	cookie, err := ioutil.ReadFile(cookieFile)
+	if err != nil {
+		return err
+	}
+	if len(cookie) != torCookieLen {
+		return fmt.Errorf("tor cookie file %s has invalid length %d",
+			cookieFile, len(cookie))
+	}
+
+	clientNonce := make([]byte, torNonceLen)
+	if _, err := rand.Read(clientNonce); err != nil {
+		return err
+	}
+
+	lines, err := c.command("AUTHCHALLENGE SAFECOOKIE " +
+		hex.EncodeToString(clientNonce))
+	if err != nil {
+		return err
+	}
+	if len(lines) != 1 || !strings.HasPrefix(lines[0], "AUTHCHALLENGE ") {
+		return ErrTorInvalidControlReply
+	}
+	args := parseTorReplyArgs(lines[0][len("AUTHCHALLENGE "):])
+	serverHash, err := hex.DecodeString(args["SERVERHASH"])
+	if err != nil {
+		return ErrTorInvalidControlReply
+	}
+	serverNonce, err := hex.DecodeString(args["SERVERNONCE"])
+	if err != nil || len(serverNonce) != torNonceLen {
+		return ErrTorInvalidControlReply
+	}
+
+	// Ensure the server knows the cookie before revealing proof that we
+	// know it as well.
+	msg := make([]byte, 0, len(cookie)+len(clientNonce)+len(serverNonce))
+	msg = append(msg, cookie...)
+	msg = append(msg, clientNonce...)
+	msg = append(msg, serverNonce...)
+	if !hmac.Equal(serverHash, torHMAC(torServerHashKey, msg)) {
+		return ErrTorInvalidServerHash
+	}
+
+	clientHash := torHMAC(torClientHashKey, msg)
+	_, err = c.command("AUTHENTICATE " + hex.EncodeToString(clientHash))
+	return err
+}
+
+// torHMAC returns the HMAC-SHA256 of the passed message using the given key.
+func torHMAC(key string, msg []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write(msg)
+	return mac.Sum(nil)
+}
+
+// AddOnion creates an onion service which forwards connections made to the
+// virtual port of the service to the passed target address.  A new ED25519-V3
+// key is generated by Tor when privateKey is empty, in which case it is
+// returned along with the service ID.  Otherwise privateKey must be of the form
+// KeyType:KeyBlob as previously returned by Tor.
+//
+// The service is removed by Tor when the control connection is closed.
+func (c *TorController) AddOnion(privateKey string, virtPort int, target string) (*OnionService, error) {
+	key := privateKey
+	if key == "" {
+		key = "NEW:" + OnionKeyTypeED25519V3
+	}
+	cmd := fmt.Sprintf("ADD_ONION %s Port=%d,%s", key, virtPort, target)
+	lines, err := c.command(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	var service OnionService
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "ServiceID="):
+			service.ServiceID = line[len("ServiceID="):]
+		case strings.HasPrefix(line, "PrivateKey="):
+			service.PrivateKey = line[len("PrivateKey="):]
+		}
+	}
+	if service.ServiceID == "" {
+		return nil, ErrTorInvalidControlReply
+	}
+	return &service, nil
+}
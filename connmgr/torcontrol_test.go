@@ -0,0 +1,315 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package connmgr
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeTorControl is a minimal Tor control port used to test the controller.
+// It supports the PROTOCOLINFO, AUTHCHALLENGE, AUTHENTICATE and ADD_ONION
+// commands.
+type fakeTorControl struct {
+	listener    net.Listener
+	methods     string
+	cookieFile  string
+	cookie      []byte
+	password    string
+	serverNonce []byte
+	badHash     bool
+
+	// These fields are set by the handler goroutine and must only be read
+	// after it has finished.
+	authenticated bool
+	addOnionCmd   string
+	done          chan struct{}
+}
+
+// newFakeTorControl starts a fake control port which offers the passed
+// authentication methods.
+func newFakeTorControl(t *testing.T, methods string) *fakeTorControl {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %v", err)
+	}
+	f := &fakeTorControl{
+		listener:    l,
+		methods:     methods,
+		serverNonce: bytes.Repeat([]byte{0x02}, torNonceLen),
+		done:        make(chan struct{}),
+	}
+	go f.serve()
+	return f
+}
+
+// serve handles a single control connection.
+func (f *fakeTorControl) serve() {
+	defer close(f.done)
+
+	conn, err := f.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	var clientNonce []byte
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		var reply string
+		switch fields[0] {
+		case "PROTOCOLINFO":
+			reply = "250-PROTOCOLINFO 1\r\n" +
+				"250-AUTH METHODS=" + f.methods
+			if f.cookieFile != "" {
+				reply += " COOKIEFILE=" + quoteTorString(f.cookieFile)
+			}
+			reply += "\r\n250-VERSION Tor=\"0.3.5.7\"\r\n250 OK\r\n"
+
+		case "AUTHCHALLENGE":
+			clientNonce, _ = hex.DecodeString(fields[2])
+			msg := append(append(append([]byte{}, f.cookie...),
+				clientNonce...), f.serverNonce...)
+			serverHash := torHMAC(torServerHashKey, msg)
+			if f.badHash {
+				serverHash[0] ^= 0xff
+			}
+			reply = fmt.Sprintf("250 AUTHCHALLENGE SERVERHASH=%x "+
+				"SERVERNONCE=%x\r\n", serverHash, f.serverNonce)
+
+		case "AUTHENTICATE":
+			var ok bool
+			switch {
+			case f.password != "":
+				ok = len(fields) == 2 &&
+					fields[1] == quoteTorString(f.password)
+			case f.cookie != nil:
+				msg := append(append(append([]byte{}, f.cookie...),
+					clientNonce...), f.serverNonce...)
+				want := hex.EncodeToString(torHMAC(torClientHashKey, msg))
+				ok = len(fields) == 2 && fields[1] == want
+			default:
+				ok = len(fields) == 1
+			}
+			if !ok {
+				reply = "515 Authentication failed\r\n"
+				break
+			}
+			f.authenticated = true
+			reply = "250 OK\r\n"
+
+		case "ADD_ONION":
+			if !f.authenticated {
+				reply = "514 Authentication required.\r\n"
+				break
+			}
+			f.addOnionCmd = line
+			reply = "250-ServiceID=" + strings.Repeat("a", 56) + "\r\n"
+			if strings.HasPrefix(fields[1], "NEW:") {
+				reply += "250-PrivateKey=ED25519-V3:c2VjcmV0\r\n"
+			}
+			reply += "250 OK\r\n"
+
+		default:
+			reply = "510 Unrecognized command\r\n"
+		}
+		if _, err := conn.Write([]byte(reply)); err != nil {
+			return
+		}
+	}
+}
+
+// TestTorControlSafeCookie ensures the controller authenticates using
+// SAFECOOKIE and creates a new ED25519-V3 onion service.
+func TestTorControlSafeCookie(t *testing.T) {
+	dir, err := ioutil.TempDir("", "torcontrol")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	f := newFakeTorControl(t, "COOKIE,SAFECOOKIE")
+	defer f.listener.Close()
+	f.cookie = bytes.Repeat([]byte{0x01}, torCookieLen)
+	f.cookieFile = filepath.Join(dir, "control_auth_cookie")
+	if err := ioutil.WriteFile(f.cookieFile, f.cookie, 0600); err != nil {
+		t.Fatalf("unable to write cookie: %v", err)
+	}
+
+	c, err := DialTorControl(f.listener.Addr().String())
+	if err != nil {
+		t.Fatalf("unable to dial control port: %v", err)
+	}
+	if err := c.Authenticate(""); err != nil {
+		t.Fatalf("unable to authenticate: %v", err)
+	}
+	service, err := c.AddOnion("", 5889, "127.0.0.1:5889")
+	if err != nil {
+		t.Fatalf("unable to add onion: %v", err)
+	}
+	c.Close()
+	<-f.done
+
+	if service.ServiceID != strings.Repeat("a", 56) {
+		t.Errorf("unexpected service id %q", service.ServiceID)
+	}
+	if service.PrivateKey != "ED25519-V3:c2VjcmV0" {
+		t.Errorf("unexpected private key %q", service.PrivateKey)
+	}
+	want := "ADD_ONION NEW:ED25519-V3 Port=5889,127.0.0.1:5889"
+	if f.addOnionCmd != want {
+		t.Errorf("unexpected command %q, want %q", f.addOnionCmd, want)
+	}
+}
+
+// TestTorControlBadServerHash ensures SAFECOOKIE authentication is aborted
+// when the control port does not prove knowledge of the cookie.
+func TestTorControlBadServerHash(t *testing.T) {
+	dir, err := ioutil.TempDir("", "torcontrol")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	f := newFakeTorControl(t, "SAFECOOKIE")
+	defer f.listener.Close()
+	f.badHash = true
+	f.cookie = bytes.Repeat([]byte{0x01}, torCookieLen)
+	f.cookieFile = filepath.Join(dir, "control_auth_cookie")
+	if err := ioutil.WriteFile(f.cookieFile, f.cookie, 0600); err != nil {
+		t.Fatalf("unable to write cookie: %v", err)
+	}
+
+	c, err := DialTorControl(f.listener.Addr().String())
+	if err != nil {
+		t.Fatalf("unable to dial control port: %v", err)
+	}
+	defer c.Close()
+	if err := c.Authenticate(""); err != ErrTorInvalidServerHash {
+		t.Fatalf("unexpected error %v, want %v", err,
+			ErrTorInvalidServerHash)
+	}
+}
+
+// TestTorControlHashedPassword ensures the controller authenticates using a
+// password and reuses an existing private key.
+func TestTorControlHashedPassword(t *testing.T) {
+	f := newFakeTorControl(t, "HASHEDPASSWORD")
+	defer f.listener.Close()
+	f.password = "pass\"word"
+
+	c, err := DialTorControl(f.listener.Addr().String())
+	if err != nil {
+		t.Fatalf("unable to dial control port: %v", err)
+	}
+
+	// A missing password must not be usable with HASHEDPASSWORD.
+	if err := c.Authenticate(""); err != ErrTorNoAuthMethod {
+		t.Fatalf("unexpected error %v, want %v", err, ErrTorNoAuthMethod)
+	}
+
+	if err := c.Authenticate(f.password); err != nil {
+		t.Fatalf("unable to authenticate: %v", err)
+	}
+	service, err := c.AddOnion("ED25519-V3:c2VjcmV0", 5889, "127.0.0.1:15889")
+	if err != nil {
+		t.Fatalf("unable to add onion: %v", err)
+	}
+	c.Close()
+	<-f.done
+
+	if service.PrivateKey != "" {
+		t.Errorf("unexpected private key %q", service.PrivateKey)
+	}
+	want := "ADD_ONION ED25519-V3:c2VjcmV0 Port=5889,127.0.0.1:15889"
+	if f.addOnionCmd != want {
+		t.Errorf("unexpected command %q, want %q", f.addOnionCmd, want)
+	}
+}
+
+// TestTorControlAuthFailure ensures error replies are returned as a
+// TorControlError.
+func TestTorControlAuthFailure(t *testing.T) {
+	f := newFakeTorControl(t, "HASHEDPASSWORD")
+	defer f.listener.Close()
+	f.password = "secret"
+
+	c, err := DialTorControl(f.listener.Addr().String())
+	if err != nil {
+		t.Fatalf("unable to dial control port: %v", err)
+	}
+	defer c.Close()
+
+	err = c.Authenticate("wrong")
+	if e, ok := err.(*TorControlError); !ok || e.Code != 515 {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+// TestParseTorReplyArgs ensures reply arguments including quoted strings are
+// parsed correctly.
+func TestParseTorReplyArgs(t *testing.T) {
+	args := parseTorReplyArgs(`METHODS=COOKIE,SAFECOOKIE COOKIEFILE="/a b/\"c\"" flag X=1`)
+	want := map[string]string{
+		"METHODS":    "COOKIE,SAFECOOKIE",
+		"COOKIEFILE": `/a b/"c"`,
+		"X":          "1",
+	}
+	if len(args) != len(want) {
+		t.Fatalf("unexpected args %v", args)
+	}
+	for k, v := range want {
+		if args[k] != v {
+			t.Errorf("arg %s: got %q, want %q", k, args[k], v)
+		}
+	}
+}
+
+// TestTorControlWait ensures Wait discards asynchronous replies and returns
+// once the control connection is closed.
+func TestTorControlWait(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %v", err)
+	}
+	defer l.Close()
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		conn.Write([]byte("650 STATUS_GENERAL NOTICE CLOCK_JUMPED\r\n"))
+		conn.Close()
+	}()
+
+	c, err := DialTorControl(l.Addr().String())
+	if err != nil {
+		t.Fatalf("unable to dial control port: %v", err)
+	}
+	defer c.Close()
+
+	if err := c.Wait(); err == nil {
+		t.Fatal("Wait returned without an error")
+	}
+}
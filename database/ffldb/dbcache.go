@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/vertcoin/vtcd/database/internal/treap"
+	"github.com/vertcoin/vtcd/metrics"
 	"github.com/btcsuite/goleveldb/leveldb"
 	"github.com/btcsuite/goleveldb/leveldb/iterator"
 	"github.com/btcsuite/goleveldb/leveldb/util"
@@ -39,6 +40,20 @@ const (
 	ldbRecordIKeySize  = 8
 )
 
+var (
+	// cacheFlushes counts the flushes of the database cache which wrote
+	// data to the underlying database.
+	cacheFlushes = metrics.NewCounter("vtcd_ffldb_cache_flushes_total",
+		"Number of database cache flushes to the underlying database.")
+
+	// cacheFlushSeconds tracks the time taken to write the database cache
+	// to the underlying database.
+	cacheFlushSeconds = metrics.NewHistogram(
+		"vtcd_ffldb_cache_flush_seconds",
+		"Time taken to flush the database cache in seconds.",
+		metrics.ExponentialBuckets(0.01, 2, 12))
+)
+
 // ldbCacheIter wraps a treap iterator to provide the additional functionality
 // needed to satisfy the leveldb iterator.Iterator interface.
 type ldbCacheIter struct {
@@ -510,9 +525,12 @@ func (c *dbCache) flush() error {
 	}
 
 	// Perform all leveldb updates using an atomic transaction.
+	start := time.Now()
 	if err := c.commitTreaps(cachedKeys, cachedRemove); err != nil {
 		return err
 	}
+	cacheFlushes.Inc()
+	cacheFlushSeconds.Observe(time.Since(start).Seconds())
 
 	// Clear the cache since it has been flushed.
 	c.cacheLock.Lock()
@@ -43,21 +43,75 @@ Application Options:
   -P, --rpcpass=            Password for RPC connections
       --rpclimituser=       Username for limited RPC connections
       --rpclimitpass=       Password for limited RPC connections
+      --rpcauth=            Hashed credentials of an additional RPC user in the
+                            form USER:SALT$HMAC, where HMAC is the hex encoded
+                            HMAC-SHA256 of the password keyed by SALT -- may be
+                            specified multiple times
+      --rpcallowlist=       Restrict an RPC user to a comma separated list of
+                            methods in the form USER:METHOD,METHOD,... -- may be
+                            specified multiple times
+      --rpcratelimit=       Limit the rate at which RPC users may call methods in
+                            the form USER:METHOD:RATE[:BURST], where USER and
+                            METHOD may be * to match all users and methods and
+                            RATE is in calls per second -- may be specified
+                            multiple times
+      --rpccookiefile=      File the RPC authentication cookie is written to
+                            when no rpcpass is specified (default: .cookie in
+                            the data directory)
       --rpclisten=          Add an interface/port to listen for RPC connections
                             (default port: 9334, testnet: 19334)
+      --grpclisten=         Add an interface/port to listen for gRPC connections
+                            (default port: 5890, testnet: 15890) -- The gRPC
+                            server uses the credentials and TLS settings of the
+                            RPC server
       --rpccert=            File containing the certificate file
       --rpckey=             File containing the certificate key
       --rpcmaxclients=      Max number of RPC clients for standard connections
                             (10)
       --rpcmaxwebsockets=   Max number of RPC websocket connections (25)
+      --rpcmaxbatchsize=    Max number of requests in a single JSON-RPC batch
+                            (1000)
+      --rpcwsqueuesize=     Max number of notifications queued for an RPC
+                            websocket client, and kept for replay when its
+                            session is resumed (1000)
+      --rpcwsqueuepolicy=   Action taken when the notification queue of an RPC
+                            websocket client is full: disconnect the client, or
+                            coalesce consecutive blockconnected notifications
+                            before disconnecting it {disconnect, coalesce}
+                            (disconnect)
+      --rpcwssessiongrace=  Time a disconnected RPC websocket client may resume
+                            its session to keep its notification requests and
+                            receive the notifications it missed -- 0 to disable
+                            (1m0s)
+      --rpcauditlog         Record every RPC call in the file rpcaudit.log in the
+                            log directory
       --rpcquirks           Mirror some JSON-RPC quirks of Bitcoin Core -- NOTE:
                             Discouraged unless interoperability issues need to
                             be worked around
+      --rest                Serve read-only chain and mempool data over an
+                            unauthenticated REST interface on the RPC listeners
       --norpc               Disable built-in RPC server -- NOTE: The RPC server
-                            is disabled by default if no rpcuser/rpcpass or
-                            rpclimituser/rpclimitpass is specified
+                            is enabled by default and uses cookie
+                            authentication for admin access if no rpcpass is
+                            specified
       --notls               Disable TLS for the RPC server -- NOTE: This is only
                             allowed if the RPC server is bound to localhost
+      --zmqpubhashblock=    Publish the hashes of connected blocks on the given
+                            ZeroMQ endpoint (eg. tcp://127.0.0.1:28332)
+      --zmqpubrawblock=     Publish connected blocks on the given ZeroMQ
+                            endpoint (eg. tcp://127.0.0.1:28332)
+      --zmqpubhashtx=       Publish the hashes of mempool and block transactions
+                            on the given ZeroMQ endpoint (eg.
+                            tcp://127.0.0.1:28332)
+      --zmqpubrawtx=        Publish mempool and block transactions on the given
+                            ZeroMQ endpoint (eg. tcp://127.0.0.1:28332)
+      --zmqpubsequence=     Publish block connection and mempool events on the
+                            given ZeroMQ endpoint (eg. tcp://127.0.0.1:28332)
+      --zmqpubhwm=          Max number of ZeroMQ messages queued per subscriber
+                            before further messages are dropped (1000)
+      --metricslisten=      Add an interface/port to serve Prometheus metrics on
+                            below /metrics (default port: 9466) -- NOTE: Metrics
+                            are served without authentication
       --nodnsseed           Disable DNS seeding for peers
       --externalip=         Add an ip to the list of local addresses we claim to
                             listen on to peers
@@ -88,7 +142,8 @@ Application Options:
                             <subsystem>=<level>,<subsystem2>=<level>,... to set
                             the log level for individual subsystems -- Use show
                             to list available subsystems (info)
-      --upnp                Use UPnP to map our listening port outside of NAT
+      --upnp                Use UPnP, NAT-PMP or PCP to map our listening port
+                            outside of NAT
       --minrelaytxfee=      The minimum transaction fee in BTC/kB to be
                             considered a non-zero fee.
       --limitfreerelay=     Limit relay of transactions with no transaction fee
@@ -98,11 +153,24 @@ Application Options:
                             high priority for relaying
       --maxorphantx=        Max number of orphan transactions to keep in memory
                             (100)
+      --maxmempool=         Max total size in megabytes of the transactions in
+                            the memory pool -- transactions with the lowest fee
+                            rates are evicted once it is exceeded (0 for no
+                            limit) (300)
       --generate            Generate (mine) bitcoins using the CPU
       --miningaddr=         Add the specified payment address to the list of
                             addresses to use for generated blocks -- At least
                             one address is required if the generate option is
                             set
+      --stratumlisten=      Add an interface/port to listen for Stratum mining
+                            connections (default port: 3333) -- At least one
+                            mining address is required
+      --stratumpass=        Password Stratum miners must use to authorize
+                            workers (default: any password is accepted)
+      --stratumdifficulty=  Initial and minimum share difficulty of Stratum
+                            connections relative to the proof of work limit (1)
+      --coinbasetag=        Tag to add to the coinbase script of generated
+                            blocks instead of the default flags
       --blockminsize=       Mininum block size in bytes to be used when creating
                             a block
       --blockmaxsize=       Maximum block size in bytes to be used when creating
@@ -118,6 +186,17 @@ Application Options:
                             default settings for the active network.
       --rejectnonstd        Reject non-standard transactions regardless of the
                             default settings for the active network.
+      --datacarriersize=    Maximum size in bytes of standard output scripts
+                            which only carry data (83)
+      --datacarrier=        Relay and mine transactions with outputs which only
+                            carry data (--datacarrier=0 to disable) (true)
+      --dustrelayfee=       The fee rate in BTC/kB used to decide whether a
+                            transaction output is dust (0.00001)
+      --nobaremultisig      Do not relay or mine transactions with bare
+                            multi-signature outputs
+      --maxstdtxweight=     Maximum weight of standard transactions (400000)
+      --maxstdtxsigopcost=  Maximum signature operation cost of transactions
+                            which are relayed or mined (20000)
 
 Help Options:
   -h, --help           Show this help message
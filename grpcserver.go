@@ -0,0 +1,620 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/vertcoin/vtcd/btcjson"
+	"github.com/vertcoin/vtcd/chaincfg/chainhash"
+	"github.com/vertcoin/vtcd/vtcdrpc"
+	"github.com/vertcoin/vtcd/wire"
+	"github.com/vertcoin/vtcutil"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// grpcMaxQueuedNotifications is the maximum number of notifications which are
+// queued for a subscription.  Subscriptions which fall further behind are
+// ended, since skipping notifications would leave the client with an
+// inconsistent view of the chain.
+const grpcMaxQueuedNotifications = 1000
+
+// grpcMethods maps the full names of the gRPC methods to the JSON-RPC commands
+// they mirror.  Users are only allowed to call a method when they are allowed
+// to call its command.
+var grpcMethods = map[string]string{
+	"/vtcdrpc.ChainService/GetBestBlock":          "getbestblock",
+	"/vtcdrpc.ChainService/GetBlockHash":          "getblockhash",
+	"/vtcdrpc.ChainService/GetBlock":              "getblock",
+	"/vtcdrpc.ChainService/GetBlockHeader":        "getblockheader",
+	"/vtcdrpc.ChainService/GetRawTransaction":     "getrawtransaction",
+	"/vtcdrpc.ChainService/GetMempoolInfo":        "getmempoolinfo",
+	"/vtcdrpc.ChainService/GetRawMempool":         "getrawmempool",
+	"/vtcdrpc.ChainService/SendRawTransaction":    "sendrawtransaction",
+	"/vtcdrpc.ChainService/SubscribeBlocks":       "notifyblocks",
+	"/vtcdrpc.ChainService/SubscribeTransactions": "loadtxfilter",
+}
+
+// grpcSubscription houses the notifications queued for a streaming call along
+// with the transaction filter of transaction subscriptions.  The notification
+// channel is closed when the subscription falls too far behind.
+type grpcSubscription struct {
+	filter *wsClientFilter
+	ntfns  chan interface{}
+}
+
+// grpcServer serves the gRPC API of the RPC server.  Its methods run the
+// handlers of the JSON-RPC commands they mirror and convert their results.
+type grpcServer struct {
+	rpc    *rpcServer
+	server *grpc.Server
+
+	mtx           sync.Mutex
+	subscriptions map[*grpcSubscription]struct{}
+}
+
+// Ensure grpcServer implements the vtcdrpc.ChainServiceServer interface.
+var _ vtcdrpc.ChainServiceServer = (*grpcServer)(nil)
+
+// newGRPCServer returns a new gRPC server for the passed RPC server.  Calls are
+// served over TLS with the passed configuration unless it is nil.
+func newGRPCServer(s *rpcServer, tlsConfig *tls.Config) *grpcServer {
+	g := &grpcServer{
+		rpc:           s,
+		subscriptions: make(map[*grpcSubscription]struct{}),
+	}
+	opts := []grpc.ServerOption{
+		grpc.UnaryInterceptor(g.unaryInterceptor),
+		grpc.StreamInterceptor(g.streamInterceptor),
+	}
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	g.server = grpc.NewServer(opts...)
+	vtcdrpc.RegisterChainServiceServer(g.server, g)
+	return g
+}
+
+// checkAuth authenticates the user of a call with the HTTP basic
+// authentication credentials in its authorization metadata and ensures the
+// user is allowed to call the passed method.  The returned call must be
+// finished once it has been serviced.
+func (g *grpcServer) checkAuth(ctx context.Context, fullMethod string) (*rpcCall, error) {
+	remoteAddr := "unknown"
+	if p, ok := peer.FromContext(ctx); ok {
+		remoteAddr = p.Addr.String()
+	}
+
+	var user *rpcUser
+	md, _ := metadata.FromIncomingContext(ctx)
+	if auth := md["authorization"]; len(auth) > 0 {
+		r := http.Request{Header: http.Header{"Authorization": auth}}
+		if name, pass, ok := r.BasicAuth(); ok {
+			user = g.rpc.auth.authenticate(name, pass)
+		}
+	}
+	if user == nil {
+		rpcsLog.Warnf("gRPC authentication failure from %s", remoteAddr)
+		return nil, status.Error(codes.Unauthenticated,
+			"invalid credentials")
+	}
+
+	method, ok := grpcMethods[fullMethod]
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented,
+			"unknown method %s", fullMethod)
+	}
+	call, jsonErr := g.rpc.calls.begin(user, remoteAddr, rpcTransportGRPC,
+		method)
+	if jsonErr != nil {
+		code := codes.PermissionDenied
+		if jsonErr.Code == btcjson.ErrRPCRateLimited {
+			code = codes.ResourceExhausted
+		}
+		err := status.Error(code, jsonErr.Message)
+		g.endCall(call, err)
+		return nil, err
+	}
+	return call, nil
+}
+
+// endCall finishes the passed call with the status of the passed error.
+func (g *grpcServer) endCall(call *rpcCall, err error) {
+	var code int
+	if err != nil {
+		s, _ := status.FromError(err)
+		code = int(s.Code())
+	}
+	g.rpc.calls.end(call, code)
+}
+
+// unaryInterceptor authenticates and authorizes unary calls before passing
+// them to their handler, and records them once they finish.
+func (g *grpcServer) unaryInterceptor(ctx context.Context, req interface{},
+	info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+
+	call, err := g.checkAuth(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := handler(ctx, req)
+	g.endCall(call, err)
+	return resp, err
+}
+
+// streamInterceptor authenticates and authorizes streaming calls before
+// passing them to their handler, and records them once they finish.
+func (g *grpcServer) streamInterceptor(srv interface{}, ss grpc.ServerStream,
+	info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+	call, err := g.checkAuth(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	err = handler(srv, ss)
+	g.endCall(call, err)
+	return err
+}
+
+// grpcError converts an error returned by an RPC handler to a gRPC status
+// error.  The codes of the JSON-RPC errors are mapped to the closest gRPC
+// code.
+func grpcError(err error) error {
+	jsonErr, ok := err.(*btcjson.RPCError)
+	if !ok {
+		return status.Error(codes.Internal, err.Error())
+	}
+	code := codes.Internal
+	switch jsonErr.Code {
+	// Also covers missing blocks and transactions.
+	case btcjson.ErrRPCInvalidAddressOrKey:
+		code = codes.NotFound
+
+	// Also covers hex decoding errors.
+	case btcjson.ErrRPCDeserialization, btcjson.ErrRPCInvalidParameter,
+		btcjson.ErrRPCInvalidParams.Code:
+		code = codes.InvalidArgument
+
+	// The only handler mirrored by the gRPC API returning this code is
+	// getblockhash, for heights beyond the main chain.
+	case btcjson.ErrRPCOutOfRange:
+		code = codes.OutOfRange
+	}
+	return status.Error(code, jsonErr.Message)
+}
+
+// grpcHash converts the passed hash in internal byte order to a chainhash.Hash.
+func grpcHash(b []byte) (*chainhash.Hash, error) {
+	hash, err := chainhash.NewHash(b)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return hash, nil
+}
+
+// grpcDecodeHex decodes a hex-encoded result of an RPC handler.
+func grpcDecodeHex(result interface{}) ([]byte, error) {
+	b, err := hex.DecodeString(result.(string))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return b, nil
+}
+
+// GetBestBlock returns the hash and height of the best block.
+func (g *grpcServer) GetBestBlock(ctx context.Context, req *vtcdrpc.GetBestBlockRequest) (*vtcdrpc.GetBestBlockResponse, error) {
+	result, err := handleGetBestBlock(g.rpc, &btcjson.GetBestBlockCmd{},
+		ctx.Done())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	best := result.(*btcjson.GetBestBlockResult)
+	hash, err := chainhash.NewHashFromStr(best.Hash)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &vtcdrpc.GetBestBlockResponse{
+		Hash:   hash[:],
+		Height: best.Height,
+	}, nil
+}
+
+// GetBlockHash returns the hash of the main chain block at the requested
+// height.
+func (g *grpcServer) GetBlockHash(ctx context.Context, req *vtcdrpc.GetBlockHashRequest) (*vtcdrpc.GetBlockHashResponse, error) {
+	result, err := handleGetBlockHash(g.rpc,
+		&btcjson.GetBlockHashCmd{Index: req.Height}, ctx.Done())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	hash, err := chainhash.NewHashFromStr(result.(string))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &vtcdrpc.GetBlockHashResponse{Hash: hash[:]}, nil
+}
+
+// GetBlock returns the serialized block with the requested hash along with its
+// height and number of confirmations.
+func (g *grpcServer) GetBlock(ctx context.Context, req *vtcdrpc.GetBlockRequest) (*vtcdrpc.GetBlockResponse, error) {
+	hash, err := grpcHash(req.Hash)
+	if err != nil {
+		return nil, err
+	}
+	result, err := handleGetBlock(g.rpc, &btcjson.GetBlockCmd{
+		Hash:      hash.String(),
+		Verbosity: btcjson.Verbosity(btcjson.BlockVerbosityHex),
+	}, ctx.Done())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	block, err := grpcDecodeHex(result)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err = handleGetBlockHeader(g.rpc, &btcjson.GetBlockHeaderCmd{
+		Hash:    hash.String(),
+		Verbose: btcjson.Bool(true),
+	}, ctx.Done())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	header := result.(btcjson.GetBlockHeaderVerboseResult)
+	return &vtcdrpc.GetBlockResponse{
+		Block:         block,
+		Height:        int64(header.Height),
+		Confirmations: header.Confirmations,
+	}, nil
+}
+
+// GetBlockHeader returns the serialized header of the block with the requested
+// hash along with its height and number of confirmations.
+func (g *grpcServer) GetBlockHeader(ctx context.Context, req *vtcdrpc.GetBlockHeaderRequest) (*vtcdrpc.GetBlockHeaderResponse, error) {
+	hash, err := grpcHash(req.Hash)
+	if err != nil {
+		return nil, err
+	}
+	result, err := handleGetBlockHeader(g.rpc, &btcjson.GetBlockHeaderCmd{
+		Hash:    hash.String(),
+		Verbose: btcjson.Bool(false),
+	}, ctx.Done())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	header, err := grpcDecodeHex(result)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err = handleGetBlockHeader(g.rpc, &btcjson.GetBlockHeaderCmd{
+		Hash:    hash.String(),
+		Verbose: btcjson.Bool(true),
+	}, ctx.Done())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	verbose := result.(btcjson.GetBlockHeaderVerboseResult)
+	return &vtcdrpc.GetBlockHeaderResponse{
+		Header:        header,
+		Height:        verbose.Height,
+		Confirmations: verbose.Confirmations,
+	}, nil
+}
+
+// GetRawTransaction returns the serialized transaction with the requested hash
+// along with the hash of the block including it and its number of
+// confirmations.
+func (g *grpcServer) GetRawTransaction(ctx context.Context, req *vtcdrpc.GetRawTransactionRequest) (*vtcdrpc.GetRawTransactionResponse, error) {
+	hash, err := grpcHash(req.Hash)
+	if err != nil {
+		return nil, err
+	}
+	result, err := handleGetRawTransaction(g.rpc,
+		&btcjson.GetRawTransactionCmd{
+			Txid:    hash.String(),
+			Verbose: btcjson.Int(1),
+		}, ctx.Done())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	rawTx := result.(btcjson.TxRawResult)
+	tx, err := grpcDecodeHex(rawTx.Hex)
+	if err != nil {
+		return nil, err
+	}
+	resp := &vtcdrpc.GetRawTransactionResponse{
+		Transaction:   tx,
+		Confirmations: rawTx.Confirmations,
+	}
+	if rawTx.BlockHash != "" {
+		blockHash, err := chainhash.NewHashFromStr(rawTx.BlockHash)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		resp.BlockHash = blockHash[:]
+	}
+	return resp, nil
+}
+
+// GetMempoolInfo returns the number of transactions in the memory pool and
+// their total size.
+func (g *grpcServer) GetMempoolInfo(ctx context.Context, req *vtcdrpc.GetMempoolInfoRequest) (*vtcdrpc.GetMempoolInfoResponse, error) {
+	result, err := handleGetMempoolInfo(g.rpc, &btcjson.GetMempoolInfoCmd{},
+		ctx.Done())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	info := result.(*btcjson.GetMempoolInfoResult)
+	return &vtcdrpc.GetMempoolInfoResponse{
+		Size:  info.Size,
+		Bytes: info.Bytes,
+	}, nil
+}
+
+// GetRawMempool returns the transactions in the memory pool ordered by hash.
+func (g *grpcServer) GetRawMempool(ctx context.Context, req *vtcdrpc.GetRawMempoolRequest) (*vtcdrpc.GetRawMempoolResponse, error) {
+	result, err := handleGetRawMempool(g.rpc,
+		&btcjson.GetRawMempoolCmd{Verbose: btcjson.Bool(true)}, ctx.Done())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	verbose := result.(map[string]*btcjson.GetRawMempoolVerboseResult)
+
+	resp := &vtcdrpc.GetRawMempoolResponse{
+		Entries: make([]*vtcdrpc.GetRawMempoolResponse_Entry, 0,
+			len(verbose)),
+	}
+	for txid, desc := range verbose {
+		hash, err := chainhash.NewHashFromStr(txid)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		fee, err := vtcutil.NewAmount(desc.Fee)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		entry := &vtcdrpc.GetRawMempoolResponse_Entry{
+			Hash:   hash[:],
+			Size:   desc.Size,
+			Fee:    int64(fee),
+			Time:   desc.Time,
+			Height: desc.Height,
+		}
+		for _, depend := range desc.Depends {
+			dependHash, err := chainhash.NewHashFromStr(depend)
+			if err != nil {
+				return nil, status.Error(codes.Internal,
+					err.Error())
+			}
+			entry.Depends = append(entry.Depends, dependHash[:])
+		}
+		resp.Entries = append(resp.Entries, entry)
+	}
+
+	sort.Sort(grpcMempoolEntries(resp.Entries))
+	return resp, nil
+}
+
+// grpcMempoolEntries implements sort.Interface to order memory pool entries by
+// their hashes in internal byte order.
+type grpcMempoolEntries []*vtcdrpc.GetRawMempoolResponse_Entry
+
+func (e grpcMempoolEntries) Len() int      { return len(e) }
+func (e grpcMempoolEntries) Swap(i, j int) { e[i], e[j] = e[j], e[i] }
+func (e grpcMempoolEntries) Less(i, j int) bool {
+	return bytes.Compare(e[i].Hash, e[j].Hash) < 0
+}
+
+// SendRawTransaction submits a serialized transaction to the memory pool and
+// relays it to the network.
+func (g *grpcServer) SendRawTransaction(ctx context.Context, req *vtcdrpc.SendRawTransactionRequest) (*vtcdrpc.SendRawTransactionResponse, error) {
+	result, err := handleSendRawTransaction(g.rpc,
+		&btcjson.SendRawTransactionCmd{
+			HexTx:         hex.EncodeToString(req.Transaction),
+			AllowHighFees: btcjson.Bool(req.AllowHighFees),
+		}, ctx.Done())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	hash, err := chainhash.NewHashFromStr(result.(string))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &vtcdrpc.SendRawTransactionResponse{Hash: hash[:]}, nil
+}
+
+// subscribe registers a new subscription with the passed transaction filter,
+// which is nil for block subscriptions.
+func (g *grpcServer) subscribe(filter *wsClientFilter) *grpcSubscription {
+	sub := &grpcSubscription{
+		filter: filter,
+		ntfns:  make(chan interface{}, grpcMaxQueuedNotifications),
+	}
+	g.mtx.Lock()
+	g.subscriptions[sub] = struct{}{}
+	g.mtx.Unlock()
+	return sub
+}
+
+// unsubscribe removes the passed subscription unless it was already removed
+// for falling behind.
+func (g *grpcServer) unsubscribe(sub *grpcSubscription) {
+	g.mtx.Lock()
+	delete(g.subscriptions, sub)
+	g.mtx.Unlock()
+}
+
+// notify queues the passed notification for the passed subscription.  A
+// subscription whose queue is full is removed and its notification channel
+// closed.
+//
+// This function MUST be called with the server lock held.
+func (g *grpcServer) notify(sub *grpcSubscription, ntfn interface{}) {
+	select {
+	case sub.ntfns <- ntfn:
+	default:
+		delete(g.subscriptions, sub)
+		close(sub.ntfns)
+	}
+}
+
+// serveSubscription sends the notifications of the passed subscription to the
+// passed stream until the call is cancelled, the subscription falls behind or
+// the server shuts down.
+func (g *grpcServer) serveSubscription(stream grpc.ServerStream, sub *grpcSubscription) error {
+	defer g.unsubscribe(sub)
+
+	for {
+		select {
+		case ntfn, ok := <-sub.ntfns:
+			if !ok {
+				return status.Error(codes.ResourceExhausted,
+					"subscription fell too far behind")
+			}
+			if err := stream.SendMsg(ntfn); err != nil {
+				return err
+			}
+
+		case <-stream.Context().Done():
+			return nil
+
+		case <-g.rpc.quit:
+			return status.Error(codes.Unavailable,
+				"server is shutting down")
+		}
+	}
+}
+
+// SubscribeBlocks streams a notification for every block connected to or
+// disconnected from the main chain.
+func (g *grpcServer) SubscribeBlocks(req *vtcdrpc.SubscribeBlocksRequest, stream vtcdrpc.ChainService_SubscribeBlocksServer) error {
+	return g.serveSubscription(stream, g.subscribe(nil))
+}
+
+// SubscribeTransactions streams the transactions accepted into the memory pool
+// or included in connected blocks which are relevant to the requested
+// addresses and outpoints.
+func (g *grpcServer) SubscribeTransactions(req *vtcdrpc.SubscribeTransactionsRequest, stream vtcdrpc.ChainService_SubscribeTransactionsServer) error {
+	params := g.rpc.cfg.ChainParams
+	for _, addr := range req.Addresses {
+		if _, err := vtcutil.DecodeAddress(addr, params); err != nil {
+			return status.Errorf(codes.InvalidArgument,
+				"invalid address %q: %v", addr, err)
+		}
+	}
+	outPoints := make([]wire.OutPoint, 0, len(req.Outpoints))
+	for _, op := range req.Outpoints {
+		hash, err := grpcHash(op.Hash)
+		if err != nil {
+			return err
+		}
+		outPoints = append(outPoints, wire.OutPoint{
+			Hash:  *hash,
+			Index: op.Index,
+		})
+	}
+
+	filter := newWSClientFilter(req.Addresses, outPoints, params)
+	return g.serveSubscription(stream, g.subscribe(filter))
+}
+
+// notifyTx queues a notification of the passed transaction for every
+// transaction subscription it is relevant to.  The block is nil for memory
+// pool transactions.
+//
+// This function MUST be called with the server lock held.
+func (g *grpcServer) notifyTx(tx *vtcutil.Tx, block *vtcutil.Block) {
+	var ntfn *vtcdrpc.TransactionNotification
+	for sub := range g.subscriptions {
+		if sub.filter == nil {
+			continue
+		}
+		sub.filter.mu.Lock()
+		matched := sub.filter.matchTx(tx, g.rpc.cfg.ChainParams)
+		sub.filter.mu.Unlock()
+		if !matched {
+			continue
+		}
+
+		if ntfn == nil {
+			var buf bytes.Buffer
+			if err := tx.MsgTx().Serialize(&buf); err != nil {
+				rpcsLog.Errorf("Unable to serialize transaction "+
+					"%s: %v", tx.Hash(), err)
+				return
+			}
+			ntfn = &vtcdrpc.TransactionNotification{
+				Transaction: buf.Bytes(),
+			}
+			if block != nil {
+				ntfn.BlockHash = block.Hash()[:]
+				ntfn.BlockHeight = block.Height()
+			}
+		}
+		g.notify(sub, ntfn)
+	}
+}
+
+// notifyBlock queues a notification of the passed block for every block
+// subscription.
+//
+// This function MUST be called with the server lock held.
+func (g *grpcServer) notifyBlock(block *vtcutil.Block, ntfnType vtcdrpc.BlockNotification_Type) {
+	var header bytes.Buffer
+	if err := block.MsgBlock().Header.Serialize(&header); err != nil {
+		rpcsLog.Errorf("Unable to serialize header of block %s: %v",
+			block.Hash(), err)
+		return
+	}
+	ntfn := &vtcdrpc.BlockNotification{
+		Type:   ntfnType,
+		Hash:   block.Hash()[:],
+		Height: block.Height(),
+		Header: header.Bytes(),
+	}
+	for sub := range g.subscriptions {
+		if sub.filter == nil {
+			g.notify(sub, ntfn)
+		}
+	}
+}
+
+// NotifyBlockConnected notifies block subscriptions of the passed block and
+// transaction subscriptions of its relevant transactions.
+func (g *grpcServer) NotifyBlockConnected(block *vtcutil.Block) {
+	g.mtx.Lock()
+	g.notifyBlock(block, vtcdrpc.BlockNotification_CONNECTED)
+	for _, tx := range block.Transactions() {
+		g.notifyTx(tx, block)
+	}
+	g.mtx.Unlock()
+}
+
+// NotifyBlockDisconnected notifies block subscriptions of the passed block.
+func (g *grpcServer) NotifyBlockDisconnected(block *vtcutil.Block) {
+	g.mtx.Lock()
+	g.notifyBlock(block, vtcdrpc.BlockNotification_DISCONNECTED)
+	g.mtx.Unlock()
+}
+
+// NotifyMempoolTx notifies transaction subscriptions of the passed transaction
+// if it is relevant to them.
+func (g *grpcServer) NotifyMempoolTx(tx *vtcutil.Tx) {
+	g.mtx.Lock()
+	g.notifyTx(tx, nil)
+	g.mtx.Unlock()
+}
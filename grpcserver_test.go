@@ -0,0 +1,363 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/vertcoin/vtcd/blockchain"
+	"github.com/vertcoin/vtcd/chaincfg/chainhash"
+	"github.com/vertcoin/vtcd/vtcdrpc"
+	"github.com/vertcoin/vtcutil"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// grpcTestHarness serves the gRPC API of a REST test harness over an
+// in-process connection.
+type grpcTestHarness struct {
+	*restTestHarness
+	grpc     *grpcServer
+	listener *bufconn.Listener
+}
+
+// newGRPCTestHarness returns a gRPC test harness whose RPC server has the
+// admin user "user" and the limited user "limited", which may call
+// getbestblock once.
+func newGRPCTestHarness(t *testing.T) *grpcTestHarness {
+	h := &grpcTestHarness{restTestHarness: newRESTTestHarness(t)}
+	auth, err := newRPCAuthenticator("user", "pass", "limited",
+		"limitpass", "", nil, nil)
+	if err != nil {
+		h.teardown()
+		t.Fatalf("newRPCAuthenticator: unexpected error: %v", err)
+	}
+	h.server.auth = auth
+	limits, err := parseRPCRateLimits([]string{"limited:getbestblock:0.001:1"},
+		auth)
+	if err != nil {
+		h.teardown()
+		t.Fatalf("parseRPCRateLimits: unexpected error: %v", err)
+	}
+	h.server.calls = newRPCCallTracker(newRPCRateLimiter(limits), nil)
+	h.grpc = newGRPCServer(h.server, nil)
+	h.server.grpc = h.grpc
+	h.listener = bufconn.Listen(1 << 20)
+	go h.grpc.server.Serve(h.listener)
+
+	teardown := h.teardown
+	h.teardown = func() {
+		h.grpc.server.Stop()
+		teardown()
+	}
+	return h
+}
+
+// dial returns a client of the harness authenticating with the passed
+// credentials.
+func (h *grpcTestHarness) dial(t *testing.T, user, pass string) (vtcdrpc.ChainServiceClient, func()) {
+	conn, err := grpc.Dial("bufconn",
+		grpc.WithDialer(func(string, time.Duration) (net.Conn, error) {
+			return h.listener.Dial()
+		}),
+		grpc.WithInsecure(),
+		grpc.WithPerRPCCredentials(&vtcdrpc.BasicAuth{
+			Username:   user,
+			Password:   pass,
+			DisableTLS: true,
+		}))
+	if err != nil {
+		t.Fatalf("unable to dial: %v", err)
+	}
+	return vtcdrpc.NewChainServiceClient(conn), func() { conn.Close() }
+}
+
+// checkCode ensures the passed error is a gRPC status error with the passed
+// code.
+func checkCode(t *testing.T, name string, err error, code codes.Code) {
+	if status.Code(err) != code {
+		t.Errorf("%s: unexpected error - got %v, want code %v", name,
+			err, code)
+	}
+}
+
+// TestGRPCServer ensures the gRPC methods return the same data as the RPC
+// handlers they mirror and enforce the authentication and method restrictions
+// of the RPC server.
+func TestGRPCServer(t *testing.T) {
+	h := newGRPCTestHarness(t)
+	defer h.teardown()
+	ctx := context.Background()
+
+	// Calls with invalid credentials are rejected and limited users may
+	// only call the methods mirroring commands they are allowed to call.
+	client, closeConn := h.dial(t, "user", "wrong")
+	_, err := client.GetBestBlock(ctx, &vtcdrpc.GetBestBlockRequest{})
+	checkCode(t, "GetBestBlock (invalid credentials)", err,
+		codes.Unauthenticated)
+	closeConn()
+	client, closeConn = h.dial(t, "limited", "limitpass")
+	_, err = client.GetMempoolInfo(ctx, &vtcdrpc.GetMempoolInfoRequest{})
+	checkCode(t, "GetMempoolInfo (limited)", err, codes.PermissionDenied)
+	_, err = client.GetBestBlock(ctx, &vtcdrpc.GetBestBlockRequest{})
+	checkCode(t, "GetBestBlock (limited)", err, codes.OK)
+	_, err = client.GetBestBlock(ctx, &vtcdrpc.GetBestBlockRequest{})
+	checkCode(t, "GetBestBlock (rate limited)", err,
+		codes.ResourceExhausted)
+	closeConn()
+
+	client, closeConn = h.dial(t, "user", "pass")
+	defer closeConn()
+
+	tip := h.blocks[len(h.blocks)-1].BlockHash()
+	best, err := client.GetBestBlock(ctx, &vtcdrpc.GetBestBlockRequest{})
+	if err != nil {
+		t.Fatalf("GetBestBlock: unexpected error: %v", err)
+	}
+	if !bytes.Equal(best.Hash, tip[:]) || int(best.Height) != len(h.blocks) {
+		t.Errorf("GetBestBlock: unexpected result %x at height %d",
+			best.Hash, best.Height)
+	}
+
+	block := h.blocks[0]
+	blockHash := block.BlockHash()
+	hashResp, err := client.GetBlockHash(ctx,
+		&vtcdrpc.GetBlockHashRequest{Height: 1})
+	if err != nil {
+		t.Fatalf("GetBlockHash: unexpected error: %v", err)
+	}
+	if !bytes.Equal(hashResp.Hash, blockHash[:]) {
+		t.Errorf("GetBlockHash: unexpected hash %x", hashResp.Hash)
+	}
+	_, err = client.GetBlockHash(ctx,
+		&vtcdrpc.GetBlockHashRequest{Height: 1000})
+	checkCode(t, "GetBlockHash (out of range)", err, codes.OutOfRange)
+
+	var blockBuf, headerBuf bytes.Buffer
+	if err := block.Serialize(&blockBuf); err != nil {
+		t.Fatalf("unable to serialize block: %v", err)
+	}
+	if err := block.Header.Serialize(&headerBuf); err != nil {
+		t.Fatalf("unable to serialize header: %v", err)
+	}
+	blockResp, err := client.GetBlock(ctx,
+		&vtcdrpc.GetBlockRequest{Hash: blockHash[:]})
+	if err != nil {
+		t.Fatalf("GetBlock: unexpected error: %v", err)
+	}
+	if !bytes.Equal(blockResp.Block, blockBuf.Bytes()) ||
+		blockResp.Height != 1 ||
+		int(blockResp.Confirmations) != len(h.blocks) {
+
+		t.Errorf("GetBlock: unexpected result at height %d with %d "+
+			"confirmations", blockResp.Height,
+			blockResp.Confirmations)
+	}
+	headerResp, err := client.GetBlockHeader(ctx,
+		&vtcdrpc.GetBlockHeaderRequest{Hash: blockHash[:]})
+	if err != nil {
+		t.Fatalf("GetBlockHeader: unexpected error: %v", err)
+	}
+	if !bytes.Equal(headerResp.Header, headerBuf.Bytes()) ||
+		headerResp.Height != 1 ||
+		int(headerResp.Confirmations) != len(h.blocks) {
+
+		t.Errorf("GetBlockHeader: unexpected result at height %d with "+
+			"%d confirmations", headerResp.Height,
+			headerResp.Confirmations)
+	}
+	var unknown chainhash.Hash
+	_, err = client.GetBlock(ctx, &vtcdrpc.GetBlockRequest{Hash: unknown[:]})
+	checkCode(t, "GetBlock (unknown)", err, codes.NotFound)
+	_, err = client.GetBlockHeader(ctx,
+		&vtcdrpc.GetBlockHeaderRequest{Hash: unknown[:]})
+	checkCode(t, "GetBlockHeader (unknown)", err, codes.NotFound)
+	_, err = client.GetBlock(ctx, &vtcdrpc.GetBlockRequest{Hash: []byte{1}})
+	checkCode(t, "GetBlock (invalid hash)", err, codes.InvalidArgument)
+
+	// Only memory pool transactions are available without the transaction
+	// index.
+	var txBuf bytes.Buffer
+	if err := h.mempoolTx.MsgTx().Serialize(&txBuf); err != nil {
+		t.Fatalf("unable to serialize tx: %v", err)
+	}
+	txResp, err := client.GetRawTransaction(ctx,
+		&vtcdrpc.GetRawTransactionRequest{Hash: h.mempoolTx.Hash()[:]})
+	if err != nil {
+		t.Fatalf("GetRawTransaction: unexpected error: %v", err)
+	}
+	if !bytes.Equal(txResp.Transaction, txBuf.Bytes()) ||
+		len(txResp.BlockHash) != 0 || txResp.Confirmations != 0 {
+
+		t.Errorf("GetRawTransaction: unexpected result %v", txResp)
+	}
+	coinbaseHash := block.Transactions[0].TxHash()
+	_, err = client.GetRawTransaction(ctx,
+		&vtcdrpc.GetRawTransactionRequest{Hash: coinbaseHash[:]})
+	checkCode(t, "GetRawTransaction (no index)", err, codes.NotFound)
+
+	info, err := client.GetMempoolInfo(ctx, &vtcdrpc.GetMempoolInfoRequest{})
+	if err != nil {
+		t.Fatalf("GetMempoolInfo: unexpected error: %v", err)
+	}
+	if info.Size != 1 || info.Bytes != int64(txBuf.Len()) {
+		t.Errorf("GetMempoolInfo: unexpected result %v", info)
+	}
+	mempoolResp, err := client.GetRawMempool(ctx,
+		&vtcdrpc.GetRawMempoolRequest{})
+	if err != nil {
+		t.Fatalf("GetRawMempool: unexpected error: %v", err)
+	}
+	if len(mempoolResp.Entries) != 1 {
+		t.Fatalf("GetRawMempool: unexpected number of entries %d",
+			len(mempoolResp.Entries))
+	}
+	entry := mempoolResp.Entries[0]
+	if !bytes.Equal(entry.Hash, h.mempoolTx.Hash()[:]) ||
+		entry.Fee != 100000 || int(entry.Size) != txBuf.Len() ||
+		len(entry.Depends) != 0 {
+
+		t.Errorf("GetRawMempool: unexpected entry %v", entry)
+	}
+
+	_, err = client.SendRawTransaction(ctx,
+		&vtcdrpc.SendRawTransactionRequest{Transaction: []byte{1, 2}})
+	checkCode(t, "SendRawTransaction (invalid)", err,
+		codes.InvalidArgument)
+}
+
+// TestGRPCSubscriptions ensures subscriptions stream connected and
+// disconnected blocks along with relevant memory pool and block transactions,
+// and are ended when they fall too far behind.
+func TestGRPCSubscriptions(t *testing.T) {
+	h := newGRPCTestHarness(t)
+	defer h.teardown()
+	client, closeConn := h.dial(t, "user", "pass")
+	defer closeConn()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Invalid filters are rejected.
+	badStream, err := client.SubscribeTransactions(ctx,
+		&vtcdrpc.SubscribeTransactionsRequest{
+			Addresses: []string{"invalid"},
+		})
+	if err != nil {
+		t.Fatalf("SubscribeTransactions: unexpected error: %v", err)
+	}
+	_, err = badStream.Recv()
+	checkCode(t, "SubscribeTransactions (invalid address)", err,
+		codes.InvalidArgument)
+
+	blockStream, err := client.SubscribeBlocks(ctx,
+		&vtcdrpc.SubscribeBlocksRequest{})
+	if err != nil {
+		t.Fatalf("SubscribeBlocks: unexpected error: %v", err)
+	}
+	coinbaseHash := h.blocks[0].Transactions[0].TxHash()
+	txStream, err := client.SubscribeTransactions(ctx,
+		&vtcdrpc.SubscribeTransactionsRequest{
+			Outpoints: []*vtcdrpc.OutPoint{{Hash: coinbaseHash[:]}},
+		})
+	if err != nil {
+		t.Fatalf("SubscribeTransactions: unexpected error: %v", err)
+	}
+	for i := 0; ; i++ {
+		h.grpc.mtx.Lock()
+		n := len(h.grpc.subscriptions)
+		h.grpc.mtx.Unlock()
+		if n == 2 {
+			break
+		}
+		if i == 500 {
+			t.Fatalf("subscriptions were not registered")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	// The memory pool transaction spends the watched outpoint, so it is
+	// streamed when it is accepted and when it is mined.
+	h.grpc.NotifyMempoolTx(h.mempoolTx)
+	var connected *vtcutil.Block
+	h.server.cfg.Chain.Subscribe(func(n *blockchain.Notification) {
+		if n.Type == blockchain.NTBlockConnected {
+			connected = n.Data.(*vtcutil.Block)
+			h.grpc.NotifyBlockConnected(connected)
+		}
+	})
+	h.mineBlock(t)
+	h.grpc.NotifyBlockDisconnected(connected)
+
+	var header bytes.Buffer
+	if err := connected.MsgBlock().Header.Serialize(&header); err != nil {
+		t.Fatalf("unable to serialize header: %v", err)
+	}
+	types := []vtcdrpc.BlockNotification_Type{
+		vtcdrpc.BlockNotification_CONNECTED,
+		vtcdrpc.BlockNotification_DISCONNECTED,
+	}
+	for _, ntfnType := range types {
+		ntfn, err := blockStream.Recv()
+		if err != nil {
+			t.Fatalf("SubscribeBlocks: unexpected error: %v", err)
+		}
+		if ntfn.Type != ntfnType ||
+			!bytes.Equal(ntfn.Hash, connected.Hash()[:]) ||
+			int(ntfn.Height) != len(h.blocks) ||
+			!bytes.Equal(ntfn.Header, header.Bytes()) {
+
+			t.Errorf("SubscribeBlocks: unexpected notification %v",
+				ntfn)
+		}
+	}
+
+	for i, blockHash := range [][]byte{nil, connected.Hash()[:]} {
+		ntfn, err := txStream.Recv()
+		if err != nil {
+			t.Fatalf("SubscribeTransactions: unexpected error: %v",
+				err)
+		}
+		tx, err := vtcutil.NewTxFromBytes(ntfn.Transaction)
+		if err != nil {
+			t.Fatalf("unable to deserialize tx: %v", err)
+		}
+		wantHeight := int32(0)
+		if blockHash != nil {
+			wantHeight = int32(len(h.blocks))
+		}
+		if !tx.Hash().IsEqual(h.mempoolTx.Hash()) ||
+			!bytes.Equal(ntfn.BlockHash, blockHash) ||
+			ntfn.BlockHeight != wantHeight {
+
+			t.Errorf("#%d: SubscribeTransactions: unexpected "+
+				"notification %v", i, ntfn)
+		}
+	}
+
+	// Subscriptions which fall too far behind are removed and their
+	// notification channel closed.
+	sub := h.grpc.subscribe(nil)
+	for i := 0; i < grpcMaxQueuedNotifications+1; i++ {
+		h.grpc.NotifyBlockDisconnected(connected)
+	}
+	h.grpc.mtx.Lock()
+	_, ok := h.grpc.subscriptions[sub]
+	h.grpc.mtx.Unlock()
+	if ok {
+		t.Errorf("subscription was not removed")
+	}
+	for i := 0; i < grpcMaxQueuedNotifications; i++ {
+		<-sub.ntfns
+	}
+	if _, ok := <-sub.ntfns; ok {
+		t.Errorf("notification channel was not closed")
+	}
+}
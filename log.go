@@ -21,8 +21,10 @@ import (
 	"github.com/vertcoin/vtcd/mempool"
 	"github.com/vertcoin/vtcd/mining"
 	"github.com/vertcoin/vtcd/mining/cpuminer"
+	"github.com/vertcoin/vtcd/mining/stratum"
 	"github.com/vertcoin/vtcd/peer"
 	"github.com/vertcoin/vtcd/txscript"
+	"github.com/vertcoin/vtcd/zmq"
 )
 
 // logWriter implements an io.Writer that outputs to both standard output and
@@ -71,7 +73,9 @@ var (
 	rpcsLog = backendLog.Logger("RPCS")
 	scrpLog = backendLog.Logger("SCRP")
 	srvrLog = backendLog.Logger("SRVR")
+	strmLog = backendLog.Logger("STRM")
 	txmpLog = backendLog.Logger("TXMP")
+	zmqpLog = backendLog.Logger("ZMQP")
 )
 
 // Initialize package-global logger variables.
@@ -83,9 +87,11 @@ func init() {
 	indexers.UseLogger(indxLog)
 	mining.UseLogger(minrLog)
 	cpuminer.UseLogger(minrLog)
+	stratum.UseLogger(strmLog)
 	peer.UseLogger(peerLog)
 	txscript.UseLogger(scrpLog)
 	mempool.UseLogger(txmpLog)
+	zmq.UseLogger(zmqpLog)
 }
 
 // subsystemLoggers maps each subsystem identifier to its associated logger.
@@ -104,7 +110,9 @@ var subsystemLoggers = map[string]btclog.Logger{
 	"RPCS": rpcsLog,
 	"SCRP": scrpLog,
 	"SRVR": srvrLog,
+	"STRM": strmLog,
 	"TXMP": txmpLog,
+	"ZMQP": zmqpLog,
 }
 
 // initLogRotator initializes the logging rotater to write logs to logFile and
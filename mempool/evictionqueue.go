@@ -0,0 +1,67 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mempool
+
+// evictionItem houses a transaction in the pool which is not spent by any other
+// pool transaction along with its fee rate including its fee delta.
+type evictionItem struct {
+	txDesc   *TxDesc
+	feePerKB int64
+	index    int
+}
+
+// evictionQueue implements a priority queue of evictionItem elements ordered
+// by fee rate, so the transaction with the lowest fee rate is evicted first
+// when the pool is full.  The index of each item in the queue is kept up to
+// date so items can be removed and updated efficiently.
+type evictionQueue []*evictionItem
+
+// Len returns the number of items in the priority queue.  It is part of the
+// heap.Interface implementation.
+func (eq evictionQueue) Len() int {
+	return len(eq)
+}
+
+// Less returns whether the item in the priority queue with index i has a lower
+// fee rate than the item with index j.  It is part of the heap.Interface
+// implementation.
+func (eq evictionQueue) Less(i, j int) bool {
+	return eq[i].feePerKB < eq[j].feePerKB
+}
+
+// Swap swaps the items at the passed indices in the priority queue.  It is
+// part of the heap.Interface implementation.
+func (eq evictionQueue) Swap(i, j int) {
+	eq[i], eq[j] = eq[j], eq[i]
+	eq[i].index = i
+	eq[j].index = j
+}
+
+// Push pushes the passed item onto the priority queue.  It is part of the
+// heap.Interface implementation.
+func (eq *evictionQueue) Push(x interface{}) {
+	item := x.(*evictionItem)
+	item.index = len(*eq)
+	*eq = append(*eq, item)
+}
+
+// Pop removes the item with the lowest fee rate from the priority queue and
+// returns it.  It is part of the heap.Interface implementation.
+func (eq *evictionQueue) Pop() interface{} {
+	old := *eq
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	item.index = -1
+	*eq = old[0 : n-1]
+	return item
+}
+
+// calcEvictionFeePerKB returns the fee rate of the passed pool transaction
+// including its fee delta, which decides the order of evictions.
+func calcEvictionFeePerKB(txDesc *TxDesc) int64 {
+	return (txDesc.Fee + txDesc.FeeDelta) * 1000 /
+		int64(txDesc.Tx.MsgTx().SerializeSize())
+}
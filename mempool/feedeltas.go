@@ -0,0 +1,99 @@
+// Copyright (c) 2013-2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mempool
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/vertcoin/vtcd/chaincfg/chainhash"
+)
+
+// feeDeltasVersion is the version of the serialized fee deltas.
+const feeDeltasVersion = 1
+
+// serializedFeeDelta is the JSON representation of a fee delta set via
+// PrioritiseTransaction.
+type serializedFeeDelta struct {
+	TxID     string `json:"txid"`
+	FeeDelta int64  `json:"feedelta"`
+}
+
+// serializedFeeDeltas is the JSON representation of all fee deltas set via
+// PrioritiseTransaction.
+type serializedFeeDeltas struct {
+	Version   int                   `json:"version"`
+	FeeDeltas []*serializedFeeDelta `json:"feedeltas"`
+}
+
+// SaveFeeDeltas writes the fee deltas set via PrioritiseTransaction to the
+// file at the passed path so they can be restored with LoadFeeDeltas after a
+// restart.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) SaveFeeDeltas(filePath string) error {
+	deltas := mp.FeeDeltas()
+	sfd := serializedFeeDeltas{
+		Version:   feeDeltasVersion,
+		FeeDeltas: make([]*serializedFeeDelta, 0, len(deltas)),
+	}
+	for hash, delta := range deltas {
+		sfd.FeeDeltas = append(sfd.FeeDeltas, &serializedFeeDelta{
+			TxID:     hash.String(),
+			FeeDelta: delta,
+		})
+	}
+
+	w, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("error opening file %s: %v", filePath, err)
+	}
+	defer w.Close()
+	if err := json.NewEncoder(w).Encode(&sfd); err != nil {
+		return fmt.Errorf("failed to encode file %s: %v", filePath, err)
+	}
+	return nil
+}
+
+// LoadFeeDeltas adds the fee deltas stored in the file at the passed path by
+// SaveFeeDeltas to the pool as if they were set via PrioritiseTransaction.  A
+// missing file is not an error.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) LoadFeeDeltas(filePath string) error {
+	r, err := os.Open(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("%s error opening file: %v", filePath, err)
+	}
+	defer r.Close()
+
+	var sfd serializedFeeDeltas
+	if err := json.NewDecoder(r).Decode(&sfd); err != nil {
+		return fmt.Errorf("error reading %s: %v", filePath, err)
+	}
+	if sfd.Version != feeDeltasVersion {
+		return fmt.Errorf("unknown version %v in serialized fee deltas",
+			sfd.Version)
+	}
+
+	for _, delta := range sfd.FeeDeltas {
+		hash, err := chainhash.NewHashFromStr(delta.TxID)
+		if err != nil {
+			return fmt.Errorf("failed to parse transaction hash %s: %v",
+				delta.TxID, err)
+		}
+		if _, err := mp.PrioritiseTransaction(hash, delta.FeeDelta); err != nil {
+			return err
+		}
+	}
+
+	log.Infof("Loaded %d fee deltas from file '%s'", len(sfd.FeeDeltas),
+		filePath)
+	return nil
+}
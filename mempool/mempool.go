@@ -5,6 +5,7 @@
 package mempool
 
 import (
+	"container/heap"
 	"container/list"
 	"fmt"
 	"math"
@@ -17,6 +18,7 @@ import (
 	"github.com/vertcoin/vtcd/btcjson"
 	"github.com/vertcoin/vtcd/chaincfg"
 	"github.com/vertcoin/vtcd/chaincfg/chainhash"
+	"github.com/vertcoin/vtcd/metrics"
 	"github.com/vertcoin/vtcd/mining"
 	"github.com/vertcoin/vtcd/txscript"
 	"github.com/vertcoin/vtcd/wire"
@@ -38,6 +40,42 @@ const (
 	// orphanExpireScanInterval is the minimum amount of time in between
 	// scans of the orphan pool to evict expired transactions.
 	orphanExpireScanInterval = time.Minute * 5
+
+	// DefaultMaxPoolSize is the default maximum total serialized size in
+	// bytes of the transactions in the memory pool.
+	DefaultMaxPoolSize = 300 * 1000 * 1000
+
+	// maxFeeDeltas is the maximum number of fee deltas set via
+	// PrioritiseTransaction which are kept.  It limits the memory used by
+	// deltas of transactions which have not been seen yet.
+	maxFeeDeltas = 10000
+
+	// incrementalRelayFeePerKB is the fee rate in satoshi per kB which is
+	// added to the fee rate of an evicted transaction to raise the rolling
+	// minimum fee of a full pool.  It ensures replacing an evicted
+	// transaction costs more than the evicted transaction paid.
+	incrementalRelayFeePerKB = 1000
+
+	// rollingFeeHalfLife is the time after which the rolling minimum fee
+	// of a full pool has decayed to half of its value.  It decays faster
+	// while the pool is less than half full.
+	rollingFeeHalfLife = time.Hour * 12
+
+	// rollingFeeUpdateInterval is the minimum amount of time in between
+	// updates of the decaying rolling minimum fee.
+	rollingFeeUpdateInterval = time.Second * 10
+)
+
+var (
+	// poolTxs, poolBytes and poolOrphans track the number of transactions
+	// in the memory pool, their total serialized size and the number of
+	// transactions in the orphan pool.
+	poolTxs = metrics.NewGauge("vtcd_mempool_transactions",
+		"Number of transactions in the memory pool.")
+	poolBytes = metrics.NewGauge("vtcd_mempool_bytes",
+		"Total serialized size of the transactions in the memory pool.")
+	poolOrphans = metrics.NewGauge("vtcd_mempool_orphans",
+		"Number of transactions in the orphan pool.")
 )
 
 // Tag represents an identifier to use for tagging orphan transactions.  The
@@ -122,13 +160,26 @@ type Policy struct {
 	MaxOrphanTxSize int
 
 	// MaxSigOpCostPerTx is the cumulative maximum cost of all the signature
-	// operations in a single transaction we will relay or mine.  It is a
-	// fraction of the max signature operations for a block.
+	// operations in a single transaction we will relay or mine.  It is only
+	// used when Standard.MaxTxSigOpCost is zero.
+	//
+	// Deprecated: Use Standard.MaxTxSigOpCost instead.
 	MaxSigOpCostPerTx int
 
 	// MinRelayTxFee defines the minimum transaction fee in BTC/kB to be
 	// considered a non-zero fee.
 	MinRelayTxFee vtcutil.Amount
+
+	// MaxPoolSize is the maximum total serialized size in bytes of the
+	// transactions in the pool.  Transactions with the lowest fee rates,
+	// including fee deltas, are evicted once it is exceeded, which raises
+	// the fee rate new transactions must pay until it decays again.  Zero
+	// means the size of the pool is not limited.
+	MaxPoolSize int64
+
+	// Standard houses the configurable rules which decide whether a
+	// transaction is standard.
+	Standard StandardPolicy
 }
 
 // TxDesc is a descriptor containing a transaction in the mempool along with
@@ -163,14 +214,34 @@ type TxPool struct {
 	orphans       map[chainhash.Hash]*orphanTx
 	orphansByPrev map[wire.OutPoint]map[chainhash.Hash]*vtcutil.Tx
 	outpoints     map[wire.OutPoint]*vtcutil.Tx
+	feeDeltas     map[chainhash.Hash]int64
 	pennyTotal    float64 // exponentially decaying total for penny spends.
 	lastPennyUnix int64   // unix time of last ``penny spend''
+	totalBytes    int64   // total serialized size of the pool transactions
+
+	// evictable and evictionQueue hold the pool transactions which are not
+	// spent by other pool transactions, ordered by fee rate, so the
+	// transaction to evict from a full pool is found without scanning it.
+	evictable     map[chainhash.Hash]*evictionItem
+	evictionQueue evictionQueue
+
+	// rollingMinFeePerKB is the fee rate in satoshi per kB new transactions
+	// must pay after transactions were evicted from the full pool.  It
+	// decays over time and is updated at most every
+	// rollingFeeUpdateInterval.
+	rollingMinFeePerKB   float64
+	lastRollingFeeUpdate time.Time
 
 	// nextExpireScan is the time after which the orphan pool will be
 	// scanned in order to evict orphans.  This is NOT a hard deadline as
 	// the scan will only run when an orphan is added to the pool as opposed
 	// to on an unconditional timer.
 	nextExpireScan time.Time
+
+	// notifications stores the callbacks which are executed when
+	// transactions are added to or removed from the pool.
+	notificationsLock sync.RWMutex
+	notifications     []NotificationCallback
 }
 
 // Ensure the TxPool type implements the mining.TxSource interface.
@@ -215,6 +286,7 @@ func (mp *TxPool) removeOrphan(tx *vtcutil.Tx, removeRedeemers bool) {
 
 	// Remove the transaction from the orphan pool.
 	delete(mp.orphans, *txHash)
+	poolOrphans.Set(int64(len(mp.orphans)))
 }
 
 // RemoveOrphan removes the passed orphan transaction from the orphan pool and
@@ -323,6 +395,7 @@ func (mp *TxPool) addOrphan(tx *vtcutil.Tx, tag Tag) {
 		}
 		mp.orphansByPrev[txIn.PreviousOutPoint][*tx.Hash()] = tx
 	}
+	poolOrphans.Set(int64(len(mp.orphans)))
 
 	log.Debugf("Stored orphan transaction %v (total: %d)", tx.Hash(),
 		len(mp.orphans))
@@ -443,18 +516,31 @@ func (mp *TxPool) HaveTransaction(hash *chainhash.Hash) bool {
 	return haveTx
 }
 
+// CheckSpend returns the transaction in the main pool which spends the passed
+// outpoint, or nil if it is not spent by the pool.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) CheckSpend(op wire.OutPoint) *vtcutil.Tx {
+	mp.mtx.RLock()
+	txR := mp.outpoints[op]
+	mp.mtx.RUnlock()
+
+	return txR
+}
+
 // removeTransaction is the internal function which implements the public
 // RemoveTransaction.  See the comment for RemoveTransaction for more details.
+// The passed reason is included in the notifications of the removals.
 //
 // This function MUST be called with the mempool lock held (for writes).
-func (mp *TxPool) removeTransaction(tx *vtcutil.Tx, removeRedeemers bool) {
+func (mp *TxPool) removeTransaction(tx *vtcutil.Tx, removeRedeemers bool, reason RemovalReason) {
 	txHash := tx.Hash()
 	if removeRedeemers {
 		// Remove any transactions which rely on this one.
 		for i := uint32(0); i < uint32(len(tx.MsgTx().TxOut)); i++ {
 			prevOut := wire.OutPoint{Hash: *txHash, Index: i}
 			if txRedeemer, exists := mp.outpoints[prevOut]; exists {
-				mp.removeTransaction(txRedeemer, true)
+				mp.removeTransaction(txRedeemer, true, reason)
 			}
 		}
 	}
@@ -467,25 +553,59 @@ func (mp *TxPool) removeTransaction(tx *vtcutil.Tx, removeRedeemers bool) {
 			mp.cfg.AddrIndex.RemoveUnconfirmedTx(txHash)
 		}
 
-		// Mark the referenced outpoints as unspent by the pool.
+		// Mark the referenced outpoints as unspent by the pool.  The
+		// pool transactions they belong to become eviction candidates
+		// once no other pool transaction spends them.
+		mp.removeEvictable(txHash)
 		for _, txIn := range txDesc.Tx.MsgTx().TxIn {
 			delete(mp.outpoints, txIn.PreviousOutPoint)
 		}
 		delete(mp.pool, *txHash)
+		for _, txIn := range txDesc.Tx.MsgTx().TxIn {
+			prevHash := &txIn.PreviousOutPoint.Hash
+			prevDesc, exists := mp.pool[*prevHash]
+			if exists && !mp.isSpentInPool(prevHash, prevDesc.Tx) {
+				mp.addEvictable(prevDesc)
+			}
+		}
 		atomic.StoreInt64(&mp.lastUpdated, time.Now().Unix())
+		mp.totalBytes -= int64(tx.MsgTx().SerializeSize())
+		poolTxs.Set(int64(len(mp.pool)))
+		poolBytes.Set(mp.totalBytes)
+
+		mp.sendNotification(&Notification{
+			Type:   NTTxRemoved,
+			Data:   txDesc.Tx,
+			Reason: reason,
+		})
 	}
 }
 
 // RemoveTransaction removes the passed transaction from the mempool. When the
 // removeRedeemers flag is set, any transactions that redeem outputs from the
 // removed transaction will also be removed recursively from the mempool, as
-// they would otherwise become orphans.
+// they would otherwise become orphans.  The fee delta of the transaction is
+// kept.
 //
 // This function is safe for concurrent access.
 func (mp *TxPool) RemoveTransaction(tx *vtcutil.Tx, removeRedeemers bool) {
 	// Protect concurrent access.
 	mp.mtx.Lock()
-	mp.removeTransaction(tx, removeRedeemers)
+	mp.removeTransaction(tx, removeRedeemers, RRExplicit)
+	mp.mtx.Unlock()
+}
+
+// RemoveMinedTransaction removes the passed transaction, which was included in
+// a block connected to the main chain, from the mempool and drops its fee
+// delta.  Transactions that redeem outputs from it are not removed since they
+// are still valid.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) RemoveMinedTransaction(tx *vtcutil.Tx) {
+	// Protect concurrent access.
+	mp.mtx.Lock()
+	mp.removeTransaction(tx, false, RRBlock)
+	delete(mp.feeDeltas, *tx.Hash())
 	mp.mtx.Unlock()
 }
 
@@ -502,7 +622,8 @@ func (mp *TxPool) RemoveDoubleSpends(tx *vtcutil.Tx) {
 	for _, txIn := range tx.MsgTx().TxIn {
 		if txRedeemer, ok := mp.outpoints[txIn.PreviousOutPoint]; ok {
 			if !txRedeemer.Hash().IsEqual(tx.Hash()) {
-				mp.removeTransaction(txRedeemer, true)
+				mp.removeTransaction(txRedeemer, true,
+					RRConflict)
 			}
 		}
 	}
@@ -524,15 +645,23 @@ func (mp *TxPool) addTransaction(utxoView *blockchain.UtxoViewpoint, tx *vtcutil
 			Height:   height,
 			Fee:      fee,
 			FeePerKB: fee * 1000 / int64(tx.MsgTx().SerializeSize()),
+			FeeDelta: mp.feeDeltas[*tx.Hash()],
 		},
 		StartingPriority: mining.CalcPriority(tx.MsgTx(), utxoView, height),
 	}
 	mp.pool[*tx.Hash()] = txD
 
+	// The transactions spent by the new one are no longer eviction
+	// candidates while the new one is.
 	for _, txIn := range tx.MsgTx().TxIn {
 		mp.outpoints[txIn.PreviousOutPoint] = tx
+		mp.removeEvictable(&txIn.PreviousOutPoint.Hash)
 	}
+	mp.addEvictable(txD)
 	atomic.StoreInt64(&mp.lastUpdated, time.Now().Unix())
+	mp.totalBytes += int64(tx.MsgTx().SerializeSize())
+	poolTxs.Set(int64(len(mp.pool)))
+	poolBytes.Set(mp.totalBytes)
 
 	// Add unconfirmed address index entries associated with the transaction
 	// if enabled.
@@ -540,9 +669,162 @@ func (mp *TxPool) addTransaction(utxoView *blockchain.UtxoViewpoint, tx *vtcutil
 		mp.cfg.AddrIndex.AddUnconfirmedTx(tx, utxoView)
 	}
 
+	mp.sendNotification(&Notification{Type: NTTxAccepted, Data: tx})
+
 	return txD
 }
 
+// trimToSize evicts transactions until the total serialized size of the pool
+// transactions no longer exceeds the maximum pool size of the policy.  Only
+// transactions which are not spent by other pool transactions are candidates,
+// so a transaction is never evicted before the transactions spending it.  The
+// candidate with the lowest fee rate including its fee delta is evicted first,
+// except for the transaction with the passed hash, which was just accepted
+// after checkPoolSpace.  The rolling minimum fee is raised above the fee rate
+// of every evicted transaction.
+//
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) trimToSize(keep *chainhash.Hash) {
+	if mp.cfg.Policy.MaxPoolSize <= 0 {
+		return
+	}
+
+	var kept *evictionItem
+	for mp.totalBytes > mp.cfg.Policy.MaxPoolSize && len(mp.evictionQueue) > 0 {
+		evict := mp.evictionQueue[0]
+		if evict.txDesc.Tx.Hash().IsEqual(keep) {
+			kept = evict
+			mp.removeEvictable(keep)
+			continue
+		}
+
+		log.Debugf("Evicting transaction %v (fee rate %d) from the "+
+			"full pool", evict.txDesc.Tx.Hash(), evict.feePerKB)
+		mp.removeTransaction(evict.txDesc.Tx, false, RRSizeLimit)
+		mp.raiseRollingMinFee(evict.feePerKB + incrementalRelayFeePerKB)
+	}
+	if kept != nil {
+		mp.addEvictable(kept.txDesc)
+	}
+}
+
+// checkPoolSpace returns an error when the passed transaction, which pays the
+// passed fee including its fee delta, doesn't fit into the pool without
+// evicting transactions paying the same or a higher fee rate.  This ensures a
+// transaction is rejected rather than added and evicted right away.
+//
+// This function MUST be called with the mempool lock held (for reads).
+func (mp *TxPool) checkPoolSpace(tx *vtcutil.Tx, modifiedFee int64) error {
+	maxPoolSize := mp.cfg.Policy.MaxPoolSize
+	txSize := int64(tx.MsgTx().SerializeSize())
+	if maxPoolSize <= 0 || mp.totalBytes+txSize <= maxPoolSize {
+		return nil
+	}
+
+	if txSize > maxPoolSize {
+		str := fmt.Sprintf("transaction %v of %d bytes exceeds the "+
+			"maximum memory pool size of %d bytes", tx.Hash(), txSize,
+			maxPoolSize)
+		return txRuleError(wire.RejectInsufficientFee, str)
+	}
+	feePerKB := modifiedFee * 1000 / txSize
+	if len(mp.evictionQueue) > 0 && feePerKB <= mp.evictionQueue[0].feePerKB {
+		str := fmt.Sprintf("transaction %v has a fee rate too low to "+
+			"enter the full memory pool (%d <= %d)", tx.Hash(),
+			feePerKB, mp.evictionQueue[0].feePerKB)
+		return txRuleError(wire.RejectInsufficientFee, str)
+	}
+	return nil
+}
+
+// addEvictable adds the passed pool transaction to the eviction candidates.
+//
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) addEvictable(txDesc *TxDesc) {
+	txHash := txDesc.Tx.Hash()
+	if _, exists := mp.evictable[*txHash]; exists {
+		return
+	}
+	item := &evictionItem{
+		txDesc:   txDesc,
+		feePerKB: calcEvictionFeePerKB(txDesc),
+	}
+	heap.Push(&mp.evictionQueue, item)
+	mp.evictable[*txHash] = item
+}
+
+// removeEvictable removes the pool transaction with the passed hash from the
+// eviction candidates.  Nothing is done when it is not a candidate.
+//
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) removeEvictable(txHash *chainhash.Hash) {
+	item, exists := mp.evictable[*txHash]
+	if !exists {
+		return
+	}
+	heap.Remove(&mp.evictionQueue, item.index)
+	delete(mp.evictable, *txHash)
+}
+
+// rollingMinFee returns the fee rate in satoshi per kB new transactions must
+// pay since transactions were evicted from the full pool, after letting it
+// decay for the time passed since its last update.  The fee rate decays faster
+// while the pool is less than half full and drops to zero once it falls below
+// half of the incremental relay fee.
+//
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) rollingMinFee() int64 {
+	if mp.rollingMinFeePerKB == 0 {
+		return 0
+	}
+
+	now := time.Now()
+	elapsed := now.Sub(mp.lastRollingFeeUpdate)
+	if elapsed < rollingFeeUpdateInterval {
+		return int64(mp.rollingMinFeePerKB)
+	}
+
+	halfLife := rollingFeeHalfLife
+	if mp.totalBytes < mp.cfg.Policy.MaxPoolSize/4 {
+		halfLife /= 4
+	} else if mp.totalBytes < mp.cfg.Policy.MaxPoolSize/2 {
+		halfLife /= 2
+	}
+	mp.rollingMinFeePerKB /= math.Pow(2, elapsed.Seconds()/
+		halfLife.Seconds())
+	mp.lastRollingFeeUpdate = now
+	if mp.rollingMinFeePerKB < incrementalRelayFeePerKB/2 {
+		mp.rollingMinFeePerKB = 0
+	}
+	return int64(mp.rollingMinFeePerKB)
+}
+
+// raiseRollingMinFee raises the rolling minimum fee to the passed fee rate in
+// satoshi per kB unless it is already higher.
+//
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) raiseRollingMinFee(feePerKB int64) {
+	if feePerKB > mp.rollingMinFee() {
+		mp.rollingMinFeePerKB = float64(feePerKB)
+		mp.lastRollingFeeUpdate = time.Now()
+	}
+}
+
+// isSpentInPool returns whether or not any output of the passed transaction is
+// spent by a transaction in the pool.
+//
+// This function MUST be called with the mempool lock held (for reads).
+func (mp *TxPool) isSpentInPool(txHash *chainhash.Hash, tx *vtcutil.Tx) bool {
+	prevOut := wire.OutPoint{Hash: *txHash}
+	for i := range tx.MsgTx().TxOut {
+		prevOut.Index = uint32(i)
+		if _, exists := mp.outpoints[prevOut]; exists {
+			return true
+		}
+	}
+	return false
+}
+
 // checkPoolDoubleSpend checks whether or not the passed transaction is
 // attempting to spend coins already spent by other transactions in the pool.
 // Note it does not check for double spends against transactions already in the
@@ -670,7 +952,7 @@ func (mp *TxPool) maybeAcceptTransaction(tx *vtcutil.Tx, isNew, rateLimit, rejec
 	// forbid their acceptance.
 	if !mp.cfg.Policy.AcceptNonStd {
 		err = checkTransactionStandard(tx, nextBlockHeight,
-			medianTimePast, mp.cfg.Policy.MinRelayTxFee,
+			medianTimePast, &mp.cfg.Policy.Standard,
 			mp.cfg.Policy.MaxTxVersion)
 		if err != nil {
 			// Attempt to extract a reject code from the error so
@@ -803,9 +1085,13 @@ func (mp *TxPool) maybeAcceptTransaction(tx *vtcutil.Tx, isNew, rateLimit, rejec
 		}
 		return nil, nil, err
 	}
-	if sigOpCost > mp.cfg.Policy.MaxSigOpCostPerTx {
+	maxSigOpCost := mp.cfg.Policy.Standard.MaxTxSigOpCost
+	if maxSigOpCost == 0 {
+		maxSigOpCost = mp.cfg.Policy.MaxSigOpCostPerTx
+	}
+	if sigOpCost > maxSigOpCost {
 		str := fmt.Sprintf("transaction %v sigop cost is too high: %d > %d",
-			txHash, sigOpCost, mp.cfg.Policy.MaxSigOpCostPerTx)
+			txHash, sigOpCost, maxSigOpCost)
 		return nil, nil, txRuleError(wire.RejectNonstandard, str)
 	}
 
@@ -820,21 +1106,47 @@ func (mp *TxPool) maybeAcceptTransaction(tx *vtcutil.Tx, isNew, rateLimit, rejec
 	// which is more desirable.  Therefore, as long as the size of the
 	// transaction does not exceeed 1000 less than the reserved space for
 	// high-priority transactions, don't require a fee for it.
+	//
+	// The fee delta of a prioritised transaction counts towards the fees
+	// of all checks below.
+	modifiedFee := txFee + mp.feeDeltas[*txHash]
 	serializedSize := GetTxVirtualSize(tx)
 	minFee := calcMinRequiredTxRelayFee(serializedSize,
 		mp.cfg.Policy.MinRelayTxFee)
-	if serializedSize >= (DefaultBlockPrioritySize-1000) && txFee < minFee {
+	if serializedSize >= (DefaultBlockPrioritySize-1000) && modifiedFee < minFee {
 		str := fmt.Sprintf("transaction %v has %d fees which is under "+
-			"the required amount of %d", txHash, txFee,
+			"the required amount of %d", txHash, modifiedFee,
 			minFee)
 		return nil, nil, txRuleError(wire.RejectInsufficientFee, str)
 	}
 
+	// Require new transactions to pay the rolling minimum fee once
+	// transactions were evicted from the full pool, so evicted
+	// transactions can't be replaced by cheaper ones.  Transactions which
+	// are being added back to the memory pool from blocks that have been
+	// disconnected during a reorg are exempted.
+	if rollingFee := mp.rollingMinFee(); isNew && rollingFee > 0 {
+		minPoolFee := serializedSize * rollingFee / 1000
+		if modifiedFee < minPoolFee {
+			str := fmt.Sprintf("transaction %v has %d fees which is "+
+				"under the memory pool minimum fee of %d",
+				txHash, modifiedFee, minPoolFee)
+			return nil, nil, txRuleError(wire.RejectInsufficientFee,
+				str)
+		}
+	}
+
+	// Don't add the transaction to a full pool when it would be evicted
+	// right away.
+	if err := mp.checkPoolSpace(tx, modifiedFee); err != nil {
+		return nil, nil, err
+	}
+
 	// Require that free transactions have sufficient priority to be mined
 	// in the next block.  Transactions which are being added back to the
 	// memory pool from blocks that have been disconnected during a reorg
 	// are exempted.
-	if isNew && !mp.cfg.Policy.DisableRelayPriority && txFee < minFee {
+	if isNew && !mp.cfg.Policy.DisableRelayPriority && modifiedFee < minFee {
 		currentPriority := mining.CalcPriority(tx.MsgTx(), utxoView,
 			nextBlockHeight)
 		if currentPriority <= mining.MinHighPriority {
@@ -847,7 +1159,7 @@ func (mp *TxPool) maybeAcceptTransaction(tx *vtcutil.Tx, isNew, rateLimit, rejec
 
 	// Free-to-relay transactions are rate limited here to prevent
 	// penny-flooding with tiny transactions as a form of attack.
-	if rateLimit && txFee < minFee {
+	if rateLimit && modifiedFee < minFee {
 		nowUnix := time.Now().Unix()
 		// Decay passed data with an exponentially decaying ~10 minute
 		// window - matches bitcoind handling.
@@ -881,8 +1193,10 @@ func (mp *TxPool) maybeAcceptTransaction(tx *vtcutil.Tx, isNew, rateLimit, rejec
 		return nil, nil, err
 	}
 
-	// Add to transaction pool.
+	// Add to transaction pool and evict the transactions with the lowest
+	// fee rates to make room for it when the pool is full.
 	txD := mp.addTransaction(utxoView, tx, bestHeight, txFee)
+	mp.trimToSize(txHash)
 
 	log.Debugf("Accepted transaction %v (pool size: %v)", txHash,
 		len(mp.pool))
@@ -1168,6 +1482,7 @@ func (mp *TxPool) RawMempoolVerbose() map[string]*btcjson.GetRawMempoolVerboseRe
 			Size:             int32(tx.MsgTx().SerializeSize()),
 			Vsize:            int32(GetTxVirtualSize(tx)),
 			Fee:              vtcutil.Amount(desc.Fee).ToBTC(),
+			ModifiedFee:      vtcutil.Amount(desc.Fee + desc.FeeDelta).ToBTC(),
 			Time:             desc.Added.Unix(),
 			Height:           int64(desc.Height),
 			StartingPriority: desc.StartingPriority,
@@ -1188,6 +1503,78 @@ func (mp *TxPool) RawMempoolVerbose() map[string]*btcjson.GetRawMempoolVerboseRe
 	return result
 }
 
+// PrioritiseTransaction adds the passed fee delta to the fee used for the
+// transaction with the passed hash when it is checked against the relay fee
+// policy, when transactions are evicted from a full pool and when block
+// templates are generated.  Deltas accumulate, may be set before the
+// transaction is seen, and are kept until the transaction is mined.  The
+// resulting fee delta is returned.
+//
+// An error is returned when a delta is set for a new transaction while
+// maxFeeDeltas deltas are already kept.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) PrioritiseTransaction(hash *chainhash.Hash, feeDelta int64) (int64, error) {
+	mp.mtx.Lock()
+	defer mp.mtx.Unlock()
+
+	_, exists := mp.feeDeltas[*hash]
+	if !exists && len(mp.feeDeltas) >= maxFeeDeltas {
+		return 0, fmt.Errorf("unable to prioritise transaction %v: "+
+			"the maximum of %d fee deltas is reached", hash,
+			maxFeeDeltas)
+	}
+
+	delta := mp.feeDeltas[*hash] + feeDelta
+	if delta == 0 {
+		delete(mp.feeDeltas, *hash)
+	} else {
+		mp.feeDeltas[*hash] = delta
+	}
+
+	// Replace the descriptor of a transaction in the pool rather than
+	// modifying it since descriptors are handed out as read only.
+	if txDesc, exists := mp.pool[*hash]; exists {
+		newDesc := *txDesc
+		newDesc.FeeDelta = delta
+		mp.pool[*hash] = &newDesc
+		if item, exists := mp.evictable[*hash]; exists {
+			item.txDesc = &newDesc
+			item.feePerKB = calcEvictionFeePerKB(&newDesc)
+			heap.Fix(&mp.evictionQueue, item.index)
+		}
+		atomic.StoreInt64(&mp.lastUpdated, time.Now().Unix())
+	}
+
+	log.Debugf("Fee delta of transaction %v is now %d", hash, delta)
+	return delta, nil
+}
+
+// FeeDeltas returns a copy of the fee deltas set via PrioritiseTransaction,
+// keyed by transaction hash.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) FeeDeltas() map[chainhash.Hash]int64 {
+	mp.mtx.RLock()
+	deltas := make(map[chainhash.Hash]int64, len(mp.feeDeltas))
+	for hash, delta := range mp.feeDeltas {
+		deltas[hash] = delta
+	}
+	mp.mtx.RUnlock()
+	return deltas
+}
+
+// FeeDelta returns the fee delta set for the transaction with the passed hash
+// via PrioritiseTransaction.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) FeeDelta(hash *chainhash.Hash) int64 {
+	mp.mtx.RLock()
+	delta := mp.feeDeltas[*hash]
+	mp.mtx.RUnlock()
+	return delta
+}
+
 // LastUpdated returns the last time a transaction was added to or removed from
 // the main pool.  It does not include the orphan pool.
 //
@@ -1196,6 +1583,20 @@ func (mp *TxPool) LastUpdated() time.Time {
 	return time.Unix(atomic.LoadInt64(&mp.lastUpdated), 0)
 }
 
+// CheckTransactionStandard returns an error when the passed transaction does
+// not follow the standard policy of the pool at the passed height and median
+// time.  No error is returned when the pool accepts non-standard transactions.
+// It allows block templates to be built following the same policy as the pool.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) CheckTransactionStandard(tx *vtcutil.Tx, height int32, medianTimePast time.Time) error {
+	if mp.cfg.Policy.AcceptNonStd {
+		return nil
+	}
+	return checkTransactionStandard(tx, height, medianTimePast,
+		&mp.cfg.Policy.Standard, mp.cfg.Policy.MaxTxVersion)
+}
+
 // New returns a new memory pool for validating and storing standalone
 // transactions until they are mined into a block.
 func New(cfg *Config) *TxPool {
@@ -1206,5 +1607,7 @@ func New(cfg *Config) *TxPool {
 		orphansByPrev:  make(map[wire.OutPoint]map[chainhash.Hash]*vtcutil.Tx),
 		nextExpireScan: time.Now().Add(orphanExpireScanInterval),
 		outpoints:      make(map[wire.OutPoint]*vtcutil.Tx),
+		feeDeltas:      make(map[chainhash.Hash]int64),
+		evictable:      make(map[chainhash.Hash]*evictionItem),
 	}
 }
@@ -5,7 +5,11 @@
 package mempool
 
 import (
+	"encoding/binary"
 	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"runtime"
 	"sync"
@@ -310,9 +314,9 @@ func newPoolHarness(chainParams *chaincfg.Params) (*poolHarness, []spendableOutp
 				FreeTxRelayLimit:     15.0,
 				MaxOrphanTxs:         5,
 				MaxOrphanTxSize:      1000,
-				MaxSigOpCostPerTx:    blockchain.MaxBlockSigOpsCost / 4,
 				MinRelayTxFee:        1000, // 1 Satoshi per byte
 				MaxTxVersion:         1,
+				Standard:             DefaultStandardPolicy(),
 			},
 			ChainParams:      chainParams,
 			FetchUtxoView:    chain.FetchUtxoView,
@@ -794,3 +798,255 @@ func TestMultiInputOrphanDoubleSpend(t *testing.T) {
 	// was not moved to the transaction pool.
 	testPoolMembership(tc, doubleSpendTx, false, false)
 }
+
+// TestPrioritiseTransaction ensures fee deltas accumulate, may be set before a
+// transaction is seen, are reflected in the pool entries, are kept when the
+// transaction leaves the pool and are dropped once it is mined.
+func TestPrioritiseTransaction(t *testing.T) {
+	t.Parallel()
+
+	harness, spendableOuts, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	tc := &testContext{t, harness}
+
+	chainedTxns, err := harness.CreateTxChain(spendableOuts[0], 1)
+	if err != nil {
+		t.Fatalf("unable to create transaction chain: %v", err)
+	}
+	tx := chainedTxns[0]
+
+	// Prioritise the transaction before it is seen.
+	harness.txPool.PrioritiseTransaction(tx.Hash(), 500)
+	delta, err := harness.txPool.PrioritiseTransaction(tx.Hash(), 500)
+	if err != nil {
+		t.Fatalf("PrioritiseTransaction: unexpected error: %v", err)
+	}
+	if delta != 1000 {
+		t.Fatalf("PrioritiseTransaction: got delta %d, want 1000", delta)
+	}
+
+	_, err = harness.txPool.ProcessTransaction(tx, false, false, 0)
+	if err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept valid tx %v", err)
+	}
+	testPoolMembership(tc, tx, false, true)
+
+	// checkDelta ensures the pool entry of the transaction reflects the
+	// passed fee delta.
+	checkDelta := func(want int64) {
+		descs := harness.txPool.MiningDescs()
+		if len(descs) != 1 || descs[0].FeeDelta != want {
+			t.Fatalf("MiningDescs: unexpected descriptors %+v, want "+
+				"fee delta %d", descs, want)
+		}
+		entry := harness.txPool.RawMempoolVerbose()[tx.Hash().String()]
+		wantFee := vtcutil.Amount(descs[0].Fee + want).ToBTC()
+		if entry == nil || entry.ModifiedFee != wantFee {
+			t.Fatalf("RawMempoolVerbose: unexpected entry %+v, want "+
+				"modified fee %v", entry, wantFee)
+		}
+	}
+	checkDelta(1000)
+
+	// Deltas of transactions in the pool are updated as well.
+	harness.txPool.PrioritiseTransaction(tx.Hash(), -1500)
+	checkDelta(-500)
+
+	// The delta is kept when the transaction leaves the pool, such as when
+	// it is evicted, and dropped once it is mined.
+	harness.txPool.RemoveTransaction(tx, false)
+	if delta := harness.txPool.FeeDelta(tx.Hash()); delta != -500 {
+		t.Fatalf("FeeDelta: got delta %d after removal, want -500",
+			delta)
+	}
+	harness.txPool.RemoveMinedTransaction(tx)
+	if delta := harness.txPool.FeeDelta(tx.Hash()); delta != 0 {
+		t.Fatalf("FeeDelta: got delta %d after mining, want 0", delta)
+	}
+}
+
+// TestFeeDeltasLimit ensures no more than maxFeeDeltas fee deltas are kept
+// while the deltas which are already kept can still be changed.
+func TestFeeDeltasLimit(t *testing.T) {
+	t.Parallel()
+
+	harness, _, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+
+	var hash chainhash.Hash
+	for i := 0; i < maxFeeDeltas; i++ {
+		binary.LittleEndian.PutUint32(hash[:], uint32(i))
+		_, err := harness.txPool.PrioritiseTransaction(&hash, 1)
+		if err != nil {
+			t.Fatalf("PrioritiseTransaction #%d: unexpected error: %v",
+				i, err)
+		}
+	}
+
+	binary.LittleEndian.PutUint32(hash[:], maxFeeDeltas)
+	if _, err := harness.txPool.PrioritiseTransaction(&hash, 1); err == nil {
+		t.Fatal("PrioritiseTransaction: did not fail with the maximum " +
+			"number of fee deltas")
+	}
+
+	binary.LittleEndian.PutUint32(hash[:], 0)
+	delta, err := harness.txPool.PrioritiseTransaction(&hash, 1)
+	if err != nil {
+		t.Fatalf("PrioritiseTransaction: unexpected error changing a "+
+			"kept delta: %v", err)
+	}
+	if delta != 2 {
+		t.Fatalf("PrioritiseTransaction: got delta %d, want 2", delta)
+	}
+}
+
+// TestPoolSizeEviction ensures transactions with the lowest fee rates
+// including their fee deltas are evicted once the pool exceeds its maximum
+// size, that transactions are not evicted before the transactions spending
+// them, that a transaction which would be evicted itself is rejected without
+// being added and that evictions raise the minimum fee of the pool.
+func TestPoolSizeEviction(t *testing.T) {
+	t.Parallel()
+
+	harness, spendableOuts, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	tc := &testContext{t, harness}
+
+	// Create a parent transaction with two outputs which are each spent
+	// by a child transaction.  None of them pay a fee.
+	parent, err := harness.CreateSignedTx(spendableOuts, 2)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	var children []*vtcutil.Tx
+	for i := uint32(0); i < 2; i++ {
+		child, err := harness.CreateSignedTx([]spendableOutput{
+			txOutToSpendableOut(parent, i)}, 1)
+		if err != nil {
+			t.Fatalf("unable to create transaction: %v", err)
+		}
+		children = append(children, child)
+	}
+
+	// Limit the pool to the parent and a single child.
+	harness.txPool.cfg.Policy.MaxPoolSize = int64(
+		parent.MsgTx().SerializeSize() +
+			children[0].MsgTx().SerializeSize())
+
+	// Accept the parent and the first child, which is prioritised.
+	harness.txPool.PrioritiseTransaction(children[0].Hash(), 1000)
+	for _, tx := range []*vtcutil.Tx{parent, children[0]} {
+		_, err := harness.txPool.ProcessTransaction(tx, false, false, 0)
+		if err != nil {
+			t.Fatalf("ProcessTransaction: failed to accept valid "+
+				"tx: %v", err)
+		}
+	}
+
+	// The second child has the lowest fee rate of the transactions which
+	// are not spent by others, so it is rejected since it would be
+	// evicted right away.  It is never added to the pool.
+	var notified []*Notification
+	harness.txPool.Subscribe(func(n *Notification) {
+		notified = append(notified, n)
+	})
+	_, err = harness.txPool.ProcessTransaction(children[1], false, false, 0)
+	if err == nil {
+		t.Fatal("ProcessTransaction: accepted a transaction with a fee " +
+			"rate too low for the full pool")
+	}
+	if len(notified) != 0 {
+		t.Fatalf("got %d notifications for a rejected transaction",
+			len(notified))
+	}
+	testPoolMembership(tc, parent, false, true)
+	testPoolMembership(tc, children[0], false, true)
+	testPoolMembership(tc, children[1], false, false)
+
+	// Prioritising the second child higher than the first one evicts the
+	// first one instead.  The parent is kept although it pays the lowest
+	// fee rate since it is spent by the second child.
+	harness.txPool.PrioritiseTransaction(children[1].Hash(), 2000)
+	_, err = harness.txPool.ProcessTransaction(children[1], false, false, 0)
+	if err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept valid tx: %v", err)
+	}
+	testPoolMembership(tc, parent, false, true)
+	testPoolMembership(tc, children[0], false, false)
+	testPoolMembership(tc, children[1], false, true)
+	if len(notified) != 2 || notified[1].Type != NTTxRemoved ||
+		notified[1].Data != children[0] ||
+		notified[1].Reason != RRSizeLimit {
+
+		t.Fatalf("unexpected notifications %+v", notified)
+	}
+
+	// The eviction raised the minimum fee of the pool above the fee rate
+	// of the evicted child, so it is rejected even when there is room for
+	// it until it pays more than the incremental relay fee on top.
+	harness.txPool.cfg.Policy.MaxPoolSize = 0
+	_, err = harness.txPool.ProcessTransaction(children[0], false, false, 0)
+	if err == nil {
+		t.Fatal("ProcessTransaction: accepted a transaction paying less " +
+			"than the minimum fee of the pool")
+	}
+	harness.txPool.PrioritiseTransaction(children[0].Hash(),
+		incrementalRelayFeePerKB)
+	_, err = harness.txPool.ProcessTransaction(children[0], false, false, 0)
+	if err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept valid tx: %v", err)
+	}
+	testPoolMembership(tc, children[0], false, true)
+}
+
+// TestSaveLoadFeeDeltas ensures fee deltas saved to a file are restored when
+// they are loaded by another pool.
+func TestSaveLoadFeeDeltas(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "feedeltas")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	filePath := filepath.Join(dir, "feedeltas.json")
+
+	harness, _, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+
+	// Loading a missing file is not an error.
+	if err := harness.txPool.LoadFeeDeltas(filePath); err != nil {
+		t.Fatalf("LoadFeeDeltas: unexpected error: %v", err)
+	}
+
+	want := map[chainhash.Hash]int64{
+		{0x01}: 1000,
+		{0x02}: -500,
+	}
+	for hash, delta := range want {
+		hash := hash
+		harness.txPool.PrioritiseTransaction(&hash, delta)
+	}
+	if err := harness.txPool.SaveFeeDeltas(filePath); err != nil {
+		t.Fatalf("SaveFeeDeltas: unexpected error: %v", err)
+	}
+
+	restored, _, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	if err := restored.txPool.LoadFeeDeltas(filePath); err != nil {
+		t.Fatalf("LoadFeeDeltas: unexpected error: %v", err)
+	}
+	if got := restored.txPool.FeeDeltas(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("FeeDeltas: got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mempool
+
+import (
+	"fmt"
+)
+
+// NotificationType represents the type of a notification message.
+type NotificationType int
+
+// NotificationCallback is used for a caller to provide a callback for
+// notifications about transactions entering and leaving the memory pool.
+type NotificationCallback func(*Notification)
+
+// Constants for the type of a notification message.
+const (
+	// NTTxAccepted indicates the associated transaction was added to the
+	// memory pool.
+	NTTxAccepted NotificationType = iota
+
+	// NTTxRemoved indicates the associated transaction was removed from
+	// the memory pool.  This includes transactions which were removed
+	// because they were included in a block connected to the main chain.
+	// The reason of the removal is provided with the notification.
+	NTTxRemoved
+)
+
+// RemovalReason describes why a transaction was removed from the memory pool.
+type RemovalReason int
+
+// Constants for the reason a transaction was removed from the memory pool.
+// Transactions which are removed because they redeem outputs of a removed
+// transaction are removed for the same reason.
+const (
+	// RRExplicit indicates the transaction was removed via
+	// RemoveTransaction, such as when it could not be added back to the
+	// memory pool after a reorganization.
+	RRExplicit RemovalReason = iota
+
+	// RRBlock indicates the transaction was included in a block connected
+	// to the main chain.
+	RRBlock
+
+	// RRConflict indicates the transaction spent an output which is spent
+	// by a transaction included in a block connected to the main chain.
+	RRConflict
+
+	// RRSizeLimit indicates the transaction was evicted from the full
+	// memory pool.
+	RRSizeLimit
+)
+
+// removalReasonStrings is a map of removal reasons back to their constant
+// names for pretty printing.
+var removalReasonStrings = map[RemovalReason]string{
+	RRExplicit:  "RRExplicit",
+	RRBlock:     "RRBlock",
+	RRConflict:  "RRConflict",
+	RRSizeLimit: "RRSizeLimit",
+}
+
+// String returns the RemovalReason in human-readable form.
+func (r RemovalReason) String() string {
+	if s, ok := removalReasonStrings[r]; ok {
+		return s
+	}
+	return fmt.Sprintf("Unknown Removal Reason (%d)", int(r))
+}
+
+// notificationTypeStrings is a map of notification types back to their constant
+// names for pretty printing.
+var notificationTypeStrings = map[NotificationType]string{
+	NTTxAccepted: "NTTxAccepted",
+	NTTxRemoved:  "NTTxRemoved",
+}
+
+// String returns the NotificationType in human-readable form.
+func (n NotificationType) String() string {
+	if s, ok := notificationTypeStrings[n]; ok {
+		return s
+	}
+	return fmt.Sprintf("Unknown Notification Type (%d)", int(n))
+}
+
+// Notification defines notification that is sent to the caller via the callback
+// function provided during the call to Subscribe and consists of a notification
+// type as well as associated data that depends on the type as follows:
+// 	- NTTxAccepted: *vtcutil.Tx
+// 	- NTTxRemoved:  *vtcutil.Tx
+//
+// The reason is only set for NTTxRemoved notifications.
+type Notification struct {
+	Type   NotificationType
+	Data   interface{}
+	Reason RemovalReason
+}
+
+// Subscribe to memory pool notifications.  Registers a callback to be executed
+// whenever a transaction is added to or removed from the memory pool.  The
+// callback is executed with the mempool lock held, so it must not call back
+// into the memory pool.
+func (mp *TxPool) Subscribe(callback NotificationCallback) {
+	mp.notificationsLock.Lock()
+	mp.notifications = append(mp.notifications, callback)
+	mp.notificationsLock.Unlock()
+}
+
+// sendNotification sends the passed notification to all subscribed callbacks.
+func (mp *TxPool) sendNotification(n *Notification) {
+	mp.notificationsLock.RLock()
+	for _, callback := range mp.notifications {
+		callback(n)
+	}
+	mp.notificationsLock.RUnlock()
+}
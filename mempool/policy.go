@@ -19,9 +19,20 @@ const (
 	// that are considered standard in a pay-to-script-hash script.
 	maxStandardP2SHSigOps = 15
 
-	// maxStandardTxCost is the max weight permitted by any transaction
-	// according to the current default policy.
-	maxStandardTxWeight = 400000
+	// DefaultMaxStandardTxWeight is the default maximum weight permitted
+	// by any standard transaction.
+	DefaultMaxStandardTxWeight = 400000
+
+	// DefaultMaxStandardTxSigOpCost is the default maximum cost of all the
+	// signature operations of a transaction which is relayed or mined.  It
+	// is a fraction of the maximum signature operation cost of a block.
+	DefaultMaxStandardTxSigOpCost = blockchain.MaxBlockSigOpsCost / 4
+
+	// DefaultMaxDataCarrierSize is the default maximum size in bytes of
+	// standard output scripts which only carry data.  It allows a push of
+	// txscript.MaxDataCarrierSize bytes following OP_RETURN, which needs
+	// an OP_RETURN, OP_PUSHDATA1 and length byte.
+	DefaultMaxDataCarrierSize = txscript.MaxDataCarrierSize + 3
 
 	// maxStandardSigScriptSize is the maximum size allowed for a
 	// transaction input signature script to be considered standard.  This
@@ -55,6 +66,49 @@ const (
 	maxStandardMultiSigKeys = 3
 )
 
+// StandardPolicy houses the configurable rules which decide whether a
+// transaction is standard.  It is used by the memory pool to reject
+// non-standard transactions and by the block template generator to skip them.
+type StandardPolicy struct {
+	// DataCarrier defines whether transactions with an output which only
+	// carries data are standard.
+	DataCarrier bool
+
+	// MaxDataCarrierSize is the maximum size in bytes of a standard output
+	// script which only carries data.
+	MaxDataCarrierSize uint32
+
+	// DustRelayFee is the fee rate in Satoshi/kB which decides whether an
+	// output is dust.  Outputs which cost more than a third of their value
+	// to spend at this fee rate are dust.
+	DustRelayFee vtcutil.Amount
+
+	// PermitBareMultiSig defines whether transactions with bare
+	// multi-signature outputs are standard.
+	PermitBareMultiSig bool
+
+	// MaxTxWeight is the maximum weight of a standard transaction.
+	MaxTxWeight int64
+
+	// MaxTxSigOpCost is the maximum cost of all the signature operations
+	// of a transaction which is relayed or mined.  Unlike the other rules,
+	// it is also enforced when non-standard transactions are accepted.
+	MaxTxSigOpCost int
+}
+
+// DefaultStandardPolicy returns the standard policy with the default settings
+// of the reference implementation.
+func DefaultStandardPolicy() StandardPolicy {
+	return StandardPolicy{
+		DataCarrier:        true,
+		MaxDataCarrierSize: DefaultMaxDataCarrierSize,
+		DustRelayFee:       DefaultMinRelayTxFee,
+		PermitBareMultiSig: true,
+		MaxTxWeight:        DefaultMaxStandardTxWeight,
+		MaxTxSigOpCost:     DefaultMaxStandardTxSigOpCost,
+	}
+}
+
 // calcMinRequiredTxRelayFee returns the minimum transaction fee required for a
 // transaction with the passed serialized size to be accepted into the memory
 // pool and relayed.
@@ -173,12 +227,20 @@ func checkPkScriptStandard(pkScript []byte, scriptClass txscript.ScriptClass) er
 	return nil
 }
 
+// isDataCarrier returns whether or not the passed public key script only
+// carries data, which is the case when OP_RETURN is followed by nothing but
+// data pushes.  Unlike the null data script class, the amount of data is not
+// limited since the standard policy limits the size of the script instead.
+func isDataCarrier(pkScript []byte) bool {
+	return len(pkScript) > 0 && pkScript[0] == txscript.OP_RETURN &&
+		txscript.IsPushOnlyScript(pkScript[1:])
+}
+
 // isDust returns whether or not the passed transaction output amount is
-// considered dust or not based on the passed minimum transaction relay fee.
-// Dust is defined in terms of the minimum transaction relay fee.  In
-// particular, if the cost to the network to spend coins is more than 1/3 of the
-// minimum transaction relay fee, it is considered dust.
-func isDust(txOut *wire.TxOut, minRelayTxFee vtcutil.Amount) bool {
+// considered dust or not based on the passed dust relay fee.  Dust is defined
+// in terms of the dust relay fee.  In particular, if the cost to the network to
+// spend coins is more than 1/3 of the dust relay fee, it is considered dust.
+func isDust(txOut *wire.TxOut, dustRelayFee vtcutil.Amount) bool {
 	// Unspendable outputs are considered dust.
 	if txscript.IsUnspendable(txOut.PkScript) {
 		return true
@@ -255,18 +317,16 @@ func isDust(txOut *wire.TxOut, minRelayTxFee vtcutil.Amount) bool {
 	}
 
 	// The output is considered dust if the cost to the network to spend the
-	// coins is more than 1/3 of the minimum free transaction relay fee.
-	// minFreeTxRelayFee is in Satoshi/KB, so multiply by 1000 to
-	// convert to bytes.
+	// coins is more than 1/3 of the dust relay fee.  dustRelayFee is in
+	// Satoshi/KB, so multiply by 1000 to convert to bytes.
 	//
 	// Using the typical values for a pay-to-pubkey-hash transaction from
-	// the breakdown above and the default minimum free transaction relay
-	// fee of 1000, this equates to values less than 546 satoshi being
-	// considered dust.
+	// the breakdown above and the default dust relay fee of 1000, this
+	// equates to values less than 546 satoshi being considered dust.
 	//
 	// The following is equivalent to (value/totalSize) * (1/3) * 1000
 	// without needing to do floating point math.
-	return txOut.Value*1000/(3*int64(totalSize)) < int64(minRelayTxFee)
+	return txOut.Value*1000/(3*int64(totalSize)) < int64(dustRelayFee)
 }
 
 // checkTransactionStandard performs a series of checks on a transaction to
@@ -275,9 +335,10 @@ func isDust(txOut *wire.TxOut, minRelayTxFee vtcutil.Amount) bool {
 // "sane" transaction such as having a version in the supported range, being
 // finalized, conforming to more stringent size constraints, having scripts
 // of recognized forms, and not containing "dust" outputs (those that are
-// so small it costs more to process them than they are worth).
+// so small it costs more to process them than they are worth).  The
+// configurable rules are taken from the passed standard policy.
 func checkTransactionStandard(tx *vtcutil.Tx, height int32,
-	medianTimePast time.Time, minRelayTxFee vtcutil.Amount,
+	medianTimePast time.Time, policy *StandardPolicy,
 	maxTxVersion int32) error {
 
 	// The transaction must be a currently supported version.
@@ -301,9 +362,9 @@ func checkTransactionStandard(tx *vtcutil.Tx, height int32,
 	// size of a transaction.  This also helps mitigate CPU exhaustion
 	// attacks.
 	txWeight := blockchain.GetTransactionWeight(tx)
-	if txWeight > maxStandardTxWeight {
+	if txWeight > policy.MaxTxWeight {
 		str := fmt.Sprintf("weight of transaction %v is larger than max "+
-			"allowed weight of %v", txWeight, maxStandardTxWeight)
+			"allowed weight of %v", txWeight, policy.MaxTxWeight)
 		return txRuleError(wire.RejectNonstandard, str)
 	}
 
@@ -330,10 +391,37 @@ func checkTransactionStandard(tx *vtcutil.Tx, height int32,
 	}
 
 	// None of the output public key scripts can be a non-standard script or
-	// be "dust" (except when the script is a null data script).
+	// be "dust" (except when the script only carries data).  Outputs which
+	// only carry data and bare multi-signature outputs are only standard
+	// when the policy permits them.
 	numNullDataOutputs := 0
 	for i, txOut := range msgTx.TxOut {
+		if isDataCarrier(txOut.PkScript) {
+			if !policy.DataCarrier {
+				str := fmt.Sprintf("transaction output %d: "+
+					"data carrier outputs are not "+
+					"permitted", i)
+				return txRuleError(wire.RejectNonstandard, str)
+			}
+			scriptLen := len(txOut.PkScript)
+			if scriptLen > int(policy.MaxDataCarrierSize) {
+				str := fmt.Sprintf("transaction output %d: "+
+					"data carrier script size of %d bytes "+
+					"is larger than max allowed size of "+
+					"%d bytes", i, scriptLen,
+					policy.MaxDataCarrierSize)
+				return txRuleError(wire.RejectNonstandard, str)
+			}
+			numNullDataOutputs++
+			continue
+		}
+
 		scriptClass := txscript.GetScriptClass(txOut.PkScript)
+		if scriptClass == txscript.MultiSigTy && !policy.PermitBareMultiSig {
+			str := fmt.Sprintf("transaction output %d: bare "+
+				"multi-signature outputs are not permitted", i)
+			return txRuleError(wire.RejectNonstandard, str)
+		}
 		err := checkPkScriptStandard(txOut.PkScript, scriptClass)
 		if err != nil {
 			// Attempt to extract a reject code from the error so
@@ -347,12 +435,8 @@ func checkTransactionStandard(tx *vtcutil.Tx, height int32,
 			return txRuleError(rejectCode, str)
 		}
 
-		// Accumulate the number of outputs which only carry data.  For
-		// all other script types, ensure the output value is not
-		// "dust".
-		if scriptClass == txscript.NullDataTy {
-			numNullDataOutputs++
-		} else if isDust(txOut, minRelayTxFee) {
+		// Ensure the output value is not "dust".
+		if isDust(txOut, policy.DustRelayFee) {
 			str := fmt.Sprintf("transaction output %d: payment "+
 				"of %d is dust", i, txOut.Value)
 			return txRuleError(wire.RejectDust, str)
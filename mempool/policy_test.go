@@ -41,13 +41,13 @@ func TestCalcMinRequiredTxRelayFee(t *testing.T) {
 		},
 		{
 			"max standard tx size with default minimum relay fee",
-			maxStandardTxWeight / 4,
+			DefaultMaxStandardTxWeight / 4,
 			DefaultMinRelayTxFee,
 			100000,
 		},
 		{
 			"max standard tx size with max satoshi relay fee",
-			maxStandardTxWeight / 4,
+			DefaultMaxStandardTxWeight / 4,
 			vtcutil.MaxSatoshi,
 			vtcutil.MaxSatoshi,
 		},
@@ -360,7 +360,7 @@ func TestCheckTransactionStandard(t *testing.T) {
 				TxOut: []*wire.TxOut{{
 					Value: 0,
 					PkScript: bytes.Repeat([]byte{0x00},
-						(maxStandardTxWeight/4)+1),
+						(DefaultMaxStandardTxWeight/4)+1),
 				}},
 				LockTime: 0,
 			},
@@ -467,10 +467,11 @@ func TestCheckTransactionStandard(t *testing.T) {
 	}
 
 	pastMedianTime := time.Now()
+	policy := DefaultStandardPolicy()
 	for _, test := range tests {
 		// Ensure standardness is as expected.
 		err := checkTransactionStandard(vtcutil.NewTx(&test.tx),
-			test.height, pastMedianTime, DefaultMinRelayTxFee, 1)
+			test.height, pastMedianTime, &policy, 1)
 		if err == nil && test.isStandard {
 			// Test passes since function returned standard for a
 			// transaction which is intended to be standard.
@@ -510,3 +511,148 @@ func TestCheckTransactionStandard(t *testing.T) {
 		}
 	}
 }
+
+// TestCheckTransactionStandardPolicy ensures checkTransactionStandard follows
+// the configurable rules of the standard policy.
+func TestCheckTransactionStandardPolicy(t *testing.T) {
+	prevOutHash, err := chainhash.NewHashFromStr("01")
+	if err != nil {
+		t.Fatalf("NewShaHashFromStr: unexpected error: %v", err)
+	}
+	txIn := wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Hash: *prevOutHash, Index: 1},
+		SignatureScript:  bytes.Repeat([]byte{0x00}, 65),
+		Sequence:         wire.MaxTxInSequenceNum,
+	}
+	pkHashScript, err := txscript.NewScriptBuilder().AddOp(txscript.OP_DUP).
+		AddOp(txscript.OP_HASH160).AddData(make([]byte, 20)).
+		AddOp(txscript.OP_EQUALVERIFY).AddOp(txscript.OP_CHECKSIG).
+		Script()
+	if err != nil {
+		t.Fatalf("NewScriptBuilder: unexpected error: %v", err)
+	}
+	pubKey := append([]byte{0x02}, bytes.Repeat([]byte{0x01}, 32)...)
+	multiSigScript, err := txscript.NewScriptBuilder().AddOp(txscript.OP_1).
+		AddData(pubKey).AddOp(txscript.OP_1).
+		AddOp(txscript.OP_CHECKMULTISIG).Script()
+	if err != nil {
+		t.Fatalf("NewScriptBuilder: unexpected error: %v", err)
+	}
+	largeDataScript, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_RETURN).
+		AddData(bytes.Repeat([]byte{0x01}, 100)).Script()
+	if err != nil {
+		t.Fatalf("NewScriptBuilder: unexpected error: %v", err)
+	}
+
+	newTx := func(txOuts ...*wire.TxOut) *vtcutil.Tx {
+		return vtcutil.NewTx(&wire.MsgTx{
+			Version: 1,
+			TxIn:    []*wire.TxIn{&txIn},
+			TxOut:   txOuts,
+		})
+	}
+	payment := &wire.TxOut{Value: 100000000, PkScript: pkHashScript}
+	smallPayment := &wire.TxOut{Value: 1000, PkScript: pkHashScript}
+	multiSig := &wire.TxOut{Value: 100000000, PkScript: multiSigScript}
+	dataCarrier := &wire.TxOut{PkScript: []byte{txscript.OP_RETURN, 0x01, 0x01}}
+	largeDataCarrier := &wire.TxOut{PkScript: largeDataScript}
+
+	tests := []struct {
+		name       string
+		tx         *vtcutil.Tx
+		policy     func(*StandardPolicy)
+		isStandard bool
+		code       wire.RejectCode
+	}{
+		{
+			name:       "data carrier with default policy",
+			tx:         newTx(payment, dataCarrier),
+			isStandard: true,
+		},
+		{
+			name: "data carrier when not permitted",
+			tx:   newTx(payment, dataCarrier),
+			policy: func(p *StandardPolicy) {
+				p.DataCarrier = false
+			},
+			code: wire.RejectNonstandard,
+		},
+		{
+			name: "large data carrier with default policy",
+			tx:   newTx(payment, largeDataCarrier),
+			code: wire.RejectNonstandard,
+		},
+		{
+			name: "large data carrier with larger max size",
+			tx:   newTx(payment, largeDataCarrier),
+			policy: func(p *StandardPolicy) {
+				p.MaxDataCarrierSize = uint32(len(largeDataScript))
+			},
+			isStandard: true,
+		},
+		{
+			name:       "bare multisig with default policy",
+			tx:         newTx(multiSig),
+			isStandard: true,
+		},
+		{
+			name: "bare multisig when not permitted",
+			tx:   newTx(multiSig),
+			policy: func(p *StandardPolicy) {
+				p.PermitBareMultiSig = false
+			},
+			code: wire.RejectNonstandard,
+		},
+		{
+			name:       "small payment with default dust relay fee",
+			tx:         newTx(smallPayment),
+			isStandard: true,
+		},
+		{
+			name: "small payment with higher dust relay fee",
+			tx:   newTx(smallPayment),
+			policy: func(p *StandardPolicy) {
+				p.DustRelayFee = 10000
+			},
+			code: wire.RejectDust,
+		},
+		{
+			name: "payment with lower max weight",
+			tx:   newTx(payment),
+			policy: func(p *StandardPolicy) {
+				p.MaxTxWeight = 100
+			},
+			code: wire.RejectNonstandard,
+		},
+	}
+
+	pastMedianTime := time.Now()
+	for _, test := range tests {
+		policy := DefaultStandardPolicy()
+		if test.policy != nil {
+			test.policy(&policy)
+		}
+		err := checkTransactionStandard(test.tx, 300000, pastMedianTime,
+			&policy, 1)
+		if test.isStandard {
+			if err != nil {
+				t.Errorf("checkTransactionStandard (%s): "+
+					"nonstandard when it should not be: %v",
+					test.name, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("checkTransactionStandard (%s): standard when "+
+				"it should not be", test.name)
+			continue
+		}
+		code, _ := extractRejectCode(err)
+		if code != test.code {
+			t.Errorf("checkTransactionStandard (%s): unexpected "+
+				"error code - got %v, want %v", test.name, code,
+				test.code)
+		}
+	}
+}
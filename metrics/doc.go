@@ -0,0 +1,24 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package metrics implements counters, gauges and histograms which are exposed in
+the Prometheus text format.
+
+Packages instrument themselves by creating their metrics at package
+initialization with the package-level constructors, which register them with
+DefaultRegistry, and updating them at the source of the events they describe.
+All metric operations are safe for concurrent access and cheap enough to be
+performed on hot paths such as reading messages from peers.
+
+A Registry implements http.Handler, so the metrics of all packages are served
+by registering DefaultRegistry with an HTTP server:
+
+	http.Handle("/metrics", metrics.DefaultRegistry)
+
+Metric and label names must match the Prometheus naming rules and must be
+unique within a registry.  Violations are programming errors, so the
+constructors panic on them.
+*/
+package metrics
@@ -0,0 +1,371 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// validName matches the valid names of metrics and labels.
+var validName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// Metric is a named metric which is exposed by a Registry.
+type Metric interface {
+	// Name returns the name of the metric.
+	Name() string
+
+	// write writes the HELP and TYPE lines of the metric followed by its
+	// samples in the Prometheus text format.
+	write(w *bufio.Writer)
+}
+
+// desc houses the name, help text and type of a metric.
+type desc struct {
+	name string
+	help string
+	typ  string
+}
+
+// Name returns the name of the metric.
+func (d *desc) Name() string {
+	return d.name
+}
+
+// writeHeader writes the HELP and TYPE lines of the metric.
+func (d *desc) writeHeader(w *bufio.Writer) {
+	help := strings.Replace(d.help, `\`, `\\`, -1)
+	help = strings.Replace(help, "\n", `\n`, -1)
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", d.name, help, d.name,
+		d.typ)
+}
+
+// labelPair returns the passed label name and value in the Prometheus text
+// format without the enclosing braces.
+func labelPair(name, value string) string {
+	value = strings.Replace(value, `\`, `\\`, -1)
+	value = strings.Replace(value, "\n", `\n`, -1)
+	value = strings.Replace(value, `"`, `\"`, -1)
+	return fmt.Sprintf(`%s="%s"`, name, value)
+}
+
+// formatLabel returns the passed label pair in the Prometheus text format.
+func formatLabel(name, value string) string {
+	return "{" + labelPair(name, value) + "}"
+}
+
+// formatFloat returns the passed value in the Prometheus text format.
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// Counter is a metric whose value only increases, such as the number of
+// processed events.
+type Counter struct {
+	// The value is accessed atomically, so it must be the first field to
+	// ensure its 64-bit alignment on 32-bit platforms.
+	value uint64
+
+	desc
+}
+
+// Inc increments the counter by one.
+//
+// This function is safe for concurrent access.
+func (c *Counter) Inc() {
+	atomic.AddUint64(&c.value, 1)
+}
+
+// Add increments the counter by the passed value.
+//
+// This function is safe for concurrent access.
+func (c *Counter) Add(n uint64) {
+	atomic.AddUint64(&c.value, n)
+}
+
+// Value returns the current value of the counter.
+//
+// This function is safe for concurrent access.
+func (c *Counter) Value() uint64 {
+	return atomic.LoadUint64(&c.value)
+}
+
+// write writes the counter in the Prometheus text format.
+func (c *Counter) write(w *bufio.Writer) {
+	c.writeHeader(w)
+	fmt.Fprintf(w, "%s %d\n", c.name, c.Value())
+}
+
+// Gauge is a metric whose value may increase and decrease, such as the number
+// of items in a queue.
+type Gauge struct {
+	// The value is accessed atomically, so it must be the first field to
+	// ensure its 64-bit alignment on 32-bit platforms.
+	value int64
+
+	desc
+}
+
+// Set sets the gauge to the passed value.
+//
+// This function is safe for concurrent access.
+func (g *Gauge) Set(v int64) {
+	atomic.StoreInt64(&g.value, v)
+}
+
+// Add adds the passed value, which may be negative, to the gauge.
+//
+// This function is safe for concurrent access.
+func (g *Gauge) Add(v int64) {
+	atomic.AddInt64(&g.value, v)
+}
+
+// Value returns the current value of the gauge.
+//
+// This function is safe for concurrent access.
+func (g *Gauge) Value() int64 {
+	return atomic.LoadInt64(&g.value)
+}
+
+// write writes the gauge in the Prometheus text format.
+func (g *Gauge) write(w *bufio.Writer) {
+	g.writeHeader(w)
+	fmt.Fprintf(w, "%s %d\n", g.name, g.Value())
+}
+
+// CounterVec is a set of counters which are partitioned by the value of a
+// label, such as the number of bytes received per message type.
+type CounterVec struct {
+	desc
+	label string
+
+	mtx      sync.RWMutex
+	counters map[string]*Counter
+}
+
+// With returns the counter for the passed label value, creating it as needed.
+//
+// This function is safe for concurrent access.
+func (v *CounterVec) With(value string) *Counter {
+	v.mtx.RLock()
+	c, ok := v.counters[value]
+	v.mtx.RUnlock()
+	if ok {
+		return c
+	}
+
+	v.mtx.Lock()
+	c, ok = v.counters[value]
+	if !ok {
+		c = &Counter{desc: v.desc}
+		v.counters[value] = c
+	}
+	v.mtx.Unlock()
+	return c
+}
+
+// write writes the counters ordered by their label value in the Prometheus
+// text format.
+func (v *CounterVec) write(w *bufio.Writer) {
+	v.writeHeader(w)
+	v.mtx.RLock()
+	values := make([]string, 0, len(v.counters))
+	for value := range v.counters {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	for _, value := range values {
+		fmt.Fprintf(w, "%s%s %d\n", v.name, formatLabel(v.label, value),
+			v.counters[value].Value())
+	}
+	v.mtx.RUnlock()
+}
+
+// GaugeVec is a set of gauges which are partitioned by the value of a label,
+// such as the number of peers per connection direction.
+type GaugeVec struct {
+	desc
+	label string
+
+	mtx    sync.RWMutex
+	gauges map[string]*Gauge
+}
+
+// With returns the gauge for the passed label value, creating it as needed.
+//
+// This function is safe for concurrent access.
+func (v *GaugeVec) With(value string) *Gauge {
+	v.mtx.RLock()
+	g, ok := v.gauges[value]
+	v.mtx.RUnlock()
+	if ok {
+		return g
+	}
+
+	v.mtx.Lock()
+	g, ok = v.gauges[value]
+	if !ok {
+		g = &Gauge{desc: v.desc}
+		v.gauges[value] = g
+	}
+	v.mtx.Unlock()
+	return g
+}
+
+// write writes the gauges ordered by their label value in the Prometheus text
+// format.
+func (v *GaugeVec) write(w *bufio.Writer) {
+	v.writeHeader(w)
+	v.mtx.RLock()
+	values := make([]string, 0, len(v.gauges))
+	for value := range v.gauges {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	for _, value := range values {
+		fmt.Fprintf(w, "%s%s %d\n", v.name, formatLabel(v.label, value),
+			v.gauges[value].Value())
+	}
+	v.mtx.RUnlock()
+}
+
+// Histogram is a metric which counts observations, such as latencies, in
+// buckets with configurable upper bounds along with their total sum.
+type Histogram struct {
+	desc
+
+	mtx     sync.Mutex
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+// Observe adds the passed value to the histogram.
+//
+// This function is safe for concurrent access.
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.buckets, v)
+	h.mtx.Lock()
+	if i < len(h.counts) {
+		h.counts[i]++
+	}
+	h.sum += v
+	h.count++
+	h.mtx.Unlock()
+}
+
+// write writes the cumulative bucket counts of the histogram along with the sum
+// and count of all observations in the Prometheus text format.
+func (h *Histogram) write(w *bufio.Writer) {
+	h.writeHeader(w)
+	h.writeSamples(w, "")
+}
+
+// writeSamples writes the samples of the histogram in the Prometheus text
+// format.  The passed label pair, if any, is added to every sample.
+func (h *Histogram) writeSamples(w *bufio.Writer, label string) {
+	bucketLabel := func(bound string) string {
+		if label == "" {
+			return "{" + labelPair("le", bound) + "}"
+		}
+		return "{" + label + "," + labelPair("le", bound) + "}"
+	}
+	var labels string
+	if label != "" {
+		labels = "{" + label + "}"
+	}
+
+	h.mtx.Lock()
+	var cumulative uint64
+	for i, bound := range h.buckets {
+		cumulative += h.counts[i]
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name,
+			bucketLabel(formatFloat(bound)), cumulative)
+	}
+	fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, bucketLabel("+Inf"),
+		h.count)
+	fmt.Fprintf(w, "%s_sum%s %s\n", h.name, labels, formatFloat(h.sum))
+	fmt.Fprintf(w, "%s_count%s %d\n", h.name, labels, h.count)
+	h.mtx.Unlock()
+}
+
+// HistogramVec is a set of histograms which are partitioned by the value of a
+// label, such as the latency of RPC calls per method.
+type HistogramVec struct {
+	desc
+	label   string
+	buckets []float64
+
+	mtx        sync.RWMutex
+	histograms map[string]*Histogram
+}
+
+// With returns the histogram for the passed label value, creating it as
+// needed.
+//
+// This function is safe for concurrent access.
+func (v *HistogramVec) With(value string) *Histogram {
+	v.mtx.RLock()
+	h, ok := v.histograms[value]
+	v.mtx.RUnlock()
+	if ok {
+		return h
+	}
+
+	v.mtx.Lock()
+	h, ok = v.histograms[value]
+	if !ok {
+		h = &Histogram{
+			desc:    v.desc,
+			buckets: v.buckets,
+			counts:  make([]uint64, len(v.buckets)),
+		}
+		v.histograms[value] = h
+	}
+	v.mtx.Unlock()
+	return h
+}
+
+// write writes the histograms ordered by their label value in the Prometheus
+// text format.
+func (v *HistogramVec) write(w *bufio.Writer) {
+	v.writeHeader(w)
+	v.mtx.RLock()
+	values := make([]string, 0, len(v.histograms))
+	for value := range v.histograms {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	for _, value := range values {
+		v.histograms[value].writeSamples(w, labelPair(v.label, value))
+	}
+	v.mtx.RUnlock()
+}
+
+// ExponentialBuckets returns count bucket upper bounds for a histogram where
+// the first bound is start and each further bound is factor times the previous
+// one.
+func ExponentialBuckets(start, factor float64, count int) []float64 {
+	buckets := make([]float64, count)
+	for i := range buckets {
+		buckets[i] = start
+		start *= factor
+	}
+	return buckets
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestWriteText ensures the metrics of a registry are written ordered by name
+// in the Prometheus text format.
+func TestWriteText(t *testing.T) {
+	t.Parallel()
+
+	r := NewRegistry()
+	counter := r.NewCounter("test_events_total", "Number of events.")
+	gauge := r.NewGauge("test_height", "Current height.")
+	counterVec := r.NewCounterVec("test_bytes_total",
+		"Bytes per \"command\".\nSecond line \\.", "command")
+	gaugeVec := r.NewGaugeVec("test_peers", "Number of peers.",
+		"direction")
+	histogram := r.NewHistogram("test_latency_seconds",
+		"Latency in seconds.", ExponentialBuckets(0.5, 2, 3))
+	histogramVec := r.NewHistogramVec("test_call_seconds",
+		"Call duration in seconds.", "method", []float64{1})
+
+	counter.Inc()
+	counter.Add(2)
+	gauge.Set(10)
+	gauge.Add(-3)
+	counterVec.With("tx").Add(250)
+	counterVec.With("block").Add(1000)
+	counterVec.With("a\"b").Inc()
+	gaugeVec.With("outbound").Set(8)
+	gaugeVec.With("inbound").Add(1)
+	for _, v := range []float64{0.25, 0.5, 1.5, 3} {
+		histogram.Observe(v)
+	}
+	histogramVec.With("b").Observe(2)
+	histogramVec.With("a").Observe(0.5)
+
+	want := `# HELP test_bytes_total Bytes per "command".\nSecond line \\.
+# TYPE test_bytes_total counter
+test_bytes_total{command="a\"b"} 1
+test_bytes_total{command="block"} 1000
+test_bytes_total{command="tx"} 250
+# HELP test_call_seconds Call duration in seconds.
+# TYPE test_call_seconds histogram
+test_call_seconds_bucket{method="a",le="1"} 1
+test_call_seconds_bucket{method="a",le="+Inf"} 1
+test_call_seconds_sum{method="a"} 0.5
+test_call_seconds_count{method="a"} 1
+test_call_seconds_bucket{method="b",le="1"} 0
+test_call_seconds_bucket{method="b",le="+Inf"} 1
+test_call_seconds_sum{method="b"} 2
+test_call_seconds_count{method="b"} 1
+# HELP test_events_total Number of events.
+# TYPE test_events_total counter
+test_events_total 3
+# HELP test_height Current height.
+# TYPE test_height gauge
+test_height 7
+# HELP test_latency_seconds Latency in seconds.
+# TYPE test_latency_seconds histogram
+test_latency_seconds_bucket{le="0.5"} 2
+test_latency_seconds_bucket{le="1"} 2
+test_latency_seconds_bucket{le="2"} 3
+test_latency_seconds_bucket{le="+Inf"} 4
+test_latency_seconds_sum 5.25
+test_latency_seconds_count 4
+# HELP test_peers Number of peers.
+# TYPE test_peers gauge
+test_peers{direction="inbound"} 1
+test_peers{direction="outbound"} 8
+`
+	var buf bytes.Buffer
+	if err := r.WriteText(&buf); err != nil {
+		t.Fatalf("WriteText: unexpected error: %v", err)
+	}
+	if buf.String() != want {
+		t.Errorf("WriteText: unexpected output - got:\n%s\nwant:\n%s",
+			buf.String(), want)
+	}
+
+	// The registry serves the same output over HTTP.
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != want ||
+		rec.Header().Get("Content-Type") != ContentType {
+
+		t.Errorf("ServeHTTP: unexpected response %d %q", rec.Code,
+			rec.Header().Get("Content-Type"))
+	}
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("POST", "/metrics", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("ServeHTTP: unexpected status %d for POST", rec.Code)
+	}
+}
+
+// TestRegisterInvalid ensures invalid and duplicate metrics are rejected.
+func TestRegisterInvalid(t *testing.T) {
+	t.Parallel()
+
+	r := NewRegistry()
+	r.NewCounter("test_total", "")
+
+	tests := []struct {
+		name     string
+		register func()
+	}{
+		{"duplicate", func() { r.NewGauge("test_total", "") }},
+		{"invalid name", func() { r.NewCounter("test-total", "") }},
+		{"invalid label", func() { r.NewCounterVec("test_vec", "", "a b") }},
+		{"reserved label", func() { r.NewGaugeVec("test_vec", "", "le") }},
+		{"unsorted buckets", func() {
+			r.NewHistogram("test_hist", "", []float64{2, 1})
+		}},
+	}
+	for _, test := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: did not panic", test.name)
+				}
+			}()
+			test.register()
+		}()
+	}
+}
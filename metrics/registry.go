@@ -0,0 +1,201 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// ContentType is the content type of the Prometheus text format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultRegistry is the registry the metrics of all packages are registered
+// with by the package-level constructors.
+var DefaultRegistry = NewRegistry()
+
+// Registry holds a set of uniquely named metrics and exposes them in the
+// Prometheus text format.
+type Registry struct {
+	mtx     sync.Mutex
+	metrics map[string]Metric
+}
+
+// NewRegistry returns a new empty registry.
+func NewRegistry() *Registry {
+	return &Registry{metrics: make(map[string]Metric)}
+}
+
+// register adds the passed metric to the registry.  It panics when the name of
+// the metric is invalid or already registered, since metrics are created at
+// package initialization and both cases are programming errors.
+func (r *Registry) register(m Metric) {
+	name := m.Name()
+	if !validName.MatchString(name) {
+		panic(fmt.Sprintf("metrics: invalid metric name %q", name))
+	}
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if _, ok := r.metrics[name]; ok {
+		panic(fmt.Sprintf("metrics: duplicate metric %q", name))
+	}
+	r.metrics[name] = m
+}
+
+// validLabel panics when the passed label name is invalid.
+func validLabel(label string) {
+	if !validName.MatchString(label) || label == "le" {
+		panic(fmt.Sprintf("metrics: invalid label name %q", label))
+	}
+}
+
+// NewCounter creates a counter and registers it with the registry.
+func (r *Registry) NewCounter(name, help string) *Counter {
+	c := &Counter{desc: desc{name: name, help: help, typ: "counter"}}
+	r.register(c)
+	return c
+}
+
+// NewGauge creates a gauge and registers it with the registry.
+func (r *Registry) NewGauge(name, help string) *Gauge {
+	g := &Gauge{desc: desc{name: name, help: help, typ: "gauge"}}
+	r.register(g)
+	return g
+}
+
+// NewCounterVec creates a set of counters partitioned by the passed label and
+// registers it with the registry.
+func (r *Registry) NewCounterVec(name, help, label string) *CounterVec {
+	validLabel(label)
+	v := &CounterVec{
+		desc:     desc{name: name, help: help, typ: "counter"},
+		label:    label,
+		counters: make(map[string]*Counter),
+	}
+	r.register(v)
+	return v
+}
+
+// NewGaugeVec creates a set of gauges partitioned by the passed label and
+// registers it with the registry.
+func (r *Registry) NewGaugeVec(name, help, label string) *GaugeVec {
+	validLabel(label)
+	v := &GaugeVec{
+		desc:   desc{name: name, help: help, typ: "gauge"},
+		label:  label,
+		gauges: make(map[string]*Gauge),
+	}
+	r.register(v)
+	return v
+}
+
+// NewHistogram creates a histogram with the passed bucket upper bounds, which
+// must be sorted in increasing order, and registers it with the registry.
+func (r *Registry) NewHistogram(name, help string, buckets []float64) *Histogram {
+	if !sort.Float64sAreSorted(buckets) {
+		panic(fmt.Sprintf("metrics: unsorted buckets for %q", name))
+	}
+	h := &Histogram{
+		desc:    desc{name: name, help: help, typ: "histogram"},
+		buckets: buckets,
+		counts:  make([]uint64, len(buckets)),
+	}
+	r.register(h)
+	return h
+}
+
+// NewHistogramVec creates a set of histograms with the passed bucket upper
+// bounds, which are partitioned by the passed label, and registers it with the
+// registry.
+func (r *Registry) NewHistogramVec(name, help, label string, buckets []float64) *HistogramVec {
+	validLabel(label)
+	if !sort.Float64sAreSorted(buckets) {
+		panic(fmt.Sprintf("metrics: unsorted buckets for %q", name))
+	}
+	v := &HistogramVec{
+		desc:       desc{name: name, help: help, typ: "histogram"},
+		label:      label,
+		buckets:    buckets,
+		histograms: make(map[string]*Histogram),
+	}
+	r.register(v)
+	return v
+}
+
+// WriteText writes all metrics of the registry ordered by name in the
+// Prometheus text format.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mtx.Lock()
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	metrics := make([]Metric, 0, len(names))
+	sort.Strings(names)
+	for _, name := range names {
+		metrics = append(metrics, r.metrics[name])
+	}
+	r.mtx.Unlock()
+
+	bw := bufio.NewWriter(w)
+	for _, m := range metrics {
+		m.write(bw)
+	}
+	return bw.Flush()
+}
+
+// ServeHTTP serves the metrics of the registry in the Prometheus text format.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != "GET" && req.Method != "HEAD" {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "405 Method Not Allowed.",
+			http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", ContentType)
+	if req.Method == "HEAD" {
+		return
+	}
+	r.WriteText(w)
+}
+
+// NewCounter creates a counter and registers it with the default registry.
+func NewCounter(name, help string) *Counter {
+	return DefaultRegistry.NewCounter(name, help)
+}
+
+// NewGauge creates a gauge and registers it with the default registry.
+func NewGauge(name, help string) *Gauge {
+	return DefaultRegistry.NewGauge(name, help)
+}
+
+// NewCounterVec creates a set of counters partitioned by the passed label and
+// registers it with the default registry.
+func NewCounterVec(name, help, label string) *CounterVec {
+	return DefaultRegistry.NewCounterVec(name, help, label)
+}
+
+// NewGaugeVec creates a set of gauges partitioned by the passed label and
+// registers it with the default registry.
+func NewGaugeVec(name, help, label string) *GaugeVec {
+	return DefaultRegistry.NewGaugeVec(name, help, label)
+}
+
+// NewHistogram creates a histogram with the passed bucket upper bounds and
+// registers it with the default registry.
+func NewHistogram(name, help string, buckets []float64) *Histogram {
+	return DefaultRegistry.NewHistogram(name, help, buckets)
+}
+
+// NewHistogramVec creates a set of histograms with the passed bucket upper
+// bounds, which are partitioned by the passed label, and registers it with the
+// default registry.
+func NewHistogramVec(name, help, label string, buckets []float64) *HistogramVec {
+	return DefaultRegistry.NewHistogramVec(name, help, label, buckets)
+}
@@ -0,0 +1,173 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mining
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/vertcoin/vtcd/txscript"
+	"github.com/vertcoin/vtcd/wire"
+)
+
+const (
+	// MaxCoinbaseFlagsLen is the maximum length of the coinbase flags added
+	// to the coinbase script of a generated block.  It leaves room for the
+	// block height, the extra nonce and any data appended by miners within
+	// the maximum coinbase script length.
+	MaxCoinbaseFlagsLen = 64
+
+	// MaxCoinbaseCommitmentLen is the maximum length of the data carried by
+	// each commitment output of a coinbase spec.
+	MaxCoinbaseCommitmentLen = txscript.MaxDataCarrierSize
+)
+
+// CoinbaseOutput describes an output of the coinbase transaction of a block
+// template.  An output either pays a fixed amount or a share of the coinbase
+// value which remains after paying all fixed amounts.
+type CoinbaseOutput struct {
+	// PkScript is the public key script the output pays to.
+	PkScript []byte
+
+	// Amount is the fixed amount in base units the output pays.  It is
+	// only used when Weight is zero.
+	Amount int64
+
+	// Weight is the share of the remaining coinbase value the output pays
+	// relative to the weights of the other outputs.
+	Weight uint32
+}
+
+// CoinbaseSpec describes the coinbase transaction of a block template.  It
+// allows the block subsidy and fees to be split between several outputs as
+// is required for cooperative mining, a custom tag to be added to the coinbase
+// script and arbitrary data to be committed to in OP_RETURN outputs.
+type CoinbaseSpec struct {
+	// Outputs are the outputs the coinbase value is paid to.  At least one
+	// of them must have a weight since all value which is not paid to
+	// outputs with a fixed amount is split between the weighted outputs.
+	Outputs []CoinbaseOutput
+
+	// Flags is added to the coinbase script instead of the default flags of
+	// the generator when it is not empty.
+	Flags string
+
+	// Commitments is a list of data which is committed to in zero value
+	// OP_RETURN outputs following the payment outputs.
+	Commitments [][]byte
+}
+
+// Validate returns an error when the spec can't be used to create a coinbase
+// transaction.
+func (spec *CoinbaseSpec) Validate() error {
+	if len(spec.Outputs) == 0 {
+		return errors.New("coinbase spec has no outputs")
+	}
+	var totalWeight uint64
+	for i, output := range spec.Outputs {
+		if len(output.PkScript) == 0 {
+			return fmt.Errorf("coinbase output %d has no script", i)
+		}
+		if output.Weight == 0 && output.Amount <= 0 {
+			return fmt.Errorf("coinbase output %d has neither a "+
+				"weight nor a positive amount", i)
+		}
+		totalWeight += uint64(output.Weight)
+	}
+	if totalWeight == 0 {
+		return errors.New("coinbase spec has no weighted outputs to " +
+			"pay the remaining value to")
+	}
+	if len(spec.Flags) > MaxCoinbaseFlagsLen {
+		return fmt.Errorf("coinbase flags length of %d exceeds the "+
+			"maximum of %d", len(spec.Flags), MaxCoinbaseFlagsLen)
+	}
+	for i, commitment := range spec.Commitments {
+		if len(commitment) > MaxCoinbaseCommitmentLen {
+			return fmt.Errorf("coinbase commitment %d length of %d "+
+				"exceeds the maximum of %d", i, len(commitment),
+				MaxCoinbaseCommitmentLen)
+		}
+	}
+	return nil
+}
+
+// outputValues returns the values of the outputs of the spec when the passed
+// total value is paid by the coinbase.  Fixed amounts are paid first and the
+// remaining value is split between the weighted outputs in proportion to their
+// weights.  Any value left over due to rounding is paid to the first weighted
+// output.
+func (spec *CoinbaseSpec) outputValues(total int64) ([]int64, error) {
+	values := make([]int64, len(spec.Outputs))
+	remaining := total
+	var totalWeight int64
+	for i, output := range spec.Outputs {
+		if output.Weight != 0 {
+			totalWeight += int64(output.Weight)
+			continue
+		}
+		values[i] = output.Amount
+		remaining -= output.Amount
+	}
+	if remaining < 0 {
+		return nil, fmt.Errorf("coinbase outputs with fixed amounts pay "+
+			"%d more than the coinbase value of %d", -remaining,
+			total)
+	}
+
+	// The product of the remaining value and a weight may overflow an
+	// int64, so the shares are calculated with big integers.
+	firstWeighted := -1
+	paid := int64(0)
+	bigRemaining := big.NewInt(remaining)
+	bigTotalWeight := big.NewInt(totalWeight)
+	for i, output := range spec.Outputs {
+		if output.Weight == 0 {
+			continue
+		}
+		if firstWeighted == -1 {
+			firstWeighted = i
+		}
+		share := new(big.Int).Mul(bigRemaining,
+			big.NewInt(int64(output.Weight)))
+		values[i] = share.Quo(share, bigTotalWeight).Int64()
+		paid += values[i]
+	}
+	values[firstWeighted] += remaining - paid
+	return values, nil
+}
+
+// txOuts returns the outputs of a coinbase transaction created from the spec.
+// The payment outputs have no value yet.  It is set once the total value paid
+// by the coinbase is known.
+func (spec *CoinbaseSpec) txOuts() ([]*wire.TxOut, error) {
+	txOuts := make([]*wire.TxOut, 0, len(spec.Outputs)+
+		len(spec.Commitments))
+	for _, output := range spec.Outputs {
+		txOuts = append(txOuts, &wire.TxOut{PkScript: output.PkScript})
+	}
+	for _, commitment := range spec.Commitments {
+		pkScript, err := txscript.NullDataScript(commitment)
+		if err != nil {
+			return nil, err
+		}
+		txOuts = append(txOuts, &wire.TxOut{PkScript: pkScript})
+	}
+	return txOuts, nil
+}
+
+// setCoinbaseValue sets the values of the payment outputs of the passed
+// coinbase transaction created from the spec so they pay the passed total.
+func (spec *CoinbaseSpec) setCoinbaseValue(tx *wire.MsgTx, total int64) error {
+	values, err := spec.outputValues(total)
+	if err != nil {
+		return err
+	}
+	for i, value := range values {
+		tx.TxOut[i].Value = value
+	}
+	return nil
+}
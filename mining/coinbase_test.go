@@ -0,0 +1,221 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mining
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/vertcoin/vtcd/txscript"
+	"github.com/vertcoin/vtcd/wire"
+)
+
+// TestCoinbaseSpecValidate ensures invalid coinbase specs are rejected.
+func TestCoinbaseSpecValidate(t *testing.T) {
+	t.Parallel()
+
+	script := []byte{txscript.OP_TRUE}
+	tests := []struct {
+		name  string
+		spec  CoinbaseSpec
+		valid bool
+	}{
+		{
+			name: "weighted and fixed outputs",
+			spec: CoinbaseSpec{
+				Outputs: []CoinbaseOutput{
+					{PkScript: script, Weight: 1},
+					{PkScript: script, Amount: 1000},
+				},
+				Flags:       "/pool/",
+				Commitments: [][]byte{{0x01, 0x02}},
+			},
+			valid: true,
+		},
+		{
+			name:  "no outputs",
+			spec:  CoinbaseSpec{},
+			valid: false,
+		},
+		{
+			name: "output without script",
+			spec: CoinbaseSpec{
+				Outputs: []CoinbaseOutput{{Weight: 1}},
+			},
+			valid: false,
+		},
+		{
+			name: "output without weight or amount",
+			spec: CoinbaseSpec{
+				Outputs: []CoinbaseOutput{
+					{PkScript: script, Weight: 1},
+					{PkScript: script},
+				},
+			},
+			valid: false,
+		},
+		{
+			name: "only fixed outputs",
+			spec: CoinbaseSpec{
+				Outputs: []CoinbaseOutput{
+					{PkScript: script, Amount: 1000},
+				},
+			},
+			valid: false,
+		},
+		{
+			name: "flags too long",
+			spec: CoinbaseSpec{
+				Outputs: []CoinbaseOutput{
+					{PkScript: script, Weight: 1},
+				},
+				Flags: string(make([]byte, MaxCoinbaseFlagsLen+1)),
+			},
+			valid: false,
+		},
+		{
+			name: "commitment too long",
+			spec: CoinbaseSpec{
+				Outputs: []CoinbaseOutput{
+					{PkScript: script, Weight: 1},
+				},
+				Commitments: [][]byte{
+					make([]byte, MaxCoinbaseCommitmentLen+1),
+				},
+			},
+			valid: false,
+		},
+	}
+
+	for _, test := range tests {
+		err := test.spec.Validate()
+		if test.valid && err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%s: did not receive expected error", test.name)
+		}
+	}
+}
+
+// TestCoinbaseSpecOutputValues ensures the coinbase value is split between the
+// outputs of a coinbase spec as expected.
+func TestCoinbaseSpecOutputValues(t *testing.T) {
+	t.Parallel()
+
+	script := []byte{txscript.OP_TRUE}
+	tests := []struct {
+		name    string
+		outputs []CoinbaseOutput
+		total   int64
+		want    []int64
+		wantErr bool
+	}{
+		{
+			name:    "single output",
+			outputs: []CoinbaseOutput{{PkScript: script, Weight: 1}},
+			total:   5000000000,
+			want:    []int64{5000000000},
+		},
+		{
+			name: "weighted outputs with remainder",
+			outputs: []CoinbaseOutput{
+				{PkScript: script, Weight: 1},
+				{PkScript: script, Weight: 1},
+				{PkScript: script, Weight: 1},
+			},
+			total: 100,
+			want:  []int64{34, 33, 33},
+		},
+		{
+			name: "fixed and weighted outputs",
+			outputs: []CoinbaseOutput{
+				{PkScript: script, Amount: 1000},
+				{PkScript: script, Weight: 3},
+				{PkScript: script, Weight: 1},
+			},
+			total: 5000,
+			want:  []int64{1000, 3000, 1000},
+		},
+		{
+			name: "large weights",
+			outputs: []CoinbaseOutput{
+				{PkScript: script, Weight: 0xffffffff},
+				{PkScript: script, Weight: 0xffffffff},
+			},
+			total: 8400000000000000,
+			want:  []int64{4200000000000000, 4200000000000000},
+		},
+		{
+			name: "fixed amounts exceed total",
+			outputs: []CoinbaseOutput{
+				{PkScript: script, Amount: 6000},
+				{PkScript: script, Weight: 1},
+			},
+			total:   5000,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		spec := CoinbaseSpec{Outputs: test.outputs}
+		values, err := spec.outputValues(test.total)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("%s: did not receive expected error",
+					test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(values, test.want) {
+			t.Errorf("%s: mismatched values - got %v, want %v",
+				test.name, values, test.want)
+		}
+	}
+}
+
+// TestCoinbaseSpecTxOuts ensures a coinbase transaction created from a spec has
+// the expected payment and commitment outputs.
+func TestCoinbaseSpecTxOuts(t *testing.T) {
+	t.Parallel()
+
+	spec := CoinbaseSpec{
+		Outputs: []CoinbaseOutput{
+			{PkScript: []byte{txscript.OP_TRUE}, Weight: 1},
+			{PkScript: []byte{txscript.OP_TRUE}, Weight: 1},
+		},
+		Commitments: [][]byte{{0xde, 0xad, 0xbe, 0xef}},
+	}
+	txOuts, err := spec.txOuts()
+	if err != nil {
+		t.Fatalf("txOuts: unexpected error: %v", err)
+	}
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.TxOut = txOuts
+	if err := spec.setCoinbaseValue(tx, 101); err != nil {
+		t.Fatalf("setCoinbaseValue: unexpected error: %v", err)
+	}
+
+	if len(tx.TxOut) != 3 {
+		t.Fatalf("unexpected number of outputs - got %d, want 3",
+			len(tx.TxOut))
+	}
+	if tx.TxOut[0].Value != 51 || tx.TxOut[1].Value != 50 {
+		t.Fatalf("unexpected output values - got %d and %d, want 51 "+
+			"and 50", tx.TxOut[0].Value, tx.TxOut[1].Value)
+	}
+	commitment := tx.TxOut[2]
+	wantScript := []byte{txscript.OP_RETURN, txscript.OP_DATA_4, 0xde,
+		0xad, 0xbe, 0xef}
+	if commitment.Value != 0 || !bytes.Equal(commitment.PkScript, wantScript) {
+		t.Fatalf("unexpected commitment output - got %d %x, want 0 %x",
+			commitment.Value, commitment.PkScript, wantScript)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"math/rand"
 	"runtime"
+	"sort"
 	"sync"
 	"time"
 
@@ -24,9 +25,15 @@ const (
 	// maxNonce is the maximum value a nonce can be in a block header.
 	maxNonce = ^uint32(0) // 2^32 - 1
 
-	// maxExtraNonce is the maximum value an extra nonce used in a coinbase
-	// transaction can be.
-	maxExtraNonce = ^uint64(0) // 2^64 - 1
+	// maxWorkerExtraNonce is the maximum value of the part of the extra
+	// nonce used in a coinbase transaction a worker iterates.
+	maxWorkerExtraNonce = ^uint32(0) // 2^32 - 1
+
+	// workerExtraNonceShift is the number of bits the ID of a worker is
+	// shifted by to form the high bits of the extra nonces it uses.  This
+	// splits the extra nonce space between the workers deterministically,
+	// so no two workers ever search the same block header.
+	workerExtraNonceShift = 32
 
 	// hpsUpdateSecs is the number of seconds to wait in between each
 	// update to the hashes per second monitor.
@@ -83,6 +90,68 @@ type Config struct {
 	IsCurrent func() bool
 }
 
+// WorkerHashRate describes the recent hashing performance of a single worker
+// of the CPU miner.
+type WorkerHashRate struct {
+	// ID identifies the worker.  The IDs of the running workers are
+	// consecutive and start at zero.
+	ID uint32
+
+	// HashesPerSec is the number of hashes per second the worker recently
+	// performed.
+	HashesPerSec float64
+}
+
+// workerHashRates implements sort.Interface to allow a slice of worker hash
+// rates to be sorted by worker ID.
+type workerHashRates []WorkerHashRate
+
+// Len returns the number of worker hash rates in the slice.  It is part of the
+// sort.Interface implementation.
+func (s workerHashRates) Len() int { return len(s) }
+
+// Swap swaps the worker hash rates at the passed indices.  It is part of the
+// sort.Interface implementation.
+func (s workerHashRates) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+
+// Less returns whether the worker hash rate with index i should sort before
+// the one with index j.  It is part of the sort.Interface implementation.
+func (s workerHashRates) Less(i, j int) bool { return s[i].ID < s[j].ID }
+
+// hashUpdate is sent by workers to the speed monitor with the number of hashes
+// they performed since their last update.
+type hashUpdate struct {
+	workerID  uint32
+	workerGen uint64
+	hashes    uint64
+
+	// exited is set by a worker when it stops, so its hash rate is no
+	// longer reported.
+	exited bool
+}
+
+// worker houses the state of a single goroutine solving blocks.  Each worker
+// searches the part of the extra nonce space identified by its ID and reuses
+// its own hashing state for every hash.
+//
+// The ID of a stopped worker is reused by the next worker launched, so each
+// worker also has a generation, which increases with every worker launched,
+// to tell the updates of the two apart.
+type worker struct {
+	id     uint32
+	gen    uint64
+	hasher *powHasher
+}
+
+// newWorker returns a new worker with the passed ID and generation.
+func newWorker(id uint32, gen uint64) *worker {
+	return &worker{
+		id:     id,
+		gen:    gen,
+		hasher: newPowHasher(),
+	}
+}
+
 // CPUMiner provides facilities for solving blocks (mining) using the CPU in
 // a concurrency-safe manner.  It consists of two main goroutines -- a speed
 // monitor and a controller for worker goroutines which generate and solve
@@ -101,18 +170,23 @@ type CPUMiner struct {
 	workerWg          sync.WaitGroup
 	updateNumWorkers  chan struct{}
 	queryHashesPerSec chan float64
-	updateHashes      chan uint64
+	queryWorkerRates  chan []WorkerHashRate
+	updateHashes      chan hashUpdate
 	speedMonitorQuit  chan struct{}
 	quit              chan struct{}
 }
 
 // speedMonitor handles tracking the number of hashes per second the mining
-// process is performing.  It must be run as a goroutine.
+// process and each of its workers is performing.  It must be run as a
+// goroutine.
 func (m *CPUMiner) speedMonitor() {
 	log.Tracef("CPU miner speed monitor started")
 
 	var hashesPerSec float64
-	var totalHashes uint64
+	var workerRates []WorkerHashRate
+	workerHashesPerSec := make(map[uint32]float64)
+	workerHashes := make(map[uint32]uint64)
+	workerGens := make(map[uint32]uint64)
 	ticker := time.NewTicker(time.Second * hpsUpdateSecs)
 	defer ticker.Stop()
 
@@ -121,26 +195,65 @@ out:
 		select {
 		// Periodic updates from the workers with how many hashes they
 		// have performed.
-		case numHashes := <-m.updateHashes:
-			totalHashes += numHashes
+		case update := <-m.updateHashes:
+			// Ignore the updates of a worker which has been
+			// replaced by a newer one with the same ID, and start
+			// over the hash rate of an ID taken over by a newer
+			// worker.
+			id := update.workerID
+			gen, ok := workerGens[id]
+			if ok && update.workerGen < gen {
+				continue
+			}
+			if !ok || update.workerGen > gen {
+				workerGens[id] = update.workerGen
+				delete(workerHashes, id)
+				delete(workerHashesPerSec, id)
+			}
+
+			if update.exited {
+				delete(workerGens, id)
+				delete(workerHashes, id)
+				delete(workerHashesPerSec, id)
+				continue
+			}
+			workerHashes[id] += update.hashes
 
 		// Time to update the hashes per second.
 		case <-ticker.C:
-			curHashesPerSec := float64(totalHashes) / hpsUpdateSecs
-			if hashesPerSec == 0 {
-				hashesPerSec = curHashesPerSec
+			hashesPerSec = 0
+			workerRates = make([]WorkerHashRate, 0, len(workerHashes))
+			for id, numHashes := range workerHashes {
+				curHashesPerSec := float64(numHashes) / hpsUpdateSecs
+				rate, ok := workerHashesPerSec[id]
+				if !ok {
+					rate = curHashesPerSec
+				}
+				rate = (rate + curHashesPerSec) / 2
+				workerHashesPerSec[id] = rate
+				workerHashes[id] = 0
+
+				hashesPerSec += rate
+				workerRates = append(workerRates, WorkerHashRate{
+					ID:           id,
+					HashesPerSec: rate,
+				})
 			}
-			hashesPerSec = (hashesPerSec + curHashesPerSec) / 2
-			totalHashes = 0
+			sort.Sort(workerHashRates(workerRates))
 			if hashesPerSec != 0 {
-				log.Debugf("Hash speed: %6.0f kilohashes/s",
-					hashesPerSec/1000)
+				log.Debugf("Hash speed: %6.0f kilohashes/s "+
+					"(%d workers)", hashesPerSec/1000,
+					len(workerRates))
 			}
 
 		// Request for the number of hashes per second.
 		case m.queryHashesPerSec <- hashesPerSec:
 			// Nothing to do.
 
+		// Request for the number of hashes per second of each worker.
+		case m.queryWorkerRates <- workerRates:
+			// Nothing to do.
+
 		case <-m.speedMonitorQuit:
 			break out
 		}
@@ -151,8 +264,9 @@ out:
 }
 
 // submitBlock submits the passed block to network after ensuring it passes all
-// of the consensus validation rules.
-func (m *CPUMiner) submitBlock(block *vtcutil.Block) bool {
+// of the consensus validation rules.  An error is returned when the block is
+// not accepted.
+func (m *CPUMiner) submitBlock(block *vtcutil.Block) error {
 	m.submitBlockLock.Lock()
 	defer m.submitBlockLock.Unlock()
 
@@ -165,7 +279,7 @@ func (m *CPUMiner) submitBlock(block *vtcutil.Block) bool {
 	if !msgBlock.Header.PrevBlock.IsEqual(&m.g.BestSnapshot().Hash) {
 		log.Debugf("Block submitted via CPU miner with previous "+
 			"block %s is stale", msgBlock.Header.PrevBlock)
-		return false
+		return errors.New("block is stale")
 	}
 
 	// Process this block using the same rules as blocks coming from other
@@ -177,22 +291,22 @@ func (m *CPUMiner) submitBlock(block *vtcutil.Block) bool {
 		if _, ok := err.(blockchain.RuleError); !ok {
 			log.Errorf("Unexpected error while processing "+
 				"block submitted via CPU miner: %v", err)
-			return false
+			return err
 		}
 
 		log.Debugf("Block submitted via CPU miner rejected: %v", err)
-		return false
+		return err
 	}
 	if isOrphan {
 		log.Debugf("Block submitted via CPU miner is an orphan")
-		return false
+		return errors.New("block is an orphan")
 	}
 
 	// The block was accepted.
 	coinbaseTx := block.MsgBlock().Transactions[0].TxOut[0]
 	log.Infof("Block submitted via CPU miner accepted (hash %s, "+
 		"amount %v)", block.Hash(), vtcutil.Amount(coinbaseTx.Value))
-	return true
+	return nil
 }
 
 // solveBlock attempts to find some combination of a nonce, extra nonce, and
@@ -204,17 +318,13 @@ func (m *CPUMiner) submitBlock(block *vtcutil.Block) bool {
 // This function will return early with false when conditions that trigger a
 // stale block such as a new block showing up or periodically when there are
 // new transactions and enough time has elapsed without finding a solution.
-func (m *CPUMiner) solveBlock(msgBlock *wire.MsgBlock, blockHeight int32,
-	ticker *time.Ticker, quit chan struct{}) bool {
+func (m *CPUMiner) solveBlock(w *worker, msgBlock *wire.MsgBlock,
+	blockHeight int32, ticker *time.Ticker, quit chan struct{}) bool {
 
-	// Choose a random extra nonce offset for this block template and
-	// worker.
-	enOffset, err := wire.RandomUint64()
-	if err != nil {
-		log.Errorf("Unexpected error while generating random "+
-			"extra nonce offset: %v", err)
-		enOffset = 0
-	}
+	// The worker only uses the extra nonces with its ID in the high bits,
+	// so the workers never search the same block headers even when their
+	// block templates are otherwise identical.
+	enBase := uint64(w.id) << workerExtraNonceShift
 
 	// Create some convenience variables.
 	header := &msgBlock.Header
@@ -225,25 +335,30 @@ func (m *CPUMiner) solveBlock(msgBlock *wire.MsgBlock, blockHeight int32,
 	lastTxUpdate := m.g.TxSource().LastUpdated()
 	hashesCompleted := uint64(0)
 
-	// Note that the entire extra nonce range is iterated and the offset is
-	// added relying on the fact that overflow will wrap around 0 as
-	// provided by the Go spec.
-	for extraNonce := uint64(0); extraNonce < maxExtraNonce; extraNonce++ {
+	// Note that the entire extra nonce range of the worker is iterated,
+	// which is far more than can be searched before the block template is
+	// stale.
+	for extraNonce := uint32(0); ; extraNonce++ {
 		// Update the extra nonce in the block template with the
 		// new value by regenerating the coinbase script and
 		// setting the merkle root to the new value.
-		m.g.UpdateExtraNonce(msgBlock, blockHeight, extraNonce+enOffset)
+		m.g.UpdateExtraNonce(msgBlock, blockHeight,
+			enBase|uint64(extraNonce))
 
 		// Search through the entire nonce range for a solution while
 		// periodically checking for early quit and stale block
 		// conditions along with updates to the speed monitor.
-		for i := uint32(0); i <= maxNonce; i++ {
+		for i := uint32(0); ; i++ {
 			select {
 			case <-quit:
 				return false
 
 			case <-ticker.C:
-				m.updateHashes <- hashesCompleted
+				m.updateHashes <- hashUpdate{
+					workerID:  w.id,
+					workerGen: w.gen,
+					hashes:    hashesCompleted,
+				}
 				hashesCompleted = 0
 
 				// The current block is stale if the best block
@@ -269,23 +384,35 @@ func (m *CPUMiner) solveBlock(msgBlock *wire.MsgBlock, blockHeight int32,
 				// Non-blocking select to fall through
 			}
 
-			// Update the nonce and hash the block header.  Each
-			// hash is actually a double sha256 (two hashes), so
-			// increment the number of hashes completed for each
-			// attempt accordingly.
+			// Update the nonce and hash the block header with the
+			// hashing state of the worker.
 			header.Nonce = i
-			hash, err := header.PowHash()
+			hash, err := w.hasher.PowHash(header)
 			if err != nil {
 				return false
 			}
-			hashesCompleted += 1
+			hashesCompleted++
 
 			// The block is solved when the new block hash is less
 			// than the target difficulty.  Yay!
 			if blockchain.HashToBig(hash).Cmp(targetDifficulty) <= 0 {
-				m.updateHashes <- hashesCompleted
+				m.updateHashes <- hashUpdate{
+					workerID:  w.id,
+					workerGen: w.gen,
+					hashes:    hashesCompleted,
+				}
 				return true
 			}
+
+			// Move on to the next extra nonce once the entire
+			// nonce range was searched.
+			if i == maxNonce {
+				break
+			}
+		}
+
+		if extraNonce == maxWorkerExtraNonce {
+			break
 		}
 	}
 
@@ -299,8 +426,8 @@ func (m *CPUMiner) solveBlock(msgBlock *wire.MsgBlock, blockHeight int32,
 // is submitted.
 //
 // It must be run as a goroutine.
-func (m *CPUMiner) generateBlocks(quit chan struct{}) {
-	log.Tracef("Starting generate blocks worker")
+func (m *CPUMiner) generateBlocks(w *worker, quit chan struct{}) {
+	log.Tracef("Starting generate blocks worker %d", w.id)
 
 	// Start a ticker which is used to signal checks for stale work and
 	// updates to the speed monitor.
@@ -357,14 +484,21 @@ out:
 		// with false when conditions that trigger a stale block, so
 		// a new block template can be generated.  When the return is
 		// true a solution was found, so submit the solved block.
-		if m.solveBlock(template.Block, curHeight+1, ticker, quit) {
+		if m.solveBlock(w, template.Block, curHeight+1, ticker, quit) {
 			block := vtcutil.NewBlock(template.Block)
 			m.submitBlock(block)
 		}
 	}
 
+	// Stop reporting the hash rate of the worker.
+	m.updateHashes <- hashUpdate{
+		workerID:  w.id,
+		workerGen: w.gen,
+		exited:    true,
+	}
+
 	m.workerWg.Done()
-	log.Tracef("Generate blocks worker done")
+	log.Tracef("Generate blocks worker %d done", w.id)
 }
 
 // miningWorkerController launches the worker goroutines that are used to
@@ -374,15 +508,19 @@ out:
 // It must be run as a goroutine.
 func (m *CPUMiner) miningWorkerController() {
 	// launchWorkers groups common code to launch a specified number of
-	// workers for generating blocks.
+	// workers for generating blocks.  The ID of each worker is its index
+	// in the running workers, so the IDs are always consecutive.
 	var runningWorkers []chan struct{}
+	var nextGen uint64
 	launchWorkers := func(numWorkers uint32) {
 		for i := uint32(0); i < numWorkers; i++ {
+			w := newWorker(uint32(len(runningWorkers)), nextGen)
+			nextGen++
 			quit := make(chan struct{})
 			runningWorkers = append(runningWorkers, quit)
 
 			m.workerWg.Add(1)
-			go m.generateBlocks(quit)
+			go m.generateBlocks(w, quit)
 		}
 	}
 
@@ -502,6 +640,23 @@ func (m *CPUMiner) HashesPerSecond() float64 {
 	return <-m.queryHashesPerSec
 }
 
+// WorkerHashesPerSecond returns the number of hashes per second each worker of
+// the mining process is performing sorted by worker ID.  Nil is returned if the
+// miner is not currently running.
+//
+// This function is safe for concurrent access.
+func (m *CPUMiner) WorkerHashesPerSecond() []WorkerHashRate {
+	m.Lock()
+	defer m.Unlock()
+
+	// Nothing to do if the miner is not currently running.
+	if !m.started {
+		return nil
+	}
+
+	return <-m.queryWorkerRates
+}
+
 // SetNumWorkers sets the number of workers to create which solve blocks.  Any
 // negative values will cause a default number of workers to be used which is
 // based on the number of processor cores in the system.  A value of 0 will
@@ -542,18 +697,16 @@ func (m *CPUMiner) NumWorkers() int32 {
 	return int32(m.numWorkers)
 }
 
-// GenerateNBlocks generates the requested number of blocks. It is self
-// contained in that it creates block templates and attempts to solve them while
-// detecting when it is performing stale work and reacting accordingly by
-// generating a new block template.  When a block is solved, it is submitted.
-// The function returns a list of the hashes of generated blocks.
-func (m *CPUMiner) GenerateNBlocks(n uint32) ([]*chainhash.Hash, error) {
+// startDiscreteMining marks the miner as running for a discrete number of
+// blocks and starts the speed monitor the block solver reports to.  An error
+// is returned when the miner is already running.
+func (m *CPUMiner) startDiscreteMining() error {
 	m.Lock()
+	defer m.Unlock()
 
 	// Respond with an error if server is already mining.
 	if m.started || m.discreteMining {
-		m.Unlock()
-		return nil, errors.New("Server is already CPU mining. Please call " +
+		return errors.New("Server is already CPU mining. Please call " +
 			"`setgenerate 0` before calling discrete `generate` commands.")
 	}
 
@@ -563,11 +716,50 @@ func (m *CPUMiner) GenerateNBlocks(n uint32) ([]*chainhash.Hash, error) {
 	m.speedMonitorQuit = make(chan struct{})
 	m.wg.Add(1)
 	go m.speedMonitor()
+	return nil
+}
 
+// stopDiscreteMining stops the speed monitor started by startDiscreteMining
+// and marks the miner as no longer running.
+func (m *CPUMiner) stopDiscreteMining() {
+	m.Lock()
+	close(m.speedMonitorQuit)
+	m.wg.Wait()
+	m.started = false
+	m.discreteMining = false
 	m.Unlock()
+}
+
+// GenerateNBlocks generates the requested number of blocks. It is self
+// contained in that it creates block templates and attempts to solve them while
+// detecting when it is performing stale work and reacting accordingly by
+// generating a new block template.  When a block is solved, it is submitted.
+// The function returns a list of the hashes of generated blocks.
+func (m *CPUMiner) GenerateNBlocks(n uint32) ([]*chainhash.Hash, error) {
+	if len(m.cfg.MiningAddrs) == 0 {
+		return nil, errors.New("no mining addresses")
+	}
+	return m.generateNBlocks(n, m.cfg.MiningAddrs)
+}
+
+// GenerateNBlocksToAddress generates the requested number of blocks like
+// GenerateNBlocks, except the coinbase of every block pays to the passed
+// address instead of one of the configured mining addresses.
+func (m *CPUMiner) GenerateNBlocksToAddress(n uint32, payToAddr vtcutil.Address) ([]*chainhash.Hash, error) {
+	return m.generateNBlocks(n, []vtcutil.Address{payToAddr})
+}
+
+// generateNBlocks generates the requested number of blocks paying to addresses
+// chosen at random from the passed list.
+func (m *CPUMiner) generateNBlocks(n uint32, payToAddrs []vtcutil.Address) ([]*chainhash.Hash, error) {
+	if err := m.startDiscreteMining(); err != nil {
+		return nil, err
+	}
 
 	log.Tracef("Generating %d blocks", n)
 
+	// Discrete mining only uses a single worker.
+	w := newWorker(0, 0)
 	i := uint32(0)
 	blockHashes := make([]*chainhash.Hash, n)
 
@@ -593,7 +785,7 @@ func (m *CPUMiner) GenerateNBlocks(n uint32) ([]*chainhash.Hash, error) {
 
 		// Choose a payment address at random.
 		rand.Seed(time.Now().UnixNano())
-		payToAddr := m.cfg.MiningAddrs[rand.Intn(len(m.cfg.MiningAddrs))]
+		payToAddr := payToAddrs[rand.Intn(len(payToAddrs))]
 
 		// Create a new block template using the available transactions
 		// in the memory pool as a source of transactions to potentially
@@ -611,25 +803,55 @@ func (m *CPUMiner) GenerateNBlocks(n uint32) ([]*chainhash.Hash, error) {
 		// with false when conditions that trigger a stale block, so
 		// a new block template can be generated.  When the return is
 		// true a solution was found, so submit the solved block.
-		if m.solveBlock(template.Block, curHeight+1, ticker, nil) {
+		if m.solveBlock(w, template.Block, curHeight+1, ticker, nil) {
 			block := vtcutil.NewBlock(template.Block)
 			m.submitBlock(block)
 			blockHashes[i] = block.Hash()
 			i++
 			if i == n {
 				log.Tracef("Generated %d blocks", i)
-				m.Lock()
-				close(m.speedMonitorQuit)
-				m.wg.Wait()
-				m.started = false
-				m.discreteMining = false
-				m.Unlock()
+				m.stopDiscreteMining()
 				return blockHashes, nil
 			}
 		}
 	}
 }
 
+// GenerateBlock generates a single block which contains exactly the passed
+// transactions in the passed order and pays the coinbase to the passed address.
+// Unlike GenerateNBlocks, no new block template is created when the chain tip
+// changes while solving the block, so an error is returned instead.  The hash
+// of the block is returned once it was accepted by the chain.
+func (m *CPUMiner) GenerateBlock(payToAddr vtcutil.Address, txns []*vtcutil.Tx) (*chainhash.Hash, error) {
+	if err := m.startDiscreteMining(); err != nil {
+		return nil, err
+	}
+	defer m.stopDiscreteMining()
+
+	ticker := time.NewTicker(time.Second * hashUpdateSecs)
+	defer ticker.Stop()
+
+	m.submitBlockLock.Lock()
+	curHeight := m.g.BestSnapshot().Height
+	template, err := m.g.NewBlockTemplateWithTxs(payToAddr, txns)
+	m.submitBlockLock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	if !m.solveBlock(newWorker(0, 0), template.Block, curHeight+1, ticker,
+		nil) {
+
+		return nil, errors.New("the chain tip changed while solving " +
+			"the block")
+	}
+	block := vtcutil.NewBlock(template.Block)
+	if err := m.submitBlock(block); err != nil {
+		return nil, err
+	}
+	return block.Hash(), nil
+}
+
 // New returns a new instance of a CPU miner for the provided configuration.
 // Use Start to begin the mining process.  See the documentation for CPUMiner
 // type for more details.
@@ -640,6 +862,7 @@ func New(cfg *Config) *CPUMiner {
 		numWorkers:        defaultNumWorkers,
 		updateNumWorkers:  make(chan struct{}),
 		queryHashesPerSec: make(chan float64),
-		updateHashes:      make(chan uint64),
+		queryWorkerRates:  make(chan []WorkerHashRate),
+		updateHashes:      make(chan hashUpdate),
 	}
 }
@@ -0,0 +1,148 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package cpuminer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+
+	"github.com/vertcoin/vtcd/chaincfg/chainhash"
+	"github.com/vertcoin/vtcd/wire"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// scryptN is the CPU/memory cost parameter of the scrypt proof of work.
+	scryptN = 1024
+
+	// scryptBlockWords is the number of 32-bit words in a scrypt block for
+	// the block size parameter r = 1 used by the proof of work.
+	scryptBlockWords = 32
+)
+
+// powHasher calculates the proof of work hash of block headers the same way
+// as wire.BlockHeader.PowHash.  Unlike PowHash, it reuses the memory needed
+// by scrypt between hashes, so each worker owns a hasher and hashes the
+// headers it searches with it.  A hasher is not safe for concurrent access.
+//
+// The hasher must match the proof of work consensus validates, so it doesn't
+// implement the Vertcoin algorithms Lyra2REv3 and Verthash, which consensus
+// doesn't support yet.
+type powHasher struct {
+	buf bytes.Buffer
+	x   [scryptBlockWords]uint32
+	v   [scryptN * scryptBlockWords]uint32
+	b   [scryptBlockWords * 4]byte
+}
+
+// newPowHasher returns a new proof of work hasher.
+func newPowHasher() *powHasher {
+	h := &powHasher{}
+	h.buf.Grow(wire.MaxBlockHeaderPayload)
+	return h
+}
+
+// PowHash returns the scrypt hash of the passed block header.  The result is
+// identical to the one of the PowHash method of the header.
+func (h *powHasher) PowHash(header *wire.BlockHeader) (*chainhash.Hash, error) {
+	h.buf.Reset()
+	if err := header.Serialize(&h.buf); err != nil {
+		return nil, err
+	}
+	data := h.buf.Bytes()
+
+	// Scrypt with N = 1024, r = 1 and p = 1 using the serialized header as
+	// both the password and the salt.
+	b := pbkdf2.Key(data, data, 1, len(h.b), sha256.New)
+	for i := range h.x {
+		h.x[i] = binary.LittleEndian.Uint32(b[i*4:])
+	}
+	for i := 0; i < scryptN; i++ {
+		copy(h.v[i*scryptBlockWords:], h.x[:])
+		blockMix(&h.x)
+	}
+	for i := 0; i < scryptN; i++ {
+		j := int(h.x[scryptBlockWords-16] & (scryptN - 1))
+		v := h.v[j*scryptBlockWords : (j+1)*scryptBlockWords]
+		for k := range h.x {
+			h.x[k] ^= v[k]
+		}
+		blockMix(&h.x)
+	}
+	for i, w := range h.x {
+		binary.LittleEndian.PutUint32(h.b[i*4:], w)
+	}
+
+	var hash chainhash.Hash
+	copy(hash[:], pbkdf2.Key(data, h.b[:], 1, chainhash.HashSize,
+		sha256.New))
+	return &hash, nil
+}
+
+// blockMix performs the scrypt BlockMix operation with r = 1 on the passed
+// block in place.  With a single pair of Salsa20/8 blocks, the even and odd
+// outputs are already in the order BlockMix requires.
+func blockMix(b *[scryptBlockWords]uint32) {
+	var x [16]uint32
+	copy(x[:], b[16:])
+	for i := 0; i < 2; i++ {
+		for k := range x {
+			x[k] ^= b[i*16+k]
+		}
+		salsa208(&x)
+		copy(b[i*16:], x[:])
+	}
+}
+
+// salsa208 applies the Salsa20/8 core to the passed words in place.
+func salsa208(b *[16]uint32) {
+	x := *b
+	for i := 0; i < 8; i += 2 {
+		// Column round.
+		x[4] ^= rotl(x[0]+x[12], 7)
+		x[8] ^= rotl(x[4]+x[0], 9)
+		x[12] ^= rotl(x[8]+x[4], 13)
+		x[0] ^= rotl(x[12]+x[8], 18)
+		x[9] ^= rotl(x[5]+x[1], 7)
+		x[13] ^= rotl(x[9]+x[5], 9)
+		x[1] ^= rotl(x[13]+x[9], 13)
+		x[5] ^= rotl(x[1]+x[13], 18)
+		x[14] ^= rotl(x[10]+x[6], 7)
+		x[2] ^= rotl(x[14]+x[10], 9)
+		x[6] ^= rotl(x[2]+x[14], 13)
+		x[10] ^= rotl(x[6]+x[2], 18)
+		x[3] ^= rotl(x[15]+x[11], 7)
+		x[7] ^= rotl(x[3]+x[15], 9)
+		x[11] ^= rotl(x[7]+x[3], 13)
+		x[15] ^= rotl(x[11]+x[7], 18)
+
+		// Row round.
+		x[1] ^= rotl(x[0]+x[3], 7)
+		x[2] ^= rotl(x[1]+x[0], 9)
+		x[3] ^= rotl(x[2]+x[1], 13)
+		x[0] ^= rotl(x[3]+x[2], 18)
+		x[6] ^= rotl(x[5]+x[4], 7)
+		x[7] ^= rotl(x[6]+x[5], 9)
+		x[4] ^= rotl(x[7]+x[6], 13)
+		x[5] ^= rotl(x[4]+x[7], 18)
+		x[11] ^= rotl(x[10]+x[9], 7)
+		x[8] ^= rotl(x[11]+x[10], 9)
+		x[9] ^= rotl(x[8]+x[11], 13)
+		x[10] ^= rotl(x[9]+x[8], 18)
+		x[12] ^= rotl(x[15]+x[14], 7)
+		x[13] ^= rotl(x[12]+x[15], 9)
+		x[14] ^= rotl(x[13]+x[12], 13)
+		x[15] ^= rotl(x[14]+x[13], 18)
+	}
+	for i := range b {
+		b[i] += x[i]
+	}
+}
+
+// rotl rotates the passed word left by the passed number of bits.
+func rotl(w uint32, n uint) uint32 {
+	return w<<n | w>>(32-n)
+}
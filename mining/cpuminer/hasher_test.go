@@ -0,0 +1,76 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package cpuminer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vertcoin/vtcd/chaincfg"
+	"github.com/vertcoin/vtcd/chaincfg/chainhash"
+	"github.com/vertcoin/vtcd/wire"
+)
+
+// TestPowHasher ensures the proof of work hasher produces the same hashes as
+// the PowHash method of block headers while reusing its memory.
+func TestPowHasher(t *testing.T) {
+	t.Parallel()
+
+	headers := []wire.BlockHeader{
+		chaincfg.RegressionNetParams.GenesisBlock.Header,
+		{
+			Version:    0x20000002,
+			PrevBlock:  chainhash.Hash{0x01, 0x02, 0x03},
+			MerkleRoot: chainhash.Hash{0xff, 0xee},
+			Timestamp:  time.Unix(1530000000, 0),
+			Bits:       0x1e0ffff0,
+			Nonce:      0xdeadbeef,
+		},
+	}
+
+	hasher := newPowHasher()
+	for i := range headers {
+		header := &headers[i]
+		for nonce := uint32(0); nonce < 4; nonce++ {
+			header.Nonce += nonce
+			want, err := header.PowHash()
+			if err != nil {
+				t.Fatalf("PowHash: unexpected error: %v", err)
+			}
+			got, err := hasher.PowHash(header)
+			if err != nil {
+				t.Fatalf("powHasher.PowHash: unexpected error: %v",
+					err)
+			}
+			if *got != *want {
+				t.Fatalf("header %d nonce %d: mismatched hash - "+
+					"got %v, want %v", i, header.Nonce, got, want)
+			}
+		}
+	}
+}
+
+// BenchmarkPowHasher benchmarks hashing block headers with a reused proof of
+// work hasher.
+func BenchmarkPowHasher(b *testing.B) {
+	header := chaincfg.RegressionNetParams.GenesisBlock.Header
+	hasher := newPowHasher()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		header.Nonce = uint32(i)
+		hasher.PowHash(&header)
+	}
+}
+
+// BenchmarkHeaderPowHash benchmarks hashing block headers with the PowHash
+// method of the header for comparison.
+func BenchmarkHeaderPowHash(b *testing.B) {
+	header := chaincfg.RegressionNetParams.GenesisBlock.Header
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		header.Nonce = uint32(i)
+		header.PowHash()
+	}
+}
@@ -51,6 +51,12 @@ type TxDesc struct {
 
 	// FeePerKB is the fee the transaction pays in Satoshi per 1000 bytes.
 	FeePerKB int64
+
+	// FeeDelta is the amount added to the fee of the transaction when
+	// choosing which transactions make it into a block.  It is set via the
+	// prioritisetransaction RPC and does not change the fees the block
+	// collects.
+	FeeDelta int64
 }
 
 // TxSource represents a source of transactions to consider for inclusion in
@@ -214,8 +220,7 @@ type BlockTemplate struct {
 
 	// WitnessCommitment is a commitment to the witness data (if any)
 	// within the block. This field will only be populted once segregated
-	// witness has been activated, and the block contains a transaction
-	// which has witness data.
+	// witness has been activated.
 	WitnessCommitment []byte
 }
 
@@ -237,20 +242,20 @@ func mergeUtxoView(viewA *blockchain.UtxoViewpoint, viewB *blockchain.UtxoViewpo
 // standardCoinbaseScript returns a standard script suitable for use as the
 // signature script of the coinbase transaction of a new block.  In particular,
 // it starts with the block height that is required by version 2 blocks and adds
-// the extra nonce as well as additional coinbase flags.
-func standardCoinbaseScript(nextBlockHeight int32, extraNonce uint64) ([]byte, error) {
+// the extra nonce as well as the passed coinbase flags.
+func standardCoinbaseScript(nextBlockHeight int32, extraNonce uint64, flags string) ([]byte, error) {
 	return txscript.NewScriptBuilder().AddInt64(int64(nextBlockHeight)).
-		AddInt64(int64(extraNonce)).AddData([]byte(CoinbaseFlags)).
+		AddInt64(int64(extraNonce)).AddData([]byte(flags)).
 		Script()
 }
 
-// createCoinbaseTx returns a coinbase transaction paying an appropriate subsidy
-// based on the passed block height to the provided address.  When the address
-// is nil, the coinbase transaction will instead be redeemable by anyone.
+// addrCoinbaseSpec returns a coinbase spec which pays the whole coinbase value
+// to the provided address.  When the address is nil, the coinbase transaction
+// will instead be redeemable by anyone.
 //
 // See the comment for NewBlockTemplate for more information about why the nil
 // address handling is useful.
-func createCoinbaseTx(params *chaincfg.Params, coinbaseScript []byte, nextBlockHeight int32, addr vtcutil.Address) (*vtcutil.Tx, error) {
+func addrCoinbaseSpec(addr vtcutil.Address) (*CoinbaseSpec, error) {
 	// Create the script to pay to the provided payment address if one was
 	// specified.  Otherwise create a script that allows the coinbase to be
 	// redeemable by anyone.
@@ -270,6 +275,20 @@ func createCoinbaseTx(params *chaincfg.Params, coinbaseScript []byte, nextBlockH
 		}
 	}
 
+	return &CoinbaseSpec{
+		Outputs: []CoinbaseOutput{{PkScript: pkScript, Weight: 1}},
+	}, nil
+}
+
+// createCoinbaseTx returns a coinbase transaction with the outputs described
+// by the passed coinbase spec paying an appropriate subsidy based on the passed
+// block height.
+func createCoinbaseTx(params *chaincfg.Params, coinbaseScript []byte, nextBlockHeight int32, spec *CoinbaseSpec) (*vtcutil.Tx, error) {
+	txOuts, err := spec.txOuts()
+	if err != nil {
+		return nil, err
+	}
+
 	tx := wire.NewMsgTx(wire.TxVersion)
 	tx.AddTxIn(&wire.TxIn{
 		// Coinbase transactions have no inputs, so previous outpoint is
@@ -279,10 +298,12 @@ func createCoinbaseTx(params *chaincfg.Params, coinbaseScript []byte, nextBlockH
 		SignatureScript: coinbaseScript,
 		Sequence:        wire.MaxTxInSequenceNum,
 	})
-	tx.AddTxOut(&wire.TxOut{
-		Value:    blockchain.CalcBlockSubsidy(nextBlockHeight, params),
-		PkScript: pkScript,
-	})
+	tx.TxOut = txOuts
+
+	// The fixed amounts of the spec may only be covered once the fees are
+	// added, so errors are ignored until the final value is known.
+	spec.setCoinbaseValue(tx, blockchain.CalcBlockSubsidy(nextBlockHeight,
+		params))
 	return vtcutil.NewTx(tx), nil
 }
 
@@ -443,25 +464,52 @@ func NewBlkTmplGenerator(policy *Policy, params *chaincfg.Params,
 //  |  <= policy.BlockMinSize)          |   |
 //   -----------------------------------  --
 func (g *BlkTmplGenerator) NewBlockTemplate(payToAddress vtcutil.Address) (*BlockTemplate, error) {
+	spec, err := addrCoinbaseSpec(payToAddress)
+	if err != nil {
+		return nil, err
+	}
+	return g.newBlockTemplate(spec, payToAddress != nil)
+}
+
+// NewBlockTemplateWithCoinbase returns a new block template like
+// NewBlockTemplate, except the coinbase is created from the passed coinbase
+// spec.  This allows the coinbase value to be split between several outputs,
+// a custom tag to be added to the coinbase script and additional data to be
+// committed to in the coinbase as is required for cooperative mining.
+func (g *BlkTmplGenerator) NewBlockTemplateWithCoinbase(spec *CoinbaseSpec) (*BlockTemplate, error) {
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	return g.newBlockTemplate(spec, true)
+}
+
+// newBlockTemplate returns a new block template with a coinbase created from
+// the passed coinbase spec.  See NewBlockTemplate for details.
+func (g *BlkTmplGenerator) newBlockTemplate(spec *CoinbaseSpec, validPayAddress bool) (*BlockTemplate, error) {
 	// Extend the most recently known best block.
 	best := g.chain.BestSnapshot()
 	nextBlockHeight := best.Height + 1
 
-	// Create a standard coinbase transaction paying to the provided
-	// address.  NOTE: The coinbase value will be updated to include the
-	// fees from the selected transactions later after they have actually
-	// been selected.  It is created here to detect any errors early
-	// before potentially doing a lot of work below.  The extra nonce helps
-	// ensure the transaction is not a duplicate transaction (paying the
-	// same value to the same public key address would otherwise be an
+	// Create a standard coinbase transaction paying to the outputs of the
+	// provided spec.  NOTE: The coinbase value will be updated to include
+	// the fees from the selected transactions later after they have
+	// actually been selected.  It is created here to detect any errors
+	// early before potentially doing a lot of work below.  The extra nonce
+	// helps ensure the transaction is not a duplicate transaction (paying
+	// the same value to the same public key address would otherwise be an
 	// identical transaction for block version 1).
 	extraNonce := uint64(0)
-	coinbaseScript, err := standardCoinbaseScript(nextBlockHeight, extraNonce)
+	flags := spec.Flags
+	if flags == "" {
+		flags = g.CoinbaseFlags()
+	}
+	coinbaseScript, err := standardCoinbaseScript(nextBlockHeight,
+		extraNonce, flags)
 	if err != nil {
 		return nil, err
 	}
 	coinbaseTx, err := createCoinbaseTx(g.chainParams, coinbaseScript,
-		nextBlockHeight, payToAddress)
+		nextBlockHeight, spec)
 	if err != nil {
 		return nil, err
 	}
@@ -521,6 +569,15 @@ mempoolLoop:
 			log.Tracef("Skipping non-finalized tx %s", tx.Hash())
 			continue
 		}
+		if g.policy.CheckTxStandard != nil {
+			err := g.policy.CheckTxStandard(tx, nextBlockHeight,
+				best.MedianTime)
+			if err != nil {
+				log.Tracef("Skipping non-standard tx %s: %v",
+					tx.Hash(), err)
+				continue
+			}
+		}
 
 		// Fetch all of the utxos referenced by the this transaction.
 		// NOTE: This intentionally does not fetch inputs from the
@@ -578,8 +635,14 @@ mempoolLoop:
 		prioItem.priority = CalcPriority(tx.MsgTx(), utxos,
 			nextBlockHeight)
 
-		// Calculate the fee in Satoshi/kB.
+		// Calculate the fee in Satoshi/kB.  Transactions are ordered by
+		// their fee including any fee delta, while the block only
+		// collects the actual fee.
 		prioItem.feePerKB = txDesc.FeePerKB
+		if txDesc.FeeDelta != 0 {
+			prioItem.feePerKB = (txDesc.Fee + txDesc.FeeDelta) * 1000 /
+				int64(tx.MsgTx().SerializeSize())
+		}
 		prioItem.fee = txDesc.Fee
 
 		// Add the transaction to the priority queue to mark it ready
@@ -798,14 +861,167 @@ mempoolLoop:
 	blockWeight -= wire.MaxVarIntPayload -
 		(uint32(wire.VarIntSerializeSize(uint64(len(blockTxns)))) *
 			blockchain.WitnessScaleFactor)
-	coinbaseTx.MsgTx().TxOut[0].Value += totalFees
+	subsidy := blockchain.CalcBlockSubsidy(nextBlockHeight, g.chainParams)
+	err = spec.setCoinbaseValue(coinbaseTx.MsgTx(), subsidy+totalFees)
+	if err != nil {
+		return nil, err
+	}
 	txFees[0] = -totalFees
 
-	// If segwit is active and we included transactions with witness data,
-	// then we'll need to include a commitment to the witness data in an
-	// OP_RETURN output within the coinbase transaction.
+	template, err := g.assembleBlockTemplate(best, blockTxns, txFees,
+		txSigOpCosts, segwitActive)
+	if err != nil {
+		return nil, err
+	}
+	template.ValidPayAddress = validPayAddress
+	msgBlock := template.Block
+
+	log.Debugf("Created new block template (%d transactions, %d in "+
+		"fees, %d signature operations cost, %d weight, target difficulty "+
+		"%064x)", len(msgBlock.Transactions), totalFees, blockSigOpCost,
+		blockWeight, blockchain.CompactToBig(msgBlock.Header.Bits))
+
+	return template, nil
+}
+
+// NewBlockTemplateWithTxs returns a new block template which pays the coinbase
+// to the passed address like NewBlockTemplate, except it contains exactly the
+// passed transactions in the passed order instead of transactions selected
+// from the source pool.  The transactions don't need to be in the source pool
+// and the mining policy is not applied to them, but they must be valid in the
+// block, so transactions may only spend outputs of the transactions which
+// precede them.
+func (g *BlkTmplGenerator) NewBlockTemplateWithTxs(payToAddress vtcutil.Address, txns []*vtcutil.Tx) (*BlockTemplate, error) {
+	// Extend the most recently known best block.
+	best := g.chain.BestSnapshot()
+	nextBlockHeight := best.Height + 1
+
+	// Create a standard coinbase transaction paying to the provided
+	// address.  The coinbase value is updated once the fees of the passed
+	// transactions are known.
+	spec, err := addrCoinbaseSpec(payToAddress)
+	if err != nil {
+		return nil, err
+	}
+	coinbaseScript, err := standardCoinbaseScript(nextBlockHeight, 0,
+		g.CoinbaseFlags())
+	if err != nil {
+		return nil, err
+	}
+	coinbaseTx, err := createCoinbaseTx(g.chainParams, coinbaseScript,
+		nextBlockHeight, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	segwitState, err := g.chain.ThresholdState(chaincfg.DeploymentSegwit)
+	if err != nil {
+		return nil, err
+	}
+	segwitActive := segwitState == blockchain.ThresholdActive
+
+	blockTxns := make([]*vtcutil.Tx, 0, len(txns)+1)
+	blockTxns = append(blockTxns, coinbaseTx)
+	txFees := make([]int64, 0, len(txns)+1)
+	txFees = append(txFees, -1) // Updated once known
+	txSigOpCosts := make([]int64, 0, len(txns)+1)
+	txSigOpCosts = append(txSigOpCosts,
+		int64(blockchain.CountSigOps(coinbaseTx))*blockchain.WitnessScaleFactor)
+
+	// Check the inputs of each transaction against the chain and the
+	// transactions which precede it in the block to determine its fee and
+	// signature operation cost.
+	blockUtxos := blockchain.NewUtxoViewpoint()
+	blockWeight := int64(blockHeaderOverhead*blockchain.WitnessScaleFactor) +
+		blockchain.GetTransactionWeight(coinbaseTx)
+	blockSigOpCost := txSigOpCosts[0]
+	totalFees := int64(0)
+	for _, tx := range txns {
+		if blockchain.IsCoinBase(tx) {
+			return nil, fmt.Errorf("transaction %s is a coinbase",
+				tx.Hash())
+		}
+		if !segwitActive && tx.HasWitness() {
+			return nil, fmt.Errorf("transaction %s has witness "+
+				"data before segwit is active", tx.Hash())
+		}
+		if err := blockchain.CheckTransactionSanity(tx); err != nil {
+			return nil, err
+		}
+
+		utxos, err := g.chain.FetchUtxoView(tx)
+		if err != nil {
+			return nil, err
+		}
+		mergeUtxoView(blockUtxos, utxos)
+		fee, err := blockchain.CheckTransactionInputs(tx,
+			nextBlockHeight, blockUtxos, g.chainParams)
+		if err != nil {
+			return nil, err
+		}
+		sigOpCost, err := blockchain.GetSigOpCost(tx, false,
+			blockUtxos, true, segwitActive)
+		if err != nil {
+			return nil, err
+		}
+		spendTransaction(blockUtxos, tx, nextBlockHeight)
+
+		blockWeight += blockchain.GetTransactionWeight(tx)
+		if blockWeight > blockchain.MaxBlockWeight {
+			return nil, fmt.Errorf("block weight exceeds the "+
+				"maximum of %d", blockchain.MaxBlockWeight)
+		}
+		blockSigOpCost += int64(sigOpCost)
+		if blockSigOpCost > blockchain.MaxBlockSigOpsCost {
+			return nil, fmt.Errorf("block signature operations cost "+
+				"exceeds the maximum of %d",
+				blockchain.MaxBlockSigOpsCost)
+		}
+
+		blockTxns = append(blockTxns, tx)
+		totalFees += fee
+		txFees = append(txFees, fee)
+		txSigOpCosts = append(txSigOpCosts, int64(sigOpCost))
+	}
+
+	subsidy := blockchain.CalcBlockSubsidy(nextBlockHeight, g.chainParams)
+	err = spec.setCoinbaseValue(coinbaseTx.MsgTx(), subsidy+totalFees)
+	if err != nil {
+		return nil, err
+	}
+	txFees[0] = -totalFees
+
+	template, err := g.assembleBlockTemplate(best, blockTxns, txFees,
+		txSigOpCosts, segwitActive)
+	if err != nil {
+		return nil, err
+	}
+	template.ValidPayAddress = payToAddress != nil
+
+	log.Debugf("Created new block template with %d chosen transactions "+
+		"(%d in fees)", len(txns), totalFees)
+
+	return template, nil
+}
+
+// assembleBlockTemplate returns a block template which extends the passed best
+// block with the passed transactions, the first of which must be the coinbase
+// with its final value.  The witness commitment is added to the coinbase when
+// segwit is active and the block is checked against the consensus rules.
+func (g *BlkTmplGenerator) assembleBlockTemplate(best *blockchain.BestState,
+	blockTxns []*vtcutil.Tx, txFees, txSigOpCosts []int64,
+	segwitActive bool) (*BlockTemplate, error) {
+
+	nextBlockHeight := best.Height + 1
+	coinbaseTx := blockTxns[0]
+
+	// If segwit is active, then we'll need to include a commitment to the
+	// witness data in an OP_RETURN output within the coinbase transaction.
+	// The commitment is included even when no transactions with witness
+	// data were selected so getblocktemplate callers which add their own
+	// transactions always have one available.
 	var witnessCommitment []byte
-	if witnessIncluded {
+	if segwitActive {
 		// The witness of the coinbase transaction MUST be exactly 32-bytes
 		// of all zeroes.
 		var witnessNonce [blockchain.CoinbaseWitnessDataLen]byte
@@ -883,17 +1099,11 @@ mempoolLoop:
 		return nil, err
 	}
 
-	log.Debugf("Created new block template (%d transactions, %d in "+
-		"fees, %d signature operations cost, %d weight, target difficulty "+
-		"%064x)", len(msgBlock.Transactions), totalFees, blockSigOpCost,
-		blockWeight, blockchain.CompactToBig(msgBlock.Header.Bits))
-
 	return &BlockTemplate{
 		Block:             &msgBlock,
 		Fees:              txFees,
 		SigOpCosts:        txSigOpCosts,
 		Height:            nextBlockHeight,
-		ValidPayAddress:   payToAddress != nil,
 		WitnessCommitment: witnessCommitment,
 	}, nil
 }
@@ -925,10 +1135,15 @@ func (g *BlkTmplGenerator) UpdateBlockTime(msgBlock *wire.MsgBlock) error {
 
 // UpdateExtraNonce updates the extra nonce in the coinbase script of the passed
 // block by regenerating the coinbase script with the passed value and block
-// height.  It also recalculates and updates the new merkle root that results
-// from changing the coinbase script.
+// height.  The coinbase flags of the current script are kept, so the flags of
+// the coinbase spec or policy the block was created with are preserved.  It
+// also recalculates and updates the new merkle root that results from changing
+// the coinbase script.
 func (g *BlkTmplGenerator) UpdateExtraNonce(msgBlock *wire.MsgBlock, blockHeight int32, extraNonce uint64) error {
-	coinbaseScript, err := standardCoinbaseScript(blockHeight, extraNonce)
+	flags := g.coinbaseScriptFlags(
+		msgBlock.Transactions[0].TxIn[0].SignatureScript)
+	coinbaseScript, err := standardCoinbaseScript(blockHeight, extraNonce,
+		flags)
 	if err != nil {
 		return err
 	}
@@ -951,6 +1166,29 @@ func (g *BlkTmplGenerator) UpdateExtraNonce(msgBlock *wire.MsgBlock, blockHeight
 	return nil
 }
 
+// coinbaseScriptFlags returns the coinbase flags of the passed coinbase script
+// created by standardCoinbaseScript, which are its last data push.  The flags
+// of the generator are returned when the script does not push any data.
+func (g *BlkTmplGenerator) coinbaseScriptFlags(script []byte) string {
+	pushes, err := txscript.PushedData(script)
+	if err != nil || len(pushes) == 0 {
+		return g.CoinbaseFlags()
+	}
+	return string(pushes[len(pushes)-1])
+}
+
+// CoinbaseFlags returns the flags added to the coinbase script of generated
+// blocks unless a coinbase spec provides its own.  They are the CoinbaseFlags
+// policy setting or the default CoinbaseFlags when it is not set.
+//
+// This function is safe for concurrent access.
+func (g *BlkTmplGenerator) CoinbaseFlags() string {
+	if g.policy.CoinbaseFlags != "" {
+		return g.policy.CoinbaseFlags
+	}
+	return CoinbaseFlags
+}
+
 // BestSnapshot returns information about the current best chain block and
 // related state as of the current point in time using the chain instance
 // associated with the block template generator.  The returned state must be
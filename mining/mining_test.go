@@ -5,10 +5,12 @@
 package mining
 
 import (
+	"bytes"
 	"container/heap"
 	"math/rand"
 	"testing"
 
+	"github.com/vertcoin/vtcd/wire"
 	"github.com/vertcoin/vtcutil"
 )
 
@@ -108,3 +110,47 @@ func TestTxFeePrioHeap(t *testing.T) {
 		highest = prioItem
 	}
 }
+
+// TestUpdateExtraNonceFlags ensures updating the extra nonce of a block keeps
+// the coinbase flags it was created with.
+func TestUpdateExtraNonceFlags(t *testing.T) {
+	t.Parallel()
+
+	g := &BlkTmplGenerator{policy: &Policy{CoinbaseFlags: "/policy/"}}
+	tests := []struct {
+		name  string
+		flags string
+	}{
+		{name: "spec flags", flags: "/tag/"},
+		{name: "policy flags", flags: "/policy/"},
+		{name: "no flags", flags: ""},
+	}
+	for _, test := range tests {
+		script, err := standardCoinbaseScript(1000, 0, test.flags)
+		if err != nil {
+			t.Fatalf("%s: unable to create coinbase script: %v",
+				test.name, err)
+		}
+		coinbaseTx := wire.NewMsgTx(wire.TxVersion)
+		coinbaseTx.AddTxIn(&wire.TxIn{SignatureScript: script})
+		msgBlock := &wire.MsgBlock{
+			Transactions: []*wire.MsgTx{coinbaseTx},
+		}
+
+		for _, extraNonce := range []uint64{1, 17, 1 << 40} {
+			err := g.UpdateExtraNonce(msgBlock, 1000, extraNonce)
+			if err != nil {
+				t.Fatalf("%s: UpdateExtraNonce: unexpected error: "+
+					"%v", test.name, err)
+			}
+			want, _ := standardCoinbaseScript(1000, extraNonce,
+				test.flags)
+			got := coinbaseTx.TxIn[0].SignatureScript
+			if !bytes.Equal(got, want) {
+				t.Errorf("%s: unexpected coinbase script for "+
+					"extra nonce %d - got %x, want %x",
+					test.name, extraNonce, got, want)
+			}
+		}
+	}
+}
@@ -5,6 +5,8 @@
 package mining
 
 import (
+	"time"
+
 	"github.com/vertcoin/vtcd/blockchain"
 	"github.com/vertcoin/vtcd/wire"
 	"github.com/vertcoin/vtcutil"
@@ -45,6 +47,17 @@ type Policy struct {
 	// required for a transaction to be treated as free for mining purposes
 	// (block template generation).
 	TxMinFreeFee vtcutil.Amount
+
+	// CoinbaseFlags is added to the coinbase script of generated blocks
+	// instead of the default CoinbaseFlags when it is not empty.
+	CoinbaseFlags string
+
+	// CheckTxStandard defines the function used to check whether a
+	// transaction follows the standard policy at the passed height and
+	// median time.  Transactions which do not are skipped when generating
+	// a block template, so templates follow the same policy profile as
+	// the transaction source.  No transactions are skipped when it is nil.
+	CheckTxStandard func(tx *vtcutil.Tx, height int32, medianTimePast time.Time) error
 }
 
 // minInt is a helper function to return the minimum of two ints.  This avoids
@@ -0,0 +1,497 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package stratum
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vertcoin/vtcd/blockchain"
+)
+
+const (
+	// idleTimeout is the duration of inactivity before a connection is
+	// closed.
+	idleTimeout = time.Minute * 10
+
+	// writeTimeout is the maximum duration to write a message.
+	writeTimeout = time.Second * 30
+
+	// sendQueueSize is the maximum number of messages queued for a
+	// connection.  Connections which do not keep up are closed.
+	sendQueueSize = 32
+
+	// targetShareInterval is the desired interval between the shares of a
+	// connection.  The difficulty of each connection is adjusted so it
+	// submits shares at about this interval.
+	targetShareInterval = time.Second * 10
+
+	// retargetInterval is the minimum interval between difficulty
+	// adjustments of a connection.
+	retargetInterval = time.Minute
+
+	// maxRetargetFactor is the maximum factor by which the difficulty of a
+	// connection changes in one adjustment.
+	maxRetargetFactor = 4
+
+	// retargetThreshold is the minimum relative change of the difficulty
+	// of a connection for it to be adjusted.  This avoids sending new
+	// difficulties due to random variations of the share rate.
+	retargetThreshold = 0.2
+)
+
+// stratumError is an error returned to a miner.  It is encoded as an array of
+// the error code, the message and a traceback which is always null.
+type stratumError struct {
+	Code    int
+	Message string
+}
+
+// MarshalJSON encodes the error in the format used by Stratum.
+func (e stratumError) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{e.Code, e.Message, nil})
+}
+
+// These variables define the errors returned to miners.
+var (
+	errOther         = &stratumError{20, "Other/Unknown"}
+	errJobNotFound   = &stratumError{21, "Job not found"}
+	errDuplicate     = &stratumError{22, "Duplicate share"}
+	errLowDifficulty = &stratumError{23, "Low difficulty share"}
+	errUnauthorized  = &stratumError{24, "Unauthorized worker"}
+	errNotSubscribed = &stratumError{25, "Not subscribed"}
+)
+
+// otherError returns an error with the code of errOther and the passed
+// message.
+func otherError(message string) *stratumError {
+	return &stratumError{errOther.Code, message}
+}
+
+// request is a request from a miner.
+type request struct {
+	ID     interface{}   `json:"id"`
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+}
+
+// response is the response to a request.
+type response struct {
+	ID     interface{} `json:"id"`
+	Result interface{} `json:"result"`
+	Error  interface{} `json:"error"`
+}
+
+// notification is a message sent to a miner without a request.
+type notification struct {
+	ID     interface{}   `json:"id"`
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+}
+
+// stringParams returns the passed parameters as strings.  At least min
+// parameters must be passed.
+func stringParams(params []interface{}, min int) ([]string, bool) {
+	if len(params) < min {
+		return nil, false
+	}
+	strs := make([]string, 0, len(params))
+	for _, param := range params {
+		str, ok := param.(string)
+		if !ok {
+			return nil, false
+		}
+		strs = append(strs, str)
+	}
+	return strs, true
+}
+
+// retargetDifficulty returns the difficulty which makes a connection submit
+// shares at the target interval given that it submitted the passed number of
+// shares at the current difficulty during the elapsed time.  The difficulty
+// changes by at most maxRetargetFactor and is never below the minimum.
+func retargetDifficulty(difficulty float64, shares uint32, elapsed time.Duration, minDifficulty float64) float64 {
+	newDifficulty := difficulty / maxRetargetFactor
+	if shares > 0 {
+		newDifficulty = difficulty * float64(shares) *
+			float64(targetShareInterval) / float64(elapsed)
+	}
+	if newDifficulty > difficulty*maxRetargetFactor {
+		newDifficulty = difficulty * maxRetargetFactor
+	}
+	if newDifficulty < difficulty/maxRetargetFactor {
+		newDifficulty = difficulty / maxRetargetFactor
+	}
+	if newDifficulty < minDifficulty {
+		newDifficulty = minDifficulty
+	}
+	return newDifficulty
+}
+
+// client is a connection from a miner.
+type client struct {
+	server       *Server
+	conn         net.Conn
+	extraNonce1  []byte
+	sendQueue    chan []byte
+	quit         chan struct{}
+	disconnected int32
+
+	mtx          sync.Mutex
+	subscribed   bool
+	workers      map[string]struct{}
+	difficulty   float64
+	jobDiffs     map[string]float64
+	jobIDs       []string
+	shares       uint32
+	lastRetarget time.Time
+}
+
+// newClient returns a new connection with the passed extranonce1.
+func newClient(s *Server, conn net.Conn, extraNonce1 []byte) *client {
+	return &client{
+		server:       s,
+		conn:         conn,
+		extraNonce1:  extraNonce1,
+		sendQueue:    make(chan []byte, sendQueueSize),
+		quit:         make(chan struct{}),
+		workers:      make(map[string]struct{}),
+		difficulty:   s.cfg.MinDifficulty,
+		jobDiffs:     make(map[string]float64),
+		lastRetarget: time.Now(),
+	}
+}
+
+// disconnect closes the connection.  It is safe to call more than once.
+func (c *client) disconnect() {
+	if !atomic.CompareAndSwapInt32(&c.disconnected, 0, 1) {
+		return
+	}
+
+	log.Debugf("Stratum connection from %s closed", c.conn.RemoteAddr())
+	close(c.quit)
+	c.conn.Close()
+	c.server.removeClient(c)
+}
+
+// inHandler reads and handles the newline delimited requests of the miner.  It
+// must be run as a goroutine.
+func (c *client) inHandler() {
+	scanner := bufio.NewScanner(c.conn)
+	for {
+		c.conn.SetReadDeadline(time.Now().Add(idleTimeout))
+		if !scanner.Scan() {
+			break
+		}
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var req request
+		if err := json.Unmarshal(line, &req); err != nil {
+			log.Debugf("Malformed Stratum request from %s: %v",
+				c.conn.RemoteAddr(), err)
+			break
+		}
+		c.handleRequest(&req)
+	}
+
+	c.disconnect()
+	c.server.wg.Done()
+}
+
+// outHandler writes the queued messages to the miner.  It must be run as a
+// goroutine.
+func (c *client) outHandler() {
+out:
+	for {
+		select {
+		case msg := <-c.sendQueue:
+			c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if _, err := c.conn.Write(msg); err != nil {
+				log.Debugf("Can't write to Stratum connection "+
+					"from %s: %v", c.conn.RemoteAddr(), err)
+				c.disconnect()
+				break out
+			}
+
+		case <-c.quit:
+			break out
+		}
+	}
+	c.server.wg.Done()
+}
+
+// queueMessage queues the passed message to be sent to the miner.  The
+// connection is closed when the queue is full.
+func (c *client) queueMessage(msg interface{}) {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		log.Errorf("Failed to marshal Stratum message: %v", err)
+		return
+	}
+	b = append(b, '\n')
+
+	select {
+	case c.sendQueue <- b:
+	case <-c.quit:
+	default:
+		log.Warnf("Closing Stratum connection from %s which does not "+
+			"keep up with messages", c.conn.RemoteAddr())
+		c.disconnect()
+	}
+}
+
+// queueNotification queues a notification with the passed method and params.
+func (c *client) queueNotification(method string, params ...interface{}) {
+	c.queueMessage(&notification{Method: method, Params: params})
+}
+
+// notifyJob sends the passed job to the miner when it is subscribed.  Shares
+// for the job must meet the current difficulty of the connection.
+func (c *client) notifyJob(j *job, cleanJobs bool) {
+	c.mtx.Lock()
+	if c.subscribed {
+		c.notifyJobLocked(j, cleanJobs)
+	}
+	c.mtx.Unlock()
+}
+
+// notifyJobLocked sends the passed job to the miner.  Messages are queued while
+// the client lock is held so the miner receives difficulties and jobs in the
+// order they apply.
+//
+// This function MUST be called with the client lock held.
+func (c *client) notifyJobLocked(j *job, cleanJobs bool) {
+	if cleanJobs {
+		c.jobDiffs = make(map[string]float64)
+		c.jobIDs = c.jobIDs[:0]
+	}
+
+	// A job is sent again after the difficulty changed.  Accept shares of
+	// either difficulty in that case since some might still be in flight.
+	if difficulty, ok := c.jobDiffs[j.id]; !ok {
+		if len(c.jobIDs) >= maxJobs {
+			delete(c.jobDiffs, c.jobIDs[0])
+			c.jobIDs = c.jobIDs[1:]
+		}
+		c.jobDiffs[j.id] = c.difficulty
+		c.jobIDs = append(c.jobIDs, j.id)
+	} else if c.difficulty < difficulty {
+		c.jobDiffs[j.id] = c.difficulty
+	}
+
+	c.queueNotification("mining.notify", j.notifyParams(cleanJobs)...)
+}
+
+// subscribe marks the connection as subscribed and sends the difficulty and the
+// current job.  It is called once the response to the subscription is queued.
+func (c *client) subscribe() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.subscribed {
+		return
+	}
+
+	c.subscribed = true
+	c.queueNotification("mining.set_difficulty", c.difficulty)
+	if j := c.server.currentJob(); j != nil {
+		c.notifyJobLocked(j, true)
+	}
+}
+
+// retarget adjusts the difficulty of the connection when the retarget interval
+// elapsed and sends the current job with the new difficulty.
+func (c *client) retarget() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	elapsed := time.Since(c.lastRetarget)
+	if !c.subscribed || elapsed < retargetInterval {
+		return
+	}
+	difficulty := retargetDifficulty(c.difficulty, c.shares, elapsed,
+		c.server.cfg.MinDifficulty)
+	c.shares = 0
+	c.lastRetarget = time.Now()
+	change := difficulty/c.difficulty - 1
+	if change > -retargetThreshold && change < retargetThreshold {
+		return
+	}
+
+	log.Debugf("Changing Stratum difficulty of %s to %v",
+		c.conn.RemoteAddr(), difficulty)
+	c.difficulty = difficulty
+	c.queueNotification("mining.set_difficulty", difficulty)
+	if j := c.server.currentJob(); j != nil {
+		c.notifyJobLocked(j, false)
+	}
+}
+
+// handleRequest handles a request from the miner and queues the response.
+func (c *client) handleRequest(req *request) {
+	var result interface{}
+	var err *stratumError
+	switch req.Method {
+	case "mining.subscribe":
+		result, err = c.handleSubscribe(req.Params)
+	case "mining.authorize":
+		result, err = c.handleAuthorize(req.Params)
+	case "mining.submit":
+		result, err = c.handleSubmit(req.Params)
+	case "mining.extranonce.subscribe":
+		// Extra nonces never change, so there is nothing to notify.
+		result = false
+	default:
+		err = otherError("Unknown method '" + req.Method + "'")
+	}
+
+	resp := response{ID: req.ID, Result: result}
+	if err != nil {
+		resp.Error = err
+	}
+	c.queueMessage(&resp)
+
+	switch {
+	case req.Method == "mining.subscribe" && err == nil:
+		c.subscribe()
+
+	case req.Method == "mining.submit" && err == nil:
+		c.retarget()
+	}
+}
+
+// handleSubscribe handles a mining.subscribe request.  The result consists of
+// the subscriptions, the extranonce1 and the size of the extranonce2.
+func (c *client) handleSubscribe(params []interface{}) (interface{}, *stratumError) {
+	subscriptionID := hex.EncodeToString(c.extraNonce1)
+	subscriptions := [][]string{
+		{"mining.set_difficulty", subscriptionID},
+		{"mining.notify", subscriptionID},
+	}
+	return []interface{}{subscriptions, subscriptionID, extraNonce2Size},
+		nil
+}
+
+// handleAuthorize handles a mining.authorize request.  The result is whether
+// the worker was authorized.
+func (c *client) handleAuthorize(params []interface{}) (interface{}, *stratumError) {
+	strs, ok := stringParams(params, 1)
+	if !ok {
+		return nil, otherError("Invalid parameters")
+	}
+	worker := strs[0]
+	var password string
+	if len(strs) > 1 {
+		password = strs[1]
+	}
+
+	if c.server.cfg.Password != "" && subtle.ConstantTimeCompare(
+		[]byte(password), []byte(c.server.cfg.Password)) != 1 {
+
+		log.Warnf("Stratum authorization of worker '%s' from %s failed",
+			worker, c.conn.RemoteAddr())
+		return false, nil
+	}
+
+	c.mtx.Lock()
+	c.workers[worker] = struct{}{}
+	c.mtx.Unlock()
+
+	log.Debugf("Authorized Stratum worker '%s' from %s", worker,
+		c.conn.RemoteAddr())
+	return true, nil
+}
+
+// handleSubmit handles a mining.submit request with the worker, job id,
+// extranonce2, time and nonce of a share.  Shares which meet the network
+// target are submitted as blocks.
+func (c *client) handleSubmit(params []interface{}) (interface{}, *stratumError) {
+	strs, ok := stringParams(params, 5)
+	if !ok {
+		return nil, otherError("Invalid parameters")
+	}
+	worker, jobID := strs[0], strs[1]
+
+	c.mtx.Lock()
+	subscribed := c.subscribed
+	_, authorized := c.workers[worker]
+	difficulty, knownJob := c.jobDiffs[jobID]
+	c.mtx.Unlock()
+	if !subscribed {
+		return nil, errNotSubscribed
+	}
+	if !authorized {
+		return nil, errUnauthorized
+	}
+	j := c.server.job(jobID)
+	if j == nil || !knownJob {
+		return nil, errJobNotFound
+	}
+
+	extraNonce2, err := hex.DecodeString(strs[2])
+	if err != nil || len(extraNonce2) != extraNonce2Size {
+		return nil, otherError("Invalid extranonce2")
+	}
+	nTime, err := parseUint32Hex(strs[3])
+	if err != nil {
+		return nil, otherError("Invalid ntime")
+	}
+	jobTime := j.template.Block.Header.Timestamp.Unix()
+	if int64(nTime) < jobTime || int64(nTime) > jobTime+maxTimeOffset {
+		return nil, otherError("Ntime out of range")
+	}
+	nonce, err := parseUint32Hex(strs[4])
+	if err != nil {
+		return nil, otherError("Invalid nonce")
+	}
+
+	extraNonce := make([]byte, 0, extraNonceSize)
+	extraNonce = append(extraNonce, c.extraNonce1...)
+	extraNonce = append(extraNonce, extraNonce2...)
+	if j.markSubmitted(extraNonce, nTime, nonce) {
+		return nil, errDuplicate
+	}
+
+	coinbase, header, err := j.solve(extraNonce, nTime, nonce)
+	if err != nil {
+		log.Errorf("Failed to build share of Stratum job %s: %v",
+			j.id, err)
+		return nil, otherError("Invalid share")
+	}
+	powHash, err := header.PowHash()
+	if err != nil {
+		log.Errorf("Failed to hash share of Stratum job %s: %v",
+			j.id, err)
+		return nil, otherError("Invalid share")
+	}
+
+	// Shares which solve a block are accepted regardless of the share
+	// difficulty.
+	hashNum := blockchain.HashToBig(powHash)
+	if hashNum.Cmp(blockchain.CompactToBig(header.Bits)) <= 0 {
+		log.Infof("Stratum worker '%s' from %s found block %s", worker,
+			c.conn.RemoteAddr(), header.BlockHash())
+		err := c.server.submitBlock(j.block(coinbase, header))
+		if err != nil {
+			return nil, otherError("Block rejected: " + err.Error())
+		}
+	} else if hashNum.Cmp(c.server.shareTarget(difficulty)) > 0 {
+		return nil, errLowDifficulty
+	}
+
+	c.mtx.Lock()
+	c.shares++
+	c.mtx.Unlock()
+	return true, nil
+}
@@ -0,0 +1,257 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package stratum
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/vertcoin/vtcd/blockchain"
+	"github.com/vertcoin/vtcd/chaincfg/chainhash"
+	"github.com/vertcoin/vtcd/mining"
+	"github.com/vertcoin/vtcd/txscript"
+	"github.com/vertcoin/vtcd/wire"
+	"github.com/vertcoin/vtcutil"
+)
+
+const (
+	// extraNonce1Size is the size in bytes of the extra nonce assigned to
+	// each connection by the server.
+	extraNonce1Size = 4
+
+	// extraNonce2Size is the size in bytes of the extra nonce chosen by the
+	// miner.
+	extraNonce2Size = 4
+
+	// extraNonceSize is the total size of the extra nonce in the coinbase
+	// signature script.
+	extraNonceSize = extraNonce1Size + extraNonce2Size
+)
+
+// job is a unit of work sent to miners.  It holds the block template along
+// with the coinbase transaction split around the extra nonce and the merkle
+// branch needed to compute the merkle root from the coinbase hash.
+type job struct {
+	id           string
+	template     *mining.BlockTemplate
+	coinbase1    []byte
+	coinbase2    []byte
+	merkleBranch []chainhash.Hash
+	lastTxUpdate time.Time
+
+	// submitted tracks the shares submitted for the job in order to reject
+	// duplicates.
+	submittedMtx sync.Mutex
+	submitted    map[string]struct{}
+}
+
+// coinbaseScript returns the signature script of the coinbase transaction for a
+// block at the passed height with the passed extra nonce and coinbase flags,
+// along with the offset of the extra nonce within the script.
+func coinbaseScript(height int32, extraNonce []byte, flags string) ([]byte, int, error) {
+	heightScript, err := txscript.NewScriptBuilder().
+		AddInt64(int64(height)).Script()
+	if err != nil {
+		return nil, 0, err
+	}
+	script, err := txscript.NewScriptBuilder().AddInt64(int64(height)).
+		AddData(extraNonce).AddData([]byte(flags)).Script()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// The extra nonce follows the height and its own data push opcode.
+	return script, len(heightScript) + 1, nil
+}
+
+// merkleBranch returns the hashes needed to compute the merkle root of the
+// passed transactions from the hash of the first one, which is the coinbase.
+func merkleBranch(txns []*wire.MsgTx) []chainhash.Hash {
+	if len(txns) <= 1 {
+		return nil
+	}
+
+	level := make([]*chainhash.Hash, 0, len(txns)-1)
+	for _, tx := range txns[1:] {
+		hash := tx.TxHash()
+		level = append(level, &hash)
+	}
+
+	// The first remaining hash of each level is the sibling of the branch
+	// leading to the coinbase.  The others are hashed into the next level.
+	var branch []chainhash.Hash
+	for len(level) > 0 {
+		branch = append(branch, *level[0])
+		rest := level[1:]
+		if len(rest)%2 != 0 {
+			rest = append(rest, rest[len(rest)-1])
+		}
+		next := make([]*chainhash.Hash, 0, len(rest)/2)
+		for i := 0; i < len(rest); i += 2 {
+			next = append(next, blockchain.HashMerkleBranches(rest[i],
+				rest[i+1]))
+		}
+		level = next
+	}
+	return branch
+}
+
+// newJob returns a job with the passed id for the passed block template.  The
+// passed coinbase flags are added to the coinbase script.
+func newJob(id string, template *mining.BlockTemplate, flags string, lastTxUpdate time.Time) (*job, error) {
+	msgBlock := template.Block
+	if len(msgBlock.Transactions) == 0 ||
+		len(msgBlock.Transactions[0].TxIn) != 1 {
+
+		return nil, errors.New("block template has no valid coinbase")
+	}
+
+	// Replace the signature script of the coinbase with one that has a
+	// placeholder for the extra nonce and split the serialized coinbase
+	// around it.  Witness data does not contribute to the transaction hash,
+	// so the transaction is serialized without it.
+	coinbase := msgBlock.Transactions[0].Copy()
+	script, offset, err := coinbaseScript(template.Height,
+		make([]byte, extraNonceSize), flags)
+	if err != nil {
+		return nil, err
+	}
+	coinbase.TxIn[0].SignatureScript = script
+	var buf bytes.Buffer
+	buf.Grow(coinbase.SerializeSizeStripped())
+	if err := coinbase.SerializeNoWitness(&buf); err != nil {
+		return nil, err
+	}
+	serialized := buf.Bytes()
+
+	// The signature script follows the version, the input count, the
+	// previous outpoint and the length of the script.
+	offset += 4 + wire.VarIntSerializeSize(1) + chainhash.HashSize + 4 +
+		wire.VarIntSerializeSize(uint64(len(script)))
+
+	return &job{
+		id:           id,
+		template:     template,
+		coinbase1:    serialized[:offset],
+		coinbase2:    serialized[offset+extraNonceSize:],
+		merkleBranch: merkleBranch(msgBlock.Transactions),
+		lastTxUpdate: lastTxUpdate,
+		submitted:    make(map[string]struct{}),
+	}, nil
+}
+
+// swapWords returns a copy of the passed bytes with the byte order of each
+// 32-bit word reversed.  Stratum sends the previous block hash in this order.
+func swapWords(b []byte) []byte {
+	swapped := make([]byte, len(b))
+	for i := 0; i+4 <= len(b); i += 4 {
+		swapped[i] = b[i+3]
+		swapped[i+1] = b[i+2]
+		swapped[i+2] = b[i+1]
+		swapped[i+3] = b[i]
+	}
+	return swapped
+}
+
+// uint32Hex returns the passed value as big-endian hex as used by Stratum.
+func uint32Hex(v uint32) string {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], v)
+	return hex.EncodeToString(b[:])
+}
+
+// parseUint32Hex parses a big-endian hex encoded value as used by Stratum.
+func parseUint32Hex(s string) (uint32, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != 4 {
+		return 0, fmt.Errorf("'%s' is not a 4-byte hex value", s)
+	}
+	return binary.BigEndian.Uint32(b), nil
+}
+
+// notifyParams returns the parameters of the mining.notify notification for
+// the job.
+func (j *job) notifyParams(cleanJobs bool) []interface{} {
+	header := &j.template.Block.Header
+	branch := make([]string, 0, len(j.merkleBranch))
+	for i := range j.merkleBranch {
+		branch = append(branch, hex.EncodeToString(j.merkleBranch[i][:]))
+	}
+	return []interface{}{
+		j.id,
+		hex.EncodeToString(swapWords(header.PrevBlock[:])),
+		hex.EncodeToString(j.coinbase1),
+		hex.EncodeToString(j.coinbase2),
+		branch,
+		uint32Hex(uint32(header.Version)),
+		uint32Hex(header.Bits),
+		uint32Hex(uint32(header.Timestamp.Unix())),
+		cleanJobs,
+	}
+}
+
+// markSubmitted records a share and returns whether it was submitted before.
+func (j *job) markSubmitted(extraNonce []byte, nTime, nonce uint32) bool {
+	key := fmt.Sprintf("%x:%08x:%08x", extraNonce, nTime, nonce)
+
+	j.submittedMtx.Lock()
+	defer j.submittedMtx.Unlock()
+	if _, ok := j.submitted[key]; ok {
+		return true
+	}
+	j.submitted[key] = struct{}{}
+	return false
+}
+
+// solve returns the coinbase transaction and the block header for the passed
+// extra nonce, time and nonce submitted by a miner.
+func (j *job) solve(extraNonce []byte, nTime, nonce uint32) (*wire.MsgTx, *wire.BlockHeader, error) {
+	serialized := make([]byte, 0, len(j.coinbase1)+len(extraNonce)+
+		len(j.coinbase2))
+	serialized = append(serialized, j.coinbase1...)
+	serialized = append(serialized, extraNonce...)
+	serialized = append(serialized, j.coinbase2...)
+	var coinbase wire.MsgTx
+	err := coinbase.DeserializeNoWitness(bytes.NewReader(serialized))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Keep the witness of the template coinbase which holds the reserved
+	// value of the witness commitment.
+	templateCoinbase := j.template.Block.Transactions[0]
+	coinbase.TxIn[0].Witness = templateCoinbase.TxIn[0].Witness
+
+	merkleRoot := coinbase.TxHash()
+	for i := range j.merkleBranch {
+		merkleRoot = *blockchain.HashMerkleBranches(&merkleRoot,
+			&j.merkleBranch[i])
+	}
+
+	header := j.template.Block.Header
+	header.MerkleRoot = merkleRoot
+	header.Timestamp = time.Unix(int64(nTime), 0)
+	header.Nonce = nonce
+	return &coinbase, &header, nil
+}
+
+// block returns the block of the job with the passed coinbase transaction and
+// header.
+func (j *job) block(coinbase *wire.MsgTx, header *wire.BlockHeader) *vtcutil.Block {
+	templateBlock := j.template.Block
+	msgBlock := wire.MsgBlock{
+		Header:       *header,
+		Transactions: make([]*wire.MsgTx, 0, len(templateBlock.Transactions)),
+	}
+	msgBlock.Transactions = append(msgBlock.Transactions, coinbase)
+	msgBlock.Transactions = append(msgBlock.Transactions,
+		templateBlock.Transactions[1:]...)
+	return vtcutil.NewBlock(&msgBlock)
+}
@@ -0,0 +1,411 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package stratum
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"math/rand"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vertcoin/vtcd/blockchain"
+	"github.com/vertcoin/vtcd/chaincfg"
+	"github.com/vertcoin/vtcd/mining"
+	"github.com/vertcoin/vtcutil"
+)
+
+const (
+	// maxJobs is the maximum number of jobs for the current block which
+	// are kept so shares for recently replaced jobs are still accepted.
+	maxJobs = 8
+
+	// jobRefreshInterval is the interval at which a new job is created
+	// when transactions were added to or removed from the memory pool
+	// since the current job was created.
+	jobRefreshInterval = time.Second * 30
+
+	// maxTimeOffset is the maximum number of seconds a miner may increase
+	// the time of a job.
+	maxTimeOffset = 7200
+
+	// minAcceptBackoff and maxAcceptBackoff bound the time the server
+	// waits before accepting connections again after a temporary error,
+	// such as running out of file descriptors.  The wait is doubled for
+	// every consecutive error.
+	minAcceptBackoff = time.Millisecond * 5
+	maxAcceptBackoff = time.Second
+)
+
+// Config is a descriptor containing the Stratum server configuration.
+type Config struct {
+	// ChainParams identifies which chain parameters the server is
+	// associated with.  Share difficulties are relative to the proof of
+	// work limit of the chain.
+	ChainParams *chaincfg.Params
+
+	// NewBlockTemplate defines the function to use to create the block
+	// templates jobs are built from.  It is typically the NewBlockTemplate
+	// method of a mining.TemplateCache so the templates are shared with the
+	// RPC server.
+	NewBlockTemplate func(payToAddress vtcutil.Address) (*mining.BlockTemplate, error)
+
+	// LastTxUpdate defines the function to use to obtain the last time a
+	// transaction was added to or removed from the source pool.  It is
+	// used to refresh jobs with new transactions.
+	LastTxUpdate func() time.Time
+
+	// MiningAddrs is a list of payment addresses to use for the generated
+	// blocks.  Each job will randomly choose one of them.
+	MiningAddrs []vtcutil.Address
+
+	// ProcessBlock defines the function to call with any solved blocks.
+	// It typically must run the provided block through the same set of
+	// rules and handling as any other block coming from the network.
+	ProcessBlock func(*vtcutil.Block, blockchain.BehaviorFlags) (bool, error)
+
+	// IsCurrent defines the function to use to obtain whether or not the
+	// block chain is current.  No jobs are created while the chain is not
+	// current since any solved blocks would end up orphaned anyways.
+	IsCurrent func() bool
+
+	// Listeners defines a slice of listeners for which the server will
+	// accept Stratum connections.
+	Listeners []net.Listener
+
+	// Password is the password miners must use to authorize workers.  Any
+	// password is accepted when it is empty.
+	Password string
+
+	// MinDifficulty is the initial and the minimum share difficulty of
+	// each connection.
+	MinDifficulty float64
+
+	// CoinbaseFlags is added to the coinbase script of the jobs.
+	CoinbaseFlags string
+}
+
+// Server provides a Stratum v1 mining server.  Jobs are built from block
+// templates and pushed to all subscribed miners whenever a block is connected
+// to the main chain or the memory pool changed.  Shares which satisfy the
+// network target are submitted as blocks.
+type Server struct {
+	started  int32
+	shutdown int32
+	cfg      Config
+	wg       sync.WaitGroup
+	newBlock chan struct{}
+	quit     chan struct{}
+
+	mtx             sync.Mutex
+	jobs            map[string]*job
+	jobIDs          []string
+	curJob          *job
+	nextJobID       uint64
+	clients         map[*client]struct{}
+	nextExtraNonce1 uint32
+}
+
+// New returns a new instance of a Stratum server for the provided
+// configuration.  Use Start to begin accepting connections.
+func New(cfg *Config) *Server {
+	return &Server{
+		cfg:             *cfg,
+		newBlock:        make(chan struct{}, 1),
+		quit:            make(chan struct{}),
+		jobs:            make(map[string]*job),
+		clients:         make(map[*client]struct{}),
+		nextExtraNonce1: rand.Uint32(),
+	}
+}
+
+// Start begins accepting connections and creating jobs.
+func (s *Server) Start() {
+	if atomic.AddInt32(&s.started, 1) != 1 {
+		return
+	}
+
+	log.Trace("Starting Stratum server")
+	for _, listener := range s.cfg.Listeners {
+		s.wg.Add(1)
+		go s.listenHandler(listener)
+	}
+	s.wg.Add(1)
+	go s.jobHandler()
+}
+
+// Stop stops accepting connections, disconnects all miners and waits for all
+// goroutines to finish.
+func (s *Server) Stop() {
+	if atomic.AddInt32(&s.shutdown, 1) != 1 {
+		log.Infof("Stratum server is already in the process of shutting " +
+			"down")
+		return
+	}
+
+	log.Warnf("Stratum server shutting down")
+	for _, listener := range s.cfg.Listeners {
+		err := listener.Close()
+		if err != nil {
+			log.Errorf("Problem shutting down Stratum listener: %v",
+				err)
+		}
+	}
+	close(s.quit)
+	for _, c := range s.clientList() {
+		c.disconnect()
+	}
+	s.wg.Wait()
+	log.Infof("Stratum server shutdown complete")
+}
+
+// HandleBlockchainNotification handles notifications from blockchain.  New jobs
+// are pushed to all miners when a block is connected to the main chain.
+func (s *Server) HandleBlockchainNotification(notification *blockchain.Notification) {
+	if notification.Type != blockchain.NTBlockConnected {
+		return
+	}
+
+	// The notification may be sent while the chain lock is held, so the
+	// job is created asynchronously.
+	select {
+	case s.newBlock <- struct{}{}:
+	default:
+	}
+}
+
+// listenHandler accepts connections on the passed listener.  It must be run as
+// a goroutine.
+func (s *Server) listenHandler(listener net.Listener) {
+	log.Infof("Stratum server listening on %s", listener.Addr())
+	var backoff time.Duration
+out:
+	for atomic.LoadInt32(&s.shutdown) == 0 {
+		conn, err := listener.Accept()
+		if err != nil {
+			// The listener is closed when forcibly shutting down.
+			if atomic.LoadInt32(&s.shutdown) != 0 {
+				break
+			}
+			log.Errorf("Can't accept Stratum connection: %v", err)
+
+			// Stop listening when the error is permanent, such as
+			// when the listener was closed.
+			if ne, ok := err.(net.Error); !ok || !ne.Temporary() {
+				break
+			}
+
+			// Wait before accepting connections again so
+			// persistent errors don't spin the CPU.
+			if backoff == 0 {
+				backoff = minAcceptBackoff
+			} else if backoff *= 2; backoff > maxAcceptBackoff {
+				backoff = maxAcceptBackoff
+			}
+			select {
+			case <-time.After(backoff):
+			case <-s.quit:
+				break out
+			}
+			continue
+		}
+		backoff = 0
+		s.addClient(conn)
+	}
+	s.wg.Done()
+	log.Tracef("Stratum listener done for %s", listener.Addr())
+}
+
+// jobHandler creates new jobs when blocks are connected and periodically
+// refreshes the current job with the transactions in the memory pool.  It must
+// be run as a goroutine.
+func (s *Server) jobHandler() {
+	s.updateJob()
+
+	ticker := time.NewTicker(jobRefreshInterval)
+	defer ticker.Stop()
+
+out:
+	for {
+		select {
+		case <-s.newBlock:
+			s.updateJob()
+
+		case <-ticker.C:
+			curJob := s.currentJob()
+			if curJob == nil ||
+				s.cfg.LastTxUpdate().After(curJob.lastTxUpdate) {
+
+				s.updateJob()
+			}
+
+			// Lower the difficulty of miners which stopped
+			// submitting shares.
+			for _, c := range s.clientList() {
+				c.retarget()
+			}
+
+		case <-s.quit:
+			break out
+		}
+	}
+
+	s.wg.Done()
+	log.Tracef("Stratum job handler done")
+}
+
+// updateJob creates a new job from a new block template and pushes it to all
+// miners.  Miners are told to abandon older jobs when the new job builds on a
+// different block.
+func (s *Server) updateJob() {
+	if !s.cfg.IsCurrent() {
+		log.Debugf("Not creating a Stratum job since the chain is not " +
+			"current")
+		return
+	}
+	if len(s.cfg.MiningAddrs) == 0 {
+		log.Errorf("No mining addresses to create a Stratum job")
+		return
+	}
+
+	// Note the time of the last memory pool update before creating the
+	// template so no update is missed.
+	lastTxUpdate := s.cfg.LastTxUpdate()
+	payToAddr := s.cfg.MiningAddrs[rand.Intn(len(s.cfg.MiningAddrs))]
+	template, err := s.cfg.NewBlockTemplate(payToAddr)
+	if err != nil {
+		log.Errorf("Failed to create block template: %v", err)
+		return
+	}
+
+	s.mtx.Lock()
+	s.nextJobID++
+	j, err := newJob(strconv.FormatUint(s.nextJobID, 16), template,
+		s.cfg.CoinbaseFlags, lastTxUpdate)
+	if err != nil {
+		s.mtx.Unlock()
+		log.Errorf("Failed to create Stratum job: %v", err)
+		return
+	}
+	cleanJobs := s.curJob == nil || s.curJob.template.Block.Header.PrevBlock !=
+		template.Block.Header.PrevBlock
+	if cleanJobs {
+		s.jobs = make(map[string]*job)
+		s.jobIDs = s.jobIDs[:0]
+	}
+	if len(s.jobIDs) >= maxJobs {
+		delete(s.jobs, s.jobIDs[0])
+		s.jobIDs = s.jobIDs[1:]
+	}
+	s.jobs[j.id] = j
+	s.jobIDs = append(s.jobIDs, j.id)
+	s.curJob = j
+	s.mtx.Unlock()
+
+	log.Debugf("Created Stratum job %s for block height %d with %d "+
+		"transactions", j.id, template.Height,
+		len(template.Block.Transactions))
+
+	for _, c := range s.clientList() {
+		c.notifyJob(j, cleanJobs)
+	}
+}
+
+// currentJob returns the most recent job or nil when no job was created yet.
+func (s *Server) currentJob() *job {
+	s.mtx.Lock()
+	j := s.curJob
+	s.mtx.Unlock()
+	return j
+}
+
+// job returns the job with the passed id or nil when it does not exist or is
+// stale.
+func (s *Server) job(id string) *job {
+	s.mtx.Lock()
+	j := s.jobs[id]
+	s.mtx.Unlock()
+	return j
+}
+
+// addClient starts serving a new connection.
+func (s *Server) addClient(conn net.Conn) {
+	s.mtx.Lock()
+	// Stop disconnects the clients after signalling the shutdown, so
+	// connections accepted while shutting down are closed right away.
+	if atomic.LoadInt32(&s.shutdown) != 0 {
+		s.mtx.Unlock()
+		conn.Close()
+		return
+	}
+	extraNonce1 := make([]byte, extraNonce1Size)
+	binary.BigEndian.PutUint32(extraNonce1, s.nextExtraNonce1)
+	s.nextExtraNonce1++
+	c := newClient(s, conn, extraNonce1)
+	s.clients[c] = struct{}{}
+	s.mtx.Unlock()
+
+	log.Debugf("New Stratum connection from %s", conn.RemoteAddr())
+	s.wg.Add(2)
+	go c.inHandler()
+	go c.outHandler()
+}
+
+// removeClient stops tracking a disconnected connection.
+func (s *Server) removeClient(c *client) {
+	s.mtx.Lock()
+	delete(s.clients, c)
+	s.mtx.Unlock()
+}
+
+// clientList returns the connections served.
+func (s *Server) clientList() []*client {
+	s.mtx.Lock()
+	clients := make([]*client, 0, len(s.clients))
+	for c := range s.clients {
+		clients = append(clients, c)
+	}
+	s.mtx.Unlock()
+	return clients
+}
+
+// shareTarget returns the target a share of the passed difficulty must meet.
+func (s *Server) shareTarget(difficulty float64) *big.Int {
+	target, _ := new(big.Float).Quo(
+		new(big.Float).SetInt(s.cfg.ChainParams.PowLimit),
+		big.NewFloat(difficulty)).Int(nil)
+	return target
+}
+
+// submitBlock submits the passed block to the chain and returns an error when
+// it is not accepted.
+func (s *Server) submitBlock(block *vtcutil.Block) error {
+	isOrphan, err := s.cfg.ProcessBlock(block, blockchain.BFNone)
+	if err != nil {
+		// Anything other than a rule violation is an unexpected error,
+		// so log that error as an internal error.
+		if _, ok := err.(blockchain.RuleError); !ok {
+			log.Errorf("Unexpected error while processing block "+
+				"submitted via Stratum: %v", err)
+			return err
+		}
+
+		log.Errorf("Block submitted via Stratum rejected: %v", err)
+		return err
+	}
+	if isOrphan {
+		log.Errorf("Block submitted via Stratum is an orphan")
+		return errors.New("block is an orphan")
+	}
+
+	coinbaseTx := block.MsgBlock().Transactions[0].TxOut[0]
+	log.Infof("Block submitted via Stratum accepted (hash %s, amount %v)",
+		block.Hash(), vtcutil.Amount(coinbaseTx.Value))
+	return nil
+}
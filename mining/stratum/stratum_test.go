@@ -0,0 +1,555 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package stratum
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vertcoin/vtcd/blockchain"
+	"github.com/vertcoin/vtcd/chaincfg"
+	"github.com/vertcoin/vtcd/chaincfg/chainhash"
+	"github.com/vertcoin/vtcd/mining"
+	"github.com/vertcoin/vtcd/wire"
+	"github.com/vertcoin/vtcutil"
+)
+
+// testTemplate returns a block template with the passed number of transactions
+// which builds on the passed block and uses the passed target bits.
+func testTemplate(prevBlock chainhash.Hash, bits uint32, numTxns int) *mining.BlockTemplate {
+	coinbase := wire.NewMsgTx(1)
+	coinbase.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: *wire.NewOutPoint(&chainhash.Hash{},
+			wire.MaxPrevOutIndex),
+		SignatureScript: []byte{0x51, 0x51},
+		Sequence:        wire.MaxTxInSequenceNum,
+		Witness:         wire.TxWitness{make([]byte, 32)},
+	})
+	coinbase.AddTxOut(wire.NewTxOut(5000000000, []byte{0x51}))
+
+	msgBlock := &wire.MsgBlock{
+		Header: wire.BlockHeader{
+			Version:   0x20000000,
+			PrevBlock: prevBlock,
+			Timestamp: time.Unix(1500000000, 0),
+			Bits:      bits,
+		},
+		Transactions: []*wire.MsgTx{coinbase},
+	}
+	for i := 1; i < numTxns; i++ {
+		tx := wire.NewMsgTx(1)
+		tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&prevBlock, uint32(i)),
+			nil, nil))
+		tx.AddTxOut(wire.NewTxOut(int64(i), []byte{0x51}))
+		msgBlock.Transactions = append(msgBlock.Transactions, tx)
+	}
+	return &mining.BlockTemplate{Block: msgBlock, Height: 1000}
+}
+
+// TestMerkleBranch ensures the merkle root computed from the coinbase hash and
+// the merkle branch matches the merkle root of the block.
+func TestMerkleBranch(t *testing.T) {
+	for numTxns := 1; numTxns <= 9; numTxns++ {
+		template := testTemplate(chainhash.Hash{}, 0x207fffff, numTxns)
+		txns := template.Block.Transactions
+		utxns := make([]*vtcutil.Tx, 0, len(txns))
+		for _, tx := range txns {
+			utxns = append(utxns, vtcutil.NewTx(tx))
+		}
+		merkles := blockchain.BuildMerkleTreeStore(utxns, false)
+		want := merkles[len(merkles)-1]
+
+		root := txns[0].TxHash()
+		for _, hash := range merkleBranch(txns) {
+			root = *blockchain.HashMerkleBranches(&root, &hash)
+		}
+		if root != *want {
+			t.Errorf("%d transactions: got merkle root %v, want %v",
+				numTxns, root, want)
+		}
+	}
+}
+
+// TestRetargetDifficulty ensures the difficulty is adjusted towards the target
+// share interval within the allowed bounds.
+func TestRetargetDifficulty(t *testing.T) {
+	tests := []struct {
+		difficulty float64
+		shares     uint32
+		elapsed    time.Duration
+		min        float64
+		want       float64
+	}{
+		// Shares at the target interval keep the difficulty.
+		{16, 6, time.Minute, 1, 16},
+		// Twice the target rate doubles the difficulty.
+		{16, 12, time.Minute, 1, 32},
+		// Half the target rate halves the difficulty.
+		{16, 3, time.Minute, 1, 8},
+		// Changes are limited to the maximum factor.
+		{16, 600, time.Minute, 1, 64},
+		{16, 0, time.Minute, 1, 4},
+		// The difficulty never drops below the minimum.
+		{16, 0, time.Minute, 8, 8},
+	}
+	for i, test := range tests {
+		got := retargetDifficulty(test.difficulty, test.shares,
+			test.elapsed, test.min)
+		if got != test.want {
+			t.Errorf("test #%d: got difficulty %v, want %v", i, got,
+				test.want)
+		}
+	}
+}
+
+// testMiner is a scripted Stratum client used to drive the server in the tests.
+type testMiner struct {
+	t           *testing.T
+	conn        net.Conn
+	reader      *bufio.Reader
+	nextID      int
+	extraNonce1 []byte
+	difficulty  float64
+	job         []interface{}
+}
+
+// message is a message received by the test miner.
+type message struct {
+	ID     interface{}       `json:"id"`
+	Method string            `json:"method"`
+	Params []interface{}     `json:"params"`
+	Result interface{}       `json:"result"`
+	Error  []json.RawMessage `json:"error"`
+}
+
+// read returns the next message sent by the server.  Notifications are applied
+// to the state of the miner.
+func (m *testMiner) read() *message {
+	m.conn.SetReadDeadline(time.Now().Add(time.Second * 10))
+	line, err := m.reader.ReadBytes('\n')
+	if err != nil {
+		m.t.Fatalf("failed to read message: %v", err)
+	}
+	var msg message
+	if err := json.Unmarshal(line, &msg); err != nil {
+		m.t.Fatalf("failed to unmarshal message %s: %v", line, err)
+	}
+	switch msg.Method {
+	case "mining.set_difficulty":
+		m.difficulty = msg.Params[0].(float64)
+	case "mining.notify":
+		m.job = msg.Params
+	}
+	return &msg
+}
+
+// readNotification returns the next notification with the passed method.
+func (m *testMiner) readNotification(method string) *message {
+	for {
+		msg := m.read()
+		if msg.Method == method {
+			return msg
+		}
+	}
+}
+
+// call sends a request and returns the response.
+func (m *testMiner) call(method string, params ...interface{}) *message {
+	m.nextID++
+	b, err := json.Marshal(map[string]interface{}{
+		"id":     m.nextID,
+		"method": method,
+		"params": params,
+	})
+	if err != nil {
+		m.t.Fatalf("failed to marshal request: %v", err)
+	}
+	if _, err := m.conn.Write(append(b, '\n')); err != nil {
+		m.t.Fatalf("failed to write request: %v", err)
+	}
+	for {
+		msg := m.read()
+		if msg.Method == "" {
+			if msg.ID != float64(m.nextID) {
+				m.t.Fatalf("unexpected response id %v", msg.ID)
+			}
+			return msg
+		}
+	}
+}
+
+// errorCode returns the error code of the response or zero when it succeeded.
+func (m *testMiner) errorCode(msg *message) int {
+	if len(msg.Error) == 0 {
+		return 0
+	}
+	var code int
+	if err := json.Unmarshal(msg.Error[0], &code); err != nil {
+		m.t.Fatalf("malformed error %s", msg.Error[0])
+	}
+	return code
+}
+
+// header returns the block header for the current job with the passed
+// extranonce2 and nonce as computed by a miner.
+func (m *testMiner) header(extraNonce2 []byte, nonce uint32) *wire.BlockHeader {
+	decode := func(s interface{}) []byte {
+		b, err := hex.DecodeString(s.(string))
+		if err != nil {
+			m.t.Fatalf("malformed job field %v", s)
+		}
+		return b
+	}
+	parse := func(s interface{}) uint32 {
+		v, err := parseUint32Hex(s.(string))
+		if err != nil {
+			m.t.Fatalf("malformed job field %v", s)
+		}
+		return v
+	}
+
+	var coinbase []byte
+	coinbase = append(coinbase, decode(m.job[2])...)
+	coinbase = append(coinbase, m.extraNonce1...)
+	coinbase = append(coinbase, extraNonce2...)
+	coinbase = append(coinbase, decode(m.job[3])...)
+	merkleRoot := chainhash.DoubleHashH(coinbase)
+	for _, branch := range m.job[4].([]interface{}) {
+		var hash chainhash.Hash
+		copy(hash[:], decode(branch))
+		merkleRoot = *blockchain.HashMerkleBranches(&merkleRoot, &hash)
+	}
+
+	var prevBlock chainhash.Hash
+	copy(prevBlock[:], swapWords(decode(m.job[1])))
+	return &wire.BlockHeader{
+		Version:    int32(parse(m.job[5])),
+		PrevBlock:  prevBlock,
+		MerkleRoot: merkleRoot,
+		Timestamp:  time.Unix(int64(parse(m.job[7])), 0),
+		Bits:       parse(m.job[6]),
+		Nonce:      nonce,
+	}
+}
+
+// findNonce returns the first nonce for which the proof of work
+// hash of the current job is at most the passed target when below is set or
+// above it otherwise.
+func (m *testMiner) findNonce(extraNonce2 []byte, target *big.Int, below bool) uint32 {
+	for nonce := uint32(0); ; nonce++ {
+		powHash, err := m.header(extraNonce2, nonce).PowHash()
+		if err != nil {
+			m.t.Fatalf("failed to hash header: %v", err)
+		}
+		if (blockchain.HashToBig(powHash).Cmp(target) <= 0) == below {
+			return nonce
+		}
+	}
+}
+
+// submit submits a share for the current job.
+func (m *testMiner) submit(worker string, extraNonce2 []byte, nonce uint32) *message {
+	return m.call("mining.submit", worker, m.job[0],
+		hex.EncodeToString(extraNonce2), m.job[7], uint32Hex(nonce))
+}
+
+// testHarness is a Stratum server serving templates from a fake chain.
+type testHarness struct {
+	server *Server
+
+	mtx       sync.Mutex
+	template  *mining.BlockTemplate
+	submitted []*vtcutil.Block
+}
+
+// newTestHarness starts a Stratum server which serves the passed template.
+func newTestHarness(t *testing.T, template *mining.BlockTemplate) (*testHarness, *testMiner) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	addr, err := vtcutil.NewAddressPubKeyHash(make([]byte, 20),
+		&chaincfg.RegressionNetParams)
+	if err != nil {
+		t.Fatalf("failed to create address: %v", err)
+	}
+
+	h := &testHarness{template: template}
+	h.server = New(&Config{
+		ChainParams: &chaincfg.RegressionNetParams,
+		NewBlockTemplate: func(vtcutil.Address) (*mining.BlockTemplate, error) {
+			h.mtx.Lock()
+			defer h.mtx.Unlock()
+			return h.template, nil
+		},
+		LastTxUpdate: func() time.Time { return time.Time{} },
+		MiningAddrs:  []vtcutil.Address{addr},
+		ProcessBlock: func(block *vtcutil.Block, flags blockchain.BehaviorFlags) (bool, error) {
+			h.mtx.Lock()
+			h.submitted = append(h.submitted, block)
+			h.mtx.Unlock()
+			return false, nil
+		},
+		IsCurrent:     func() bool { return true },
+		Listeners:     []net.Listener{listener},
+		Password:      "secret",
+		MinDifficulty: 1,
+		CoinbaseFlags: mining.CoinbaseFlags,
+	})
+	h.server.Start()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	m := &testMiner{t: t, conn: conn, reader: bufio.NewReader(conn)}
+
+	// Subscribe and authorize the worker.
+	resp := m.call("mining.subscribe", "testminer/1.0")
+	result, ok := resp.Result.([]interface{})
+	if !ok || len(result) != 3 {
+		t.Fatalf("unexpected subscribe result %v", resp.Result)
+	}
+	m.extraNonce1, err = hex.DecodeString(result[1].(string))
+	if err != nil || len(m.extraNonce1) != extraNonce1Size {
+		t.Fatalf("unexpected extranonce1 %v", result[1])
+	}
+	if result[2] != float64(extraNonce2Size) {
+		t.Fatalf("unexpected extranonce2 size %v", result[2])
+	}
+	if m.job == nil {
+		m.readNotification("mining.notify")
+	}
+	if m.difficulty != 1 {
+		t.Fatalf("unexpected difficulty %v", m.difficulty)
+	}
+
+	if resp := m.call("mining.authorize", "worker", "wrong"); resp.Result != false {
+		t.Fatalf("authorized worker with wrong password: %v",
+			resp.Result)
+	}
+	if resp := m.call("mining.authorize", "worker", "secret"); resp.Result != true {
+		t.Fatalf("failed to authorize worker: %v", resp.Result)
+	}
+	return h, m
+}
+
+// setTemplate replaces the template served by the harness.
+func (h *testHarness) setTemplate(template *mining.BlockTemplate) {
+	h.mtx.Lock()
+	h.template = template
+	h.mtx.Unlock()
+}
+
+// submittedBlocks returns the blocks submitted by the server.
+func (h *testHarness) submittedBlocks() []*vtcutil.Block {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	return h.submitted
+}
+
+// TestStratumBlock mines a block with a scripted miner and ensures it is
+// submitted with the coinbase and header computed by the miner.
+func TestStratumBlock(t *testing.T) {
+	prevBlock := chainhash.Hash{0x01, 0x02, 0x03, 0x04, 0x05}
+	template := testTemplate(prevBlock, 0x207fffff, 4)
+	h, m := newTestHarness(t, template)
+	defer h.server.Stop()
+	defer m.conn.Close()
+
+	// Ensure the job builds on the template.
+	if m.job[8] != true {
+		t.Fatalf("first job does not clean previous jobs")
+	}
+	header := m.header(make([]byte, extraNonce2Size), 0)
+	if header.PrevBlock != prevBlock ||
+		header.Bits != template.Block.Header.Bits ||
+		header.Version != template.Block.Header.Version ||
+		!header.Timestamp.Equal(template.Block.Header.Timestamp) {
+
+		t.Fatalf("job header %+v does not match template", header)
+	}
+
+	// Shares from unauthorized workers and for unknown jobs are rejected.
+	extraNonce2 := []byte{0xde, 0xad, 0xbe, 0xef}
+	nonce := m.findNonce(extraNonce2, chaincfg.RegressionNetParams.PowLimit,
+		true)
+	resp := m.submit("other", extraNonce2, nonce)
+	if code := m.errorCode(resp); code != errUnauthorized.Code {
+		t.Fatalf("unexpected error code %d for unauthorized worker",
+			code)
+	}
+	resp = m.call("mining.submit", "worker", "bogus",
+		hex.EncodeToString(extraNonce2), m.job[7], uint32Hex(nonce))
+	if code := m.errorCode(resp); code != errJobNotFound.Code {
+		t.Fatalf("unexpected error code %d for unknown job", code)
+	}
+
+	// Submit a share which solves the block.
+	resp = m.submit("worker", extraNonce2, nonce)
+	if resp.Result != true {
+		t.Fatalf("share was rejected: %s", resp.Error)
+	}
+	blocks := h.submittedBlocks()
+	if len(blocks) != 1 {
+		t.Fatalf("got %d submitted blocks, want 1", len(blocks))
+	}
+	block := blocks[0].MsgBlock()
+	if block.BlockHash() != m.header(extraNonce2, nonce).BlockHash() {
+		t.Fatalf("submitted block does not match the share")
+	}
+	utxns := blocks[0].Transactions()
+	merkles := blockchain.BuildMerkleTreeStore(utxns, false)
+	if block.Header.MerkleRoot != *merkles[len(merkles)-1] {
+		t.Fatalf("submitted block has invalid merkle root")
+	}
+	coinbase := block.Transactions[0]
+	extraNonce := append(append([]byte(nil), m.extraNonce1...),
+		extraNonce2...)
+	if !bytes.Contains(coinbase.TxIn[0].SignatureScript, extraNonce) {
+		t.Fatalf("coinbase script %x does not contain extra nonce %x",
+			coinbase.TxIn[0].SignatureScript, extraNonce)
+	}
+	if len(coinbase.TxIn[0].Witness) != 1 {
+		t.Fatalf("coinbase witness was not preserved")
+	}
+	for i, tx := range block.Transactions[1:] {
+		if tx.TxHash() != template.Block.Transactions[i+1].TxHash() {
+			t.Fatalf("transaction %d does not match template", i+1)
+		}
+	}
+
+	// Duplicate shares are rejected.
+	resp = m.submit("worker", extraNonce2, nonce)
+	if code := m.errorCode(resp); code != errDuplicate.Code {
+		t.Fatalf("unexpected error code %d for duplicate share", code)
+	}
+
+	// A connected block pushes a new job which replaces older ones.
+	oldJob := m.job
+	h.setTemplate(testTemplate(chainhash.Hash{0x06}, 0x207fffff, 2))
+	h.server.HandleBlockchainNotification(&blockchain.Notification{
+		Type: blockchain.NTBlockConnected,
+	})
+	m.readNotification("mining.notify")
+	if m.job[0] == oldJob[0] || m.job[8] != true {
+		t.Fatalf("unexpected job after connected block: %v", m.job)
+	}
+	if header := m.header(extraNonce2, 0); header.PrevBlock != (chainhash.Hash{0x06}) {
+		t.Fatalf("new job builds on %v", header.PrevBlock)
+	}
+	resp = m.call("mining.submit", "worker", oldJob[0],
+		hex.EncodeToString(extraNonce2), oldJob[7], uint32Hex(nonce))
+	if code := m.errorCode(resp); code != errJobNotFound.Code {
+		t.Fatalf("unexpected error code %d for stale job", code)
+	}
+}
+
+// TestStratumShares ensures shares are checked against the share difficulty
+// and only shares which solve a block are submitted.
+func TestStratumShares(t *testing.T) {
+	// Use a network target far below the share target.
+	template := testTemplate(chainhash.Hash{0x01}, 0x1d00ffff, 3)
+	h, m := newTestHarness(t, template)
+	defer h.server.Stop()
+	defer m.conn.Close()
+
+	shareTarget := h.server.shareTarget(m.difficulty)
+	extraNonce2 := []byte{0x00, 0x00, 0x00, 0x01}
+	low := m.findNonce(extraNonce2, shareTarget, false)
+	resp := m.submit("worker", extraNonce2, low)
+	if code := m.errorCode(resp); code != errLowDifficulty.Code {
+		t.Fatalf("unexpected error code %d for low difficulty share",
+			code)
+	}
+
+	nonce := m.findNonce(extraNonce2, shareTarget, true)
+	resp = m.submit("worker", extraNonce2, nonce)
+	if resp.Result != true {
+		t.Fatalf("share was rejected: %s", resp.Error)
+	}
+
+	// Times outside the allowed range are rejected.
+	resp = m.call("mining.submit", "worker", m.job[0],
+		hex.EncodeToString(extraNonce2), uint32Hex(1400000000),
+		uint32Hex(nonce))
+	if code := m.errorCode(resp); code != errOther.Code {
+		t.Fatalf("unexpected error code %d for invalid time", code)
+	}
+
+	if blocks := h.submittedBlocks(); len(blocks) != 0 {
+		t.Fatalf("got %d submitted blocks, want 0", len(blocks))
+	}
+}
+
+// tempError is a temporary network error, such as returned by Accept when the
+// process is out of file descriptors.
+type tempError struct{}
+
+func (tempError) Error() string   { return "too many open files" }
+func (tempError) Timeout() bool   { return false }
+func (tempError) Temporary() bool { return true }
+
+// errListener is a listener which fails to accept connections with a temporary
+// error a fixed number of times and then with a permanent error.
+type errListener struct {
+	tempErrors int
+	accepts    []time.Time
+}
+
+// Accept records the time of the call and returns the next error.
+func (l *errListener) Accept() (net.Conn, error) {
+	l.accepts = append(l.accepts, time.Now())
+	if len(l.accepts) <= l.tempErrors {
+		return nil, tempError{}
+	}
+	return nil, errors.New("use of closed network connection")
+}
+
+// Close does nothing since the listener has no resources to release.
+func (l *errListener) Close() error { return nil }
+
+// Addr returns an unspecified TCP address.
+func (l *errListener) Addr() net.Addr { return &net.TCPAddr{} }
+
+// TestListenHandlerBackoff ensures the listener handler backs off on temporary
+// accept errors and stops on permanent ones.
+func TestListenHandlerBackoff(t *testing.T) {
+	l := &errListener{tempErrors: 4}
+	s := New(&Config{})
+	s.wg.Add(1)
+
+	done := make(chan struct{})
+	go func() {
+		s.listenHandler(l)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("listener handler did not stop on a permanent error")
+	}
+
+	if len(l.accepts) != l.tempErrors+1 {
+		t.Fatalf("got %d accepts, want %d", len(l.accepts),
+			l.tempErrors+1)
+	}
+	backoff := minAcceptBackoff
+	for i := 1; i < len(l.accepts); i++ {
+		if wait := l.accepts[i].Sub(l.accepts[i-1]); wait < backoff {
+			t.Fatalf("accept %d after %v, want at least %v", i,
+				wait, backoff)
+		}
+		backoff *= 2
+	}
+}
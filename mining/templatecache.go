@@ -0,0 +1,436 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mining
+
+import (
+	"bytes"
+	"container/heap"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/vertcoin/vtcd/blockchain"
+	"github.com/vertcoin/vtcd/chaincfg"
+	"github.com/vertcoin/vtcd/chaincfg/chainhash"
+	"github.com/vertcoin/vtcd/txscript"
+	"github.com/vertcoin/vtcd/wire"
+	"github.com/vertcoin/vtcutil"
+)
+
+const (
+	// maxCachedTemplates is the maximum number of block templates with
+	// different coinbase configurations the template cache keeps.  The
+	// oldest template is evicted when another one is added.
+	maxCachedTemplates = 16
+
+	// templateRebuildInterval is the interval after which a cached block
+	// template is rebuilt from the whole source pool instead of only being
+	// extended with new transactions.  This allows transactions which were
+	// skipped by incremental updates to be reconsidered and restores the
+	// ordering of the policy.
+	templateRebuildInterval = time.Minute
+)
+
+// cachedTemplate houses a block template kept by the template cache along with
+// the information needed to extend it with new transactions.
+type cachedTemplate struct {
+	template        *BlockTemplate
+	spec            *CoinbaseSpec
+	validPayAddress bool
+	txHashes        map[chainhash.Hash]struct{}
+	lastTxUpdate    time.Time
+	generated       time.Time
+}
+
+// TemplateCache provides block templates built by a block template generator
+// while avoiding the work of selecting transactions from the whole source pool
+// for every request.  The most recent template for each coinbase configuration
+// is cached until the best chain changes.  When transactions are added to the
+// source pool, the cached template is extended with the new transactions which
+// pay at least the minimum fee of the policy instead of being rebuilt.
+//
+// The templates returned by the cache are copies, so callers are free to
+// modify their header and coinbase transaction.
+type TemplateCache struct {
+	g *BlkTmplGenerator
+
+	mtx       sync.Mutex
+	bestHash  chainhash.Hash
+	templates map[string]*cachedTemplate
+	keys      []string
+}
+
+// NewTemplateCache returns a new template cache for the passed block template
+// generator.
+func NewTemplateCache(g *BlkTmplGenerator) *TemplateCache {
+	return &TemplateCache{
+		g:         g,
+		templates: make(map[string]*cachedTemplate),
+	}
+}
+
+// NewBlockTemplate returns a block template which pays the coinbase to the
+// passed address like the NewBlockTemplate method of the generator of the
+// cache.
+//
+// This function is safe for concurrent access.
+func (c *TemplateCache) NewBlockTemplate(payToAddress vtcutil.Address) (*BlockTemplate, error) {
+	spec, err := addrCoinbaseSpec(payToAddress)
+	if err != nil {
+		return nil, err
+	}
+	return c.blockTemplate(spec, payToAddress != nil)
+}
+
+// NewBlockTemplateWithCoinbase returns a block template with a coinbase created
+// from the passed spec like the NewBlockTemplateWithCoinbase method of the
+// generator of the cache.
+//
+// This function is safe for concurrent access.
+func (c *TemplateCache) NewBlockTemplateWithCoinbase(spec *CoinbaseSpec) (*BlockTemplate, error) {
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	return c.blockTemplate(spec, true)
+}
+
+// blockTemplate returns a copy of the cached block template for the passed
+// coinbase configuration after bringing it up to date with the best chain and
+// the source pool.
+func (c *TemplateCache) blockTemplate(spec *CoinbaseSpec, validPayAddress bool) (*BlockTemplate, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	// All cached templates are stale once the best chain changed.
+	best := c.g.BestSnapshot()
+	if best.Hash != c.bestHash {
+		c.templates = make(map[string]*cachedTemplate)
+		c.keys = c.keys[:0]
+		c.bestHash = best.Hash
+	}
+
+	// Note the time of the last source pool update before updating the
+	// template so no update is missed.
+	txSource := c.g.TxSource()
+	lastTxUpdate := txSource.LastUpdated()
+	key := spec.cacheKey(validPayAddress)
+	entry := c.templates[key]
+	switch {
+	case entry == nil || time.Since(entry.generated) >= templateRebuildInterval ||
+		!entry.txsAvailable(txSource):
+
+		template, err := c.g.newBlockTemplate(spec, validPayAddress)
+		if err != nil {
+			return nil, err
+		}
+		c.add(key, newCachedTemplate(template, spec, validPayAddress,
+			lastTxUpdate))
+		entry = c.templates[key]
+
+	case entry.lastTxUpdate != lastTxUpdate:
+		err := entry.extend(c.g, lastTxUpdate)
+		if err != nil {
+			log.Debugf("Rebuilding block template after failing to "+
+				"extend it: %v", err)
+			template, err := c.g.newBlockTemplate(spec,
+				validPayAddress)
+			if err != nil {
+				return nil, err
+			}
+			entry = newCachedTemplate(template, spec,
+				validPayAddress, lastTxUpdate)
+			c.templates[key] = entry
+		}
+	}
+
+	// Update the time of the copy since the cached template may have been
+	// created some time ago.
+	template := copyBlockTemplate(entry.template)
+	c.g.UpdateBlockTime(template.Block)
+	return template, nil
+}
+
+// add adds the passed entry to the cache under the passed key and evicts the
+// oldest entry when the cache is full.
+//
+// This function MUST be called with the cache lock held.
+func (c *TemplateCache) add(key string, entry *cachedTemplate) {
+	if _, ok := c.templates[key]; !ok {
+		if len(c.keys) >= maxCachedTemplates {
+			delete(c.templates, c.keys[0])
+			c.keys = c.keys[1:]
+		}
+		c.keys = append(c.keys, key)
+	}
+	c.templates[key] = entry
+}
+
+// newCachedTemplate returns a new cache entry for the passed freshly generated
+// block template.
+func newCachedTemplate(template *BlockTemplate, spec *CoinbaseSpec,
+	validPayAddress bool, lastTxUpdate time.Time) *cachedTemplate {
+
+	txns := template.Block.Transactions
+	txHashes := make(map[chainhash.Hash]struct{}, len(txns)-1)
+	for _, tx := range txns[1:] {
+		txHashes[tx.TxHash()] = struct{}{}
+	}
+	return &cachedTemplate{
+		template:        template,
+		spec:            spec,
+		validPayAddress: validPayAddress,
+		txHashes:        txHashes,
+		lastTxUpdate:    lastTxUpdate,
+		generated:       time.Now(),
+	}
+}
+
+// txsAvailable returns whether all transactions of the cached template are
+// still in the passed source pool.  A template which contains transactions
+// that were removed from the pool may conflict with the transactions added
+// since, so it must be rebuilt instead of extended.
+func (entry *cachedTemplate) txsAvailable(txSource TxSource) bool {
+	for hash := range entry.txHashes {
+		hash := hash
+		if !txSource.HaveTransaction(&hash) {
+			return false
+		}
+	}
+	return true
+}
+
+// extend adds the transactions which were added to the source pool since the
+// cached template was last updated to it.  An error is returned when the
+// template can't be extended, in which case it must be rebuilt.
+func (entry *cachedTemplate) extend(g *BlkTmplGenerator, lastTxUpdate time.Time) error {
+	template, added, err := g.extendBlockTemplate(entry.template,
+		entry.spec, entry.txHashes)
+	if err != nil {
+		return err
+	}
+	template.ValidPayAddress = entry.validPayAddress
+	entry.template = template
+	for _, tx := range added {
+		entry.txHashes[*tx.Hash()] = struct{}{}
+	}
+	entry.lastTxUpdate = lastTxUpdate
+	return nil
+}
+
+// cacheKey returns a key which identifies the coinbase configuration described
+// by the spec.
+func (spec *CoinbaseSpec) cacheKey(validPayAddress bool) string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%v|%q|", validPayAddress, spec.Flags)
+	for _, output := range spec.Outputs {
+		fmt.Fprintf(&buf, "%x:%d:%d,", output.PkScript, output.Amount,
+			output.Weight)
+	}
+	buf.WriteByte('|')
+	for _, commitment := range spec.Commitments {
+		buf.WriteString(hex.EncodeToString(commitment))
+		buf.WriteByte(',')
+	}
+	return buf.String()
+}
+
+// copyBlockTemplate returns a copy of the passed block template which may be
+// modified without affecting the original.  Only the transactions besides the
+// coinbase are shared since they are never modified.
+func copyBlockTemplate(template *BlockTemplate) *BlockTemplate {
+	msgBlock := &wire.MsgBlock{
+		Header:       template.Block.Header,
+		Transactions: make([]*wire.MsgTx, len(template.Block.Transactions)),
+	}
+	copy(msgBlock.Transactions, template.Block.Transactions)
+	msgBlock.Transactions[0] = msgBlock.Transactions[0].Copy()
+
+	templateCopy := *template
+	templateCopy.Block = msgBlock
+	templateCopy.Fees = append([]int64(nil), template.Fees...)
+	templateCopy.SigOpCosts = append([]int64(nil), template.SigOpCosts...)
+	return &templateCopy
+}
+
+// extendBlockTemplate returns a new block template which contains all
+// transactions of the passed template followed by transactions from the source
+// pool which are not in the passed set of included transactions and pay at
+// least the minimum fee of the policy.  Transactions are added in order of
+// their fee per kilobyte as long as the block limits of the policy allow it.
+// Transactions which depend on source pool transactions that are not in the
+// block are skipped.  The added transactions are returned along with the
+// template.
+//
+// The source pool does not contain conflicting transactions, so the passed
+// template must only contain transactions which are still in the source pool.
+func (g *BlkTmplGenerator) extendBlockTemplate(template *BlockTemplate,
+	spec *CoinbaseSpec, included map[chainhash.Hash]struct{}) (*BlockTemplate, []*vtcutil.Tx, error) {
+
+	best := g.chain.BestSnapshot()
+	nextBlockHeight := best.Height + 1
+	if template.Height != nextBlockHeight ||
+		template.Block.Header.PrevBlock != best.Hash {
+
+		return nil, nil, fmt.Errorf("block template for height %d is "+
+			"stale", template.Height)
+	}
+
+	segwitState, err := g.chain.ThresholdState(chaincfg.DeploymentSegwit)
+	if err != nil {
+		return nil, nil, err
+	}
+	segwitActive := segwitState == blockchain.ThresholdActive
+
+	// Queue the new transactions which pay enough fees by their fee per
+	// kilobyte including any fee delta.  Free transactions are left for the
+	// next time the template is rebuilt.
+	sourceTxns := g.txSource.MiningDescs()
+	priorityQueue := newTxPriorityQueue(len(sourceTxns), true)
+	for _, txDesc := range sourceTxns {
+		tx := txDesc.Tx
+		if _, ok := included[*tx.Hash()]; ok {
+			continue
+		}
+		if blockchain.IsCoinBase(tx) || (!segwitActive && tx.HasWitness()) {
+			continue
+		}
+		if !blockchain.IsFinalizedTransaction(tx, nextBlockHeight,
+			g.timeSource.AdjustedTime()) {
+
+			continue
+		}
+		if g.policy.CheckTxStandard != nil && g.policy.CheckTxStandard(tx,
+			nextBlockHeight, best.MedianTime) != nil {
+
+			continue
+		}
+		feePerKB := txDesc.FeePerKB
+		if txDesc.FeeDelta != 0 {
+			feePerKB = (txDesc.Fee + txDesc.FeeDelta) * 1000 /
+				int64(tx.MsgTx().SerializeSize())
+		}
+		if feePerKB < int64(g.policy.TxMinFreeFee) {
+			continue
+		}
+		heap.Push(priorityQueue, &txPrioItem{
+			tx:       tx,
+			fee:      txDesc.Fee,
+			feePerKB: feePerKB,
+		})
+	}
+	if priorityQueue.Len() == 0 {
+		return template, nil, nil
+	}
+
+	// Make the outputs of the transactions in the template available to the
+	// new transactions.
+	msgBlock := template.Block
+	blockUtxos := blockchain.NewUtxoViewpoint()
+	for _, msgTx := range msgBlock.Transactions[1:] {
+		blockUtxos.AddTxOuts(vtcutil.NewTx(msgTx), nextBlockHeight)
+	}
+
+	// The weight of the template already accounts for the witness
+	// commitment, so only room for a larger transaction count is reserved.
+	blockWeight := blockchain.GetBlockWeight(vtcutil.NewBlock(msgBlock)) +
+		wire.MaxVarIntPayload*blockchain.WitnessScaleFactor
+	blockSigOpCost := int64(0)
+	for _, sigOpCost := range template.SigOpCosts {
+		blockSigOpCost += sigOpCost
+	}
+	totalFees := -template.Fees[0]
+
+	blockTxns := make([]*vtcutil.Tx, 0, len(msgBlock.Transactions)+
+		priorityQueue.Len())
+	for _, msgTx := range msgBlock.Transactions {
+		blockTxns = append(blockTxns, vtcutil.NewTx(msgTx))
+	}
+	txFees := append([]int64(nil), template.Fees...)
+	txSigOpCosts := append([]int64(nil), template.SigOpCosts...)
+	var added []*vtcutil.Tx
+	for priorityQueue.Len() > 0 {
+		prioItem := heap.Pop(priorityQueue).(*txPrioItem)
+		tx := prioItem.tx
+
+		txWeight := blockchain.GetTransactionWeight(tx)
+		if blockWeight+txWeight >= int64(g.policy.BlockMaxWeight) {
+			continue
+		}
+
+		utxos, err := g.chain.FetchUtxoView(tx)
+		if err != nil {
+			log.Warnf("Unable to fetch utxo view for tx %s: %v",
+				tx.Hash(), err)
+			continue
+		}
+		mergeUtxoView(blockUtxos, utxos)
+
+		sigOpCost, err := blockchain.GetSigOpCost(tx, false, blockUtxos,
+			true, segwitActive)
+		if err != nil {
+			continue
+		}
+		if blockSigOpCost+int64(sigOpCost) > blockchain.MaxBlockSigOpsCost {
+			continue
+		}
+
+		// Transactions which spend outputs of source pool transactions
+		// that are not in the block fail here.
+		_, err = blockchain.CheckTransactionInputs(tx, nextBlockHeight,
+			blockUtxos, g.chainParams)
+		if err != nil {
+			log.Tracef("Skipping tx %s due to error in "+
+				"CheckTransactionInputs: %v", tx.Hash(), err)
+			continue
+		}
+		err = blockchain.ValidateTransactionScripts(tx, blockUtxos,
+			txscript.StandardVerifyFlags, g.sigCache,
+			g.hashCache)
+		if err != nil {
+			log.Tracef("Skipping tx %s due to error in "+
+				"ValidateTransactionScripts: %v", tx.Hash(), err)
+			continue
+		}
+		spendTransaction(blockUtxos, tx, nextBlockHeight)
+
+		blockTxns = append(blockTxns, tx)
+		blockWeight += txWeight
+		blockSigOpCost += int64(sigOpCost)
+		totalFees += prioItem.fee
+		txFees = append(txFees, prioItem.fee)
+		txSigOpCosts = append(txSigOpCosts, int64(sigOpCost))
+		added = append(added, tx)
+	}
+	if len(added) == 0 {
+		return template, nil, nil
+	}
+
+	// Replace the coinbase with a copy which pays the new fees and has no
+	// witness commitment since it is recreated for the new transactions.
+	coinbaseTx := msgBlock.Transactions[0].Copy()
+	coinbaseTx.TxOut = coinbaseTx.TxOut[:len(spec.Outputs)+
+		len(spec.Commitments)]
+	coinbaseTx.TxIn[0].Witness = nil
+	subsidy := blockchain.CalcBlockSubsidy(nextBlockHeight, g.chainParams)
+	err = spec.setCoinbaseValue(coinbaseTx, subsidy+totalFees)
+	if err != nil {
+		return nil, nil, err
+	}
+	blockTxns[0] = vtcutil.NewTx(coinbaseTx)
+	txFees[0] = -totalFees
+
+	newTemplate, err := g.assembleBlockTemplate(best, blockTxns, txFees,
+		txSigOpCosts, segwitActive)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	log.Debugf("Extended block template with %d transactions (%d "+
+		"transactions, %d in fees)", len(added), len(blockTxns),
+		totalFees)
+
+	return newTemplate, added, nil
+}
@@ -0,0 +1,528 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mining
+
+import (
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vertcoin/vtcd/blockchain"
+	"github.com/vertcoin/vtcd/chaincfg"
+	"github.com/vertcoin/vtcd/chaincfg/chainhash"
+	"github.com/vertcoin/vtcd/database"
+	_ "github.com/vertcoin/vtcd/database/ffldb"
+	"github.com/vertcoin/vtcd/txscript"
+	"github.com/vertcoin/vtcd/wire"
+	"github.com/vertcoin/vtcutil"
+)
+
+const (
+	// testSplitOutputs is the number of outputs the test harness splits a
+	// mature coinbase into so transactions can be created from them.
+	testSplitOutputs = 500
+
+	// testMinFeePerKB is the minimum fee per kilobyte of the policy of the
+	// test harness.
+	testMinFeePerKB = 1000
+)
+
+// fakeTxSource provides a transaction source for tests which holds the
+// transactions added to it in order.
+type fakeTxSource struct {
+	mtx         sync.Mutex
+	descs       []*TxDesc
+	lastUpdated time.Time
+}
+
+// Ensure the fakeTxSource type implements the TxSource interface.
+var _ TxSource = (*fakeTxSource)(nil)
+
+// LastUpdated returns the last time a transaction was added to or removed from
+// the source.
+func (s *fakeTxSource) LastUpdated() time.Time {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.lastUpdated
+}
+
+// MiningDescs returns the descriptors of all transactions in the source.
+func (s *fakeTxSource) MiningDescs() []*TxDesc {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return append([]*TxDesc(nil), s.descs...)
+}
+
+// HaveTransaction returns whether the transaction with the passed hash is in the
+// source.
+func (s *fakeTxSource) HaveTransaction(hash *chainhash.Hash) bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for _, desc := range s.descs {
+		if desc.Tx.Hash().IsEqual(hash) {
+			return true
+		}
+	}
+	return false
+}
+
+// add adds the passed transaction paying the passed fee to the source.
+func (s *fakeTxSource) add(tx *vtcutil.Tx, fee int64, height int32) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.descs = append(s.descs, &TxDesc{
+		Tx:       tx,
+		Added:    time.Now(),
+		Height:   height,
+		Fee:      fee,
+		FeePerKB: fee * 1000 / int64(tx.MsgTx().SerializeSize()),
+	})
+	s.touch()
+}
+
+// remove removes the transactions with the passed hashes from the source.
+func (s *fakeTxSource) remove(hashes ...*chainhash.Hash) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	descs := s.descs[:0]
+	for _, desc := range s.descs {
+		keep := true
+		for _, hash := range hashes {
+			if desc.Tx.Hash().IsEqual(hash) {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			descs = append(descs, desc)
+		}
+	}
+	s.descs = descs
+	s.touch()
+}
+
+// touch updates the last update time of the source so it differs from all
+// previous ones even when the clock did not advance.
+//
+// This function MUST be called with the source lock held.
+func (s *fakeTxSource) touch() {
+	now := time.Now()
+	if !now.After(s.lastUpdated) {
+		now = s.lastUpdated.Add(time.Nanosecond)
+	}
+	s.lastUpdated = now
+}
+
+// templateTestHarness provides a regression test chain with spendable outputs
+// along with a block template generator and a template cache using a fake
+// transaction source.
+type templateTestHarness struct {
+	chain     *blockchain.BlockChain
+	txSource  *fakeTxSource
+	generator *BlkTmplGenerator
+	cache     *TemplateCache
+	spendable []wire.OutPoint
+	outValue  int64
+	teardown  func()
+}
+
+// newTemplateTestHarness returns a new test harness with a chain in which a
+// mature coinbase was split into testSplitOutputs spendable outputs.
+func newTemplateTestHarness(tb testing.TB) *templateTestHarness {
+	dbPath, err := ioutil.TempDir("", "templatecache")
+	if err != nil {
+		tb.Fatalf("unable to create temp dir: %v", err)
+	}
+	params := chaincfg.RegressionNetParams
+	db, err := database.Create("ffldb", dbPath, params.Net)
+	if err != nil {
+		os.RemoveAll(dbPath)
+		tb.Fatalf("unable to create db: %v", err)
+	}
+	teardown := func() {
+		db.Close()
+		os.RemoveAll(dbPath)
+	}
+
+	timeSource := blockchain.NewMedianTime()
+	sigCache := txscript.NewSigCache(1000)
+	chain, err := blockchain.New(&blockchain.Config{
+		DB:          db,
+		ChainParams: &params,
+		TimeSource:  timeSource,
+		SigCache:    sigCache,
+	})
+	if err != nil {
+		teardown()
+		tb.Fatalf("unable to create chain: %v", err)
+	}
+
+	policy := Policy{
+		BlockMaxWeight:    blockchain.MaxBlockWeight - 4000,
+		BlockMaxSize:      blockchain.MaxBlockBaseSize - 1000,
+		BlockPrioritySize: 50000,
+		TxMinFreeFee:      testMinFeePerKB,
+	}
+	txSource := &fakeTxSource{}
+	generator := NewBlkTmplGenerator(&policy, &params, txSource, chain,
+		timeSource, sigCache, txscript.NewHashCache(1000))
+	h := &templateTestHarness{
+		chain:     chain,
+		txSource:  txSource,
+		generator: generator,
+		cache:     NewTemplateCache(generator),
+		teardown:  teardown,
+	}
+
+	// Mine enough blocks for the coinbase of the first one to mature and
+	// split it into many outputs.
+	for i := 0; i < int(params.CoinbaseMaturity)+1; i++ {
+		h.mineBlock(tb)
+	}
+	firstHash, err := chain.BlockHashByHeight(1)
+	if err != nil {
+		teardown()
+		tb.Fatalf("unable to fetch block: %v", err)
+	}
+	firstBlock, err := chain.BlockByHash(firstHash)
+	if err != nil {
+		teardown()
+		tb.Fatalf("unable to fetch block: %v", err)
+	}
+	coinbase := firstBlock.Transactions()[0]
+	splitFee := int64(100000)
+	h.outValue = (coinbase.MsgTx().TxOut[0].Value - splitFee) /
+		testSplitOutputs
+	splitTx := wire.NewMsgTx(wire.TxVersion)
+	splitTx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Hash: *coinbase.Hash()},
+		Sequence:         wire.MaxTxInSequenceNum,
+	})
+	for i := 0; i < testSplitOutputs; i++ {
+		splitTx.AddTxOut(wire.NewTxOut(h.outValue, opTrueScript))
+	}
+	split := vtcutil.NewTx(splitTx)
+	txSource.add(split, splitFee, chain.BestSnapshot().Height)
+	h.mineBlock(tb)
+	txSource.remove(split.Hash())
+	for i := uint32(0); i < testSplitOutputs; i++ {
+		h.spendable = append(h.spendable, wire.OutPoint{
+			Hash:  *split.Hash(),
+			Index: i,
+		})
+	}
+
+	return h
+}
+
+// opTrueScript is the public key script of the outputs created by the test
+// harness.  It can be spent with an empty signature script.
+var opTrueScript = []byte{txscript.OP_TRUE}
+
+// mineBlock solves a block from a new block template of the generator and
+// connects it to the chain.
+func (h *templateTestHarness) mineBlock(tb testing.TB) *wire.MsgBlock {
+	template, err := h.generator.NewBlockTemplate(nil)
+	if err != nil {
+		h.teardown()
+		tb.Fatalf("NewBlockTemplate: unexpected error: %v", err)
+	}
+	msgBlock := template.Block
+	target := blockchain.CompactToBig(msgBlock.Header.Bits)
+	for {
+		hash, err := msgBlock.Header.PowHash()
+		if err != nil {
+			h.teardown()
+			tb.Fatalf("PowHash: unexpected error: %v", err)
+		}
+		if blockchain.HashToBig(hash).Cmp(target) <= 0 {
+			break
+		}
+		msgBlock.Header.Nonce++
+	}
+	_, isOrphan, err := h.chain.ProcessBlock(vtcutil.NewBlock(msgBlock),
+		blockchain.BFNone)
+	if err != nil || isOrphan {
+		h.teardown()
+		tb.Fatalf("ProcessBlock: unexpected result - orphan %v, error %v",
+			isOrphan, err)
+	}
+	return msgBlock
+}
+
+// addTx adds a transaction which spends the next spendable output while paying
+// the passed fee to the source of the harness.
+func (h *templateTestHarness) addTx(tb testing.TB, fee int64) *vtcutil.Tx {
+	if len(h.spendable) == 0 {
+		h.teardown()
+		tb.Fatal("test harness ran out of spendable outputs")
+	}
+	msgTx := wire.NewMsgTx(wire.TxVersion)
+	msgTx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: h.spendable[0],
+		Sequence:         wire.MaxTxInSequenceNum,
+	})
+	msgTx.AddTxOut(wire.NewTxOut(h.outValue-fee, opTrueScript))
+	h.spendable = h.spendable[1:]
+	tx := vtcutil.NewTx(msgTx)
+	h.txSource.add(tx, fee, h.chain.BestSnapshot().Height)
+	return tx
+}
+
+// templateTxHashes returns the hashes of the non-coinbase transactions of the
+// passed template.
+func templateTxHashes(template *BlockTemplate) map[chainhash.Hash]struct{} {
+	hashes := make(map[chainhash.Hash]struct{})
+	for _, tx := range template.Block.Transactions[1:] {
+		hashes[tx.TxHash()] = struct{}{}
+	}
+	return hashes
+}
+
+// checkTemplateFees ensures the coinbase of the passed template pays the block
+// subsidy plus the fees of its transactions.
+func checkTemplateFees(t *testing.T, template *BlockTemplate, params *chaincfg.Params) {
+	totalFees := int64(0)
+	for _, fee := range template.Fees[1:] {
+		totalFees += fee
+	}
+	if template.Fees[0] != -totalFees {
+		t.Errorf("unexpected coinbase fee - got %d, want %d",
+			template.Fees[0], -totalFees)
+	}
+	coinbaseValue := int64(0)
+	for _, txOut := range template.Block.Transactions[0].TxOut {
+		coinbaseValue += txOut.Value
+	}
+	want := blockchain.CalcBlockSubsidy(template.Height, params) + totalFees
+	if coinbaseValue != want {
+		t.Errorf("unexpected coinbase value - got %d, want %d",
+			coinbaseValue, want)
+	}
+	if len(template.Fees) != len(template.Block.Transactions) ||
+		len(template.SigOpCosts) != len(template.Block.Transactions) {
+
+		t.Errorf("unexpected number of fees %d and sigop costs %d for "+
+			"%d transactions", len(template.Fees),
+			len(template.SigOpCosts), len(template.Block.Transactions))
+	}
+}
+
+// TestTemplateCache ensures the template cache serves copies of cached
+// templates, extends them with new transactions paying enough fees and
+// rebuilds them when needed.
+func TestTemplateCache(t *testing.T) {
+	h := newTemplateTestHarness(t)
+	defer h.teardown()
+	params := h.generator.chainParams
+	cache := h.cache
+
+	// Templates are served from the cache until the source changes and
+	// modifying a template does not affect the cached one.
+	template, err := cache.NewBlockTemplate(nil)
+	if err != nil {
+		t.Fatalf("NewBlockTemplate: unexpected error: %v", err)
+	}
+	template.Block.Transactions[0].TxOut[0].Value = 0
+	template.Block.Header.Nonce = 12345
+	cached, err := cache.NewBlockTemplate(nil)
+	if err != nil {
+		t.Fatalf("NewBlockTemplate: unexpected error: %v", err)
+	}
+	if cached.Block.Transactions[0].TxOut[0].Value == 0 ||
+		cached.Block.Header.Nonce == 12345 {
+
+		t.Fatal("modifying a template changed the cached template")
+	}
+	if len(cache.templates) != 1 {
+		t.Fatalf("unexpected number of cached templates - got %d, "+
+			"want 1", len(cache.templates))
+	}
+	key := addrSpecKey(t, nil)
+	generated := cache.templates[key].generated
+
+	// New transactions paying enough fees are added to the cached template
+	// while free ones are left for the next rebuild.
+	var paying []*vtcutil.Tx
+	for i := 0; i < 5; i++ {
+		paying = append(paying, h.addTx(t, int64(10000*(i+1))))
+	}
+	free := h.addTx(t, 0)
+	template, err = cache.NewBlockTemplate(nil)
+	if err != nil {
+		t.Fatalf("NewBlockTemplate: unexpected error: %v", err)
+	}
+	if cache.templates[key].generated != generated {
+		t.Fatal("template was rebuilt instead of extended")
+	}
+	hashes := templateTxHashes(template)
+	for _, tx := range paying {
+		if _, ok := hashes[*tx.Hash()]; !ok {
+			t.Errorf("extended template is missing tx %v", tx.Hash())
+		}
+	}
+	if _, ok := hashes[*free.Hash()]; ok {
+		t.Errorf("extended template contains free tx %v", free.Hash())
+	}
+	if len(hashes) != len(paying) {
+		t.Errorf("unexpected number of transactions - got %d, want %d",
+			len(hashes), len(paying))
+	}
+	checkTemplateFees(t, template, params)
+
+	// Templates for other coinbase configurations are cached separately.
+	spec := &CoinbaseSpec{
+		Outputs: []CoinbaseOutput{
+			{PkScript: opTrueScript, Amount: 1000},
+			{PkScript: []byte{txscript.OP_TRUE, txscript.OP_TRUE},
+				Weight: 1},
+		},
+		Commitments: [][]byte{{0x01, 0x02}},
+	}
+	specTemplate, err := cache.NewBlockTemplateWithCoinbase(spec)
+	if err != nil {
+		t.Fatalf("NewBlockTemplateWithCoinbase: unexpected error: %v",
+			err)
+	}
+	if len(cache.templates) != 2 {
+		t.Fatalf("unexpected number of cached templates - got %d, "+
+			"want 2", len(cache.templates))
+	}
+	extra := h.addTx(t, 20000)
+	specTemplate, err = cache.NewBlockTemplateWithCoinbase(spec)
+	if err != nil {
+		t.Fatalf("NewBlockTemplateWithCoinbase: unexpected error: %v",
+			err)
+	}
+	if _, ok := templateTxHashes(specTemplate)[*extra.Hash()]; !ok {
+		t.Errorf("extended template is missing tx %v", extra.Hash())
+	}
+	if specTemplate.Block.Transactions[0].TxOut[0].Value != 1000 ||
+		!specTemplate.ValidPayAddress {
+
+		t.Errorf("extended template does not follow coinbase spec")
+	}
+	checkTemplateFees(t, specTemplate, params)
+
+	// Removing a transaction of a cached template causes a rebuild.
+	h.txSource.remove(paying[0].Hash())
+	template, err = cache.NewBlockTemplate(nil)
+	if err != nil {
+		t.Fatalf("NewBlockTemplate: unexpected error: %v", err)
+	}
+	hashes = templateTxHashes(template)
+	if _, ok := hashes[*paying[0].Hash()]; ok {
+		t.Errorf("rebuilt template contains removed tx %v",
+			paying[0].Hash())
+	}
+	if _, ok := hashes[*extra.Hash()]; !ok {
+		t.Errorf("rebuilt template is missing tx %v", extra.Hash())
+	}
+	checkTemplateFees(t, template, params)
+
+	// A new best block invalidates all cached templates.
+	h.mineBlock(t)
+	template, err = cache.NewBlockTemplate(nil)
+	if err != nil {
+		t.Fatalf("NewBlockTemplate: unexpected error: %v", err)
+	}
+	best := h.chain.BestSnapshot()
+	if template.Height != best.Height+1 ||
+		template.Block.Header.PrevBlock != best.Hash {
+
+		t.Errorf("template does not extend the new best block")
+	}
+	if len(cache.templates) != 1 {
+		t.Errorf("unexpected number of cached templates - got %d, "+
+			"want 1", len(cache.templates))
+	}
+}
+
+// addrSpecKey returns the cache key of templates paying to the passed address.
+func addrSpecKey(tb testing.TB, addr vtcutil.Address) string {
+	spec, err := addrCoinbaseSpec(addr)
+	if err != nil {
+		tb.Fatalf("addrCoinbaseSpec: unexpected error: %v", err)
+	}
+	return spec.cacheKey(addr != nil)
+}
+
+// benchmarkPoolTxns is the number of transactions in the source pool of the
+// template benchmarks.
+const benchmarkPoolTxns = 400
+
+// BenchmarkNewBlockTemplate benchmarks creating a block template from the
+// source pool without the cache.
+func BenchmarkNewBlockTemplate(b *testing.B) {
+	h := newTemplateTestHarness(b)
+	defer h.teardown()
+	for i := 0; i < benchmarkPoolTxns; i++ {
+		h.addTx(b, int64(1000+i))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := h.generator.NewBlockTemplate(nil); err != nil {
+			b.Fatalf("NewBlockTemplate: unexpected error: %v", err)
+		}
+	}
+}
+
+// BenchmarkTemplateCacheHit benchmarks serving a cached block template when the
+// source pool did not change.
+func BenchmarkTemplateCacheHit(b *testing.B) {
+	h := newTemplateTestHarness(b)
+	defer h.teardown()
+	for i := 0; i < benchmarkPoolTxns; i++ {
+		h.addTx(b, int64(1000+i))
+	}
+	if _, err := h.cache.NewBlockTemplate(nil); err != nil {
+		b.Fatalf("NewBlockTemplate: unexpected error: %v", err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := h.cache.NewBlockTemplate(nil); err != nil {
+			b.Fatalf("NewBlockTemplate: unexpected error: %v", err)
+		}
+	}
+}
+
+// BenchmarkTemplateCacheExtend benchmarks extending a cached block template
+// with ten new transactions.
+func BenchmarkTemplateCacheExtend(b *testing.B) {
+	h := newTemplateTestHarness(b)
+	defer h.teardown()
+	for i := 0; i < benchmarkPoolTxns-10; i++ {
+		h.addTx(b, int64(1000+i))
+	}
+	template, err := h.generator.NewBlockTemplate(nil)
+	if err != nil {
+		b.Fatalf("NewBlockTemplate: unexpected error: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		h.addTx(b, int64(100000+i))
+	}
+	spec, err := addrCoinbaseSpec(nil)
+	if err != nil {
+		b.Fatalf("addrCoinbaseSpec: unexpected error: %v", err)
+	}
+	included := templateTxHashes(template)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, added, err := h.generator.extendBlockTemplate(template, spec,
+			included)
+		if err != nil {
+			b.Fatalf("extendBlockTemplate: unexpected error: %v", err)
+		}
+		if len(added) != 10 {
+			b.Fatalf("unexpected number of added transactions - "+
+				"got %d, want 10", len(added))
+		}
+	}
+}
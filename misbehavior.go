@@ -0,0 +1,233 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vertcoin/vtcd/connmgr"
+)
+
+// maxBanScoreHistory is the maximum number of ban score events kept for each
+// peer.  The oldest events are discarded first.
+const maxBanScoreHistory = 50
+
+// misbehavior identifies a kind of peer misbehavior which increases the ban
+// score of the peer.
+type misbehavior int
+
+// These constants define the kinds of misbehavior which are penalized.
+const (
+	// misbehaviorInvalidBlock is a block which fails validation.
+	misbehaviorInvalidBlock misbehavior = iota
+
+	// misbehaviorInvalidHeaders is a header which does not match a
+	// checkpoint.
+	misbehaviorInvalidHeaders
+
+	// misbehaviorUnconnectedHeaders is a header which does not connect to
+	// the previously received headers.
+	misbehaviorUnconnectedHeaders
+
+	// misbehaviorUnrequestedData is a block or headers message which was
+	// not requested.
+	misbehaviorUnrequestedData
+
+	// misbehaviorInvalidAddr is an addr message which does not contain any
+	// addresses.
+	misbehaviorInvalidAddr
+
+	// misbehaviorBadFilter is a filteradd or filterclear message sent
+	// without a loaded bloom filter.
+	misbehaviorBadFilter
+
+	// misbehaviorBloomDisabled is a bloom filter message sent although the
+	// bloom filter service is not offered.
+	misbehaviorBloomDisabled
+
+	// misbehaviorBadFeeFilter is a feefilter message with an invalid fee.
+	misbehaviorBadFeeFilter
+
+	// misbehaviorMempoolFlood is a mempool request.  It only has a
+	// transient weight so bursts of requests are penalized.
+	misbehaviorMempoolFlood
+
+	// misbehaviorGetDataFlood is a getdata request.  Its weight is scaled
+	// by the number of requested items relative to the maximum.
+	misbehaviorGetDataFlood
+
+	// numMisbehaviors is the number of kinds of misbehavior.  It MUST be
+	// the last entry.
+	numMisbehaviors
+)
+
+// banWeight is the amount by which a misbehavior increases the persistent and
+// transient parts of the ban score of a peer.
+type banWeight struct {
+	Persistent uint32
+	Transient  uint32
+}
+
+// scale returns the weight scaled by n/max.  The full weight is returned when n
+// is at least max.
+func (w banWeight) scale(n, max uint32) banWeight {
+	if n >= max {
+		return w
+	}
+	return banWeight{
+		Persistent: uint32(uint64(w.Persistent) * uint64(n) / uint64(max)),
+		Transient:  uint32(uint64(w.Transient) * uint64(n) / uint64(max)),
+	}
+}
+
+// misbehaviorInfo describes a kind of misbehavior in the catalogue.
+type misbehaviorInfo struct {
+	name   string
+	weight banWeight
+}
+
+// misbehaviorCatalogue holds the name and the default weight of each kind of
+// misbehavior.  The names are used to configure the weights and in RPC
+// results.
+var misbehaviorCatalogue = [numMisbehaviors]misbehaviorInfo{
+	misbehaviorInvalidBlock:       {"invalidblock", banWeight{100, 0}},
+	misbehaviorInvalidHeaders:     {"invalidheaders", banWeight{100, 0}},
+	misbehaviorUnconnectedHeaders: {"unconnectedheaders", banWeight{20, 0}},
+	misbehaviorUnrequestedData:    {"unrequesteddata", banWeight{20, 0}},
+	misbehaviorInvalidAddr:        {"invalidaddr", banWeight{20, 0}},
+	misbehaviorBadFilter:          {"badfilter", banWeight{100, 0}},
+	misbehaviorBloomDisabled:      {"bloomdisabled", banWeight{100, 0}},
+	misbehaviorBadFeeFilter:       {"badfeefilter", banWeight{100, 0}},
+	misbehaviorMempoolFlood:       {"mempoolflood", banWeight{0, 33}},
+	misbehaviorGetDataFlood:       {"getdataflood", banWeight{0, 99}},
+}
+
+// String returns the name of the misbehavior.
+func (m misbehavior) String() string {
+	if m < 0 || m >= numMisbehaviors {
+		return fmt.Sprintf("unknown misbehavior (%d)", int(m))
+	}
+	return misbehaviorCatalogue[m].name
+}
+
+// misbehaviorNames returns the names of all kinds of misbehavior.
+func misbehaviorNames() []string {
+	names := make([]string, 0, numMisbehaviors)
+	for _, info := range misbehaviorCatalogue {
+		names = append(names, info.name)
+	}
+	return names
+}
+
+// banWeights holds the weight of each kind of misbehavior.
+type banWeights [numMisbehaviors]banWeight
+
+// parseBanWeights returns the default weights of the misbehavior catalogue with
+// the passed overrides applied.  Each override is of the form
+// <misbehavior>:<persistent>:<transient>.
+func parseBanWeights(overrides []string) (banWeights, error) {
+	var weights banWeights
+	for m, info := range misbehaviorCatalogue {
+		weights[m] = info.weight
+	}
+
+	for _, override := range overrides {
+		parts := strings.Split(override, ":")
+		if len(parts) != 3 {
+			return weights, fmt.Errorf("ban weight '%s' is not of the "+
+				"form <misbehavior>:<persistent>:<transient>",
+				override)
+		}
+
+		m := misbehavior(-1)
+		for i, info := range misbehaviorCatalogue {
+			if info.name == parts[0] {
+				m = misbehavior(i)
+				break
+			}
+		}
+		if m == -1 {
+			return weights, fmt.Errorf("unknown misbehavior '%s' in "+
+				"ban weight '%s' -- valid misbehaviors are %s",
+				parts[0], override,
+				strings.Join(misbehaviorNames(), ", "))
+		}
+
+		persistent, err := strconv.ParseUint(parts[1], 10, 32)
+		if err != nil {
+			return weights, fmt.Errorf("invalid persistent weight "+
+				"in ban weight '%s': %v", override, err)
+		}
+		transient, err := strconv.ParseUint(parts[2], 10, 32)
+		if err != nil {
+			return weights, fmt.Errorf("invalid transient weight "+
+				"in ban weight '%s': %v", override, err)
+		}
+		weights[m] = banWeight{
+			Persistent: uint32(persistent),
+			Transient:  uint32(transient),
+		}
+	}
+	return weights, nil
+}
+
+// banScoreEvent records a single increase of the ban score of a peer.
+type banScoreEvent struct {
+	Time        time.Time
+	Misbehavior misbehavior
+	Reason      string
+	Weight      banWeight
+
+	// Score is the ban score of the peer after the increase.
+	Score uint32
+}
+
+// peerBanScore is the ban score of a peer along with the history of the events
+// which increased it.
+type peerBanScore struct {
+	connmgr.DynamicBanScore
+
+	historyMtx sync.Mutex
+	history    []banScoreEvent
+}
+
+// increase increases the ban score by the passed weight and records the event.
+// The resulting score is returned.
+//
+// This function is safe for concurrent access.
+func (s *peerBanScore) increase(m misbehavior, w banWeight, reason string) uint32 {
+	score := s.DynamicBanScore.Increase(w.Persistent, w.Transient)
+
+	s.historyMtx.Lock()
+	if len(s.history) >= maxBanScoreHistory {
+		copy(s.history, s.history[1:])
+		s.history = s.history[:len(s.history)-1]
+	}
+	s.history = append(s.history, banScoreEvent{
+		Time:        time.Now(),
+		Misbehavior: m,
+		Reason:      reason,
+		Weight:      w,
+		Score:       score,
+	})
+	s.historyMtx.Unlock()
+
+	return score
+}
+
+// History returns the recorded ban score events from oldest to newest.
+//
+// This function is safe for concurrent access.
+func (s *peerBanScore) History() []banScoreEvent {
+	s.historyMtx.Lock()
+	history := make([]banScoreEvent, len(s.history))
+	copy(history, s.history)
+	s.historyMtx.Unlock()
+	return history
+}
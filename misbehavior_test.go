@@ -0,0 +1,119 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+)
+
+// TestParseBanWeights ensures the catalogue defaults are used and that
+// overrides are applied and validated.
+func TestParseBanWeights(t *testing.T) {
+	weights, err := parseBanWeights(nil)
+	if err != nil {
+		t.Fatalf("unexpected error parsing defaults: %v", err)
+	}
+	for m, info := range misbehaviorCatalogue {
+		if weights[m] != info.weight {
+			t.Errorf("%s: got default weight %+v, want %+v",
+				misbehavior(m), weights[m], info.weight)
+		}
+	}
+
+	weights, err = parseBanWeights([]string{
+		"invalidaddr:50:0",
+		"mempoolflood:1:10",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error parsing overrides: %v", err)
+	}
+	if w := weights[misbehaviorInvalidAddr]; w != (banWeight{50, 0}) {
+		t.Errorf("unexpected invalidaddr weight %+v", w)
+	}
+	if w := weights[misbehaviorMempoolFlood]; w != (banWeight{1, 10}) {
+		t.Errorf("unexpected mempoolflood weight %+v", w)
+	}
+	if w := weights[misbehaviorInvalidBlock]; w != (banWeight{100, 0}) {
+		t.Errorf("unexpected invalidblock weight %+v", w)
+	}
+
+	invalid := []string{
+		"invalidaddr:50",
+		"invalidaddr:50:0:1",
+		"bogus:1:1",
+		"invalidaddr:-1:0",
+		"invalidaddr:1:x",
+		"invalidaddr:4294967296:0",
+	}
+	for _, override := range invalid {
+		if _, err := parseBanWeights([]string{override}); err == nil {
+			t.Errorf("%s: expected error", override)
+		}
+	}
+}
+
+// TestBanWeightScale ensures weights are scaled proportionally and capped at the
+// full weight.
+func TestBanWeightScale(t *testing.T) {
+	w := banWeight{Persistent: 10, Transient: 99}
+	tests := []struct {
+		n, max uint32
+		want   banWeight
+	}{
+		{0, 50000, banWeight{0, 0}},
+		{25000, 50000, banWeight{5, 49}},
+		{50000, 50000, w},
+		{60000, 50000, w},
+	}
+	for _, test := range tests {
+		if got := w.scale(test.n, test.max); got != test.want {
+			t.Errorf("scale(%d, %d): got %+v, want %+v", test.n,
+				test.max, got, test.want)
+		}
+	}
+}
+
+// TestPeerBanScoreHistory ensures each increase is recorded with the resulting
+// score and that only the most recent events are kept.
+func TestPeerBanScoreHistory(t *testing.T) {
+	var s peerBanScore
+	s.increase(misbehaviorUnrequestedData, banWeight{20, 0}, "unrequested block")
+	score := s.increase(misbehaviorMempoolFlood, banWeight{0, 33}, "mempool")
+	if score != 53 || s.Int() != 53 {
+		t.Fatalf("unexpected score %d", score)
+	}
+	persistent, transient := s.Components()
+	if persistent != 20 || transient != 33 {
+		t.Errorf("unexpected components %d and %d", persistent, transient)
+	}
+
+	history := s.History()
+	if len(history) != 2 {
+		t.Fatalf("unexpected history length %d", len(history))
+	}
+	if history[0].Misbehavior != misbehaviorUnrequestedData ||
+		history[0].Reason != "unrequested block" ||
+		history[0].Score != 20 {
+		t.Errorf("unexpected first event %+v", history[0])
+	}
+	if history[1].Misbehavior != misbehaviorMempoolFlood ||
+		history[1].Weight != (banWeight{0, 33}) ||
+		history[1].Score != 53 {
+		t.Errorf("unexpected second event %+v", history[1])
+	}
+
+	for i := 0; i < maxBanScoreHistory; i++ {
+		s.increase(misbehaviorInvalidAddr, banWeight{1, 0}, "addr")
+	}
+	history = s.History()
+	if len(history) != maxBanScoreHistory {
+		t.Fatalf("unexpected history length %d", len(history))
+	}
+	for _, event := range history {
+		if event.Misbehavior != misbehaviorInvalidAddr {
+			t.Fatalf("old event %+v was not discarded", event)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"time"
+)
+
+const (
+	// natGatewayPort is the port NAT-PMP and PCP servers listen on.
+	natGatewayPort = 5351
+
+	// natRequestAttempts is the number of times a NAT-PMP or PCP request is
+	// sent before giving up.
+	natRequestAttempts = 3
+
+	// natInitialTimeout is the time to wait for a response to the first
+	// NAT-PMP or PCP request.  It is doubled for every retransmission.
+	natInitialTimeout = 250 * time.Millisecond
+
+	// natMaxPacketSize is the maximum size of a NAT-PMP or PCP response.
+	natMaxPacketSize = 1100
+)
+
+var (
+	// errNATTimeout indicates the gateway did not respond to a NAT-PMP or
+	// PCP request.
+	errNATTimeout = errors.New("no response from gateway")
+
+	// privateNets are the IPv4 networks a NAT gateway is expected to use
+	// on the local side.
+	privateNets = []net.IPNet{
+		{IP: net.IPv4(10, 0, 0, 0), Mask: net.CIDRMask(8, 32)},
+		{IP: net.IPv4(172, 16, 0, 0), Mask: net.CIDRMask(12, 32)},
+		{IP: net.IPv4(192, 168, 0, 0), Mask: net.CIDRMask(16, 32)},
+	}
+)
+
+// discoverNAT tries UPnP, NAT-PMP and PCP in turn and returns a NAT for the
+// first protocol supported by the local gateway.
+func discoverNAT() (NAT, error) {
+	nat, err := Discover()
+	if err == nil {
+		return nat, nil
+	}
+	srvrLog.Debugf("UPnP discovery failed: %v", err)
+
+	gateways := potentialGateways()
+	for _, gw := range gateways {
+		addr := net.JoinHostPort(gw.String(), strconv.Itoa(natGatewayPort))
+		nat, err := discoverNATPMP(addr)
+		if err == nil {
+			return nat, nil
+		}
+		srvrLog.Debugf("NAT-PMP discovery via %s failed: %v", addr, err)
+	}
+	for _, gw := range gateways {
+		addr := net.JoinHostPort(gw.String(), strconv.Itoa(natGatewayPort))
+		nat, err := discoverPCP(addr)
+		if err == nil {
+			return nat, nil
+		}
+		srvrLog.Debugf("PCP discovery via %s failed: %v", addr, err)
+	}
+
+	return nil, errors.New("no UPnP, NAT-PMP or PCP gateway found")
+}
+
+// potentialGateways returns the likely addresses of the gateway for each
+// private IPv4 network the host is attached to.  Routers almost always use the
+// first address of the network, so that is assumed to be the gateway.
+func potentialGateways() []net.IP {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil
+	}
+
+	var gateways []net.IP
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok {
+				continue
+			}
+			ip := ipNet.IP.To4()
+			if ip == nil || !isPrivateIPv4(ip) {
+				continue
+			}
+			gw := ip.Mask(ipNet.Mask)
+			gw[3] |= 0x01
+			if !gw.Equal(ip) {
+				gateways = append(gateways, gw)
+			}
+		}
+	}
+	return gateways
+}
+
+// isPrivateIPv4 returns whether or not the passed address is part of one of the
+// private IPv4 networks.
+func isPrivateIPv4(ip net.IP) bool {
+	for i := range privateNets {
+		if privateNets[i].Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// natRequest sends the request over the connection and waits for a response
+// that is accepted by the passed function.  The request is retransmitted with
+// an increasing timeout when no response is received.
+func natRequest(conn net.Conn, req []byte, accept func([]byte) bool) ([]byte, error) {
+	buf := make([]byte, natMaxPacketSize)
+	timeout := natInitialTimeout
+	for i := 0; i < natRequestAttempts; i++ {
+		if _, err := conn.Write(req); err != nil {
+			return nil, err
+		}
+		if err := conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+			return nil, err
+		}
+		for {
+			n, err := conn.Read(buf)
+			if err != nil {
+				if nerr, ok := err.(net.Error); ok && nerr.Timeout() {
+					break
+				}
+				return nil, err
+			}
+			if accept(buf[:n]) {
+				resp := make([]byte, n)
+				copy(resp, buf[:n])
+				return resp, nil
+			}
+		}
+		timeout *= 2
+	}
+	return nil, errNATTimeout
+}
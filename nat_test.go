@@ -0,0 +1,348 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeUDPServer is a local UDP stand-in for a gateway which answers each
+// request using the handler.  No response is sent when the handler returns nil.
+type fakeUDPServer struct {
+	conn    net.PacketConn
+	handler func(req []byte) []byte
+	done    chan struct{}
+}
+
+// newFakeUDPServer starts a UDP server on the loopback interface.
+func newFakeUDPServer(t *testing.T, handler func(req []byte) []byte) *fakeUDPServer {
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %v", err)
+	}
+	s := &fakeUDPServer{
+		conn:    conn,
+		handler: handler,
+		done:    make(chan struct{}),
+	}
+	go s.serve()
+	return s
+}
+
+// serve answers requests until the server is closed.
+func (s *fakeUDPServer) serve() {
+	defer close(s.done)
+	buf := make([]byte, natMaxPacketSize)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		req := make([]byte, n)
+		copy(req, buf[:n])
+		if resp := s.handler(req); resp != nil {
+			s.conn.WriteTo(resp, addr)
+		}
+	}
+}
+
+// Addr returns the address the server is listening on.
+func (s *fakeUDPServer) Addr() string {
+	return s.conn.LocalAddr().String()
+}
+
+// Close stops the server.
+func (s *fakeUDPServer) Close() {
+	s.conn.Close()
+	<-s.done
+}
+
+// fakeMapping records a mapping request received by a fake gateway.
+type fakeMapping struct {
+	op           byte
+	internalPort uint16
+	externalPort uint16
+	lifetime     uint32
+	clientIP     net.IP
+}
+
+// TestNATPMP ensures the NAT-PMP backend queries the external address and adds
+// and removes port mappings.
+func TestNATPMP(t *testing.T) {
+	var mtx sync.Mutex
+	var mappings []fakeMapping
+	s := newFakeUDPServer(t, func(req []byte) []byte {
+		if len(req) < 2 || req[0] != natPMPVersion {
+			return nil
+		}
+		switch req[1] {
+		case natPMPOpExternalAddress:
+			return []byte{0, 128, 0, 0, 0, 0, 0, 1, 203, 0, 113, 5}
+
+		case natPMPOpMapUDP:
+			// Refuse UDP mappings.
+			return []byte{0, 129, 0, 2, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0}
+
+		case natPMPOpMapTCP:
+			m := fakeMapping{
+				op:           req[1],
+				internalPort: binary.BigEndian.Uint16(req[4:6]),
+				externalPort: binary.BigEndian.Uint16(req[6:8]),
+				lifetime:     binary.BigEndian.Uint32(req[8:12]),
+			}
+			mtx.Lock()
+			mappings = append(mappings, m)
+			mtx.Unlock()
+
+			// Map a different external port than requested.
+			resp := make([]byte, 16)
+			resp[1] = 130
+			copy(resp[8:10], req[4:6])
+			if m.lifetime != 0 {
+				binary.BigEndian.PutUint16(resp[10:12],
+					m.externalPort+1)
+			}
+			copy(resp[12:16], req[8:12])
+			return resp
+		}
+		return nil
+	})
+	defer s.Close()
+
+	nat, err := discoverNATPMP(s.Addr())
+	if err != nil {
+		t.Fatalf("unable to discover NAT-PMP: %v", err)
+	}
+	ip, err := nat.GetExternalAddress()
+	if err != nil {
+		t.Fatalf("unable to get external address: %v", err)
+	}
+	if !ip.Equal(net.IPv4(203, 0, 113, 5)) {
+		t.Errorf("unexpected external address %v", ip)
+	}
+
+	port, err := nat.AddPortMapping("tcp", 5889, 5889, "vtcd", 1200)
+	if err != nil {
+		t.Fatalf("unable to add port mapping: %v", err)
+	}
+	if port != 5890 {
+		t.Errorf("unexpected mapped port %d", port)
+	}
+	if err := nat.DeletePortMapping("tcp", 5889, 5889); err != nil {
+		t.Fatalf("unable to delete port mapping: %v", err)
+	}
+	if _, err := nat.AddPortMapping("udp", 5889, 5889, "vtcd", 1200); err == nil {
+		t.Errorf("refused mapping did not return an error")
+	}
+	if _, err := nat.AddPortMapping("sctp", 5889, 5889, "vtcd", 1200); err == nil {
+		t.Errorf("unsupported protocol did not return an error")
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	want := []fakeMapping{
+		{op: natPMPOpMapTCP, internalPort: 5889, externalPort: 5889, lifetime: 1200},
+		{op: natPMPOpMapTCP, internalPort: 5889},
+	}
+	if fmt.Sprint(mappings) != fmt.Sprint(want) {
+		t.Errorf("unexpected mapping requests %v, want %v", mappings, want)
+	}
+}
+
+// TestPCP ensures the PCP backend announces itself and adds and removes port
+// mappings, learning the external address from the mapping response.
+func TestPCP(t *testing.T) {
+	var mtx sync.Mutex
+	var mappings []fakeMapping
+	externalIP := net.IPv4(203, 0, 113, 7)
+	s := newFakeUDPServer(t, func(req []byte) []byte {
+		if len(req) < pcpHeaderLen || req[0] != pcpVersion {
+			return nil
+		}
+		resp := make([]byte, len(req))
+		resp[0] = pcpVersion
+		resp[1] = req[1] | pcpResponseBit
+		copy(resp[4:8], req[4:8])
+		switch req[1] {
+		case pcpOpAnnounce:
+			return resp
+
+		case pcpOpMap:
+			if len(req) < pcpHeaderLen+pcpMapLen {
+				return nil
+			}
+			data := req[pcpHeaderLen:]
+			m := fakeMapping{
+				op:           data[12],
+				internalPort: binary.BigEndian.Uint16(data[16:18]),
+				externalPort: binary.BigEndian.Uint16(data[18:20]),
+				lifetime:     binary.BigEndian.Uint32(req[4:8]),
+				clientIP:     net.IP(append([]byte(nil), req[8:24]...)),
+			}
+			mtx.Lock()
+			mappings = append(mappings, m)
+			mtx.Unlock()
+
+			copy(resp[pcpHeaderLen:], data[:16])
+			if m.op == pcpProtoUDP {
+				// Refuse UDP mappings with NOT_AUTHORIZED.
+				resp[3] = 2
+				return resp
+			}
+			if m.lifetime != 0 {
+				binary.BigEndian.PutUint16(resp[pcpHeaderLen+18:],
+					m.externalPort+1)
+				copy(resp[pcpHeaderLen+20:], externalIP.To16())
+			}
+			return resp
+		}
+		return nil
+	})
+	defer s.Close()
+
+	nat, err := discoverPCP(s.Addr())
+	if err != nil {
+		t.Fatalf("unable to discover PCP: %v", err)
+	}
+	if _, err := nat.GetExternalAddress(); err == nil {
+		t.Errorf("external address known before adding a mapping")
+	}
+
+	port, err := nat.AddPortMapping("tcp", 5889, 5889, "vtcd", 1200)
+	if err != nil {
+		t.Fatalf("unable to add port mapping: %v", err)
+	}
+	if port != 5890 {
+		t.Errorf("unexpected mapped port %d", port)
+	}
+	ip, err := nat.GetExternalAddress()
+	if err != nil {
+		t.Fatalf("unable to get external address: %v", err)
+	}
+	if !ip.Equal(externalIP) {
+		t.Errorf("unexpected external address %v", ip)
+	}
+	if err := nat.DeletePortMapping("tcp", 5889, 5889); err != nil {
+		t.Fatalf("unable to delete port mapping: %v", err)
+	}
+	if _, err := nat.AddPortMapping("udp", 5889, 5889, "vtcd", 1200); err == nil {
+		t.Errorf("refused mapping did not return an error")
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	clientIP := net.IPv4(127, 0, 0, 1)
+	want := []fakeMapping{
+		{op: pcpProtoTCP, internalPort: 5889, externalPort: 5889,
+			lifetime: 1200, clientIP: clientIP},
+		{op: pcpProtoTCP, internalPort: 5889, clientIP: clientIP},
+		{op: pcpProtoUDP, internalPort: 5889, externalPort: 5889,
+			lifetime: 1200, clientIP: clientIP},
+	}
+	if fmt.Sprint(mappings) != fmt.Sprint(want) {
+		t.Errorf("unexpected mapping requests %v, want %v", mappings, want)
+	}
+}
+
+// TestUPnP ensures the UPnP backend discovers a gateway via SSDP and issues
+// the expected SOAP requests to its WANIPConnection service.
+func TestUPnP(t *testing.T) {
+	const rootXML = `<?xml version="1.0"?>
+<root xmlns="urn:schemas-upnp-org:device-1-0">
+<specVersion><major>1</major><minor>0</minor></specVersion>
+<device>
+<deviceType>urn:schemas-upnp-org:device:InternetGatewayDevice:1</deviceType>
+<deviceList><device>
+<deviceType>urn:schemas-upnp-org:device:WANDevice:1</deviceType>
+<deviceList><device>
+<deviceType>urn:schemas-upnp-org:device:WANConnectionDevice:1</deviceType>
+<serviceList><service>
+<serviceType>urn:schemas-upnp-org:service:WANIPConnection:1</serviceType>
+<controlURL>/ctl</controlURL>
+</service></serviceList>
+</device></deviceList>
+</device></deviceList>
+</device>
+</root>`
+
+	var mtx sync.Mutex
+	var actions []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/root.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, rootXML)
+	})
+	mux.HandleFunc("/ctl", func(w http.ResponseWriter, r *http.Request) {
+		action := r.Header.Get("SOAPAction")
+		action = strings.Trim(action[strings.Index(action, "#")+1:], `"`)
+		body, _ := ioutil.ReadAll(r.Body)
+		if action == "AddPortMapping" &&
+			!strings.Contains(string(body), "<NewLeaseDuration>1200<") {
+			http.Error(w, "bad lease", http.StatusBadRequest)
+			return
+		}
+		mtx.Lock()
+		actions = append(actions, action)
+		mtx.Unlock()
+
+		var reply string
+		if action == "GetExternalIPAddress" {
+			reply = "<u:GetExternalIPAddressResponse>" +
+				"<NewExternalIPAddress>203.0.113.9</NewExternalIPAddress>" +
+				"</u:GetExternalIPAddressResponse>"
+		}
+		fmt.Fprintf(w, `<?xml version="1.0"?><s:Envelope `+
+			`xmlns:s="http://schemas.xmlsoap.org/soap/envelope/">`+
+			`<s:Body>%s</s:Body></s:Envelope>`, reply)
+	})
+	httpServer := httptest.NewServer(mux)
+	defer httpServer.Close()
+
+	s := newFakeUDPServer(t, func(req []byte) []byte {
+		if !strings.HasPrefix(string(req), "M-SEARCH * HTTP/1.1\r\n") {
+			return nil
+		}
+		return []byte("HTTP/1.1 200 OK\r\n" +
+			"ST: urn:schemas-upnp-org:device:InternetGatewayDevice:1\r\n" +
+			"LOCATION: " + httpServer.URL + "/root.xml\r\n\r\n")
+	})
+	defer s.Close()
+
+	nat, err := discoverUPnP(s.Addr())
+	if err != nil {
+		t.Fatalf("unable to discover UPnP: %v", err)
+	}
+	port, err := nat.AddPortMapping("tcp", 5889, 5889, "vtcd", 1200)
+	if err != nil {
+		t.Fatalf("unable to add port mapping: %v", err)
+	}
+	if port != 5889 {
+		t.Errorf("unexpected mapped port %d", port)
+	}
+	ip, err := nat.GetExternalAddress()
+	if err != nil {
+		t.Fatalf("unable to get external address: %v", err)
+	}
+	if !ip.Equal(net.IPv4(203, 0, 113, 9)) {
+		t.Errorf("unexpected external address %v", ip)
+	}
+	if err := nat.DeletePortMapping("tcp", 5889, 5889); err != nil {
+		t.Fatalf("unable to delete port mapping: %v", err)
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	want := "[AddPortMapping GetExternalIPAddress DeletePortMapping]"
+	if fmt.Sprint(actions) != want {
+		t.Errorf("unexpected actions %v, want %v", actions, want)
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+)
+
+const (
+	// natPMPVersion is the NAT-PMP protocol version.
+	natPMPVersion = 0
+
+	// These constants define the NAT-PMP opcodes.  Responses use the
+	// request opcode plus natPMPResponseOp.
+	natPMPOpExternalAddress = 0
+	natPMPOpMapUDP          = 1
+	natPMPOpMapTCP          = 2
+	natPMPResponseOp        = 128
+
+	// natPMPResultSuccess is the NAT-PMP result code of a successful
+	// request.
+	natPMPResultSuccess = 0
+)
+
+// natPMPNAT implements the NAT interface using the NAT Port Mapping Protocol
+// described in RFC 6886.
+type natPMPNAT struct {
+	gateway string
+}
+
+// Ensure natPMPNAT implements the NAT interface.
+var _ NAT = (*natPMPNAT)(nil)
+
+// discoverNATPMP returns a NAT for the NAT-PMP gateway at the given address
+// when it responds to an external address request.
+func discoverNATPMP(gateway string) (*natPMPNAT, error) {
+	n := &natPMPNAT{gateway: gateway}
+	if _, err := n.GetExternalAddress(); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// request sends the request to the gateway and returns a successful response
+// of at least the given length.
+func (n *natPMPNAT) request(req []byte, respLen int) ([]byte, error) {
+	conn, err := net.Dial("udp", n.gateway)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	op := req[1] + natPMPResponseOp
+	resp, err := natRequest(conn, req, func(b []byte) bool {
+		return len(b) >= respLen && b[0] == natPMPVersion && b[1] == op
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := binary.BigEndian.Uint16(resp[2:4])
+	if result != natPMPResultSuccess {
+		return nil, fmt.Errorf("NAT-PMP request failed with result "+
+			"code %d", result)
+	}
+	return resp, nil
+}
+
+// Name implements the NAT interface by returning the name of the protocol.
+func (n *natPMPNAT) Name() string {
+	return "NAT-PMP"
+}
+
+// GetExternalAddress implements the NAT interface by requesting the external
+// IPv4 address from the gateway.
+func (n *natPMPNAT) GetExternalAddress() (net.IP, error) {
+	resp, err := n.request([]byte{natPMPVersion, natPMPOpExternalAddress}, 12)
+	if err != nil {
+		return nil, err
+	}
+	return net.IPv4(resp[8], resp[9], resp[10], resp[11]), nil
+}
+
+// mapPort sends a mapping request to the gateway and returns the mapped
+// external port.  A lifetime of zero removes the mapping.
+func (n *natPMPNAT) mapPort(protocol string, externalPort, internalPort, lifetime int) (int, error) {
+	var op byte
+	switch protocol {
+	case "udp":
+		op = natPMPOpMapUDP
+	case "tcp":
+		op = natPMPOpMapTCP
+	default:
+		return 0, fmt.Errorf("unsupported protocol %q", protocol)
+	}
+
+	req := make([]byte, 12)
+	req[0] = natPMPVersion
+	req[1] = op
+	binary.BigEndian.PutUint16(req[4:6], uint16(internalPort))
+	binary.BigEndian.PutUint16(req[6:8], uint16(externalPort))
+	binary.BigEndian.PutUint32(req[8:12], uint32(lifetime))
+	resp, err := n.request(req, 16)
+	if err != nil {
+		return 0, err
+	}
+	return int(binary.BigEndian.Uint16(resp[10:12])), nil
+}
+
+// AddPortMapping implements the NAT interface by requesting a mapping of the
+// external port to the internal port for timeout seconds.  The gateway may map
+// a different external port, which is returned.
+func (n *natPMPNAT) AddPortMapping(protocol string, externalPort, internalPort int, description string, timeout int) (int, error) {
+	return n.mapPort(protocol, externalPort, internalPort, timeout)
+}
+
+// DeletePortMapping implements the NAT interface by removing the mapping for
+// the internal port.
+func (n *natPMPNAT) DeletePortMapping(protocol string, externalPort, internalPort int) error {
+	_, err := n.mapPort(protocol, 0, internalPort, 0)
+	return err
+}
@@ -17,7 +17,8 @@ var activeNetParams = &mainNetParams
 // network and test networks.
 type params struct {
 	*chaincfg.Params
-	rpcPort string
+	rpcPort  string
+	grpcPort string
 }
 
 // mainNetParams contains parameters specific to the main network
@@ -27,16 +28,18 @@ type params struct {
 // it does not handle on to vtcd.  This approach allows the wallet process
 // to emulate the full reference implementation RPC API.
 var mainNetParams = params{
-	Params:  &chaincfg.VertcoinParams,
-	rpcPort: "5888",
+	Params:   &chaincfg.VertcoinParams,
+	rpcPort:  "5888",
+	grpcPort: "5890",
 }
 
 // testNet4Params contains parameters specific to the test network (version 4)
 // (wire.TestNet4).  NOTE: The RPC port is intentionally different than the
 // reference implementation - see the mainNetParams comment for details.
 var testNet4Params = params{
-	Params:  &chaincfg.VertcoinTestNetParams,
-	rpcPort: "15888",
+	Params:   &chaincfg.VertcoinTestNetParams,
+	rpcPort:  "15888",
+	grpcPort: "15890",
 }
 
 // regressionNetParams contains parameters specific to the regression test
@@ -44,8 +47,9 @@ var testNet4Params = params{
 // than the reference implementation - see the mainNetParams comment for
 // details.
 var regressionNetParams = params{
-	Params:  &chaincfg.RegressionNetParams,
-	rpcPort: "19334",
+	Params:   &chaincfg.RegressionNetParams,
+	rpcPort:  "19334",
+	grpcPort: "19336",
 }
 
 // netName returns the name used when referring to a bitcoin network.  At the
@@ -0,0 +1,164 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+)
+
+const (
+	// pcpVersion is the PCP protocol version.
+	pcpVersion = 2
+
+	// These constants define the PCP opcodes.  Responses have the
+	// pcpResponseBit set.
+	pcpOpAnnounce  = 0
+	pcpOpMap       = 1
+	pcpResponseBit = 0x80
+
+	// pcpResultSuccess is the PCP result code of a successful request.
+	pcpResultSuccess = 0
+
+	// pcpHeaderLen and pcpMapLen are the lengths of the common header and
+	// of the MAP opcode data respectively.
+	pcpHeaderLen = 24
+	pcpMapLen    = 36
+
+	// pcpNonceLen is the length of the mapping nonce.
+	pcpNonceLen = 12
+
+	// These constants define the IANA protocol numbers used in MAP
+	// requests.
+	pcpProtoTCP = 6
+	pcpProtoUDP = 17
+)
+
+// pcpNAT implements the NAT interface using the Port Control Protocol described
+// in RFC 6887.
+type pcpNAT struct {
+	gateway    string
+	nonce      [pcpNonceLen]byte
+	externalIP net.IP
+}
+
+// Ensure pcpNAT implements the NAT interface.
+var _ NAT = (*pcpNAT)(nil)
+
+// discoverPCP returns a NAT for the PCP server at the given address when it
+// responds to an announce request.
+func discoverPCP(gateway string) (*pcpNAT, error) {
+	n := &pcpNAT{gateway: gateway}
+	if _, err := rand.Read(n.nonce[:]); err != nil {
+		return nil, err
+	}
+	if _, err := n.request(pcpOpAnnounce, 0, nil); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// request sends a request with the given opcode, lifetime and opcode data to
+// the server and returns the opcode data of a successful response.
+func (n *pcpNAT) request(op byte, lifetime uint32, data []byte) ([]byte, error) {
+	conn, err := net.Dial("udp", n.gateway)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// The client address must be the source address of the request.
+	clientIP := conn.LocalAddr().(*net.UDPAddr).IP.To16()
+
+	req := make([]byte, pcpHeaderLen+len(data))
+	req[0] = pcpVersion
+	req[1] = op
+	binary.BigEndian.PutUint32(req[4:8], lifetime)
+	copy(req[8:24], clientIP)
+	copy(req[pcpHeaderLen:], data)
+
+	resp, err := natRequest(conn, req, func(b []byte) bool {
+		if len(b) < pcpHeaderLen+len(data) || b[0] != pcpVersion ||
+			b[1] != op|pcpResponseBit {
+			return false
+		}
+		// Responses to MAP requests must echo the mapping nonce.
+		return op != pcpOpMap ||
+			bytes.Equal(b[pcpHeaderLen:pcpHeaderLen+pcpNonceLen],
+				n.nonce[:])
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result := resp[3]; result != pcpResultSuccess {
+		return nil, fmt.Errorf("PCP request failed with result code %d",
+			result)
+	}
+	return resp[pcpHeaderLen:], nil
+}
+
+// mapPort sends a MAP request to the server and returns the assigned external
+// port.  A lifetime of zero removes the mapping.
+func (n *pcpNAT) mapPort(protocol string, externalPort, internalPort, lifetime int) (int, error) {
+	var proto byte
+	switch protocol {
+	case "udp":
+		proto = pcpProtoUDP
+	case "tcp":
+		proto = pcpProtoTCP
+	default:
+		return 0, fmt.Errorf("unsupported protocol %q", protocol)
+	}
+
+	data := make([]byte, pcpMapLen)
+	copy(data[0:12], n.nonce[:])
+	data[12] = proto
+	binary.BigEndian.PutUint16(data[16:18], uint16(internalPort))
+	binary.BigEndian.PutUint16(data[18:20], uint16(externalPort))
+	copy(data[20:36], net.IPv4zero.To16())
+	resp, err := n.request(pcpOpMap, uint32(lifetime), data)
+	if err != nil {
+		return 0, err
+	}
+
+	if lifetime != 0 {
+		n.externalIP = net.IP(append([]byte(nil), resp[20:36]...))
+	}
+	return int(binary.BigEndian.Uint16(resp[18:20])), nil
+}
+
+// Name implements the NAT interface by returning the name of the protocol.
+func (n *pcpNAT) Name() string {
+	return "PCP"
+}
+
+// GetExternalAddress implements the NAT interface by returning the external
+// address assigned to the most recent port mapping.  PCP has no request for
+// the external address, so it is only known once a mapping was added.
+func (n *pcpNAT) GetExternalAddress() (net.IP, error) {
+	if n.externalIP == nil {
+		return nil, errors.New("PCP external address is unknown until " +
+			"a port mapping is added")
+	}
+	return n.externalIP, nil
+}
+
+// AddPortMapping implements the NAT interface by requesting a mapping of the
+// external port to the internal port for timeout seconds.  The server may
+// assign a different external port, which is returned.
+func (n *pcpNAT) AddPortMapping(protocol string, externalPort, internalPort int, description string, timeout int) (int, error) {
+	return n.mapPort(protocol, externalPort, internalPort, timeout)
+}
+
+// DeletePortMapping implements the NAT interface by removing the mapping for
+// the internal port.
+func (n *pcpNAT) DeletePortMapping(protocol string, externalPort, internalPort int) error {
+	_, err := n.mapPort(protocol, 0, internalPort, 0)
+	return err
+}
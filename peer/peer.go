@@ -22,6 +22,7 @@ import (
 	"github.com/vertcoin/vtcd/blockchain"
 	"github.com/vertcoin/vtcd/chaincfg"
 	"github.com/vertcoin/vtcd/chaincfg/chainhash"
+	"github.com/vertcoin/vtcd/metrics"
 	"github.com/vertcoin/vtcd/wire"
 )
 
@@ -87,8 +88,21 @@ var (
 	// connection detecting and disconnect logic since they intentionally
 	// do so for testing purposes.
 	allowSelfConns bool
+
+	// bytesReceived and bytesSent count the bytes exchanged with all peers
+	// by message command.  Bytes of messages which could not be decoded
+	// are counted under unknownCommand.
+	bytesReceived = metrics.NewCounterVec("vtcd_peer_received_bytes_total",
+		"Number of bytes received from peers by message command.",
+		"command")
+	bytesSent = metrics.NewCounterVec("vtcd_peer_sent_bytes_total",
+		"Number of bytes sent to peers by message command.", "command")
 )
 
+// unknownCommand is the command label of the bytes of messages which could not
+// be decoded.
+const unknownCommand = "unknown"
+
 // MessageListeners defines callback function pointers to invoke with message
 // listeners for a peer. Any listener which is not set to a concrete callback
 // during peer initialization is ignored. Execution of multiple message
@@ -257,6 +271,11 @@ type Config struct {
 	// not send inv messages for transactions.
 	DisableRelayTx bool
 
+	// MaxWriteRate specifies the maximum average rate, in bytes per second,
+	// at which messages are written to the remote peer.  This field can be
+	// omitted in which case it will be 0 and writes are not limited.
+	MaxWriteRate uint64
+
 	// Listeners houses callback functions to be invoked on receiving peer
 	// messages.
 	Listeners MessageListeners
@@ -435,6 +454,10 @@ type Peer struct {
 
 	wireEncoding wire.MessageEncoding
 
+	// writeLimiter limits the rate of outgoing data when a maximum write
+	// rate is configured.  It is nil otherwise.
+	writeLimiter *writeLimiter
+
 	knownInventory     *mruInventoryMap
 	prevGetBlocksMtx   sync.Mutex
 	prevGetBlocksBegin *chainhash.Hash
@@ -1139,6 +1162,11 @@ func (p *Peer) readMessage(encoding wire.MessageEncoding) (wire.Message, []byte,
 	n, msg, buf, err := wire.ReadMessageWithEncodingN(p.conn,
 		p.ProtocolVersion(), p.cfg.ChainParams.Net, encoding)
 	atomic.AddUint64(&p.bytesReceived, uint64(n))
+	if msg != nil {
+		bytesReceived.With(msg.Command()).Add(uint64(n))
+	} else if n > 0 {
+		bytesReceived.With(unknownCommand).Add(uint64(n))
+	}
 	if p.cfg.Listeners.OnRead != nil {
 		p.cfg.Listeners.OnRead(p, n, msg, err)
 	}
@@ -1202,9 +1230,21 @@ func (p *Peer) writeMessage(msg wire.Message, enc wire.MessageEncoding) error {
 	n, err := wire.WriteMessageWithEncodingN(p.conn, msg,
 		p.ProtocolVersion(), p.cfg.ChainParams.Net, enc)
 	atomic.AddUint64(&p.bytesSent, uint64(n))
+	bytesSent.With(msg.Command()).Add(uint64(n))
 	if p.cfg.Listeners.OnWrite != nil {
 		p.cfg.Listeners.OnWrite(p, n, msg, err)
 	}
+
+	// Delay further writes as needed to stay within the configured write
+	// rate.  The wait is cut short when the peer is disconnected.
+	if p.writeLimiter != nil {
+		if wait := p.writeLimiter.take(n, time.Now()); wait > 0 {
+			select {
+			case <-time.After(wait):
+			case <-p.quit:
+			}
+		}
+	}
 	return err
 }
 
@@ -2172,6 +2212,9 @@ func newPeerBase(origCfg *Config, inbound bool) *Peer {
 		services:        cfg.Services,
 		protocolVersion: cfg.ProtocolVersion,
 	}
+	if cfg.MaxWriteRate > 0 {
+		p.writeLimiter = newWriteLimiter(cfg.MaxWriteRate)
+	}
 	return &p
 }
 
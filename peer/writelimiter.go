@@ -0,0 +1,56 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package peer
+
+import (
+	"time"
+)
+
+// writeLimiter implements a token bucket which limits the average rate at
+// which bytes are written to a peer.  The bucket holds at most one second
+// worth of tokens so short bursts are allowed while the long term rate is
+// kept at or below the configured rate.
+//
+// A writeLimiter is not safe for concurrent access.  It is only used by the
+// goroutine which writes messages to the peer.
+type writeLimiter struct {
+	rate   float64 // bytes per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newWriteLimiter returns a new write limiter which limits writes to the passed
+// number of bytes per second.
+func newWriteLimiter(bytesPerSecond uint64) *writeLimiter {
+	rate := float64(bytesPerSecond)
+	return &writeLimiter{
+		rate:   rate,
+		burst:  rate,
+		tokens: rate,
+	}
+}
+
+// take removes the passed number of bytes from the bucket at the provided time
+// and returns how long the caller must wait before writing again in order to
+// stay within the configured rate.
+func (l *writeLimiter) take(n int, now time.Time) time.Duration {
+	if !l.last.IsZero() {
+		elapsed := now.Sub(l.last).Seconds()
+		if elapsed > 0 {
+			l.tokens += elapsed * l.rate
+			if l.tokens > l.burst {
+				l.tokens = l.burst
+			}
+		}
+	}
+	l.last = now
+
+	l.tokens -= float64(n)
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
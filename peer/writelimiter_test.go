@@ -0,0 +1,37 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package peer
+
+import (
+	"testing"
+	"time"
+)
+
+// TestWriteLimiter ensures the token bucket used to limit the write rate allows
+// bursts up to one second worth of data and delays writes beyond that.
+func TestWriteLimiter(t *testing.T) {
+	l := newWriteLimiter(1000)
+	base := time.Now()
+
+	// A full bucket allows one second worth of data without waiting.
+	if d := l.take(1000, base); d != 0 {
+		t.Fatalf("unexpected wait %v with full bucket", d)
+	}
+
+	// Exceeding the bucket requires waiting for it to refill.
+	if d := l.take(500, base); d != 500*time.Millisecond {
+		t.Fatalf("unexpected wait %v, want 500ms", d)
+	}
+
+	// Refilling over time reduces the deficit.
+	if d := l.take(0, base.Add(250*time.Millisecond)); d != 250*time.Millisecond {
+		t.Fatalf("unexpected wait %v after partial refill, want 250ms", d)
+	}
+
+	// The bucket never holds more than one second worth of tokens.
+	if d := l.take(1500, base.Add(10*time.Second)); d != 500*time.Millisecond {
+		t.Fatalf("unexpected wait %v after long idle, want 500ms", d)
+	}
+}
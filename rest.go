@@ -0,0 +1,478 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/vertcoin/vtcd/btcjson"
+	"github.com/vertcoin/vtcd/chaincfg/chainhash"
+	"github.com/vertcoin/vtcd/wire"
+)
+
+const (
+	// restMaxHeaders is the maximum number of headers which may be
+	// requested from the headers endpoint at once.
+	restMaxHeaders = 2000
+
+	// restMaxOutpoints is the maximum number of outpoints which may be
+	// queried from the getutxos endpoint at once.
+	restMaxOutpoints = 15
+)
+
+// restFormat identifies the format of a REST response.  It is selected by the
+// extension of the request path.
+type restFormat int
+
+// These constants define the supported REST response formats.
+const (
+	restFormatBinary restFormat = iota
+	restFormatHex
+	restFormatJSON
+)
+
+// restFormatExtensions maps the path extensions of REST requests to the
+// response format they select.
+var restFormatExtensions = map[string]restFormat{
+	"bin":  restFormatBinary,
+	"hex":  restFormatHex,
+	"json": restFormatJSON,
+}
+
+// restAllFormats and restJSONFormat list the formats supported by REST
+// endpoints.
+var (
+	restAllFormats = []restFormat{restFormatBinary, restFormatHex,
+		restFormatJSON}
+	restJSONFormat = []restFormat{restFormatJSON}
+)
+
+// restHandler describes a callback function used to serve a REST endpoint.  It
+// is passed the part of the request path which follows the endpoint and
+// precedes the format extension.  Binary and hex responses are returned as the
+// raw bytes to encode, while JSON responses may be any value which can be
+// marshalled.
+type restHandler func(s *rpcServer, param string, format restFormat) (interface{}, error)
+
+// restEndpoints lists the REST endpoints in the order they are matched against
+// the request path.
+var restEndpoints = []struct {
+	path    string
+	formats []restFormat
+	handler restHandler
+}{
+	{"/rest/block/notxdetails", restAllFormats, restBlockNoTxDetails},
+	{"/rest/block", restAllFormats, restBlock},
+	{"/rest/headers", restAllFormats, restHeaders},
+	{"/rest/tx", restAllFormats, restTx},
+	{"/rest/chaininfo", restJSONFormat, restChainInfo},
+	{"/rest/mempool/info", restJSONFormat, restMempoolInfo},
+	{"/rest/mempool/contents", restJSONFormat, restMempoolContents},
+	{"/rest/getutxos", restJSONFormat, restGetUTXOs},
+}
+
+// restError is an error which is returned to REST clients with an HTTP status
+// code.
+type restError struct {
+	code    int
+	message string
+}
+
+// Error satisfies the error interface and prints human-readable errors.
+func (e *restError) Error() string {
+	return e.message
+}
+
+// restBadRequest returns a REST error reporting an invalid request.
+func restBadRequest(format string, args ...interface{}) *restError {
+	return &restError{http.StatusBadRequest, fmt.Sprintf(format, args...)}
+}
+
+// restNotFound returns a REST error reporting that the requested data does not
+// exist.
+func restNotFound(format string, args ...interface{}) *restError {
+	return &restError{http.StatusNotFound, fmt.Sprintf(format, args...)}
+}
+
+// restRPCError converts an error returned by an RPC handler to a REST error with
+// the HTTP status code matching the RPC error code.
+func restRPCError(err error) error {
+	rpcErr, ok := err.(*btcjson.RPCError)
+	if !ok {
+		return err
+	}
+	switch rpcErr.Code {
+	// Unknown blocks and transactions share the same error code.
+	case btcjson.ErrRPCInvalidAddressOrKey:
+		return &restError{http.StatusNotFound, rpcErr.Message}
+	case btcjson.ErrRPCDecodeHexString, btcjson.ErrRPCInvalidParameter,
+		btcjson.ErrRPCInvalidParams.Code:
+		return &restError{http.StatusBadRequest, rpcErr.Message}
+	}
+	return &restError{http.StatusInternalServerError, rpcErr.Message}
+}
+
+// restHexResult decodes the hex encoded result of an RPC handler invoked in
+// its non-verbose mode into the raw bytes of a binary or hex REST response.
+func restHexResult(result interface{}, err error) (interface{}, error) {
+	if err != nil {
+		return nil, restRPCError(err)
+	}
+	hexStr, ok := result.(string)
+	if !ok {
+		return nil, fmt.Errorf("unexpected result type %T", result)
+	}
+	return hex.DecodeString(hexStr)
+}
+
+// restJSONResult returns the result of an RPC handler as the result of a JSON
+// REST response.
+func restJSONResult(result interface{}, err error) (interface{}, error) {
+	if err != nil {
+		return nil, restRPCError(err)
+	}
+	return result, nil
+}
+
+// restGetBlock serves a block with or without the details of its transactions
+// by invoking the getblock handler.
+func restGetBlock(s *rpcServer, hash string, format restFormat, txDetails bool) (interface{}, error) {
+	verbosity := btcjson.BlockVerbosityHex
+	if format == restFormatJSON {
+		verbosity = btcjson.BlockVerbosityJSON
+	}
+	c := &btcjson.GetBlockCmd{
+		Hash:      hash,
+		Verbosity: &verbosity,
+		VerboseTx: &txDetails,
+	}
+	result, err := handleGetBlock(s, c, nil)
+	if verbosity == btcjson.BlockVerbosityHex {
+		return restHexResult(result, err)
+	}
+	return restJSONResult(result, err)
+}
+
+// restBlock serves /rest/block/<hash>.<bin|hex|json>.
+func restBlock(s *rpcServer, param string, format restFormat) (interface{}, error) {
+	return restGetBlock(s, param, format, true)
+}
+
+// restBlockNoTxDetails serves /rest/block/notxdetails/<hash>.<bin|hex|json>.
+func restBlockNoTxDetails(s *rpcServer, param string, format restFormat) (interface{}, error) {
+	return restGetBlock(s, param, format, false)
+}
+
+// restHeaders serves /rest/headers/<count>/<hash>.<bin|hex|json>, which returns
+// up to count headers of the main chain starting with the block with the passed
+// hash.
+func restHeaders(s *rpcServer, param string, format restFormat) (interface{}, error) {
+	parts := strings.Split(param, "/")
+	if len(parts) != 2 {
+		return nil, restBadRequest("invalid URI format. Expected " +
+			"/rest/headers/<count>/<hash>.<ext>")
+	}
+	count, err := strconv.Atoi(parts[0])
+	if err != nil || count < 1 || count > restMaxHeaders {
+		return nil, restBadRequest("header count out of range: %s",
+			parts[0])
+	}
+	hash, err := chainhash.NewHashFromStr(parts[1])
+	if err != nil {
+		return nil, restBadRequest("invalid hash: %s", parts[1])
+	}
+
+	// Collect the hashes of the requested headers from the main chain.
+	chain := s.cfg.Chain
+	height, err := chain.BlockHeightByHash(hash)
+	if err != nil {
+		return nil, restNotFound("%s not found", hash)
+	}
+	hashes := []*chainhash.Hash{hash}
+	bestHeight := chain.BestSnapshot().Height
+	for height++; len(hashes) < count && height <= bestHeight; height++ {
+		nextHash, err := chain.BlockHashByHeight(height)
+		if err != nil {
+			// The chain was reorganized while collecting the
+			// headers, so return the ones collected so far.
+			break
+		}
+		hashes = append(hashes, nextHash)
+	}
+
+	// Serve the headers by invoking the getblockheader handler.
+	verbose := format == restFormatJSON
+	var headers []byte
+	var results []interface{}
+	for _, hash := range hashes {
+		c := &btcjson.GetBlockHeaderCmd{
+			Hash:    hash.String(),
+			Verbose: &verbose,
+		}
+		result, err := handleGetBlockHeader(s, c, nil)
+		if verbose {
+			result, err = restJSONResult(result, err)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, result)
+			continue
+		}
+		header, err := restHexResult(result, err)
+		if err != nil {
+			return nil, err
+		}
+		headers = append(headers, header.([]byte)...)
+	}
+	if verbose {
+		return results, nil
+	}
+	return headers, nil
+}
+
+// restTx serves /rest/tx/<txid>.<bin|hex|json>.  Transactions which are not in
+// the mempool are only available when the transaction index is enabled.
+func restTx(s *rpcServer, param string, format restFormat) (interface{}, error) {
+	verbose := 0
+	if format == restFormatJSON {
+		verbose = 1
+	}
+	c := &btcjson.GetRawTransactionCmd{
+		Txid:    param,
+		Verbose: &verbose,
+	}
+	result, err := handleGetRawTransaction(s, c, nil)
+	if verbose == 0 {
+		return restHexResult(result, err)
+	}
+	return restJSONResult(result, err)
+}
+
+// restChainInfo serves /rest/chaininfo.json by invoking the getblockchaininfo
+// handler.
+func restChainInfo(s *rpcServer, param string, format restFormat) (interface{}, error) {
+	if param != "" {
+		return nil, restNotFound("not found")
+	}
+	return restJSONResult(handleGetBlockChainInfo(s, nil, nil))
+}
+
+// restMempoolInfo serves /rest/mempool/info.json by invoking the
+// getmempoolinfo handler.
+func restMempoolInfo(s *rpcServer, param string, format restFormat) (interface{}, error) {
+	if param != "" {
+		return nil, restNotFound("not found")
+	}
+	return restJSONResult(handleGetMempoolInfo(s, nil, nil))
+}
+
+// restMempoolContents serves /rest/mempool/contents.json by invoking the
+// getrawmempool handler in its verbose mode.
+func restMempoolContents(s *rpcServer, param string, format restFormat) (interface{}, error) {
+	if param != "" {
+		return nil, restNotFound("not found")
+	}
+	verbose := true
+	c := &btcjson.GetRawMempoolCmd{Verbose: &verbose}
+	return restJSONResult(handleGetRawMempool(s, c, nil))
+}
+
+// restUTXOsResult models the data returned by the getutxos REST endpoint.  The
+// bitmap holds a '1' for every queried outpoint which is unspent and a '0'
+// otherwise, while the details of the unspent outputs are listed in the same
+// order.
+type restUTXOsResult struct {
+	ChainHeight  int32                     `json:"chainHeight"`
+	ChainTipHash string                    `json:"chaintipHash"`
+	Bitmap       string                    `json:"bitmap"`
+	UTXOs        []*btcjson.GetTxOutResult `json:"utxos"`
+}
+
+// restGetUTXOs serves /rest/getutxos[/checkmempool]/<txid>-<n>/....json, which
+// reports which of the passed outpoints are unspent.  With checkmempool, the
+// outputs of mempool transactions are included and outputs spent by mempool
+// transactions are excluded.
+func restGetUTXOs(s *rpcServer, param string, format restFormat) (interface{}, error) {
+	parts := strings.Split(param, "/")
+	checkMempool := parts[0] == "checkmempool"
+	if checkMempool {
+		parts = parts[1:]
+	}
+	if len(parts) == 0 || parts[0] == "" {
+		return nil, restBadRequest("no outpoints specified")
+	}
+	if len(parts) > restMaxOutpoints {
+		return nil, restBadRequest("too many outpoints, the maximum "+
+			"is %d", restMaxOutpoints)
+	}
+
+	best := s.cfg.Chain.BestSnapshot()
+	result := &restUTXOsResult{
+		ChainHeight:  best.Height,
+		ChainTipHash: best.Hash.String(),
+		UTXOs:        make([]*btcjson.GetTxOutResult, 0, len(parts)),
+	}
+	bitmap := make([]byte, 0, len(parts))
+	for _, part := range parts {
+		dash := strings.LastIndex(part, "-")
+		if dash < 0 {
+			return nil, restBadRequest("invalid outpoint %s", part)
+		}
+		txHash, err := chainhash.NewHashFromStr(part[:dash])
+		if err != nil {
+			return nil, restBadRequest("invalid outpoint %s", part)
+		}
+		vout, err := strconv.ParseUint(part[dash+1:], 10, 32)
+		if err != nil {
+			return nil, restBadRequest("invalid outpoint %s", part)
+		}
+
+		// Outputs spent by the mempool are reported as spent when it
+		// is checked.
+		outpoint := wire.OutPoint{Hash: *txHash, Index: uint32(vout)}
+		if checkMempool && s.cfg.TxMemPool.CheckSpend(outpoint) != nil {
+			bitmap = append(bitmap, '0')
+			continue
+		}
+
+		// Look up the output by invoking the gettxout handler, which
+		// reports outputs which are spent or don't exist as null or an
+		// error.
+		c := &btcjson.GetTxOutCmd{
+			Txid:           txHash.String(),
+			Vout:           uint32(vout),
+			IncludeMempool: &checkMempool,
+		}
+		txOut, err := handleGetTxOut(s, c, nil)
+		utxo, ok := txOut.(*btcjson.GetTxOutResult)
+		if err != nil || !ok || utxo == nil {
+			bitmap = append(bitmap, '0')
+			continue
+		}
+		bitmap = append(bitmap, '1')
+		result.UTXOs = append(result.UTXOs, utxo)
+	}
+	result.Bitmap = string(bitmap)
+	return result, nil
+}
+
+// restWriteError writes the passed error to the REST client as plain text with
+// the HTTP status code of the error.
+func restWriteError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	if restErr, ok := err.(*restError); ok {
+		code = restErr.code
+	}
+	http.Error(w, err.Error(), code)
+}
+
+// handleREST serves the unauthenticated, read-only REST interface.  The format
+// of the response is selected by the extension of the request path, which must
+// be one of the formats supported by the requested endpoint.
+func (s *rpcServer) handleREST(w http.ResponseWriter, r *http.Request) {
+	if atomic.LoadInt32(&s.shutdown) != 0 {
+		return
+	}
+
+	// Limit the number of connections to max allowed.
+	if s.limitConnections(w, r.RemoteAddr) {
+		return
+	}
+
+	// Keep track of the number of connected clients.
+	s.incrementClients()
+	defer s.decrementClients()
+
+	if r.Method != "GET" {
+		w.Header().Set("Allow", "GET")
+		restWriteError(w, &restError{http.StatusMethodNotAllowed,
+			"only GET requests are supported"})
+		return
+	}
+
+	// Split the format extension from the request path.
+	path := r.URL.Path
+	ext := ""
+	if dot := strings.LastIndex(path, "."); dot > strings.LastIndex(path, "/") {
+		path, ext = path[:dot], path[dot+1:]
+	}
+
+	// Find the endpoint serving the path.
+	for _, endpoint := range restEndpoints {
+		if !strings.HasPrefix(path, endpoint.path) {
+			continue
+		}
+		param := path[len(endpoint.path):]
+		if param != "" && param[0] != '/' {
+			continue
+		}
+		param = strings.TrimPrefix(param, "/")
+
+		// Ensure the endpoint supports the requested format.
+		format, ok := restFormatExtensions[ext]
+		supported := false
+		available := make([]string, 0, len(endpoint.formats))
+		for _, f := range endpoint.formats {
+			supported = supported || (ok && f == format)
+			for name, nameFormat := range restFormatExtensions {
+				if nameFormat == f {
+					available = append(available, name)
+				}
+			}
+		}
+		if !supported {
+			restWriteError(w, restNotFound("output format not found "+
+				"(available: %s)", strings.Join(available, ", ")))
+			return
+		}
+
+		result, err := endpoint.handler(s, param, format)
+		if err != nil {
+			restWriteError(w, err)
+			return
+		}
+		s.restWriteResult(w, result, format)
+		return
+	}
+
+	restWriteError(w, restNotFound("not found"))
+}
+
+// restWriteResult writes the passed result of a REST endpoint in the passed
+// format.
+func (s *rpcServer) restWriteResult(w http.ResponseWriter, result interface{}, format restFormat) {
+	var contentType string
+	var body []byte
+	switch format {
+	case restFormatBinary:
+		contentType = "application/octet-stream"
+		body = result.([]byte)
+
+	case restFormatHex:
+		contentType = "text/plain"
+		body = []byte(hex.EncodeToString(result.([]byte)) + "\n")
+
+	case restFormatJSON:
+		marshalled, err := json.Marshal(result)
+		if err != nil {
+			rpcsLog.Errorf("Failed to marshal REST reply: %v", err)
+			restWriteError(w, err)
+			return
+		}
+		contentType = "application/json"
+		body = append(marshalled, '\n')
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	if _, err := w.Write(body); err != nil {
+		rpcsLog.Errorf("Failed to write REST reply: %v", err)
+	}
+}
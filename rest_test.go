@@ -0,0 +1,465 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vertcoin/vtcd/blockchain"
+	"github.com/vertcoin/vtcd/btcjson"
+	"github.com/vertcoin/vtcd/chaincfg"
+	"github.com/vertcoin/vtcd/database"
+	_ "github.com/vertcoin/vtcd/database/ffldb"
+	"github.com/vertcoin/vtcd/mempool"
+	"github.com/vertcoin/vtcd/mining"
+	"github.com/vertcoin/vtcd/txscript"
+	"github.com/vertcoin/vtcd/wire"
+	"github.com/vertcoin/vtcutil"
+)
+
+// restTestHarness provides an RPC server backed by a regression test chain
+// and mempool for testing the REST interface.
+type restTestHarness struct {
+	server    *rpcServer
+	generator *mining.BlkTmplGenerator
+	blocks    []*wire.MsgBlock
+	mempoolTx *vtcutil.Tx
+	teardown  func()
+}
+
+// newRESTTestHarness returns a REST test harness whose chain has enough blocks
+// for the coinbase of the first one to mature, and whose mempool holds a
+// transaction spending that coinbase.
+func newRESTTestHarness(t *testing.T) *restTestHarness {
+	// The subsystem loggers must not write to the log rotator, which isn't
+	// initialized by tests.
+	setLogLevels("off")
+
+	dbPath, err := ioutil.TempDir("", "rest")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	params := chaincfg.RegressionNetParams
+	db, err := database.Create("ffldb", dbPath, params.Net)
+	if err != nil {
+		os.RemoveAll(dbPath)
+		t.Fatalf("unable to create db: %v", err)
+	}
+	teardown := func() {
+		db.Close()
+		os.RemoveAll(dbPath)
+	}
+
+	timeSource := blockchain.NewMedianTime()
+	sigCache := txscript.NewSigCache(1000)
+	hashCache := txscript.NewHashCache(1000)
+	chain, err := blockchain.New(&blockchain.Config{
+		DB:          db,
+		ChainParams: &params,
+		TimeSource:  timeSource,
+		SigCache:    sigCache,
+	})
+	if err != nil {
+		teardown()
+		t.Fatalf("unable to create chain: %v", err)
+	}
+	txMemPool := mempool.New(&mempool.Config{
+		Policy: mempool.Policy{
+			DisableRelayPriority: true,
+			AcceptNonStd:         true,
+			MaxTxVersion:         2,
+		},
+		ChainParams:   &params,
+		FetchUtxoView: chain.FetchUtxoView,
+		BestHeight:    func() int32 { return chain.BestSnapshot().Height },
+		MedianTimePast: func() time.Time {
+			return chain.BestSnapshot().MedianTime
+		},
+		CalcSequenceLock: func(tx *vtcutil.Tx, view *blockchain.UtxoViewpoint) (*blockchain.SequenceLock, error) {
+			return chain.CalcSequenceLock(tx, view, true)
+		},
+		IsDeploymentActive: chain.IsDeploymentActive,
+		SigCache:           sigCache,
+		HashCache:          hashCache,
+	})
+	policy := mining.Policy{
+		BlockMaxWeight: blockchain.MaxBlockWeight - 4000,
+		BlockMaxSize:   blockchain.MaxBlockBaseSize - 1000,
+	}
+	h := &restTestHarness{
+		server: &rpcServer{
+			cfg: rpcserverConfig{
+				TimeSource:  timeSource,
+				Chain:       chain,
+				ChainParams: &params,
+				DB:          db,
+				TxMemPool:   txMemPool,
+			},
+			statusLines: make(map[int]string),
+		},
+		generator: mining.NewBlkTmplGenerator(&policy, &params,
+			txMemPool, chain, timeSource, sigCache, hashCache),
+		teardown: teardown,
+	}
+
+	for i := 0; i < int(params.CoinbaseMaturity)+1; i++ {
+		h.mineBlock(t)
+	}
+
+	// Spend the coinbase of the first block to an anyone-can-spend output
+	// from the mempool.
+	coinbase := h.blocks[0].Transactions[0]
+	msgTx := wire.NewMsgTx(wire.TxVersion)
+	msgTx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Hash: coinbase.TxHash()},
+		Sequence:         wire.MaxTxInSequenceNum,
+	})
+	msgTx.AddTxOut(wire.NewTxOut(coinbase.TxOut[0].Value-100000,
+		[]byte{txscript.OP_TRUE}))
+	h.mempoolTx = vtcutil.NewTx(msgTx)
+	_, err = txMemPool.ProcessTransaction(h.mempoolTx, false, false, 0)
+	if err != nil {
+		teardown()
+		t.Fatalf("ProcessTransaction: unexpected error: %v", err)
+	}
+
+	return h
+}
+
+// mineBlock solves a block from a new block template of the generator and
+// connects it to the chain.
+func (h *restTestHarness) mineBlock(t *testing.T) {
+	template, err := h.generator.NewBlockTemplate(nil)
+	if err != nil {
+		h.teardown()
+		t.Fatalf("NewBlockTemplate: unexpected error: %v", err)
+	}
+	msgBlock := template.Block
+	target := blockchain.CompactToBig(msgBlock.Header.Bits)
+	for {
+		hash, err := msgBlock.Header.PowHash()
+		if err != nil {
+			h.teardown()
+			t.Fatalf("PowHash: unexpected error: %v", err)
+		}
+		if blockchain.HashToBig(hash).Cmp(target) <= 0 {
+			break
+		}
+		msgBlock.Header.Nonce++
+	}
+	_, isOrphan, err := h.server.cfg.Chain.ProcessBlock(
+		vtcutil.NewBlock(msgBlock), blockchain.BFNone)
+	if err != nil || isOrphan {
+		h.teardown()
+		t.Fatalf("ProcessBlock: unexpected result - orphan %v, error %v",
+			isOrphan, err)
+	}
+	h.blocks = append(h.blocks, msgBlock)
+}
+
+// TestREST ensures the REST endpoints serve the expected data in each of their
+// formats and reject invalid requests with the expected status codes.
+func TestREST(t *testing.T) {
+	// The connection limit of the REST handler is read from the global
+	// config.
+	defer func(prev *config) { cfg = prev }(cfg)
+	cfg = &config{RPCMaxClients: 10}
+
+	h := newRESTTestHarness(t)
+	defer h.teardown()
+	ts := httptest.NewServer(http.HandlerFunc(h.server.handleREST))
+	defer ts.Close()
+
+	get := func(method, path string) (int, string, []byte) {
+		req, err := http.NewRequest(method, ts.URL+path, nil)
+		if err != nil {
+			t.Fatalf("unable to create request: %v", err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: unexpected error: %v", method, path, err)
+		}
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("%s %s: unable to read body: %v", method, path,
+				err)
+		}
+		return resp.StatusCode, resp.Header.Get("Content-Type"), body
+	}
+
+	block := h.blocks[1]
+	blockHash := block.BlockHash()
+	var blockBuf bytes.Buffer
+	if err := block.Serialize(&blockBuf); err != nil {
+		t.Fatalf("unable to serialize block: %v", err)
+	}
+	var headersBuf bytes.Buffer
+	for _, b := range h.blocks[1:4] {
+		if err := b.Header.Serialize(&headersBuf); err != nil {
+			t.Fatalf("unable to serialize header: %v", err)
+		}
+	}
+	var txBuf bytes.Buffer
+	if err := h.mempoolTx.MsgTx().Serialize(&txBuf); err != nil {
+		t.Fatalf("unable to serialize tx: %v", err)
+	}
+	coinbaseHash := h.blocks[0].Transactions[0].TxHash()
+	lastCoinbaseHash := h.blocks[len(h.blocks)-1].Transactions[0].TxHash()
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		code        int
+		contentType string
+		body        []byte
+	}{
+		{
+			name:        "block binary",
+			path:        fmt.Sprintf("/rest/block/%s.bin", blockHash),
+			code:        http.StatusOK,
+			contentType: "application/octet-stream",
+			body:        blockBuf.Bytes(),
+		},
+		{
+			name:        "block hex",
+			path:        fmt.Sprintf("/rest/block/%s.hex", blockHash),
+			code:        http.StatusOK,
+			contentType: "text/plain",
+			body:        []byte(hex.EncodeToString(blockBuf.Bytes()) + "\n"),
+		},
+		{
+			name:        "block without tx details binary",
+			path:        fmt.Sprintf("/rest/block/notxdetails/%s.bin", blockHash),
+			code:        http.StatusOK,
+			contentType: "application/octet-stream",
+			body:        blockBuf.Bytes(),
+		},
+		{
+			name:        "headers binary",
+			path:        fmt.Sprintf("/rest/headers/3/%s.bin", blockHash),
+			code:        http.StatusOK,
+			contentType: "application/octet-stream",
+			body:        headersBuf.Bytes(),
+		},
+		{
+			name:        "tx binary",
+			path:        fmt.Sprintf("/rest/tx/%s.bin", h.mempoolTx.Hash()),
+			code:        http.StatusOK,
+			contentType: "application/octet-stream",
+			body:        txBuf.Bytes(),
+		},
+		{
+			name: "unknown block",
+			path: fmt.Sprintf("/rest/block/%s.bin", coinbaseHash),
+			code: http.StatusNotFound,
+		},
+		{
+			name: "invalid block hash",
+			path: "/rest/block/xyz.hex",
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "missing format",
+			path: fmt.Sprintf("/rest/block/%s", blockHash),
+			code: http.StatusNotFound,
+		},
+		{
+			name: "unsupported format",
+			path: "/rest/chaininfo.bin",
+			code: http.StatusNotFound,
+		},
+		{
+			name: "header count out of range",
+			path: fmt.Sprintf("/rest/headers/2001/%s.bin", blockHash),
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "headers without count",
+			path: fmt.Sprintf("/rest/headers/%s.bin", blockHash),
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "confirmed tx without tx index",
+			path: fmt.Sprintf("/rest/tx/%s.hex", coinbaseHash),
+			code: http.StatusNotFound,
+		},
+		{
+			name: "too many outpoints",
+			path: "/rest/getutxos" + strings.Repeat(fmt.Sprintf("/%s-0",
+				coinbaseHash), restMaxOutpoints+1) + ".json",
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "malformed outpoint",
+			path: fmt.Sprintf("/rest/getutxos/%s.json", coinbaseHash),
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "unknown endpoint",
+			path: "/rest/blocks.json",
+			code: http.StatusNotFound,
+		},
+		{
+			name:   "post request",
+			method: "POST",
+			path:   "/rest/chaininfo.json",
+			code:   http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, test := range tests {
+		method := test.method
+		if method == "" {
+			method = "GET"
+		}
+		code, contentType, body := get(method, test.path)
+		if code != test.code {
+			t.Errorf("%s: unexpected status code - got %d, want %d "+
+				"(body %q)", test.name, code, test.code, body)
+			continue
+		}
+		if test.contentType != "" && contentType != test.contentType {
+			t.Errorf("%s: unexpected content type - got %q, want %q",
+				test.name, contentType, test.contentType)
+		}
+		if test.body != nil && !bytes.Equal(body, test.body) {
+			t.Errorf("%s: unexpected body - got %x, want %x",
+				test.name, body, test.body)
+		}
+	}
+
+	// getJSON requests the passed path and unmarshals the JSON response into
+	// the passed result.
+	getJSON := func(path string, result interface{}) {
+		code, contentType, body := get("GET", path)
+		if code != http.StatusOK || contentType != "application/json" {
+			t.Fatalf("%s: unexpected response - status code %d, "+
+				"content type %q, body %q", path, code,
+				contentType, body)
+		}
+		if err := json.Unmarshal(body, result); err != nil {
+			t.Fatalf("%s: unable to unmarshal %q: %v", path, body,
+				err)
+		}
+	}
+
+	var blockResult btcjson.GetBlockVerboseResult
+	getJSON(fmt.Sprintf("/rest/block/%s.json", blockHash), &blockResult)
+	if blockResult.Hash != blockHash.String() || len(blockResult.RawTx) != 1 {
+		t.Errorf("unexpected block result %+v", blockResult)
+	}
+	var noTxResult btcjson.GetBlockVerboseResult
+	getJSON(fmt.Sprintf("/rest/block/notxdetails/%s.json", blockHash),
+		&noTxResult)
+	if len(noTxResult.RawTx) != 0 || len(noTxResult.Tx) != 1 {
+		t.Errorf("unexpected block result without tx details %+v",
+			noTxResult)
+	}
+
+	// Requesting more headers than are available returns the headers up to
+	// the tip of the main chain.
+	var headersResult []btcjson.GetBlockHeaderVerboseResult
+	getJSON(fmt.Sprintf("/rest/headers/2000/%s.json", blockHash),
+		&headersResult)
+	if len(headersResult) != len(h.blocks)-1 {
+		t.Errorf("unexpected number of headers - got %d, want %d",
+			len(headersResult), len(h.blocks)-1)
+	} else if headersResult[1].Hash != h.blocks[2].BlockHash().String() {
+		t.Errorf("unexpected second header %+v", headersResult[1])
+	}
+
+	var txResult btcjson.TxRawResult
+	getJSON(fmt.Sprintf("/rest/tx/%s.json", h.mempoolTx.Hash()), &txResult)
+	if txResult.Txid != h.mempoolTx.Hash().String() {
+		t.Errorf("unexpected tx result %+v", txResult)
+	}
+
+	var chainInfo btcjson.GetBlockChainInfoResult
+	getJSON("/rest/chaininfo.json", &chainInfo)
+	if chainInfo.Blocks != int32(len(h.blocks)) ||
+		chainInfo.Chain != chaincfg.RegressionNetParams.Name {
+		t.Errorf("unexpected chain info %+v", chainInfo)
+	}
+
+	var mempoolInfo btcjson.GetMempoolInfoResult
+	getJSON("/rest/mempool/info.json", &mempoolInfo)
+	if mempoolInfo.Size != 1 {
+		t.Errorf("unexpected mempool info %+v", mempoolInfo)
+	}
+	var mempoolContents map[string]btcjson.GetRawMempoolVerboseResult
+	getJSON("/rest/mempool/contents.json", &mempoolContents)
+	if _, ok := mempoolContents[h.mempoolTx.Hash().String()]; !ok ||
+		len(mempoolContents) != 1 {
+		t.Errorf("unexpected mempool contents %+v", mempoolContents)
+	}
+
+	// The coinbase of the first block is spent by the mempool transaction,
+	// whose output is only known to the mempool.  The coinbase of the last
+	// block is unspent and the second output of the mempool transaction
+	// doesn't exist.
+	outpoints := fmt.Sprintf("/%s-0/%s-0/%s-0/%s-1", coinbaseHash,
+		h.mempoolTx.Hash(), lastCoinbaseHash, h.mempoolTx.Hash())
+	utxoTests := []struct {
+		path   string
+		bitmap string
+		values []float64
+	}{
+		{
+			path:   "/rest/getutxos" + outpoints + ".json",
+			bitmap: "1010",
+			values: []float64{
+				vtcutil.Amount(h.blocks[0].Transactions[0].TxOut[0].Value).ToBTC(),
+				vtcutil.Amount(h.blocks[len(h.blocks)-1].Transactions[0].TxOut[0].Value).ToBTC(),
+			},
+		},
+		{
+			path:   "/rest/getutxos/checkmempool" + outpoints + ".json",
+			bitmap: "0110",
+			values: []float64{
+				vtcutil.Amount(h.mempoolTx.MsgTx().TxOut[0].Value).ToBTC(),
+				vtcutil.Amount(h.blocks[len(h.blocks)-1].Transactions[0].TxOut[0].Value).ToBTC(),
+			},
+		},
+	}
+	for _, test := range utxoTests {
+		var result restUTXOsResult
+		getJSON(test.path, &result)
+		best := h.server.cfg.Chain.BestSnapshot()
+		if result.ChainHeight != best.Height ||
+			result.ChainTipHash != best.Hash.String() {
+			t.Errorf("%s: unexpected chain tip %d %s", test.path,
+				result.ChainHeight, result.ChainTipHash)
+		}
+		if result.Bitmap != test.bitmap {
+			t.Errorf("%s: unexpected bitmap - got %s, want %s",
+				test.path, result.Bitmap, test.bitmap)
+		}
+		if len(result.UTXOs) != len(test.values) {
+			t.Errorf("%s: unexpected number of utxos - got %d, "+
+				"want %d", test.path, len(result.UTXOs),
+				len(test.values))
+			continue
+		}
+		for i, utxo := range result.UTXOs {
+			if utxo.Value != test.values[i] {
+				t.Errorf("%s: unexpected value of utxo %d - got "+
+					"%v, want %v", test.path, i, utxo.Value,
+					test.values[i])
+			}
+		}
+	}
+}
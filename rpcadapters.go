@@ -5,10 +5,14 @@
 package main
 
 import (
+	"errors"
+	"net"
 	"sync/atomic"
+	"time"
 
 	"github.com/vertcoin/vtcd/blockchain"
 	"github.com/vertcoin/vtcd/chaincfg/chainhash"
+	"github.com/vertcoin/vtcd/connmgr"
 	"github.com/vertcoin/vtcd/mempool"
 	"github.com/vertcoin/vtcd/peer"
 	"github.com/vertcoin/vtcd/wire"
@@ -51,6 +55,24 @@ func (p *rpcPeer) BanScore() uint32 {
 	return (*serverPeer)(p).banScore.Int()
 }
 
+// BanScoreComponents returns the persistent and the decaying parts of the ban
+// score.
+//
+// This function is safe for concurrent access and is part of the rpcserverPeer
+// interface implementation.
+func (p *rpcPeer) BanScoreComponents() (uint32, uint32) {
+	return (*serverPeer)(p).banScore.Components()
+}
+
+// BanScoreHistory returns the events which increased the ban score of the peer
+// from oldest to newest.
+//
+// This function is safe for concurrent access and is part of the rpcserverPeer
+// interface implementation.
+func (p *rpcPeer) BanScoreHistory() []banScoreEvent {
+	return (*serverPeer)(p).banScore.History()
+}
+
 // FeeFilter returns the requested current minimum fee rate for which
 // transactions should be announced.
 //
@@ -163,6 +185,14 @@ func (cm *rpcConnManager) NetTotals() (uint64, uint64) {
 	return cm.server.NetTotals()
 }
 
+// UploadTarget returns the state of the upload target for the current cycle.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverConnManager interface implementation.
+func (cm *rpcConnManager) UploadTarget() uploadTargetState {
+	return cm.server.uploadTarget.State()
+}
+
 // ConnectedPeers returns an array consisting of all connected peers.
 //
 // This function is safe for concurrent access and is part of the
@@ -276,3 +306,48 @@ func (b *rpcSyncMgr) SyncPeerID() int32 {
 func (b *rpcSyncMgr) LocateHeaders(locators []*chainhash.Hash, hashStop *chainhash.Hash) []wire.BlockHeader {
 	return b.server.chain.LocateHeaders(locators, hashStop)
 }
+
+// BanSubnet bans the provided subnet until the given time and disconnects all
+// connected peers which are part of it.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverConnManager interface implementation.
+func (cm *rpcConnManager) BanSubnet(subnet *net.IPNet, until time.Time, reason string) error {
+	replyChan := make(chan error)
+	cm.server.query <- banSubnetMsg{
+		subnet: subnet,
+		until:  until,
+		reason: reason,
+		reply:  replyChan,
+	}
+	return <-replyChan
+}
+
+// UnbanSubnet removes the provided subnet from the ban list.  Attempting to
+// unban a subnet which is not banned will return an error.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverConnManager interface implementation.
+func (cm *rpcConnManager) UnbanSubnet(subnet *net.IPNet) error {
+	if !cm.server.banList.Unban(subnet) {
+		return errors.New("subnet not banned")
+	}
+	return cm.server.banList.Save()
+}
+
+// BannedSubnets returns all of the currently banned subnets.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverConnManager interface implementation.
+func (cm *rpcConnManager) BannedSubnets() []connmgr.BanEntry {
+	return cm.server.banList.List()
+}
+
+// ClearBanned removes all subnets from the ban list.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverConnManager interface implementation.
+func (cm *rpcConnManager) ClearBanned() error {
+	cm.server.banList.Clear()
+	return cm.server.banList.Save()
+}
@@ -0,0 +1,208 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vertcoin/vtcd/btcjson"
+	"github.com/vertcoin/vtcd/metrics"
+)
+
+const (
+	// defaultRPCAuditLogFilename is the name of the file in the log
+	// directory the RPC audit log is written to.
+	defaultRPCAuditLogFilename = "rpcaudit.log"
+
+	// The transports RPC calls are recorded with.
+	rpcTransportHTTP      = "http"
+	rpcTransportWebsocket = "websocket"
+	rpcTransportGRPC      = "grpc"
+)
+
+var (
+	// rpcCallSeconds tracks the duration of RPC calls per method.
+	rpcCallSeconds = metrics.NewHistogramVec("vtcd_rpc_call_seconds",
+		"Duration of RPC calls in seconds by method.", "method",
+		metrics.ExponentialBuckets(0.001, 4, 10))
+
+	// rpcRateLimitedCalls tracks the RPC calls rejected by rate limits per
+	// method.
+	rpcRateLimitedCalls = metrics.NewCounterVec(
+		"vtcd_rpc_rate_limited_calls_total",
+		"Number of RPC calls rejected by rate limits by method.",
+		"method")
+)
+
+// rpcCall is an RPC call which is being serviced.
+type rpcCall struct {
+	method     string
+	user       string
+	remoteAddr string
+	transport  string
+	start      time.Time
+}
+
+// rpcAuditRecord is the JSON encoded record of a finished RPC call written to
+// the audit log.  The error code is the JSON-RPC error code of failed calls, or
+// the status code of failed gRPC calls.
+type rpcAuditRecord struct {
+	Time       string  `json:"time"`
+	User       string  `json:"user"`
+	RemoteAddr string  `json:"remoteaddr"`
+	Transport  string  `json:"transport"`
+	Method     string  `json:"method"`
+	Duration   float64 `json:"duration"`
+	ErrorCode  int     `json:"errorcode,omitempty"`
+}
+
+// rpcCallTracker authorizes and rate limits RPC calls, keeps track of the
+// calls being serviced, and records them in the audit log and metrics once
+// they finish.  It is shared by all transports of the RPC server.
+type rpcCallTracker struct {
+	limiter *rpcRateLimiter
+
+	mtx      sync.Mutex
+	active   map[*rpcCall]struct{}
+	auditLog io.WriteCloser
+}
+
+// newRPCCallTracker returns a new call tracker enforcing the rate limits of
+// the passed limiter.  Finished calls are written to the passed audit log as
+// lines of JSON unless it is nil.
+func newRPCCallTracker(limiter *rpcRateLimiter, auditLog io.WriteCloser) *rpcCallTracker {
+	return &rpcCallTracker{
+		limiter:  limiter,
+		active:   make(map[*rpcCall]struct{}),
+		auditLog: auditLog,
+	}
+}
+
+// begin starts servicing a call of the passed method by the passed user.  The
+// call is always returned and must be passed to end once it finishes.  When the
+// user may not invoke the method, or has exceeded a rate limit, an error
+// suitable for use in replies is returned and the call must not be serviced.
+//
+// This function is safe for concurrent access.
+func (t *rpcCallTracker) begin(user *rpcUser, remoteAddr, transport, method string) (*rpcCall, *btcjson.RPCError) {
+	call := &rpcCall{
+		method:     method,
+		user:       user.name,
+		remoteAddr: remoteAddr,
+		transport:  transport,
+		start:      time.Now(),
+	}
+	if jsonErr := user.authorize(method); jsonErr != nil {
+		return call, jsonErr
+	}
+	if jsonErr := t.limiter.allow(user.name, method); jsonErr != nil {
+		rpcsLog.Debugf("Rejected %s call by %s from %s: %s", method,
+			user.name, remoteAddr, jsonErr.Message)
+		rpcRateLimitedCalls.With(metricsMethod(method)).Inc()
+		return call, jsonErr
+	}
+
+	t.mtx.Lock()
+	t.active[call] = struct{}{}
+	t.mtx.Unlock()
+	return call, nil
+}
+
+// end finishes the passed call with the passed error code, which is zero when
+// the call succeeded, and records it.
+//
+// This function is safe for concurrent access.
+func (t *rpcCallTracker) end(call *rpcCall, errorCode int) {
+	duration := time.Since(call.start)
+	rpcCallSeconds.With(metricsMethod(call.method)).Observe(
+		duration.Seconds())
+
+	t.mtx.Lock()
+	delete(t.active, call)
+	if t.auditLog != nil {
+		record, err := json.Marshal(&rpcAuditRecord{
+			Time:       call.start.UTC().Format(time.RFC3339Nano),
+			User:       call.user,
+			RemoteAddr: call.remoteAddr,
+			Transport:  call.transport,
+			Method:     call.method,
+			Duration:   duration.Seconds(),
+			ErrorCode:  errorCode,
+		})
+		if err == nil {
+			_, err = t.auditLog.Write(append(record, '\n'))
+		}
+		if err != nil {
+			rpcsLog.Errorf("Unable to write RPC audit log: %v", err)
+		}
+	}
+	t.mtx.Unlock()
+}
+
+// closeAuditLog closes the audit log.  Calls which finish afterwards are no
+// longer recorded in it.
+//
+// This function is safe for concurrent access.
+func (t *rpcCallTracker) closeAuditLog() error {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	if t.auditLog == nil {
+		return nil
+	}
+	err := t.auditLog.Close()
+	t.auditLog = nil
+	return err
+}
+
+// activeCalls returns the calls which are being serviced ordered by the time
+// they started.
+//
+// This function is safe for concurrent access.
+func (t *rpcCallTracker) activeCalls() []rpcCall {
+	t.mtx.Lock()
+	calls := make([]rpcCall, 0, len(t.active))
+	for call := range t.active {
+		calls = append(calls, *call)
+	}
+	t.mtx.Unlock()
+
+	sort.Sort(rpcCallsByStart(calls))
+	return calls
+}
+
+// rpcCallsByStart provides sorting of RPC calls by the time they started.
+type rpcCallsByStart []rpcCall
+
+func (s rpcCallsByStart) Len() int           { return len(s) }
+func (s rpcCallsByStart) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s rpcCallsByStart) Less(i, j int) bool { return s[i].start.Before(s[j].start) }
+
+// rpcErrorCode returns the JSON-RPC error code of the passed error returned by
+// an RPC handler, or zero when it is nil.
+func rpcErrorCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	if jsonErr, ok := err.(*btcjson.RPCError); ok {
+		return int(jsonErr.Code)
+	}
+	return int(btcjson.ErrRPCInternal.Code)
+}
+
+// metricsMethod returns the passed method when it is known, and "unknown"
+// otherwise, so metrics labeled by method can't grow without bound.
+func metricsMethod(method string) string {
+	if _, ok := rpcHandlers[method]; ok {
+		return method
+	}
+	if _, ok := wsHandlers[method]; ok {
+		return method
+	}
+	return "unknown"
+}
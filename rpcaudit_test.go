@@ -0,0 +1,152 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/vertcoin/vtcd/btcjson"
+)
+
+// auditBuffer is an in-memory audit log.
+type auditBuffer struct {
+	bytes.Buffer
+	closed bool
+}
+
+// Close marks the buffer as closed.
+func (b *auditBuffer) Close() error {
+	b.closed = true
+	return nil
+}
+
+// TestRPCCallTracker ensures the calls being serviced are reported by
+// getrpcinfo and every call, including rejected ones, is recorded in the audit
+// log once it finishes.
+func TestRPCCallTracker(t *testing.T) {
+	// The getrpcinfo handler reads the log directory from the global
+	// configuration, so install a test configuration for the duration of
+	// the test.
+	defer func(oldCfg *config) { cfg = oldCfg }(cfg)
+	cfg = &config{LogDir: "logs"}
+
+	auth, err := newRPCAuthenticator("alice", "alicepass", "bob",
+		"bobpass", "", nil, nil)
+	if err != nil {
+		t.Fatalf("newRPCAuthenticator: unexpected error: %v", err)
+	}
+	limits, err := parseRPCRateLimits([]string{"alice:rescan:1:1"}, auth)
+	if err != nil {
+		t.Fatalf("parseRPCRateLimits: unexpected error: %v", err)
+	}
+	auditLog := new(auditBuffer)
+	s := &rpcServer{
+		calls: newRPCCallTracker(newRPCRateLimiter(limits), auditLog),
+	}
+	alice := auth.authenticate("alice", "alicepass")
+	bob := auth.authenticate("bob", "bobpass")
+
+	// Start a rescan and a getrpcinfo call, which are both reported as
+	// active, oldest first.
+	rescan, jsonErr := s.calls.begin(alice, "127.0.0.1:1000",
+		rpcTransportWebsocket, "rescan")
+	if jsonErr != nil {
+		t.Fatalf("begin: unexpected error: %v", jsonErr)
+	}
+	getRPCInfo, jsonErr := s.calls.begin(alice, "127.0.0.1:1001",
+		rpcTransportHTTP, "getrpcinfo")
+	if jsonErr != nil {
+		t.Fatalf("begin: unexpected error: %v", jsonErr)
+	}
+	result, err := handleGetRPCInfo(s, &btcjson.GetRPCInfoCmd{}, nil)
+	s.calls.end(getRPCInfo, rpcErrorCode(err))
+	if err != nil {
+		t.Fatalf("handleGetRPCInfo: unexpected error: %v", err)
+	}
+	info := result.(*btcjson.GetRPCInfoResult)
+	var methods []string
+	for _, cmd := range info.ActiveCommands {
+		methods = append(methods, cmd.Method)
+		if cmd.User != "alice" || cmd.Duration < 0 {
+			t.Errorf("getrpcinfo: unexpected active command %+v",
+				cmd)
+		}
+	}
+	if !reflect.DeepEqual(methods, []string{"rescan", "getrpcinfo"}) {
+		t.Errorf("getrpcinfo: unexpected active commands %v", methods)
+	}
+
+	// A second rescan exceeds the rate limit and the limited user may not
+	// call stop at all.  Neither is reported as active.
+	call, jsonErr := s.calls.begin(alice, "127.0.0.1:1002",
+		rpcTransportHTTP, "rescan")
+	if jsonErr == nil || jsonErr.Code != btcjson.ErrRPCRateLimited {
+		t.Errorf("begin: unexpected error for rate limited call: %v",
+			jsonErr)
+	}
+	s.calls.end(call, rpcErrorCode(jsonErr))
+	call, jsonErr = s.calls.begin(bob, "127.0.0.1:1003", rpcTransportGRPC,
+		"stop")
+	if jsonErr == nil || jsonErr.Code != btcjson.ErrRPCInvalidParams.Code {
+		t.Errorf("begin: unexpected error for limited user: %v", jsonErr)
+	}
+	s.calls.end(call, rpcErrorCode(jsonErr))
+	if calls := s.calls.activeCalls(); len(calls) != 1 ||
+		calls[0].method != "rescan" {
+
+		t.Errorf("activeCalls: unexpected calls %+v", calls)
+	}
+	s.calls.end(rescan, 0)
+	if calls := s.calls.activeCalls(); len(calls) != 0 {
+		t.Errorf("activeCalls: unexpected calls %+v", calls)
+	}
+
+	// Every call is recorded in the order it finished.
+	want := []rpcAuditRecord{
+		{User: "alice", RemoteAddr: "127.0.0.1:1001",
+			Transport: rpcTransportHTTP, Method: "getrpcinfo"},
+		{User: "alice", RemoteAddr: "127.0.0.1:1002",
+			Transport: rpcTransportHTTP, Method: "rescan",
+			ErrorCode: int(btcjson.ErrRPCRateLimited)},
+		{User: "bob", RemoteAddr: "127.0.0.1:1003",
+			Transport: rpcTransportGRPC, Method: "stop",
+			ErrorCode: int(btcjson.ErrRPCInvalidParams.Code)},
+		{User: "alice", RemoteAddr: "127.0.0.1:1000",
+			Transport: rpcTransportWebsocket, Method: "rescan"},
+	}
+	var records []rpcAuditRecord
+	scanner := bufio.NewScanner(&auditLog.Buffer)
+	for scanner.Scan() {
+		var record rpcAuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("unable to decode audit record %q: %v",
+				scanner.Text(), err)
+		}
+		if record.Time == "" || record.Duration < 0 {
+			t.Errorf("unexpected audit record %+v", record)
+		}
+		record.Time, record.Duration = "", 0
+		records = append(records, record)
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("unexpected audit records - got %+v, want %+v",
+			records, want)
+	}
+
+	// Calls finishing after the audit log is closed are not recorded.
+	if err := s.calls.closeAuditLog(); err != nil || !auditLog.closed {
+		t.Fatalf("closeAuditLog: unexpected error: %v", err)
+	}
+	call, _ = s.calls.begin(alice, "127.0.0.1:1004", rpcTransportHTTP,
+		"uptime")
+	s.calls.end(call, 0)
+	if auditLog.Len() != 0 {
+		t.Errorf("call recorded after the audit log was closed")
+	}
+}
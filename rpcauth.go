@@ -0,0 +1,290 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/vertcoin/vtcd/btcjson"
+)
+
+const (
+	// cookieAuthUser is the username of the credentials stored in the RPC
+	// authentication cookie file.
+	cookieAuthUser = "__cookie__"
+
+	// defaultRPCCookieFilename is the name of the RPC authentication cookie
+	// file which is created in the data directory.
+	defaultRPCCookieFilename = ".cookie"
+
+	// rpcAuthSaltLen is the number of random bytes used to salt the
+	// passwords of users configured with plaintext credentials.
+	rpcAuthSaltLen = 16
+)
+
+// rpcUser houses the credentials and permissions of a user of the RPC server.
+// Passwords are never stored in the clear.  Instead, the HMAC-SHA256 of the
+// password keyed by the salt is kept, which is the same scheme used for the
+// --rpcauth option.
+type rpcUser struct {
+	name     string
+	salt     string
+	passHMAC []byte
+
+	// isAdmin specifies whether the user may change the state of the
+	// server.  Users which are not admins are limited to the methods in
+	// rpcLimited.
+	isAdmin bool
+
+	// allowed is the set of methods the user is restricted to.  It is nil
+	// when the user has no allowlist.
+	allowed map[string]struct{}
+}
+
+// passwordHMAC returns the HMAC-SHA256 of the passed password keyed by the
+// passed salt.
+func passwordHMAC(salt, pass string) []byte {
+	mac := hmac.New(sha256.New, []byte(salt))
+	mac.Write([]byte(pass))
+	return mac.Sum(nil)
+}
+
+// newRPCUser returns a new RPC user with the passed plaintext credentials.  The
+// password is salted with random bytes and only its HMAC is kept.
+func newRPCUser(name, pass string, isAdmin bool) (*rpcUser, error) {
+	var saltBytes [rpcAuthSaltLen]byte
+	if _, err := rand.Read(saltBytes[:]); err != nil {
+		return nil, err
+	}
+	salt := hex.EncodeToString(saltBytes[:])
+	return &rpcUser{
+		name:     name,
+		salt:     salt,
+		passHMAC: passwordHMAC(salt, pass),
+		isAdmin:  isAdmin,
+	}, nil
+}
+
+// parseRPCAuth parses an admin RPC user from an --rpcauth entry of the form
+// USER:SALT$HMAC, where HMAC is the hex encoded HMAC-SHA256 of the password
+// keyed by SALT.
+func parseRPCAuth(entry string) (*rpcUser, error) {
+	colon := strings.Index(entry, ":")
+	dollar := strings.LastIndex(entry, "$")
+	if colon < 1 || dollar < colon+2 {
+		return nil, fmt.Errorf("malformed rpcauth entry %q -- must be "+
+			"of the form USER:SALT$HMAC", entry)
+	}
+	passHMAC, err := hex.DecodeString(entry[dollar+1:])
+	if err != nil || len(passHMAC) != sha256.Size {
+		return nil, fmt.Errorf("malformed rpcauth entry %q -- HMAC "+
+			"must be %d hex encoded bytes", entry, sha256.Size)
+	}
+	return &rpcUser{
+		name:     entry[:colon],
+		salt:     entry[colon+1 : dollar],
+		passHMAC: passHMAC,
+		isAdmin:  true,
+	}, nil
+}
+
+// parseRPCAllowList parses --rpcallowlist entries of the form
+// USER:METHOD,METHOD,... into the set of allowed methods of each user.  Several
+// entries for the same user restrict the user to the methods common to all of
+// them.  Unknown methods are rejected to catch typos.
+func parseRPCAllowList(entries []string) (map[string]map[string]struct{}, error) {
+	allowLists := make(map[string]map[string]struct{})
+	for _, entry := range entries {
+		colon := strings.Index(entry, ":")
+		if colon < 1 {
+			return nil, fmt.Errorf("malformed rpcallowlist entry %q "+
+				"-- must be of the form USER:METHOD,METHOD,...",
+				entry)
+		}
+		user := entry[:colon]
+
+		allowed := make(map[string]struct{})
+		for _, method := range strings.Split(entry[colon+1:], ",") {
+			method = strings.TrimSpace(method)
+			if method == "" {
+				continue
+			}
+			_, isStandard := rpcHandlers[method]
+			_, isWebsocket := wsHandlers[method]
+			if !isStandard && !isWebsocket {
+				return nil, fmt.Errorf("rpcallowlist entry %q "+
+					"contains unknown method %q", entry,
+					method)
+			}
+			allowed[method] = struct{}{}
+		}
+
+		// Intersect with the previous entries for the user.
+		if prev, ok := allowLists[user]; ok {
+			for method := range allowed {
+				if _, ok := prev[method]; !ok {
+					delete(allowed, method)
+				}
+			}
+		}
+		allowLists[user] = allowed
+	}
+	return allowLists, nil
+}
+
+// rpcAuthenticator authenticates RPC users by their credentials.
+type rpcAuthenticator struct {
+	users []*rpcUser
+}
+
+// newRPCAuthenticator returns a new RPC authenticator for the users configured
+// by the passed admin and limited credentials, --rpcauth entries, and
+// --rpcallowlist entries.  When cookiePass is not empty, an admin user for the
+// authentication cookie is added as well.
+func newRPCAuthenticator(adminUser, adminPass, limitUser, limitPass, cookiePass string,
+	rpcAuth, allowList []string) (*rpcAuthenticator, error) {
+
+	var users []*rpcUser
+	addUser := func(name, pass string, isAdmin bool) error {
+		user, err := newRPCUser(name, pass, isAdmin)
+		if err != nil {
+			return err
+		}
+		users = append(users, user)
+		return nil
+	}
+	if adminUser != "" && adminPass != "" {
+		if err := addUser(adminUser, adminPass, true); err != nil {
+			return nil, err
+		}
+	}
+	if limitUser != "" && limitPass != "" {
+		if err := addUser(limitUser, limitPass, false); err != nil {
+			return nil, err
+		}
+	}
+	if cookiePass != "" {
+		if err := addUser(cookieAuthUser, cookiePass, true); err != nil {
+			return nil, err
+		}
+	}
+	for _, entry := range rpcAuth {
+		user, err := parseRPCAuth(entry)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	// Usernames must be unique since they identify the allowlist of a
+	// user.
+	names := make(map[string]struct{}, len(users))
+	for _, user := range users {
+		if _, ok := names[user.name]; ok {
+			return nil, fmt.Errorf("RPC user %q is configured more "+
+				"than once", user.name)
+		}
+		names[user.name] = struct{}{}
+	}
+
+	allowLists, err := parseRPCAllowList(allowList)
+	if err != nil {
+		return nil, err
+	}
+	for name, allowed := range allowLists {
+		if _, ok := names[name]; !ok {
+			return nil, fmt.Errorf("rpcallowlist specifies unknown "+
+				"RPC user %q", name)
+		}
+		for _, user := range users {
+			if user.name == name {
+				user.allowed = allowed
+			}
+		}
+	}
+
+	return &rpcAuthenticator{users: users}, nil
+}
+
+// authenticate returns the user identified by the passed credentials, or nil
+// if they don't match any user.  Every user is checked regardless of earlier
+// matches so the time taken doesn't reveal which users exist.
+func (a *rpcAuthenticator) authenticate(name, pass string) *rpcUser {
+	var match *rpcUser
+	for _, user := range a.users {
+		nameCmp := subtle.ConstantTimeCompare([]byte(name),
+			[]byte(user.name))
+		passCmp := subtle.ConstantTimeCompare(passwordHMAC(user.salt,
+			pass), user.passHMAC)
+		if nameCmp&passCmp == 1 {
+			match = user
+		}
+	}
+	return match
+}
+
+// hasUser returns whether a user with the passed name is configured.
+func (a *rpcAuthenticator) hasUser(name string) bool {
+	for _, user := range a.users {
+		if user.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// authorize returns an error suitable for use in replies when the user may not
+// invoke the passed method, and nil otherwise.
+func (u *rpcUser) authorize(method string) *btcjson.RPCError {
+	if !u.isAdmin {
+		if _, ok := rpcLimited[method]; !ok {
+			return &btcjson.RPCError{
+				Code:    btcjson.ErrRPCInvalidParams.Code,
+				Message: "limited user not authorized for this method",
+			}
+		}
+	}
+	if u.allowed != nil {
+		if _, ok := u.allowed[method]; !ok {
+			return &btcjson.RPCError{
+				Code:    btcjson.ErrRPCInvalidParams.Code,
+				Message: "user not authorized for this method",
+			}
+		}
+	}
+	return nil
+}
+
+// writeAuthCookie generates random credentials for the cookie user and writes
+// them to the passed path in the form USER:PASS, readable by the owner only.
+// The generated password is returned.
+func writeAuthCookie(path string) (string, error) {
+	var passBytes [32]byte
+	if _, err := rand.Read(passBytes[:]); err != nil {
+		return "", err
+	}
+	pass := hex.EncodeToString(passBytes[:])
+
+	// Write to a temporary file which is renamed into place so readers
+	// never observe a partially written cookie.
+	tmpPath := path + ".tmp"
+	cookie := []byte(cookieAuthUser + ":" + pass)
+	if err := ioutil.WriteFile(tmpPath, cookie, 0600); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return "", err
+	}
+	return pass, nil
+}
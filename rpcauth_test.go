@@ -0,0 +1,179 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// TestRPCAuthenticator ensures users configured with plaintext credentials,
+// hashed rpcauth entries, and the authentication cookie are authenticated and
+// authorized as expected.
+func TestRPCAuthenticator(t *testing.T) {
+	t.Parallel()
+
+	// The rpcauth entry holds the HMAC-SHA256 of "secret" keyed by "salt".
+	rpcAuth := []string{"carol:salt$98e5340f0f4f96d2b80c2a90da0d03cf46c3" +
+		"5e9492918cc7af73d9a39efa5981"}
+	allowList := []string{
+		"carol:getblockcount,getblockhash,uptime",
+		"carol:getblockcount, uptime",
+		"bob:uptime,stop",
+	}
+	auth, err := newRPCAuthenticator("alice", "alicepass", "bob",
+		"bobpass", "cookiepass", rpcAuth, allowList)
+	if err != nil {
+		t.Fatalf("newRPCAuthenticator: unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		user       string
+		pass       string
+		authorized []string
+		denied     []string
+	}{
+		{
+			name:       "admin",
+			user:       "alice",
+			pass:       "alicepass",
+			authorized: []string{"stop", "uptime", "getblockcount"},
+		},
+		{
+			name:       "limited with allowlist",
+			user:       "bob",
+			pass:       "bobpass",
+			authorized: []string{"uptime"},
+			denied:     []string{"stop", "getblockcount"},
+		},
+		{
+			name:       "cookie",
+			user:       cookieAuthUser,
+			pass:       "cookiepass",
+			authorized: []string{"stop"},
+		},
+		{
+			name:       "rpcauth with allowlists",
+			user:       "carol",
+			pass:       "secret",
+			authorized: []string{"getblockcount", "uptime"},
+			denied:     []string{"getblockhash", "stop"},
+		},
+		{
+			name: "wrong password",
+			user: "alice",
+			pass: "bobpass",
+		},
+		{
+			name: "unknown user",
+			user: "dave",
+			pass: "secret",
+		},
+	}
+
+	for _, test := range tests {
+		user := auth.authenticate(test.user, test.pass)
+		if test.authorized == nil {
+			if user != nil {
+				t.Errorf("%s: unexpectedly authenticated as %q",
+					test.name, user.name)
+			}
+			continue
+		}
+		if user == nil || user.name != test.user {
+			t.Errorf("%s: failed to authenticate", test.name)
+			continue
+		}
+		for _, method := range test.authorized {
+			if err := user.authorize(method); err != nil {
+				t.Errorf("%s: unexpected error authorizing %s: %v",
+					test.name, method, err)
+			}
+		}
+		for _, method := range test.denied {
+			if err := user.authorize(method); err == nil {
+				t.Errorf("%s: unexpectedly authorized %s",
+					test.name, method)
+			}
+		}
+	}
+}
+
+// TestRPCAuthenticatorErrors ensures invalid rpcauth and rpcallowlist entries
+// are rejected.
+func TestRPCAuthenticatorErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		rpcAuth   []string
+		allowList []string
+	}{
+		{"rpcauth without salt", []string{"carol:abcd"}, nil},
+		{"rpcauth without user", []string{":salt$abcd"}, nil},
+		{"rpcauth with short hmac", []string{"carol:salt$abcd"}, nil},
+		{"rpcauth with duplicate user", []string{"alice:salt$98e5340f0f4f" +
+			"96d2b80c2a90da0d03cf46c35e9492918cc7af73d9a39efa5981"}, nil},
+		{"allowlist without user", nil, []string{"getblockcount"}},
+		{"allowlist for unknown user", nil, []string{"dave:uptime"}},
+		{"allowlist with unknown method", nil, []string{"alice:nosuch"}},
+	}
+
+	for _, test := range tests {
+		_, err := newRPCAuthenticator("alice", "alicepass", "", "", "",
+			test.rpcAuth, test.allowList)
+		if err == nil {
+			t.Errorf("%s: did not receive expected error", test.name)
+		}
+	}
+}
+
+// TestWriteAuthCookie ensures the authentication cookie is written with the
+// generated credentials and is only accessible by its owner.
+func TestWriteAuthCookie(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "rpcauth")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, defaultRPCCookieFilename)
+	pass, err := writeAuthCookie(path)
+	if err != nil {
+		t.Fatalf("writeAuthCookie: unexpected error: %v", err)
+	}
+	cookie, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unable to read cookie: %v", err)
+	}
+	if want := cookieAuthUser + ":" + pass; string(cookie) != want {
+		t.Errorf("unexpected cookie - got %q, want %q", cookie, want)
+	}
+	if len(pass) != 64 {
+		t.Errorf("unexpected cookie password length %d", len(pass))
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("unable to stat cookie: %v", err)
+	}
+	if perm := fi.Mode().Perm(); runtime.GOOS != "windows" && perm&0077 != 0 {
+		t.Errorf("cookie is accessible by others - mode %v", perm)
+	}
+
+	// A new cookie replaces the previous one.
+	pass2, err := writeAuthCookie(path)
+	if err != nil {
+		t.Fatalf("writeAuthCookie: unexpected error: %v", err)
+	}
+	if pass2 == pass {
+		t.Errorf("cookie password was not regenerated")
+	}
+}
@@ -0,0 +1,262 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/vertcoin/vtcd/blockchain"
+	"github.com/vertcoin/vtcd/btcjson"
+	"github.com/vertcoin/vtcd/wire"
+	"github.com/vertcoin/vtcutil"
+)
+
+// utxoOverhead is the estimated number of bytes, besides the serialized
+// output itself, taken by an entry of the utxo set.  It matches the value used
+// by Bitcoin Core for the utxo_size_inc statistic of getblockstats.
+const utxoOverhead = 41
+
+// int64Sorter implements sort.Interface to allow a slice of int64 values to be
+// sorted.
+type int64Sorter []int64
+
+// Len returns the number of values in the slice.  It is part of the
+// sort.Interface implementation.
+func (s int64Sorter) Len() int {
+	return len(s)
+}
+
+// Swap swaps the values at the passed indices.  It is part of the
+// sort.Interface implementation.
+func (s int64Sorter) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+
+// Less returns whether the value with index i should sort before the value
+// with index j.  It is part of the sort.Interface implementation.
+func (s int64Sorter) Less(i, j int) bool {
+	return s[i] < s[j]
+}
+
+// weightedFeeRate is the fee rate of a transaction along with its weight.
+type weightedFeeRate struct {
+	feeRate int64
+	weight  int64
+}
+
+// feeRatesByRate implements sort.Interface to allow a slice of weighted fee
+// rates to be sorted by fee rate.
+type feeRatesByRate []weightedFeeRate
+
+// Len returns the number of fee rates in the slice.  It is part of the
+// sort.Interface implementation.
+func (s feeRatesByRate) Len() int {
+	return len(s)
+}
+
+// Swap swaps the fee rates at the passed indices.  It is part of the
+// sort.Interface implementation.
+func (s feeRatesByRate) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+
+// Less returns whether the fee rate with index i should sort before the fee
+// rate with index j.  It is part of the sort.Interface implementation.
+func (s feeRatesByRate) Less(i, j int) bool {
+	return s[i].feeRate < s[j].feeRate
+}
+
+// truncatedMedian returns the median of the passed values, truncating the
+// average of the two middle values when their number is even, or zero when
+// there are no values.  The passed slice is sorted in place.
+func truncatedMedian(values []int64) int64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sort.Sort(int64Sorter(values))
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[mid-1] + values[mid]) / 2
+	}
+	return values[mid]
+}
+
+// feeRatePercentiles returns the fee rates at the 10th, 25th, 50th, 75th and
+// 90th percentiles of the passed fee rates weighted by the weight of their
+// transactions.  The passed slice is sorted in place.
+func feeRatePercentiles(feeRates []weightedFeeRate) [5]int64 {
+	var percentiles [5]int64
+	if len(feeRates) == 0 {
+		return percentiles
+	}
+
+	sort.Sort(feeRatesByRate(feeRates))
+	var totalWeight int64
+	for _, fr := range feeRates {
+		totalWeight += fr.weight
+	}
+	thresholds := [5]float64{
+		float64(totalWeight) / 10,
+		float64(totalWeight) / 4,
+		float64(totalWeight) / 2,
+		float64(totalWeight) * 3 / 4,
+		float64(totalWeight) * 9 / 10,
+	}
+
+	next := 0
+	var cumulativeWeight int64
+	for _, fr := range feeRates {
+		cumulativeWeight += fr.weight
+		for next < len(thresholds) &&
+			float64(cumulativeWeight) >= thresholds[next] {
+
+			percentiles[next] = fr.feeRate
+			next++
+		}
+	}
+	for ; next < len(percentiles); next++ {
+		percentiles[next] = feeRates[len(feeRates)-1].feeRate
+	}
+	return percentiles
+}
+
+// calcBlockStats computes the statistics of the passed block, whose inputs
+// spend the passed outputs in order, as returned by the getblockstats command.
+// Only the statistics which can be derived from the block transactions are
+// set.  The coinbase transaction only contributes to the output and utxo set
+// statistics.
+func calcBlockStats(blk *vtcutil.Block, spent []blockchain.SpentTxOut) (*btcjson.GetBlockStatsResult, error) {
+	txns := blk.Transactions()
+	stats := &btcjson.GetBlockStatsResult{
+		MinFee:     math.MaxInt64,
+		MinFeeRate: math.MaxInt64,
+		MinTxSize:  math.MaxInt64,
+		Txs:        int64(len(txns)),
+	}
+
+	fees := make([]int64, 0, len(txns))
+	txSizes := make([]int64, 0, len(txns))
+	feeRates := make([]weightedFeeRate, 0, len(txns))
+	for i, tx := range txns {
+		mtx := tx.MsgTx()
+		var totalOut int64
+		for _, txOut := range mtx.TxOut {
+			totalOut += txOut.Value
+			stats.UTXOSizeIncrease += int64(txOut.SerializeSize()) +
+				utxoOverhead
+		}
+		stats.Outs += int64(len(mtx.TxOut))
+		if i == 0 {
+			continue
+		}
+
+		var totalIn int64
+		for range mtx.TxIn {
+			if len(spent) == 0 {
+				return nil, errors.New("missing spent outputs")
+			}
+			txOut := spent[0]
+			spent = spent[1:]
+
+			totalIn += txOut.Amount
+			prevOut := wire.NewTxOut(txOut.Amount, txOut.PkScript)
+			stats.UTXOSizeIncrease -= int64(prevOut.SerializeSize()) +
+				utxoOverhead
+		}
+		stats.Ins += int64(len(mtx.TxIn))
+		stats.TotalOut += totalOut
+
+		txSize := int64(mtx.SerializeSize())
+		weight := blockchain.GetTransactionWeight(tx)
+		txSizes = append(txSizes, txSize)
+		stats.TotalSize += txSize
+		stats.TotalWeight += weight
+		if txSize < stats.MinTxSize {
+			stats.MinTxSize = txSize
+		}
+		if txSize > stats.MaxTxSize {
+			stats.MaxTxSize = txSize
+		}
+		if mtx.HasWitness() {
+			stats.SegWitTxs++
+			stats.SegWitTotalSize += txSize
+			stats.SegWitTotalWeight += weight
+		}
+
+		fee := totalIn - totalOut
+		fees = append(fees, fee)
+		stats.TotalFee += fee
+		if fee < stats.MinFee {
+			stats.MinFee = fee
+		}
+		if fee > stats.MaxFee {
+			stats.MaxFee = fee
+		}
+
+		var feeRate int64
+		if weight > 0 {
+			feeRate = fee * blockchain.WitnessScaleFactor / weight
+		}
+		feeRates = append(feeRates, weightedFeeRate{feeRate, weight})
+		if feeRate < stats.MinFeeRate {
+			stats.MinFeeRate = feeRate
+		}
+		if feeRate > stats.MaxFeeRate {
+			stats.MaxFeeRate = feeRate
+		}
+	}
+	if len(spent) != 0 {
+		return nil, fmt.Errorf("%d unexpected spent outputs", len(spent))
+	}
+
+	stats.UTXOIncrease = stats.Outs - stats.Ins
+	if len(fees) == 0 {
+		stats.MinFee = 0
+		stats.MinFeeRate = 0
+		stats.MinTxSize = 0
+	} else {
+		stats.AvgFee = stats.TotalFee / int64(len(fees))
+		stats.AvgTxSize = stats.TotalSize / int64(len(fees))
+	}
+	if stats.TotalWeight > 0 {
+		stats.AvgFeeRate = stats.TotalFee * blockchain.WitnessScaleFactor /
+			stats.TotalWeight
+	}
+	stats.MedianFee = truncatedMedian(fees)
+	stats.MedianTxSize = truncatedMedian(txSizes)
+	stats.FeeRatePercentiles = feeRatePercentiles(feeRates)
+
+	return stats, nil
+}
+
+// filterBlockStats returns the JSON object of the passed block statistics
+// reduced to the requested statistics.  An error is returned when an unknown
+// statistic is requested.
+func filterBlockStats(stats *btcjson.GetBlockStatsResult, selected []string) (map[string]json.RawMessage, error) {
+	marshalled, err := json.Marshal(stats)
+	if err != nil {
+		return nil, err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(marshalled, &all); err != nil {
+		return nil, err
+	}
+
+	filtered := make(map[string]json.RawMessage, len(selected))
+	for _, name := range selected {
+		value, ok := all[name]
+		if !ok {
+			return nil, fmt.Errorf("invalid selected statistic %q",
+				name)
+		}
+		filtered[name] = value
+	}
+	return filtered, nil
+}
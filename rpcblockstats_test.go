@@ -0,0 +1,192 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/vertcoin/vtcd/blockchain"
+	"github.com/vertcoin/vtcd/btcjson"
+	"github.com/vertcoin/vtcd/chaincfg/chainhash"
+	"github.com/vertcoin/vtcd/wire"
+	"github.com/vertcoin/vtcutil"
+)
+
+// TestTruncatedMedian ensures the median of a set of values is computed as
+// expected.
+func TestTruncatedMedian(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		values []int64
+		want   int64
+	}{
+		{values: nil, want: 0},
+		{values: []int64{7}, want: 7},
+		{values: []int64{9, 1, 5}, want: 5},
+		{values: []int64{4, 1, 8, 2}, want: 3},
+		{values: []int64{2, 1}, want: 1},
+	}
+
+	for i, test := range tests {
+		got := truncatedMedian(test.values)
+		if got != test.want {
+			t.Errorf("test #%d: unexpected median - got %d, want %d",
+				i, got, test.want)
+		}
+	}
+}
+
+// TestFeeRatePercentiles ensures the weighted fee rate percentiles are
+// computed as expected.
+func TestFeeRatePercentiles(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		feeRates []weightedFeeRate
+		want     [5]int64
+	}{
+		{
+			name: "no transactions",
+			want: [5]int64{0, 0, 0, 0, 0},
+		},
+		{
+			name:     "single transaction",
+			feeRates: []weightedFeeRate{{feeRate: 3, weight: 400}},
+			want:     [5]int64{3, 3, 3, 3, 3},
+		},
+		{
+			name: "weighted",
+			feeRates: []weightedFeeRate{
+				{feeRate: 1, weight: 100},
+				{feeRate: 5, weight: 100},
+				{feeRate: 10, weight: 200},
+				{feeRate: 2, weight: 600},
+			},
+			want: [5]int64{1, 2, 2, 5, 10},
+		},
+	}
+
+	for _, test := range tests {
+		got := feeRatePercentiles(test.feeRates)
+		if got != test.want {
+			t.Errorf("%s: unexpected percentiles - got %v, want %v",
+				test.name, got, test.want)
+		}
+	}
+}
+
+// TestCalcBlockStats ensures the statistics of a block are computed from its
+// transactions and the outputs they spend.
+func TestCalcBlockStats(t *testing.T) {
+	t.Parallel()
+
+	pkScript := []byte{
+		0x76, 0xa9, 0x14, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x88, 0xac,
+	}
+	coinbase := wire.NewMsgTx(1)
+	coinbase.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{},
+		wire.MaxPrevOutIndex), []byte{0x51}, nil))
+	coinbase.AddTxOut(wire.NewTxOut(5000000000, pkScript))
+	coinbase.AddTxOut(wire.NewTxOut(0, []byte{0x6a}))
+
+	legacy := wire.NewMsgTx(1)
+	legacy.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{1}, 0),
+		[]byte{0x51}, nil))
+	legacy.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{1}, 1),
+		[]byte{0x51}, nil))
+	legacy.AddTxOut(wire.NewTxOut(140000, pkScript))
+
+	segwit := wire.NewMsgTx(1)
+	segwit.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{2}, 0),
+		nil, wire.TxWitness{{0x01, 0x02}}))
+	segwit.AddTxOut(wire.NewTxOut(49000, pkScript))
+
+	msgBlock := wire.NewMsgBlock(&wire.BlockHeader{})
+	for _, mtx := range []*wire.MsgTx{coinbase, legacy, segwit} {
+		msgBlock.AddTransaction(mtx)
+	}
+	blk := vtcutil.NewBlock(msgBlock)
+	spent := []blockchain.SpentTxOut{
+		{Amount: 100000, PkScript: pkScript},
+		{Amount: 50000, PkScript: pkScript},
+		{Amount: 50000, PkScript: pkScript},
+	}
+
+	stats, err := calcBlockStats(blk, spent)
+	if err != nil {
+		t.Fatalf("calcBlockStats: unexpected error: %v", err)
+	}
+
+	txns := blk.Transactions()
+	legacySize := int64(legacy.SerializeSize())
+	segwitSize := int64(segwit.SerializeSize())
+	legacyWeight := blockchain.GetTransactionWeight(txns[1])
+	segwitWeight := blockchain.GetTransactionWeight(txns[2])
+	legacyFeeRate := 10000 * blockchain.WitnessScaleFactor / legacyWeight
+	segwitFeeRate := 1000 * blockchain.WitnessScaleFactor / segwitWeight
+	want := &btcjson.GetBlockStatsResult{
+		AvgFee: 5500,
+		AvgFeeRate: 11000 * blockchain.WitnessScaleFactor /
+			(legacyWeight + segwitWeight),
+		AvgTxSize: (legacySize + segwitSize) / 2,
+		FeeRatePercentiles: [5]int64{segwitFeeRate, segwitFeeRate,
+			legacyFeeRate, legacyFeeRate, legacyFeeRate},
+		Ins:               3,
+		MaxFee:            10000,
+		MaxFeeRate:        legacyFeeRate,
+		MaxTxSize:         legacySize,
+		MedianFee:         5500,
+		MedianTxSize:      (legacySize + segwitSize) / 2,
+		MinFee:            1000,
+		MinFeeRate:        segwitFeeRate,
+		MinTxSize:         segwitSize,
+		Outs:              4,
+		SegWitTotalSize:   segwitSize,
+		SegWitTotalWeight: segwitWeight,
+		SegWitTxs:         1,
+		TotalOut:          189000,
+		TotalSize:         legacySize + segwitSize,
+		TotalWeight:       legacyWeight + segwitWeight,
+		TotalFee:          11000,
+		Txs:               3,
+		UTXOIncrease:      1,
+
+		// Besides the null data output, the block creates as many
+		// outputs as it spends, all of them with the same size.
+		UTXOSizeIncrease: int64(wire.NewTxOut(0, []byte{0x6a}).SerializeSize()) +
+			utxoOverhead,
+	}
+	if !reflect.DeepEqual(stats, want) {
+		t.Fatalf("calcBlockStats: unexpected stats - got %+v, want %+v",
+			stats, want)
+	}
+
+	// The spent outputs must match the inputs of the block.
+	if _, err := calcBlockStats(blk, spent[:2]); err == nil {
+		t.Fatal("calcBlockStats: missing spent outputs not detected")
+	}
+	if _, err := calcBlockStats(blk, append(spent, spent[0])); err == nil {
+		t.Fatal("calcBlockStats: unexpected spent outputs not detected")
+	}
+
+	// Only the requested statistics are kept when filtering.
+	filtered, err := filterBlockStats(stats, []string{"totalfee", "txs"})
+	if err != nil {
+		t.Fatalf("filterBlockStats: unexpected error: %v", err)
+	}
+	if len(filtered) != 2 || string(filtered["totalfee"]) != "11000" ||
+		string(filtered["txs"]) != "3" {
+
+		t.Fatalf("filterBlockStats: unexpected stats %v", filtered)
+	}
+	if _, err := filterBlockStats(stats, []string{"bogus"}); err == nil {
+		t.Fatal("filterBlockStats: unknown statistic not rejected")
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rpcclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// batchResponse is a partially-unmarshaled response to one of the requests of
+// a JSON-RPC batch.  The ID is used to deliver it to the matching request since
+// servers are not required to answer the requests of a batch in order.
+type batchResponse struct {
+	ID *uint64 `json:"id"`
+	rawResponse
+}
+
+// NewBatch creates a new RPC client which queues requests instead of sending
+// them immediately.  The async functions of the returned client return their
+// futures as usual, however, the requests are only sent to the server, as a
+// single JSON-RPC batch in one HTTP POST request, when Send is invoked.
+// Receiving from a future before the batch has been sent blocks forever.
+//
+// Batches are only supported in HTTP POST mode, so ErrNotHTTPPostClient is
+// returned when the configuration does not have HTTPPostMode set.
+func NewBatch(config *ConnConfig) (*Client, error) {
+	if !config.HTTPPostMode {
+		return nil, ErrNotHTTPPostClient
+	}
+
+	// Notifications are not available in HTTP POST mode, so there are no
+	// notification handlers.
+	client, err := New(config, nil)
+	if err != nil {
+		return nil, err
+	}
+	client.batch = true
+	return client, nil
+}
+
+// failBatch delivers the passed error to every request of a batch and returns
+// it.
+func failBatch(requests []*jsonRequest, err error) error {
+	for _, jReq := range requests {
+		jReq.responseChan <- &response{err: err}
+	}
+	return err
+}
+
+// Send sends all requests queued by a batch client since the previous call as
+// a single JSON-RPC batch and delivers the responses to their futures.  The
+// queue is emptied, so the client may be reused for further batches.
+//
+// The error of each request is delivered by its future.  An error is only
+// returned when the batch as a whole failed, in which case it is also delivered
+// to every future of the batch.
+func (c *Client) Send() error {
+	if !c.batch {
+		return ErrNotBatchClient
+	}
+
+	c.batchLock.Lock()
+	requests := c.batchList
+	c.batchList = nil
+	c.batchLock.Unlock()
+	if len(requests) == 0 {
+		return nil
+	}
+
+	// Don't send the batch if shutting down.
+	select {
+	case <-c.shutdown:
+		return failBatch(requests, ErrClientShutdown)
+	default:
+	}
+
+	// Marshal the queued requests as a JSON array.
+	var marshalledJSON bytes.Buffer
+	marshalledJSON.WriteByte('[')
+	for i, jReq := range requests {
+		if i > 0 {
+			marshalledJSON.WriteByte(',')
+		}
+		marshalledJSON.Write(jReq.marshalledJSON)
+	}
+	marshalledJSON.WriteByte(']')
+
+	httpReq, err := c.newPostRequest(marshalledJSON.Bytes())
+	if err != nil {
+		return failBatch(requests, err)
+	}
+	log.Tracef("Sending batch of %d commands", len(requests))
+	httpResponse, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return failBatch(requests, err)
+	}
+
+	// Read the raw bytes and close the response.
+	respBytes, err := ioutil.ReadAll(httpResponse.Body)
+	httpResponse.Body.Close()
+	if err != nil {
+		err = fmt.Errorf("error reading json reply: %v", err)
+		return failBatch(requests, err)
+	}
+
+	// The server answers a batch with an array of responses unless it
+	// rejects the batch as a whole, in which case a single response
+	// carries the error.
+	var responses []batchResponse
+	if err := json.Unmarshal(respBytes, &responses); err != nil {
+		var resp rawResponse
+		if json.Unmarshal(respBytes, &resp) == nil && resp.Error != nil {
+			return failBatch(requests, resp.Error)
+		}
+
+		// When the response itself isn't a valid JSON-RPC response
+		// return an error which includes the HTTP status code and raw
+		// response bytes.
+		err = fmt.Errorf("status code: %d, response: %q",
+			httpResponse.StatusCode, string(respBytes))
+		return failBatch(requests, err)
+	}
+
+	// Deliver each response to the request with the same ID.  Requests
+	// the server did not answer fail individually.
+	pending := make(map[uint64]*jsonRequest, len(requests))
+	for _, jReq := range requests {
+		pending[jReq.id] = jReq
+	}
+	for _, resp := range responses {
+		if resp.ID == nil {
+			continue
+		}
+		jReq, ok := pending[*resp.ID]
+		if !ok {
+			continue
+		}
+		delete(pending, *resp.ID)
+
+		res, err := resp.result()
+		jReq.responseChan <- &response{result: res, err: err}
+	}
+	for id, jReq := range pending {
+		err := fmt.Errorf("no response to request id %d", id)
+		jReq.responseChan <- &response{err: err}
+	}
+	return nil
+}
@@ -97,7 +97,8 @@ func (c *Client) GetBlockAsync(blockHash *chainhash.Hash) FutureGetBlockResult {
 		hash = blockHash.String()
 	}
 
-	cmd := btcjson.NewGetBlockCmd(hash, btcjson.Bool(false), nil)
+	cmd := btcjson.NewGetBlockCmd(hash,
+		btcjson.Verbosity(btcjson.BlockVerbosityHex), nil)
 	return c.sendCmd(cmd)
 }
 
@@ -141,7 +142,8 @@ func (c *Client) GetBlockVerboseAsync(blockHash *chainhash.Hash) FutureGetBlockV
 		hash = blockHash.String()
 	}
 
-	cmd := btcjson.NewGetBlockCmd(hash, btcjson.Bool(true), nil)
+	cmd := btcjson.NewGetBlockCmd(hash,
+		btcjson.Verbosity(btcjson.BlockVerbosityJSON), nil)
 	return c.sendCmd(cmd)
 }
 
@@ -165,7 +167,8 @@ func (c *Client) GetBlockVerboseTxAsync(blockHash *chainhash.Hash) FutureGetBloc
 		hash = blockHash.String()
 	}
 
-	cmd := btcjson.NewGetBlockCmd(hash, btcjson.Bool(true), btcjson.Bool(true))
+	cmd := btcjson.NewGetBlockCmd(hash,
+		btcjson.Verbosity(btcjson.BlockVerbosityJSON), btcjson.Bool(true))
 	return c.sendCmd(cmd)
 }
 
@@ -178,6 +181,97 @@ func (c *Client) GetBlockVerboseTx(blockHash *chainhash.Hash) (*btcjson.GetBlock
 	return c.GetBlockVerboseTxAsync(blockHash).Receive()
 }
 
+// FutureGetBlockVerboseTxPrevOutResult is a future promise to deliver the
+// result of a GetBlockVerboseTxPrevOutAsync RPC invocation (or an applicable
+// error).
+type FutureGetBlockVerboseTxPrevOutResult chan *response
+
+// Receive waits for the response promised by the future and returns the data
+// structure from the server with information about the requested block and its
+// transactions, including the previous outputs spent by their inputs.
+func (r FutureGetBlockVerboseTxPrevOutResult) Receive() (*btcjson.GetBlockVerboseTxResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal the raw result into a GetBlockVerboseTxResult.
+	var blockResult btcjson.GetBlockVerboseTxResult
+	err = json.Unmarshal(res, &blockResult)
+	if err != nil {
+		return nil, err
+	}
+	return &blockResult, nil
+}
+
+// GetBlockVerboseTxPrevOutAsync returns an instance of a type that can be used
+// to get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See GetBlockVerboseTxPrevOut for the blocking version and more details.
+func (c *Client) GetBlockVerboseTxPrevOutAsync(blockHash *chainhash.Hash) FutureGetBlockVerboseTxPrevOutResult {
+	hash := ""
+	if blockHash != nil {
+		hash = blockHash.String()
+	}
+
+	cmd := btcjson.NewGetBlockCmd(hash,
+		btcjson.Verbosity(btcjson.BlockVerbosityTxPrevOut), nil)
+	return c.sendCmd(cmd)
+}
+
+// GetBlockVerboseTxPrevOut returns a data structure from the server with
+// information about a block and its transactions given its hash.  Unlike
+// GetBlockVerboseTx, the inputs of the transactions include the previous
+// outputs they spend, and the transactions include their fees.
+//
+// See GetBlockVerboseTx if the previous outputs aren't needed.
+func (c *Client) GetBlockVerboseTxPrevOut(blockHash *chainhash.Hash) (*btcjson.GetBlockVerboseTxResult, error) {
+	return c.GetBlockVerboseTxPrevOutAsync(blockHash).Receive()
+}
+
+// FutureGetBlockStatsResult is a future promise to deliver the result of a
+// GetBlockStatsAsync RPC invocation (or an applicable error).
+type FutureGetBlockStatsResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// statistics of the requested block.
+func (r FutureGetBlockStatsResult) Receive() (*btcjson.GetBlockStatsResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal the raw result into a GetBlockStatsResult.
+	var stats btcjson.GetBlockStatsResult
+	err = json.Unmarshal(res, &stats)
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// GetBlockStatsAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See GetBlockStats for the blocking version and more details.
+func (c *Client) GetBlockStatsAsync(hashOrHeight btcjson.HashOrHeight, stats []string) FutureGetBlockStatsResult {
+	var statsPtr *[]string
+	if len(stats) != 0 {
+		statsPtr = &stats
+	}
+	cmd := btcjson.NewGetBlockStatsCmd(hashOrHeight, statsPtr)
+	return c.sendCmd(cmd)
+}
+
+// GetBlockStats returns the statistics of a block given its hash or its height
+// in the main chain.  When stats isn't empty, only the named statistics are
+// requested and the others are left zero.
+func (c *Client) GetBlockStats(hashOrHeight btcjson.HashOrHeight, stats []string) (*btcjson.GetBlockStatsResult, error) {
+	return c.GetBlockStatsAsync(hashOrHeight, stats).Receive()
+}
+
 // FutureGetBlockCountResult is a future promise to deliver the result of a
 // GetBlockCountAsync RPC invocation (or an applicable error).
 type FutureGetBlockCountResult chan *response
@@ -890,3 +984,116 @@ func (c *Client) GetCFilterHeaderAsync(blockHash *chainhash.Hash, extended bool)
 func (c *Client) GetCFilterHeader(blockHash *chainhash.Hash, extended bool) (*wire.MsgCFHeaders, error) {
 	return c.GetCFilterHeaderAsync(blockHash, extended).Receive()
 }
+
+// FutureScanTxOutSetResult is a future promise to deliver the result of a
+// ScanTxOutSetAsync RPC invocation (or an applicable error).
+type FutureScanTxOutSetResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// unspent transaction outputs found by the scan.
+func (r FutureScanTxOutSetResult) Receive() (*btcjson.ScanTxOutSetResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal the raw result into a ScanTxOutSetResult.
+	var result btcjson.ScanTxOutSetResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ScanTxOutSetAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See ScanTxOutSet for the blocking version and more details.
+func (c *Client) ScanTxOutSetAsync(scanObjects []btcjson.ScanObject) FutureScanTxOutSetResult {
+	cmd := btcjson.NewScanTxOutSetCmd(btcjson.ScanStart, &scanObjects)
+	return c.sendCmd(cmd)
+}
+
+// ScanTxOutSet scans the unspent transaction output set of the server for the
+// outputs paying to the scripts of the passed output descriptors.  The server
+// only runs one scan at a time, which can be aborted with ScanTxOutSetAbort.
+func (c *Client) ScanTxOutSet(scanObjects []btcjson.ScanObject) (*btcjson.ScanTxOutSetResult, error) {
+	return c.ScanTxOutSetAsync(scanObjects).Receive()
+}
+
+// FutureScanTxOutSetAbortResult is a future promise to deliver the result of a
+// ScanTxOutSetAbortAsync RPC invocation (or an applicable error).
+type FutureScanTxOutSetAbortResult chan *response
+
+// Receive waits for the response promised by the future and returns whether a
+// scan was in progress and has been aborted.
+func (r FutureScanTxOutSetAbortResult) Receive() (bool, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return false, err
+	}
+
+	// Unmarshal the result as a bool.
+	var aborted bool
+	err = json.Unmarshal(res, &aborted)
+	if err != nil {
+		return false, err
+	}
+	return aborted, nil
+}
+
+// ScanTxOutSetAbortAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function
+// on the returned instance.
+//
+// See ScanTxOutSetAbort for the blocking version and more details.
+func (c *Client) ScanTxOutSetAbortAsync() FutureScanTxOutSetAbortResult {
+	cmd := btcjson.NewScanTxOutSetCmd(btcjson.ScanAbort, nil)
+	return c.sendCmd(cmd)
+}
+
+// ScanTxOutSetAbort aborts the scan of the unspent transaction output set in
+// progress on the server and returns whether there was one.
+func (c *Client) ScanTxOutSetAbort() (bool, error) {
+	return c.ScanTxOutSetAbortAsync().Receive()
+}
+
+// FutureScanTxOutSetStatusResult is a future promise to deliver the result of
+// a ScanTxOutSetStatusAsync RPC invocation (or an applicable error).
+type FutureScanTxOutSetStatusResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// progress of the scan in progress, or nil when there is none.
+func (r FutureScanTxOutSetStatusResult) Receive() (*btcjson.ScanTxOutSetStatusResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal the raw result into a ScanTxOutSetStatusResult.  The result
+	// is null when no scan is in progress.
+	var status *btcjson.ScanTxOutSetStatusResult
+	err = json.Unmarshal(res, &status)
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// ScanTxOutSetStatusAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function
+// on the returned instance.
+//
+// See ScanTxOutSetStatus for the blocking version and more details.
+func (c *Client) ScanTxOutSetStatusAsync() FutureScanTxOutSetStatusResult {
+	cmd := btcjson.NewScanTxOutSetCmd(btcjson.ScanStatus, nil)
+	return c.sendCmd(cmd)
+}
+
+// ScanTxOutSetStatus returns the progress of the scan of the unspent
+// transaction output set in progress on the server, or nil when there is none.
+func (c *Client) ScanTxOutSetStatus() (*btcjson.ScanTxOutSetStatusResult, error) {
+	return c.ScanTxOutSetStatusAsync().Receive()
+}
@@ -57,6 +57,15 @@ the Receive method on the returned instance will either return the result
 immediately if it has already arrived, or block until it has.  This is useful
 since it provides the caller with greater control over concurrency.
 
+Batch Requests
+
+A client created with NewBatch queues the requests issued through its async
+functions instead of sending them.  Invoking Send delivers all queued requests
+to the server as a single JSON-RPC batch in one HTTP POST request and then
+resolves their futures.  This saves a round trip per request when many
+independent requests are needed at once.  Batch clients must be configured for
+HTTP POST mode.
+
 Notifications
 
 The first important part of notifications is to realize that they will only
@@ -93,6 +102,12 @@ notifications are automatically re-registered and any in-flight commands are
 re-issued.  This means from the caller's perspective, the request simply takes
 longer to complete.
 
+When notifications have been registered, the client first tries to resume the
+session of the lost connection instead.  Servers which support resuming
+sessions then replay the notifications missed while disconnected, so none of
+them are lost as long as the client reconnects within the grace period of the
+server.
+
 The caller may invoke the Shutdown method on the client to force the client
 to cease reconnect attempts and return ErrClientShutdown for all outstanding
 commands.
@@ -422,6 +422,33 @@ func (c *Client) Session() (*btcjson.SessionResult, error) {
 	return c.SessionAsync().Receive()
 }
 
+// ResumeSessionAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See ResumeSession for the blocking version and more details.
+//
+// NOTE: This is a ltcd extension and requires a websocket connection.
+func (c *Client) ResumeSessionAsync(sessionID uint64) FutureSessionResult {
+	// Not supported in HTTP POST mode.
+	if c.config.HTTPPostMode {
+		return newFutureError(ErrWebsocketsRequired)
+	}
+
+	cmd := btcjson.NewResumeSessionCmd(sessionID)
+	return c.sendCmd(cmd)
+}
+
+// ResumeSession resumes the session with the passed ID of a previous websocket
+// connection.  The server takes over the notifications registered by the
+// previous connection and replays the ones it missed.  Note that the client
+// does this automatically when it reconnects.
+//
+// NOTE: This is a ltcd extension and requires a websocket connection.
+func (c *Client) ResumeSession(sessionID uint64) (*btcjson.SessionResult, error) {
+	return c.ResumeSessionAsync(sessionID).Receive()
+}
+
 // FutureVersionResult is a future promise to delivere the result of a version
 // RPC invocation (or an applicable error).
 //
@@ -19,6 +19,7 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -69,6 +70,17 @@ var (
 	// client having already connected to the RPC server.
 	ErrClientAlreadyConnected = errors.New("websocket client has already " +
 		"connected")
+
+	// ErrNotHTTPPostClient is an error to describe the condition of
+	// creating a batch client with a configuration which does not run in
+	// HTTP POST mode.
+	ErrNotHTTPPostClient = errors.New("client is not configured for " +
+		"HTTP POST mode")
+
+	// ErrNotBatchClient is an error to describe the condition of calling
+	// a Client method intended for a batch client when the client sends
+	// each request immediately instead.
+	ErrNotBatchClient = errors.New("client is not a batch client")
 )
 
 const (
@@ -149,6 +161,19 @@ type Client struct {
 	ntfnStateLock sync.Mutex
 	ntfnState     *notificationState
 
+	// sessionID is the ID of the server session the notifications are
+	// registered in, which is resumed on reconnect, or nil when it is
+	// unknown.  sessionRequested is set once it has been requested.  Both
+	// are protected by the notification state lock.
+	sessionID        *uint64
+	sessionRequested bool
+
+	// Batch mode.  Requests are queued in batchList until Send is called
+	// when batch is set.
+	batch     bool
+	batchLock sync.Mutex
+	batchList []*jsonRequest
+
 	// Networking infrastructure.
 	sendChan        chan []byte
 	sendPostChan    chan *sendPostDetails
@@ -258,9 +283,42 @@ func (c *Client) trackRegisteredNtfns(cmd interface{}) {
 		for _, addr := range bcmd.Addresses {
 			c.ntfnState.notifyReceived[addr] = struct{}{}
 		}
+
+	default:
+		return
+	}
+
+	// The session ID is needed to resume the session, and with it the
+	// registered notifications, after reconnecting.  Request it once
+	// notifications have been registered.  The reply is recorded by
+	// trackSession.
+	if c.sessionID == nil && !c.sessionRequested {
+		c.sessionRequested = true
+		go c.SessionAsync()
 	}
 }
 
+// trackSession examines the passed command to see if it is a session command,
+// and if it is, records the session ID of the passed result so the session can
+// be resumed on reconnect.
+func (c *Client) trackSession(cmd interface{}, result json.RawMessage) {
+	// Nothing to do if the caller is not interested in notifications.
+	if c.ntfnHandlers == nil {
+		return
+	}
+	if _, ok := cmd.(*btcjson.SessionCmd); !ok {
+		return
+	}
+
+	var session btcjson.SessionResult
+	if err := json.Unmarshal(result, &session); err != nil {
+		return
+	}
+	c.ntfnStateLock.Lock()
+	c.sessionID = &session.SessionID
+	c.ntfnStateLock.Unlock()
+}
+
 type (
 	// inMessage is the first type that an incoming message is unmarshaled
 	// into. It supports both requests (for notification support) and
@@ -368,6 +426,9 @@ func (c *Client) handleMessage(msg []byte) {
 
 	// Deliver the response.
 	result, err := in.rawResponse.result()
+	if err == nil {
+		c.trackSession(request.cmd, result)
+	}
 	request.responseChan <- &response{result: result, err: err}
 }
 
@@ -552,6 +613,35 @@ func (c *Client) reregisterNtfns() error {
 	return nil
 }
 
+// resumeSession tries to resume the server session of the previous connection,
+// which keeps the registered notifications and replays the ones missed while
+// disconnected.  It returns whether the session was resumed.  It should only
+// be called on reconnect by the resendRequests function.
+func (c *Client) resumeSession() bool {
+	// Nothing to do if the caller is not interested in notifications.
+	if c.ntfnHandlers == nil {
+		return false
+	}
+
+	// Forget the session ID, so the ID of the new session is requested
+	// once notifications are reregistered when resuming fails.
+	c.ntfnStateLock.Lock()
+	sessionID := c.sessionID
+	c.sessionID = nil
+	c.sessionRequested = false
+	c.ntfnStateLock.Unlock()
+	if sessionID == nil {
+		return false
+	}
+
+	if _, err := c.ResumeSession(*sessionID); err != nil {
+		log.Infof("Unable to resume session %d: %v", *sessionID, err)
+		return false
+	}
+	log.Debugf("Resumed session %d", *sessionID)
+	return true
+}
+
 // ignoreResends is a set of all methods for requests that are "long running"
 // are not be reissued by the client on reconnect.
 var ignoreResends = map[string]struct{}{
@@ -562,12 +652,16 @@ var ignoreResends = map[string]struct{}{
 // disconnected.  It is intended to be called once the client has reconnected as
 // a separate goroutine.
 func (c *Client) resendRequests() {
-	// Set the notification state back up.  If anything goes wrong,
-	// disconnect the client.
-	if err := c.reregisterNtfns(); err != nil {
-		log.Warnf("Unable to re-establish notification state: %v", err)
-		c.Disconnect()
-		return
+	// Resume the previous session if possible.  Otherwise, set the
+	// notification state back up.  If anything goes wrong, disconnect the
+	// client.
+	if !c.resumeSession() {
+		if err := c.reregisterNtfns(); err != nil {
+			log.Warnf("Unable to re-establish notification state: "+
+				"%v", err)
+			c.Disconnect()
+			return
+		}
 	}
 
 	// Since it's possible to block on send and more requests might be
@@ -608,10 +702,11 @@ func (c *Client) resendRequests() {
 
 // wsReconnectHandler listens for client disconnects and automatically tries
 // to reconnect with retry interval that scales based on the number of retries.
-// It also resends any commands that had not completed when the client
-// disconnected so the disconnect/reconnect process is largely transparent to
-// the caller.  This function is not run when the DisableAutoReconnect config
-// options is set.
+// It also resumes the session of the lost connection, or reregisters the
+// notifications when that fails, and resends any commands that had not
+// completed when the client disconnected so the disconnect/reconnect process
+// is largely transparent to the caller.  This function is not run when the
+// DisableAutoReconnect config options is set.
 //
 // This function must be run as a goroutine.
 func (c *Client) wsReconnectHandler() {
@@ -796,29 +891,43 @@ func receiveFuture(f chan *response) ([]byte, error) {
 	return r.result, r.err
 }
 
-// sendPost sends the passed request to the server by issuing an HTTP POST
-// request using the provided response channel for the reply.  Typically a new
-// connection is opened and closed for each command when using this method,
-// however, the underlying HTTP client might coalesce multiple commands
-// depending on several factors including the remote server configuration.
-func (c *Client) sendPost(jReq *jsonRequest) {
+// newPostRequest returns an HTTP POST request to the configured RPC server with
+// the passed marshalled JSON as its body.
+func (c *Client) newPostRequest(marshalledJSON []byte) (*http.Request, error) {
 	// Generate a request to the configured RPC server.
 	protocol := "http"
 	if !c.config.DisableTLS {
 		protocol = "https"
 	}
 	url := protocol + "://" + c.config.Host
-	bodyReader := bytes.NewReader(jReq.marshalledJSON)
+	bodyReader := bytes.NewReader(marshalledJSON)
 	httpReq, err := http.NewRequest("POST", url, bodyReader)
 	if err != nil {
-		jReq.responseChan <- &response{result: nil, err: err}
-		return
+		return nil, err
 	}
 	httpReq.Close = true
 	httpReq.Header.Set("Content-Type", "application/json")
 
 	// Configure basic access authorization.
-	httpReq.SetBasicAuth(c.config.User, c.config.Pass)
+	user, pass, err := c.config.getAuth()
+	if err != nil {
+		return nil, err
+	}
+	httpReq.SetBasicAuth(user, pass)
+	return httpReq, nil
+}
+
+// sendPost sends the passed request to the server by issuing an HTTP POST
+// request using the provided response channel for the reply.  Typically a new
+// connection is opened and closed for each command when using this method,
+// however, the underlying HTTP client might coalesce multiple commands
+// depending on several factors including the remote server configuration.
+func (c *Client) sendPost(jReq *jsonRequest) {
+	httpReq, err := c.newPostRequest(jReq.marshalledJSON)
+	if err != nil {
+		jReq.responseChan <- &response{result: nil, err: err}
+		return
+	}
 
 	log.Tracef("Sending command [%s] with id %d", jReq.method, jReq.id)
 	c.sendPostRequest(httpReq, jReq)
@@ -828,6 +937,15 @@ func (c *Client) sendPost(jReq *jsonRequest) {
 // provided response channel for the reply.  It handles both websocket and HTTP
 // POST mode depending on the configuration of the client.
 func (c *Client) sendRequest(jReq *jsonRequest) {
+	// Queue the request until the batch is sent when running in batch
+	// mode.
+	if c.batch {
+		c.batchLock.Lock()
+		c.batchList = append(c.batchList, jReq)
+		c.batchLock.Unlock()
+		return
+	}
+
 	// Choose which marshal and send function to use depending on whether
 	// the client running in HTTP POST mode or not.  When running in HTTP
 	// POST mode, the command is issued via an HTTP client.  Otherwise,
@@ -1063,6 +1181,12 @@ type ConnConfig struct {
 	// Pass is the passphrase to use to authenticate to the RPC server.
 	Pass string
 
+	// CookiePath is the path of an RPC authentication cookie file written
+	// by the RPC server.  When set, the credentials are read from the file
+	// for every connection instead of using User and Pass, so a new cookie
+	// written by a restarted server is picked up automatically.
+	CookiePath string
+
 	// DisableTLS specifies whether transport layer security should be
 	// disabled.  It is recommended to always use TLS if the RPC server
 	// supports it as otherwise your username and password is sent across
@@ -1111,6 +1235,25 @@ type ConnConfig struct {
 	EnableBCInfoHacks bool
 }
 
+// getAuth returns the username and passphrase to authenticate to the RPC
+// server with.  They are read from the cookie file when CookiePath is set.
+func (config *ConnConfig) getAuth() (string, string, error) {
+	if config.CookiePath == "" {
+		return config.User, config.Pass, nil
+	}
+
+	cookie, err := ioutil.ReadFile(config.CookiePath)
+	if err != nil {
+		return "", "", err
+	}
+	parts := strings.SplitN(strings.TrimSpace(string(cookie)), ":", 2)
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("malformed cookie file %s",
+			config.CookiePath)
+	}
+	return parts[0], parts[1], nil
+}
+
 // newHTTPClient returns a new http client that is configured according to the
 // proxy and TLS settings in the associated connection configuration.
 func newHTTPClient(config *ConnConfig) (*http.Client, error) {
@@ -1180,7 +1323,11 @@ func dial(config *ConnConfig) (*websocket.Conn, error) {
 
 	// The RPC server requires basic authorization, so create a custom
 	// request header with the Authorization header set.
-	login := config.User + ":" + config.Pass
+	user, pass, err := config.getAuth()
+	if err != nil {
+		return nil, err
+	}
+	login := user + ":" + pass
 	auth := "Basic " + base64.StdEncoding.EncodeToString([]byte(login))
 	requestHeader := make(http.Header)
 	requestHeader.Add("Authorization", auth)
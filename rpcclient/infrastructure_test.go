@@ -0,0 +1,208 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rpcclient
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/websocket"
+	"github.com/vertcoin/vtcd/btcjson"
+)
+
+// wsTestServer is a websocket RPC server which hands out a fixed session ID,
+// records the requests it receives, and replies to all other requests with a
+// null result.
+type wsTestServer struct {
+	*httptest.Server
+
+	sessionID uint64
+	resumable bool
+	requests  chan *btcjson.Request
+
+	mtx   sync.Mutex
+	conns []*websocket.Conn
+}
+
+// newWSTestServer returns a started websocket RPC server which hands out the
+// passed session ID and allows resuming it when resumable is set.
+func newWSTestServer(sessionID uint64, resumable bool) *wsTestServer {
+	s := &wsTestServer{
+		sessionID: sessionID,
+		resumable: resumable,
+		requests:  make(chan *btcjson.Request, 100),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// handle serves the requests of a websocket connection.
+func (s *wsTestServer) handle(w http.ResponseWriter, r *http.Request) {
+	conn, err := websocket.Upgrade(w, r, nil, 0, 0)
+	if err != nil {
+		return
+	}
+	s.mtx.Lock()
+	s.conns = append(s.conns, conn)
+	s.mtx.Unlock()
+
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var request btcjson.Request
+		if err := json.Unmarshal(msg, &request); err != nil {
+			return
+		}
+		select {
+		case s.requests <- &request:
+		default:
+		}
+
+		var result interface{}
+		var rpcErr *btcjson.RPCError
+		if request.Method == "session" {
+			result = &btcjson.SessionResult{SessionID: s.sessionID}
+			if len(request.Params) != 0 && !s.resumable {
+				result = nil
+				rpcErr = &btcjson.RPCError{
+					Code:    btcjson.ErrRPCInvalidParameter,
+					Message: "Unknown or expired session",
+				}
+			}
+		}
+		reply, err := btcjson.MarshalResponse(request.ID, result, rpcErr)
+		if err != nil {
+			return
+		}
+		if err := conn.WriteMessage(websocket.TextMessage, reply); err != nil {
+			return
+		}
+	}
+}
+
+// dropConns closes all websocket connections to the server.
+func (s *wsTestServer) dropConns() {
+	s.mtx.Lock()
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+	s.conns = nil
+	s.mtx.Unlock()
+}
+
+// nextRequest returns the next request received by the server with the passed
+// method, along with the methods of the requests received before it.
+func (s *wsTestServer) nextRequest(t *testing.T, method string) (*btcjson.Request, []string) {
+	var skipped []string
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case request := <-s.requests:
+			if request.Method == method {
+				return request, skipped
+			}
+			skipped = append(skipped, request.Method)
+		case <-timeout:
+			t.Fatalf("timeout waiting for %s request", method)
+		}
+	}
+}
+
+// newWSTestClient returns a client connected to the passed server which has
+// registered for block notifications and learned the ID of its session.
+func newWSTestClient(t *testing.T, s *wsTestServer) *Client {
+	c, err := New(&ConnConfig{
+		Host:       strings.TrimPrefix(s.URL, "http://"),
+		Endpoint:   "ws",
+		User:       "user",
+		Pass:       "pass",
+		DisableTLS: true,
+	}, &NotificationHandlers{})
+	if err != nil {
+		t.Fatalf("unable to create client: %v", err)
+	}
+	if err := c.NotifyBlocks(); err != nil {
+		c.Shutdown()
+		t.Fatalf("NotifyBlocks: unexpected error: %v", err)
+	}
+	waitSessionID(t, c, s.sessionID)
+	return c
+}
+
+// waitSessionID waits until the passed client learned the passed session ID.
+func waitSessionID(t *testing.T, c *Client, sessionID uint64) {
+	for i := 0; i < 1000; i++ {
+		c.ntfnStateLock.Lock()
+		id := c.sessionID
+		c.ntfnStateLock.Unlock()
+		if id != nil && *id == sessionID {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("client did not learn session ID %d", sessionID)
+}
+
+// TestResumeSessionOnReconnect ensures a client resumes the session of its
+// lost connection after reconnecting instead of reregistering notifications.
+func TestResumeSessionOnReconnect(t *testing.T) {
+	s := newWSTestServer(42, true)
+	defer s.Close()
+	c := newWSTestClient(t, s)
+	defer c.Shutdown()
+
+	s.dropConns()
+	request, _ := s.nextRequest(t, "session")
+	if len(request.Params) != 1 || string(request.Params[0]) != "42" {
+		t.Fatalf("unexpected session request params %s",
+			request.Params)
+	}
+	waitSessionID(t, c, 42)
+
+	// No notifications are reregistered when the session is resumed.
+	if err := c.Ping(); err != nil {
+		t.Fatalf("Ping: unexpected error: %v", err)
+	}
+	_, skipped := s.nextRequest(t, "ping")
+	for _, method := range skipped {
+		if method == "notifyblocks" {
+			t.Fatal("notifications reregistered after resuming " +
+				"the session")
+		}
+	}
+}
+
+// TestReregisterOnFailedResume ensures a client reregisters its notifications
+// after reconnecting when the session of its lost connection can't be resumed.
+func TestReregisterOnFailedResume(t *testing.T) {
+	s := newWSTestServer(42, false)
+	defer s.Close()
+	c := newWSTestClient(t, s)
+	defer c.Shutdown()
+
+	s.dropConns()
+	request, _ := s.nextRequest(t, "session")
+	if len(request.Params) != 1 {
+		t.Fatalf("unexpected session request params %s",
+			request.Params)
+	}
+	s.nextRequest(t, "notifyblocks")
+
+	// The ID of the new session is requested once the notifications are
+	// reregistered.
+	request, _ = s.nextRequest(t, "session")
+	if len(request.Params) != 0 {
+		t.Fatalf("unexpected session request params %s",
+			request.Params)
+	}
+	waitSessionID(t, c, 42)
+}
@@ -5,12 +5,14 @@
 package rpcclient
 
 import (
+	"bytes"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 
 	"github.com/vertcoin/vtcd/btcjson"
 	"github.com/vertcoin/vtcd/chaincfg/chainhash"
+	"github.com/vertcoin/vtcd/wire"
 	"github.com/vertcoin/vtcutil"
 )
 
@@ -61,6 +63,65 @@ func (c *Client) Generate(numBlocks uint32) ([]*chainhash.Hash, error) {
 	return c.GenerateAsync(numBlocks).Receive()
 }
 
+// GenerateToAddressAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GenerateToAddress for the blocking version and more details.
+func (c *Client) GenerateToAddressAsync(numBlocks uint32, address vtcutil.Address) FutureGenerateResult {
+	cmd := btcjson.NewGenerateToAddressCmd(numBlocks, address.EncodeAddress())
+	return c.sendCmd(cmd)
+}
+
+// GenerateToAddress generates numBlocks blocks which pay to the passed address
+// and returns their hashes.
+func (c *Client) GenerateToAddress(numBlocks uint32, address vtcutil.Address) ([]*chainhash.Hash, error) {
+	return c.GenerateToAddressAsync(numBlocks, address).Receive()
+}
+
+// FutureGenerateBlockResult is a future promise to deliver the result of a
+// GenerateBlockAsync RPC invocation (or an applicable error).
+type FutureGenerateBlockResult chan *response
+
+// Receive waits for the response promised by the future and returns the hash
+// of the generated block.
+func (r FutureGenerateBlockResult) Receive() (*chainhash.Hash, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a generateblock result object.
+	var result btcjson.GenerateBlockResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return chainhash.NewHashFromStr(result.Hash)
+}
+
+// GenerateBlockAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GenerateBlock for the blocking version and more details.
+func (c *Client) GenerateBlockAsync(address vtcutil.Address, transactions []string) FutureGenerateBlockResult {
+	if transactions == nil {
+		transactions = []string{}
+	}
+	cmd := btcjson.NewGenerateBlockCmd(address.EncodeAddress(), transactions)
+	return c.sendCmd(cmd)
+}
+
+// GenerateBlock generates a block which pays to the passed address and contains
+// exactly the passed transactions in order, and returns its hash.  Each
+// transaction is either the id of a transaction in the memory pool of the
+// server or a hex-encoded raw transaction.
+func (c *Client) GenerateBlock(address vtcutil.Address, transactions []string) (*chainhash.Hash, error) {
+	return c.GenerateBlockAsync(address, transactions).Receive()
+}
+
 // FutureGetGenerateResult is a future promise to deliver the result of a
 // GetGenerateAsync RPC invocation (or an applicable error).
 type FutureGetGenerateResult chan *response
@@ -164,6 +225,46 @@ func (c *Client) GetHashesPerSec() (int64, error) {
 	return c.GetHashesPerSecAsync().Receive()
 }
 
+// FutureGetHashesPerSecVerboseResult is a future promise to deliver the result
+// of a GetHashesPerSecVerboseAsync RPC invocation (or an applicable error).
+type FutureGetHashesPerSecVerboseResult chan *response
+
+// Receive waits for the response promised by the future and returns a recent
+// hashes per second performance measurement of the server and each of its CPU
+// mining workers.
+func (r FutureGetHashesPerSecVerboseResult) Receive() (*btcjson.GetHashesPerSecVerboseResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a gethashespersec verbose result object.
+	var result btcjson.GetHashesPerSecVerboseResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetHashesPerSecVerboseAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See GetHashesPerSecVerbose for the blocking version and more details.
+func (c *Client) GetHashesPerSecVerboseAsync() FutureGetHashesPerSecVerboseResult {
+	cmd := btcjson.NewGetHashesPerSecVerboseCmd()
+	return c.sendCmd(cmd)
+}
+
+// GetHashesPerSecVerbose returns a recent hashes per second performance
+// measurement while generating coins (mining) along with the performance of
+// each CPU mining worker of the server.
+func (c *Client) GetHashesPerSecVerbose() (*btcjson.GetHashesPerSecVerboseResult, error) {
+	return c.GetHashesPerSecVerboseAsync().Receive()
+}
+
 // FutureGetMiningInfoResult is a future promise to deliver the result of a
 // GetMiningInfoAsync RPC invocation (or an applicable error).
 type FutureGetMiningInfoResult chan *response
@@ -363,6 +464,39 @@ func (c *Client) GetWorkSubmit(data string) (bool, error) {
 	return c.GetWorkSubmitAsync(data).Receive()
 }
 
+// FuturePrioritiseTransactionResult is a future promise to deliver the result
+// of a PrioritiseTransactionAsync RPC invocation (or an applicable error).
+type FuturePrioritiseTransactionResult chan *response
+
+// Receive waits for the response promised by the future and returns an error if
+// any occurred when prioritising the transaction.
+func (r FuturePrioritiseTransactionResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
+}
+
+// PrioritiseTransactionAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See PrioritiseTransaction for the blocking version and more details.
+func (c *Client) PrioritiseTransactionAsync(txHash *chainhash.Hash, feeDelta int64) FuturePrioritiseTransactionResult {
+	hash := ""
+	if txHash != nil {
+		hash = txHash.String()
+	}
+
+	cmd := btcjson.NewPrioritiseTransactionCmd(hash, feeDelta)
+	return c.sendCmd(cmd)
+}
+
+// PrioritiseTransaction adds the fee delta in satoshi to the fee the server
+// uses for the transaction when accepting it into the memory pool and when
+// selecting transactions for new blocks.
+func (c *Client) PrioritiseTransaction(txHash *chainhash.Hash, feeDelta int64) error {
+	return c.PrioritiseTransactionAsync(txHash, feeDelta).Receive()
+}
+
 // FutureSubmitBlockResult is a future promise to deliver the result of a
 // SubmitBlockAsync RPC invocation (or an applicable error).
 type FutureSubmitBlockResult chan *response
@@ -414,4 +548,111 @@ func (c *Client) SubmitBlock(block *vtcutil.Block, options *btcjson.SubmitBlockO
 	return c.SubmitBlockAsync(block, options).Receive()
 }
 
-// TODO(davec): Implement GetBlockTemplate
+// FutureBlockValidityResult is a future promise to deliver the result of a
+// SubmitHeaderAsync or TestBlockValidityAsync RPC invocation (or an
+// applicable error).
+type FutureBlockValidityResult chan *response
+
+// Receive waits for the response promised by the future and returns whether
+// the header or block is valid along with the rule it violates if not.
+func (r FutureBlockValidityResult) Receive() (*btcjson.BlockValidityResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a block validity result object.
+	var result btcjson.BlockValidityResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// SubmitHeaderAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See SubmitHeader for the blocking version and more details.
+func (c *Client) SubmitHeaderAsync(header *wire.BlockHeader) FutureBlockValidityResult {
+	var buf bytes.Buffer
+	if err := header.Serialize(&buf); err != nil {
+		return newFutureError(err)
+	}
+
+	cmd := btcjson.NewSubmitHeaderCmd(hex.EncodeToString(buf.Bytes()))
+	return c.sendCmd(cmd)
+}
+
+// SubmitHeader submits a block header to the server, which checks its proof of
+// work and difficulty and adds it to its known headers when it is valid.
+func (c *Client) SubmitHeader(header *wire.BlockHeader) (*btcjson.BlockValidityResult, error) {
+	return c.SubmitHeaderAsync(header).Receive()
+}
+
+// TestBlockValidityAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See TestBlockValidity for the blocking version and more details.
+func (c *Client) TestBlockValidityAsync(block *vtcutil.Block, checkPoW bool) FutureBlockValidityResult {
+	blockBytes, err := block.Bytes()
+	if err != nil {
+		return newFutureError(err)
+	}
+
+	cmd := btcjson.NewTestBlockValidityCmd(hex.EncodeToString(blockBytes),
+		&checkPoW)
+	return c.sendCmd(cmd)
+}
+
+// TestBlockValidity checks whether a block which extends the best block of the
+// server is valid without submitting it.  The proof of work check is skipped
+// when checkPoW is false, which allows block templates to be checked before
+// they are solved.
+func (c *Client) TestBlockValidity(block *vtcutil.Block, checkPoW bool) (*btcjson.BlockValidityResult, error) {
+	return c.TestBlockValidityAsync(block, checkPoW).Receive()
+}
+
+// FutureGetBlockTemplateResult is a future promise to deliver the result of a
+// GetBlockTemplateAsync RPC invocation (or an applicable error).
+type FutureGetBlockTemplateResult chan *response
+
+// Receive waits for the response promised by the future and returns the block
+// template requested from the server.
+func (r FutureGetBlockTemplateResult) Receive() (*btcjson.GetBlockTemplateResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a getblocktemplate result object.
+	var result btcjson.GetBlockTemplateResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetBlockTemplateAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetBlockTemplate for the blocking version and more details.
+func (c *Client) GetBlockTemplateAsync(request *btcjson.TemplateRequest) FutureGetBlockTemplateResult {
+	cmd := btcjson.NewGetBlockTemplateCmd(request)
+	return c.sendCmd(cmd)
+}
+
+// GetBlockTemplate returns a new block template to mine on as defined by BIP
+// 0022.  The request may list the BIP 0009 rules the caller supports, which is
+// required once a rule that needs explicit client support is active.  The
+// result includes the active rules, the deployments being voted on, the block
+// limits and the witness commitment once segwit is active.
+func (c *Client) GetBlockTemplate(request *btcjson.TemplateRequest) (*btcjson.GetBlockTemplateResult, error) {
+	return c.GetBlockTemplateAsync(request).Receive()
+}
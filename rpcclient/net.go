@@ -247,6 +247,45 @@ func (c *Client) GetPeerInfo() ([]btcjson.GetPeerInfoResult, error) {
 	return c.GetPeerInfoAsync().Receive()
 }
 
+// FutureGetPeerBanScoreResult is a future promise to deliver the result of a
+// GetPeerBanScoreAsync RPC invocation (or an applicable error).
+type FutureGetPeerBanScoreResult chan *response
+
+// Receive waits for the response promised by the future and returns the ban
+// score and misbehavior history of the connected peers.
+func (r FutureGetPeerBanScoreResult) Receive() ([]btcjson.GetPeerBanScoreResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as an array of getpeerbanscore result objects.
+	var banScores []btcjson.GetPeerBanScoreResult
+	err = json.Unmarshal(res, &banScores)
+	if err != nil {
+		return nil, err
+	}
+
+	return banScores, nil
+}
+
+// GetPeerBanScoreAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetPeerBanScore for the blocking version and more details.
+func (c *Client) GetPeerBanScoreAsync(id *int32) FutureGetPeerBanScoreResult {
+	cmd := btcjson.NewGetPeerBanScoreCmd(id)
+	return c.sendCmd(cmd)
+}
+
+// GetPeerBanScore returns the ban score of each connected network peer along
+// with the history of misbehavior which increased it.  Only the peer with the
+// given node ID is returned when id is not nil.
+func (c *Client) GetPeerBanScore(id *int32) ([]btcjson.GetPeerBanScoreResult, error) {
+	return c.GetPeerBanScoreAsync(id).Receive()
+}
+
 // FutureGetNetTotalsResult is a future promise to deliver the result of a
 // GetNetTotalsAsync RPC invocation (or an applicable error).
 type FutureGetNetTotalsResult chan *response
@@ -283,3 +322,152 @@ func (c *Client) GetNetTotalsAsync() FutureGetNetTotalsResult {
 func (c *Client) GetNetTotals() (*btcjson.GetNetTotalsResult, error) {
 	return c.GetNetTotalsAsync().Receive()
 }
+
+// SetBanCommand enumerates the available commands that the SetBan function
+// accepts.
+type SetBanCommand string
+
+// Constants used to indicate the command for the SetBan function.
+const (
+	// SBAdd indicates the specified subnet should be banned.
+	SBAdd SetBanCommand = "add"
+
+	// SBRemove indicates the specified subnet should be unbanned.
+	SBRemove SetBanCommand = "remove"
+)
+
+// String returns the SetBanCommand in human-readable form.
+func (cmd SetBanCommand) String() string {
+	return string(cmd)
+}
+
+// FutureSetBanResult is a future promise to deliver the result of a
+// SetBanAsync RPC invocation (or an applicable error).
+type FutureSetBanResult chan *response
+
+// Receive waits for the response promised by the future and returns an error if
+// any occurred when performing the specified command.
+func (r FutureSetBanResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
+}
+
+// SetBanAsync returns an instance of a type that can be used to get the result
+// of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See SetBan for the blocking version and more details.
+func (c *Client) SetBanAsync(subnet string, command SetBanCommand, banTime int64, absolute bool) FutureSetBanResult {
+	cmd := btcjson.NewSetBanCmd(subnet, btcjson.SetBanSubCmd(command),
+		&banTime, &absolute)
+	return c.sendCmd(cmd)
+}
+
+// SetBan attempts to perform the passed command on the passed IP address or
+// subnet.  When adding a ban, banTime is the number of seconds to ban for, or a
+// unix timestamp when absolute is true.  A banTime of zero uses the server's
+// default ban duration.
+func (c *Client) SetBan(subnet string, command SetBanCommand, banTime int64, absolute bool) error {
+	return c.SetBanAsync(subnet, command, banTime, absolute).Receive()
+}
+
+// FutureListBannedResult is a future promise to deliver the result of a
+// ListBannedAsync RPC invocation (or an applicable error).
+type FutureListBannedResult chan *response
+
+// Receive waits for the response promised by the future and returns the list
+// of banned IP addresses and subnets.
+func (r FutureListBannedResult) Receive() ([]btcjson.ListBannedResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal as an array of listbanned result objects.
+	var banned []btcjson.ListBannedResult
+	err = json.Unmarshal(res, &banned)
+	if err != nil {
+		return nil, err
+	}
+
+	return banned, nil
+}
+
+// ListBannedAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See ListBanned for the blocking version and more details.
+func (c *Client) ListBannedAsync() FutureListBannedResult {
+	cmd := btcjson.NewListBannedCmd()
+	return c.sendCmd(cmd)
+}
+
+// ListBanned returns all banned IP addresses and subnets.
+func (c *Client) ListBanned() ([]btcjson.ListBannedResult, error) {
+	return c.ListBannedAsync().Receive()
+}
+
+// FutureClearBannedResult is a future promise to deliver the result of a
+// ClearBannedAsync RPC invocation (or an applicable error).
+type FutureClearBannedResult chan *response
+
+// Receive waits for the response promised by the future and returns an error if
+// any occurred when clearing the ban list.
+func (r FutureClearBannedResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
+}
+
+// ClearBannedAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See ClearBanned for the blocking version and more details.
+func (c *Client) ClearBannedAsync() FutureClearBannedResult {
+	cmd := btcjson.NewClearBannedCmd()
+	return c.sendCmd(cmd)
+}
+
+// ClearBanned removes all IP addresses and subnets from the ban list.
+func (c *Client) ClearBanned() error {
+	return c.ClearBannedAsync().Receive()
+}
+
+// FutureGetRPCInfoResult is a future promise to deliver the result of a
+// GetRPCInfoAsync RPC invocation (or an applicable error).
+type FutureGetRPCInfoResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// commands being serviced by the RPC server.
+func (r FutureGetRPCInfoResult) Receive() (*btcjson.GetRPCInfoResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a getrpcinfo result object.
+	var info btcjson.GetRPCInfoResult
+	err = json.Unmarshal(res, &info)
+	if err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// GetRPCInfoAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See GetRPCInfo for the blocking version and more details.
+func (c *Client) GetRPCInfoAsync() FutureGetRPCInfoResult {
+	cmd := btcjson.NewGetRPCInfoCmd()
+	return c.sendCmd(cmd)
+}
+
+// GetRPCInfo returns the commands being serviced by the RPC server along with
+// how long they have been running.
+func (c *Client) GetRPCInfo() (*btcjson.GetRPCInfoResult, error) {
+	return c.GetRPCInfoAsync().Receive()
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vertcoin/vtcd/btcjson"
+)
+
+// rpcRateLimitAny matches every user or method in an --rpcratelimit entry.
+const rpcRateLimitAny = "*"
+
+// rpcRateLimit is a token bucket limit on the rate at which RPC users may
+// invoke methods.  Every user matched by the limit has its own bucket.  When
+// the limit matches any method, all methods invoked by a user share the
+// bucket of the user.
+type rpcRateLimit struct {
+	user   string
+	method string
+
+	// rate is the number of tokens added to a bucket per second, and burst
+	// is the number of tokens a bucket holds at most.
+	rate  float64
+	burst float64
+}
+
+// matches returns whether the limit applies to calls of the passed method by
+// the passed user.
+func (l *rpcRateLimit) matches(user, method string) bool {
+	return (l.user == rpcRateLimitAny || l.user == user) &&
+		(l.method == rpcRateLimitAny || l.method == method)
+}
+
+// parseRPCRateLimits parses --rpcratelimit entries of the form
+// USER:METHOD:RATE[:BURST], where USER and METHOD may be * to match all users
+// and methods, RATE is the number of calls per second, and BURST is the number
+// of calls which may be made at once.  BURST defaults to RATE rounded up.
+// Unknown users and methods are rejected to catch typos.
+func parseRPCRateLimits(entries []string, auth *rpcAuthenticator) ([]rpcRateLimit, error) {
+	limits := make([]rpcRateLimit, 0, len(entries))
+	for _, entry := range entries {
+		fields := strings.Split(entry, ":")
+		if (len(fields) != 3 && len(fields) != 4) || fields[0] == "" ||
+			fields[1] == "" {
+
+			return nil, fmt.Errorf("malformed rpcratelimit entry %q "+
+				"-- must be of the form USER:METHOD:RATE[:BURST]",
+				entry)
+		}
+
+		user := fields[0]
+		if user != rpcRateLimitAny && !auth.hasUser(user) {
+			return nil, fmt.Errorf("rpcratelimit entry %q specifies "+
+				"unknown RPC user %q", entry, user)
+		}
+
+		method := fields[1]
+		if method != rpcRateLimitAny {
+			_, isStandard := rpcHandlers[method]
+			_, isWebsocket := wsHandlers[method]
+			if !isStandard && !isWebsocket {
+				return nil, fmt.Errorf("rpcratelimit entry %q "+
+					"specifies unknown method %q", entry,
+					method)
+			}
+		}
+
+		rate, err := strconv.ParseFloat(fields[2], 64)
+		if err != nil || rate <= 0 || math.IsInf(rate, 0) {
+			return nil, fmt.Errorf("rpcratelimit entry %q has invalid "+
+				"rate %q -- must be a positive number of calls "+
+				"per second", entry, fields[2])
+		}
+		burst := math.Ceil(rate)
+		if len(fields) == 4 {
+			n, err := strconv.ParseUint(fields[3], 10, 32)
+			if err != nil || n == 0 {
+				return nil, fmt.Errorf("rpcratelimit entry %q has "+
+					"invalid burst %q -- must be a positive "+
+					"integer", entry, fields[3])
+			}
+			burst = float64(n)
+		}
+
+		limits = append(limits, rpcRateLimit{
+			user:   user,
+			method: method,
+			rate:   rate,
+			burst:  burst,
+		})
+	}
+	return limits, nil
+}
+
+// tokenBucket tracks the tokens available to a user under a rate limit.
+type tokenBucket struct {
+	tokens     float64
+	lastUpdate time.Time
+}
+
+// rpcBucketKey identifies the bucket of a user under a rate limit by the index
+// of the limit.
+type rpcBucketKey struct {
+	limit int
+	user  string
+}
+
+// rpcRateLimiter enforces the rate limits of RPC calls.
+type rpcRateLimiter struct {
+	limits []rpcRateLimit
+
+	// now returns the current time.  It is replaced in tests.
+	now func() time.Time
+
+	mtx     sync.Mutex
+	buckets map[rpcBucketKey]*tokenBucket
+}
+
+// newRPCRateLimiter returns a new rate limiter enforcing the passed limits.
+func newRPCRateLimiter(limits []rpcRateLimit) *rpcRateLimiter {
+	return &rpcRateLimiter{
+		limits:  limits,
+		now:     time.Now,
+		buckets: make(map[rpcBucketKey]*tokenBucket),
+	}
+}
+
+// allow takes a token from the bucket of every limit matching a call of the
+// passed method by the passed user.  An error suitable for use in replies is
+// returned without taking any tokens when one of the buckets is empty.
+//
+// This function is safe for concurrent access.
+func (r *rpcRateLimiter) allow(user, method string) *btcjson.RPCError {
+	if len(r.limits) == 0 {
+		return nil
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	now := r.now()
+	var matched []*tokenBucket
+	for i := range r.limits {
+		limit := &r.limits[i]
+		if !limit.matches(user, method) {
+			continue
+		}
+
+		// Buckets start out full and are refilled at the rate of the
+		// limit up to its burst.
+		key := rpcBucketKey{limit: i, user: user}
+		bucket, ok := r.buckets[key]
+		if !ok {
+			bucket = &tokenBucket{tokens: limit.burst, lastUpdate: now}
+			r.buckets[key] = bucket
+		}
+		elapsed := now.Sub(bucket.lastUpdate).Seconds()
+		if elapsed > 0 {
+			bucket.tokens = math.Min(limit.burst,
+				bucket.tokens+elapsed*limit.rate)
+			bucket.lastUpdate = now
+		}
+
+		if bucket.tokens < 1 {
+			return &btcjson.RPCError{
+				Code: btcjson.ErrRPCRateLimited,
+				Message: fmt.Sprintf("rate limit of %v calls per "+
+					"second exceeded for method %s",
+					limit.rate, method),
+			}
+		}
+		matched = append(matched, bucket)
+	}
+
+	for _, bucket := range matched {
+		bucket.tokens--
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vertcoin/vtcd/btcjson"
+)
+
+// TestRPCRateLimiter ensures calls are limited per user and method according
+// to the configured token buckets, which are refilled over time.
+func TestRPCRateLimiter(t *testing.T) {
+	t.Parallel()
+
+	auth, err := newRPCAuthenticator("alice", "alicepass", "bob",
+		"bobpass", "", nil, nil)
+	if err != nil {
+		t.Fatalf("newRPCAuthenticator: unexpected error: %v", err)
+	}
+	limits, err := parseRPCRateLimits([]string{
+		"*:searchrawtransactions:0.5:2",
+		"bob:*:1:3",
+	}, auth)
+	if err != nil {
+		t.Fatalf("parseRPCRateLimits: unexpected error: %v", err)
+	}
+	limiter := newRPCRateLimiter(limits)
+	now := time.Unix(1500000000, 0)
+	limiter.now = func() time.Time { return now }
+
+	tests := []struct {
+		name    string
+		advance time.Duration
+		user    string
+		method  string
+		allowed bool
+	}{
+		// Every user has their own bucket for the method limit.
+		{"alice burst 1", 0, "alice", "searchrawtransactions", true},
+		{"alice burst 2", 0, "alice", "searchrawtransactions", true},
+		{"alice exhausted", 0, "alice", "searchrawtransactions", false},
+		{"alice unlimited method", 0, "alice", "getblockcount", true},
+
+		// Calls of bob take tokens of both limits, and a call rejected
+		// by one limit doesn't take tokens of the others.
+		{"bob burst 1", 0, "bob", "searchrawtransactions", true},
+		{"bob burst 2", 0, "bob", "searchrawtransactions", true},
+		{"bob method limit", 0, "bob", "searchrawtransactions", false},
+		{"bob other method", 0, "bob", "getblockcount", true},
+		{"bob user limit", 0, "bob", "uptime", false},
+
+		// Buckets are refilled at their rate.
+		{"alice not refilled", time.Second, "alice",
+			"searchrawtransactions", false},
+		{"alice refilled", time.Second, "alice",
+			"searchrawtransactions", true},
+		{"bob refilled", 0, "bob", "uptime", true},
+	}
+
+	for _, test := range tests {
+		now = now.Add(test.advance)
+		jsonErr := limiter.allow(test.user, test.method)
+		if test.allowed && jsonErr != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, jsonErr)
+			continue
+		}
+		if !test.allowed && (jsonErr == nil ||
+			jsonErr.Code != btcjson.ErrRPCRateLimited) {
+
+			t.Errorf("%s: unexpected error - got %v, want code %d",
+				test.name, jsonErr, btcjson.ErrRPCRateLimited)
+		}
+	}
+}
+
+// TestParseRPCRateLimitsErrors ensures invalid rpcratelimit entries are
+// rejected.
+func TestParseRPCRateLimitsErrors(t *testing.T) {
+	t.Parallel()
+
+	auth, err := newRPCAuthenticator("alice", "alicepass", "", "", "", nil,
+		nil)
+	if err != nil {
+		t.Fatalf("newRPCAuthenticator: unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		entry string
+	}{
+		{"missing rate", "alice:uptime"},
+		{"too many fields", "alice:uptime:1:2:3"},
+		{"missing user", ":uptime:1"},
+		{"unknown user", "dave:uptime:1"},
+		{"unknown method", "alice:nosuch:1"},
+		{"zero rate", "alice:uptime:0"},
+		{"invalid rate", "alice:uptime:fast"},
+		{"zero burst", "alice:uptime:1:0"},
+		{"fractional burst", "alice:uptime:1:1.5"},
+	}
+
+	for _, test := range tests {
+		_, err := parseRPCRateLimits([]string{test.entry}, auth)
+		if err == nil {
+			t.Errorf("%s: did not receive expected error", test.name)
+		}
+	}
+}
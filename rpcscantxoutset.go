@@ -0,0 +1,610 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/vertcoin/vtcd/blockchain"
+	"github.com/vertcoin/vtcd/btcec"
+	"github.com/vertcoin/vtcd/btcjson"
+	"github.com/vertcoin/vtcd/chaincfg"
+	"github.com/vertcoin/vtcd/txscript"
+	"github.com/vertcoin/vtcd/wire"
+	"github.com/vertcoin/vtcutil"
+	"github.com/vertcoin/vtcutil/hdkeychain"
+)
+
+const (
+	// defaultScanRangeEnd is the last child index derived by ranged
+	// descriptors when no range is specified.
+	defaultScanRangeEnd = 1000
+
+	// maxScanRangeSize is the maximum number of child indices derived by
+	// all ranged descriptors of a scan combined.
+	maxScanRangeSize = 1000000
+
+	// descriptorInputCharset and descriptorChecksumCharset are the
+	// character sets of output descriptors and of their checksums.
+	descriptorInputCharset = "0123456789()[],'/*abcdefgh@:$%{}" +
+		"IJKLMNOPQRSTUVWXYZ&+-.;<=>?!^_|~" +
+		"ijklmnopqrstuvwxyzABCDEFGH`#\"\\ "
+	descriptorChecksumCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+)
+
+// errScanAborted is returned when a scan of the utxo set is aborted.
+var errScanAborted = errors.New("scan aborted")
+
+// descriptorPolyMod updates the checksum state c of an output descriptor with
+// the passed 5-bit value.
+func descriptorPolyMod(c uint64, val uint64) uint64 {
+	c0 := c >> 35
+	c = ((c & 0x7ffffffff) << 5) ^ val
+	if c0&1 != 0 {
+		c ^= 0xf5dee51989
+	}
+	if c0&2 != 0 {
+		c ^= 0xa9fdca3312
+	}
+	if c0&4 != 0 {
+		c ^= 0x1bab10e32d
+	}
+	if c0&8 != 0 {
+		c ^= 0x3706b1677a
+	}
+	if c0&16 != 0 {
+		c ^= 0x644d626ffd
+	}
+	return c
+}
+
+// descriptorChecksum returns the checksum of the passed output descriptor as
+// defined by Bitcoin Core.  It returns false when the descriptor contains
+// characters which can't be part of a descriptor.
+func descriptorChecksum(desc string) (string, bool) {
+	c := uint64(1)
+	var class, classCount uint64
+	for _, ch := range desc {
+		pos := strings.IndexRune(descriptorInputCharset, ch)
+		if pos < 0 {
+			return "", false
+		}
+		c = descriptorPolyMod(c, uint64(pos)&31)
+		class = class*3 + uint64(pos)>>5
+		classCount++
+		if classCount == 3 {
+			c = descriptorPolyMod(c, class)
+			class = 0
+			classCount = 0
+		}
+	}
+	if classCount > 0 {
+		c = descriptorPolyMod(c, class)
+	}
+	for i := 0; i < 8; i++ {
+		c = descriptorPolyMod(c, 0)
+	}
+	c ^= 1
+
+	checksum := make([]byte, 8)
+	for i := range checksum {
+		checksum[i] = descriptorChecksumCharset[(c>>(5*uint(7-i)))&31]
+	}
+	return string(checksum), true
+}
+
+// descriptorKey is a key expression of an output descriptor.  It is either a
+// public key, or an extended key along with a derivation path which may end
+// with a wildcard.
+type descriptorKey struct {
+	// expr is the key expression as written in the descriptor.
+	expr string
+
+	// pubKey is the serialized public key of key expressions which don't
+	// end with a wildcard.
+	pubKey []byte
+
+	// extKey is the extended key derived with the path of key expressions
+	// ending with a wildcard, which are derived with hardened child
+	// indices when hardened is set.
+	extKey   *hdkeychain.ExtendedKey
+	hardened bool
+}
+
+// parseDescriptorKey parses the passed key expression, which may be a
+// hex-encoded public key, a WIF-encoded private key, or an extended key
+// followed by a derivation path, optionally prefixed by the origin of the key.
+func parseDescriptorKey(expr string, params *chaincfg.Params) (*descriptorKey, error) {
+	key := &descriptorKey{expr: expr}
+
+	// Key origins only document where keys come from, so they are skipped.
+	if strings.HasPrefix(expr, "[") {
+		end := strings.Index(expr, "]")
+		if end < 0 {
+			return nil, fmt.Errorf("key origin of %q is not closed",
+				key.expr)
+		}
+		expr = expr[end+1:]
+	}
+
+	if serialized, err := hex.DecodeString(expr); err == nil {
+		_, err := btcec.ParsePubKey(serialized, btcec.S256())
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key %q: %v", expr,
+				err)
+		}
+		key.pubKey = serialized
+		return key, nil
+	}
+
+	if wif, err := vtcutil.DecodeWIF(expr); err == nil {
+		if !wif.IsForNet(params) {
+			return nil, fmt.Errorf("private key %q is for the "+
+				"wrong network", expr)
+		}
+		key.pubKey = wif.SerializePubKey()
+		return key, nil
+	}
+
+	path := strings.Split(expr, "/")
+	extKey, err := hdkeychain.NewKeyFromString(path[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid key %q: %v", expr, err)
+	}
+	if !extKey.IsForNet(params) {
+		return nil, fmt.Errorf("extended key %q is for the wrong "+
+			"network", path[0])
+	}
+	for i, element := range path[1:] {
+		if i == len(path)-2 {
+			switch element {
+			case "*":
+				key.extKey = extKey
+				return key, nil
+
+			case "*'", "*h":
+				key.extKey = extKey
+				key.hardened = true
+				return key, nil
+			}
+		}
+
+		hardened := strings.HasSuffix(element, "'") ||
+			strings.HasSuffix(element, "h")
+		if hardened {
+			element = element[:len(element)-1]
+		}
+		index, err := strconv.ParseUint(element, 10, 31)
+		if err != nil {
+			return nil, fmt.Errorf("invalid derivation path element "+
+				"%q in %q", path[i+1], expr)
+		}
+		if hardened {
+			index += hdkeychain.HardenedKeyStart
+		}
+		extKey, err = extKey.Child(uint32(index))
+		if err != nil {
+			return nil, fmt.Errorf("unable to derive %q: %v", expr,
+				err)
+		}
+	}
+
+	pubKey, err := extKey.ECPubKey()
+	if err != nil {
+		return nil, err
+	}
+	key.pubKey = pubKey.SerializeCompressed()
+	return key, nil
+}
+
+// ranged returns whether the key expression ends with a wildcard.
+func (k *descriptorKey) ranged() bool {
+	return k.extKey != nil
+}
+
+// derive returns the serialized public key of the key expression along with
+// the key expression with its wildcard replaced by the passed child index.
+func (k *descriptorKey) derive(index uint32) ([]byte, string, error) {
+	if !k.ranged() {
+		return k.pubKey, k.expr, nil
+	}
+
+	// The wildcard is the last path element, so it is replaced by removing
+	// its last character, or its last two when it is hardened.
+	expr := k.expr[:len(k.expr)-1]
+	childIndex := index
+	if k.hardened {
+		expr = expr[:len(expr)-1]
+		childIndex += hdkeychain.HardenedKeyStart
+	}
+	expr += strconv.FormatUint(uint64(index), 10) +
+		k.expr[len(expr)+1:]
+
+	child, err := k.extKey.Child(childIndex)
+	if err != nil {
+		return nil, "", err
+	}
+	pubKey, err := child.ECPubKey()
+	if err != nil {
+		return nil, "", err
+	}
+	return pubKey.SerializeCompressed(), expr, nil
+}
+
+// scanDescriptor is an output descriptor of the scripts searched by the
+// scantxoutset command.  It is either a descriptor without keys, for which
+// script is set, or a descriptor paying to a key expression which is
+// surrounded by prefix and suffix in the descriptor.
+type scanDescriptor struct {
+	script []byte
+	desc   string
+
+	key      *descriptorKey
+	prefix   string
+	suffix   string
+	scriptFn func(pubKey []byte) ([]byte, error)
+}
+
+// unwrapDescriptor returns the argument of the passed descriptor if it is a
+// call to the passed function.
+func unwrapDescriptor(desc, fn string) (string, bool) {
+	if !strings.HasPrefix(desc, fn+"(") || !strings.HasSuffix(desc, ")") {
+		return "", false
+	}
+	return desc[len(fn)+1 : len(desc)-1], true
+}
+
+// payToPubKeyHashScript returns the script paying to the hash of the passed
+// serialized public key.
+func payToPubKeyHashScript(pubKey []byte, params *chaincfg.Params) ([]byte, error) {
+	addr, err := vtcutil.NewAddressPubKeyHash(vtcutil.Hash160(pubKey),
+		params)
+	if err != nil {
+		return nil, err
+	}
+	return txscript.PayToAddrScript(addr)
+}
+
+// payToWitnessPubKeyHashScript returns the version 0 witness program paying to
+// the hash of the passed serialized public key, which must be compressed.  The
+// script is built directly since not all networks define a bech32 prefix.
+func payToWitnessPubKeyHashScript(pubKey []byte) ([]byte, error) {
+	if len(pubKey) != btcec.PubKeyBytesLenCompressed {
+		return nil, errors.New("witness outputs require compressed " +
+			"public keys")
+	}
+	return txscript.NewScriptBuilder().AddOp(txscript.OP_0).
+		AddData(vtcutil.Hash160(pubKey)).Script()
+}
+
+// parseScanDescriptor parses the passed output descriptor, which may end with
+// its checksum.  The supported descriptors are addr(ADDR), raw(HEX), pkh(KEY),
+// wpkh(KEY) and sh(wpkh(KEY)).
+func parseScanDescriptor(desc string, params *chaincfg.Params) (*scanDescriptor, error) {
+	if i := strings.LastIndex(desc, "#"); i >= 0 {
+		checksum, ok := descriptorChecksum(desc[:i])
+		if !ok || checksum != desc[i+1:] {
+			return nil, fmt.Errorf("invalid checksum for "+
+				"descriptor %q", desc)
+		}
+		desc = desc[:i]
+	} else if _, ok := descriptorChecksum(desc); !ok {
+		return nil, fmt.Errorf("invalid characters in descriptor %q",
+			desc)
+	}
+
+	if arg, ok := unwrapDescriptor(desc, "addr"); ok {
+		addr, err := vtcutil.DecodeAddress(arg, params)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %q: %v", arg,
+				err)
+		}
+		if !addr.IsForNet(params) {
+			return nil, fmt.Errorf("address %q is for the wrong "+
+				"network", arg)
+		}
+		script, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			return nil, err
+		}
+		return &scanDescriptor{script: script, desc: desc}, nil
+	}
+
+	if arg, ok := unwrapDescriptor(desc, "raw"); ok {
+		script, err := hex.DecodeString(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid script %q: %v", arg, err)
+		}
+		return &scanDescriptor{script: script, desc: desc}, nil
+	}
+
+	d := &scanDescriptor{}
+	keyExpr, ok := unwrapDescriptor(desc, "pkh")
+	if ok {
+		d.prefix, d.suffix = "pkh(", ")"
+		d.scriptFn = func(pubKey []byte) ([]byte, error) {
+			return payToPubKeyHashScript(pubKey, params)
+		}
+	} else if keyExpr, ok = unwrapDescriptor(desc, "wpkh"); ok {
+		d.prefix, d.suffix = "wpkh(", ")"
+		d.scriptFn = payToWitnessPubKeyHashScript
+	} else if arg, ok := unwrapDescriptor(desc, "sh"); ok {
+		keyExpr, ok = unwrapDescriptor(arg, "wpkh")
+		if !ok {
+			return nil, fmt.Errorf("unsupported descriptor %q, only "+
+				"sh(wpkh()) is supported", desc)
+		}
+		d.prefix, d.suffix = "sh(wpkh(", "))"
+		d.scriptFn = func(pubKey []byte) ([]byte, error) {
+			script, err := payToWitnessPubKeyHashScript(pubKey)
+			if err != nil {
+				return nil, err
+			}
+			addr, err := vtcutil.NewAddressScriptHash(script, params)
+			if err != nil {
+				return nil, err
+			}
+			return txscript.PayToAddrScript(addr)
+		}
+	} else {
+		return nil, fmt.Errorf("unsupported descriptor %q", desc)
+	}
+
+	key, err := parseDescriptorKey(keyExpr, params)
+	if err != nil {
+		return nil, err
+	}
+	d.key = key
+
+	// Ensure the key is usable with the script now rather than when the
+	// descriptor is expanded.
+	if !key.ranged() {
+		if _, err := d.scriptFn(key.pubKey); err != nil {
+			return nil, fmt.Errorf("invalid descriptor %q: %v", desc,
+				err)
+		}
+	}
+	return d, nil
+}
+
+// ranged returns whether the descriptor derives keys from a range of child
+// indices.
+func (d *scanDescriptor) ranged() bool {
+	return d.key != nil && d.key.ranged()
+}
+
+// expand returns the script of the descriptor for the passed child index along
+// with the descriptor the script is for, which includes its checksum.  The
+// child index is ignored when the descriptor isn't ranged.
+func (d *scanDescriptor) expand(index uint32) ([]byte, string, error) {
+	script, desc := d.script, d.desc
+	if d.key != nil {
+		pubKey, keyExpr, err := d.key.derive(index)
+		if err != nil {
+			return nil, "", err
+		}
+		script, err = d.scriptFn(pubKey)
+		if err != nil {
+			return nil, "", err
+		}
+		desc = d.prefix + keyExpr + d.suffix
+	}
+
+	checksum, _ := descriptorChecksum(desc)
+	return script, desc + "#" + checksum, nil
+}
+
+// scanNeedles expands the passed scan objects to the scripts searched in the
+// utxo set, which are mapped to the descriptors they are for.
+func scanNeedles(scanObjects []btcjson.ScanObject, params *chaincfg.Params) (map[string]string, error) {
+	// Parse all descriptors and limit the total number of keys derived
+	// before deriving any since the derivation is expensive.
+	descs := make([]*scanDescriptor, len(scanObjects))
+	ranges := make([][2]uint32, len(scanObjects))
+	var rangeSize uint64
+	for i, scanObject := range scanObjects {
+		d, err := parseScanDescriptor(scanObject.Desc, params)
+		if err != nil {
+			return nil, err
+		}
+
+		var begin, end uint32
+		switch {
+		case !d.ranged() && scanObject.Range != nil:
+			return nil, fmt.Errorf("range specified for descriptor "+
+				"%q which isn't ranged", scanObject.Desc)
+
+		case d.ranged() && scanObject.Range == nil:
+			end = defaultScanRangeEnd
+
+		case d.ranged():
+			begin, end = scanObject.Range[0], scanObject.Range[1]
+			if begin > end {
+				return nil, fmt.Errorf("range of descriptor %q "+
+					"ends before it begins", scanObject.Desc)
+			}
+			if end >= hdkeychain.HardenedKeyStart {
+				return nil, fmt.Errorf("range of descriptor %q "+
+					"ends too high", scanObject.Desc)
+			}
+		}
+		if d.ranged() {
+			rangeSize += uint64(end-begin) + 1
+			if rangeSize > maxScanRangeSize {
+				return nil, fmt.Errorf("ranges of descriptors "+
+					"are too large - at most %d child "+
+					"indices are derived", maxScanRangeSize)
+			}
+		}
+		descs[i] = d
+		ranges[i] = [2]uint32{begin, end}
+	}
+
+	needles := make(map[string]string)
+	for i, scanObject := range scanObjects {
+		d, begin, end := descs[i], ranges[i][0], ranges[i][1]
+		for index := begin; index <= end; index++ {
+			script, desc, err := d.expand(index)
+			if err != nil {
+				return nil, fmt.Errorf("unable to expand "+
+					"descriptor %q: %v", scanObject.Desc, err)
+			}
+			needles[string(script)] = desc
+		}
+	}
+	return needles, nil
+}
+
+// utxoScanner runs the scans of the utxo set started by the scantxoutset
+// command in the background.  At most one scan runs at a time.
+type utxoScanner struct {
+	// position holds the two first bytes of the hash of the transaction
+	// being scanned.  Since the utxo set is iterated in the order of the
+	// transaction hashes, which are uniformly distributed, it estimates
+	// the progress of the scan.  It must be accessed atomically.
+	position uint32
+
+	mtx   sync.Mutex
+	abort chan struct{}
+}
+
+// newUtxoScanner returns a new utxo scanner without any scan running.
+func newUtxoScanner() *utxoScanner {
+	return &utxoScanner{}
+}
+
+// Start starts scanning the utxo set of the passed chain for the passed scripts
+// in the background and returns a channel on which the result is sent once the
+// scan finishes or is aborted, along with a function which aborts the scan.
+// Unlike Abort, the function only ever aborts the scan started by this call.
+// It returns false when a scan is already running.
+//
+// This function is safe for concurrent access.
+func (s *utxoScanner) Start(chain *blockchain.BlockChain, needles map[string]string) (<-chan *btcjson.ScanTxOutSetResult, func(), bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.abort != nil {
+		return nil, nil, false
+	}
+	abort := make(chan struct{})
+	s.abort = abort
+	atomic.StoreUint32(&s.position, 0)
+
+	resultChan := make(chan *btcjson.ScanTxOutSetResult, 1)
+	go func() {
+		result := s.scan(chain, needles, abort)
+
+		// Mark the scan finished before sending its result so a new
+		// scan can be started as soon as the result is received.
+		s.mtx.Lock()
+		s.abort = nil
+		s.mtx.Unlock()
+		resultChan <- result
+	}()
+	abortScan := func() {
+		s.mtx.Lock()
+		closeAbort(abort)
+		s.mtx.Unlock()
+	}
+	return resultChan, abortScan, true
+}
+
+// Abort aborts the scan in progress, if any, and returns whether there was one.
+//
+// This function is safe for concurrent access.
+func (s *utxoScanner) Abort() bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.abort == nil {
+		return false
+	}
+	closeAbort(s.abort)
+	return true
+}
+
+// closeAbort closes the passed abort channel of a scan unless it is already
+// closed.  It must be called with the scanner mutex held.
+func closeAbort(abort chan struct{}) {
+	select {
+	case <-abort:
+	default:
+		close(abort)
+	}
+}
+
+// Progress returns the estimated percentage of the utxo set scanned by the
+// scan in progress.  It returns false when there is no scan in progress.
+//
+// This function is safe for concurrent access.
+func (s *utxoScanner) Progress() (float64, bool) {
+	s.mtx.Lock()
+	running := s.abort != nil
+	s.mtx.Unlock()
+	if !running {
+		return 0, false
+	}
+
+	position := atomic.LoadUint32(&s.position)
+	return float64(position) * 100 / (1 << 16), true
+}
+
+// scan scans the utxo set of the passed chain for the passed scripts until it
+// is done or the passed abort channel is closed.  The unspent outputs found
+// before the scan is aborted are returned along with an unsuccessful result.
+func (s *utxoScanner) scan(chain *blockchain.BlockChain, needles map[string]string, abort <-chan struct{}) *btcjson.ScanTxOutSetResult {
+	result := &btcjson.ScanTxOutSetResult{
+		Unspents: make([]btcjson.ScanTxOutSetUnspent, 0),
+	}
+	var totalAmount int64
+	hash, height, err := chain.ForEachUtxo(func(outpoint wire.OutPoint, entry *blockchain.UtxoEntry) error {
+		select {
+		case <-abort:
+			return errScanAborted
+		default:
+		}
+
+		atomic.StoreUint32(&s.position,
+			uint32(outpoint.Hash[0])<<8|uint32(outpoint.Hash[1]))
+		result.TxOuts++
+		pkScript := entry.PkScriptByIndex(outpoint.Index)
+		desc, ok := needles[string(pkScript)]
+		if !ok {
+			return nil
+		}
+
+		amount := entry.AmountByIndex(outpoint.Index)
+		totalAmount += amount
+		result.Unspents = append(result.Unspents,
+			btcjson.ScanTxOutSetUnspent{
+				TxID:         outpoint.Hash.String(),
+				Vout:         outpoint.Index,
+				ScriptPubKey: hex.EncodeToString(pkScript),
+				Desc:         desc,
+				Amount:       vtcutil.Amount(amount).ToBTC(),
+				Height:       entry.BlockHeight(),
+			})
+		return nil
+	})
+	result.TotalAmount = vtcutil.Amount(totalAmount).ToBTC()
+	if err != nil {
+		if err != errScanAborted {
+			rpcsLog.Errorf("Unable to scan the utxo set: %v", err)
+		}
+		return result
+	}
+
+	result.Success = true
+	result.Height = height
+	result.BestBlock = hash.String()
+	return result
+}
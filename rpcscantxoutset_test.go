@@ -0,0 +1,308 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/vertcoin/vtcd/btcec"
+	"github.com/vertcoin/vtcd/btcjson"
+	"github.com/vertcoin/vtcd/chaincfg"
+	"github.com/vertcoin/vtcd/txscript"
+	"github.com/vertcoin/vtcutil"
+	"github.com/vertcoin/vtcutil/hdkeychain"
+)
+
+// TestDescriptorChecksum ensures the checksums of output descriptors match
+// the ones computed by Bitcoin Core.
+func TestDescriptorChecksum(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc string
+		want string
+	}{
+		{
+			desc: "raw(deadbeef)",
+			want: "89f8spxm",
+		},
+		{
+			desc: "pkh(02c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5)",
+			want: "8fhd9pwu",
+		},
+	}
+
+	for _, test := range tests {
+		got, ok := descriptorChecksum(test.desc)
+		if !ok || got != test.want {
+			t.Errorf("%s: unexpected checksum - got %q, want %q",
+				test.desc, got, test.want)
+		}
+	}
+
+	if _, ok := descriptorChecksum("raw(deadé)"); ok {
+		t.Error("invalid descriptor character not rejected")
+	}
+}
+
+// TestParseScanDescriptor ensures output descriptors are expanded to the
+// expected scripts and invalid descriptors are rejected.
+func TestParseScanDescriptor(t *testing.T) {
+	t.Parallel()
+
+	params := &chaincfg.VertcoinTestNetParams
+	privKey, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("unable to generate private key: %v", err)
+	}
+	pubKey := privKey.PubKey().SerializeCompressed()
+	pubKeyHex := hex.EncodeToString(pubKey)
+	wif, err := vtcutil.NewWIF(privKey, params, true)
+	if err != nil {
+		t.Fatalf("unable to encode private key: %v", err)
+	}
+
+	pkhAddr, _ := vtcutil.NewAddressPubKeyHash(vtcutil.Hash160(pubKey),
+		params)
+	wpkhScript := append([]byte{txscript.OP_0, txscript.OP_DATA_20},
+		vtcutil.Hash160(pubKey)...)
+	shAddr, _ := vtcutil.NewAddressScriptHash(wpkhScript, params)
+	pkhScript, _ := txscript.PayToAddrScript(pkhAddr)
+	mainAddr, _ := vtcutil.NewAddressPubKeyHash(vtcutil.Hash160(pubKey),
+		&chaincfg.VertcoinParams)
+	shScript, _ := txscript.PayToAddrScript(shAddr)
+
+	tests := []struct {
+		desc   string
+		script []byte
+	}{
+		{desc: "addr(" + pkhAddr.EncodeAddress() + ")", script: pkhScript},
+		{desc: "raw(deadbeef)#89f8spxm", script: []byte{0xde, 0xad, 0xbe, 0xef}},
+		{desc: "pkh(" + pubKeyHex + ")", script: pkhScript},
+		{desc: "pkh(" + wif.String() + ")", script: pkhScript},
+		{desc: "wpkh([d34db33f/84'/0'/0']" + pubKeyHex + ")", script: wpkhScript},
+		{desc: "sh(wpkh(" + pubKeyHex + "))", script: shScript},
+	}
+
+	for _, test := range tests {
+		d, err := parseScanDescriptor(test.desc, params)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.desc, err)
+			continue
+		}
+		if d.ranged() {
+			t.Errorf("%s: unexpected ranged descriptor", test.desc)
+			continue
+		}
+		script, _, err := d.expand(0)
+		if err != nil {
+			t.Errorf("%s: unable to expand: %v", test.desc, err)
+			continue
+		}
+		if !bytes.Equal(script, test.script) {
+			t.Errorf("%s: unexpected script - got %x, want %x",
+				test.desc, script, test.script)
+		}
+	}
+
+	invalid := []string{
+		"raw(deadbeef)#89f8spxq",
+		"raw(xyz)",
+		"pk(" + pubKeyHex + ")",
+		"sh(pkh(" + pubKeyHex + "))",
+		"wpkh(" + hex.EncodeToString(privKey.PubKey().SerializeUncompressed()) + ")",
+		"pkh(02deadbeef)",
+		"addr(notanaddress)",
+		"addr(" + mainAddr.EncodeAddress() + ")",
+	}
+	for _, desc := range invalid {
+		if _, err := parseScanDescriptor(desc, params); err == nil {
+			t.Errorf("%s: invalid descriptor not rejected", desc)
+		}
+	}
+}
+
+// TestScanNeedlesRanged ensures ranged descriptors derive the keys of the
+// child indices in their range and invalid ranges are rejected.
+func TestScanNeedlesRanged(t *testing.T) {
+	t.Parallel()
+
+	params := &chaincfg.VertcoinTestNetParams
+	seed := bytes.Repeat([]byte{0x01}, hdkeychain.RecommendedSeedLen)
+	master, err := hdkeychain.NewMaster(seed, params)
+	if err != nil {
+		t.Fatalf("unable to create master key: %v", err)
+	}
+	xpub, err := master.Neuter()
+	if err != nil {
+		t.Fatalf("unable to neuter master key: %v", err)
+	}
+
+	desc := "wpkh(" + xpub.String() + "/1/*)"
+	needles, err := scanNeedles([]btcjson.ScanObject{
+		{Desc: desc, Range: &[2]uint32{2, 4}},
+	}, params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(needles) != 3 {
+		t.Fatalf("unexpected number of scripts - got %d, want 3",
+			len(needles))
+	}
+
+	branch, _ := xpub.Child(1)
+	for index := uint32(2); index <= 4; index++ {
+		child, _ := branch.Child(index)
+		pubKey, _ := child.ECPubKey()
+		script := append([]byte{txscript.OP_0, txscript.OP_DATA_20},
+			vtcutil.Hash160(pubKey.SerializeCompressed())...)
+		got, ok := needles[string(script)]
+		if !ok {
+			t.Errorf("missing script of child %d", index)
+			continue
+		}
+		childDesc := "wpkh(" + xpub.String() + "/1/" +
+			string('0'+rune(index)) + ")"
+		checksum, _ := descriptorChecksum(childDesc)
+		if want := childDesc + "#" + checksum; got != want {
+			t.Errorf("unexpected descriptor of child %d - got %q, "+
+				"want %q", index, got, want)
+		}
+	}
+
+	// Ranged descriptors without a range use the default one.
+	needles, err = scanNeedles([]btcjson.ScanObject{{Desc: desc}}, params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(needles) != defaultScanRangeEnd+1 {
+		t.Fatalf("unexpected number of scripts - got %d, want %d",
+			len(needles), defaultScanRangeEnd+1)
+	}
+
+	invalid := []btcjson.ScanObject{
+		{Desc: desc, Range: &[2]uint32{5, 4}},
+		{Desc: desc, Range: &[2]uint32{0, hdkeychain.HardenedKeyStart}},
+		{Desc: desc, Range: &[2]uint32{0, maxScanRangeSize}},
+		{Desc: "wpkh(" + xpub.String() + "/1/*')", Range: &[2]uint32{0, 1}},
+		{Desc: "wpkh(" + xpub.String() + "/1')"},
+		{Desc: "wpkh(" + xpub.String() + ")", Range: &[2]uint32{0, 1}},
+	}
+	for _, scanObject := range invalid {
+		if _, err := scanNeedles([]btcjson.ScanObject{scanObject}, params); err == nil {
+			t.Errorf("%s %v: invalid scan object not rejected",
+				scanObject.Desc, scanObject.Range)
+		}
+	}
+
+	// The limit on the number of derived keys applies to all descriptors
+	// of a scan combined.
+	half := &[2]uint32{0, maxScanRangeSize / 2}
+	_, err = scanNeedles([]btcjson.ScanObject{
+		{Desc: desc, Range: half},
+		{Desc: "wpkh(" + xpub.String() + "/0/*)", Range: half},
+	}, params)
+	if err == nil {
+		t.Error("scan objects with too large combined ranges not " +
+			"rejected")
+	}
+}
+
+// TestUtxoScannerIdle ensures an idle utxo scanner reports no scan in
+// progress.
+func TestUtxoScannerIdle(t *testing.T) {
+	t.Parallel()
+
+	s := newUtxoScanner()
+	if _, ok := s.Progress(); ok {
+		t.Error("Progress: unexpected scan in progress")
+	}
+	if s.Abort() {
+		t.Error("Abort: unexpected scan aborted")
+	}
+}
+
+// TestUtxoScannerAbort ensures scans started by a utxo scanner can be aborted,
+// that aborted scans are unsuccessful, and that the scanner is idle once the
+// result of a scan is received.
+func TestUtxoScannerAbort(t *testing.T) {
+	h := newRESTTestHarness(t)
+	defer h.teardown()
+	chain := h.server.cfg.Chain
+
+	// The coinbases of the harness pay to an anyone-can-spend script.
+	pkScript := []byte{txscript.OP_TRUE}
+	needles := map[string]string{string(pkScript): "raw(51)"}
+
+	// A scan aborted before visiting any output finds nothing and is
+	// unsuccessful.
+	s := newUtxoScanner()
+	abort := make(chan struct{})
+	close(abort)
+	result := s.scan(chain, needles, abort)
+	if result.Success || result.TxOuts != 0 || len(result.Unspents) != 0 {
+		t.Fatalf("scan: unexpected result of aborted scan %+v", result)
+	}
+
+	waitResult := func(resultChan <-chan *btcjson.ScanTxOutSetResult) *btcjson.ScanTxOutSetResult {
+		select {
+		case result := <-resultChan:
+			return result
+		case <-time.After(time.Minute):
+			t.Fatal("timeout waiting for scan result")
+		}
+		return nil
+	}
+
+	// Start a scan and abort it.  The scan may finish before it notices it
+	// is aborted, but it must not be reported successful when it was
+	// already finished.
+	resultChan, abortScan, ok := s.Start(chain, needles)
+	if !ok {
+		t.Fatal("Start: unexpected scan in progress")
+	}
+	if _, _, ok := s.Start(chain, needles); ok {
+		t.Fatal("Start: unexpected scan started while one is running")
+	}
+	aborted := s.Abort()
+	result = waitResult(resultChan)
+	if !aborted && !result.Success {
+		t.Fatalf("Start: unexpected result of finished scan %+v",
+			result)
+	}
+	if _, ok := s.Progress(); ok {
+		t.Fatal("Progress: unexpected scan in progress after result")
+	}
+	if s.Abort() {
+		t.Fatal("Abort: unexpected scan aborted after result")
+	}
+
+	// A new scan can be started once the result is received, and finds the
+	// outputs of all coinbases.  Aborting the previous scan, such as when
+	// the client which started it disconnects, doesn't abort the new one.
+	resultChan, _, ok = s.Start(chain, needles)
+	if !ok {
+		t.Fatal("Start: unexpected scan in progress")
+	}
+	abortScan()
+	result = waitResult(resultChan)
+	best := chain.BestSnapshot()
+	if !result.Success || result.Height != best.Height ||
+		result.BestBlock != best.Hash.String() {
+		t.Fatalf("Start: unexpected result %+v", result)
+	}
+	if len(result.Unspents) != len(h.blocks) {
+		t.Fatalf("Start: unexpected number of unspent outputs - got "+
+			"%d, want %d", len(result.Unspents), len(h.blocks))
+	}
+	for _, unspent := range result.Unspents {
+		if unspent.Desc != "raw(51)" || unspent.Vout != 0 {
+			t.Fatalf("Start: unexpected unspent output %+v", unspent)
+		}
+	}
+}
@@ -8,8 +8,9 @@ package main
 import (
 	"bytes"
 	"crypto/sha256"
-	"crypto/subtle"
+	"crypto/tls"
 	"encoding/base64"
+	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
@@ -21,6 +22,7 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
@@ -28,12 +30,14 @@ import (
 	"time"
 
 	"github.com/btcsuite/websocket"
+	"github.com/jrick/logrotate/rotator"
 	"github.com/vertcoin/vtcd/blockchain"
 	"github.com/vertcoin/vtcd/blockchain/indexers"
 	"github.com/vertcoin/vtcd/btcec"
 	"github.com/vertcoin/vtcd/btcjson"
 	"github.com/vertcoin/vtcd/chaincfg"
 	"github.com/vertcoin/vtcd/chaincfg/chainhash"
+	"github.com/vertcoin/vtcd/connmgr"
 	"github.com/vertcoin/vtcd/database"
 	"github.com/vertcoin/vtcd/mempool"
 	"github.com/vertcoin/vtcd/mining"
@@ -62,6 +66,23 @@ const (
 	// 256-bit integer.
 	uint256Size = 32
 
+	// getworkDataLen is the length of the data field of the getwork RPC.
+	// It consists of the serialized block header plus the internal sha256
+	// padding.  The internal sha256 padding consists of a single 1 bit
+	// followed by enough zeros to pad the message out to 56 bytes followed
+	// by length of the message in bits encoded as a big-endian uint64
+	// (8 bytes).  Thus, the resulting length is a multiple of the sha256
+	// block size (64 bytes).
+	getworkDataLen = (1 + ((wire.MaxBlockHeaderPayload + 8) /
+		sha256.BlockSize)) * sha256.BlockSize
+
+	// hash1Len is the length of the hash1 field of the getwork RPC.  It
+	// consists of a zero hash plus the internal sha256 padding.  See
+	// the getworkDataLen comment for details about the internal sha256
+	// padding format.
+	hash1Len = (1 + ((chainhash.HashSize + 8) / sha256.BlockSize)) *
+		sha256.BlockSize
+
 	// gbtNonceRange is two 32-bit big-endian hexadecimal integers which
 	// represent the valid ranges of nonces returned by the getblocktemplate
 	// RPC.
@@ -86,16 +107,6 @@ var (
 		"time", "transactions/add", "prevblock", "coinbase/append",
 	}
 
-	// gbtCoinbaseAux describes additional data that miners should include
-	// in the coinbase signature script.  It is declared here to avoid the
-	// overhead of creating a new object on every invocation for constant
-	// data.
-	gbtCoinbaseAux = &btcjson.GetBlockTemplateResultAux{
-		Flags: hex.EncodeToString(builderScript(txscript.
-			NewScriptBuilder().
-			AddData([]byte(mining.CoinbaseFlags)))),
-	}
-
 	// gbtCapabilities describes additional capabilities returned with a
 	// block template generated by the getblocktemplate RPC.    It is
 	// declared here to avoid the overhead of creating the slice on every
@@ -128,11 +139,14 @@ type commandHandler func(*rpcServer, interface{}, <-chan struct{}) (interface{},
 var rpcHandlers map[string]commandHandler
 var rpcHandlersBeforeInit = map[string]commandHandler{
 	"addnode":               handleAddNode,
+	"clearbanned":           handleClearBanned,
 	"createrawtransaction":  handleCreateRawTransaction,
 	"debuglevel":            handleDebugLevel,
 	"decoderawtransaction":  handleDecodeRawTransaction,
 	"decodescript":          handleDecodeScript,
 	"generate":              handleGenerate,
+	"generateblock":         handleGenerateBlock,
+	"generatetoaddress":     handleGenerateToAddress,
 	"getaddednodeinfo":      handleGetAddedNodeInfo,
 	"getbestblock":          handleGetBestBlock,
 	"getbestblockhash":      handleGetBestBlockHash,
@@ -141,6 +155,7 @@ var rpcHandlersBeforeInit = map[string]commandHandler{
 	"getblockcount":         handleGetBlockCount,
 	"getblockhash":          handleGetBlockHash,
 	"getblockheader":        handleGetBlockHeader,
+	"getblockstats":         handleGetBlockStats,
 	"getblocktemplate":      handleGetBlockTemplate,
 	"getcfilter":            handleGetCFilter,
 	"getcfilterheader":      handleGetCFilterHeader,
@@ -155,18 +170,27 @@ var rpcHandlersBeforeInit = map[string]commandHandler{
 	"getmininginfo":         handleGetMiningInfo,
 	"getnettotals":          handleGetNetTotals,
 	"getnetworkhashps":      handleGetNetworkHashPS,
+	"getpeerbanscore":       handleGetPeerBanScore,
 	"getpeerinfo":           handleGetPeerInfo,
 	"getrawmempool":         handleGetRawMempool,
 	"getrawtransaction":     handleGetRawTransaction,
+	"getrpcinfo":            handleGetRPCInfo,
 	"gettxout":              handleGetTxOut,
+	"getwork":               handleGetWork,
 	"help":                  handleHelp,
+	"listbanned":            handleListBanned,
 	"node":                  handleNode,
 	"ping":                  handlePing,
+	"prioritisetransaction": handlePrioritiseTransaction,
+	"scantxoutset":          handleScanTxOutSet,
 	"searchrawtransactions": handleSearchRawTransactions,
 	"sendrawtransaction":    handleSendRawTransaction,
+	"setban":                handleSetBan,
 	"setgenerate":           handleSetGenerate,
 	"stop":                  handleStop,
 	"submitblock":           handleSubmitBlock,
+	"submitheader":          handleSubmitHeader,
+	"testblockvalidity":     handleTestBlockValidity,
 	"uptime":                handleUptime,
 	"validateaddress":       handleValidateAddress,
 	"verifychain":           handleVerifyChain,
@@ -229,7 +253,6 @@ var rpcUnimplemented = map[string]struct{}{
 	"getchaintips":     {},
 	"getmempoolentry":  {},
 	"getnetworkinfo":   {},
-	"getwork":          {},
 	"invalidateblock":  {},
 	"preciousblock":    {},
 	"reconsiderblock":  {},
@@ -260,6 +283,7 @@ var rpcLimited = map[string]struct{}{
 	"getblockcount":         {},
 	"getblockhash":          {},
 	"getblockheader":        {},
+	"getblockstats":         {},
 	"getcfilter":            {},
 	"getcfilterheader":      {},
 	"getcurrentnet":         {},
@@ -323,6 +347,14 @@ func rpcNoTxInfoError(txHash *chainhash.Hash) *btcjson.RPCError {
 			txHash))
 }
 
+// rpcNotInMainChainError is a convenience function for returning a nicely
+// formatted RPC error which indicates the provided block is not in the main
+// chain.
+func rpcNotInMainChainError(hash *chainhash.Hash) *btcjson.RPCError {
+	return btcjson.NewRPCError(btcjson.ErrRPCBlockNotFound,
+		fmt.Sprintf("Block %v is not in the main chain", hash))
+}
+
 // gbtWorkState houses state that is used in between multiple RPC invocations to
 // getblocktemplate.
 type gbtWorkState struct {
@@ -334,15 +366,113 @@ type gbtWorkState struct {
 	template      *mining.BlockTemplate
 	notifyMap     map[chainhash.Hash]map[int64]chan struct{}
 	timeSource    blockchain.MedianTimeSource
+
+	// deployments houses the state of each rule change deployment for the
+	// block template indexed by deployment ID.
+	deployments []gbtDeployment
+
+	// coinbaseAux describes additional data that miners should include in
+	// the coinbase signature script.  It is created once to avoid the
+	// overhead of creating a new object on every invocation for constant
+	// data.
+	coinbaseAux *btcjson.GetBlockTemplateResultAux
+}
+
+// gbtDeployment describes the state of a BIP0009 rule change deployment for a
+// block template generated by the getblocktemplate RPC.
+type gbtDeployment struct {
+	chaincfg.DeploymentInfo
+	bit   uint8
+	state blockchain.ThresholdState
+}
+
+// gbtDeployments returns the state of all defined rule change deployments for
+// the block after the end of the current best chain indexed by deployment ID.
+func gbtDeployments(chain *blockchain.BlockChain, params *chaincfg.Params) ([]gbtDeployment, error) {
+	deployments := make([]gbtDeployment, 0, len(params.Deployments))
+	for id, deployment := range params.Deployments {
+		state, err := chain.ThresholdState(uint32(id))
+		if err != nil {
+			context := "Failed to obtain deployment status"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		deployments = append(deployments, gbtDeployment{
+			DeploymentInfo: chaincfg.DeploymentInfos[id],
+			bit:            deployment.BitNumber,
+			state:          state,
+		})
+	}
+	return deployments, nil
 }
 
 // newGbtWorkState returns a new instance of a gbtWorkState with all internal
-// fields initialized and ready to use.
-func newGbtWorkState(timeSource blockchain.MedianTimeSource) *gbtWorkState {
+// fields initialized and ready to use.  The passed coinbase flags are included
+// in the coinbase data returned to miners.
+func newGbtWorkState(timeSource blockchain.MedianTimeSource, coinbaseFlags string) *gbtWorkState {
 	return &gbtWorkState{
 		notifyMap:  make(map[chainhash.Hash]map[int64]chan struct{}),
 		timeSource: timeSource,
+		coinbaseAux: &btcjson.GetBlockTemplateResultAux{
+			Flags: hex.EncodeToString(builderScript(txscript.
+				NewScriptBuilder().
+				AddData([]byte(coinbaseFlags)))),
+		},
+	}
+}
+
+// workStateBlockInfo houses information about how to reconstruct a block
+// handed out via getwork given the header submitted by a miner.
+type workStateBlockInfo struct {
+	msgBlock        *wire.MsgBlock
+	signatureScript []byte
+}
+
+// workState houses state that is used in between multiple RPC invocations to
+// getwork.
+//
+// The embedded mutex protects the fields and is never held while calling into
+// the chain, so the state can be discarded synchronously from chain
+// notifications.  The requestLock serializes the getwork invocations instead,
+// which are the only ones modifying the block template.
+type workState struct {
+	sync.Mutex
+	requestLock   sync.Mutex
+	lastTxUpdate  time.Time
+	lastGenerated time.Time
+	prevHash      *chainhash.Hash
+	msgBlock      *wire.MsgBlock
+	extraNonce    uint64
+
+	// blockInfo houses the information needed to reconstruct the blocks
+	// handed out for the current best block keyed by their merkle root,
+	// which identifies the work since each block has a unique extra nonce.
+	blockInfo map[chainhash.Hash]*workStateBlockInfo
+}
+
+// newWorkState returns a new instance of a workState with all internal fields
+// initialized and ready to use.
+func newWorkState() *workState {
+	return &workState{
+		blockInfo: make(map[chainhash.Hash]*workStateBlockInfo),
+	}
+}
+
+// NotifyBlockConnected discards all work handed out via getwork when a block
+// is connected to the main chain since it is stale.  The work is discarded
+// before returning, so no stale work is handed out or accepted afterwards.
+func (state *workState) NotifyBlockConnected(blockHash *chainhash.Hash) {
+	state.Lock()
+	defer state.Unlock()
+
+	if state.msgBlock == nil && len(state.blockInfo) == 0 {
+		return
 	}
+	rpcsLog.Debugf("Discarding %d stale getwork variations after block %v "+
+		"was connected", len(state.blockInfo), blockHash)
+	state.msgBlock = nil
+	state.prevHash = nil
+	state.extraNonce = 0
+	state.blockInfo = make(map[chainhash.Hash]*workStateBlockInfo)
 }
 
 // handleUnimplemented is the handler for commands that should ultimately be
@@ -507,6 +637,15 @@ func messageToHex(msg wire.Message) (string, error) {
 	return hex.EncodeToString(buf.Bytes()), nil
 }
 
+// handleClearBanned implements the clearbanned command.
+func handleClearBanned(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	if err := s.cfg.ConnMgr.ClearBanned(); err != nil {
+		context := "Failed to save ban list"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	return nil, nil
+}
+
 // handleCreateRawTransaction handles createrawtransaction commands.
 func handleCreateRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*btcjson.CreateRawTransactionCmd)
@@ -906,6 +1045,132 @@ func handleGenerate(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (i
 	return reply, nil
 }
 
+// generatePayToAddress decodes the passed address the coinbase of generated
+// blocks pays to and ensures blocks can be generated on the current network.
+func generatePayToAddress(s *rpcServer, encodedAddr string) (vtcutil.Address, error) {
+	params := s.cfg.ChainParams
+	addr, err := vtcutil.DecodeAddress(encodedAddr, params)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidAddressOrKey,
+			Message: "Invalid address or key: " + err.Error(),
+		}
+	}
+	if !addr.IsForNet(params) {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidAddressOrKey,
+			Message: "Invalid address: " + encodedAddr +
+				" is for the wrong network",
+		}
+	}
+
+	// Respond with an error if there's virtually 0 chance of mining a block
+	// with the CPU.
+	if !params.GenerateSupported {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCDifficulty,
+			Message: fmt.Sprintf("No support for `generate` on "+
+				"the current network, %s, as it's unlikely to "+
+				"be possible to main a block with the CPU.",
+				params.Net),
+		}
+	}
+
+	return addr, nil
+}
+
+// handleGenerateBlock handles generateblock commands.
+func handleGenerateBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GenerateBlockCmd)
+	payToAddr, err := generatePayToAddress(s, c.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each transaction is either the id of a transaction in the memory
+	// pool or a raw transaction which doesn't need to be in the memory
+	// pool.
+	txns := make([]*vtcutil.Tx, 0, len(c.Transactions))
+	for _, txStr := range c.Transactions {
+		if len(txStr) == chainhash.MaxHashStringSize {
+			txHash, err := chainhash.NewHashFromStr(txStr)
+			if err != nil {
+				return nil, rpcDecodeHexError(txStr)
+			}
+			tx, err := s.cfg.TxMemPool.FetchTransaction(txHash)
+			if err != nil {
+				return nil, &btcjson.RPCError{
+					Code: btcjson.ErrRPCInvalidAddressOrKey,
+					Message: "Transaction " + txStr + " not " +
+						"in mempool",
+				}
+			}
+			txns = append(txns, tx)
+			continue
+		}
+
+		hexStr := txStr
+		if len(hexStr)%2 != 0 {
+			hexStr = "0" + hexStr
+		}
+		serializedTx, err := hex.DecodeString(hexStr)
+		if err != nil {
+			return nil, rpcDecodeHexError(hexStr)
+		}
+		var msgTx wire.MsgTx
+		err = msgTx.Deserialize(bytes.NewReader(serializedTx))
+		if err != nil {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCDeserialization,
+				Message: "TX decode failed: " + err.Error(),
+			}
+		}
+		txns = append(txns, vtcutil.NewTx(&msgTx))
+	}
+
+	blockHash, err := s.cfg.CPUMiner.GenerateBlock(payToAddr, txns)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCVerify,
+			Message: "Failed to generate block: " + err.Error(),
+		}
+	}
+
+	return &btcjson.GenerateBlockResult{Hash: blockHash.String()}, nil
+}
+
+// handleGenerateToAddress handles generatetoaddress commands.
+func handleGenerateToAddress(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GenerateToAddressCmd)
+	payToAddr, err := generatePayToAddress(s, c.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	// Respond with an error if the client is requesting 0 blocks to be generated.
+	if c.NumBlocks == 0 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInternal.Code,
+			Message: "Please request a nonzero number of blocks to generate.",
+		}
+	}
+
+	blockHashes, err := s.cfg.CPUMiner.GenerateNBlocksToAddress(c.NumBlocks,
+		payToAddr)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInternal.Code,
+			Message: err.Error(),
+		}
+	}
+
+	reply := make([]string, len(blockHashes))
+	for i, hash := range blockHashes {
+		reply[i] = hash.String()
+	}
+	return reply, nil
+}
+
 // handleGetAddedNodeInfo handles getaddednodeinfo commands.
 func handleGetAddedNodeInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*btcjson.GetAddedNodeInfoCmd)
@@ -1057,13 +1322,32 @@ func handleGetBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (i
 		}
 	}
 
-	// When the verbose flag isn't set, simply return the serialized block
-	// as a hex-encoded string.
-	if c.Verbose != nil && !*c.Verbose {
+	// When the verbosity is BlockVerbosityHex, simply return the serialized
+	// block as a hex-encoded string.
+	verbosity := btcjson.BlockVerbosityJSON
+	if c.Verbosity != nil {
+		verbosity = *c.Verbosity
+	}
+	switch verbosity {
+	case btcjson.BlockVerbosityHex:
 		return hex.EncodeToString(blkBytes), nil
+
+	case btcjson.BlockVerbosityTxPrevOut:
+		// The spent outputs are only kept for blocks in the main chain.
+		if !s.cfg.Chain.MainChainHasBlock(hash) {
+			return nil, rpcNotInMainChainError(hash)
+		}
+
+	case btcjson.BlockVerbosityJSON:
+	default:
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidParameter,
+			Message: fmt.Sprintf("Invalid verbosity %d -- must be "+
+				"0, 1 or 2", verbosity),
+		}
 	}
 
-	// The verbose flag is set, so generate the JSON object and return it.
+	// Otherwise, generate the JSON object and return it.
 
 	// Deserialize the block.
 	blk, err := vtcutil.NewBlockFromBytes(blkBytes)
@@ -1112,6 +1396,34 @@ func handleGetBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (i
 		NextHash:      nextHashString,
 	}
 
+	// When the verbosity is BlockVerbosityTxPrevOut, return the decoded
+	// transactions along with their previous outputs and fees in place of
+	// their hashes.
+	if verbosity == btcjson.BlockVerbosityTxPrevOut {
+		txns, err := createTxRawPrevOutResults(s, blk)
+		if err != nil {
+			return nil, err
+		}
+		return btcjson.GetBlockVerboseTxResult{
+			Hash:          blockReply.Hash,
+			Confirmations: blockReply.Confirmations,
+			StrippedSize:  blockReply.StrippedSize,
+			Size:          blockReply.Size,
+			Weight:        blockReply.Weight,
+			Height:        blockReply.Height,
+			Version:       blockReply.Version,
+			VersionHex:    blockReply.VersionHex,
+			MerkleRoot:    blockReply.MerkleRoot,
+			Tx:            txns,
+			Time:          blockReply.Time,
+			Nonce:         blockReply.Nonce,
+			Bits:          blockReply.Bits,
+			Difficulty:    blockReply.Difficulty,
+			PreviousHash:  blockReply.PreviousHash,
+			NextHash:      blockReply.NextHash,
+		}, nil
+	}
+
 	if c.VerboseTx == nil || !*c.VerboseTx {
 		transactions := blk.Transactions()
 		txNames := make([]string, len(transactions))
@@ -1138,6 +1450,107 @@ func handleGetBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (i
 	return blockReply, nil
 }
 
+// createTxRawPrevOutResults converts the transactions of the passed block,
+// which must be in the main chain, to JSON objects which include the previous
+// outputs spent by their inputs, as recorded in the spend journal, and their
+// fees.
+func createTxRawPrevOutResults(s *rpcServer, blk *vtcutil.Block) ([]btcjson.TxRawPrevOutResult, error) {
+	spent, err := s.cfg.Chain.FetchSpendJournal(blk)
+	if err != nil {
+		// The block might have been disconnected from the main chain,
+		// which removes its spent outputs, since it was looked up.
+		if !s.cfg.Chain.MainChainHasBlock(blk.Hash()) {
+			return nil, rpcNotInMainChainError(blk.Hash())
+		}
+		context := "Failed to fetch spent outputs"
+		return nil, internalRPCError(err.Error(), context)
+	}
+
+	params := s.cfg.ChainParams
+	txns := blk.Transactions()
+	results := make([]btcjson.TxRawPrevOutResult, len(txns))
+	for i, tx := range txns {
+		mtx := tx.MsgTx()
+		mtxHex, err := messageToHex(mtx)
+		if err != nil {
+			return nil, err
+		}
+		result := &results[i]
+		*result = btcjson.TxRawPrevOutResult{
+			Hex:      mtxHex,
+			Txid:     tx.Hash().String(),
+			Hash:     mtx.WitnessHash().String(),
+			Size:     int32(mtx.SerializeSize()),
+			Vsize:    int32(mempool.GetTxVirtualSize(tx)),
+			Version:  mtx.Version,
+			LockTime: mtx.LockTime,
+			Vin:      make([]btcjson.VinPrevOut, len(mtx.TxIn)),
+			Vout:     createVoutList(mtx, params, nil),
+		}
+
+		// The coinbase transaction doesn't spend any outputs.
+		if i == 0 {
+			txIn := mtx.TxIn[0]
+			result.Vin[0].Coinbase = hex.EncodeToString(
+				txIn.SignatureScript)
+			result.Vin[0].Sequence = txIn.Sequence
+			continue
+		}
+
+		var totalIn, totalOut int64
+		for j, txIn := range mtx.TxIn {
+			if len(spent) == 0 {
+				context := "Failed to fetch spent outputs"
+				return nil, internalRPCError("missing spent "+
+					"outputs", context)
+			}
+			stxo := spent[0]
+			spent = spent[1:]
+			totalIn += stxo.Amount
+
+			// The disassembled string will contain [error] inline
+			// if the script doesn't fully parse, so ignore the
+			// error here.
+			disbuf, _ := txscript.DisasmString(txIn.SignatureScript)
+
+			// Ignore the error here since an error means the script
+			// couldn't parse and there is no additional information
+			// about it anyways.
+			_, addrs, _, _ := txscript.ExtractPkScriptAddrs(
+				stxo.PkScript, params)
+			encodedAddrs := make([]string, len(addrs))
+			for k, addr := range addrs {
+				encodedAddrs[k] = addr.EncodeAddress()
+			}
+
+			prevOut := &txIn.PreviousOutPoint
+			result.Vin[j] = btcjson.VinPrevOut{
+				Txid:     prevOut.Hash.String(),
+				Vout:     prevOut.Index,
+				Sequence: txIn.Sequence,
+				ScriptSig: &btcjson.ScriptSig{
+					Asm: disbuf,
+					Hex: hex.EncodeToString(txIn.SignatureScript),
+				},
+				PrevOut: &btcjson.PrevOut{
+					Addresses: encodedAddrs,
+					Value:     vtcutil.Amount(stxo.Amount).ToBTC(),
+				},
+			}
+			if len(txIn.Witness) != 0 {
+				result.Vin[j].Witness = witnessToSring(txIn.Witness)
+			}
+		}
+		for _, txOut := range mtx.TxOut {
+			totalOut += txOut.Value
+		}
+		fee := vtcutil.Amount(totalIn - totalOut).ToBTC()
+		result.Fee = &fee
+	}
+
+	return results, nil
+}
+
 // softForkStatus converts a ThresholdState state into a human readable string
 // corresponding to the particular state.
 func softForkStatus(state blockchain.ThresholdState) (string, error) {
@@ -1215,24 +1628,7 @@ func handleGetBlockChainInfo(s *rpcServer, cmd interface{}, closeChan <-chan str
 	for deployment, deploymentDetails := range params.Deployments {
 		// Map the integer deployment ID into a human readable
 		// fork-name.
-		var forkName string
-		switch deployment {
-		case chaincfg.DeploymentTestDummy:
-			forkName = "dummy"
-
-		case chaincfg.DeploymentCSV:
-			forkName = "csv"
-
-		case chaincfg.DeploymentSegwit:
-			forkName = "segwit"
-
-		default:
-			return nil, &btcjson.RPCError{
-				Code: btcjson.ErrRPCInternal.Code,
-				Message: fmt.Sprintf("Unknown deployment %v "+
-					"detected", deployment),
-			}
-		}
+		forkName := chaincfg.DeploymentInfos[deployment].Name
 
 		// Query the chain for the current status of the deployment as
 		// identified by its deployment ID.
@@ -1287,6 +1683,84 @@ func handleGetBlockHash(s *rpcServer, cmd interface{}, closeChan <-chan struct{}
 	return hash.String(), nil
 }
 
+// handleGetBlockStats implements the getblockstats command.
+func handleGetBlockStats(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetBlockStatsCmd)
+
+	// Resolve the block, which is identified by either its hash or its
+	// height in the main chain.
+	var hash *chainhash.Hash
+	if height, ok := c.HashOrHeight.Height(); ok {
+		var err error
+		hash, err = s.cfg.Chain.BlockHashByHeight(height)
+		if err != nil {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCOutOfRange,
+				Message: "Block number out of range",
+			}
+		}
+	} else {
+		var err error
+		hash, err = chainhash.NewHashFromStr(string(c.HashOrHeight))
+		if err != nil {
+			return nil, rpcDecodeHexError(string(c.HashOrHeight))
+		}
+	}
+
+	blk, err := s.cfg.Chain.BlockByHash(hash)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCBlockNotFound,
+			Message: "Block not found",
+		}
+	}
+
+	// The spent outputs are only kept for blocks in the main chain.
+	if !s.cfg.Chain.MainChainHasBlock(hash) {
+		return nil, rpcNotInMainChainError(hash)
+	}
+	spent, err := s.cfg.Chain.FetchSpendJournal(blk)
+	if err != nil {
+		// The block might have been disconnected from the main chain,
+		// which removes its spent outputs, since it was looked up.
+		if !s.cfg.Chain.MainChainHasBlock(hash) {
+			return nil, rpcNotInMainChainError(hash)
+		}
+		context := "Failed to fetch spent outputs"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	stats, err := calcBlockStats(blk, spent)
+	if err != nil {
+		context := "Failed to compute block statistics"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	medianTime, err := s.cfg.Chain.BlockPastMedianTime(hash)
+	if err != nil {
+		context := "Failed to obtain median time"
+		return nil, internalRPCError(err.Error(), context)
+	}
+
+	header := &blk.MsgBlock().Header
+	stats.BlockHash = hash.String()
+	stats.Height = int64(blk.Height())
+	stats.Time = header.Timestamp.Unix()
+	stats.MedianTime = medianTime.Unix()
+	stats.Subsidy = blockchain.CalcBlockSubsidy(blk.Height(),
+		s.cfg.ChainParams)
+
+	if c.Stats == nil || len(*c.Stats) == 0 {
+		return stats, nil
+	}
+	filtered, err := filterBlockStats(stats, *c.Stats)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: err.Error(),
+		}
+	}
+	return filtered, nil
+}
+
 // handleGetBlockHeader implements the getblockheader command.
 func handleGetBlockHeader(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*btcjson.GetBlockHeaderCmd)
@@ -1546,7 +2020,7 @@ func (state *gbtWorkState) updateBlockTemplate(s *rpcServer, useCoinbaseValue bo
 		// block template doesn't include the coinbase, so the caller
 		// will ultimately create their own coinbase which pays to the
 		// appropriate address(es).
-		blkTemplate, err := generator.NewBlockTemplate(payAddr)
+		blkTemplate, err := s.cfg.TemplateCache.NewBlockTemplate(payAddr)
 		if err != nil {
 			return internalRPCError("Failed to create new block "+
 				"template: "+err.Error(), "")
@@ -1562,6 +2036,14 @@ func (state *gbtWorkState) updateBlockTemplate(s *rpcServer, useCoinbaseValue bo
 		best := s.cfg.Chain.BestSnapshot()
 		minTimestamp := mining.MinimumMedianTime(best)
 
+		// Get the state of the rule change deployments the template
+		// signals for or is subject to.
+		deployments, err := gbtDeployments(s.cfg.Chain,
+			s.cfg.ChainParams)
+		if err != nil {
+			return err
+		}
+
 		// Update work state to ensure another block template isn't
 		// generated until needed.
 		state.template = template
@@ -1569,6 +2051,7 @@ func (state *gbtWorkState) updateBlockTemplate(s *rpcServer, useCoinbaseValue bo
 		state.lastTxUpdate = lastTxUpdate
 		state.prevHash = latestHash
 		state.minTimestamp = minTimestamp
+		state.deployments = deployments
 
 		rpcsLog.Debugf("Generated block template (timestamp %v, "+
 			"target %s, merkle root %s)",
@@ -1632,10 +2115,13 @@ func (state *gbtWorkState) updateBlockTemplate(s *rpcServer, useCoinbaseValue bo
 
 // blockTemplateResult returns the current block template associated with the
 // state as a btcjson.GetBlockTemplateResult that is ready to be encoded to JSON
-// and returned to the caller.
+// and returned to the caller.  The version bits and limits of the result are
+// adjusted to the rules supported by the caller per BIP0009 and an error is
+// returned when the caller doesn't support an active rule which requires
+// explicit support.
 //
 // This function MUST be called with the state locked.
-func (state *gbtWorkState) blockTemplateResult(useCoinbaseValue bool, submitOld *bool) (*btcjson.GetBlockTemplateResult, error) {
+func (state *gbtWorkState) blockTemplateResult(useCoinbaseValue bool, request *btcjson.TemplateRequest, submitOld *bool) (*btcjson.GetBlockTemplateResult, error) {
 	// Ensure the timestamps are still in valid range for the template.
 	// This should really only ever happen if the local clock is changed
 	// after the template is generated, but it's important to avoid serving
@@ -1655,6 +2141,80 @@ func (state *gbtWorkState) blockTemplateResult(useCoinbaseValue bool, submitOld
 		}
 	}
 
+	// Determine the rules supported by the caller.  Callers which don't
+	// specify any rules are treated as not supporting any.
+	clientRules := make(map[string]struct{})
+	if request != nil {
+		for _, rule := range request.Rules {
+			clientRules[rule] = struct{}{}
+		}
+	}
+
+	// Apply the BIP0009 version bits semantics for each rule change
+	// deployment.  Callers which don't support a deployment that requires
+	// explicit support may not signal for it while it is being voted on,
+	// and can't create valid blocks once it is active.
+	version := header.Version
+	rules := make([]string, 0, len(state.deployments))
+	vbAvailable := make(map[string]int32)
+	segwitActive := false
+	for id, deployment := range state.deployments {
+		_, supported := clientRules[deployment.Name]
+		bitMask := int32(1) << deployment.bit
+		switch deployment.state {
+		case blockchain.ThresholdLockedIn:
+			// Blocks must signal for locked in deployments.
+			version |= bitMask
+			vbAvailable[deployment.Name] = int32(deployment.bit)
+
+		case blockchain.ThresholdStarted:
+			if !supported && !deployment.GBTForce {
+				version &^= bitMask
+			}
+			vbAvailable[deployment.Name] = int32(deployment.bit)
+
+		case blockchain.ThresholdActive:
+			rule := deployment.Name
+			if !deployment.GBTForce {
+				if !supported {
+					return nil, &btcjson.RPCError{
+						Code: btcjson.ErrRPCInvalidParameter,
+						Message: fmt.Sprintf("Support for "+
+							"'%s' rule requires "+
+							"explicit client "+
+							"support", rule),
+					}
+				}
+				rule = "!" + rule
+			}
+			rules = append(rules, rule)
+			if id == chaincfg.DeploymentSegwit {
+				segwitActive = true
+			}
+		}
+	}
+
+	// Before segwit is active, limits are expressed without the witness
+	// scale factor and transactions have no weight.
+	sigOpScale := int64(1)
+	sizeLimit := int64(wire.MaxBlockPayload)
+	weightLimit := int64(blockchain.MaxBlockWeight)
+	if !segwitActive {
+		sigOpScale = blockchain.WitnessScaleFactor
+		sizeLimit = blockchain.MaxBlockBaseSize
+		weightLimit = 0
+	}
+
+	// Clients which predate BIP0009 may force the version of the block
+	// as long as it is at least version 2, which is the first version
+	// which requires the block height in the coinbase.
+	mutable := gbtMutableFields
+	if request != nil && request.Rules == nil && request.MaxVersion >= 2 {
+		mutable = make([]string, 0, len(gbtMutableFields)+1)
+		mutable = append(mutable, gbtMutableFields...)
+		mutable = append(mutable, "version/force")
+	}
+
 	// Convert each transaction in the block template to a template result
 	// transaction.  The result does not include the coinbase, so notice
 	// the adjustments to the various lengths and indices.
@@ -1700,7 +2260,7 @@ func (state *gbtWorkState) blockTemplateResult(useCoinbaseValue bool, submitOld
 			Hash:    txHash.String(),
 			Depends: depends,
 			Fee:     template.Fees[i],
-			SigOps:  template.SigOpCosts[i],
+			SigOps:  template.SigOpCosts[i] / sigOpScale,
 			Weight:  blockchain.GetTransactionWeight(bTx),
 		}
 		transactions = append(transactions, resultTx)
@@ -1717,17 +2277,19 @@ func (state *gbtWorkState) blockTemplateResult(useCoinbaseValue bool, submitOld
 		CurTime:      header.Timestamp.Unix(),
 		Height:       int64(template.Height),
 		PreviousHash: header.PrevBlock.String(),
-		WeightLimit:  blockchain.MaxBlockWeight,
-		SigOpLimit:   blockchain.MaxBlockSigOpsCost,
-		SizeLimit:    wire.MaxBlockPayload,
+		WeightLimit:  weightLimit,
+		SigOpLimit:   blockchain.MaxBlockSigOpsCost / sigOpScale,
+		SizeLimit:    sizeLimit,
 		Transactions: transactions,
-		Version:      header.Version,
+		Version:      version,
+		Rules:        rules,
+		VbAvailable:  vbAvailable,
 		LongPollID:   templateID,
 		SubmitOld:    submitOld,
 		Target:       targetDifficulty,
 		MinTime:      state.minTimestamp.Unix(),
 		MaxTime:      maxTime.Unix(),
-		Mutable:      gbtMutableFields,
+		Mutable:      mutable,
 		NonceRange:   gbtNonceRange,
 		Capabilities: gbtCapabilities,
 	}
@@ -1738,7 +2300,7 @@ func (state *gbtWorkState) blockTemplateResult(useCoinbaseValue bool, submitOld
 	}
 
 	if useCoinbaseValue {
-		reply.CoinbaseAux = gbtCoinbaseAux
+		reply.CoinbaseAux = state.coinbaseAux
 		reply.CoinbaseValue = &msgBlock.Transactions[0].TxOut[0].Value
 	} else {
 		// Ensure the template has a valid payment address associated
@@ -1766,7 +2328,7 @@ func (state *gbtWorkState) blockTemplateResult(useCoinbaseValue bool, submitOld
 			Hash:    tx.TxHash().String(),
 			Depends: []int64{},
 			Fee:     template.Fees[0],
-			SigOps:  template.SigOpCosts[0],
+			SigOps:  template.SigOpCosts[0] / sigOpScale,
 		}
 
 		reply.CoinbaseTxn = &resultTx
@@ -1785,7 +2347,7 @@ func (state *gbtWorkState) blockTemplateResult(useCoinbaseValue bool, submitOld
 // has passed without finding a solution.
 //
 // See https://en.bitcoin.it/wiki/BIP_0022 for more details.
-func handleGetBlockTemplateLongPoll(s *rpcServer, longPollID string, useCoinbaseValue bool, closeChan <-chan struct{}) (interface{}, error) {
+func handleGetBlockTemplateLongPoll(s *rpcServer, request *btcjson.TemplateRequest, useCoinbaseValue bool, closeChan <-chan struct{}) (interface{}, error) {
 	state := s.gbtWorkState
 	state.Lock()
 	// The state unlock is intentionally not deferred here since it needs to
@@ -1799,9 +2361,10 @@ func handleGetBlockTemplateLongPoll(s *rpcServer, longPollID string, useCoinbase
 
 	// Just return the current block template if the long poll ID provided by
 	// the caller is invalid.
-	prevHash, lastGenerated, err := decodeTemplateID(longPollID)
+	prevHash, lastGenerated, err := decodeTemplateID(request.LongPollID)
 	if err != nil {
-		result, err := state.blockTemplateResult(useCoinbaseValue, nil)
+		result, err := state.blockTemplateResult(useCoinbaseValue,
+			request, nil)
 		if err != nil {
 			state.Unlock()
 			return nil, err
@@ -1823,7 +2386,7 @@ func handleGetBlockTemplateLongPoll(s *rpcServer, longPollID string, useCoinbase
 		// already been found and added to the block chain.
 		submitOld := prevHash.IsEqual(prevTemplateHash)
 		result, err := state.blockTemplateResult(useCoinbaseValue,
-			&submitOld)
+			request, &submitOld)
 		if err != nil {
 			state.Unlock()
 			return nil, err
@@ -1863,7 +2426,8 @@ func handleGetBlockTemplateLongPoll(s *rpcServer, longPollID string, useCoinbase
 	// block template depending on whether or not a solution has already
 	// been found and added to the block chain.
 	submitOld := prevHash.IsEqual(&state.template.Block.Header.PrevBlock)
-	result, err := state.blockTemplateResult(useCoinbaseValue, &submitOld)
+	result, err := state.blockTemplateResult(useCoinbaseValue, request,
+		&submitOld)
 	if err != nil {
 		return nil, err
 	}
@@ -1901,7 +2465,9 @@ func handleGetBlockTemplateRequest(s *rpcServer, request *btcjson.TemplateReques
 
 	// When a coinbase transaction has been requested, respond with an error
 	// if there are no addresses to pay the created block template to.
-	if !useCoinbaseValue && len(cfg.miningAddrs) == 0 {
+	// Requests which specify their own coinbase don't need any.
+	hasCoinbaseSpec := request != nil && request.Coinbase != nil
+	if !useCoinbaseValue && !hasCoinbaseSpec && len(cfg.miningAddrs) == 0 {
 		return nil, &btcjson.RPCError{
 			Code: btcjson.ErrRPCInternal.Code,
 			Message: "A coinbase transaction has been requested, " +
@@ -1932,11 +2498,18 @@ func handleGetBlockTemplateRequest(s *rpcServer, request *btcjson.TemplateReques
 		}
 	}
 
+	// Templates with a coinbase created from a spec provided by the caller
+	// are specific to the request, so they are generated separately from
+	// the shared block template.
+	if hasCoinbaseSpec {
+		return handleGetBlockTemplateCoinbase(s, request)
+	}
+
 	// When a long poll ID was provided, this is a long poll request by the
 	// client to be notified when block template referenced by the ID should
 	// be replaced with a new one.
 	if request != nil && request.LongPollID != "" {
-		return handleGetBlockTemplateLongPoll(s, request.LongPollID,
+		return handleGetBlockTemplateLongPoll(s, request,
 			useCoinbaseValue, closeChan)
 	}
 
@@ -1954,22 +2527,143 @@ func handleGetBlockTemplateRequest(s *rpcServer, request *btcjson.TemplateReques
 	if err := state.updateBlockTemplate(s, useCoinbaseValue); err != nil {
 		return nil, err
 	}
-	return state.blockTemplateResult(useCoinbaseValue, nil)
+	return state.blockTemplateResult(useCoinbaseValue, request, nil)
 }
 
-// chainErrToGBTErrString converts an error returned from btcchain to a string
-// which matches the reasons and format described in BIP0022 for rejection
-// reasons.
-func chainErrToGBTErrString(err error) string {
-	// When the passed error is not a RuleError, just return a generic
-	// rejected string with the error text.
-	ruleErr, ok := err.(blockchain.RuleError)
-	if !ok {
-		return "rejected: " + err.Error()
+// templateCoinbaseSpec converts the coinbase spec provided with a
+// getblocktemplate request to a mining.CoinbaseSpec.
+func templateCoinbaseSpec(request *btcjson.TemplateRequestCoinbase, params *chaincfg.Params) (*mining.CoinbaseSpec, error) {
+	spec := &mining.CoinbaseSpec{
+		Outputs: make([]mining.CoinbaseOutput, 0, len(request.Outputs)),
+		Flags:   request.Tag,
 	}
+	for _, output := range request.Outputs {
+		if (output.Address == "") == (output.Script == "") {
+			return nil, &btcjson.RPCError{
+				Code: btcjson.ErrRPCInvalidParameter,
+				Message: "Each coinbase output must specify " +
+					"either an address or a script",
+			}
+		}
+		if output.Amount < 0 || output.Amount > vtcutil.MaxSatoshi {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCType,
+				Message: "Invalid amount",
+			}
+		}
 
-	switch ruleErr.ErrorCode {
-	case blockchain.ErrDuplicateBlock:
+		var pkScript []byte
+		if output.Address != "" {
+			addr, err := vtcutil.DecodeAddress(output.Address, params)
+			if err != nil {
+				return nil, &btcjson.RPCError{
+					Code: btcjson.ErrRPCInvalidAddressOrKey,
+					Message: "Invalid address or key: " +
+						err.Error(),
+				}
+			}
+			if !addr.IsForNet(params) {
+				return nil, &btcjson.RPCError{
+					Code: btcjson.ErrRPCInvalidAddressOrKey,
+					Message: "Invalid address: " +
+						output.Address + " is for the " +
+						"wrong network",
+				}
+			}
+			pkScript, err = txscript.PayToAddrScript(addr)
+			if err != nil {
+				return nil, &btcjson.RPCError{
+					Code:    btcjson.ErrRPCInvalidAddressOrKey,
+					Message: "Invalid address or key",
+				}
+			}
+		} else {
+			var err error
+			pkScript, err = hex.DecodeString(output.Script)
+			if err != nil {
+				return nil, rpcDecodeHexError(output.Script)
+			}
+		}
+
+		spec.Outputs = append(spec.Outputs, mining.CoinbaseOutput{
+			PkScript: pkScript,
+			Amount:   output.Amount,
+			Weight:   output.Weight,
+		})
+	}
+	for _, commitment := range request.Commitments {
+		data, err := hex.DecodeString(commitment)
+		if err != nil {
+			return nil, rpcDecodeHexError(commitment)
+		}
+		spec.Commitments = append(spec.Commitments, data)
+	}
+
+	if err := spec.Validate(); err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Invalid coinbase: " + err.Error(),
+		}
+	}
+	return spec, nil
+}
+
+// handleGetBlockTemplateCoinbase is a helper for handleGetBlockTemplateRequest
+// which generates a block template with a coinbase created from the spec
+// provided by the caller.  The template always includes the coinbase
+// transaction.  Since it is specific to the request, it is not shared with
+// other callers and long polling is not supported.
+func handleGetBlockTemplateCoinbase(s *rpcServer, request *btcjson.TemplateRequest) (interface{}, error) {
+	if request.LongPollID != "" {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidParameter,
+			Message: "Long polling is not supported for templates " +
+				"with a coinbase",
+		}
+	}
+
+	spec, err := templateCoinbaseSpec(request.Coinbase, s.cfg.ChainParams)
+	if err != nil {
+		return nil, err
+	}
+	template, err := s.cfg.TemplateCache.NewBlockTemplateWithCoinbase(spec)
+	if err != nil {
+		return nil, internalRPCError("Failed to create new block "+
+			"template: "+err.Error(), "")
+	}
+
+	// Create the result from a work state which is private to the request.
+	state := newGbtWorkState(s.gbtWorkState.timeSource,
+		s.cfg.Generator.CoinbaseFlags())
+	state.template = template
+	state.lastGenerated = time.Now()
+	state.prevHash = &template.Block.Header.PrevBlock
+	state.minTimestamp = mining.MinimumMedianTime(s.cfg.Chain.BestSnapshot())
+	state.deployments, err = gbtDeployments(s.cfg.Chain, s.cfg.ChainParams)
+	if err != nil {
+		return nil, err
+	}
+	result, err := state.blockTemplateResult(false, request, nil)
+	if err != nil {
+		return nil, err
+	}
+	result.LongPollID = ""
+	return result, nil
+}
+
+// chainErrToGBTErrString converts an error returned from btcchain to a string
+// which matches the reasons and format described in BIP0022 for rejection
+// reasons.
+func chainErrToGBTErrString(err error) string {
+	// When the passed error is not a RuleError, just return a generic
+	// rejected string with the error text.
+	ruleErr, ok := err.(blockchain.RuleError)
+	if !ok {
+		return "rejected: " + err.Error()
+	}
+
+	switch ruleErr.ErrorCode {
+	case blockchain.ErrDuplicateBlock:
 		return "duplicate"
 	case blockchain.ErrBlockTooBig:
 		return "bad-block-size"
@@ -2043,6 +2737,8 @@ func chainErrToGBTErrString(err error) string {
 		return "bad-script-malformed"
 	case blockchain.ErrScriptValidation:
 		return "bad-script-validate"
+	case blockchain.ErrPreviousBlockUnknown:
+		return "prev-blk-not-found"
 	}
 
 	return "rejected: " + err.Error()
@@ -2209,7 +2905,37 @@ func handleGetGenerate(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 
 // handleGetHashesPerSec implements the gethashespersec command.
 func handleGetHashesPerSec(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
-	return int64(s.cfg.CPUMiner.HashesPerSecond()), nil
+	c := cmd.(*btcjson.GetHashesPerSecCmd)
+	hashesPerSec := int64(s.cfg.CPUMiner.HashesPerSecond())
+	if c.Verbose == nil || !*c.Verbose {
+		return hashesPerSec, nil
+	}
+
+	workers := workerHashesPerSec(s.cfg.CPUMiner)
+	if workers == nil {
+		workers = []btcjson.WorkerHashesPerSecResult{}
+	}
+	return &btcjson.GetHashesPerSecVerboseResult{
+		HashesPerSec: hashesPerSec,
+		Workers:      workers,
+	}, nil
+}
+
+// workerHashesPerSec returns the hashing performance of each worker of the
+// passed CPU miner.  Nil is returned when the miner is not running.
+func workerHashesPerSec(miner *cpuminer.CPUMiner) []btcjson.WorkerHashesPerSecResult {
+	rates := miner.WorkerHashesPerSecond()
+	if len(rates) == 0 {
+		return nil
+	}
+	workers := make([]btcjson.WorkerHashesPerSecResult, 0, len(rates))
+	for _, rate := range rates {
+		workers = append(workers, btcjson.WorkerHashesPerSecResult{
+			ID:           rate.ID,
+			HashesPerSec: int64(rate.HashesPerSec),
+		})
+	}
+	return workers
 }
 
 // handleGetHeaders implements the getheaders command.
@@ -2321,6 +3047,7 @@ func handleGetMiningInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{
 		NetworkHashPS:      networkHashesPerSec,
 		PooledTx:           uint64(s.cfg.TxMemPool.Count()),
 		TestNet:            cfg.TestNet4,
+		WorkerHashesPerSec: workerHashesPerSec(s.cfg.CPUMiner),
 	}
 	return &result, nil
 }
@@ -2328,10 +3055,26 @@ func handleGetMiningInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{
 // handleGetNetTotals implements the getnettotals command.
 func handleGetNetTotals(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	totalBytesRecv, totalBytesSent := s.cfg.ConnMgr.NetTotals()
+	target := s.cfg.ConnMgr.UploadTarget()
+
+	// The time left in the cycle is only meaningful when a target is set.
+	var timeLeft int64
+	if target.Target != 0 {
+		timeLeft = int64(target.CycleEnd.Sub(time.Now()).Seconds())
+	}
+
 	reply := &btcjson.GetNetTotalsResult{
 		TotalBytesRecv: totalBytesRecv,
 		TotalBytesSent: totalBytesSent,
 		TimeMillis:     time.Now().UTC().UnixNano() / int64(time.Millisecond),
+		UploadTarget: btcjson.GetNetTotalsUploadTargetResult{
+			TimeFrame:             int64(uploadTargetTimeframe.Seconds()),
+			Target:                target.Target,
+			TargetReached:         target.Reached,
+			ServeHistoricalBlocks: target.ServeHistoricalBlocks,
+			BytesLeftInCycle:      target.BytesLeft,
+			TimeLeftInCycle:       timeLeft,
+		},
 	}
 	return reply, nil
 }
@@ -2430,6 +3173,50 @@ func handleGetNetworkHashPS(s *rpcServer, cmd interface{}, closeChan <-chan stru
 	return hashesPerSec.Int64(), nil
 }
 
+// handleGetPeerBanScore implements the getpeerbanscore command.
+func handleGetPeerBanScore(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetPeerBanScoreCmd)
+
+	peers := s.cfg.ConnMgr.ConnectedPeers()
+	results := make([]*btcjson.GetPeerBanScoreResult, 0, len(peers))
+	for _, p := range peers {
+		if c.ID != nil && p.ToPeer().ID() != *c.ID {
+			continue
+		}
+
+		persistent, transient := p.BanScoreComponents()
+		history := p.BanScoreHistory()
+		result := &btcjson.GetPeerBanScoreResult{
+			ID:         p.ToPeer().ID(),
+			Addr:       p.ToPeer().Addr(),
+			BanScore:   persistent + transient,
+			Persistent: persistent,
+			Transient:  transient,
+			History:    make([]btcjson.BanScoreEventResult, 0, len(history)),
+		}
+		for _, event := range history {
+			result.History = append(result.History,
+				btcjson.BanScoreEventResult{
+					Time:        event.Time.Unix(),
+					Misbehavior: event.Misbehavior.String(),
+					Reason:      event.Reason,
+					Persistent:  event.Weight.Persistent,
+					Transient:   event.Weight.Transient,
+					BanScore:    event.Score,
+				})
+		}
+		results = append(results, result)
+	}
+
+	if c.ID != nil && len(results) == 0 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCClientNodeNotConnected,
+			Message: "Node not found in connected nodes",
+		}
+	}
+	return results, nil
+}
+
 // handleGetPeerInfo implements the getpeerinfo command.
 func handleGetPeerInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	peers := s.cfg.ConnMgr.ConnectedPeers()
@@ -2459,6 +3246,9 @@ func handleGetPeerInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 			FeeFilter:      p.FeeFilter(),
 			SyncNode:       statsSnap.ID == syncPeerID,
 		}
+		persistent, transient := p.BanScoreComponents()
+		info.BanScorePersistent = int32(persistent)
+		info.BanScoreTransient = int32(transient)
 		if p.ToPeer().LastPingNonce() != 0 {
 			wait := float64(time.Since(statsSnap.LastPingTime).Nanoseconds())
 			// We actually want microseconds.
@@ -2608,6 +3398,27 @@ func handleGetRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan str
 	return *rawTxn, nil
 }
 
+// handleGetRPCInfo implements the getrpcinfo command.
+func handleGetRPCInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	calls := s.calls.activeCalls()
+	result := &btcjson.GetRPCInfoResult{
+		ActiveCommands: make([]btcjson.RPCActiveCommandResult, 0,
+			len(calls)),
+		LogPath: filepath.Join(cfg.LogDir, defaultLogFilename),
+	}
+	for _, call := range calls {
+		// The duration is reported in microseconds like Bitcoin Core.
+		duration := time.Since(call.start).Nanoseconds() / 1000
+		result.ActiveCommands = append(result.ActiveCommands,
+			btcjson.RPCActiveCommandResult{
+				Method:   call.method,
+				User:     call.user,
+				Duration: duration,
+			})
+	}
+	return result, nil
+}
+
 // handleGetTxOut handles gettxout commands.
 func handleGetTxOut(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*btcjson.GetTxOutCmd)
@@ -2717,6 +3528,389 @@ func handleGetTxOut(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (i
 	return txOutReply, nil
 }
 
+// reverseUint32Array treats the passed bytes as a series of uint32s and
+// reverses the byte order of each uint32.  The passed byte slice length must
+// be a multiple of 4 for a correct result.  The passed bytes slice is modified.
+func reverseUint32Array(b []byte) {
+	blen := len(b)
+	for i := 0; i < blen; i += 4 {
+		b[i], b[i+3] = b[i+3], b[i]
+		b[i+1], b[i+2] = b[i+2], b[i+1]
+	}
+}
+
+// bigToLEUint256 returns the passed big integer as an unsigned 256-bit integer
+// encoded as little-endian bytes.  Numbers which are larger than the max
+// unsigned 256-bit integer are truncated.
+func bigToLEUint256(n *big.Int) [uint256Size]byte {
+	// Pad or truncate the big-endian big int to correct number of bytes.
+	nBytes := n.Bytes()
+	nlen := len(nBytes)
+	pad := 0
+	start := 0
+	if nlen <= uint256Size {
+		pad = uint256Size - nlen
+	} else {
+		start = nlen - uint256Size
+	}
+	var buf [uint256Size]byte
+	copy(buf[pad:], nBytes[start:])
+
+	// Reverse the bytes to little endian and return them.
+	for i := 0; i < uint256Size/2; i++ {
+		buf[i], buf[uint256Size-1-i] = buf[uint256Size-1-i], buf[i]
+	}
+	return buf
+}
+
+// handleGetWorkRequest is a helper for handleGetWork which deals with
+// generating and returning work to the caller.
+//
+// This function MUST be called with the RPC workstate request lock held.
+func handleGetWorkRequest(s *rpcServer) (interface{}, error) {
+	state := s.workState
+	generator := s.cfg.Generator
+
+	// The work state is only locked while it is accessed, but not while
+	// the block template is created or updated since that calls into the
+	// chain.  The work is handed out once it is recorded in the work state
+	// without a block being connected in the meantime, which would have
+	// discarded the template.
+	var msgBlock *wire.MsgBlock
+	for {
+		// Generate a new block template when the current best block
+		// has changed or the transactions in the memory pool have been
+		// updated and it has been at least one minute since the last
+		// template was generated.
+		lastTxUpdate := generator.TxSource().LastUpdated()
+		best := s.cfg.Chain.BestSnapshot()
+		latestHash, latestHeight := &best.Hash, best.Height
+		state.Lock()
+		msgBlock = state.msgBlock
+		generate := msgBlock == nil || state.prevHash == nil ||
+			!state.prevHash.IsEqual(latestHash) ||
+			(state.lastTxUpdate != lastTxUpdate &&
+				time.Now().After(state.lastGenerated.Add(time.Minute)))
+		if generate {
+			// Reset the extra nonce and clear all cached template
+			// variations if the best block changed.
+			if msgBlock != nil &&
+				!msgBlock.Header.PrevBlock.IsEqual(latestHash) {
+
+				state.extraNonce = 0
+				state.blockInfo = make(map[chainhash.Hash]*workStateBlockInfo)
+			}
+
+			// Reset the previous best hash the block template was
+			// generated against so any errors below cause the next
+			// invocation to try again.
+			state.prevHash = nil
+		} else {
+			// Increment the extra nonce, which the existing
+			// template is updated with below.
+			state.extraNonce++
+		}
+		extraNonce := state.extraNonce
+		state.Unlock()
+
+		if generate {
+			// Choose a payment address at random.
+			payToAddr := cfg.miningAddrs[rand.Intn(len(cfg.miningAddrs))]
+
+			template, err := s.cfg.TemplateCache.NewBlockTemplate(payToAddr)
+			if err != nil {
+				context := "Failed to create new block template"
+				return nil, internalRPCError(err.Error(), context)
+			}
+			msgBlock = template.Block
+
+			rpcsLog.Debugf("Generated block template (timestamp %v, "+
+				"extra nonce %d, target %064x, merkle root %s, "+
+				"signature script %x)", msgBlock.Header.Timestamp,
+				extraNonce,
+				blockchain.CompactToBig(msgBlock.Header.Bits),
+				msgBlock.Header.MerkleRoot,
+				msgBlock.Transactions[0].TxIn[0].SignatureScript)
+		} else {
+			// At this point, there is a saved block template and a
+			// new request for work was made, but either the
+			// available transactions haven't change or it hasn't
+			// been long enough to trigger a new block template to
+			// be generated.  So, update the existing block template
+			// and track the variations so each variation can be
+			// regenerated if a caller finds an answer and makes a
+			// submission against it.
+
+			// Update the time of the block template to the current
+			// time while accounting for the median time of the past
+			// several blocks per the chain consensus rules.
+			generator.UpdateBlockTime(msgBlock)
+
+			// Update the block template with the new extra nonce by
+			// regenerating the coinbase script and setting the
+			// merkle root to the new value.
+			err := generator.UpdateExtraNonce(msgBlock,
+				latestHeight+1, extraNonce)
+			if err != nil {
+				errStr := fmt.Sprintf("Failed to update extra "+
+					"nonce: %v", err)
+				return nil, internalRPCError(errStr, "")
+			}
+
+			rpcsLog.Debugf("Updated block template (timestamp %v, "+
+				"extra nonce %d, target %064x, merkle root %s, "+
+				"signature script %x)", msgBlock.Header.Timestamp,
+				extraNonce,
+				blockchain.CompactToBig(msgBlock.Header.Bits),
+				msgBlock.Header.MerkleRoot,
+				msgBlock.Transactions[0].TxIn[0].SignatureScript)
+		}
+
+		// Start over when a block was connected while the template was
+		// created or updated, since the work is stale.  The best
+		// snapshot is updated before the work state is discarded, so a
+		// stale template is always detected here.
+		state.Lock()
+		best = s.cfg.Chain.BestSnapshot()
+		if !msgBlock.Header.PrevBlock.IsEqual(&best.Hash) ||
+			(!generate && state.msgBlock != msgBlock) {
+
+			state.Unlock()
+			continue
+		}
+
+		// Update work state to ensure another block template isn't
+		// generated until needed.
+		if generate {
+			state.msgBlock = msgBlock
+			state.lastGenerated = time.Now()
+			state.lastTxUpdate = lastTxUpdate
+			prevHash := msgBlock.Header.PrevBlock
+			state.prevHash = &prevHash
+		}
+
+		// In order to efficiently store the variations of block
+		// templates that have been provided to callers, save a pointer
+		// to the block as well as the modified signature script keyed
+		// by the merkle root.  This information, along with the data
+		// that is included in a work submission, is used to rebuild
+		// the block before checking the submitted solution.
+		coinbaseTx := msgBlock.Transactions[0]
+		state.blockInfo[msgBlock.Header.MerkleRoot] = &workStateBlockInfo{
+			msgBlock:        msgBlock,
+			signatureScript: coinbaseTx.TxIn[0].SignatureScript,
+		}
+		state.Unlock()
+		break
+	}
+
+	// Serialize the block header into a buffer large enough to hold the
+	// block header and the internal sha256 padding that is added and
+	// returned as part of the data below.
+	data := make([]byte, 0, getworkDataLen)
+	buf := bytes.NewBuffer(data)
+	err := msgBlock.Header.Serialize(buf)
+	if err != nil {
+		errStr := fmt.Sprintf("Failed to serialize data: %v", err)
+		return nil, internalRPCError(errStr, "")
+	}
+
+	// Expand the data slice to include the full data buffer and apply the
+	// internal sha256 padding which consists of a single 1 bit followed
+	// by enough zeros to pad the message out to 56 bytes followed by the
+	// length of the message in bits encoded as a big-endian uint64
+	// (8 bytes).  Thus, the resulting length is a multiple of the sha256
+	// block size (64 bytes).  This padding is only kept for compatibility
+	// with legacy miners.
+	data = data[:getworkDataLen]
+	data[wire.MaxBlockHeaderPayload] = 0x80
+	binary.BigEndian.PutUint64(data[len(data)-8:],
+		wire.MaxBlockHeaderPayload*8)
+
+	// The final result reverses each of the fields to little endian.  In
+	// particular, the data and hash1 fields are treated as arrays of
+	// uint32s (per the internal sha256 hashing state) which are in
+	// big endian, and thus each 4 bytes is byte swapped.  The target is
+	// also in big endian, but it is treated as a uint256 and byte swapped
+	// to little endian accordingly.
+	//
+	// The fact the fields are reversed in this way is rather odd and likely
+	// an artifact of some legacy internal state in the reference
+	// implementation, but it is required for compatibility.
+	//
+	// The midstate is left empty since the proof of work is scrypt, so
+	// there is no use for the SHA-256 state of the first chunk of data.
+	reverseUint32Array(data)
+	hash1 := make([]byte, hash1Len)
+	hash1[chainhash.HashSize] = 0x80
+	binary.BigEndian.PutUint64(hash1[len(hash1)-8:], chainhash.HashSize*8)
+	reverseUint32Array(hash1)
+	target := bigToLEUint256(blockchain.CompactToBig(msgBlock.Header.Bits))
+	reply := &btcjson.GetWorkResult{
+		Data:   hex.EncodeToString(data),
+		Hash1:  hex.EncodeToString(hash1),
+		Target: hex.EncodeToString(target[:]),
+	}
+	return reply, nil
+}
+
+// handleGetWorkSubmission is a helper for handleGetWork which deals with
+// the caller submitting work to be verified and processed.
+//
+// This function MUST be called with the RPC workstate request lock held.
+func handleGetWorkSubmission(s *rpcServer, hexData string) (interface{}, error) {
+	// Ensure the provided data is sane.
+	if len(hexData)%2 != 0 {
+		hexData = "0" + hexData
+	}
+	data, err := hex.DecodeString(hexData)
+	if err != nil {
+		return false, rpcDecodeHexError(hexData)
+	}
+	if len(data) != getworkDataLen {
+		return false, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidParameter,
+			Message: fmt.Sprintf("Argument must be %d bytes (not "+
+				"%d)", getworkDataLen, len(data)),
+		}
+	}
+
+	// Reverse the data as if it were an array of 32-bit unsigned integers.
+	// The fact the getwork request and submission data is reversed in this
+	// way is rather odd and likely an artifact of some legacy internal state
+	// in the reference implementation, but it is required for
+	// compatibility.
+	reverseUint32Array(data)
+
+	// Deserialize the block header from the data.
+	var submittedHeader wire.BlockHeader
+	bhBuf := bytes.NewReader(data[0:wire.MaxBlockHeaderPayload])
+	err = submittedHeader.Deserialize(bhBuf)
+	if err != nil {
+		return false, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidParameter,
+			Message: fmt.Sprintf("Argument does not contain a "+
+				"valid block header: %v", err),
+		}
+	}
+
+	// Look up the full block for the provided data based on the merkle
+	// root.  Return false to indicate the solve failed if it's not
+	// available, which happens when the work is stale.
+	state := s.workState
+	state.Lock()
+	blockInfo, ok := state.blockInfo[submittedHeader.MerkleRoot]
+	state.Unlock()
+	if !ok {
+		rpcsLog.Debugf("Block submitted via getwork has no matching "+
+			"template for merkle root %s",
+			submittedHeader.MerkleRoot)
+		return false, nil
+	}
+
+	// Reconstruct the block using the submitted header and the stored
+	// block info.
+	// The coinbase is copied since the signature script of the template
+	// is changed for each request.
+	msgBlock := blockInfo.msgBlock
+	block := vtcutil.NewBlock(&wire.MsgBlock{
+		Header:       submittedHeader,
+		Transactions: make([]*wire.MsgTx, 0, len(msgBlock.Transactions)),
+	})
+	coinbaseTx := msgBlock.Transactions[0].Copy()
+	coinbaseTx.TxIn[0].SignatureScript = blockInfo.signatureScript
+	block.MsgBlock().Transactions = append(block.MsgBlock().Transactions,
+		coinbaseTx)
+	block.MsgBlock().Transactions = append(block.MsgBlock().Transactions,
+		msgBlock.Transactions[1:]...)
+
+	// Ensure the submitted block hash is less than the target difficulty.
+	powHash, err := submittedHeader.PowHash()
+	if err != nil {
+		context := "Failed to calculate proof of work hash"
+		return false, internalRPCError(err.Error(), context)
+	}
+	target := blockchain.CompactToBig(submittedHeader.Bits)
+	if blockchain.HashToBig(powHash).Cmp(target) > 0 {
+		rpcsLog.Debugf("Block submitted via getwork does not meet the "+
+			"required proof of work: proof of work hash %v, target "+
+			"%064x", powHash, target)
+		return false, nil
+	}
+
+	// Process this block using the same rules as blocks coming from other
+	// nodes.  This will in turn relay it to the network like normal.
+	isOrphan, err := s.cfg.SyncMgr.SubmitBlock(block, blockchain.BFNone)
+	if err != nil || isOrphan {
+		// Anything other than a rule violation is an unexpected error,
+		// so return that error as an internal error.
+		if _, ok := err.(blockchain.RuleError); !ok && err != nil {
+			context := "Unexpected error while processing block"
+			return false, internalRPCError(err.Error(), context)
+		}
+
+		rpcsLog.Infof("Block submitted via getwork rejected: %v "+
+			"(orphan %v)", err, isOrphan)
+		return false, nil
+	}
+
+	// The block was accepted.
+	rpcsLog.Infof("Block submitted via getwork accepted: %s", block.Hash())
+	return true, nil
+}
+
+// handleGetWork implements the getwork command.
+func handleGetWork(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetWorkCmd)
+
+	// Respond with an error if there are no addresses to pay the created
+	// blocks to.
+	if len(cfg.miningAddrs) == 0 {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInternal.Code,
+			Message: "No payment addresses specified via " +
+				"--miningaddr",
+		}
+	}
+
+	// Return an error if there are no peers connected since there is no
+	// way to relay a found block or receive transactions to work on.
+	// However, allow this state when running in the regression test or
+	// simulation test mode.
+	if !(cfg.RegressionTest || cfg.SimNet) &&
+		s.cfg.ConnMgr.ConnectedCount() == 0 {
+
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCClientNotConnected,
+			Message: "Bitcoin is not connected",
+		}
+	}
+
+	// No point in generating or accepting work before the chain is synced.
+	currentHeight := s.cfg.Chain.BestSnapshot().Height
+	if currentHeight != 0 && !s.cfg.SyncMgr.IsCurrent() {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCClientInInitialDownload,
+			Message: "Bitcoin is downloading blocks...",
+		}
+	}
+
+	// Protect concurrent access from multiple RPC invocations for work
+	// requests and submission.
+	s.workState.requestLock.Lock()
+	defer s.workState.requestLock.Unlock()
+
+	// When the caller provided data, it is a submission of a supposedly
+	// solved block that needs to be checked and submitted to the network
+	// if valid.
+	if c.Data != nil && *c.Data != "" {
+		return handleGetWorkSubmission(s, *c.Data)
+	}
+
+	// No data was provided, so the caller is requesting work.
+	return handleGetWorkRequest(s)
+}
+
 // handleHelp implements the help command.
 func handleHelp(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*btcjson.HelpCmd)
@@ -2756,6 +3950,21 @@ func handleHelp(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (inter
 	return help, nil
 }
 
+// handleListBanned implements the listbanned command.
+func handleListBanned(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	entries := s.cfg.ConnMgr.BannedSubnets()
+	results := make([]btcjson.ListBannedResult, 0, len(entries))
+	for _, entry := range entries {
+		results = append(results, btcjson.ListBannedResult{
+			Address:     entry.Subnet.String(),
+			BannedUntil: entry.Until.Unix(),
+			BanCreated:  entry.Created.Unix(),
+			BanReason:   entry.Reason,
+		})
+	}
+	return results, nil
+}
+
 // handlePing implements the ping command.
 func handlePing(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	// Ask server to ping \o_
@@ -2769,6 +3978,23 @@ func handlePing(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (inter
 	return nil, nil
 }
 
+// handlePrioritiseTransaction implements the prioritisetransaction command.
+func handlePrioritiseTransaction(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.PrioritiseTransactionCmd)
+	txHash, err := chainhash.NewHashFromStr(c.Txid)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.Txid)
+	}
+
+	if _, err := s.cfg.TxMemPool.PrioritiseTransaction(txHash, c.FeeDelta); err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCOutOfMemory,
+			Message: err.Error(),
+		}
+	}
+	return true, nil
+}
+
 // retrievedTx represents a transaction that was either loaded from the
 // transaction memory pool or from the database.  When a transaction is loaded
 // from the database, it is loaded with the raw serialized bytes while the
@@ -2990,6 +4216,67 @@ func fetchMempoolTxnsForAddress(s *rpcServer, addr vtcutil.Address, numToSkip, n
 	return mpTxns[numToSkip:rangeEnd], numToSkip
 }
 
+// handleScanTxOutSet implements the scantxoutset command.
+func handleScanTxOutSet(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.ScanTxOutSetCmd)
+	switch c.Action {
+	case btcjson.ScanStatus:
+		progress, ok := s.utxoScanner.Progress()
+		if !ok {
+			return nil, nil
+		}
+		return &btcjson.ScanTxOutSetStatusResult{Progress: progress}, nil
+
+	case btcjson.ScanAbort:
+		return s.utxoScanner.Abort(), nil
+
+	case btcjson.ScanStart:
+		// Handled below.
+
+	default:
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: fmt.Sprintf("Invalid action %q", c.Action),
+		}
+	}
+
+	if c.ScanObjects == nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Scan objects are required to start a scan",
+		}
+	}
+	needles, err := scanNeedles(*c.ScanObjects, s.cfg.ChainParams)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: err.Error(),
+		}
+	}
+
+	resultChan, abortScan, ok := s.utxoScanner.Start(s.cfg.Chain, needles)
+	if !ok {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidParameter,
+			Message: "Scan already in progress, use action " +
+				"\"abort\" or \"status\"",
+		}
+	}
+
+	select {
+	// Abort the scan when the client closes before it finishes so it
+	// doesn't keep running in the background.  Only the scan started by
+	// this client is aborted since another client might have started a
+	// new scan once this one finished.
+	case <-closeChan:
+		abortScan()
+		return nil, ErrClientQuit
+
+	case result := <-resultChan:
+		return result, nil
+	}
+}
+
 // handleSearchRawTransactions implements the searchrawtransactions command.
 func handleSearchRawTransactions(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	// Respond with an error if the address index is not enabled.
@@ -3331,6 +4618,65 @@ func handleSendRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan st
 	return tx.Hash().String(), nil
 }
 
+// handleSetBan implements the setban command.
+func handleSetBan(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.SetBanCmd)
+
+	subnet, err := connmgr.ParseSubnet(c.SubNet)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCClientInvalidIPOrSubnet,
+			Message: "Invalid IP/Subnet: " + err.Error(),
+		}
+	}
+
+	switch c.SubCmd {
+	case btcjson.SBAdd:
+		// A zero ban time uses the configured default ban duration.
+		// Otherwise the ban time is either a number of seconds from now
+		// or, when absolute is set, a unix timestamp.
+		until := time.Now().Add(cfg.BanDuration)
+		if c.BanTime != nil && *c.BanTime > 0 {
+			if c.Absolute != nil && *c.Absolute {
+				until = time.Unix(*c.BanTime, 0)
+			} else {
+				until = time.Now().Add(time.Duration(*c.BanTime) *
+					time.Second)
+			}
+		}
+		if !until.After(time.Now()) {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCInvalidParameter,
+				Message: "Ban time is in the past",
+			}
+		}
+
+		err := s.cfg.ConnMgr.BanSubnet(subnet, until,
+			connmgr.BanReasonManuallyAdded)
+		if err != nil {
+			context := "Failed to save ban list"
+			return nil, internalRPCError(err.Error(), context)
+		}
+
+	case btcjson.SBRemove:
+		if err := s.cfg.ConnMgr.UnbanSubnet(subnet); err != nil {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCClientInvalidIPOrSubnet,
+				Message: "Unban failed: " + err.Error(),
+			}
+		}
+
+	default:
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "invalid subcommand for setban",
+		}
+	}
+
+	// no data returned unless an error.
+	return nil, nil
+}
+
 // handleSetGenerate implements the setgenerate command.
 func handleSetGenerate(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*btcjson.SetGenerateCmd)
@@ -3409,6 +4755,122 @@ func handleSubmitBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 	return nil, nil
 }
 
+// blockValidityResult returns the result of the submitheader and
+// testblockvalidity commands for the header or block with the passed hash
+// given the error processing it returned.  Errors other than rule violations
+// are returned as internal errors.
+func blockValidityResult(hash *chainhash.Hash, err error) (*btcjson.BlockValidityResult, error) {
+	result := &btcjson.BlockValidityResult{
+		Hash:  hash.String(),
+		Valid: err == nil,
+	}
+	if err == nil {
+		return result, nil
+	}
+
+	ruleErr, ok := err.(blockchain.RuleError)
+	if !ok {
+		context := "Failed to process block " + hash.String()
+		return nil, internalRPCError(err.Error(), context)
+	}
+	result.Reason = chainErrToGBTErrString(ruleErr)
+	result.RuleError = ruleErr.ErrorCode.String()
+	result.Description = ruleErr.Description
+	return result, nil
+}
+
+// handleSubmitHeader implements the submitheader command.
+func handleSubmitHeader(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.SubmitHeaderCmd)
+
+	// Deserialize the submitted header.
+	hexStr := c.HexHeader
+	if len(hexStr)%2 != 0 {
+		hexStr = "0" + c.HexHeader
+	}
+	serializedHeader, err := hex.DecodeString(hexStr)
+	if err != nil {
+		return nil, rpcDecodeHexError(hexStr)
+	}
+	if len(serializedHeader) != wire.MaxBlockHeaderPayload {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCDeserialization,
+			Message: fmt.Sprintf("Header must be %d bytes (not %d)",
+				wire.MaxBlockHeaderPayload, len(serializedHeader)),
+		}
+	}
+	var header wire.BlockHeader
+	err = header.Deserialize(bytes.NewReader(serializedHeader))
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCDeserialization,
+			Message: "Header decode failed: " + err.Error(),
+		}
+	}
+
+	// The header is checked against the consensus rules and added to the
+	// known headers, but it does not affect the best chain until the block
+	// is submitted.
+	blockHash := header.BlockHash()
+	err = s.cfg.Chain.ProcessBlockHeader(&header)
+	if err == nil {
+		rpcsLog.Infof("Accepted block header %s via submitheader",
+			blockHash)
+	}
+	return blockValidityResult(&blockHash, err)
+}
+
+// handleTestBlockValidity implements the testblockvalidity command.
+func handleTestBlockValidity(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.TestBlockValidityCmd)
+
+	// Deserialize the block to test.
+	hexStr := c.HexBlock
+	if len(hexStr)%2 != 0 {
+		hexStr = "0" + c.HexBlock
+	}
+	serializedBlock, err := hex.DecodeString(hexStr)
+	if err != nil {
+		return nil, rpcDecodeHexError(hexStr)
+	}
+	block, err := vtcutil.NewBlockFromBytes(serializedBlock)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCDeserialization,
+			Message: "Block decode failed: " + err.Error(),
+		}
+	}
+
+	// Only blocks which extend the best chain are connected when they are
+	// processed, so blocks building on any other block can't be fully
+	// validated.
+	best := s.cfg.Chain.BestSnapshot()
+	if block.MsgBlock().Header.PrevBlock != best.Hash {
+		return &btcjson.BlockValidityResult{
+			Hash:   block.Hash().String(),
+			Reason: "bad-prevblk",
+			Description: fmt.Sprintf("block does not extend the "+
+				"best block %v", best.Hash),
+		}, nil
+	}
+
+	// Run the block through all checks without modifying the chain.  The
+	// proof of work check is skipped when requested so templates can be
+	// tested before they are solved.
+	flags := blockchain.BFDryRun
+	if !*c.CheckPoW {
+		flags |= blockchain.BFNoPoWCheck
+	}
+	isOrphan, err := s.cfg.SyncMgr.SubmitBlock(block, flags)
+	if err == nil && isOrphan {
+		return &btcjson.BlockValidityResult{
+			Hash:   block.Hash().String(),
+			Reason: "orphan",
+		}, nil
+	}
+	return blockValidityResult(block.Hash(), err)
+}
+
 // handleUptime implements the uptime command.
 func handleUptime(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	return time.Now().Unix() - s.cfg.StartupTime, nil
@@ -3565,15 +5027,19 @@ type rpcServer struct {
 	started                int32
 	shutdown               int32
 	cfg                    rpcserverConfig
-	authsha                [sha256.Size]byte
-	limitauthsha           [sha256.Size]byte
+	auth                   *rpcAuthenticator
+	authCookie             string
+	calls                  *rpcCallTracker
 	ntfnMgr                *wsNotificationManager
+	grpc                   *grpcServer
 	numClients             int32
 	statusLines            map[int]string
 	statusLock             sync.RWMutex
 	wg                     sync.WaitGroup
 	gbtWorkState           *gbtWorkState
+	workState              *workState
 	helpCacher             *helpCacher
+	utxoScanner            *utxoScanner
 	requestProcessShutdown chan struct{}
 	quit                   chan int
 }
@@ -3647,10 +5113,23 @@ func (s *rpcServer) Stop() error {
 			return err
 		}
 	}
+	if s.grpc != nil {
+		s.grpc.server.Stop()
+	}
 	s.ntfnMgr.Shutdown()
 	s.ntfnMgr.WaitForShutdown()
+	s.utxoScanner.Abort()
 	close(s.quit)
 	s.wg.Wait()
+	if err := s.calls.closeAuditLog(); err != nil {
+		rpcsLog.Errorf("Unable to close RPC audit log: %v", err)
+	}
+	if s.authCookie != "" {
+		if err := os.Remove(s.authCookie); err != nil {
+			rpcsLog.Warnf("Unable to remove RPC authentication "+
+				"cookie: %v", err)
+		}
+	}
 	rpcsLog.Infof("RPC server shutdown complete")
 	return nil
 }
@@ -3662,7 +5141,7 @@ func (s *rpcServer) RequestedProcessShutdown() <-chan struct{} {
 	return s.requestProcessShutdown
 }
 
-// NotifyNewTransactions notifies both websocket and getblocktemplate long
+// NotifyNewTransactions notifies websocket, gRPC and getblocktemplate long
 // poll clients of the passed transactions.  This function should be called
 // whenever new transactions are added to the mempool.
 func (s *rpcServer) NotifyNewTransactions(txns []*mempool.TxDesc) {
@@ -3670,6 +5149,11 @@ func (s *rpcServer) NotifyNewTransactions(txns []*mempool.TxDesc) {
 		// Notify websocket clients about mempool transactions.
 		s.ntfnMgr.NotifyMempoolTx(txD.Tx, true)
 
+		// Notify gRPC transaction subscriptions.
+		if s.grpc != nil {
+			s.grpc.NotifyMempoolTx(txD.Tx)
+		}
+
 		// Potentially notify any getblocktemplate long poll clients
 		// about stale block templates due to the new transaction.
 		s.gbtWorkState.NotifyMempoolTx(s.cfg.TxMemPool.LastUpdated())
@@ -3712,55 +5196,46 @@ func (s *rpcServer) decrementClients() {
 
 // checkAuth checks the HTTP Basic authentication supplied by a wallet
 // or RPC client in the HTTP request r.  If the supplied authentication
-// does not match the username and password expected, a non-nil error is
-// returned.
+// does not match the username and password of any RPC user, a non-nil error
+// is returned.
 //
 // This check is time-constant.
 //
-// The first bool return value signifies auth success (true if successful) and
-// the second bool return value specifies whether the user can change the state
-// of the server (true) or whether the user is limited (false). The second is
-// always false if the first is.
-func (s *rpcServer) checkAuth(r *http.Request, require bool) (bool, bool, error) {
-	authhdr := r.Header["Authorization"]
-	if len(authhdr) <= 0 {
+// The authenticated user is returned, which determines the methods the client
+// may invoke.  It is nil when no authentication was supplied and it is not
+// required.
+func (s *rpcServer) checkAuth(r *http.Request, require bool) (*rpcUser, error) {
+	if len(r.Header["Authorization"]) <= 0 {
 		if require {
 			rpcsLog.Warnf("RPC authentication failure from %s",
 				r.RemoteAddr)
-			return false, false, errors.New("auth failure")
+			return nil, errors.New("auth failure")
 		}
 
-		return false, false, nil
-	}
-
-	authsha := sha256.Sum256([]byte(authhdr[0]))
-
-	// Check for limited auth first as in environments with limited users, those
-	// are probably expected to have a higher volume of calls
-	limitcmp := subtle.ConstantTimeCompare(authsha[:], s.limitauthsha[:])
-	if limitcmp == 1 {
-		return true, false, nil
+		return nil, nil
 	}
 
-	// Check for admin-level auth
-	cmp := subtle.ConstantTimeCompare(authsha[:], s.authsha[:])
-	if cmp == 1 {
-		return true, true, nil
+	name, pass, ok := r.BasicAuth()
+	if ok {
+		if user := s.auth.authenticate(name, pass); user != nil {
+			return user, nil
+		}
 	}
 
-	// Request's auth doesn't match either user
+	// Request's auth doesn't match any user
 	rpcsLog.Warnf("RPC authentication failure from %s", r.RemoteAddr)
-	return false, false, errors.New("auth failure")
+	return nil, errors.New("auth failure")
 }
 
 // parsedRPCCmd represents a JSON-RPC request object that has been parsed into
 // a known concrete command along with any error that might have happened while
 // parsing it.
 type parsedRPCCmd struct {
-	id     interface{}
-	method string
-	cmd    interface{}
-	err    *btcjson.RPCError
+	jsonrpc string
+	id      interface{}
+	method  string
+	cmd     interface{}
+	err     *btcjson.RPCError
 }
 
 // standardCmdResult checks that a parsed command is a standard Bitcoin JSON-RPC
@@ -3794,6 +5269,7 @@ handled:
 // an unregistered command or invalid parameters.
 func parseCmd(request *btcjson.Request) *parsedRPCCmd {
 	var parsedCmd parsedRPCCmd
+	parsedCmd.jsonrpc = request.Jsonrpc
 	parsedCmd.id = request.ID
 	parsedCmd.method = request.Method
 
@@ -3820,9 +5296,10 @@ func parseCmd(request *btcjson.Request) *parsedRPCCmd {
 }
 
 // createMarshalledReply returns a new marshalled JSON-RPC response given the
-// passed parameters.  It will automatically convert errors that are not of
-// the type *btcjson.RPCError to the appropriate type as needed.
-func createMarshalledReply(id, result interface{}, replyErr error) ([]byte, error) {
+// passed parameters.  The response uses the envelope of the passed JSON-RPC
+// protocol version.  It will automatically convert errors that are not of the
+// type *btcjson.RPCError to the appropriate type as needed.
+func createMarshalledReply(rpcVersion string, id, result interface{}, replyErr error) ([]byte, error) {
 	var jsonErr *btcjson.RPCError
 	if replyErr != nil {
 		if jErr, ok := replyErr.(*btcjson.RPCError); ok {
@@ -3832,11 +5309,170 @@ func createMarshalledReply(id, result interface{}, replyErr error) ([]byte, erro
 		}
 	}
 
-	return btcjson.MarshalResponse(id, result, jsonErr)
+	return btcjson.MarshalResponseVersion(rpcVersion, id, result, jsonErr)
+}
+
+// processRequest services a single JSON-RPC request and returns the marshalled
+// response to it.  Nil is returned for notifications since they must not be
+// responded to.
+func (s *rpcServer) processRequest(request *btcjson.Request, user *rpcUser, remoteAddr string, closeChan <-chan struct{}) []byte {
+	// The JSON-RPC 1.0 spec defines that notifications must have their "id"
+	// set to null and states that notifications do not have a response.
+	//
+	// A JSON-RPC 2.0 notification is a request with "json-rpc":"2.0", and
+	// without an "id" member. The specification states that notifications
+	// must not be responded to. JSON-RPC 2.0 permits the null value as a
+	// valid request id, therefore such requests are not notifications.
+	//
+	// Bitcoin Core serves requests with "id":null or even an absent "id",
+	// and responds to such requests with "id":null in the response.
+	//
+	// Btcd does not respond to any request without and "id" or "id":null,
+	// regardless the indicated JSON-RPC protocol version unless RPC quirks
+	// are enabled. With RPC quirks enabled, such requests will be responded
+	// to if the reqeust does not indicate JSON-RPC version.
+	//
+	// RPC quirks can be enabled by the user to avoid compatibility issues
+	// with software relying on Core's behavior.
+	if request.ID == nil && !(cfg.RPCQuirks && request.Jsonrpc == "") {
+		return nil
+	}
+
+	// Check if the user is limited or rate limited and set error if the
+	// method may not be invoked.
+	var jsonErr error
+	var result interface{}
+	call, authErr := s.calls.begin(user, remoteAddr, rpcTransportHTTP,
+		request.Method)
+	if authErr != nil {
+		jsonErr = authErr
+	}
+
+	if jsonErr == nil {
+		// Attempt to parse the JSON-RPC request into a known concrete
+		// command.
+		parsedCmd := parseCmd(request)
+		if parsedCmd.err != nil {
+			jsonErr = parsedCmd.err
+		} else {
+			result, jsonErr = s.standardCmdResult(parsedCmd, closeChan)
+		}
+	}
+	s.calls.end(call, rpcErrorCode(jsonErr))
+
+	// Marshal the response.  A result which can't be marshalled is
+	// reported to the caller as an internal error.
+	msg, err := createMarshalledReply(request.Jsonrpc, request.ID, result,
+		jsonErr)
+	if err != nil {
+		rpcsLog.Errorf("Failed to marshal reply for <%s> command: %v",
+			request.Method, err)
+		msg, err = createMarshalledReply(request.Jsonrpc, request.ID,
+			nil, btcjson.ErrRPCInternal)
+		if err != nil {
+			return nil
+		}
+	}
+	return msg
+}
+
+// processBatch services the requests of a JSON-RPC batch and returns the
+// marshalled responses to them in request order.  At most
+// rpcmaxconcurrentreqs requests of the batch are serviced concurrently.
+// Notifications have no response, so the returned slice may be shorter than the
+// batch.
+func (s *rpcServer) processBatch(batch []json.RawMessage, user *rpcUser, remoteAddr string, closeChan <-chan struct{}) [][]byte {
+	workers := cfg.RPCMaxConcurrentReqs
+	if workers < 1 {
+		workers = 1
+	}
+	sem := makeSemaphore(workers)
+
+	var wg sync.WaitGroup
+	responses := make([][]byte, len(batch))
+	for i, rawRequest := range batch {
+		// Each element of the batch which is not a valid request
+		// object is answered with its own error response.
+		request := new(btcjson.Request)
+		if err := json.Unmarshal(rawRequest, request); err != nil {
+			jsonErr := &btcjson.RPCError{
+				Code:    btcjson.ErrRPCInvalidRequest.Code,
+				Message: "Invalid request: " + err.Error(),
+			}
+			responses[i], err = createMarshalledReply("", nil, nil,
+				jsonErr)
+			if err != nil {
+				rpcsLog.Errorf("Failed to marshal reply: %v", err)
+			}
+			continue
+		}
+
+		sem.acquire()
+		wg.Add(1)
+		go func(i int, request *btcjson.Request) {
+			defer wg.Done()
+			responses[i] = s.processRequest(request, user,
+				remoteAddr, closeChan)
+			sem.release()
+		}(i, request)
+	}
+	wg.Wait()
+
+	// Drop the missing responses to notifications.
+	n := 0
+	for _, response := range responses {
+		if response != nil {
+			responses[n] = response
+			n++
+		}
+	}
+	return responses[:n]
+}
+
+// processBatchBody parses the passed raw body as a JSON-RPC batch, services it,
+// and returns the marshalled array of responses.  Malformed, empty and
+// oversized batches are rejected with a single error response as required by
+// the JSON-RPC 2.0 specification.  Nil is returned when the batch consists of
+// notifications only.
+func (s *rpcServer) processBatchBody(body []byte, user *rpcUser, remoteAddr string, closeChan <-chan struct{}) ([]byte, error) {
+	var batch []json.RawMessage
+	var jsonErr *btcjson.RPCError
+	err := json.Unmarshal(body, &batch)
+	switch {
+	case err != nil:
+		jsonErr = &btcjson.RPCError{
+			Code:    btcjson.ErrRPCParse.Code,
+			Message: "Failed to parse request: " + err.Error(),
+		}
+	case len(batch) == 0:
+		jsonErr = &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidRequest.Code,
+			Message: "Empty batch request",
+		}
+	case len(batch) > cfg.RPCMaxBatchSize:
+		jsonErr = &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidRequest.Code,
+			Message: fmt.Sprintf("Batch of %d requests exceeds the "+
+				"maximum of %d", len(batch), cfg.RPCMaxBatchSize),
+		}
+	}
+	if jsonErr != nil {
+		return createMarshalledReply("", nil, nil, jsonErr)
+	}
+
+	responses := s.processBatch(batch, user, remoteAddr, closeChan)
+	if len(responses) == 0 {
+		return nil, nil
+	}
+	var msg bytes.Buffer
+	msg.WriteByte('[')
+	msg.Write(bytes.Join(responses, []byte{','}))
+	msg.WriteByte(']')
+	return msg.Bytes(), nil
 }
 
 // jsonRPCRead handles reading and responding to RPC messages.
-func (s *rpcServer) jsonRPCRead(w http.ResponseWriter, r *http.Request, isAdmin bool) {
+func (s *rpcServer) jsonRPCRead(w http.ResponseWriter, r *http.Request, user *rpcUser) {
 	if atomic.LoadInt32(&s.shutdown) != 0 {
 		return
 	}
@@ -3876,83 +5512,51 @@ func (s *rpcServer) jsonRPCRead(w http.ResponseWriter, r *http.Request, isAdmin
 	defer buf.Flush()
 	conn.SetReadDeadline(timeZeroVal)
 
-	// Attempt to parse the raw body into a JSON-RPC request.
-	var responseID interface{}
-	var jsonErr error
-	var result interface{}
-	var request btcjson.Request
-	if err := json.Unmarshal(body, &request); err != nil {
-		jsonErr = &btcjson.RPCError{
-			Code:    btcjson.ErrRPCParse.Code,
-			Message: "Failed to parse request: " + err.Error(),
-		}
-	}
-	if jsonErr == nil {
-		// The JSON-RPC 1.0 spec defines that notifications must have their "id"
-		// set to null and states that notifications do not have a response.
-		//
-		// A JSON-RPC 2.0 notification is a request with "json-rpc":"2.0", and
-		// without an "id" member. The specification states that notifications
-		// must not be responded to. JSON-RPC 2.0 permits the null value as a
-		// valid request id, therefore such requests are not notifications.
-		//
-		// Bitcoin Core serves requests with "id":null or even an absent "id",
-		// and responds to such requests with "id":null in the response.
-		//
-		// Btcd does not respond to any request without and "id" or "id":null,
-		// regardless the indicated JSON-RPC protocol version unless RPC quirks
-		// are enabled. With RPC quirks enabled, such requests will be responded
-		// to if the reqeust does not indicate JSON-RPC version.
-		//
-		// RPC quirks can be enabled by the user to avoid compatibility issues
-		// with software relying on Core's behavior.
-		if request.ID == nil && !(cfg.RPCQuirks && request.Jsonrpc == "") {
-			return
-		}
-
-		// The parse was at least successful enough to have an ID so
-		// set it for the response.
-		responseID = request.ID
-
-		// Setup a close notifier.  Since the connection is hijacked,
-		// the CloseNotifer on the ResponseWriter is not available.
-		closeChan := make(chan struct{}, 1)
-		go func() {
-			_, err := conn.Read(make([]byte, 1))
-			if err != nil {
-				close(closeChan)
-			}
-		}()
-
-		// Check if the user is limited and set error if method unauthorized
-		if !isAdmin {
-			if _, ok := rpcLimited[request.Method]; !ok {
-				jsonErr = &btcjson.RPCError{
-					Code:    btcjson.ErrRPCInvalidParams.Code,
-					Message: "limited user not authorized for this method",
-				}
-			}
+	// Setup a close notifier.  Since the connection is hijacked,
+	// the CloseNotifer on the ResponseWriter is not available.
+	closeChan := make(chan struct{}, 1)
+	go func() {
+		_, err := conn.Read(make([]byte, 1))
+		if err != nil {
+			close(closeChan)
 		}
+	}()
 
-		if jsonErr == nil {
-			// Attempt to parse the JSON-RPC request into a known concrete
-			// command.
-			parsedCmd := parseCmd(&request)
-			if parsedCmd.err != nil {
-				jsonErr = parsedCmd.err
-			} else {
-				result, jsonErr = s.standardCmdResult(parsedCmd, closeChan)
+	// Attempt to parse the raw body into either a single JSON-RPC request
+	// or a batch of them and service it.
+	var msg []byte
+	if btcjson.IsBatchRequest(body) {
+		msg, err = s.processBatchBody(body, user, r.RemoteAddr,
+			closeChan)
+	} else {
+		var request btcjson.Request
+		if jerr := json.Unmarshal(body, &request); jerr != nil {
+			jsonErr := &btcjson.RPCError{
+				Code:    btcjson.ErrRPCParse.Code,
+				Message: "Failed to parse request: " + jerr.Error(),
 			}
+			msg, err = createMarshalledReply("", nil, nil, jsonErr)
+		} else {
+			msg = s.processRequest(&request, user, r.RemoteAddr,
+				closeChan)
 		}
 	}
-
-	// Marshal the response.
-	msg, err := createMarshalledReply(responseID, result, jsonErr)
 	if err != nil {
 		rpcsLog.Errorf("Failed to marshal reply: %v", err)
 		return
 	}
 
+	// Notifications are not responded to, so only acknowledge the request
+	// when there is no response to write.
+	if msg == nil {
+		err := s.writeHTTPResponseHeaders(r, w.Header(),
+			http.StatusNoContent, buf)
+		if err != nil {
+			rpcsLog.Error(err)
+		}
+		return
+	}
+
 	// Write the response.
 	err = s.writeHTTPResponseHeaders(r, w.Header(), http.StatusOK, buf)
 	if err != nil {
@@ -4003,19 +5607,24 @@ func (s *rpcServer) Start() {
 		// Keep track of the number of connected clients.
 		s.incrementClients()
 		defer s.decrementClients()
-		_, isAdmin, err := s.checkAuth(r, true)
+		user, err := s.checkAuth(r, true)
 		if err != nil {
 			jsonAuthFail(w)
 			return
 		}
 
 		// Read and respond to the request.
-		s.jsonRPCRead(w, r, isAdmin)
+		s.jsonRPCRead(w, r, user)
 	})
 
+	// Unauthenticated REST endpoints.
+	if cfg.EnableREST {
+		rpcServeMux.HandleFunc("/rest/", s.handleREST)
+	}
+
 	// Websocket endpoint.
 	rpcServeMux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
-		authenticated, isAdmin, err := s.checkAuth(r, false)
+		user, err := s.checkAuth(r, false)
 		if err != nil {
 			jsonAuthFail(w)
 			return
@@ -4032,7 +5641,7 @@ func (s *rpcServer) Start() {
 			http.Error(w, "400 Bad Request.", http.StatusBadRequest)
 			return
 		}
-		s.WebsocketHandler(ws, r.RemoteAddr, authenticated, isAdmin)
+		s.WebsocketHandler(ws, r.RemoteAddr, user)
 	})
 
 	for _, listener := range s.cfg.Listeners {
@@ -4045,6 +5654,20 @@ func (s *rpcServer) Start() {
 		}(listener)
 	}
 
+	if s.grpc != nil {
+		for _, listener := range s.cfg.GRPCListeners {
+			s.wg.Add(1)
+			go func(listener net.Listener) {
+				rpcsLog.Infof("gRPC server listening on %s",
+					listener.Addr())
+				s.grpc.server.Serve(listener)
+				rpcsLog.Tracef("gRPC listener done for %s",
+					listener.Addr())
+				s.wg.Done()
+			}(listener)
+		}
+	}
+
 	s.ntfnMgr.Start()
 }
 
@@ -4088,6 +5711,14 @@ type rpcserverPeer interface {
 	// the peer is to being banned.
 	BanScore() uint32
 
+	// BanScoreComponents returns the persistent and the decaying parts of
+	// the ban score.
+	BanScoreComponents() (persistent, transient uint32)
+
+	// BanScoreHistory returns the events which increased the ban score of
+	// the peer from oldest to newest.
+	BanScoreHistory() []banScoreEvent
+
 	// FeeFilter returns the requested current minimum fee rate for which
 	// transactions should be announced.
 	FeeFilter() int64
@@ -4133,6 +5764,10 @@ type rpcserverConnManager interface {
 	// network for all peers.
 	NetTotals() (uint64, uint64)
 
+	// UploadTarget returns the state of the upload target for the current
+	// cycle.
+	UploadTarget() uploadTargetState
+
 	// ConnectedPeers returns an array consisting of all connected peers.
 	ConnectedPeers() []rpcserverPeer
 
@@ -4152,6 +5787,21 @@ type rpcserverConnManager interface {
 	// RelayTransactions generates and relays inventory vectors for all of
 	// the passed transactions to all connected peers.
 	RelayTransactions(txns []*mempool.TxDesc)
+
+	// BanSubnet bans the provided subnet until the given time and
+	// disconnects all connected peers which are part of it.
+	BanSubnet(subnet *net.IPNet, until time.Time, reason string) error
+
+	// UnbanSubnet removes the provided subnet from the ban list.
+	// Attempting to unban a subnet which is not banned will return an
+	// error.
+	UnbanSubnet(subnet *net.IPNet) error
+
+	// BannedSubnets returns all of the currently banned subnets.
+	BannedSubnets() []connmgr.BanEntry
+
+	// ClearBanned removes all subnets from the ban list.
+	ClearBanned() error
 }
 
 // rpcserverSyncManager represents a sync manager for use with the RPC server.
@@ -4189,6 +5839,15 @@ type rpcserverConfig struct {
 	// is stopped.
 	Listeners []net.Listener
 
+	// GRPCListeners defines a slice of listeners for which the gRPC server
+	// will take ownership of and accept connections.  The gRPC server is
+	// only created when there is at least one listener.
+	GRPCListeners []net.Listener
+
+	// GRPCTLSConfig is the TLS configuration of the gRPC server.  Calls
+	// are served without TLS when it is nil.
+	GRPCTLSConfig *tls.Config
+
 	// StartupTime is the unix timestamp for when the server that is hosting
 	// the RPC server started.
 	StartupTime int64
@@ -4220,6 +5879,11 @@ type rpcserverConfig struct {
 	Generator *mining.BlkTmplGenerator
 	CPUMiner  *cpuminer.CPUMiner
 
+	// TemplateCache provides the block templates served to external
+	// miners.  It caches the templates of the generator so getwork,
+	// getblocktemplate and the Stratum server share them.
+	TemplateCache *mining.TemplateCache
+
 	// These fields define any optional indexes the RPC server can make use
 	// of to provide additional data when queried.
 	TxIndex   *indexers.TxIndex
@@ -4232,22 +5896,68 @@ func newRPCServer(config *rpcserverConfig) (*rpcServer, error) {
 	rpc := rpcServer{
 		cfg:                    *config,
 		statusLines:            make(map[int]string),
-		gbtWorkState:           newGbtWorkState(config.TimeSource),
+		gbtWorkState:           newGbtWorkState(config.TimeSource, config.Generator.CoinbaseFlags()),
+		workState:              newWorkState(),
 		helpCacher:             newHelpCacher(),
+		utxoScanner:            newUtxoScanner(),
 		requestProcessShutdown: make(chan struct{}),
 		quit: make(chan int),
 	}
-	if cfg.RPCUser != "" && cfg.RPCPass != "" {
-		login := cfg.RPCUser + ":" + cfg.RPCPass
-		auth := "Basic " + base64.StdEncoding.EncodeToString([]byte(login))
-		rpc.authsha = sha256.Sum256([]byte(auth))
+
+	// Authenticate the admin user with a generated cookie written to the
+	// data directory when no password is configured for it.
+	var cookiePass string
+	if cfg.RPCPass == "" {
+		var err error
+		cookiePass, err = writeAuthCookie(cfg.RPCCookieFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to write RPC authentication "+
+				"cookie: %v", err)
+		}
+		rpc.authCookie = cfg.RPCCookieFile
+		rpcsLog.Infof("Generated RPC authentication cookie %s",
+			cfg.RPCCookieFile)
+	}
+	auth, err := newRPCAuthenticator(cfg.RPCUser, cfg.RPCPass,
+		cfg.RPCLimitUser, cfg.RPCLimitPass, cookiePass, cfg.RPCAuth,
+		cfg.RPCAllowList)
+	if err != nil {
+		if rpc.authCookie != "" {
+			os.Remove(rpc.authCookie)
+		}
+		return nil, err
+	}
+	rpc.auth = auth
+
+	// Enforce the configured rate limits and record calls in the audit log
+	// when it is enabled.
+	limits, err := parseRPCRateLimits(cfg.RPCRateLimits, auth)
+	if err != nil {
+		if rpc.authCookie != "" {
+			os.Remove(rpc.authCookie)
+		}
+		return nil, err
 	}
-	if cfg.RPCLimitUser != "" && cfg.RPCLimitPass != "" {
-		login := cfg.RPCLimitUser + ":" + cfg.RPCLimitPass
-		auth := "Basic " + base64.StdEncoding.EncodeToString([]byte(login))
-		rpc.limitauthsha = sha256.Sum256([]byte(auth))
+	var auditLog io.WriteCloser
+	if cfg.RPCAuditLog {
+		auditLogFile := filepath.Join(cfg.LogDir,
+			defaultRPCAuditLogFilename)
+		r, err := rotator.New(auditLogFile, 10*1024, false, 3)
+		if err != nil {
+			if rpc.authCookie != "" {
+				os.Remove(rpc.authCookie)
+			}
+			return nil, fmt.Errorf("unable to open RPC audit log: %v",
+				err)
+		}
+		auditLog = r
+		rpcsLog.Infof("Recording RPC calls in %s", auditLogFile)
 	}
+	rpc.calls = newRPCCallTracker(newRPCRateLimiter(limits), auditLog)
 	rpc.ntfnMgr = newWsNotificationManager(&rpc)
+	if len(config.GRPCListeners) > 0 {
+		rpc.grpc = newGRPCServer(&rpc, config.GRPCTLSConfig)
+	}
 	rpc.cfg.Chain.Subscribe(rpc.handleBlockchainNotification)
 
 	return &rpc, nil
@@ -4276,9 +5986,18 @@ func (s *rpcServer) handleBlockchainNotification(notification *blockchain.Notifi
 			break
 		}
 
+		// Discard the work handed out via getwork since it is stale
+		// now.
+		s.workState.NotifyBlockConnected(block.Hash())
+
 		// Notify registered websocket clients of incoming block.
 		s.ntfnMgr.NotifyBlockConnected(block)
 
+		// Notify gRPC subscriptions.
+		if s.grpc != nil {
+			s.grpc.NotifyBlockConnected(block)
+		}
+
 	case blockchain.NTBlockDisconnected:
 		block, ok := notification.Data.(*vtcutil.Block)
 		if !ok {
@@ -4288,6 +6007,11 @@ func (s *rpcServer) handleBlockchainNotification(notification *blockchain.Notifi
 
 		// Notify registered websocket clients.
 		s.ntfnMgr.NotifyBlockDisconnected(block)
+
+		// Notify gRPC subscriptions.
+		if s.grpc != nil {
+			s.grpc.NotifyBlockDisconnected(block)
+		}
 	}
 }
 
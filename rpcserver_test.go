@@ -0,0 +1,381 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vertcoin/vtcd/blockchain"
+	"github.com/vertcoin/vtcd/btcjson"
+	"github.com/vertcoin/vtcd/chaincfg"
+	"github.com/vertcoin/vtcd/chaincfg/chainhash"
+	"github.com/vertcoin/vtcd/mining"
+	"github.com/vertcoin/vtcd/wire"
+)
+
+// newTestGbtWorkState returns a getblocktemplate work state with a template of
+// the passed version and rule change deployments in the passed states.
+func newTestGbtWorkState(version int32, states [chaincfg.DefinedDeployments]blockchain.ThresholdState) *gbtWorkState {
+	coinbase := wire.NewMsgTx(wire.TxVersion)
+	coinbase.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Index: wire.MaxPrevOutIndex},
+		SignatureScript:  []byte{0x51, 0x51},
+	})
+	coinbase.AddTxOut(&wire.TxOut{Value: 5000000000, PkScript: []byte{0x51}})
+	block := &wire.MsgBlock{
+		Header: wire.BlockHeader{
+			Version:   version,
+			Timestamp: time.Unix(time.Now().Unix(), 0),
+		},
+		Transactions: []*wire.MsgTx{coinbase},
+	}
+
+	state := newGbtWorkState(blockchain.NewMedianTime(),
+		mining.CoinbaseFlags)
+	state.template = &mining.BlockTemplate{
+		Block:      block,
+		Fees:       []int64{0},
+		SigOpCosts: []int64{4},
+		Height:     1,
+	}
+	state.prevHash = &block.Header.PrevBlock
+	state.lastGenerated = time.Now()
+	for id, deployment := range chaincfg.RegressionNetParams.Deployments {
+		state.deployments = append(state.deployments, gbtDeployment{
+			DeploymentInfo: chaincfg.DeploymentInfos[id],
+			bit:            deployment.BitNumber,
+			state:          states[id],
+		})
+	}
+	return state
+}
+
+// TestBlockTemplateResultRules ensures block templates follow the BIP0009
+// getblocktemplate semantics for the rules supported by the caller.
+func TestBlockTemplateResultRules(t *testing.T) {
+	t.Parallel()
+
+	// The dummy deployment uses bit 28, csv bit 0 and segwit bit 1.
+	var states [chaincfg.DefinedDeployments]blockchain.ThresholdState
+	states[chaincfg.DeploymentTestDummy] = blockchain.ThresholdLockedIn
+	states[chaincfg.DeploymentCSV] = blockchain.ThresholdActive
+	states[chaincfg.DeploymentSegwit] = blockchain.ThresholdStarted
+	state := newTestGbtWorkState(0x20000002, states)
+
+	// A caller which doesn't support segwit may not signal for it, while
+	// locked in deployments are always signalled.
+	result, err := state.blockTemplateResult(true, nil, nil)
+	if err != nil {
+		t.Fatalf("blockTemplateResult: unexpected error: %v", err)
+	}
+	if result.Version != 0x30000000 {
+		t.Errorf("unexpected version - got %x, want %x", result.Version,
+			0x30000000)
+	}
+	if !reflect.DeepEqual(result.Rules, []string{"csv"}) {
+		t.Errorf("unexpected rules - got %v, want [csv]", result.Rules)
+	}
+	wantAvailable := map[string]int32{"dummy": 28, "segwit": 1}
+	if !reflect.DeepEqual(result.VbAvailable, wantAvailable) {
+		t.Errorf("unexpected vbavailable - got %v, want %v",
+			result.VbAvailable, wantAvailable)
+	}
+	if result.WeightLimit != 0 || result.SigOpLimit !=
+		blockchain.MaxBlockSigOpsCost/blockchain.WitnessScaleFactor ||
+		result.SizeLimit != blockchain.MaxBlockBaseSize {
+
+		t.Errorf("unexpected pre-segwit limits - got weight %d, "+
+			"sigops %d, size %d", result.WeightLimit,
+			result.SigOpLimit, result.SizeLimit)
+	}
+
+	// A caller which supports segwit keeps its bit.
+	request := &btcjson.TemplateRequest{Rules: []string{"segwit"}}
+	result, err = state.blockTemplateResult(true, request, nil)
+	if err != nil {
+		t.Fatalf("blockTemplateResult: unexpected error: %v", err)
+	}
+	if result.Version != 0x30000002 {
+		t.Errorf("unexpected version - got %x, want %x", result.Version,
+			0x30000002)
+	}
+
+	// Pre-BIP0009 callers may force the version.
+	request = &btcjson.TemplateRequest{MaxVersion: 2}
+	result, err = state.blockTemplateResult(true, request, nil)
+	if err != nil {
+		t.Fatalf("blockTemplateResult: unexpected error: %v", err)
+	}
+	mutable := result.Mutable
+	if len(mutable) == 0 || mutable[len(mutable)-1] != "version/force" {
+		t.Errorf("unexpected mutable fields %v", mutable)
+	}
+
+	// Once segwit is active, callers must support it explicitly.
+	states[chaincfg.DeploymentSegwit] = blockchain.ThresholdActive
+	state = newTestGbtWorkState(0x20000000, states)
+	_, err = state.blockTemplateResult(true, nil, nil)
+	if err == nil {
+		t.Fatal("blockTemplateResult: did not receive expected error " +
+			"for caller without segwit support")
+	}
+	request = &btcjson.TemplateRequest{Rules: []string{"segwit"}}
+	result, err = state.blockTemplateResult(true, request, nil)
+	if err != nil {
+		t.Fatalf("blockTemplateResult: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(result.Rules, []string{"csv", "!segwit"}) {
+		t.Errorf("unexpected rules - got %v, want [csv !segwit]",
+			result.Rules)
+	}
+	if result.WeightLimit != blockchain.MaxBlockWeight ||
+		result.SigOpLimit != blockchain.MaxBlockSigOpsCost {
+
+		t.Errorf("unexpected segwit limits - got weight %d, sigops %d",
+			result.WeightLimit, result.SigOpLimit)
+	}
+}
+
+// TestGetWorkByteOrder ensures the data and target returned by getwork are
+// encoded in the byte order legacy miners expect.
+func TestGetWorkByteOrder(t *testing.T) {
+	t.Parallel()
+
+	data := []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}
+	reverseUint32Array(data)
+	want := []byte{0x04, 0x03, 0x02, 0x01, 0x08, 0x07, 0x06, 0x05}
+	if !bytes.Equal(data, want) {
+		t.Errorf("reverseUint32Array: got %x, want %x", data, want)
+	}
+
+	tests := []struct {
+		n    *big.Int
+		want string
+	}{
+		{big.NewInt(0), strings.Repeat("00", uint256Size)},
+		{big.NewInt(0x1234), "3412" + strings.Repeat("00", uint256Size-2)},
+		{
+			new(big.Int).Lsh(big.NewInt(0xff), 256),
+			strings.Repeat("00", uint256Size),
+		},
+	}
+	for _, test := range tests {
+		got := bigToLEUint256(test.n)
+		if hex.EncodeToString(got[:]) != test.want {
+			t.Errorf("bigToLEUint256(%x): got %x, want %s", test.n,
+				got, test.want)
+		}
+	}
+
+	if getworkDataLen != 128 || hash1Len != 64 {
+		t.Errorf("unexpected getwork lengths - got data %d, hash1 %d, "+
+			"want data 128, hash1 64", getworkDataLen, hash1Len)
+	}
+}
+
+// TestWorkStateNotifyBlockConnected ensures the work handed out via getwork is
+// discarded before NotifyBlockConnected returns.
+func TestWorkStateNotifyBlockConnected(t *testing.T) {
+	t.Parallel()
+
+	state := newWorkState()
+	msgBlock := &wire.MsgBlock{}
+	state.msgBlock = msgBlock
+	state.prevHash = &msgBlock.Header.PrevBlock
+	state.extraNonce = 2
+	state.blockInfo[msgBlock.Header.MerkleRoot] = &workStateBlockInfo{
+		msgBlock: msgBlock,
+	}
+
+	state.NotifyBlockConnected(&chainhash.Hash{0x01})
+	if state.msgBlock != nil || state.prevHash != nil ||
+		state.extraNonce != 0 || len(state.blockInfo) != 0 {
+
+		t.Fatalf("NotifyBlockConnected: work not discarded - template "+
+			"%v, previous hash %v, extra nonce %d, %d variations",
+			state.msgBlock, state.prevHash, state.extraNonce,
+			len(state.blockInfo))
+	}
+}
+
+// TestJSONRPCBatch ensures the HTTP POST handler serves single and batched
+// JSON-RPC 1.0 and 2.0 requests, including notifications and malformed or
+// oversized batches.
+func TestJSONRPCBatch(t *testing.T) {
+	// The handler reads its limits from the global configuration, so
+	// install a test configuration for the duration of the test.
+	defer func(oldCfg *config) { cfg = oldCfg }(cfg)
+	cfg = &config{RPCMaxBatchSize: 4, RPCMaxConcurrentReqs: 2}
+
+	s := &rpcServer{
+		statusLines: make(map[int]string),
+		calls:       newRPCCallTracker(newRPCRateLimiter(nil), nil),
+	}
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			s.jsonRPCRead(w, r, &rpcUser{name: "limited"})
+		}))
+	defer server.Close()
+
+	const (
+		version1 = `{"jsonrpc":"1.0","method":"uptime","params":[],"id":1}`
+		version2 = `{"jsonrpc":"2.0","method":"uptime","id":"a"}`
+		notify   = `{"jsonrpc":"2.0","method":"uptime"}`
+		limited  = `{"jsonrpc":"2.0","method":"stop","id":3}`
+	)
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		batch  bool
+		want   []string // expected top-level members of each response
+		codes  []int    // expected error code of each response
+	}{
+		{
+			name:   "1.0 request",
+			body:   version1,
+			status: http.StatusOK,
+			want:   []string{"error,id,result"},
+			codes:  []int{0},
+		},
+		{
+			name:   "2.0 request",
+			body:   version2,
+			status: http.StatusOK,
+			want:   []string{"id,jsonrpc,result"},
+			codes:  []int{0},
+		},
+		{
+			name:   "2.0 notification",
+			body:   notify,
+			status: http.StatusNoContent,
+		},
+		{
+			name:   "malformed request",
+			body:   `{"jsonrpc":`,
+			status: http.StatusOK,
+			want:   []string{"error,id,result"},
+			codes:  []int{-32700},
+		},
+		{
+			name:   "batch with notification, invalid and limited requests",
+			body:   "[" + version2 + "," + notify + ",5," + limited + "]",
+			status: http.StatusOK,
+			batch:  true,
+			want: []string{"id,jsonrpc,result", "error,id,result",
+				"error,id,jsonrpc"},
+			codes: []int{0, -32600, -32602},
+		},
+		{
+			name:   "batch of notifications",
+			body:   "[" + notify + "," + notify + "]",
+			status: http.StatusNoContent,
+		},
+		{
+			name:   "empty batch",
+			body:   " []",
+			status: http.StatusOK,
+			want:   []string{"error,id,result"},
+			codes:  []int{-32600},
+		},
+		{
+			name: "oversized batch",
+			body: "[" + version1 + "," + version1 + "," + version1 +
+				"," + version1 + "," + version1 + "]",
+			status: http.StatusOK,
+			want:   []string{"error,id,result"},
+			codes:  []int{-32600},
+		},
+		{
+			name:   "malformed batch",
+			body:   "[" + version1 + ",",
+			status: http.StatusOK,
+			want:   []string{"error,id,result"},
+			codes:  []int{-32700},
+		},
+	}
+
+	for _, test := range tests {
+		resp, err := http.Post(server.URL, "application/json",
+			strings.NewReader(test.body))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Errorf("%s: unexpected error reading body: %v",
+				test.name, err)
+			continue
+		}
+		if resp.StatusCode != test.status {
+			t.Errorf("%s: unexpected status - got %d, want %d",
+				test.name, resp.StatusCode, test.status)
+			continue
+		}
+		if test.status == http.StatusNoContent {
+			if len(body) != 0 {
+				t.Errorf("%s: unexpected body %q", test.name,
+					body)
+			}
+			continue
+		}
+
+		var replies []map[string]json.RawMessage
+		if test.batch {
+			err = json.Unmarshal(body, &replies)
+		} else {
+			replies = make([]map[string]json.RawMessage, 1)
+			err = json.Unmarshal(body, &replies[0])
+		}
+		if err != nil {
+			t.Errorf("%s: unable to decode reply %q: %v", test.name,
+				body, err)
+			continue
+		}
+		if len(replies) != len(test.want) {
+			t.Errorf("%s: unexpected number of replies - got %d, "+
+				"want %d", test.name, len(replies),
+				len(test.want))
+			continue
+		}
+		for i, reply := range replies {
+			members := make([]string, 0, len(reply))
+			for member := range reply {
+				members = append(members, member)
+			}
+			sort.Strings(members)
+			if got := strings.Join(members, ","); got != test.want[i] {
+				t.Errorf("%s: reply %d has members %s, want %s",
+					test.name, i, got, test.want[i])
+			}
+
+			var rpcErr *btcjson.RPCError
+			if errJSON, ok := reply["error"]; ok {
+				json.Unmarshal(errJSON, &rpcErr)
+			}
+			var code int
+			if rpcErr != nil {
+				code = int(rpcErr.Code)
+			}
+			if code != test.codes[i] {
+				t.Errorf("%s: reply %d has error code %d, want %d",
+					test.name, i, code, test.codes[i])
+			}
+		}
+	}
+}
@@ -34,6 +34,9 @@ var helpDescsEnUS = map[string]string{
 	"addnode-addr":      "IP address and port of the peer to operate on",
 	"addnode-subcmd":    "'add' to add a persistent peer, 'remove' to remove a persistent peer, or 'onetry' to try a single connection to a peer",
 
+	// ClearBannedCmd help.
+	"clearbanned--synopsis": "Removes all subnets from the ban list.",
+
 	// NodeCmd help.
 	"node--synopsis":     "Attempts to add or remove a peer.",
 	"node-subcmd":        "'disconnect' to remove all matching non-persistent peers, 'remove' to remove a persistent peer, or 'connect' to connect to a peer",
@@ -121,6 +124,22 @@ var helpDescsEnUS = map[string]string{
 	"generate-numblocks": "Number of blocks to generate",
 	"generate--result0":  "The hashes, in order, of blocks generated by the call",
 
+	// GenerateBlockCmd help
+	"generateblock--synopsis": "Generates a block (simnet or regtest only) which contains exactly the passed transactions in the passed order.\n" +
+		"The transactions don't need to be in the memory pool.",
+	"generateblock-address":      "The address the coinbase of the block pays to",
+	"generateblock-transactions": "Ids of transactions in the memory pool or hex-encoded raw transactions to include in the block",
+
+	// GenerateBlockResult help.
+	"generateblockresult-hash": "The hash of the generated block",
+
+	// GenerateToAddressCmd help
+	"generatetoaddress--synopsis": "Generates a set number of blocks (simnet or regtest only) which pay to the passed address and returns a JSON\n" +
+		" array of their hashes.",
+	"generatetoaddress-numblocks": "Number of blocks to generate",
+	"generatetoaddress-address":   "The address the coinbase of the blocks pays to",
+	"generatetoaddress--result0":  "The hashes, in order, of blocks generated by the call",
+
 	// GetAddedNodeInfoResultAddr help.
 	"getaddednodeinforesultaddr-address":   "The ip address for this DNS entry",
 	"getaddednodeinforesultaddr-connected": "The connection 'direction' (inbound/outbound/false)",
@@ -153,10 +172,11 @@ var helpDescsEnUS = map[string]string{
 	// GetBlockCmd help.
 	"getblock--synopsis":   "Returns information about a block given its hash.",
 	"getblock-hash":        "The hash of the block",
-	"getblock-verbose":     "Specifies the block is returned as a JSON object instead of hex-encoded string",
-	"getblock-verbosetx":   "Specifies that each transaction is returned as a JSON object and only applies if the verbose flag is true (vtcd extension)",
-	"getblock--condition0": "verbose=false",
-	"getblock--condition1": "verbose=true",
+	"getblock-verbosity":   "0 (or false) for a hex-encoded string, 1 (or true) for a JSON object, and 2 for a JSON object with the decoded transactions, the previous outputs spent by their inputs and their fees",
+	"getblock-verbosetx":   "Specifies that each transaction is returned as a JSON object and only applies if the verbosity is 1 (vtcd extension)",
+	"getblock--condition0": "verbosity=0",
+	"getblock--condition1": "verbosity=1",
+	"getblock--condition2": "verbosity=2",
 	"getblock--result0":    "Hex-encoded bytes of the serialized block",
 
 	// GetBlockChainInfoCmd help.
@@ -234,6 +254,72 @@ var helpDescsEnUS = map[string]string{
 	"getblockverboseresult-strippedsize":      "The size of the block without witness data",
 	"getblockverboseresult-weight":            "The weight of the block",
 
+	// GetBlockVerboseTxResult help.
+	"getblockverbosetxresult-hash":              "The hash of the block (same as provided)",
+	"getblockverbosetxresult-confirmations":     "The number of confirmations",
+	"getblockverbosetxresult-size":              "The size of the block",
+	"getblockverbosetxresult-height":            "The height of the block in the block chain",
+	"getblockverbosetxresult-version":           "The block version",
+	"getblockverbosetxresult-versionHex":        "The block version in hexidecimal",
+	"getblockverbosetxresult-merkleroot":        "Root hash of the merkle tree",
+	"getblockverbosetxresult-tx":                "The transactions as JSON objects",
+	"getblockverbosetxresult-time":              "The block time in seconds since 1 Jan 1970 GMT",
+	"getblockverbosetxresult-nonce":             "The block nonce",
+	"getblockverbosetxresult-bits":              "The bits which represent the block difficulty",
+	"getblockverbosetxresult-difficulty":        "The proof-of-work difficulty as a multiple of the minimum difficulty",
+	"getblockverbosetxresult-previousblockhash": "The hash of the previous block",
+	"getblockverbosetxresult-nextblockhash":     "The hash of the next block (only if there is one)",
+	"getblockverbosetxresult-strippedsize":      "The size of the block without witness data",
+	"getblockverbosetxresult-weight":            "The weight of the block",
+
+	// TxRawPrevOutResult help.
+	"txrawprevoutresult-hex":      "Hex-encoded transaction",
+	"txrawprevoutresult-txid":     "The hash of the transaction",
+	"txrawprevoutresult-hash":     "The wtxid of the transaction",
+	"txrawprevoutresult-size":     "The size of the transaction in bytes",
+	"txrawprevoutresult-vsize":    "The virtual size of the transaction in bytes",
+	"txrawprevoutresult-version":  "The transaction version",
+	"txrawprevoutresult-locktime": "The transaction lock time",
+	"txrawprevoutresult-vin":      "The transaction inputs, along with the previous outputs they spend, as JSON objects",
+	"txrawprevoutresult-vout":     "The transaction outputs as JSON objects",
+	"txrawprevoutresult-fee":      "The transaction fee in BTC (omitted for the coinbase transaction)",
+
+	// GetBlockStatsCmd help.
+	"getblockstats--synopsis":    "Returns statistics about the transactions of a block in the main chain.\nAll amounts are in satoshi and all fee rates are in satoshi per virtual byte.",
+	"getblockstats-hashorheight": "The hash or the height of the block",
+	"getblockstats-stats":        "The names of the statistics to return (default: all of them)",
+
+	// GetBlockStatsResult help.
+	"getblockstatsresult-avgfee":              "The average fee of the transactions",
+	"getblockstatsresult-avgfeerate":          "The average fee rate of the transactions",
+	"getblockstatsresult-avgtxsize":           "The average size of the transactions",
+	"getblockstatsresult-blockhash":           "The hash of the block",
+	"getblockstatsresult-feerate_percentiles": "The fee rates at the 10th, 25th, 50th, 75th and 90th percentiles of the transactions weighted by their weight",
+	"getblockstatsresult-height":              "The height of the block",
+	"getblockstatsresult-ins":                 "The number of inputs of the transactions",
+	"getblockstatsresult-maxfee":              "The maximum fee of the transactions",
+	"getblockstatsresult-maxfeerate":          "The maximum fee rate of the transactions",
+	"getblockstatsresult-maxtxsize":           "The maximum size of the transactions",
+	"getblockstatsresult-medianfee":           "The median fee of the transactions",
+	"getblockstatsresult-mediantime":          "The median time of the block and its 10 ancestors",
+	"getblockstatsresult-mediantxsize":        "The median size of the transactions",
+	"getblockstatsresult-minfee":              "The minimum fee of the transactions",
+	"getblockstatsresult-minfeerate":          "The minimum fee rate of the transactions",
+	"getblockstatsresult-mintxsize":           "The minimum size of the transactions",
+	"getblockstatsresult-outs":                "The number of outputs of all transactions, including the coinbase",
+	"getblockstatsresult-subsidy":             "The block subsidy",
+	"getblockstatsresult-swtotal_size":        "The total size of the segwit transactions",
+	"getblockstatsresult-swtotal_weight":      "The total weight of the segwit transactions",
+	"getblockstatsresult-swtxs":               "The number of segwit transactions",
+	"getblockstatsresult-time":                "The block time in seconds since 1 Jan 1970 GMT",
+	"getblockstatsresult-total_out":           "The total amount of the outputs of the transactions",
+	"getblockstatsresult-total_size":          "The total size of the transactions",
+	"getblockstatsresult-total_weight":        "The total weight of the transactions",
+	"getblockstatsresult-totalfee":            "The total fee of the transactions",
+	"getblockstatsresult-txs":                 "The number of transactions, including the coinbase",
+	"getblockstatsresult-utxo_increase":       "The increase in the number of unspent outputs",
+	"getblockstatsresult-utxo_size_inc":       "The estimated increase in the size of the unspent output set",
+
 	// GetBlockCountCmd help.
 	"getblockcount--synopsis": "Returns the number of blocks in the longest block chain.",
 	"getblockcount--result0":  "The current block count",
@@ -268,6 +354,7 @@ var helpDescsEnUS = map[string]string{
 	// TemplateRequest help.
 	"templaterequest-mode":         "This is 'template', 'proposal', or omitted",
 	"templaterequest-capabilities": "List of capabilities",
+	"templaterequest-rules":        "List of rule change deployments supported by the client",
 	"templaterequest-longpollid":   "The long poll ID of a job to monitor for expiration; required and valid only for long poll requests ",
 	"templaterequest-sigoplimit":   "Number of signature operations allowed in blocks (this parameter is ignored)",
 	"templaterequest-sizelimit":    "Number of bytes allowed in blocks (this parameter is ignored)",
@@ -275,6 +362,18 @@ var helpDescsEnUS = map[string]string{
 	"templaterequest-target":       "The desired target for the block template (this parameter is ignored)",
 	"templaterequest-data":         "Hex-encoded block data (only for mode=proposal)",
 	"templaterequest-workid":       "The server provided workid if provided in block template (not applicable)",
+	"templaterequest-coinbase":     "Coinbase to create the block template with (extension; implies coinbasetxn and does not support long polling)",
+
+	// TemplateRequestCoinbase help.
+	"templaterequestcoinbase-outputs":     "Outputs the coinbase value is paid to",
+	"templaterequestcoinbase-tag":         "Tag to add to the coinbase script instead of the default flags",
+	"templaterequestcoinbase-commitments": "Hex-encoded data to commit to in OP_RETURN outputs of the coinbase",
+
+	// TemplateRequestCoinbaseOutput help.
+	"templaterequestcoinbaseoutput-address": "Address the output pays to",
+	"templaterequestcoinbaseoutput-script":  "Hex-encoded public key script the output pays to when no address is given",
+	"templaterequestcoinbaseoutput-amount":  "Fixed amount in satoshi the output pays when it has no weight",
+	"templaterequestcoinbaseoutput-weight":  "Share of the coinbase value remaining after paying all fixed amounts the output is paid relative to the other weighted outputs",
 
 	// GetBlockTemplateResultTx help.
 	"getblocktemplateresulttx-data":    "Hex-encoded transaction data (byte-for-byte)",
@@ -311,8 +410,14 @@ var helpDescsEnUS = map[string]string{
 	"getblocktemplateresult-noncerange":                 "Two concatenated hex-encoded big-endian 32-bit integers which represent the valid ranges of nonces the miner may scan",
 	"getblocktemplateresult-capabilities":               "List of server capabilities including 'proposal' to indicate support for block proposals",
 	"getblocktemplateresult-reject-reason":              "Reason the proposal was invalid as-is (only applies to proposal responses)",
-	"getblocktemplateresult-default_witness_commitment": "The witness commitment itself. Will be populated if the block has witness data",
-	"getblocktemplateresult-weightlimit":                "The current limit on the max allowed weight of a block",
+	"getblocktemplateresult-default_witness_commitment": "The witness commitment itself. Will be populated once segwit is active",
+	"getblocktemplateresult-weightlimit":                "The current limit on the max allowed weight of a block (only once segwit is active)",
+	"getblocktemplateresult-rules":                      "Active rule change deployments; rules prefixed with '!' require explicit client support",
+	"getblocktemplateresult-vbavailable":                "Rule change deployments being voted on or locked in, mapped to their version bit",
+	"getblocktemplateresult-vbavailable--key":           "rulename",
+	"getblocktemplateresult-vbavailable--value":         "The version bit of the deployment",
+	"getblocktemplateresult-vbavailable--desc":          "Pending rule change deployments",
+	"getblocktemplateresult-vbrequired":                 "Bit mask of versionbits the server requires set in submissions",
 
 	// GetBlockTemplateCmd help.
 	"getblocktemplate--synopsis": "Returns a JSON object with information necessary to construct a block to mine or accepts a proposal to validate.\n" +
@@ -345,8 +450,19 @@ var helpDescsEnUS = map[string]string{
 	"getgenerate--result0":  "True if mining, false if not",
 
 	// GetHashesPerSecCmd help.
-	"gethashespersec--synopsis": "Returns a recent hashes per second performance measurement while generating coins (mining).",
-	"gethashespersec--result0":  "The number of hashes per second",
+	"gethashespersec--synopsis":   "Returns a recent hashes per second performance measurement while generating coins (mining).",
+	"gethashespersec-verbose":     "Specifies the performance of each CPU mining worker is returned along with the total",
+	"gethashespersec--condition0": "verbose=false",
+	"gethashespersec--condition1": "verbose=true",
+	"gethashespersec--result0":    "The number of hashes per second",
+
+	// GetHashesPerSecVerboseResult help.
+	"gethashespersecverboseresult-hashespersec": "The total number of hashes per second",
+	"gethashespersecverboseresult-workers":      "The performance of each CPU mining worker sorted by worker id",
+
+	// WorkerHashesPerSecResult help.
+	"workerhashespersecresult-id":           "The id of the worker, which also selects the part of the extra nonce space it searches",
+	"workerhashespersecresult-hashespersec": "The number of hashes per second the worker recently performed",
 
 	// InfoChainResult help.
 	"infochainresult-version":         "The version of the server",
@@ -407,6 +523,7 @@ var helpDescsEnUS = map[string]string{
 	"getmininginforesult-networkhashps":      "Estimated network hashes per second for the most recent blocks",
 	"getmininginforesult-pooledtx":           "Number of transactions in the memory pool",
 	"getmininginforesult-testnet":            "Whether or not server is using testnet",
+	"getmininginforesult-workerhashespersec": "Recent hashes per second performance measurement of each CPU mining worker",
 
 	// GetMiningInfoCmd help.
 	"getmininginfo--synopsis": "Returns a JSON object containing mining-related information.",
@@ -424,29 +541,60 @@ var helpDescsEnUS = map[string]string{
 	"getnettotalsresult-totalbytesrecv": "Total bytes received",
 	"getnettotalsresult-totalbytessent": "Total bytes sent",
 	"getnettotalsresult-timemillis":     "Number of milliseconds since 1 Jan 1970 GMT",
+	"getnettotalsresult-uploadtarget":   "Upload target statistics",
+
+	// GetNetTotalsUploadTargetResult help.
+	"getnettotalsuploadtargetresult-timeframe":               "Length of the upload target cycle in seconds",
+	"getnettotalsuploadtargetresult-target":                  "Target in bytes per cycle or 0 when there is no target",
+	"getnettotalsuploadtargetresult-target_reached":          "Whether the target has been reached for the current cycle",
+	"getnettotalsuploadtargetresult-serve_historical_blocks": "Whether historical blocks are still served to non-whitelisted peers",
+	"getnettotalsuploadtargetresult-bytes_left_in_cycle":     "Bytes left in the current cycle",
+	"getnettotalsuploadtargetresult-time_left_in_cycle":      "Seconds until the current cycle ends and the budget is reset",
 
 	// GetPeerInfoResult help.
-	"getpeerinforesult-id":             "A unique node ID",
-	"getpeerinforesult-addr":           "The ip address and port of the peer",
-	"getpeerinforesult-addrlocal":      "Local address",
-	"getpeerinforesult-services":       "Services bitmask which represents the services supported by the peer",
-	"getpeerinforesult-relaytxes":      "Peer has requested transactions be relayed to it",
-	"getpeerinforesult-lastsend":       "Time the last message was received in seconds since 1 Jan 1970 GMT",
-	"getpeerinforesult-lastrecv":       "Time the last message was sent in seconds since 1 Jan 1970 GMT",
-	"getpeerinforesult-bytessent":      "Total bytes sent",
-	"getpeerinforesult-bytesrecv":      "Total bytes received",
-	"getpeerinforesult-conntime":       "Time the connection was made in seconds since 1 Jan 1970 GMT",
-	"getpeerinforesult-timeoffset":     "The time offset of the peer",
-	"getpeerinforesult-pingtime":       "Number of microseconds the last ping took",
-	"getpeerinforesult-pingwait":       "Number of microseconds a queued ping has been waiting for a response",
-	"getpeerinforesult-version":        "The protocol version of the peer",
-	"getpeerinforesult-subver":         "The user agent of the peer",
-	"getpeerinforesult-inbound":        "Whether or not the peer is an inbound connection",
-	"getpeerinforesult-startingheight": "The latest block height the peer knew about when the connection was established",
-	"getpeerinforesult-currentheight":  "The current height of the peer",
-	"getpeerinforesult-banscore":       "The ban score",
-	"getpeerinforesult-feefilter":      "The requested minimum fee a transaction must have to be announced to the peer",
-	"getpeerinforesult-syncnode":       "Whether or not the peer is the sync peer",
+	"getpeerinforesult-id":                 "A unique node ID",
+	"getpeerinforesult-addr":               "The ip address and port of the peer",
+	"getpeerinforesult-addrlocal":          "Local address",
+	"getpeerinforesult-services":           "Services bitmask which represents the services supported by the peer",
+	"getpeerinforesult-relaytxes":          "Peer has requested transactions be relayed to it",
+	"getpeerinforesult-lastsend":           "Time the last message was received in seconds since 1 Jan 1970 GMT",
+	"getpeerinforesult-lastrecv":           "Time the last message was sent in seconds since 1 Jan 1970 GMT",
+	"getpeerinforesult-bytessent":          "Total bytes sent",
+	"getpeerinforesult-bytesrecv":          "Total bytes received",
+	"getpeerinforesult-conntime":           "Time the connection was made in seconds since 1 Jan 1970 GMT",
+	"getpeerinforesult-timeoffset":         "The time offset of the peer",
+	"getpeerinforesult-pingtime":           "Number of microseconds the last ping took",
+	"getpeerinforesult-pingwait":           "Number of microseconds a queued ping has been waiting for a response",
+	"getpeerinforesult-version":            "The protocol version of the peer",
+	"getpeerinforesult-subver":             "The user agent of the peer",
+	"getpeerinforesult-inbound":            "Whether or not the peer is an inbound connection",
+	"getpeerinforesult-startingheight":     "The latest block height the peer knew about when the connection was established",
+	"getpeerinforesult-currentheight":      "The current height of the peer",
+	"getpeerinforesult-banscore":           "The ban score",
+	"getpeerinforesult-banscorepersistent": "The persistent part of the ban score",
+	"getpeerinforesult-banscoretransient":  "The decaying part of the ban score",
+	"getpeerinforesult-feefilter":          "The requested minimum fee a transaction must have to be announced to the peer",
+	"getpeerinforesult-syncnode":           "Whether or not the peer is the sync peer",
+
+	// BanScoreEventResult help.
+	"banscoreeventresult-time":        "Time of the event in seconds since 1 Jan 1970 GMT",
+	"banscoreeventresult-misbehavior": "The kind of misbehavior",
+	"banscoreeventresult-reason":      "The reason given for the misbehavior",
+	"banscoreeventresult-persistent":  "The amount the persistent part of the ban score was increased by",
+	"banscoreeventresult-transient":   "The amount the decaying part of the ban score was increased by",
+	"banscoreeventresult-banscore":    "The ban score after the increase",
+
+	// GetPeerBanScoreResult help.
+	"getpeerbanscoreresult-id":         "A unique node ID",
+	"getpeerbanscoreresult-addr":       "The ip address and port of the peer",
+	"getpeerbanscoreresult-banscore":   "The ban score",
+	"getpeerbanscoreresult-persistent": "The persistent part of the ban score",
+	"getpeerbanscoreresult-transient":  "The decaying part of the ban score",
+	"getpeerbanscoreresult-history":    "The most recent events which increased the ban score, oldest first",
+
+	// GetPeerBanScoreCmd help.
+	"getpeerbanscore--synopsis": "Returns the ban score of each connected network peer along with the history of misbehavior which increased it.",
+	"getpeerbanscore-id":        "Only return the ban score of the peer with this node ID",
 
 	// GetPeerInfoCmd help.
 	"getpeerinfo--synopsis": "Returns data about each connected network peer as an array of json objects.",
@@ -454,6 +602,7 @@ var helpDescsEnUS = map[string]string{
 	// GetRawMempoolVerboseResult help.
 	"getrawmempoolverboseresult-size":             "Transaction size in bytes",
 	"getrawmempoolverboseresult-fee":              "Transaction fee in bitcoins",
+	"getrawmempoolverboseresult-modifiedfee":      "Transaction fee including the fee delta set by prioritisetransaction in bitcoins",
 	"getrawmempoolverboseresult-time":             "Local time transaction entered pool in seconds since 1 Jan 1970 GMT",
 	"getrawmempoolverboseresult-height":           "Block height when transaction entered the pool",
 	"getrawmempoolverboseresult-startingpriority": "Priority when transaction entered the pool",
@@ -476,6 +625,18 @@ var helpDescsEnUS = map[string]string{
 	"getrawtransaction--condition1": "verbose=true",
 	"getrawtransaction--result0":    "Hex-encoded bytes of the serialized transaction",
 
+	// RPCActiveCommandResult help.
+	"rpcactivecommandresult-method":   "The name of the method",
+	"rpcactivecommandresult-user":     "The RPC user which invoked the method",
+	"rpcactivecommandresult-duration": "Number of microseconds the command has been running",
+
+	// GetRPCInfoResult help.
+	"getrpcinforesult-active_commands": "The commands being serviced, oldest first",
+	"getrpcinforesult-logpath":         "The path of the debug log file",
+
+	// GetRPCInfoCmd help.
+	"getrpcinfo--synopsis": "Returns the commands being serviced by the RPC server along with how long they have been running.",
+
 	// GetTxOutResult help.
 	"gettxoutresult-bestblock":     "The block hash that contains the transaction output",
 	"gettxoutresult-confirmations": "The number of confirmations",
@@ -490,6 +651,20 @@ var helpDescsEnUS = map[string]string{
 	"gettxout-vout":           "The index of the output",
 	"gettxout-includemempool": "Include the mempool when true",
 
+	// GetWorkResult help.
+	"getworkresult-data":     "Hex-encoded block data",
+	"getworkresult-hash1":    "(DEPRECATED) Hex-encoded formatted hash buffer",
+	"getworkresult-midstate": "(DEPRECATED) Always empty since the proof of work is scrypt",
+	"getworkresult-target":   "Hex-encoded little-endian hash target",
+
+	// GetWorkCmd help.
+	"getwork--synopsis":   "(DEPRECATED - Use getblocktemplate instead) Returns formatted hash data to work on or checks and submits solved data.",
+	"getwork-data":        "Hex-encoded data to submit",
+	"getwork--condition0": "no data provided",
+	"getwork--condition1": "data provided",
+	"getwork--result0":    "Hash data to work on",
+	"getwork--result1":    "Whether or not the solved data is valid and was added to the chain",
+
 	// HelpCmd help.
 	"help--synopsis":   "Returns a list of all commands or help for a specified command.",
 	"help-command":     "The command to retrieve help for",
@@ -498,10 +673,61 @@ var helpDescsEnUS = map[string]string{
 	"help--result0":    "List of commands",
 	"help--result1":    "Help for specified command",
 
+	// ListBannedResult help.
+	"listbannedresult-address":      "The banned IP address or subnet",
+	"listbannedresult-banned_until": "The time the ban expires in seconds since 1 Jan 1970 GMT",
+	"listbannedresult-ban_created":  "The time the ban was created in seconds since 1 Jan 1970 GMT",
+	"listbannedresult-ban_reason":   "The reason the subnet was banned",
+
+	// ListBannedCmd help.
+	"listbanned--synopsis": "Returns all banned IP addresses and subnets.",
+
 	// PingCmd help.
 	"ping--synopsis": "Queues a ping to be sent to each connected peer.\n" +
 		"Ping times are provided by getpeerinfo via the pingtime and pingwait fields.",
 
+	// PrioritiseTransactionCmd help.
+	"prioritisetransaction--synopsis": "Adds a fee delta to the fee used when deciding whether to accept a transaction into the memory pool, which transactions to evict from a full memory pool and which transactions to select for new blocks.\n" +
+		"Deltas accumulate, may be set before the transaction is known, are saved across restarts and are dropped once the transaction is mined.\n" +
+		"At most 10000 deltas are kept.\n" +
+		"The fees collected by a block are unaffected.",
+	"prioritisetransaction-txid":     "The hash of the transaction",
+	"prioritisetransaction-feedelta": "The fee delta in satoshi to add (or subtract when negative)",
+	"prioritisetransaction--result0": "Always true",
+
+	// ScanTxOutSetCmd help.
+	"scantxoutset--synopsis": "Scans the unspent transaction output set for outputs paying to the scripts of the passed output descriptors.\n" +
+		"Supported descriptors are addr(ADDRESS), raw(HEX), pkh(KEY), wpkh(KEY) and sh(wpkh(KEY)), where KEY is a hex-encoded public key, a WIF-encoded private key or an extended key followed by a derivation path.\n" +
+		"Extended keys whose path ends with /* are ranged and derive the keys of the child indices in their range.\n" +
+		"Only one scan runs at a time and it can be aborted or its progress queried from another connection.",
+	"scantxoutset-action":      "'start' to start a scan and wait for its result, 'abort' to abort the scan in progress, or 'status' to get the progress of the scan in progress",
+	"scantxoutset-scanobjects": "The output descriptors to scan for, either as strings or as objects including the range of child indices to derive, at most 1000000 for all descriptors combined (required for 'start')",
+	"scanobject-desc":          "The output descriptor, optionally followed by its checksum",
+	"scanobject-range":         "The first and last child indices to derive for ranged descriptors (default: [0,1000])",
+	"scantxoutset--condition0": "action=start",
+	"scantxoutset--condition1": "action=status",
+	"scantxoutset--condition2": "action=abort",
+	"scantxoutset--result2":    "Whether a scan was in progress and has been aborted",
+
+	// ScanTxOutSetResult help.
+	"scantxoutsetresult-success":      "Whether the scan completed, as opposed to being aborted",
+	"scantxoutsetresult-txouts":       "The number of unspent transaction outputs scanned",
+	"scantxoutsetresult-height":       "The height of the best block when the scan completed",
+	"scantxoutsetresult-bestblock":    "The hash of the best block when the scan completed",
+	"scantxoutsetresult-unspents":     "The unspent transaction outputs found",
+	"scantxoutsetresult-total_amount": "The total amount of the unspent transaction outputs found in BTC",
+
+	// ScanTxOutSetUnspent help.
+	"scantxoutsetunspent-txid":         "The hash of the transaction of the output",
+	"scantxoutsetunspent-vout":         "The index of the output in its transaction",
+	"scantxoutsetunspent-scriptPubKey": "The hex-encoded public key script of the output",
+	"scantxoutsetunspent-desc":         "The output descriptor the output matched, including its checksum",
+	"scantxoutsetunspent-amount":       "The amount of the output in BTC",
+	"scantxoutsetunspent-height":       "The height of the block including the transaction of the output",
+
+	// ScanTxOutSetStatusResult help.
+	"scantxoutsetstatusresult-progress": "The estimated percentage of the unspent transaction output set scanned",
+
 	// SearchRawTransactionsCmd help.
 	"searchrawtransactions--synopsis": "Returns raw data for transactions involving the passed address.\n" +
 		"Returned transactions are pulled from both the database, and transactions currently in the mempool.\n" +
@@ -525,6 +751,13 @@ var helpDescsEnUS = map[string]string{
 	"sendrawtransaction-allowhighfees": "Whether or not to allow insanely high fees (vtcd does not yet implement this parameter, so it has no effect)",
 	"sendrawtransaction--result0":      "The hash of the transaction",
 
+	// SetBanCmd help.
+	"setban--synopsis": "Attempts to add or remove an IP address or subnet from the ban list.",
+	"setban-subnet":    "The IP address or subnet (CIDR notation, e.g. 192.168.0.0/24) to operate on",
+	"setban-subcmd":    "'add' to ban the IP address or subnet and disconnect all matching peers, or 'remove' to remove it from the ban list",
+	"setban-bantime":   "Number of seconds to ban for, or a unix timestamp when absolute is true (0 uses the --banduration setting)",
+	"setban-absolute":  "Whether bantime is an absolute unix timestamp",
+
 	// SetGenerateCmd help.
 	"setgenerate--synopsis":    "Set the server to generate coins (mine) or not.",
 	"setgenerate-generate":     "Use true to enable generation, false to disable it",
@@ -545,6 +778,24 @@ var helpDescsEnUS = map[string]string{
 	"submitblock--condition1": "Block rejected",
 	"submitblock--result1":    "The reason the block was rejected",
 
+	// BlockValidityResult help.
+	"blockvalidityresult-hash":        "The hash of the header or block",
+	"blockvalidityresult-valid":       "Whether or not the header or block is valid",
+	"blockvalidityresult-reason":      "The BIP0022 reason the header or block was rejected for",
+	"blockvalidityresult-ruleerror":   "The name of the rule error code the header or block was rejected with",
+	"blockvalidityresult-description": "The details of the rule violation",
+
+	// SubmitHeaderCmd help.
+	"submitheader--synopsis": "Checks a serialized, hex-encoded block header against the proof of work, difficulty and other header rules and adds it to the known headers when it is valid.\n" +
+		"The previous block must be a known block or a previously submitted header.\n" +
+		"Submitted headers do not affect the best chain until their blocks are submitted.",
+	"submitheader-hexheader": "Serialized, hex-encoded block header",
+
+	// TestBlockValidityCmd help.
+	"testblockvalidity--synopsis": "Checks whether a serialized, hex-encoded block which extends the best block is valid without submitting it.",
+	"testblockvalidity-hexblock":  "Serialized, hex-encoded block",
+	"testblockvalidity-checkpow":  "Whether or not to check the proof of work of the block",
+
 	// ValidateAddressResult help.
 	"validateaddresschainresult-isvalid": "Whether or not the address is valid",
 	"validateaddresschainresult-address": "The bitcoin address (only when isvalid is true)",
@@ -573,7 +824,8 @@ var helpDescsEnUS = map[string]string{
 	// -------- Websocket-specific help --------
 
 	// Session help.
-	"session--synopsis":       "Return details regarding a websocket client's current connection session.",
+	"session--synopsis":       "Return details regarding a websocket client's current connection session, or resume a previous session.",
+	"session-sessionid":       "The ID of a session of a disconnected client to resume -- the requests made by that client are taken over and the notifications it missed are replayed before the reply",
 	"sessionresult-sessionid": "The unique session ID for a client's websocket connection.",
 
 	// NotifyBlocksCmd help.
@@ -659,18 +911,22 @@ var helpDescsEnUS = map[string]string{
 // pointer to the type (or nil to indicate no return value).
 var rpcResultTypes = map[string][]interface{}{
 	"addnode":               nil,
+	"clearbanned":           nil,
 	"createrawtransaction":  {(*string)(nil)},
 	"debuglevel":            {(*string)(nil), (*string)(nil)},
 	"decoderawtransaction":  {(*btcjson.TxRawDecodeResult)(nil)},
 	"decodescript":          {(*btcjson.DecodeScriptResult)(nil)},
 	"generate":              {(*[]string)(nil)},
+	"generateblock":         {(*btcjson.GenerateBlockResult)(nil)},
+	"generatetoaddress":     {(*[]string)(nil)},
 	"getaddednodeinfo":      {(*[]string)(nil), (*[]btcjson.GetAddedNodeInfoResult)(nil)},
 	"getbestblock":          {(*btcjson.GetBestBlockResult)(nil)},
 	"getbestblockhash":      {(*string)(nil)},
-	"getblock":              {(*string)(nil), (*btcjson.GetBlockVerboseResult)(nil)},
+	"getblock":              {(*string)(nil), (*btcjson.GetBlockVerboseResult)(nil), (*btcjson.GetBlockVerboseTxResult)(nil)},
 	"getblockcount":         {(*int64)(nil)},
 	"getblockhash":          {(*string)(nil)},
 	"getblockheader":        {(*string)(nil), (*btcjson.GetBlockHeaderVerboseResult)(nil)},
+	"getblockstats":         {(*btcjson.GetBlockStatsResult)(nil)},
 	"getblocktemplate":      {(*btcjson.GetBlockTemplateResult)(nil), (*string)(nil), nil},
 	"getblockchaininfo":     {(*btcjson.GetBlockChainInfoResult)(nil)},
 	"getcfilter":            {(*string)(nil)},
@@ -678,25 +934,34 @@ var rpcResultTypes = map[string][]interface{}{
 	"getcurrentnet":         {(*uint32)(nil)},
 	"getdifficulty":         {(*float64)(nil)},
 	"getgenerate":           {(*bool)(nil)},
-	"gethashespersec":       {(*float64)(nil)},
+	"gethashespersec":       {(*float64)(nil), (*btcjson.GetHashesPerSecVerboseResult)(nil)},
 	"getheaders":            {(*[]string)(nil)},
 	"getinfo":               {(*btcjson.InfoChainResult)(nil)},
 	"getmempoolinfo":        {(*btcjson.GetMempoolInfoResult)(nil)},
 	"getmininginfo":         {(*btcjson.GetMiningInfoResult)(nil)},
 	"getnettotals":          {(*btcjson.GetNetTotalsResult)(nil)},
 	"getnetworkhashps":      {(*int64)(nil)},
+	"getpeerbanscore":       {(*[]btcjson.GetPeerBanScoreResult)(nil)},
 	"getpeerinfo":           {(*[]btcjson.GetPeerInfoResult)(nil)},
 	"getrawmempool":         {(*[]string)(nil), (*btcjson.GetRawMempoolVerboseResult)(nil)},
 	"getrawtransaction":     {(*string)(nil), (*btcjson.TxRawResult)(nil)},
+	"getrpcinfo":            {(*btcjson.GetRPCInfoResult)(nil)},
 	"gettxout":              {(*btcjson.GetTxOutResult)(nil)},
+	"getwork":               {(*btcjson.GetWorkResult)(nil), (*bool)(nil)},
 	"node":                  nil,
 	"help":                  {(*string)(nil), (*string)(nil)},
+	"listbanned":            {(*[]btcjson.ListBannedResult)(nil)},
 	"ping":                  nil,
+	"prioritisetransaction": {(*bool)(nil)},
+	"scantxoutset":          {(*btcjson.ScanTxOutSetResult)(nil), (*btcjson.ScanTxOutSetStatusResult)(nil), (*bool)(nil)},
 	"searchrawtransactions": {(*string)(nil), (*[]btcjson.SearchRawTransactionsResult)(nil)},
 	"sendrawtransaction":    {(*string)(nil)},
+	"setban":                nil,
 	"setgenerate":           nil,
 	"stop":                  {(*string)(nil)},
 	"submitblock":           {nil, (*string)(nil)},
+	"submitheader":          {(*btcjson.BlockValidityResult)(nil)},
+	"testblockvalidity":     {(*btcjson.BlockValidityResult)(nil)},
 	"uptime":                {(*int64)(nil)},
 	"validateaddress":       {(*btcjson.ValidateAddressChainResult)(nil)},
 	"verifychain":           {(*bool)(nil)},
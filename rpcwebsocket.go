@@ -8,9 +8,6 @@ package main
 import (
 	"bytes"
 	"container/list"
-	"crypto/sha256"
-	"crypto/subtle"
-	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
@@ -83,9 +80,10 @@ var wsHandlersBeforeInit = map[string]wsCommandHandler{
 // starting it, and blocking until the connection closes.  Since it blocks, it
 // must be run in a separate goroutine.  It should be invoked from the websocket
 // server handler which runs each new connection in a new goroutine thereby
-// satisfying the requirement.
+// satisfying the requirement.  The user is nil when the client has not been
+// authenticated yet.
 func (s *rpcServer) WebsocketHandler(conn *websocket.Conn, remoteAddr string,
-	authenticated bool, isAdmin bool) {
+	user *rpcUser) {
 
 	// Clear the read deadline that was set before the websocket hijacked
 	// the connection.
@@ -104,7 +102,7 @@ func (s *rpcServer) WebsocketHandler(conn *websocket.Conn, remoteAddr string,
 	// Create a new websocket client to handle the new websocket connection
 	// and wait for it to shutdown.  Once it has shutdown (and hence
 	// disconnected), remove it and any notifications it registered for.
-	client, err := newWebsocketClient(s, conn, remoteAddr, authenticated, isAdmin)
+	client, err := newWebsocketClient(s, conn, remoteAddr, user)
 	if err != nil {
 		rpcsLog.Errorf("Failed to serve client %s: %v", remoteAddr, err)
 		conn.Close()
@@ -456,6 +454,12 @@ type notificationTxAcceptedByMempool struct {
 // Notification control requests
 type notificationRegisterClient wsClient
 type notificationUnregisterClient wsClient
+type notificationExpireSession wsClient
+type notificationResumeSession struct {
+	wsc       *wsClient
+	sessionID uint64
+	result    chan error
+}
 type notificationRegisterBlocks wsClient
 type notificationUnregisterBlocks wsClient
 type notificationRegisterNewMempoolTxs wsClient
@@ -495,6 +499,24 @@ func (m *wsNotificationManager) notificationHandler() {
 	watchedOutPoints := make(map[wire.OutPoint]map[chan struct{}]*wsClient)
 	watchedAddrs := make(map[string]map[chan struct{}]*wsClient)
 
+	// detached is a map of disconnected websocket clients whose session
+	// may still be resumed.  Their requests are kept, so the notifications
+	// they miss are recorded in their session for replay.
+	detached := make(map[chan struct{}]*wsClient)
+
+	// removeRequests removes any requests made by the passed client.
+	removeRequests := func(wsc *wsClient) {
+		delete(blockNotifications, wsc.quit)
+		delete(txNotifications, wsc.quit)
+		for k := range wsc.spentRequests {
+			op := k
+			m.removeSpentRequest(watchedOutPoints, wsc, &op)
+		}
+		for addr := range wsc.addrRequests {
+			m.removeAddrRequest(watchedAddrs, wsc, addr)
+		}
+	}
+
 out:
 	for {
 		select {
@@ -539,6 +561,7 @@ out:
 				}
 				m.notifyForTx(watchedOutPoints, watchedAddrs, n.tx, nil)
 				m.notifyRelevantTxAccepted(n.tx, clients)
+				m.notifyRelevantTxAccepted(n.tx, detached)
 
 			case *notificationRegisterBlocks:
 				wsc := (*wsClient)(n)
@@ -554,18 +577,77 @@ out:
 
 			case *notificationUnregisterClient:
 				wsc := (*wsClient)(n)
-				// Remove any requests made by the client as well as
-				// the client itself.
-				delete(blockNotifications, wsc.quit)
-				delete(txNotifications, wsc.quit)
-				for k := range wsc.spentRequests {
-					op := k
-					m.removeSpentRequest(watchedOutPoints, wsc, &op)
+				delete(clients, wsc.quit)
+
+				// Keep the requests made by an authenticated client
+				// until its session can no longer be resumed.
+				// Otherwise, remove them right away.
+				if cfg.RPCWSSessionGrace > 0 && wsc.authenticated {
+					detached[wsc.quit] = wsc
+					m.expireSession(wsc, cfg.RPCWSSessionGrace)
+					continue
 				}
-				for addr := range wsc.addrRequests {
-					m.removeAddrRequest(watchedAddrs, wsc, addr)
+				removeRequests(wsc)
+
+			case *notificationExpireSession:
+				wsc := (*wsClient)(n)
+				if _, ok := detached[wsc.quit]; ok {
+					delete(detached, wsc.quit)
+					removeRequests(wsc)
 				}
-				delete(clients, wsc.quit)
+
+			case *notificationResumeSession:
+				// Find the detached client of the session.  Sessions
+				// can only be resumed by the user which started them.
+				var old *wsClient
+				for _, wsc := range detached {
+					if wsc.sessionID() == n.sessionID &&
+						wsc.user.name == n.wsc.user.name {
+
+						old = wsc
+						break
+					}
+				}
+				if old == nil {
+					n.result <- &btcjson.RPCError{
+						Code:    btcjson.ErrRPCInvalidParameter,
+						Message: "Unknown or expired session",
+					}
+					continue
+				}
+				delete(detached, old.quit)
+
+				// The session can't be resumed when notifications the
+				// client missed are no longer buffered.
+				missed, ok := old.session.unsent()
+				if !ok {
+					removeRequests(old)
+					n.result <- &btcjson.RPCError{
+						Code: btcjson.ErrRPCInvalidParameter,
+						Message: "Too many notifications missed " +
+							"to resume session",
+					}
+					continue
+				}
+
+				m.transferRequests(blockNotifications, txNotifications,
+					watchedOutPoints, watchedAddrs, old, n.wsc)
+
+				// Adopt the session and replay the missed
+				// notifications before any notifications which are
+				// handled afterwards.
+				n.wsc.Lock()
+				n.wsc.session = old.session
+				n.wsc.Unlock()
+				for _, ntfn := range missed {
+					if n.wsc.queueRecorded(ntfn) != nil {
+						break
+					}
+				}
+				rpcsLog.Debugf("Websocket client %s resumed session "+
+					"of %s, replaying %d notifications",
+					n.wsc.addr, old.addr, len(missed))
+				n.result <- nil
 
 			case *notificationRegisterSpent:
 				m.addSpentRequests(watchedOutPoints, n.wsc, n.ops)
@@ -703,7 +785,7 @@ func (*wsNotificationManager) notifyBlockConnected(clients map[chan struct{}]*ws
 		return
 	}
 	for _, wsc := range clients {
-		wsc.QueueNotification(marshalledJSON)
+		wsc.queueBlockNotification(marshalledJSON, wsNtfnBlockConnected)
 	}
 }
 
@@ -727,7 +809,8 @@ func (*wsNotificationManager) notifyBlockDisconnected(clients map[chan struct{}]
 		return
 	}
 	for _, wsc := range clients {
-		wsc.QueueNotification(marshalledJSON)
+		wsc.queueBlockNotification(marshalledJSON,
+			wsNtfnBlockDisconnected)
 	}
 }
 
@@ -1161,6 +1244,85 @@ func (*wsNotificationManager) removeAddrRequest(addrs map[string]map[chan struct
 	}
 }
 
+// transferRequests moves the requests made by the websocket client from, which
+// has disconnected, to the websocket client to, which resumes its session.
+func (m *wsNotificationManager) transferRequests(blockNotifications,
+	txNotifications map[chan struct{}]*wsClient,
+	watchedOutPoints map[wire.OutPoint]map[chan struct{}]*wsClient,
+	watchedAddrs map[string]map[chan struct{}]*wsClient, from, to *wsClient) {
+
+	if _, ok := blockNotifications[from.quit]; ok {
+		delete(blockNotifications, from.quit)
+		blockNotifications[to.quit] = to
+	}
+	if _, ok := txNotifications[from.quit]; ok {
+		delete(txNotifications, from.quit)
+		txNotifications[to.quit] = to
+		to.verboseTxUpdates = from.verboseTxUpdates
+	}
+
+	ops := make([]*wire.OutPoint, 0, len(from.spentRequests))
+	for k := range from.spentRequests {
+		op := k
+		m.removeSpentRequest(watchedOutPoints, from, &op)
+		ops = append(ops, &op)
+	}
+	m.addSpentRequests(watchedOutPoints, to, ops)
+
+	addrs := make([]string, 0, len(from.addrRequests))
+	for addr := range from.addrRequests {
+		m.removeAddrRequest(watchedAddrs, from, addr)
+		addrs = append(addrs, addr)
+	}
+	m.addAddrRequests(watchedAddrs, to, addrs)
+
+	// Keep the transaction filter loaded by the new client, if any.
+	from.Lock()
+	filter := from.filterData
+	from.Unlock()
+	to.Lock()
+	if to.filterData == nil {
+		to.filterData = filter
+	}
+	to.Unlock()
+}
+
+// expireSession removes the requests of the passed disconnected websocket
+// client once its session can no longer be resumed after the passed grace
+// period.
+func (m *wsNotificationManager) expireSession(wsc *wsClient, grace time.Duration) {
+	time.AfterFunc(grace, func() {
+		select {
+		case m.queueNotification <- (*notificationExpireSession)(wsc):
+		case <-m.quit:
+		}
+	})
+}
+
+// ResumeSession resumes the session with the passed ID of a websocket client
+// which disconnected within the session grace period for the passed websocket
+// client.  The requests made by the disconnected client are moved to the
+// passed client, and the notifications it missed are replayed.
+func (m *wsNotificationManager) ResumeSession(wsc *wsClient, sessionID uint64) error {
+	result := make(chan error, 1)
+	select {
+	case m.queueNotification <- &notificationResumeSession{
+		wsc:       wsc,
+		sessionID: sessionID,
+		result:    result,
+	}:
+	case <-m.quit:
+		return ErrClientQuit
+	}
+
+	select {
+	case err := <-result:
+		return err
+	case <-m.quit:
+		return ErrClientQuit
+	}
+}
+
 // AddClient adds the passed websocket client to the notification manager.
 func (m *wsNotificationManager) AddClient(wsc *wsClient) {
 	m.queueNotification <- (*notificationRegisterClient)(wsc)
@@ -1249,14 +1411,24 @@ type wsClient struct {
 	// and therefore is allowed to communicated over the websocket.
 	authenticated bool
 
-	// isAdmin specifies whether a client may change the state of the server;
-	// false means its access is only to the limited set of RPC calls.
-	isAdmin bool
-
-	// sessionID is a random ID generated for each client when connected.
-	// These IDs may be queried by a client using the session RPC.  A change
-	// to the session ID indicates that the client reconnected.
-	sessionID uint64
+	// user is the RPC user the client authenticated as, which determines
+	// the RPC calls it may make.
+	user *rpcUser
+
+	// session records the notifications sent to the client under a random
+	// session ID generated for each client when connected.  These IDs may
+	// be queried by a client using the session RPC.  A change to the
+	// session ID indicates that the client reconnected.  A client
+	// reconnecting within the session grace period may pass the ID of its
+	// previous session to the session RPC to resume it instead.
+	session *wsSession
+
+	// ntfnQueueSize is the max number of notifications queued for the
+	// client, and coalesceBlocks specifies whether block notifications are
+	// coalesced rather than disconnecting the client when the queue is
+	// full.
+	ntfnQueueSize  int
+	coalesceBlocks bool
 
 	// verboseTxUpdates specifies whether a client has requested verbose
 	// information about all new transactions.
@@ -1279,7 +1451,7 @@ type wsClient struct {
 
 	// Networking infrastructure.
 	serviceRequestSem semaphore
-	ntfnChan          chan []byte
+	ntfnChan          chan wsNotification
 	sendChan          chan wsResponse
 	quit              chan struct{}
 	wg                sync.WaitGroup
@@ -1319,7 +1491,7 @@ out:
 				Code:    btcjson.ErrRPCParse.Code,
 				Message: "Failed to parse request: " + err.Error(),
 			}
-			reply, err := createMarshalledReply(nil, nil, jsonErr)
+			reply, err := createMarshalledReply("", nil, nil, jsonErr)
 			if err != nil {
 				rpcsLog.Errorf("Failed to marshal parse failure "+
 					"reply: %v", err)
@@ -1360,7 +1532,7 @@ out:
 				break out
 			}
 
-			reply, err := createMarshalledReply(cmd.id, nil, cmd.err)
+			reply, err := createMarshalledReply(cmd.jsonrpc, cmd.id, nil, cmd.err)
 			if err != nil {
 				rpcsLog.Errorf("Failed to marshal parse failure "+
 					"reply: %v", err)
@@ -1387,20 +1559,17 @@ out:
 			break out
 		case !c.authenticated:
 			// Check credentials.
-			login := authCmd.Username + ":" + authCmd.Passphrase
-			auth := "Basic " + base64.StdEncoding.EncodeToString([]byte(login))
-			authSha := sha256.Sum256([]byte(auth))
-			cmp := subtle.ConstantTimeCompare(authSha[:], c.server.authsha[:])
-			limitcmp := subtle.ConstantTimeCompare(authSha[:], c.server.limitauthsha[:])
-			if cmp != 1 && limitcmp != 1 {
+			user := c.server.auth.authenticate(authCmd.Username,
+				authCmd.Passphrase)
+			if user == nil {
 				rpcsLog.Warnf("Auth failure.")
 				break out
 			}
 			c.authenticated = true
-			c.isAdmin = cmp == 1
+			c.user = user
 
 			// Marshal and send response.
-			reply, err := createMarshalledReply(cmd.id, nil, nil)
+			reply, err := createMarshalledReply(cmd.jsonrpc, cmd.id, nil, nil)
 			if err != nil {
 				rpcsLog.Errorf("Failed to marshal authenticate reply: "+
 					"%v", err.Error())
@@ -1410,24 +1579,24 @@ out:
 			continue
 		}
 
-		// Check if the client is using limited RPC credentials and
-		// error when not authorized to call this RPC.
-		if !c.isAdmin {
-			if _, ok := rpcLimited[request.Method]; !ok {
-				jsonErr := &btcjson.RPCError{
-					Code:    btcjson.ErrRPCInvalidParams.Code,
-					Message: "limited user not authorized for this method",
-				}
-				// Marshal and send response.
-				reply, err := createMarshalledReply(request.ID, nil, jsonErr)
-				if err != nil {
-					rpcsLog.Errorf("Failed to marshal parse failure "+
-						"reply: %v", err)
-					continue
-				}
-				c.SendMessage(reply, nil)
+		// Check if the client is using limited RPC credentials, has
+		// an allowlist, or is rate limited and error when not allowed
+		// to call this RPC.
+		call, jsonErr := c.server.calls.begin(c.user, c.addr,
+			rpcTransportWebsocket, request.Method)
+		if jsonErr != nil {
+			c.server.calls.end(call, rpcErrorCode(jsonErr))
+
+			// Marshal and send response.
+			reply, err := createMarshalledReply(request.Jsonrpc, request.ID,
+				nil, jsonErr)
+			if err != nil {
+				rpcsLog.Errorf("Failed to marshal parse failure "+
+					"reply: %v", err)
 				continue
 			}
+			c.SendMessage(reply, nil)
+			continue
 		}
 
 		// Asynchronously handle the request.  A semaphore is used to
@@ -1452,7 +1621,7 @@ out:
 		// many requests to be waited on concurrently.
 		c.serviceRequestSem.acquire()
 		go func() {
-			c.serviceRequest(cmd)
+			c.serviceRequest(cmd, call)
 			c.serviceRequestSem.release()
 		}()
 	}
@@ -1465,8 +1634,8 @@ out:
 
 // serviceRequest services a parsed RPC request by looking up and executing the
 // appropriate RPC handler.  The response is marshalled and sent to the
-// websocket client.
-func (c *wsClient) serviceRequest(r *parsedRPCCmd) {
+// websocket client, and the passed call is finished.
+func (c *wsClient) serviceRequest(r *parsedRPCCmd, call *rpcCall) {
 	var (
 		result interface{}
 		err    error
@@ -1480,7 +1649,8 @@ func (c *wsClient) serviceRequest(r *parsedRPCCmd) {
 	} else {
 		result, err = c.server.standardCmdResult(r, nil)
 	}
-	reply, err := createMarshalledReply(r.id, result, err)
+	c.server.calls.end(call, rpcErrorCode(err))
+	reply, err := createMarshalledReply(r.jsonrpc, r.id, result, err)
 	if err != nil {
 		rpcsLog.Errorf("Failed to marshal reply for <%s> "+
 			"command: %v", r.method, err)
@@ -1507,8 +1677,14 @@ func (c *wsClient) notificationQueueHandler() {
 	// future, not knowing what has and hasn't been sent to the outHandler
 	// (and thus who should respond to the done channel) would be
 	// problematic without using this approach.
+	//
+	// The pending queue is bounded so a client which can't keep up doesn't
+	// make the server run out of memory.  Once it is full, the client is
+	// either disconnected or, when configured, consecutive blockconnected
+	// notifications are coalesced to make room.
 	pendingNtfns := list.New()
 	waiting := false
+	var sending wsNotification
 out:
 	for {
 		select {
@@ -1517,17 +1693,31 @@ out:
 		// message immediately if a send is not already in progress, or
 		// queue the message to be sent once the other pending messages
 		// are sent.
-		case msg := <-c.ntfnChan:
+		case n := <-c.ntfnChan:
 			if !waiting {
-				c.SendMessage(msg, ntfnSentChan)
-			} else {
-				pendingNtfns.PushBack(msg)
+				sending = n
+				c.SendMessage(n.msg, ntfnSentChan)
+				waiting = true
+				continue
 			}
-			waiting = true
+			if pendingNtfns.Len() >= c.ntfnQueueSize &&
+				!(c.coalesceBlocks && coalesceBlockNtfns(pendingNtfns, &n)) {
+
+				rpcsLog.Warnf("Notification queue of websocket "+
+					"client %s is full -- disconnecting",
+					c.addr)
+				c.Disconnect()
+				break out
+			}
+			pendingNtfns.PushBack(n)
 
 		// This channel is notified when a notification has been sent
 		// across the network socket.
-		case <-ntfnSentChan:
+		case sent := <-ntfnSentChan:
+			if sent {
+				sending.session.sent(sending.seq)
+			}
+
 			// No longer waiting if there are no more messages in
 			// the pending messages queue.
 			next := pendingNtfns.Front()
@@ -1538,8 +1728,8 @@ out:
 
 			// Notify the outHandler about the next item to
 			// asynchronously send.
-			msg := pendingNtfns.Remove(next).([]byte)
-			c.SendMessage(msg, ntfnSentChan)
+			sending = pendingNtfns.Remove(next).(wsNotification)
+			c.SendMessage(sending.msg, ntfnSentChan)
 
 		case <-c.quit:
 			break out
@@ -1636,13 +1826,52 @@ var ErrClientQuit = errors.New("client quit")
 // ErrClientQuit.  This is intended to be checked by long-running notification
 // handlers to stop processing if there is no more work needed to be done.
 func (c *wsClient) QueueNotification(marshalledJSON []byte) error {
+	return c.queueNotification(marshalledJSON, wsNtfnOther)
+}
+
+// queueBlockNotification queues the passed blockconnected or blockdisconnected
+// notification, as specified by the passed kind, to be sent to the websocket
+// client.  Unlike other notifications, blockconnected notifications may be
+// coalesced when the client falls behind.
+func (c *wsClient) queueBlockNotification(marshalledJSON []byte, kind wsNtfnKind) error {
+	return c.queueNotification(marshalledJSON, kind)
+}
+
+// queueNotification records the passed notification in the session of the
+// client and queues it to be sent.  Notifications are recorded even when the
+// client is disconnected, so they can be replayed once the session is resumed.
+func (c *wsClient) queueNotification(marshalledJSON []byte, kind wsNtfnKind) error {
+	c.Lock()
+	session := c.session
+	disconnected := c.disconnected
+	c.Unlock()
+
+	n := session.record(marshalledJSON, kind)
+
 	// Don't queue the message if disconnected.
-	if c.Disconnected() {
+	if disconnected {
 		return ErrClientQuit
 	}
+	return c.queueRecorded(n)
+}
 
-	c.ntfnChan <- marshalledJSON
-	return nil
+// queueRecorded queues the passed notification, which has already been
+// recorded in a session, to be sent to the websocket client.
+func (c *wsClient) queueRecorded(n wsNotification) error {
+	select {
+	case c.ntfnChan <- n:
+		return nil
+	case <-c.quit:
+		return ErrClientQuit
+	}
+}
+
+// sessionID returns the ID of the current session of the websocket client.
+func (c *wsClient) sessionID() uint64 {
+	c.Lock()
+	id := c.session.id
+	c.Unlock()
+	return id
 }
 
 // Disconnected returns whether or not the websocket client is disconnected.
@@ -1688,30 +1917,39 @@ func (c *wsClient) WaitForShutdown() {
 }
 
 // newWebsocketClient returns a new websocket client given the notification
-// manager, websocket connection, remote address, and whether or not the client
-// has already been authenticated (via HTTP Basic access authentication).  The
+// manager, websocket connection, remote address, and the user the client has
+// already been authenticated as (via HTTP Basic access authentication), if any.  The
 // returned client is ready to start.  Once started, the client will process
 // incoming and outgoing messages in separate goroutines complete with queuing
 // and asynchrous handling for long-running operations.
 func newWebsocketClient(server *rpcServer, conn *websocket.Conn,
-	remoteAddr string, authenticated bool, isAdmin bool) (*wsClient, error) {
+	remoteAddr string, user *rpcUser) (*wsClient, error) {
 
 	sessionID, err := wire.RandomUint64()
 	if err != nil {
 		return nil, err
 	}
 
+	// Notifications are only kept for replay when sessions may be
+	// resumed.
+	var replaySize int
+	if cfg.RPCWSSessionGrace > 0 {
+		replaySize = cfg.RPCWSQueueSize
+	}
+
 	client := &wsClient{
 		conn:              conn,
 		addr:              remoteAddr,
-		authenticated:     authenticated,
-		isAdmin:           isAdmin,
-		sessionID:         sessionID,
+		authenticated:     user != nil,
+		user:              user,
+		session:           newWSSession(sessionID, replaySize),
+		ntfnQueueSize:     cfg.RPCWSQueueSize,
+		coalesceBlocks:    cfg.RPCWSQueuePolicy == wsQueuePolicyCoalesce,
 		server:            server,
 		addrRequests:      make(map[string]struct{}),
 		spentRequests:     make(map[wire.OutPoint]struct{}),
 		serviceRequestSem: makeSemaphore(cfg.RPCMaxConcurrentReqs),
-		ntfnChan:          make(chan []byte, 1), // nonblocking sync
+		ntfnChan:          make(chan wsNotification, 1), // nonblocking sync
 		sendChan:          make(chan wsResponse, websocketSendBufferSize),
 		quit:              make(chan struct{}),
 	}
@@ -1820,7 +2058,27 @@ func handleNotifyBlocks(wsc *wsClient, icmd interface{}) (interface{}, error) {
 // handleSession implements the session command extension for websocket
 // connections.
 func handleSession(wsc *wsClient, icmd interface{}) (interface{}, error) {
-	return &btcjson.SessionResult{SessionID: wsc.sessionID}, nil
+	cmd, ok := icmd.(*btcjson.SessionCmd)
+	if !ok {
+		return nil, btcjson.ErrRPCInternal
+	}
+
+	// Resume the passed session if requested.
+	if cmd.SessionID != nil {
+		if cfg.RPCWSSessionGrace <= 0 {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCInvalidParameter,
+				Message: "Resuming sessions is disabled",
+			}
+		}
+		if err := wsc.server.ntfnMgr.ResumeSession(wsc,
+			*cmd.SessionID); err != nil {
+
+			return nil, err
+		}
+	}
+
+	return &btcjson.SessionResult{SessionID: wsc.sessionID()}, nil
 }
 
 // handleStopNotifyBlocks implements the stopnotifyblocks command extension for
@@ -2123,6 +2381,50 @@ func rescanBlock(wsc *wsClient, lookups *rescanKeys, blk *vtcutil.Block) {
 	}
 }
 
+// matchTx returns whether the passed transaction spends one of the unspent
+// outpoints of the filter or pays to one of its addresses.  Outputs paying to
+// an address of the filter are added to its unspent outpoints, so later spends
+// of them are matched as well.
+//
+// This function MUST be called with the filter lock held.
+func (f *wsClientFilter) matchTx(tx *vtcutil.Tx, params *chaincfg.Params) bool {
+	msgTx := tx.MsgTx()
+	matched := false
+
+	// Scan inputs if not a coinbase transaction.
+	if !blockchain.IsCoinBaseTx(msgTx) {
+		for _, input := range msgTx.TxIn {
+			if f.existsUnspentOutPoint(&input.PreviousOutPoint) {
+				matched = true
+				break
+			}
+		}
+	}
+
+	// Scan outputs.
+	for i, output := range msgTx.TxOut {
+		_, addrs, _, err := txscript.ExtractPkScriptAddrs(
+			output.PkScript, params)
+		if err != nil {
+			continue
+		}
+		for _, a := range addrs {
+			if !f.existsAddress(a) {
+				continue
+			}
+
+			op := wire.OutPoint{
+				Hash:  *tx.Hash(),
+				Index: uint32(i),
+			}
+			f.addUnspentOutPoint(&op)
+			matched = true
+		}
+	}
+
+	return matched
+}
+
 // rescanBlockFilter rescans a block for any relevant transactions for the
 // passed lookup keys. Any discovered transactions are returned hex encoded as
 // a string slice.
@@ -2133,52 +2435,9 @@ func rescanBlockFilter(filter *wsClientFilter, block *vtcutil.Block, params *cha
 
 	filter.mu.Lock()
 	for _, tx := range block.Transactions() {
-		msgTx := tx.MsgTx()
-
-		// Keep track of whether the transaction has already been added
-		// to the result.  It shouldn't be added twice.
-		added := false
-
-		// Scan inputs if not a coinbase transaction.
-		if !blockchain.IsCoinBaseTx(msgTx) {
-			for _, input := range msgTx.TxIn {
-				if !filter.existsUnspentOutPoint(&input.PreviousOutPoint) {
-					continue
-				}
-				if !added {
-					transactions = append(
-						transactions,
-						txHexString(msgTx))
-					added = true
-				}
-			}
-		}
-
-		// Scan outputs.
-		for i, output := range msgTx.TxOut {
-			_, addrs, _, err := txscript.ExtractPkScriptAddrs(
-				output.PkScript, params)
-			if err != nil {
-				continue
-			}
-			for _, a := range addrs {
-				if !filter.existsAddress(a) {
-					continue
-				}
-
-				op := wire.OutPoint{
-					Hash:  *tx.Hash(),
-					Index: uint32(i),
-				}
-				filter.addUnspentOutPoint(&op)
-
-				if !added {
-					transactions = append(
-						transactions,
-						txHexString(msgTx))
-					added = true
-				}
-			}
+		if filter.matchTx(tx, params) {
+			transactions = append(transactions,
+				txHexString(tx.MsgTx()))
 		}
 	}
 	filter.mu.Unlock()
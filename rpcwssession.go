@@ -0,0 +1,156 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"container/list"
+	"sync"
+)
+
+const (
+	// The policies applied by --rpcwsqueuepolicy when the notification
+	// queue of a websocket client is full.
+	wsQueuePolicyDisconnect = "disconnect"
+	wsQueuePolicyCoalesce   = "coalesce"
+)
+
+// wsNtfnKind identifies the block notifications queued for a websocket client,
+// which need to be told apart when the queue is coalesced.
+type wsNtfnKind uint8
+
+const (
+	wsNtfnOther wsNtfnKind = iota
+	wsNtfnBlockConnected
+	wsNtfnBlockDisconnected
+)
+
+// wsNotification is a marshalled notification queued for a websocket client.
+type wsNotification struct {
+	msg []byte
+
+	// session is the session the notification was recorded in, and seq is
+	// its sequence number within the session.
+	session *wsSession
+	seq     uint64
+
+	// kind specifies whether the notification is a blockconnected or
+	// blockdisconnected notification.  A blockconnected notification only
+	// announces the new best block, so it is superseded by a newer one
+	// unless a block is disconnected in between, and may be coalesced when
+	// the client falls behind.
+	kind wsNtfnKind
+}
+
+// wsSession records the notifications sent to a websocket client under a
+// session ID.  The most recent notifications are kept in a ring buffer so the
+// ones the client missed can be replayed when it resumes the session after
+// reconnecting.
+type wsSession struct {
+	id uint64
+
+	mtx sync.Mutex
+
+	// ntfns is the ring buffer of recorded notifications.  The
+	// notification with sequence number seq is stored at index seq % size
+	// once it has been filled.  Nothing is kept when size is zero.
+	ntfns []wsNotification
+	size  int
+
+	// nextSeq is the sequence number of the next recorded notification,
+	// and sentSeq is the sequence number following the last notification
+	// written to the client.
+	nextSeq uint64
+	sentSeq uint64
+}
+
+// newWSSession returns a new session with the passed ID which keeps the passed
+// number of notifications for replay.
+func newWSSession(id uint64, size int) *wsSession {
+	return &wsSession{id: id, size: size}
+}
+
+// record assigns the next sequence number of the session to the passed
+// notification, stores it in the ring buffer, and returns it.
+//
+// This function is safe for concurrent access.
+func (s *wsSession) record(msg []byte, kind wsNtfnKind) wsNotification {
+	s.mtx.Lock()
+	n := wsNotification{
+		msg:     msg,
+		session: s,
+		seq:     s.nextSeq,
+		kind:    kind,
+	}
+	s.nextSeq++
+	switch {
+	case s.size == 0:
+	case len(s.ntfns) < s.size:
+		s.ntfns = append(s.ntfns, n)
+	default:
+		s.ntfns[n.seq%uint64(s.size)] = n
+	}
+	s.mtx.Unlock()
+	return n
+}
+
+// sent marks the notification with the passed sequence number, and any
+// notification recorded before it, as written to the client.
+//
+// This function is safe for concurrent access.
+func (s *wsSession) sent(seq uint64) {
+	s.mtx.Lock()
+	if seq >= s.sentSeq {
+		s.sentSeq = seq + 1
+	}
+	s.mtx.Unlock()
+}
+
+// unsent returns the recorded notifications which have not been written to the
+// client, oldest first.  It returns false when some of them have already been
+// evicted from the ring buffer and thus can't be replayed.
+//
+// This function is safe for concurrent access.
+func (s *wsSession) unsent() ([]wsNotification, bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.nextSeq-s.sentSeq > uint64(len(s.ntfns)) {
+		return nil, false
+	}
+	ntfns := make([]wsNotification, 0, s.nextSeq-s.sentSeq)
+	for seq := s.sentSeq; seq < s.nextSeq; seq++ {
+		ntfns = append(ntfns, s.ntfns[seq%uint64(s.size)])
+	}
+	return ntfns, true
+}
+
+// coalesceBlockNtfns removes the queued blockconnected notifications superseded
+// by a newer blockconnected notification from the passed queue of pending
+// notifications, treating the passed incoming notification as the newest one.
+// Only consecutive blockconnected notifications are coalesced: those queued
+// before a blockdisconnected notification are kept, as are all
+// blockdisconnected notifications, so the client still learns about each
+// reorganize.  It returns whether any notification was removed.
+func coalesceBlockNtfns(pending *list.List, incoming *wsNotification) bool {
+	superseded := incoming.kind == wsNtfnBlockConnected
+	removed := false
+	var prev *list.Element
+	for e := pending.Back(); e != nil; e = prev {
+		prev = e.Prev()
+		switch e.Value.(wsNotification).kind {
+		case wsNtfnBlockDisconnected:
+			superseded = false
+
+		case wsNtfnBlockConnected:
+			if !superseded {
+				superseded = true
+				continue
+			}
+			pending.Remove(e)
+			removed = true
+		}
+	}
+	return removed
+}
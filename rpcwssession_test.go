@@ -0,0 +1,358 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vertcoin/vtcd/btcjson"
+	"github.com/vertcoin/vtcd/wire"
+	"github.com/vertcoin/vtcutil"
+)
+
+// unsentMsgs returns the messages of the unsent notifications of the passed
+// session, and whether they could all be replayed.
+func unsentMsgs(s *wsSession) ([]string, bool) {
+	ntfns, ok := s.unsent()
+	msgs := make([]string, 0, len(ntfns))
+	for _, n := range ntfns {
+		msgs = append(msgs, string(n.msg))
+	}
+	return msgs, ok
+}
+
+// TestWSSessionReplay ensures sessions keep the most recent notifications in
+// their ring buffer and report the ones which have not been sent.
+func TestWSSessionReplay(t *testing.T) {
+	t.Parallel()
+
+	s := newWSSession(1, 3)
+	if msgs, ok := unsentMsgs(s); !ok || len(msgs) != 0 {
+		t.Fatalf("new session: unexpected unsent notifications %v "+
+			"(ok %v)", msgs, ok)
+	}
+
+	var ntfns []wsNotification
+	for i := 0; i < 3; i++ {
+		n := s.record([]byte(fmt.Sprintf("n%d", i)), wsNtfnOther)
+		if n.seq != uint64(i) || n.session != s {
+			t.Fatalf("record %d: unexpected notification %+v", i, n)
+		}
+		ntfns = append(ntfns, n)
+	}
+	s.sent(ntfns[0].seq)
+	want := []string{"n1", "n2"}
+	if msgs, ok := unsentMsgs(s); !ok || !reflect.DeepEqual(msgs, want) {
+		t.Fatalf("unsent: got %v (ok %v), want %v", msgs, ok, want)
+	}
+
+	// Notifications evicted from the ring buffer after being sent don't
+	// prevent replaying the others.
+	s.record([]byte("n3"), wsNtfnOther)
+	want = []string{"n1", "n2", "n3"}
+	if msgs, ok := unsentMsgs(s); !ok || !reflect.DeepEqual(msgs, want) {
+		t.Fatalf("unsent after wrap: got %v (ok %v), want %v", msgs,
+			ok, want)
+	}
+
+	// Marking an older notification as sent doesn't go back.
+	s.sent(ntfns[2].seq)
+	s.sent(ntfns[1].seq)
+	want = []string{"n3"}
+	if msgs, ok := unsentMsgs(s); !ok || !reflect.DeepEqual(msgs, want) {
+		t.Fatalf("unsent after sent: got %v (ok %v), want %v", msgs,
+			ok, want)
+	}
+
+	// Unsent notifications can't be replayed once evicted.
+	for i := 4; i < 7; i++ {
+		s.record([]byte(fmt.Sprintf("n%d", i)), wsNtfnOther)
+	}
+	if msgs, ok := unsentMsgs(s); ok {
+		t.Fatalf("unsent after eviction: unexpected notifications %v",
+			msgs)
+	}
+
+	// Sessions which aren't resumable don't keep any notifications.
+	s = newWSSession(2, 0)
+	n := s.record([]byte("n0"), wsNtfnOther)
+	s.sent(n.seq)
+	if msgs, ok := unsentMsgs(s); !ok || len(msgs) != 0 {
+		t.Fatalf("all sent: unexpected unsent notifications %v "+
+			"(ok %v)", msgs, ok)
+	}
+	s.record([]byte("n1"), wsNtfnOther)
+	if _, ok := s.unsent(); ok {
+		t.Fatal("unbuffered session: unexpected replayable " +
+			"notifications")
+	}
+}
+
+// TestCoalesceBlockNtfns ensures superseded block notifications are removed
+// from queues of pending notifications.
+func TestCoalesceBlockNtfns(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		pending  []string
+		incoming string
+		removed  bool
+		want     []string
+	}{
+		{
+			name:     "no block notifications",
+			pending:  []string{"tx1", "tx2"},
+			incoming: "block1",
+			removed:  false,
+			want:     []string{"tx1", "tx2"},
+		},
+		{
+			name:     "single block notification kept",
+			pending:  []string{"tx1", "block1", "tx2"},
+			incoming: "tx3",
+			removed:  false,
+			want:     []string{"tx1", "block1", "tx2"},
+		},
+		{
+			name:     "newest block notification kept",
+			pending:  []string{"block1", "tx1", "block2", "tx2"},
+			incoming: "tx3",
+			removed:  true,
+			want:     []string{"tx1", "block2", "tx2"},
+		},
+		{
+			name:     "superseded by incoming",
+			pending:  []string{"block1", "tx1", "block2"},
+			incoming: "block3",
+			removed:  true,
+			want:     []string{"tx1"},
+		},
+		{
+			name:     "disconnect keeps previous connects",
+			pending:  []string{"block1", "block2", "disconnect2"},
+			incoming: "block3",
+			removed:  true,
+			want:     []string{"block2", "disconnect2"},
+		},
+		{
+			name:     "connects after disconnect coalesced",
+			pending:  []string{"block1", "disconnect1", "block2"},
+			incoming: "block3",
+			removed:  true,
+			want:     []string{"block1", "disconnect1"},
+		},
+		{
+			name:     "incoming disconnect",
+			pending:  []string{"block1", "block2"},
+			incoming: "disconnect2",
+			removed:  true,
+			want:     []string{"block2"},
+		},
+	}
+
+	s := newWSSession(1, 0)
+	kind := func(msg string) wsNtfnKind {
+		switch {
+		case strings.HasPrefix(msg, "block"):
+			return wsNtfnBlockConnected
+		case strings.HasPrefix(msg, "disconnect"):
+			return wsNtfnBlockDisconnected
+		}
+		return wsNtfnOther
+	}
+	for _, test := range tests {
+		pending := list.New()
+		for _, msg := range test.pending {
+			pending.PushBack(s.record([]byte(msg), kind(msg)))
+		}
+		incoming := s.record([]byte(test.incoming),
+			kind(test.incoming))
+
+		removed := coalesceBlockNtfns(pending, &incoming)
+		if removed != test.removed {
+			t.Errorf("%s: unexpected removed - got %v, want %v",
+				test.name, removed, test.removed)
+			continue
+		}
+		got := make([]string, 0, pending.Len())
+		for e := pending.Front(); e != nil; e = e.Next() {
+			got = append(got, string(e.Value.(wsNotification).msg))
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: unexpected pending notifications - got "+
+				"%v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+// newDetachedWSClient returns a websocket client of the passed user which has
+// registered for block notifications and for the passed address with the
+// passed notification manager and then disconnected, so its session may be
+// resumed.
+func newDetachedWSClient(t *testing.T, m *wsNotificationManager, user *rpcUser, addr string) *wsClient {
+	wsc, err := newWebsocketClient(nil, nil, "old", user)
+	if err != nil {
+		t.Fatalf("unable to create websocket client: %v", err)
+	}
+	m.RegisterBlockUpdates(wsc)
+	m.RegisterTxOutAddressRequests(wsc, []string{addr})
+	m.RegisterSpentRequests(wsc, []*wire.OutPoint{{Index: 1}})
+
+	wsc.Lock()
+	wsc.disconnected = true
+	wsc.Unlock()
+	m.RemoveClient(wsc)
+	return wsc
+}
+
+// TestWSSessionResume ensures the notification manager moves the requests of
+// a disconnected websocket client to the client resuming its session, replays
+// the notifications it missed in order before newer ones, and refuses to
+// resume sessions which expired, belong to another user, or missed too many
+// notifications.
+func TestWSSessionResume(t *testing.T) {
+	setLogLevels("off")
+	defer func(prev *config) { cfg = prev }(cfg)
+	cfg = &config{
+		RPCWSQueueSize:    10,
+		RPCWSSessionGrace: time.Minute,
+	}
+
+	m := newWsNotificationManager(nil)
+	m.Start()
+	defer func() {
+		m.Shutdown()
+		m.WaitForShutdown()
+	}()
+
+	newBlock := func(height int32) *vtcutil.Block {
+		block := vtcutil.NewBlock(&wire.MsgBlock{
+			Header: wire.BlockHeader{Nonce: uint32(height)},
+		})
+		block.SetHeight(height)
+		return block
+	}
+	user := &rpcUser{name: "user"}
+	const addr = "mpn1yvuRvYaJSRNEyjZH5ZamYQo5oUYrcR"
+
+	// The notifications of the disconnected client are recorded in its
+	// session while it is detached.
+	old := newDetachedWSClient(t, m, user, addr)
+	block1, block2 := newBlock(1), newBlock(2)
+	m.NotifyBlockConnected(block1)
+	m.NotifyBlockConnected(block2)
+	m.NotifyBlockDisconnected(block2)
+
+	// Forward the notifications queued for the resuming client since the
+	// replay blocks until they are read.
+	wsc, err := newWebsocketClient(nil, nil, "new", user)
+	if err != nil {
+		t.Fatalf("unable to create websocket client: %v", err)
+	}
+	ntfns := make(chan wsNotification, 100)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case n := <-wsc.ntfnChan:
+				ntfns <- n
+			case <-done:
+				return
+			}
+		}
+	}()
+	if err := m.ResumeSession(wsc, old.sessionID()); err != nil {
+		t.Fatalf("ResumeSession: unexpected error: %v", err)
+	}
+	if wsc.sessionID() != old.sessionID() {
+		t.Fatalf("ResumeSession: session not adopted - got %d, want %d",
+			wsc.sessionID(), old.sessionID())
+	}
+
+	// The requests of the disconnected client are moved to the resuming
+	// one.
+	if _, ok := wsc.addrRequests[addr]; !ok || len(old.addrRequests) != 0 {
+		t.Fatalf("ResumeSession: address request not moved")
+	}
+	if _, ok := wsc.spentRequests[wire.OutPoint{Index: 1}]; !ok ||
+		len(old.spentRequests) != 0 {
+
+		t.Fatalf("ResumeSession: spent request not moved")
+	}
+
+	// The missed notifications are replayed in order, followed by the
+	// ones for the blocks connected after the session was resumed.
+	m.NotifyBlockConnected(newBlock(3))
+	want := []string{
+		"blockconnected", "filteredblockconnected",
+		"blockconnected", "filteredblockconnected",
+		"blockdisconnected", "filteredblockdisconnected",
+		"blockconnected", "filteredblockconnected",
+	}
+	for i, method := range want {
+		var n wsNotification
+		select {
+		case n = <-ntfns:
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timeout waiting for notification %d", i)
+		}
+		var request btcjson.Request
+		if err := json.Unmarshal(n.msg, &request); err != nil {
+			t.Fatalf("unable to unmarshal notification %d: %v", i,
+				err)
+		}
+		if request.Method != method || n.seq != uint64(i) ||
+			n.session != wsc.session {
+
+			t.Fatalf("unexpected notification %d - got %s (seq "+
+				"%d), want %s (seq %d)", i, request.Method,
+				n.seq, method, i)
+		}
+	}
+
+	// Sessions can't be resumed twice.
+	other, err := newWebsocketClient(nil, nil, "other", user)
+	if err != nil {
+		t.Fatalf("unable to create websocket client: %v", err)
+	}
+	if err := m.ResumeSession(other, old.sessionID()); err == nil {
+		t.Fatal("ResumeSession: resumed session twice")
+	}
+
+	// Sessions can only be resumed by the user which started them.
+	old = newDetachedWSClient(t, m, user, addr)
+	other.user = &rpcUser{name: "other"}
+	if err := m.ResumeSession(other, old.sessionID()); err == nil {
+		t.Fatal("ResumeSession: resumed session of another user")
+	}
+
+	// Sessions can't be resumed once notifications they missed have been
+	// evicted from the ring buffer.
+	cfg.RPCWSQueueSize = 2
+	old = newDetachedWSClient(t, m, user, addr)
+	m.NotifyBlockConnected(newBlock(4))
+	m.NotifyBlockConnected(newBlock(5))
+	other.user = user
+	if err := m.ResumeSession(other, old.sessionID()); err == nil {
+		t.Fatal("ResumeSession: resumed session which missed too " +
+			"many notifications")
+	}
+
+	// Sessions can't be resumed once the grace period expired.
+	cfg.RPCWSSessionGrace = time.Millisecond
+	old = newDetachedWSClient(t, m, user, addr)
+	time.Sleep(100 * time.Millisecond)
+	if err := m.ResumeSession(other, old.sessionID()); err == nil {
+		t.Fatal("ResumeSession: resumed expired session")
+	}
+}
@@ -12,8 +12,12 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"math"
 	"net"
+	"net/http"
+	"os"
+	"path/filepath"
 	"runtime"
 	"sort"
 	"strconv"
@@ -30,11 +34,14 @@ import (
 	"github.com/vertcoin/vtcd/connmgr"
 	"github.com/vertcoin/vtcd/database"
 	"github.com/vertcoin/vtcd/mempool"
+	"github.com/vertcoin/vtcd/metrics"
 	"github.com/vertcoin/vtcd/mining"
 	"github.com/vertcoin/vtcd/mining/cpuminer"
+	"github.com/vertcoin/vtcd/mining/stratum"
 	"github.com/vertcoin/vtcd/peer"
 	"github.com/vertcoin/vtcd/txscript"
 	"github.com/vertcoin/vtcd/wire"
+	"github.com/vertcoin/vtcd/zmq"
 	"github.com/vertcoin/vtcutil"
 	"github.com/vertcoin/vtcutil/bloom"
 )
@@ -56,6 +63,24 @@ const (
 	// retries when connecting to persistent peers.  It is adjusted by the
 	// number of retries such that there is a retry backoff.
 	connectionRetryInterval = time.Second * 5
+
+	// banListFilename is the name of the file in the data directory which
+	// holds the persisted list of banned subnets.
+	banListFilename = "banlist.json"
+
+	// feeDeltasFilename is the name of the file in the data directory which
+	// holds the fee deltas set via the prioritisetransaction RPC.
+	feeDeltasFilename = "feedeltas.json"
+
+	// onionKeyFilename is the name of the file in the data directory which
+	// holds the private key of the onion service created via the Tor
+	// control port.
+	onionKeyFilename = "onion_v3_private_key"
+
+	// onionRetryInterval is the amount of time to wait before retrying to
+	// create the onion service after a failure or after the connection to
+	// the Tor control port was lost.
+	onionRetryInterval = time.Minute
 )
 
 var (
@@ -66,6 +91,12 @@ var (
 	// userAgentVersion is the user agent version and is used to help
 	// identify ourselves to other bitcoin peers.
 	userAgentVersion = fmt.Sprintf("%d.%d.%d", appMajor, appMinor, appPatch)
+
+	// connectedPeers tracks the number of connected peers by the direction
+	// of their connection.
+	connectedPeers = metrics.NewGaugeVec("vtcd_peers",
+		"Number of connected peers by connection direction.",
+		"direction")
 )
 
 // onionAddr implements the net.Addr interface and represents a tor address.
@@ -125,12 +156,11 @@ type updatePeerHeightsMsg struct {
 }
 
 // peerState maintains state of inbound, persistent, outbound peers as well
-// as banned peers and outbound groups.
+// as outbound groups.
 type peerState struct {
 	inboundPeers    map[int32]*serverPeer
 	outboundPeers   map[int32]*serverPeer
 	persistentPeers map[int32]*serverPeer
-	banned          map[string]time.Time
 	outboundGroups  map[string]int
 }
 
@@ -174,6 +204,8 @@ type server struct {
 
 	chainParams          *chaincfg.Params
 	addrManager          *addrmgr.AddrManager
+	banList              *connmgr.BanList
+	uploadTarget         *uploadTarget
 	connManager          *connmgr.ConnManager
 	sigCache             *txscript.SigCache
 	hashCache            *txscript.HashCache
@@ -182,6 +214,9 @@ type server struct {
 	chain                *blockchain.BlockChain
 	txMemPool            *mempool.TxPool
 	cpuMiner             *cpuminer.CPUMiner
+	stratumServer        *stratum.Server
+	zmqPublisher         *zmq.Publisher
+	metricsListeners     []net.Listener
 	modifyRebroadcastInv chan interface{}
 	newPeers             chan *serverPeer
 	donePeers            chan *serverPeer
@@ -193,6 +228,7 @@ type server struct {
 	wg                   sync.WaitGroup
 	quit                 chan struct{}
 	nat                  NAT
+	onionTarget          string
 	db                   database.DB
 	timeSource           blockchain.MedianTimeSource
 	services             wire.ServiceFlag
@@ -223,7 +259,8 @@ type serverPeer struct {
 	sentAddrs      bool
 	filter         *bloom.Filter
 	knownAddresses map[string]struct{}
-	banScore       connmgr.DynamicBanScore
+	banScore       peerBanScore
+	isWhitelisted  bool
 	quit           chan struct{}
 	// The following chans are used to sync blockmanager and server.
 	txProcessed    chan struct{}
@@ -304,37 +341,61 @@ func (sp *serverPeer) pushAddrMsg(addresses []*wire.NetAddress) {
 }
 
 // addBanScore increases the persistent and decaying ban score fields by the
-// values passed as parameters. If the resulting score exceeds half of the ban
-// threshold, a warning is logged including the reason provided. Further, if
-// the score is above the ban threshold, the peer will be banned and
-// disconnected.
-func (sp *serverPeer) addBanScore(persistent, transient uint32, reason string) {
+// weights configured for the passed misbehavior.  If the resulting score
+// exceeds half of the ban threshold, a warning is logged including the reason
+// provided. Further, if the score is above the ban threshold, the peer will be
+// banned and disconnected.
+func (sp *serverPeer) addBanScore(m misbehavior, reason string) {
+	sp.addWeightedBanScore(m, cfg.banWeights[m], reason)
+}
+
+// addWeightedBanScore increases the ban score fields by the passed weight for
+// the misbehavior, banning and disconnecting the peer once it exceeds the ban
+// threshold.
+func (sp *serverPeer) addWeightedBanScore(m misbehavior, w banWeight, reason string) {
+	if sp.increaseBanScore(m, w, reason) {
+		sp.server.BanPeer(sp)
+		sp.Disconnect()
+	}
+}
+
+// increaseBanScore increases the ban score fields by the passed weight for the
+// misbehavior and logs a warning if the resulting score exceeds half of the
+// ban threshold.  It returns whether or not the score is above the ban
+// threshold, in which case the caller is responsible for banning the peer.
+func (sp *serverPeer) increaseBanScore(m misbehavior, w banWeight, reason string) bool {
 	// No warning is logged and no score is calculated if banning is disabled.
 	if cfg.DisableBanning {
-		return
+		return false
+	}
+	if sp.isWhitelisted {
+		peerLog.Debugf("Misbehaving whitelisted peer %s: %s (%s)", sp,
+			reason, m)
+		return false
 	}
 	warnThreshold := cfg.BanThreshold >> 1
-	if transient == 0 && persistent == 0 {
+	if w.Transient == 0 && w.Persistent == 0 {
 		// The score is not being increased, but a warning message is still
 		// logged if the score is above the warn threshold.
 		score := sp.banScore.Int()
 		if score > warnThreshold {
-			peerLog.Warnf("Misbehaving peer %s: %s -- ban score is %d, "+
-				"it was not increased this time", sp, reason, score)
+			peerLog.Warnf("Misbehaving peer %s: %s (%s) -- ban score "+
+				"is %d, it was not increased this time", sp,
+				reason, m, score)
 		}
-		return
+		return false
 	}
-	score := sp.banScore.Increase(persistent, transient)
+	score := sp.banScore.increase(m, w, reason)
 	if score > warnThreshold {
-		peerLog.Warnf("Misbehaving peer %s: %s -- ban score increased to %d",
-			sp, reason, score)
+		peerLog.Warnf("Misbehaving peer %s: %s (%s) -- ban score "+
+			"increased to %d", sp, reason, m, score)
 		if score > cfg.BanThreshold {
 			peerLog.Warnf("Misbehaving peer %s -- banning and disconnecting",
 				sp)
-			sp.server.BanPeer(sp)
-			sp.Disconnect()
+			return true
 		}
 	}
+	return false
 }
 
 // OnVersion is invoked when a peer receives a version bitcoin message
@@ -429,7 +490,7 @@ func (sp *serverPeer) OnMemPool(_ *peer.Peer, msg *wire.MsgMemPool) {
 	// The ban score accumulates and passes the ban threshold if a burst of
 	// mempool messages comes from a peer. The score decays each minute to
 	// half of its value.
-	sp.addBanScore(0, 33, "mempool")
+	sp.addBanScore(misbehaviorMempoolFlood, "mempool")
 
 	// Generate inventory message with the available transactions in the
 	// transaction memory pool.  Limit it to the max allowed inventory
@@ -569,7 +630,9 @@ func (sp *serverPeer) OnGetData(_ *peer.Peer, msg *wire.MsgGetData) {
 	// bursts of small requests are not penalized as that would potentially ban
 	// peers performing IBD.
 	// This incremental score decays each minute to half of its value.
-	sp.addBanScore(0, uint32(length)*99/wire.MaxInvPerMsg, "getdata")
+	w := cfg.banWeights[misbehaviorGetDataFlood]
+	sp.addWeightedBanScore(misbehaviorGetDataFlood,
+		w.scale(uint32(length), wire.MaxInvPerMsg), "getdata")
 
 	// We wait on this wait channel periodically to prevent queuing
 	// far more data than we can send in a reasonable time, wasting memory.
@@ -579,6 +642,16 @@ func (sp *serverPeer) OnGetData(_ *peer.Peer, msg *wire.MsgGetData) {
 	doneChan := make(chan struct{}, 1)
 
 	for i, iv := range msg.InvList {
+		// Stop serving historical blocks to non-whitelisted peers once
+		// the upload target has been reached so the remaining budget is
+		// kept for relaying new blocks.
+		if sp.historicalBlockLimited(iv) {
+			peerLog.Infof("Historical block serving limit reached, "+
+				"disconnecting peer %s", sp)
+			sp.Disconnect()
+			return
+		}
+
 		var c chan struct{}
 		// If this will be the last message we send.
 		if i == length-1 && len(notFound.InvList) == 0 {
@@ -635,6 +708,27 @@ func (sp *serverPeer) OnGetData(_ *peer.Peer, msg *wire.MsgGetData) {
 	}
 }
 
+// historicalBlockLimited returns whether or not the passed inventory vector
+// refers to a historical block which must not be served to the peer because
+// the upload target has been reached.
+func (sp *serverPeer) historicalBlockLimited(iv *wire.InvVect) bool {
+	switch iv.Type {
+	case wire.InvTypeBlock, wire.InvTypeWitnessBlock,
+		wire.InvTypeFilteredBlock, wire.InvTypeFilteredWitnessBlock:
+	default:
+		return false
+	}
+	if sp.isWhitelisted || !sp.server.uploadTarget.HistoricalLimitReached() {
+		return false
+	}
+
+	header, err := sp.server.chain.FetchHeader(&iv.Hash)
+	if err != nil {
+		return false
+	}
+	return time.Since(header.Timestamp) > historicalBlockAge
+}
+
 // OnGetBlocks is invoked when a peer receives a getblocks bitcoin
 // message.
 func (sp *serverPeer) OnGetBlocks(_ *peer.Peer, msg *wire.MsgGetBlocks) {
@@ -860,7 +954,7 @@ func (sp *serverPeer) enforceNodeBloomFlag(cmd string) bool {
 
 			// Disconnect the peer regardless of whether it was
 			// banned.
-			sp.addBanScore(100, 0, cmd)
+			sp.addBanScore(misbehaviorBloomDisabled, cmd)
 			sp.Disconnect()
 			return false
 		}
@@ -885,6 +979,7 @@ func (sp *serverPeer) OnFeeFilter(_ *peer.Peer, msg *wire.MsgFeeFilter) {
 	if msg.MinFee < 0 || msg.MinFee > vtcutil.MaxSatoshi {
 		peerLog.Debugf("Peer %v sent an invalid feefilter '%v' -- "+
 			"disconnecting", sp, vtcutil.Amount(msg.MinFee))
+		sp.addBanScore(misbehaviorBadFeeFilter, msg.Command())
 		sp.Disconnect()
 		return
 	}
@@ -903,9 +998,10 @@ func (sp *serverPeer) OnFilterAdd(_ *peer.Peer, msg *wire.MsgFilterAdd) {
 		return
 	}
 
-	if sp.filter.IsLoaded() {
+	if !sp.filter.IsLoaded() {
 		peerLog.Debugf("%s sent a filteradd request with no filter "+
 			"loaded -- disconnecting", sp)
+		sp.addBanScore(misbehaviorBadFilter, msg.Command())
 		sp.Disconnect()
 		return
 	}
@@ -927,6 +1023,7 @@ func (sp *serverPeer) OnFilterClear(_ *peer.Peer, msg *wire.MsgFilterClear) {
 	if !sp.filter.IsLoaded() {
 		peerLog.Debugf("%s sent a filterclear request with no "+
 			"filter loaded -- disconnecting", sp)
+		sp.addBanScore(misbehaviorBadFilter, msg.Command())
 		sp.Disconnect()
 		return
 	}
@@ -1003,10 +1100,12 @@ func (sp *serverPeer) OnAddr(_ *peer.Peer, msg *wire.MsgAddr) {
 		return
 	}
 
-	// A message that has no addresses is invalid.
+	// A message that has no addresses is invalid.  Messages with more than
+	// the maximum allowed number of addresses are rejected while decoding.
 	if len(msg.AddrList) == 0 {
 		peerLog.Errorf("Command [%s] from %s does not contain any addresses",
 			msg.Command(), sp)
+		sp.addBanScore(misbehaviorInvalidAddr, "empty addr")
 		sp.Disconnect()
 		return
 	}
@@ -1047,6 +1146,7 @@ func (sp *serverPeer) OnRead(_ *peer.Peer, bytesRead int, msg wire.Message, err
 // the bytes sent by the server.
 func (sp *serverPeer) OnWrite(_ *peer.Peer, bytesWritten int, msg wire.Message, err error) {
 	sp.server.AddBytesSent(uint64(bytesWritten))
+	sp.server.uploadTarget.AddBytesSent(uint64(bytesWritten))
 }
 
 // randomUint16Number returns a random uint16 in a specified input range.  Note
@@ -1331,16 +1431,13 @@ func (s *server) handleAddPeerMsg(state *peerState, sp *serverPeer) bool {
 		sp.Disconnect()
 		return false
 	}
-	if banEnd, ok := state.banned[host]; ok {
-		if time.Now().Before(banEnd) {
+	if ip := net.ParseIP(host); ip != nil {
+		if banned, entry := s.banList.IsBanned(ip); banned {
 			srvrLog.Debugf("Peer %s is banned for another %v - disconnecting",
-				host, banEnd.Sub(time.Now()))
+				host, entry.Until.Sub(time.Now()))
 			sp.Disconnect()
 			return false
 		}
-
-		srvrLog.Infof("Peer %s is no longer banned", host)
-		delete(state.banned, host)
 	}
 
 	// TODO: Check for max peers from a single IP.
@@ -1357,6 +1454,7 @@ func (s *server) handleAddPeerMsg(state *peerState, sp *serverPeer) bool {
 
 	// Add the new peer and start it.
 	srvrLog.Debugf("New peer %s", sp)
+	connectedPeers.With(directionString(sp.Inbound())).Add(1)
 	if sp.Inbound() {
 		state.inboundPeers[sp.ID()] = sp
 	} else {
@@ -1390,6 +1488,7 @@ func (s *server) handleDonePeerMsg(state *peerState, sp *serverPeer) {
 			s.connManager.Disconnect(sp.connReq.ID())
 		}
 		delete(list, sp.ID())
+		connectedPeers.With(directionString(sp.Inbound())).Add(-1)
 		srvrLog.Debugf("Removed peer %s", sp)
 		return
 	}
@@ -1416,10 +1515,19 @@ func (s *server) handleBanPeerMsg(state *peerState, sp *serverPeer) {
 		srvrLog.Debugf("can't split ban peer %s %v", sp.Addr(), err)
 		return
 	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		srvrLog.Debugf("can't ban peer %s with non-IP address", sp.Addr())
+		return
+	}
 	direction := directionString(sp.Inbound())
 	srvrLog.Infof("Banned peer %s (%s) for %v", host, direction,
 		cfg.BanDuration)
-	state.banned[host] = time.Now().Add(cfg.BanDuration)
+	s.banList.Ban(connmgr.SingleHostSubnet(ip), time.Now().Add(cfg.BanDuration),
+		connmgr.BanReasonNodeMisbehaving)
+	if err := s.banList.Save(); err != nil {
+		srvrLog.Errorf("Unable to save ban list: %v", err)
+	}
 }
 
 // handleRelayInvMsg deals with relaying inventory to peers that are not already
@@ -1539,6 +1647,19 @@ type removeNodeMsg struct {
 	reply chan error
 }
 
+type banSubnetMsg struct {
+	subnet *net.IPNet
+	until  time.Time
+	reason string
+	reply  chan error
+}
+
+type misbehavingMsg struct {
+	peer        *peer.Peer
+	misbehavior misbehavior
+	reason      string
+}
+
 // handleQuery is the central handler for all queries and commands from other
 // goroutines related to peer state.
 func (s *server) handleQuery(state *peerState, querymsg interface{}) {
@@ -1648,6 +1769,52 @@ func (s *server) handleQuery(state *peerState, querymsg interface{}) {
 		}
 
 		msg.reply <- errors.New("peer not found")
+
+	case banSubnetMsg:
+		s.banList.Ban(msg.subnet, msg.until, msg.reason)
+		srvrLog.Infof("Banned subnet %s until %v (%s)", msg.subnet,
+			msg.until, msg.reason)
+
+		// Disconnect all connected peers which are part of the newly
+		// banned subnet.
+		banned := func(sp *serverPeer) bool {
+			host, _, err := net.SplitHostPort(sp.Addr())
+			if err != nil {
+				return false
+			}
+			ip := net.ParseIP(host)
+			return ip != nil && msg.subnet.Contains(ip)
+		}
+		for disconnectPeer(state.inboundPeers, banned, nil) {
+		}
+		for disconnectPeer(state.outboundPeers, banned, func(sp *serverPeer) {
+			state.outboundGroups[addrmgr.GroupKey(sp.NA())]--
+		}) {
+		}
+		for disconnectPeer(state.persistentPeers, banned, func(sp *serverPeer) {
+			state.outboundGroups[addrmgr.GroupKey(sp.NA())]--
+		}) {
+		}
+
+		msg.reply <- s.banList.Save()
+
+	case misbehavingMsg:
+		found := false
+		state.forAllPeers(func(sp *serverPeer) {
+			if sp.Peer != msg.peer {
+				return
+			}
+			found = true
+			w := cfg.banWeights[msg.misbehavior]
+			if sp.increaseBanScore(msg.misbehavior, w, msg.reason) {
+				s.handleBanPeerMsg(state, sp)
+				sp.Disconnect()
+			}
+		})
+		if !found {
+			peerLog.Debugf("Ignoring misbehavior of removed peer %s: "+
+				"%s (%s)", msg.peer, msg.reason, msg.misbehavior)
+		}
 	}
 }
 
@@ -1715,15 +1882,26 @@ func newPeerConfig(sp *serverPeer) *peer.Config {
 		Services:          sp.server.services,
 		DisableRelayTx:    cfg.BlocksOnly,
 		ProtocolVersion:   peer.MaxProtocolVersion,
+		MaxWriteRate:      sp.maxWriteRate(),
 	}
 }
 
+// maxWriteRate returns the maximum rate in bytes per second at which data is
+// written to the peer.  Whitelisted peers are never limited.
+func (sp *serverPeer) maxWriteRate() uint64 {
+	if sp.isWhitelisted {
+		return 0
+	}
+	return cfg.MaxPeerUploadRate * 1024
+}
+
 // inboundPeerConnected is invoked by the connection manager when a new inbound
 // connection is established.  It initializes a new inbound server peer
 // instance, associates it with the connection, and starts a goroutine to wait
 // for disconnection.
 func (s *server) inboundPeerConnected(conn net.Conn) {
 	sp := newServerPeer(s, false)
+	sp.isWhitelisted = isWhitelisted(conn.RemoteAddr())
 	sp.Peer = peer.NewInboundPeer(newPeerConfig(sp))
 	sp.AssociateConnection(conn)
 	go s.peerDoneHandler(sp)
@@ -1736,6 +1914,7 @@ func (s *server) inboundPeerConnected(conn net.Conn) {
 // manager of the attempt.
 func (s *server) outboundPeerConnected(c *connmgr.ConnReq, conn net.Conn) {
 	sp := newServerPeer(s, c.Permanent)
+	sp.isWhitelisted = isWhitelisted(conn.RemoteAddr())
 	p, err := peer.NewOutboundPeer(newPeerConfig(sp), c.Addr.String())
 	if err != nil {
 		srvrLog.Debugf("Cannot create outbound peer %s: %v", c.Addr, err)
@@ -1787,7 +1966,6 @@ func (s *server) peerHandler() {
 		inboundPeers:    make(map[int32]*serverPeer),
 		persistentPeers: make(map[int32]*serverPeer),
 		outboundPeers:   make(map[int32]*serverPeer),
-		banned:          make(map[string]time.Time),
 		outboundGroups:  make(map[string]int),
 	}
 
@@ -1849,6 +2027,13 @@ out:
 	s.connManager.Stop()
 	s.blockManager.Stop()
 	s.addrManager.Stop()
+	if err := s.banList.Save(); err != nil {
+		srvrLog.Errorf("Unable to save ban list: %v", err)
+	}
+	feeDeltasPath := filepath.Join(cfg.DataDir, feeDeltasFilename)
+	if err := s.txMemPool.SaveFeeDeltas(feeDeltasPath); err != nil {
+		srvrLog.Errorf("Unable to save fee deltas: %v", err)
+	}
 
 	// Drain channels before exiting so nothing is left waiting around
 	// to send.
@@ -1942,6 +2127,19 @@ func (s *server) UpdatePeerHeights(latestBlkHash *chainhash.Hash, latestHeight i
 	}
 }
 
+// Misbehaving increases the ban score of the passed peer by the weight
+// configured for the misbehavior, banning and disconnecting it once the ban
+// threshold is exceeded.  The peer handler has taken the request by the time
+// this function returns, so the score is applied before the removal of a peer
+// which the caller disconnects afterwards is processed.  Callers must not
+// invoke it asynchronously for that reason.
+func (s *server) Misbehaving(p *peer.Peer, m misbehavior, reason string) {
+	select {
+	case s.query <- misbehavingMsg{peer: p, misbehavior: m, reason: reason}:
+	case <-s.quit:
+	}
+}
+
 // rebroadcastHandler keeps track of user submitted inventories that we have
 // sent out but have not yet made it into a block. We periodically rebroadcast
 // them in case our peers restarted or otherwise lost track of them.
@@ -2022,6 +2220,11 @@ func (s *server) Start() {
 		go s.upnpUpdateThread()
 	}
 
+	if cfg.ListenOnion {
+		s.wg.Add(1)
+		go s.onionServiceThread()
+	}
+
 	if !cfg.DisableRPC {
 		s.wg.Add(1)
 
@@ -2036,6 +2239,22 @@ func (s *server) Start() {
 	if cfg.Generate {
 		s.cpuMiner.Start()
 	}
+
+	// Start the Stratum server if it's enabled.
+	if s.stratumServer != nil {
+		s.stratumServer.Start()
+	}
+
+	// Start the ZeroMQ publisher if it's enabled.
+	if s.zmqPublisher != nil {
+		s.zmqPublisher.Start()
+	}
+
+	// Start serving metrics if it's enabled.
+	for _, listener := range s.metricsListeners {
+		s.wg.Add(1)
+		go s.metricsHandler(listener)
+	}
 }
 
 // Stop gracefully shuts down the server by stopping and disconnecting all
@@ -2052,6 +2271,21 @@ func (s *server) Stop() error {
 	// Stop the CPU miner if needed
 	s.cpuMiner.Stop()
 
+	// Stop the Stratum server if it's enabled.
+	if s.stratumServer != nil {
+		s.stratumServer.Stop()
+	}
+
+	// Stop the ZeroMQ publisher if it's enabled.
+	if s.zmqPublisher != nil {
+		s.zmqPublisher.Stop()
+	}
+
+	// Stop serving metrics.
+	for _, listener := range s.metricsListeners {
+		listener.Close()
+	}
+
 	// Shutdown the RPC server if it's not disabled.
 	if !cfg.DisableRPC {
 		s.rpcServer.Stop()
@@ -2062,6 +2296,23 @@ func (s *server) Stop() error {
 	return nil
 }
 
+// metricsHandler serves the metrics of all subsystems in the Prometheus text
+// format below /metrics until the passed listener is closed.
+//
+// This must be run as a goroutine.
+func (s *server) metricsHandler(listener net.Listener) {
+	srvrLog.Infof("Metrics server listening on %s", listener.Addr())
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.DefaultRegistry)
+	httpServer := &http.Server{
+		Handler:     mux,
+		ReadTimeout: time.Second * 10,
+	}
+	httpServer.Serve(listener)
+	srvrLog.Tracef("Metrics listener done for %s", listener.Addr())
+	s.wg.Done()
+}
+
 // WaitForShutdown blocks until the main listener and peer handlers are stopped.
 func (s *server) WaitForShutdown() {
 	s.wg.Wait()
@@ -2157,12 +2408,16 @@ func parseListeners(addrs []string) ([]string, []string, bool, error) {
 	return ipv4ListenAddrs, ipv6ListenAddrs, haveWildcard, nil
 }
 
+// upnpUpdateThread maps the listen port on the NAT gateway and advertises the
+// external address to peers.  The mapping is renewed periodically and removed
+// when the server shuts down.
 func (s *server) upnpUpdateThread() {
 	// Go off immediately to prevent code duplication, thereafter we renew
 	// lease every 15 minutes.
 	timer := time.NewTimer(0 * time.Second)
 	lport, _ := strconv.ParseInt(activeNetParams.DefaultPort, 10, 16)
-	first := true
+	name := s.nat.Name()
+	var boundAddr string
 out:
 	for {
 		select {
@@ -2172,29 +2427,35 @@ out:
 			// TODO: if specific listen port doesn't work then ask for wildcard
 			// listen port?
 			// XXX this assumes timeout is in seconds.
+			timer.Reset(time.Minute * 15)
 			listenPort, err := s.nat.AddPortMapping("tcp", int(lport), int(lport),
 				"vtcd listen port", 20*60)
 			if err != nil {
-				srvrLog.Warnf("can't add UPnP port mapping: %v", err)
+				srvrLog.Warnf("can't add %s port mapping: %v", name, err)
+				continue
 			}
-			if first && err == nil {
-				// TODO: look this up periodically to see if upnp domain changed
-				// and so did ip.
-				externalip, err := s.nat.GetExternalAddress()
-				if err != nil {
-					srvrLog.Warnf("UPnP can't get external address: %v", err)
-					continue out
-				}
-				na := wire.NewNetAddressIPPort(externalip, uint16(listenPort),
-					s.services)
-				err = s.addrManager.AddLocalAddress(na, addrmgr.UpnpPrio)
-				if err != nil {
-					// XXX DeletePortMapping?
-				}
-				srvrLog.Warnf("Successfully bound via UPnP to %s", addrmgr.NetAddressKey(na))
-				first = false
+
+			// Look up the external address on every renewal so a
+			// changed address is advertised as well.
+			externalip, err := s.nat.GetExternalAddress()
+			if err != nil {
+				srvrLog.Warnf("%s can't get external address: %v", name, err)
+				continue
 			}
-			timer.Reset(time.Minute * 15)
+			na := wire.NewNetAddressIPPort(externalip, uint16(listenPort),
+				s.services)
+			addr := addrmgr.NetAddressKey(na)
+			if addr == boundAddr {
+				continue
+			}
+			err = s.addrManager.AddLocalAddress(na, addrmgr.UpnpPrio)
+			if err != nil {
+				srvrLog.Warnf("Unable to add %s external address %s: %v",
+					name, addr, err)
+				continue
+			}
+			srvrLog.Infof("Successfully bound via %s to %s", name, addr)
+			boundAddr = addr
 		case <-s.quit:
 			break out
 		}
@@ -2203,42 +2464,142 @@ out:
 	timer.Stop()
 
 	if err := s.nat.DeletePortMapping("tcp", int(lport), int(lport)); err != nil {
-		srvrLog.Warnf("unable to remove UPnP port mapping: %v", err)
+		srvrLog.Warnf("unable to remove %s port mapping: %v", name, err)
 	} else {
-		srvrLog.Debugf("successfully disestablished UPnP port mapping")
+		srvrLog.Debugf("successfully disestablished %s port mapping", name)
 	}
 
 	s.wg.Done()
 }
 
-// setupRPCListeners returns a slice of listners that are configured for use
-// with the RPC server depending on the configuration settings for listen
-// addresses and TLS.
-func setupRPCListeners() ([]net.Listener, error) {
-	// Setup TLS if not disabled.
-	listenFunc := net.Listen
-	if !cfg.DisableTLS {
-		// Generate the TLS cert and key file if both don't already
-		// exist.
-		if !fileExists(cfg.RPCKey) && !fileExists(cfg.RPCCert) {
-			err := genCertPair(cfg.RPCCert, cfg.RPCKey)
-			if err != nil {
-				return nil, err
+// onionServiceThread publishes the peer listener as a Tor onion service via the
+// Tor control port.  The control connection is kept open until the server shuts
+// down since Tor removes the service once it is closed.  When the connection is
+// lost, such as when Tor restarts, the service is published again.
+func (s *server) onionServiceThread() {
+	defer s.wg.Done()
+
+	for {
+		ctrl, err := s.publishOnionService()
+		if err != nil {
+			srvrLog.Warnf("Unable to create onion service via Tor "+
+				"control port %s: %v", cfg.TorControl, err)
+		} else {
+			// Wait for the control connection to end, which removes
+			// the service, or for the server to shut down.
+			done := make(chan error, 1)
+			go func() {
+				done <- ctrl.Wait()
+			}()
+			select {
+			case err := <-done:
+				ctrl.Close()
+				srvrLog.Warnf("Lost connection to Tor control port "+
+					"%s: %v -- publishing the onion service "+
+					"again", cfg.TorControl, err)
+			case <-s.quit:
+				ctrl.Close()
+				<-done
+				return
 			}
 		}
-		keypair, err := tls.LoadX509KeyPair(cfg.RPCCert, cfg.RPCKey)
+
+		select {
+		case <-time.After(onionRetryInterval):
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// publishOnionService connects and authenticates to the Tor control port and
+// creates an onion service which forwards to the local peer listener.  The
+// private key of the service is kept in the data directory so the onion
+// address stays the same across restarts.
+func (s *server) publishOnionService() (*connmgr.TorController, error) {
+	keyFile := filepath.Join(cfg.DataDir, onionKeyFilename)
+	var privateKey string
+	keyBytes, err := ioutil.ReadFile(keyFile)
+	if err == nil {
+		privateKey = strings.TrimSpace(string(keyBytes))
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	// Forward the default peer port of the onion service to the first
+	// peer listener.
+	target := s.onionTarget
+	virtPort, err := strconv.ParseUint(activeNetParams.DefaultPort, 10, 16)
+	if err != nil {
+		return nil, err
+	}
+
+	ctrl, err := connmgr.DialTorControl(cfg.TorControl)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctrl.Authenticate(cfg.TorPassword); err != nil {
+		ctrl.Close()
+		return nil, err
+	}
+	service, err := ctrl.AddOnion(privateKey, int(virtPort), target)
+	if err != nil {
+		ctrl.Close()
+		return nil, err
+	}
+
+	// Save newly generated keys so the same service is created next time.
+	if service.PrivateKey != "" {
+		err := ioutil.WriteFile(keyFile, []byte(service.PrivateKey+"\n"),
+			0600)
 		if err != nil {
-			return nil, err
+			srvrLog.Errorf("Unable to save onion service key to %s: %v",
+				keyFile, err)
 		}
+	}
+
+	// The service is not added as a local address to advertise to peers.
+	// Only version 2 onion addresses can be encoded as the OnionCat IPv6
+	// addresses the address manager and the addr message support, while
+	// Tor only creates version 3 services.  The address is only usable by
+	// peers which are given it out of band, for example via --connect.
+	host := service.ServiceID + ".onion"
+	srvrLog.Infof("Onion service %s created for listener %s -- it is not "+
+		"advertised to peers since version 3 onion addresses are not "+
+		"supported by the addr message",
+		net.JoinHostPort(host, activeNetParams.DefaultPort), target)
+	return ctrl, nil
+}
 
-		tlsConfig := tls.Config{
-			Certificates: []tls.Certificate{keypair},
-			MinVersion:   tls.VersionTLS12,
+// onionServiceTarget returns the address an onion service forwards to in
+// order to reach the passed peer listener.  Listeners bound to all interfaces
+// are reached via the loopback address of the same address family.
+func onionServiceTarget(addr net.Addr) string {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return addr.String()
+	}
+
+	ip := tcpAddr.IP
+	if ip.IsUnspecified() {
+		ip = net.IPv4(127, 0, 0, 1)
+		if tcpAddr.IP.To4() == nil {
+			ip = net.IPv6loopback
 		}
+	}
+	return net.JoinHostPort(ip.String(), strconv.Itoa(tcpAddr.Port))
+}
 
+// setupRPCListeners returns a slice of listners that are configured for use
+// with the RPC server depending on the configuration settings for listen
+// addresses and TLS.
+func setupRPCListeners(tlsConfig *tls.Config) ([]net.Listener, error) {
+	// Setup TLS if not disabled.
+	listenFunc := net.Listen
+	if tlsConfig != nil {
 		// Change the standard net.Listen function to the tls one.
 		listenFunc = func(net string, laddr string) (net.Listener, error) {
-			return tls.Listen(net, laddr, &tlsConfig)
+			return tls.Listen(net, laddr, tlsConfig)
 		}
 	}
 
@@ -2270,6 +2631,121 @@ func setupRPCListeners() ([]net.Listener, error) {
 	return listeners, nil
 }
 
+// setupRPCTLS returns the TLS configuration shared by the RPC and gRPC
+// servers, generating the TLS cert and key file if both don't already exist.
+// It returns nil when TLS is disabled.
+func setupRPCTLS() (*tls.Config, error) {
+	if cfg.DisableTLS {
+		return nil, nil
+	}
+
+	if !fileExists(cfg.RPCKey) && !fileExists(cfg.RPCCert) {
+		err := genCertPair(cfg.RPCCert, cfg.RPCKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+	keypair, err := tls.LoadX509KeyPair(cfg.RPCCert, cfg.RPCKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{keypair},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// setupGRPCListeners returns a slice of listeners that are configured for use
+// with the gRPC server depending on the configuration settings for listen
+// addresses.  TLS is provided by the gRPC server itself.
+func setupGRPCListeners() ([]net.Listener, error) {
+	ipv4Addrs, ipv6Addrs, _, err := parseListeners(cfg.GRPCListeners)
+	if err != nil {
+		return nil, err
+	}
+	listeners := make([]net.Listener, 0, len(ipv4Addrs)+len(ipv6Addrs))
+	for _, addr := range ipv4Addrs {
+		listener, err := net.Listen("tcp4", addr)
+		if err != nil {
+			rpcsLog.Warnf("Can't listen on %s: %v", addr, err)
+			continue
+		}
+		listeners = append(listeners, listener)
+	}
+
+	for _, addr := range ipv6Addrs {
+		listener, err := net.Listen("tcp6", addr)
+		if err != nil {
+			rpcsLog.Warnf("Can't listen on %s: %v", addr, err)
+			continue
+		}
+		listeners = append(listeners, listener)
+	}
+
+	return listeners, nil
+}
+
+// setupMetricsListeners returns a slice of listeners that are configured for
+// serving metrics depending on the configuration settings for listen
+// addresses.
+func setupMetricsListeners() ([]net.Listener, error) {
+	ipv4Addrs, ipv6Addrs, _, err := parseListeners(cfg.MetricsListeners)
+	if err != nil {
+		return nil, err
+	}
+	listeners := make([]net.Listener, 0, len(ipv4Addrs)+len(ipv6Addrs))
+	for _, addr := range ipv4Addrs {
+		listener, err := net.Listen("tcp4", addr)
+		if err != nil {
+			srvrLog.Warnf("Can't listen on %s: %v", addr, err)
+			continue
+		}
+		listeners = append(listeners, listener)
+	}
+
+	for _, addr := range ipv6Addrs {
+		listener, err := net.Listen("tcp6", addr)
+		if err != nil {
+			srvrLog.Warnf("Can't listen on %s: %v", addr, err)
+			continue
+		}
+		listeners = append(listeners, listener)
+	}
+
+	return listeners, nil
+}
+
+// setupStratumListeners returns a slice of listeners that are configured for
+// use with the Stratum server depending on the configuration settings for
+// listen addresses.
+func setupStratumListeners() ([]net.Listener, error) {
+	ipv4Addrs, ipv6Addrs, _, err := parseListeners(cfg.StratumListeners)
+	if err != nil {
+		return nil, err
+	}
+	listeners := make([]net.Listener, 0, len(ipv4Addrs)+len(ipv6Addrs))
+	for _, addr := range ipv4Addrs {
+		listener, err := net.Listen("tcp4", addr)
+		if err != nil {
+			strmLog.Warnf("Can't listen on %s: %v", addr, err)
+			continue
+		}
+		listeners = append(listeners, listener)
+	}
+
+	for _, addr := range ipv6Addrs {
+		listener, err := net.Listen("tcp6", addr)
+		if err != nil {
+			strmLog.Warnf("Can't listen on %s: %v", addr, err)
+			continue
+		}
+		listeners = append(listeners, listener)
+	}
+
+	return listeners, nil
+}
+
 // newServer returns a new vtcd server configured to listen on addr for the
 // bitcoin network type specified by chainParams.  Use start to begin accepting
 // connections from peers.
@@ -2284,6 +2760,14 @@ func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Param
 
 	amgr := addrmgr.New(cfg.DataDir, vtcdLookup)
 
+	// Restore the subnets which were banned before the last shutdown.  A
+	// corrupt ban list is not fatal and results in an empty list which will
+	// overwrite it on the next save.
+	banList := connmgr.NewBanList(filepath.Join(cfg.DataDir, banListFilename))
+	if err := banList.Load(); err != nil {
+		srvrLog.Errorf("Unable to load ban list: %v", err)
+	}
+
 	var listeners []net.Listener
 	var nat NAT
 	if !cfg.DisableListen {
@@ -2332,11 +2816,12 @@ func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Param
 				}
 			}
 		} else if cfg.Upnp {
-			nat, err = Discover()
+			nat, err = discoverNAT()
 			if err != nil {
-				srvrLog.Warnf("Can't discover upnp: %v", err)
+				srvrLog.Warnf("Can't discover NAT gateway: %v", err)
 			}
-			// nil nat here is fine, just means no upnp on network.
+			// nil nat here is fine, just means no port mapping
+			// capable gateway on network.
 		}
 
 		// TODO: nonstandard port...
@@ -2408,9 +2893,16 @@ func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Param
 		}
 	}
 
+	var onionTarget string
+	if cfg.ListenOnion {
+		onionTarget = onionServiceTarget(listeners[0].Addr())
+	}
+
 	s := server{
 		chainParams:          chainParams,
 		addrManager:          amgr,
+		banList:              banList,
+		uploadTarget:         newUploadTarget(cfg.MaxUploadTarget * 1024 * 1024),
 		newPeers:             make(chan *serverPeer, cfg.MaxPeers),
 		donePeers:            make(chan *serverPeer, cfg.MaxPeers),
 		banPeers:             make(chan *serverPeer, cfg.MaxPeers),
@@ -2421,6 +2913,7 @@ func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Param
 		modifyRebroadcastInv: make(chan interface{}),
 		peerHeightsUpdate:    make(chan updatePeerHeightsMsg),
 		nat:                  nat,
+		onionTarget:          onionTarget,
 		db:                   db,
 		timeSource:           blockchain.NewMedianTime(),
 		services:             services,
@@ -2494,9 +2987,17 @@ func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Param
 			FreeTxRelayLimit:     cfg.FreeTxRelayLimit,
 			MaxOrphanTxs:         cfg.MaxOrphanTxs,
 			MaxOrphanTxSize:      defaultMaxOrphanTxSize,
-			MaxSigOpCostPerTx:    blockchain.MaxBlockSigOpsCost / 4,
 			MinRelayTxFee:        cfg.minRelayTxFee,
+			MaxPoolSize:          int64(cfg.MaxMemPool) * 1000 * 1000,
 			MaxTxVersion:         2,
+			Standard: mempool.StandardPolicy{
+				DataCarrier:        bool(cfg.DataCarrier),
+				MaxDataCarrierSize: cfg.DataCarrierSize,
+				DustRelayFee:       cfg.dustRelayFee,
+				PermitBareMultiSig: !cfg.NoBareMultiSig,
+				MaxTxWeight:        int64(cfg.MaxStdTxWeight),
+				MaxTxSigOpCost:     int(cfg.MaxStdTxSigOpCost),
+			},
 		},
 		ChainParams:    chainParams,
 		FetchUtxoView:  s.chain.FetchUtxoView,
@@ -2512,6 +3013,13 @@ func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Param
 	}
 	s.txMemPool = mempool.New(&txC)
 
+	// Restore the fee deltas which were set before the last shutdown.
+	// Failing to do so is not fatal.
+	feeDeltasPath := filepath.Join(cfg.DataDir, feeDeltasFilename)
+	if err := s.txMemPool.LoadFeeDeltas(feeDeltasPath); err != nil {
+		srvrLog.Errorf("Unable to load fee deltas: %v", err)
+	}
+
 	s.blockManager, err = newBlockManager(&blockManagerConfig{
 		PeerNotifier:       &s,
 		Chain:              s.chain,
@@ -2536,10 +3044,13 @@ func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Param
 		BlockMaxSize:      cfg.BlockMaxSize,
 		BlockPrioritySize: cfg.BlockPrioritySize,
 		TxMinFreeFee:      cfg.minRelayTxFee,
+		CoinbaseFlags:     cfg.CoinbaseTag,
+		CheckTxStandard:   s.txMemPool.CheckTransactionStandard,
 	}
 	blockTemplateGenerator := mining.NewBlkTmplGenerator(&policy,
 		s.chainParams, s.txMemPool, s.chain, s.timeSource,
 		s.sigCache, s.hashCache)
+	templateCache := mining.NewTemplateCache(blockTemplateGenerator)
 	s.cpuMiner = cpuminer.New(&cpuminer.Config{
 		ChainParams:            chainParams,
 		BlockTemplateGenerator: blockTemplateGenerator,
@@ -2549,6 +3060,58 @@ func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Param
 		IsCurrent:              s.blockManager.IsCurrent,
 	})
 
+	// Create the Stratum server which serves jobs from the same block
+	// templates as the CPU miner when enabled.
+	if len(cfg.StratumListeners) > 0 {
+		stratumListeners, err := setupStratumListeners()
+		if err != nil {
+			return nil, err
+		}
+		if len(stratumListeners) == 0 {
+			return nil, errors.New("STRM: No valid listen address")
+		}
+
+		s.stratumServer = stratum.New(&stratum.Config{
+			ChainParams:      chainParams,
+			NewBlockTemplate: templateCache.NewBlockTemplate,
+			LastTxUpdate:     s.txMemPool.LastUpdated,
+			MiningAddrs:      cfg.miningAddrs,
+			ProcessBlock:     s.blockManager.ProcessBlock,
+			IsCurrent:        s.blockManager.IsCurrent,
+			Listeners:        stratumListeners,
+			Password:         cfg.StratumPass,
+			MinDifficulty:    cfg.StratumDifficulty,
+			CoinbaseFlags:    blockTemplateGenerator.CoinbaseFlags(),
+		})
+		s.chain.Subscribe(s.stratumServer.HandleBlockchainNotification)
+	}
+
+	// Create the ZeroMQ publisher which pushes blocks and transactions to
+	// subscribers when enabled.
+	if len(cfg.zmqEndpoints) > 0 {
+		s.zmqPublisher, err = zmq.New(&zmq.Config{
+			Endpoints:     cfg.zmqEndpoints,
+			HighWaterMark: cfg.ZMQPubHWM,
+		})
+		if err != nil {
+			return nil, err
+		}
+		s.chain.Subscribe(s.zmqPublisher.HandleBlockchainNotification)
+		s.txMemPool.Subscribe(s.zmqPublisher.HandleMempoolNotification)
+	}
+
+	// Setup listeners for serving metrics when enabled.
+	if len(cfg.MetricsListeners) > 0 {
+		s.metricsListeners, err = setupMetricsListeners()
+		if err != nil {
+			return nil, err
+		}
+		if len(s.metricsListeners) == 0 {
+			return nil, errors.New("SRVR: No valid metrics listen " +
+				"address")
+		}
+	}
+
 	// Only setup a function to return new addresses to connect to when
 	// not running in connect-only mode.  The simulation network is always
 	// in connect-only mode since it is only intended to connect to
@@ -2575,6 +3138,11 @@ func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Param
 					continue
 				}
 
+				// Never attempt connections to banned addresses.
+				if banned, _ := s.banList.IsBanned(addr.NetAddress().IP); banned {
+					continue
+				}
+
 				// only allow recent nodes (10mins) after we failed 30
 				// times
 				if tries < 30 && time.Since(addr.LastAttempt()) < 10*time.Minute {
@@ -2634,7 +3202,11 @@ func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Param
 	if !cfg.DisableRPC {
 		// Setup listeners for the configured RPC listen addresses and
 		// TLS settings.
-		rpcListeners, err := setupRPCListeners()
+		tlsConfig, err := setupRPCTLS()
+		if err != nil {
+			return nil, err
+		}
+		rpcListeners, err := setupRPCListeners(tlsConfig)
 		if err != nil {
 			return nil, err
 		}
@@ -2642,20 +3214,36 @@ func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Param
 			return nil, errors.New("RPCS: No valid listen address")
 		}
 
+		// Setup listeners for the configured gRPC listen addresses.
+		var grpcListeners []net.Listener
+		if len(cfg.GRPCListeners) > 0 {
+			grpcListeners, err = setupGRPCListeners()
+			if err != nil {
+				return nil, err
+			}
+			if len(grpcListeners) == 0 {
+				return nil, errors.New("RPCS: No valid gRPC " +
+					"listen address")
+			}
+		}
+
 		s.rpcServer, err = newRPCServer(&rpcserverConfig{
-			Listeners:   rpcListeners,
-			StartupTime: s.startupTime,
-			ConnMgr:     &rpcConnManager{&s},
-			SyncMgr:     &rpcSyncMgr{&s, s.blockManager},
-			TimeSource:  s.timeSource,
-			Chain:       s.blockManager.chain,
-			ChainParams: chainParams,
-			DB:          db,
-			TxMemPool:   s.txMemPool,
-			Generator:   blockTemplateGenerator,
-			CPUMiner:    s.cpuMiner,
-			TxIndex:     s.txIndex,
-			AddrIndex:   s.addrIndex,
+			Listeners:     rpcListeners,
+			GRPCListeners: grpcListeners,
+			GRPCTLSConfig: tlsConfig,
+			StartupTime:   s.startupTime,
+			ConnMgr:       &rpcConnManager{&s},
+			SyncMgr:       &rpcSyncMgr{&s, s.blockManager},
+			TimeSource:    s.timeSource,
+			Chain:         s.blockManager.chain,
+			ChainParams:   chainParams,
+			DB:            db,
+			TxMemPool:     s.txMemPool,
+			Generator:     blockTemplateGenerator,
+			CPUMiner:      s.cpuMiner,
+			TemplateCache: templateCache,
+			TxIndex:       s.txIndex,
+			AddrIndex:     s.addrIndex,
 		})
 		if err != nil {
 			return nil, err
@@ -2671,6 +3259,32 @@ func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Param
 	return &s, nil
 }
 
+// isWhitelisted returns whether the IP address is included in the whitelisted
+// networks and IPs.
+func isWhitelisted(addr net.Addr) bool {
+	if len(cfg.whitelists) == 0 {
+		return false
+	}
+
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		srvrLog.Warnf("Unable to SplitHostPort on '%s': %v", addr, err)
+		return false
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		srvrLog.Warnf("Unable to parse IP '%s'", addr)
+		return false
+	}
+
+	for _, ipnet := range cfg.whitelists {
+		if ipnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
 // addrStringToNetAddr takes an address in the form of 'host:port' and returns
 // a net.Addr which maps to the original address with any host names resolved
 // to IP addresses.  It also handles tor addresses properly by returning a
@@ -0,0 +1,51 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net"
+	"testing"
+)
+
+// TestOnionServiceTarget ensures the address an onion service forwards to is
+// derived from the address the peer listener is bound to.
+func TestOnionServiceTarget(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		addr net.Addr
+		want string
+	}{
+		{
+			name: "ipv4 wildcard",
+			addr: &net.TCPAddr{IP: net.IPv4zero, Port: 5889},
+			want: "127.0.0.1:5889",
+		},
+		{
+			name: "ipv6 wildcard",
+			addr: &net.TCPAddr{IP: net.IPv6unspecified, Port: 5889},
+			want: "[::1]:5889",
+		},
+		{
+			name: "ipv4 interface",
+			addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.2"), Port: 15889},
+			want: "10.0.0.2:15889",
+		},
+		{
+			name: "ipv6 loopback",
+			addr: &net.TCPAddr{IP: net.IPv6loopback, Port: 5889},
+			want: "[::1]:5889",
+		},
+	}
+
+	for _, test := range tests {
+		got := onionServiceTarget(test.addr)
+		if got != test.want {
+			t.Errorf("%s: unexpected target - got %s, want %s",
+				test.name, got, test.want)
+		}
+	}
+}
@@ -9,6 +9,16 @@ import (
 
 	"github.com/vertcoin/vtcd/btcec"
 	"github.com/vertcoin/vtcd/chaincfg/chainhash"
+	"github.com/vertcoin/vtcd/metrics"
+)
+
+var (
+	// sigCacheLookups counts the lookups of all signature caches by
+	// whether a matching entry was found, which gives their hit rate.
+	sigCacheLookups = metrics.NewCounterVec("vtcd_sigcache_lookups_total",
+		"Number of signature cache lookups by result.", "result")
+	sigCacheHits   = sigCacheLookups.With("hit")
+	sigCacheMisses = sigCacheLookups.With("miss")
 )
 
 // sigCacheEntry represents an entry in the SigCache. Entries within the
@@ -60,7 +70,12 @@ func (s *SigCache) Exists(sigHash chainhash.Hash, sig *btcec.Signature, pubKey *
 	entry, ok := s.validSigs[sigHash]
 	s.RUnlock()
 
-	return ok && entry.pubKey.IsEqual(pubKey) && entry.sig.IsEqual(sig)
+	if ok && entry.pubKey.IsEqual(pubKey) && entry.sig.IsEqual(sig) {
+		sigCacheHits.Inc()
+		return true
+	}
+	sigCacheMisses.Inc()
+	return false
 }
 
 // Add adds an entry for a signature over 'sigHash' under public key 'pubKey'
@@ -0,0 +1,151 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/vertcoin/vtcd/wire"
+)
+
+const (
+	// uploadTargetTimeframe is the length of a single upload target cycle.
+	uploadTargetTimeframe = time.Hour * 24
+
+	// historicalBlockAge is the age after which a block is considered
+	// historical and is no longer served to non-whitelisted peers once the
+	// upload target has been reached.
+	historicalBlockAge = time.Hour * 24 * 7
+)
+
+// uploadTargetState describes the state of the upload target for the current
+// cycle.
+type uploadTargetState struct {
+	// Target is the maximum number of bytes to send per cycle.  A zero
+	// value means there is no limit.
+	Target uint64
+
+	// Reached indicates the target has been reached for the current cycle.
+	Reached bool
+
+	// ServeHistoricalBlocks indicates whether or not historical blocks are
+	// still served to non-whitelisted peers.
+	ServeHistoricalBlocks bool
+
+	// BytesLeft is the number of bytes remaining in the current cycle.
+	BytesLeft uint64
+
+	// CycleEnd is the time at which the current cycle ends and the budget
+	// is reset.
+	CycleEnd time.Time
+}
+
+// uploadTarget tracks the number of bytes sent to peers during a fixed
+// timeframe against a configured target.  Once the target is close to being
+// reached, historical blocks are no longer served so that the remaining budget
+// is kept for relaying new blocks and transactions.
+type uploadTarget struct {
+	mtx        sync.Mutex
+	target     uint64
+	cycleStart time.Time
+	sent       uint64
+}
+
+// newUploadTarget returns a new upload target which limits the number of bytes
+// sent per cycle to the provided target.  A target of zero disables the limit.
+func newUploadTarget(target uint64) *uploadTarget {
+	return &uploadTarget{target: target}
+}
+
+// maybeResetCycle starts a new cycle when the current one has ended.
+//
+// This function MUST be called with the upload target lock held.
+func (u *uploadTarget) maybeResetCycle(now time.Time) {
+	if u.cycleStart.IsZero() || now.Sub(u.cycleStart) >= uploadTargetTimeframe {
+		u.cycleStart = now
+		u.sent = 0
+	}
+}
+
+// AddBytesSent records the passed number of bytes as sent in the current
+// cycle.
+//
+// This function is safe for concurrent access.
+func (u *uploadTarget) AddBytesSent(n uint64) {
+	u.addBytesSent(n, time.Now())
+}
+
+// addBytesSent records the passed number of bytes as sent at the given time.
+func (u *uploadTarget) addBytesSent(n uint64, now time.Time) {
+	if u.target == 0 {
+		return
+	}
+
+	u.mtx.Lock()
+	u.maybeResetCycle(now)
+	u.sent += n
+	u.mtx.Unlock()
+}
+
+// bytesLeft returns the number of bytes left in the current cycle.
+//
+// This function MUST be called with the upload target lock held.
+func (u *uploadTarget) bytesLeft() uint64 {
+	if u.sent >= u.target {
+		return 0
+	}
+	return u.target - u.sent
+}
+
+// HistoricalLimitReached returns whether or not serving historical blocks must
+// stop for the remainder of the current cycle.  Serving stops once the
+// remaining budget would not fit a maximum sized block.
+//
+// This function is safe for concurrent access.
+func (u *uploadTarget) HistoricalLimitReached() bool {
+	return u.historicalLimitReached(time.Now())
+}
+
+// historicalLimitReached returns whether or not serving historical blocks must
+// stop at the given time.
+func (u *uploadTarget) historicalLimitReached(now time.Time) bool {
+	if u.target == 0 {
+		return false
+	}
+
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+
+	u.maybeResetCycle(now)
+	return u.bytesLeft() < wire.MaxBlockPayload
+}
+
+// State returns the state of the upload target for the current cycle.
+//
+// This function is safe for concurrent access.
+func (u *uploadTarget) State() uploadTargetState {
+	return u.state(time.Now())
+}
+
+// state returns the state of the upload target at the given time.
+func (u *uploadTarget) state(now time.Time) uploadTargetState {
+	if u.target == 0 {
+		return uploadTargetState{ServeHistoricalBlocks: true}
+	}
+
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+
+	u.maybeResetCycle(now)
+	bytesLeft := u.bytesLeft()
+	return uploadTargetState{
+		Target:                u.target,
+		Reached:               bytesLeft == 0,
+		ServeHistoricalBlocks: bytesLeft >= wire.MaxBlockPayload,
+		BytesLeft:             bytesLeft,
+		CycleEnd:              u.cycleStart.Add(uploadTargetTimeframe),
+	}
+}
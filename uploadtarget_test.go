@@ -0,0 +1,71 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vertcoin/vtcd/wire"
+)
+
+// TestUploadTarget ensures the upload target tracks the bytes sent per cycle,
+// stops serving historical blocks once the remaining budget no longer fits a
+// maximum sized block, and resets at the end of each cycle.
+func TestUploadTarget(t *testing.T) {
+	const target = 3 * wire.MaxBlockPayload
+	u := newUploadTarget(target)
+	base := time.Now()
+
+	state := u.state(base)
+	if state.Target != target || state.BytesLeft != target ||
+		state.Reached || !state.ServeHistoricalBlocks {
+		t.Fatalf("unexpected initial state: %+v", state)
+	}
+	if !state.CycleEnd.Equal(base.Add(uploadTargetTimeframe)) {
+		t.Fatalf("unexpected cycle end %v", state.CycleEnd)
+	}
+
+	// Use most of the budget so less than a full block remains.
+	u.addBytesSent(2*wire.MaxBlockPayload+1, base.Add(time.Hour))
+	if !u.historicalLimitReached(base.Add(time.Hour)) {
+		t.Fatalf("historical limit not reached")
+	}
+	state = u.state(base.Add(time.Hour))
+	if state.Reached || state.ServeHistoricalBlocks ||
+		state.BytesLeft != wire.MaxBlockPayload-1 {
+		t.Fatalf("unexpected state after sending: %+v", state)
+	}
+
+	// Exceed the target entirely.
+	u.addBytesSent(wire.MaxBlockPayload, base.Add(2*time.Hour))
+	state = u.state(base.Add(2 * time.Hour))
+	if !state.Reached || state.BytesLeft != 0 {
+		t.Fatalf("unexpected state after exceeding target: %+v", state)
+	}
+
+	// The budget must be restored once the cycle ends.
+	next := base.Add(uploadTargetTimeframe)
+	if u.historicalLimitReached(next) {
+		t.Fatalf("historical limit still reached after cycle reset")
+	}
+	state = u.state(next)
+	if state.BytesLeft != target || state.Reached {
+		t.Fatalf("unexpected state after cycle reset: %+v", state)
+	}
+}
+
+// TestUploadTargetDisabled ensures a zero target never limits serving.
+func TestUploadTargetDisabled(t *testing.T) {
+	u := newUploadTarget(0)
+	u.AddBytesSent(10 * wire.MaxBlockPayload)
+	if u.HistoricalLimitReached() {
+		t.Fatalf("historical limit reached with no target")
+	}
+	state := u.State()
+	if state.Target != 0 || state.Reached || !state.ServeHistoricalBlocks {
+		t.Fatalf("unexpected state with no target: %+v", state)
+	}
+}
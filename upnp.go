@@ -56,6 +56,8 @@ type NAT interface {
 	// Remove a previously added port mapping from external port to
 	// internal port.
 	DeletePortMapping(protocol string, externalPort, internalPort int) (err error)
+	// Name returns the name of the port mapping protocol.
+	Name() string
 }
 
 type upnpNAT struct {
@@ -66,7 +68,12 @@ type upnpNAT struct {
 // Discover searches the local network for a UPnP router returning a NAT
 // for the network if so, nil if not.
 func Discover() (nat NAT, err error) {
-	ssdp, err := net.ResolveUDPAddr("udp4", "239.255.255.250:1900")
+	return discoverUPnP("239.255.255.250:1900")
+}
+
+// discoverUPnP sends an SSDP search for a UPnP router to the given address.
+func discoverUPnP(ssdpAddr string) (nat NAT, err error) {
+	ssdp, err := net.ResolveUDPAddr("udp4", ssdpAddr)
 	if err != nil {
 		return
 	}
@@ -229,7 +236,7 @@ func getServiceURL(rootURL string) (url string, err error) {
 	}
 	defer r.Body.Close()
 	if r.StatusCode >= 400 {
-		err = errors.New(string(r.StatusCode))
+		err = errors.New("Error " + strconv.Itoa(r.StatusCode) + " for " + rootURL)
 		return
 	}
 	var root root
@@ -333,6 +340,11 @@ type getExternalIPAddressResponse struct {
 	ExternalIPAddress string   `xml:"NewExternalIPAddress"`
 }
 
+// Name implements the NAT interface by returning the name of the protocol.
+func (n *upnpNAT) Name() string {
+	return "UPnP"
+}
+
 // GetExternalAddress implements the NAT interface by fetching the external IP
 // from the UPnP router.
 func (n *upnpNAT) GetExternalAddress() (addr net.IP, err error) {
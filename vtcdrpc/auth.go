@@ -0,0 +1,36 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package vtcdrpc
+
+import (
+	"encoding/base64"
+
+	"golang.org/x/net/context"
+)
+
+// BasicAuth implements the PerRPCCredentials interface of the grpc package to
+// authenticate calls with the RPC username and password of the server.
+type BasicAuth struct {
+	Username string
+	Password string
+
+	// DisableTLS allows the credentials to be sent over connections without
+	// transport security, such as to a server on localhost started with
+	// --notls.
+	DisableTLS bool
+}
+
+// GetRequestMetadata returns the authorization metadata sent with each call.
+func (a *BasicAuth) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	login := a.Username + ":" + a.Password
+	auth := "Basic " + base64.StdEncoding.EncodeToString([]byte(login))
+	return map[string]string{"authorization": auth}, nil
+}
+
+// RequireTransportSecurity returns whether the credentials may only be sent
+// over connections with transport security.
+func (a *BasicAuth) RequireTransportSecurity() bool {
+	return !a.DisableTLS
+}
@@ -0,0 +1,30 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package vtcdrpc provides the protocol buffer definitions of the gRPC API served
+by vtcd along with the generated client and server code.
+
+The ChainService mirrors the chain server JSON-RPC commands for looking up
+blocks, headers and transactions, querying the memory pool and submitting
+transactions, and adds server-streaming subscriptions for connected and
+disconnected blocks and for transactions relevant to a set of addresses and
+outpoints.
+
+Calls are authenticated with the same credentials as the JSON-RPC server, which
+are passed as HTTP basic authentication in the authorization metadata of each
+call.  BasicAuth provides them as per-RPC credentials:
+
+	creds := &vtcdrpc.BasicAuth{Username: "user", Password: "pass"}
+	conn, err := grpc.Dial("localhost:5890",
+		grpc.WithTransportCredentials(credentials.NewClientTLSFromCert(pool, "")),
+		grpc.WithPerRPCCredentials(creds))
+	if err != nil {
+		return err
+	}
+	client := vtcdrpc.NewChainServiceClient(conn)
+*/
+package vtcdrpc
+
+//go:generate protoc -I. api.proto --go_out=plugins=grpc:.
@@ -0,0 +1,278 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zmq
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	"github.com/vertcoin/vtcd/blockchain"
+	"github.com/vertcoin/vtcd/chaincfg/chainhash"
+	"github.com/vertcoin/vtcd/mempool"
+	"github.com/vertcoin/vtcutil"
+)
+
+// Topics which may be published.  Each message is made up of three parts: the
+// topic, the body described below, and the sequence number of the message as a
+// 4-byte little-endian integer.  Sequence numbers are kept per topic and
+// increase by one for every message, so subscribers can detect dropped
+// messages.
+const (
+	// TopicHashBlock publishes the hash of every block connected to the
+	// main chain.
+	TopicHashBlock = "hashblock"
+
+	// TopicRawBlock publishes the serialized block of every block
+	// connected to the main chain.
+	TopicRawBlock = "rawblock"
+
+	// TopicHashTx publishes the hash of every transaction accepted into
+	// the memory pool or included in a block connected to the main chain.
+	TopicHashTx = "hashtx"
+
+	// TopicRawTx publishes the serialized transaction of every transaction
+	// accepted into the memory pool or included in a block connected to
+	// the main chain.
+	TopicRawTx = "rawtx"
+
+	// TopicSequence publishes the hash of every connected and disconnected
+	// block and of every transaction added to or removed from the memory
+	// pool, followed by a one byte label: 'C' for connected and 'D' for
+	// disconnected blocks, and 'A' for added and 'R' for removed
+	// transactions.  Memory pool events are followed by an 8-byte
+	// little-endian memory pool sequence number which increases by one for
+	// every added or removed transaction.
+	TopicSequence = "sequence"
+)
+
+// Topics lists all topics which may be published.
+var Topics = []string{TopicHashBlock, TopicRawBlock, TopicHashTx, TopicRawTx,
+	TopicSequence}
+
+// Labels of the events published on the sequence topic.
+const (
+	sequenceBlockConnected    = 'C'
+	sequenceBlockDisconnected = 'D'
+	sequenceTxAdded           = 'A'
+	sequenceTxRemoved         = 'R'
+)
+
+// Config is a descriptor containing the publisher configuration.
+type Config struct {
+	// Endpoints maps the topics to publish to the endpoints they are
+	// published on, such as tcp://127.0.0.1:28332.  Several topics may
+	// share an endpoint.
+	Endpoints map[string]string
+
+	// HighWaterMark is the maximum number of messages which are queued for
+	// each subscriber.  Further messages for the subscriber are dropped
+	// until it catches up.
+	HighWaterMark int
+}
+
+// topic houses the socket a topic is published on along with the sequence
+// number of its next message.
+type topic struct {
+	name     []byte
+	socket   *PubSocket
+	sequence uint32
+}
+
+// Publisher publishes blocks and transactions to ZeroMQ subscribers.  It is
+// driven by the notifications of the block chain and the memory pool, which
+// are passed to HandleBlockchainNotification and HandleMempoolNotification.
+type Publisher struct {
+	sockets []*PubSocket
+
+	mtx             sync.Mutex
+	topics          map[string]*topic
+	mempoolSequence uint64
+}
+
+// New returns a new publisher bound to the endpoints of the provided
+// configuration.  Use Start to begin accepting subscribers.
+func New(cfg *Config) (*Publisher, error) {
+	hwm := cfg.HighWaterMark
+	if hwm == 0 {
+		hwm = DefaultHighWaterMark
+	}
+
+	p := &Publisher{topics: make(map[string]*topic)}
+	sockets := make(map[string]*PubSocket)
+	for _, name := range Topics {
+		endpoint, ok := cfg.Endpoints[name]
+		if !ok {
+			continue
+		}
+		socket, ok := sockets[endpoint]
+		if !ok {
+			var err error
+			socket, err = Listen(endpoint, hwm)
+			if err != nil {
+				p.Stop()
+				return nil, fmt.Errorf("unable to publish %s on "+
+					"%s: %v", name, endpoint, err)
+			}
+			sockets[endpoint] = socket
+			p.sockets = append(p.sockets, socket)
+		}
+		p.topics[name] = &topic{name: []byte(name), socket: socket}
+	}
+	for name := range cfg.Endpoints {
+		if _, ok := p.topics[name]; !ok {
+			p.Stop()
+			return nil, fmt.Errorf("unknown topic %q", name)
+		}
+	}
+	return p, nil
+}
+
+// Start begins accepting subscribers on all endpoints.
+func (p *Publisher) Start() {
+	for _, socket := range p.sockets {
+		socket.Start()
+	}
+}
+
+// Stop disconnects all subscribers and closes all endpoints.
+func (p *Publisher) Stop() {
+	for _, socket := range p.sockets {
+		socket.Close()
+	}
+}
+
+// publish sends the passed body on the passed topic along with the next
+// sequence number of the topic.  Nothing is sent when the topic isn't
+// published.
+//
+// This function MUST be called with the publisher lock held.
+func (p *Publisher) publish(name string, body []byte) {
+	t, ok := p.topics[name]
+	if !ok {
+		return
+	}
+	var sequence [4]byte
+	binary.LittleEndian.PutUint32(sequence[:], t.sequence)
+	t.sequence++
+	t.socket.Send(t.name, body, sequence[:])
+}
+
+// isPublished returns whether the passed topic is published, so the work of
+// creating its messages can be skipped otherwise.
+//
+// This function MUST be called with the publisher lock held.
+func (p *Publisher) isPublished(name string) bool {
+	_, ok := p.topics[name]
+	return ok
+}
+
+// hashBytes returns the passed hash in the byte order it is displayed in, which
+// is the order it is published in.
+func hashBytes(hash *chainhash.Hash) []byte {
+	b := make([]byte, chainhash.HashSize)
+	for i := range hash {
+		b[chainhash.HashSize-1-i] = hash[i]
+	}
+	return b
+}
+
+// publishSequence publishes an event with the passed label on the sequence
+// topic.  Memory pool events carry the next memory pool sequence number.
+//
+// This function MUST be called with the publisher lock held.
+func (p *Publisher) publishSequence(hash *chainhash.Hash, label byte) {
+	body := append(hashBytes(hash), label)
+	if label == sequenceTxAdded || label == sequenceTxRemoved {
+		var sequence [8]byte
+		binary.LittleEndian.PutUint64(sequence[:], p.mempoolSequence)
+		p.mempoolSequence++
+		body = append(body, sequence[:]...)
+	}
+	p.publish(TopicSequence, body)
+}
+
+// publishTx publishes the passed transaction on the hashtx and rawtx topics.
+//
+// This function MUST be called with the publisher lock held.
+func (p *Publisher) publishTx(tx *vtcutil.Tx) {
+	p.publish(TopicHashTx, hashBytes(tx.Hash()))
+	if p.isPublished(TopicRawTx) {
+		var buf bytes.Buffer
+		if err := tx.MsgTx().Serialize(&buf); err != nil {
+			log.Errorf("Unable to serialize transaction %s: %v",
+				tx.Hash(), err)
+			return
+		}
+		p.publish(TopicRawTx, buf.Bytes())
+	}
+}
+
+// HandleBlockchainNotification publishes connected and disconnected blocks
+// along with the transactions of connected blocks.
+func (p *Publisher) HandleBlockchainNotification(notification *blockchain.Notification) {
+	switch notification.Type {
+	case blockchain.NTBlockConnected:
+		block, ok := notification.Data.(*vtcutil.Block)
+		if !ok {
+			log.Warnf("Chain connected notification is not a block.")
+			break
+		}
+
+		p.mtx.Lock()
+		p.publish(TopicHashBlock, hashBytes(block.Hash()))
+		if p.isPublished(TopicRawBlock) {
+			blockBytes, err := block.Bytes()
+			if err != nil {
+				log.Errorf("Unable to serialize block %s: %v",
+					block.Hash(), err)
+			} else {
+				p.publish(TopicRawBlock, blockBytes)
+			}
+		}
+		for _, tx := range block.Transactions() {
+			p.publishTx(tx)
+		}
+		p.publishSequence(block.Hash(), sequenceBlockConnected)
+		p.mtx.Unlock()
+
+	case blockchain.NTBlockDisconnected:
+		block, ok := notification.Data.(*vtcutil.Block)
+		if !ok {
+			log.Warnf("Chain disconnected notification is not a block.")
+			break
+		}
+
+		p.mtx.Lock()
+		p.publishSequence(block.Hash(), sequenceBlockDisconnected)
+		p.mtx.Unlock()
+	}
+}
+
+// HandleMempoolNotification publishes transactions added to and removed from
+// the memory pool.  Like the reference implementation, no removal is published
+// for transactions removed because they were included in a block, since the
+// block connection sequence notification covers them.
+func (p *Publisher) HandleMempoolNotification(notification *mempool.Notification) {
+	tx, ok := notification.Data.(*vtcutil.Tx)
+	if !ok {
+		log.Warnf("Mempool notification is not a transaction.")
+		return
+	}
+
+	p.mtx.Lock()
+	switch notification.Type {
+	case mempool.NTTxAccepted:
+		p.publishTx(tx)
+		p.publishSequence(tx.Hash(), sequenceTxAdded)
+
+	case mempool.NTTxRemoved:
+		if notification.Reason != mempool.RRBlock {
+			p.publishSequence(tx.Hash(), sequenceTxRemoved)
+		}
+	}
+	p.mtx.Unlock()
+}
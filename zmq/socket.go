@@ -0,0 +1,386 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zmq
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// handshakeTimeout is the maximum time a subscriber may take to
+	// complete the greeting and handshake after connecting.
+	handshakeTimeout = time.Second * 10
+
+	// writeTimeout is the maximum time a write to a subscriber may take
+	// before it is disconnected.
+	writeTimeout = time.Minute
+
+	// DefaultHighWaterMark is the default maximum number of messages which
+	// are queued for each subscriber.
+	DefaultHighWaterMark = 1000
+)
+
+// ParseEndpoint returns the TCP address of the passed endpoint, which must be
+// of the form tcp://host:port.  As with ZeroMQ, a host of * binds all
+// interfaces.
+func ParseEndpoint(endpoint string) (string, error) {
+	const prefix = "tcp://"
+	if !strings.HasPrefix(endpoint, prefix) {
+		return "", fmt.Errorf("unsupported endpoint %q -- only tcp:// "+
+			"endpoints are supported", endpoint)
+	}
+	host, port, err := net.SplitHostPort(endpoint[len(prefix):])
+	if err != nil {
+		return "", fmt.Errorf("invalid endpoint %q: %v", endpoint, err)
+	}
+	if host == "*" {
+		host = ""
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// PubSocket is a ZeroMQ PUB socket speaking ZMTP 3 over TCP.  Messages are sent
+// to every connected subscriber with a matching subscription.  Like ZeroMQ,
+// sending never blocks: messages for subscribers whose queue is full are
+// dropped.
+type PubSocket struct {
+	started  int32
+	shutdown int32
+	listener net.Listener
+	hwm      int
+	wg       sync.WaitGroup
+	quit     chan struct{}
+
+	mtx   sync.Mutex
+	peers map[*subscriber]struct{}
+}
+
+// Listen returns a new PUB socket bound to the passed endpoint.  Each
+// subscriber may have up to hwm messages queued.  Use Start to begin accepting
+// subscribers.
+func Listen(endpoint string, hwm int) (*PubSocket, error) {
+	addr, err := ParseEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if hwm < 1 {
+		return nil, errors.New("high water mark must be positive")
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &PubSocket{
+		listener: listener,
+		hwm:      hwm,
+		quit:     make(chan struct{}),
+		peers:    make(map[*subscriber]struct{}),
+	}, nil
+}
+
+// Addr returns the address the socket is bound to.
+func (s *PubSocket) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Start begins accepting subscribers.
+func (s *PubSocket) Start() {
+	if atomic.AddInt32(&s.started, 1) != 1 {
+		return
+	}
+
+	s.wg.Add(1)
+	go s.listenHandler()
+}
+
+// Close stops accepting subscribers, disconnects all subscribers and waits for
+// all goroutines to finish.
+func (s *PubSocket) Close() error {
+	if atomic.AddInt32(&s.shutdown, 1) != 1 {
+		return nil
+	}
+
+	close(s.quit)
+	err := s.listener.Close()
+	s.mtx.Lock()
+	for sub := range s.peers {
+		sub.conn.Close()
+	}
+	s.mtx.Unlock()
+	s.wg.Wait()
+	return err
+}
+
+// Send queues a multipart message for all subscribers which are subscribed to
+// a prefix of its first part.  It never blocks.
+func (s *PubSocket) Send(parts ...[]byte) {
+	if len(parts) == 0 {
+		return
+	}
+	var msg []byte
+	s.mtx.Lock()
+	for sub := range s.peers {
+		if !sub.isSubscribed(parts[0]) {
+			continue
+		}
+		if msg == nil {
+			msg = encodeMessage(parts)
+		}
+		select {
+		case sub.sendQueue <- msg:
+		default:
+			log.Debugf("Dropping message for slow subscriber %s",
+				sub.conn.RemoteAddr())
+		}
+	}
+	s.mtx.Unlock()
+}
+
+// NumSubscribers returns the number of connected subscribers.
+func (s *PubSocket) NumSubscribers() int {
+	s.mtx.Lock()
+	n := len(s.peers)
+	s.mtx.Unlock()
+	return n
+}
+
+// listenHandler accepts subscribers until the socket is closed.
+//
+// This must be run as a goroutine.
+func (s *PubSocket) listenHandler() {
+	defer s.wg.Done()
+
+	log.Infof("ZMQ publisher listening on %s", s.listener.Addr())
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if atomic.LoadInt32(&s.shutdown) == 0 {
+				log.Errorf("Can't accept connection: %v", err)
+			}
+			break
+		}
+		s.wg.Add(1)
+		go s.handleConn(conn)
+	}
+	log.Tracef("ZMQ listener done for %s", s.listener.Addr())
+}
+
+// subscriber houses a connection to a SUB or XSUB socket along with its
+// subscriptions and the queue of messages to send to it.
+type subscriber struct {
+	conn      net.Conn
+	sendQueue chan []byte
+
+	subsMtx sync.Mutex
+	subs    map[string]int
+}
+
+// isSubscribed returns whether the subscriber subscribed to a prefix of the
+// passed topic.
+func (sub *subscriber) isSubscribed(topic []byte) bool {
+	sub.subsMtx.Lock()
+	defer sub.subsMtx.Unlock()
+	for prefix := range sub.subs {
+		if len(prefix) <= len(topic) && string(topic[:len(prefix)]) == prefix {
+			return true
+		}
+	}
+	return false
+}
+
+// updateSubscription adds or removes a subscription of the subscriber.  Like
+// ZeroMQ, subscriptions are counted, so each subscription must be cancelled as
+// many times as it was added.
+func (sub *subscriber) updateSubscription(prefix []byte, subscribe bool) {
+	sub.subsMtx.Lock()
+	if subscribe {
+		sub.subs[string(prefix)]++
+	} else if sub.subs[string(prefix)] > 1 {
+		sub.subs[string(prefix)]--
+	} else {
+		delete(sub.subs, string(prefix))
+	}
+	sub.subsMtx.Unlock()
+}
+
+// handleConn performs the handshake with a new connection and serves it as a
+// subscriber until it disconnects or the socket is closed.
+//
+// This must be run as a goroutine.
+func (s *PubSocket) handleConn(conn net.Conn) {
+	defer s.wg.Done()
+	defer conn.Close()
+
+	sub := &subscriber{
+		conn:      conn,
+		sendQueue: make(chan []byte, s.hwm),
+		subs:      make(map[string]int),
+	}
+
+	// Track the connection right away so it is closed along with the
+	// socket.  Nothing is sent to it until it subscribes.
+	s.mtx.Lock()
+	if atomic.LoadInt32(&s.shutdown) != 0 {
+		s.mtx.Unlock()
+		return
+	}
+	s.peers[sub] = struct{}{}
+	s.mtx.Unlock()
+	defer func() {
+		s.mtx.Lock()
+		delete(s.peers, sub)
+		s.mtx.Unlock()
+	}()
+
+	r := bufio.NewReader(conn)
+	if err := handshake(conn, r); err != nil {
+		log.Debugf("ZMQ handshake with %s failed: %v", conn.RemoteAddr(),
+			err)
+		return
+	}
+	log.Debugf("New ZMQ subscriber %s", conn.RemoteAddr())
+
+	done := make(chan struct{})
+	s.wg.Add(1)
+	go s.sendHandler(sub, done)
+	err := s.readHandler(sub, r)
+	close(done)
+
+	if atomic.LoadInt32(&s.shutdown) == 0 {
+		log.Debugf("ZMQ subscriber %s disconnected: %v",
+			conn.RemoteAddr(), err)
+	}
+}
+
+// handshake exchanges greetings and READY commands with a new connection and
+// ensures the peer is a subscriber.
+func handshake(conn net.Conn, r *bufio.Reader) error {
+	conn.SetDeadline(time.Now().Add(handshakeTimeout))
+	defer conn.SetDeadline(time.Time{})
+
+	if _, err := conn.Write(greeting()); err != nil {
+		return err
+	}
+	if err := readGreeting(r); err != nil {
+		return err
+	}
+	ready := encodeCommand(cmdReady, encodeMetadata(map[string]string{
+		"Socket-Type": "PUB",
+	}))
+	if _, err := conn.Write(ready); err != nil {
+		return err
+	}
+
+	f, err := readFrame(r, maxCommandSize)
+	if err != nil {
+		return err
+	}
+	if !f.isCommand() {
+		return errors.New("expected READY command")
+	}
+	name, data, err := parseCommand(f.body)
+	if err != nil {
+		return err
+	}
+	if name != cmdReady {
+		return fmt.Errorf("expected READY command, got %s", name)
+	}
+	props, err := parseMetadata(data)
+	if err != nil {
+		return err
+	}
+	socketType := props["socket-type"]
+	if socketType != "SUB" && socketType != "XSUB" {
+		errMsg := "invalid socket type"
+		conn.Write(encodeCommand(cmdError, append([]byte{
+			byte(len(errMsg))}, errMsg...)))
+		return fmt.Errorf("incompatible socket type %q", socketType)
+	}
+	return nil
+}
+
+// readHandler reads subscriptions and commands from a subscriber until it
+// disconnects.  ZMTP 3.0 subscribers send subscriptions as messages whose
+// first byte is 1 to subscribe and 0 to cancel, while ZMTP 3.1 subscribers may
+// also use the SUBSCRIBE and CANCEL commands.
+func (s *PubSocket) readHandler(sub *subscriber, r *bufio.Reader) error {
+	for {
+		f, err := readFrame(r, maxFrameSize)
+		if err != nil {
+			return err
+		}
+
+		if !f.isCommand() {
+			// Subscribers don't send multipart messages, so only
+			// single frames are interpreted.
+			if f.hasMore() || len(f.body) == 0 {
+				continue
+			}
+			switch f.body[0] {
+			case 0:
+				sub.updateSubscription(f.body[1:], false)
+			case 1:
+				sub.updateSubscription(f.body[1:], true)
+			}
+			continue
+		}
+
+		name, data, err := parseCommand(f.body)
+		if err != nil {
+			return err
+		}
+		switch name {
+		case cmdSubscribe:
+			sub.updateSubscription(data, true)
+		case cmdCancel:
+			sub.updateSubscription(data, false)
+		case cmdPing:
+			// Reply with the context of the ping, which follows
+			// its two byte TTL.
+			if len(data) < 2 {
+				return errors.New("malformed PING command")
+			}
+			pong := encodeCommand(cmdPong, data[2:])
+			select {
+			case sub.sendQueue <- pong:
+			default:
+			}
+		case cmdError:
+			return errors.New("subscriber sent ERROR command")
+		}
+	}
+}
+
+// sendHandler writes the queued messages to a subscriber until it disconnects
+// or the socket is closed.
+//
+// This must be run as a goroutine.
+func (s *PubSocket) sendHandler(sub *subscriber, done <-chan struct{}) {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case msg := <-sub.sendQueue:
+			sub.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if _, err := sub.conn.Write(msg); err != nil {
+				sub.conn.Close()
+				return
+			}
+
+		case <-done:
+			return
+
+		case <-s.quit:
+			return
+		}
+	}
+}
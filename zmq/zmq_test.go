@@ -0,0 +1,326 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zmq
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/vertcoin/vtcd/blockchain"
+	"github.com/vertcoin/vtcd/chaincfg"
+	"github.com/vertcoin/vtcd/mempool"
+	"github.com/vertcoin/vtcd/wire"
+	"github.com/vertcoin/vtcutil"
+)
+
+// testSubscriber is a minimal ZMTP SUB socket used to test the publisher.
+type testSubscriber struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// dialSubscriber connects to the passed address and performs the handshake
+// announcing the passed socket type.  The READY command of the publisher is
+// returned.
+func dialSubscriber(t *testing.T, addr net.Addr, socketType string) (*testSubscriber, *frame) {
+	conn, err := net.Dial("tcp", addr.String())
+	if err != nil {
+		t.Fatalf("unable to connect: %v", err)
+	}
+	conn.SetDeadline(time.Now().Add(time.Second * 10))
+	sub := &testSubscriber{conn: conn, r: bufio.NewReader(conn)}
+	if _, err := conn.Write(greeting()); err != nil {
+		t.Fatalf("unable to send greeting: %v", err)
+	}
+	if err := readGreeting(sub.r); err != nil {
+		t.Fatalf("unable to read greeting: %v", err)
+	}
+	ready := encodeCommand(cmdReady, encodeMetadata(map[string]string{
+		"Socket-Type": socketType,
+	}))
+	if _, err := conn.Write(ready); err != nil {
+		t.Fatalf("unable to send READY: %v", err)
+	}
+	f, err := readFrame(sub.r, maxCommandSize)
+	if err != nil {
+		t.Fatalf("unable to read READY: %v", err)
+	}
+	return sub, f
+}
+
+// subscribe subscribes to the passed prefix using a ZMTP 3.0 subscription
+// message or a ZMTP 3.1 SUBSCRIBE command.
+func (sub *testSubscriber) subscribe(t *testing.T, prefix string, useCommand bool) {
+	var msg []byte
+	if useCommand {
+		msg = encodeCommand(cmdSubscribe, []byte(prefix))
+	} else {
+		msg = encodeMessage([][]byte{append([]byte{1}, prefix...)})
+	}
+	if _, err := sub.conn.Write(msg); err != nil {
+		t.Fatalf("unable to subscribe: %v", err)
+	}
+}
+
+// readMessage reads the next multipart message sent by the publisher.
+func (sub *testSubscriber) readMessage(t *testing.T) [][]byte {
+	var parts [][]byte
+	for {
+		f, err := readFrame(sub.r, 1<<20)
+		if err != nil {
+			t.Fatalf("unable to read message: %v", err)
+		}
+		if f.isCommand() {
+			t.Fatalf("unexpected command %q", f.body)
+		}
+		parts = append(parts, f.body)
+		if !f.hasMore() {
+			return parts
+		}
+	}
+}
+
+// waitSubscribed waits until the passed number of subscribers of the socket
+// are subscribed to the passed topic.
+func waitSubscribed(t *testing.T, s *PubSocket, topic string, n int) {
+	for i := 0; i < 500; i++ {
+		subscribed := 0
+		s.mtx.Lock()
+		for sub := range s.peers {
+			if sub.isSubscribed([]byte(topic)) {
+				subscribed++
+			}
+		}
+		s.mtx.Unlock()
+		if subscribed == n {
+			return
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	t.Fatalf("subscribers did not subscribe to %s", topic)
+}
+
+// TestParseEndpoint ensures endpoints are converted to TCP addresses as
+// expected.
+func TestParseEndpoint(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		endpoint string
+		addr     string
+		valid    bool
+	}{
+		{"tcp://127.0.0.1:28332", "127.0.0.1:28332", true},
+		{"tcp://*:28332", ":28332", true},
+		{"tcp://[::1]:28332", "[::1]:28332", true},
+		{"tcp://127.0.0.1", "", false},
+		{"ipc:///tmp/vtcd.sock", "", false},
+		{"127.0.0.1:28332", "", false},
+	}
+
+	for _, test := range tests {
+		addr, err := ParseEndpoint(test.endpoint)
+		if (err == nil) != test.valid {
+			t.Errorf("%s: unexpected error result %v", test.endpoint,
+				err)
+			continue
+		}
+		if addr != test.addr {
+			t.Errorf("%s: unexpected address - got %s, want %s",
+				test.endpoint, addr, test.addr)
+		}
+	}
+}
+
+// TestPubSocket ensures messages are only sent to subscribers with a matching
+// subscription and incompatible peers are rejected.
+func TestPubSocket(t *testing.T) {
+	t.Parallel()
+
+	s, err := Listen("tcp://127.0.0.1:0", DefaultHighWaterMark)
+	if err != nil {
+		t.Fatalf("Listen: unexpected error: %v", err)
+	}
+	defer s.Close()
+	s.Start()
+
+	// A peer which isn't a subscriber is sent an error.
+	pub, reply := dialSubscriber(t, s.Addr(), "PUB")
+	defer pub.conn.Close()
+	if name, _, err := parseCommand(reply.body); err != nil || name != cmdReady {
+		t.Fatalf("unexpected reply to handshake %q", reply.body)
+	}
+	f, err := readFrame(pub.r, maxCommandSize)
+	if err != nil {
+		t.Fatalf("unable to read reply: %v", err)
+	}
+	if name, _, err := parseCommand(f.body); err != nil || name != cmdError {
+		t.Fatalf("unexpected reply to PUB peer %q", f.body)
+	}
+
+	// The READY command of the socket announces its type.
+	sub1, reply := dialSubscriber(t, s.Addr(), "SUB")
+	defer sub1.conn.Close()
+	_, data, err := parseCommand(reply.body)
+	if err != nil {
+		t.Fatalf("unable to parse READY: %v", err)
+	}
+	props, err := parseMetadata(data)
+	if err != nil || props["socket-type"] != "PUB" {
+		t.Fatalf("unexpected READY metadata %q", data)
+	}
+	sub2, _ := dialSubscriber(t, s.Addr(), "SUB")
+	defer sub2.conn.Close()
+
+	sub1.subscribe(t, "hash", false)
+	sub2.subscribe(t, "raw", true)
+	waitSubscribed(t, s, "hashblock", 1)
+	waitSubscribed(t, s, "rawblock", 1)
+
+	s.Send([]byte("rawtx"), []byte{1})
+	s.Send([]byte("hashtx"), []byte{2}, []byte{3})
+	s.Send([]byte("sequence"), []byte{4})
+	s.Send([]byte("hashblock"), bytes.Repeat([]byte{5}, 300))
+
+	msg := sub1.readMessage(t)
+	if len(msg) != 3 || string(msg[0]) != "hashtx" {
+		t.Errorf("unexpected first message %q", msg)
+	}
+	msg = sub1.readMessage(t)
+	if len(msg) != 2 || string(msg[0]) != "hashblock" || len(msg[1]) != 300 {
+		t.Errorf("unexpected second message %q", msg)
+	}
+	msg = sub2.readMessage(t)
+	if len(msg) != 2 || string(msg[0]) != "rawtx" || msg[1][0] != 1 {
+		t.Errorf("unexpected message %q", msg)
+	}
+}
+
+// TestPublisher ensures block and memory pool notifications are published on
+// the configured topics with consecutive sequence numbers and that removals of
+// transactions included in a block are not published.
+func TestPublisher(t *testing.T) {
+	t.Parallel()
+
+	// Topics must be known and endpoints valid.
+	_, err := New(&Config{Endpoints: map[string]string{
+		"rawmempool": "tcp://127.0.0.1:0",
+	}})
+	if err == nil {
+		t.Errorf("New: did not receive expected error for unknown topic")
+	}
+	_, err = New(&Config{Endpoints: map[string]string{
+		TopicRawTx: "ipc:///tmp/vtcd.sock",
+	}})
+	if err == nil {
+		t.Errorf("New: did not receive expected error for invalid " +
+			"endpoint")
+	}
+
+	// The topics share an endpoint, so they are published on the same
+	// socket.
+	endpoint := "tcp://127.0.0.1:0"
+	p, err := New(&Config{Endpoints: map[string]string{
+		TopicHashBlock: endpoint,
+		TopicRawTx:     endpoint,
+		TopicSequence:  endpoint,
+	}})
+	if err != nil {
+		t.Fatalf("New: unexpected error: %v", err)
+	}
+	defer p.Stop()
+	if len(p.sockets) != 1 {
+		t.Fatalf("unexpected number of sockets %d", len(p.sockets))
+	}
+	p.Start()
+	sub, _ := dialSubscriber(t, p.sockets[0].Addr(), "SUB")
+	defer sub.conn.Close()
+	sub.subscribe(t, "", false)
+	waitSubscribed(t, p.sockets[0], "", 1)
+
+	block := vtcutil.NewBlock(chaincfg.RegressionNetParams.GenesisBlock)
+	coinbase := block.Transactions()[0]
+	tx := vtcutil.NewTx(wire.NewMsgTx(wire.TxVersion))
+	var txBuf bytes.Buffer
+	if err := tx.MsgTx().Serialize(&txBuf); err != nil {
+		t.Fatalf("unable to serialize tx: %v", err)
+	}
+	var coinbaseBuf bytes.Buffer
+	if err := coinbase.MsgTx().Serialize(&coinbaseBuf); err != nil {
+		t.Fatalf("unable to serialize tx: %v", err)
+	}
+
+	p.HandleMempoolNotification(&mempool.Notification{
+		Type: mempool.NTTxAccepted,
+		Data: tx,
+	})
+	p.HandleMempoolNotification(&mempool.Notification{
+		Type:   mempool.NTTxRemoved,
+		Data:   tx,
+		Reason: mempool.RRBlock,
+	})
+	p.HandleMempoolNotification(&mempool.Notification{
+		Type:   mempool.NTTxRemoved,
+		Data:   tx,
+		Reason: mempool.RRConflict,
+	})
+	p.HandleBlockchainNotification(&blockchain.Notification{
+		Type: blockchain.NTBlockConnected,
+		Data: block,
+	})
+	p.HandleBlockchainNotification(&blockchain.Notification{
+		Type: blockchain.NTBlockDisconnected,
+		Data: block,
+	})
+
+	mempoolSequence := func(label byte, sequence uint64) []byte {
+		body := append(hashBytes(tx.Hash()), label)
+		var seq [8]byte
+		binary.LittleEndian.PutUint64(seq[:], sequence)
+		return append(body, seq[:]...)
+	}
+	tests := []struct {
+		topic    string
+		body     []byte
+		sequence uint32
+	}{
+		{TopicRawTx, txBuf.Bytes(), 0},
+		{TopicSequence, mempoolSequence('A', 0), 0},
+		{TopicSequence, mempoolSequence('R', 1), 1},
+		{TopicHashBlock, hashBytes(block.Hash()), 0},
+		{TopicRawTx, coinbaseBuf.Bytes(), 1},
+		{TopicSequence, append(hashBytes(block.Hash()), 'C'), 2},
+		{TopicSequence, append(hashBytes(block.Hash()), 'D'), 3},
+	}
+	for i, test := range tests {
+		msg := sub.readMessage(t)
+		if len(msg) != 3 {
+			t.Fatalf("#%d: unexpected number of parts %d", i, len(msg))
+		}
+		if string(msg[0]) != test.topic {
+			t.Fatalf("#%d: unexpected topic - got %s, want %s", i,
+				msg[0], test.topic)
+		}
+		if !bytes.Equal(msg[1], test.body) {
+			t.Errorf("#%d: unexpected body - got %x, want %x", i,
+				msg[1], test.body)
+		}
+		if len(msg[2]) != 4 {
+			t.Errorf("#%d: unexpected sequence number %x", i, msg[2])
+		} else if seq := binary.LittleEndian.Uint32(msg[2]); seq != test.sequence {
+			t.Errorf("#%d: unexpected sequence number - got %d, "+
+				"want %d", i, seq, test.sequence)
+		}
+	}
+
+	// Hashes are published in the byte order they are displayed in.
+	if hashBytes(block.Hash())[0] != block.Hash()[31] {
+		t.Errorf("hash is not published in display order")
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright (c) 2018 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zmq
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// greetingLen is the length of the greeting each peer sends when a
+	// connection is established.
+	greetingLen = 64
+
+	// zmtpMajorVersion and zmtpMinorVersion are the ZMTP version announced
+	// in the greeting.  Announcing 3.0 keeps the socket compatible with all
+	// ZMTP 3.x peers, which then send subscriptions as messages.
+	zmtpMajorVersion = 3
+	zmtpMinorVersion = 0
+
+	// maxCommandSize is the maximum size of a command body accepted from a
+	// peer.
+	maxCommandSize = 4096
+
+	// maxFrameSize is the maximum size of a message frame accepted from a
+	// peer.  Subscribers only send subscriptions, which are short.
+	maxFrameSize = 4096
+)
+
+// Frame flags as defined by the ZMTP specification.
+const (
+	flagMore    = 0x01
+	flagLong    = 0x02
+	flagCommand = 0x04
+)
+
+// Names of the commands used by the socket.
+const (
+	cmdReady     = "READY"
+	cmdError     = "ERROR"
+	cmdSubscribe = "SUBSCRIBE"
+	cmdCancel    = "CANCEL"
+	cmdPing      = "PING"
+	cmdPong      = "PONG"
+)
+
+// nullMechanism is the name of the only security mechanism supported by the
+// socket, which performs no authentication or encryption.
+const nullMechanism = "NULL"
+
+// greeting returns the greeting sent to peers.  It announces the NULL
+// security mechanism.
+func greeting() []byte {
+	var g [greetingLen]byte
+	g[0] = 0xff
+	g[9] = 0x7f
+	g[10] = zmtpMajorVersion
+	g[11] = zmtpMinorVersion
+	copy(g[12:32], nullMechanism)
+	return g[:]
+}
+
+// readGreeting reads the greeting of a peer and ensures it speaks ZMTP 3.x
+// with the NULL security mechanism.
+func readGreeting(r io.Reader) error {
+	var g [greetingLen]byte
+	if _, err := io.ReadFull(r, g[:]); err != nil {
+		return err
+	}
+	if g[0] != 0xff || g[9]&0x01 != 0x01 {
+		return errors.New("invalid ZMTP signature")
+	}
+	if g[10] < zmtpMajorVersion {
+		return fmt.Errorf("unsupported ZMTP version %d.%d", g[10], g[11])
+	}
+	mechanism := string(bytes.TrimRight(g[12:32], "\x00"))
+	if mechanism != nullMechanism {
+		return fmt.Errorf("unsupported security mechanism %q", mechanism)
+	}
+	return nil
+}
+
+// frame is a message frame or command read from a peer.
+type frame struct {
+	flags byte
+	body  []byte
+}
+
+// isCommand returns whether the frame is a command.
+func (f *frame) isCommand() bool {
+	return f.flags&flagCommand != 0
+}
+
+// hasMore returns whether the frame is followed by more frames of the same
+// message.
+func (f *frame) hasMore() bool {
+	return f.flags&flagMore != 0
+}
+
+// readFrame reads a message frame or command from a peer.  Frames larger than
+// the passed maximum size are rejected.
+func readFrame(r io.Reader, maxSize uint64) (*frame, error) {
+	var flags [1]byte
+	if _, err := io.ReadFull(r, flags[:]); err != nil {
+		return nil, err
+	}
+	var size uint64
+	if flags[0]&flagLong != 0 {
+		var sizeBytes [8]byte
+		if _, err := io.ReadFull(r, sizeBytes[:]); err != nil {
+			return nil, err
+		}
+		size = binary.BigEndian.Uint64(sizeBytes[:])
+	} else {
+		var sizeByte [1]byte
+		if _, err := io.ReadFull(r, sizeByte[:]); err != nil {
+			return nil, err
+		}
+		size = uint64(sizeByte[0])
+	}
+	if size > maxSize {
+		return nil, fmt.Errorf("frame size %d exceeds the maximum %d",
+			size, maxSize)
+	}
+	body := make([]byte, size)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	return &frame{flags: flags[0], body: body}, nil
+}
+
+// appendFrame appends the encoding of a frame with the passed flags and body
+// to buf.  The long flag is added as needed.
+func appendFrame(buf []byte, flags byte, body []byte) []byte {
+	if len(body) > 255 {
+		var size [8]byte
+		binary.BigEndian.PutUint64(size[:], uint64(len(body)))
+		buf = append(buf, flags|flagLong)
+		buf = append(buf, size[:]...)
+	} else {
+		buf = append(buf, flags, byte(len(body)))
+	}
+	return append(buf, body...)
+}
+
+// encodeMessage returns the encoding of a multipart message made up of the
+// passed parts.
+func encodeMessage(parts [][]byte) []byte {
+	size := 0
+	for _, part := range parts {
+		size += 9 + len(part)
+	}
+	buf := make([]byte, 0, size)
+	for i, part := range parts {
+		var flags byte
+		if i < len(parts)-1 {
+			flags = flagMore
+		}
+		buf = appendFrame(buf, flags, part)
+	}
+	return buf
+}
+
+// encodeCommand returns the encoding of a command with the passed name and
+// data.
+func encodeCommand(name string, data []byte) []byte {
+	body := make([]byte, 0, 1+len(name)+len(data))
+	body = append(body, byte(len(name)))
+	body = append(body, name...)
+	body = append(body, data...)
+	return appendFrame(nil, flagCommand, body)
+}
+
+// parseCommand splits the body of a command into its name and data.
+func parseCommand(body []byte) (string, []byte, error) {
+	if len(body) == 0 || len(body) < 1+int(body[0]) {
+		return "", nil, errors.New("malformed command")
+	}
+	nameLen := int(body[0])
+	return string(body[1 : 1+nameLen]), body[1+nameLen:], nil
+}
+
+// encodeMetadata returns the encoding of the passed properties as used by the
+// READY command.
+func encodeMetadata(props map[string]string) []byte {
+	var buf []byte
+	for name, value := range props {
+		var valueLen [4]byte
+		binary.BigEndian.PutUint32(valueLen[:], uint32(len(value)))
+		buf = append(buf, byte(len(name)))
+		buf = append(buf, name...)
+		buf = append(buf, valueLen[:]...)
+		buf = append(buf, value...)
+	}
+	return buf
+}
+
+// parseMetadata parses the properties of a READY command.  Property names are
+// case-insensitive, so they are returned in lower case.
+func parseMetadata(data []byte) (map[string]string, error) {
+	props := make(map[string]string)
+	for len(data) > 0 {
+		nameLen := int(data[0])
+		if len(data) < 1+nameLen+4 {
+			return nil, errors.New("malformed metadata")
+		}
+		name := string(bytes.ToLower(data[1 : 1+nameLen]))
+		data = data[1+nameLen:]
+		valueLen := binary.BigEndian.Uint32(data[:4])
+		data = data[4:]
+		if uint64(len(data)) < uint64(valueLen) {
+			return nil, errors.New("malformed metadata")
+		}
+		props[name] = string(data[:valueLen])
+		data = data[valueLen:]
+	}
+	return props, nil
+}